@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// auditRecord is a line of the audit log: the evidence a repository was validated,
+// by whom, where and with which configuration
+type auditRecord struct {
+	Time           string `json:"time"` // UTC, RFC 3339
+	User           string `json:"user"`
+	Host           string `json:"host"`
+	ToolVersion    string `json:"tool_version"`
+	Config         string `json:"config"`
+	ConfigSHA256   string `json:"config_sha256"`
+	Repository     string `json:"repository,omitempty"`
+	SourceCodeRoot string `json:"source_code_root"`
+	Commit         string `json:"commit,omitempty"` // git commit of the source code root
+	Verdict        string `json:"verdict"`          // PASS, FAIL, or ERROR when the validation could not run
+	Errors         int    `json:"errors"`
+	Warnings       int    `json:"warnings"`
+	Error          string `json:"error,omitempty"`
+}
+
+// currentUser returns the name of the user running the tool
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, name := range []string{"USER", "USERNAME"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// auditRecords returns the audit records of the results, one per repository
+func auditRecords(now time.Time, configPath, configSHA256 string, results []analyzer.RepositoryResult) []auditRecord {
+	host, _ := os.Hostname()
+	records := make([]auditRecord, 0, len(results))
+	for _, r := range results {
+		record := auditRecord{
+			Time:           now.UTC().Format(time.RFC3339),
+			User:           currentUser(),
+			Host:           host,
+			ToolVersion:    version,
+			Config:         configPath,
+			ConfigSHA256:   configSHA256,
+			Repository:     r.Name,
+			SourceCodeRoot: r.Root,
+			Commit:         analyzer.HeadCommit(r.Root),
+			Verdict:        r.Result.Summary.Verdict(),
+			Errors:         r.Result.Summary.Errors,
+			Warnings:       r.Result.Summary.Warnings,
+		}
+		if r.Err != nil {
+			record.Error = r.Err.Error()
+			// The validation failed before summarizing any script, e.g. a misconfigured script
+			if len(r.Result.Summary.Scripts) == 0 {
+				record.Verdict = "ERROR"
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// appendAuditRecords appends the audit records of the results to the JSONL file,
+// created when missing; existing records are never rewritten
+func appendAuditRecords(file, configPath, configSHA256 string, results []analyzer.RepositoryResult) error {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, record := range auditRecords(time.Now(), configPath, configSHA256, results) {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write audit log '%s': %w", file, err)
+		}
+	}
+	return f.Close()
+}
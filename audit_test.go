@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestRunCheck_AuditLog(t *testing.T) {
+	// What: Every run appends a record with the verdict and the configuration checksum
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		if err := runCheck([]string{"-c", configPath, "-format", "compact", "-audit-log", auditLog}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("runCheck() failed: %v", err)
+		}
+	}
+	content, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatalf("Audit log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %q", content)
+	}
+	var record auditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Invalid record %q: %v", lines[1], err)
+	}
+	if record.Verdict != "PASS" || record.Config != configPath || len(record.ConfigSHA256) != 64 || record.User == "" || record.ToolVersion != version {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestRunCheck_AuditLogUnwritable(t *testing.T) {
+	// What: A run whose audit record cannot be written fails with an I/O error
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-audit-log", t.TempDir()}, &bytes.Buffer{})
+	if exitCode(err) != exitIO || !strings.Contains(err.Error(), "failed to open audit log") {
+		t.Errorf("Expected an I/O error, got %v", err)
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	// What: One record per repository; a repository whose validation could not run has the verdict ERROR
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 7200))
+	results := []analyzer.RepositoryResult{
+		{Name: "plant", Root: t.TempDir(), Result: analyzer.Result{Summary: analyzer.Summary{
+			Scripts: []analyzer.ScriptSummary{{Script: "deploy.sh", Errors: 2}}, Errors: 2}}, Err: errors.New("thresholds exceeded")},
+		{Name: "broken", Root: t.TempDir(), Err: errors.New("script not found")},
+	}
+	records := auditRecords(now, "config.yaml", "abc", results)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %+v", records)
+	}
+	if r := records[0]; r.Time != "2024-10-01T10:00:00Z" || r.Repository != "plant" || r.Verdict != "FAIL" || r.Errors != 2 || r.Commit != "" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+	if r := records[1]; r.Verdict != "ERROR" || r.Error != "script not found" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// Baseline file written by baseline and read by diff
+const defaultBaselineFile = "tcx-baseline.json"
+
+// baselineOptions are the command-line parameters of the baseline and diff subcommands
+type baselineOptions struct {
+	Args
+	File string
+}
+
+// baselineFlagSet defines the flags of the baseline subcommand into o
+func baselineFlagSet(o *baselineOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("baseline", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.StringVar(&o.File, "o", defaultBaselineFile, "baseline file to write")
+	return f
+}
+
+// diffFlagSet defines the flags of the diff subcommand into o
+func diffFlagSet(o *baselineOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("diff", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.StringVar(&o.File, "baseline", defaultBaselineFile, "baseline file to compare with")
+	return f
+}
+
+// runBaseline records the findings of the validation as the accepted baseline:
+// baseline [-c config.yaml] [-o tcx-baseline.json]
+func runBaseline(arguments []string, w io.Writer) error {
+	var o baselineOptions
+	if err := baselineFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	current, err := baselineOf(o.Args)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(o.File)
+	if err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to create baseline: %w", err))
+	}
+	defer file.Close()
+	if err := report.WriteBaseline(file, current); err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to write baseline '%s': %w", o.File, err))
+	}
+	fmt.Fprintf(w, "%d finding(s) recorded in '%s'\n", len(current.Findings), o.File)
+	return nil
+}
+
+// runDiff reports the findings added (+) and fixed (-) since the baseline; added
+// findings fail the run: diff [-c config.yaml] [-baseline tcx-baseline.json]
+func runDiff(arguments []string, w io.Writer) error {
+	var o baselineOptions
+	if err := diffFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	file, err := os.Open(o.File)
+	if err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to open baseline: %w", err))
+	}
+	defer file.Close()
+	baseline, err := report.ReadBaseline(file)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("baseline '%s': %w", o.File, err))
+	}
+
+	current, err := baselineOf(o.Args)
+	if err != nil {
+		return err
+	}
+	added, fixed := report.DiffBaseline(baseline, current)
+	for _, f := range added {
+		fmt.Fprintln(w, "+ "+report.BaselineLine(f))
+	}
+	for _, f := range fixed {
+		fmt.Fprintln(w, "- "+report.BaselineLine(f))
+	}
+	fmt.Fprintf(w, "%d new, %d fixed finding(s) since '%s'\n", len(added), len(fixed), o.File)
+	if len(added) > 0 {
+		return withExitCode(exitFindings, fmt.Errorf("%d new finding(s) since baseline '%s'", len(added), o.File))
+	}
+	return nil
+}
+
+// baselineOf runs the validation without console log and returns its findings.
+// Exceeded thresholds are findings like any other; a repository whose validation
+// could not run fails, as its baseline would be empty.
+func baselineOf(args Args) (report.Baseline, error) {
+	configurationParameters, err := getConfigFrom(args.ConfigPath, args.Config)
+	if err != nil {
+		return report.Baseline{}, err
+	}
+	results, err := validate(args, configurationParameters, true)
+	if results == nil {
+		return report.Baseline{}, err
+	}
+	if err := validationFailure(results); err != nil {
+		return report.Baseline{}, err
+	}
+	return report.NewBaseline(results), nil
+}
+
+// validationFailure returns the error of a repository whose validation could not
+// run, e.g. a misconfigured script: it failed before summarizing any script
+func validationFailure(results []analyzer.RepositoryResult) error {
+	for _, r := range results {
+		if r.Err != nil && len(r.Result.Summary.Scripts) == 0 {
+			err := r.Err
+			if r.Name != "" {
+				err = fmt.Errorf("repository '%s': %w", r.Name, r.Err)
+			}
+			return withExitCode(kindExitCodes[analyzer.Kind(r.Err)], err)
+		}
+	}
+	return nil
+}
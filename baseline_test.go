@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+func TestBaselineAndDiff(t *testing.T) {
+	// What: A recorded baseline accepts its findings, a new finding fails diff
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+
+	var out bytes.Buffer
+	if err := runBaseline([]string{"-c", configPath, "-o", baselinePath}, &out); err != nil {
+		t.Fatalf("runBaseline() failed: %v", err)
+	}
+	file, err := os.Open(baselinePath)
+	if err != nil {
+		t.Fatalf("Baseline not written: %v", err)
+	}
+	baseline, err := report.ReadBaseline(file)
+	file.Close()
+	if err != nil || len(baseline.Findings) != 1 || baseline.Findings[0].Rule != "TCX010" {
+		t.Fatalf("Expected the missing file in the baseline, got %+v (%v)", baseline, err)
+	}
+
+	out.Reset()
+	if err := runDiff([]string{"-c", configPath, "-baseline", baselinePath}, &out); err != nil {
+		t.Fatalf("runDiff() against its own baseline failed: %v", err)
+	}
+
+	// A line inserted above the accepted finding does not make it new
+	script := filepath.Join(filepath.Dir(configPath), "repo", "deploy.sh")
+	content := "plmxml_import -xml_file=\"100-Config/other.xml\"\nplmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to update script: %v", err)
+	}
+	out.Reset()
+	err = runDiff([]string{"-c", configPath, "-baseline", baselinePath}, &out)
+	if err == nil || !strings.Contains(err.Error(), "1 new finding(s)") {
+		t.Errorf("Expected 1 new finding, got: %v", err)
+	}
+	if !strings.Contains(out.String(), "+ deploy.sh:1:26: error: TCX010") || strings.Contains(out.String(), "- ") {
+		t.Errorf("Unexpected diff output %q", out.String())
+	}
+}
+
+func TestRunDiff_MissingBaseline(t *testing.T) {
+	// What: diff without a baseline file is an error
+	err := runDiff([]string{"-c", "config.yaml", "-baseline", filepath.Join(t.TempDir(), "none.json")}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "failed to open baseline") {
+		t.Errorf("Expected missing baseline error, got: %v", err)
+	}
+}
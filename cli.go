@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/urfave/cli/v2"
+)
+
+// command is a subcommand of the tool. A command either runs or groups further
+// subcommands, e.g. 'config validate'.
+type command struct {
+	name     string
+	aliases  []string
+	summary  string
+	flags    func() *flag.FlagSet // flags of the command, for help and completion
+	args     []string             // positional arguments offered by the completion
+	run      func(arguments []string, w io.Writer) error
+	commands []command
+}
+
+// toolCommands returns the subcommands of the tool
+func toolCommands() []command {
+	var configPath string
+	return []command{
+		{name: "check", summary: "validate the configured scripts (default without a subcommand)",
+			flags: func() *flag.FlagSet { return defaultFlagSet(&Args{}) }, run: runCheck},
+		{name: "plan", aliases: []string{"trace"}, summary: "print the commands the scripts would run",
+			flags: func() *flag.FlagSet { return traceFlagSet(&traceOptions{}) }, run: runTrace},
+		{name: "baseline", summary: "record the current findings as the accepted baseline",
+			flags: func() *flag.FlagSet { return baselineFlagSet(&baselineOptions{}) }, run: runBaseline},
+		{name: "diff", summary: "report the findings added and fixed since the baseline",
+			flags: func() *flag.FlagSet { return diffFlagSet(&baselineOptions{}) }, run: runDiff},
+		{name: "fix", summary: "rewrite the script lines of findings with a mechanical fix",
+			flags: func() *flag.FlagSet { return fixFlagSet(&fixOptions{}) }, run: runFix},
+		{name: "export-manifest", summary: "write the manifest of the files the scripts deploy, optionally signed",
+			flags: func() *flag.FlagSet { return manifestFlagSet(&manifestOptions{}) }, run: runExportManifest},
+		{name: "graph", summary: "write the graph of the scripts, utilities and referenced files as DOT or JSON",
+			flags: func() *flag.FlagSet { return graphFlagSet(&graphOptions{}) }, run: runGraph},
+		{name: "explain", summary: "explain a rule and how to fix or suppress its findings, or list the rules",
+			flags: explainFlagSet, args: ruleIDs(), run: runExplain},
+		{name: "init", summary: "write a starter configuration",
+			flags: func() *flag.FlagSet { return initFlagSet(&initOptions{}) }, run: runInit},
+		{name: "e2e", summary: "validate fixture repositories and compare their reports with the expected ones",
+			flags: func() *flag.FlagSet { return e2eFlagSet(&e2eOptions{}) }, run: runE2E},
+		{name: "serve", summary: "validate on HTTP requests",
+			flags: func() *flag.FlagSet { return serveFlagSet(&serveOptions{}) }, run: runServe},
+		{name: "config", summary: "configuration commands", commands: []command{
+			{name: "validate", summary: "load and validate the configuration without running the checks",
+				flags: func() *flag.FlagSet { return configValidateFlagSet(&Args{}) }, run: runConfigValidate},
+			{name: "schema", summary: "print the JSON Schema of the configuration, for editor completion",
+				flags: configSchemaFlagSet, run: runConfigSchema},
+		}},
+		{name: "completion", summary: "print a shell completion script",
+			flags: func() *flag.FlagSet { return completionFlagSet(&configPath) },
+			args:  append(append([]string{}, completionShells...), completionScriptsArg), run: runCompletion},
+		{name: "help", summary: "list the subcommands"},
+	}
+}
+
+// dispatch runs the subcommand named by the first argument. Without a subcommand, or
+// with a flag first, the arguments are those of check, as before subcommands existed.
+func dispatch(commands []command, arguments []string, w io.Writer) error {
+	return newApp(commands, w).Run(append([]string{completionProgram()}, arguments...))
+}
+
+// newApp returns the command line application routing the arguments to the commands.
+// The commands parse their arguments with their own flag sets (SkipFlagParsing), which
+// keeps the single-dash flags and the flag sets the completion scripts are built from.
+func newApp(commands []command, w io.Writer) *cli.App {
+	return &cli.App{
+		Name:            completionProgram(),
+		Usage:           "validate Teamcenter deployment scripts",
+		Writer:          w,
+		ErrWriter:       w,
+		HideHelp:        true,
+		HideVersion:     true,
+		SkipFlagParsing: true,
+		Commands:        cliCommands(commands, "", w),
+		Action: func(ctx *cli.Context) error {
+			if name := ctx.Args().First(); name != "" && !strings.HasPrefix(name, "-") {
+				return withExitCode(exitConfig, fmt.Errorf("unknown subcommand '%s' (see 'help')", name))
+			}
+			return runCheck(ctx.Args().Slice(), w)
+		},
+		// the exit codes are set by main from the errors returned
+		ExitErrHandler: func(*cli.Context, error) {},
+	}
+}
+
+// cliCommands returns the commands of the application, parent names the command
+// grouping them
+func cliCommands(commands []command, parent string, w io.Writer) []*cli.Command {
+	var appCommands []*cli.Command
+	for _, c := range commands {
+		c := c
+		cliCommand := &cli.Command{
+			Name:            c.name,
+			Aliases:         c.aliases,
+			Usage:           c.summary,
+			HideHelp:        true,
+			SkipFlagParsing: true,
+			Action: func(ctx *cli.Context) error {
+				return c.run(ctx.Args().Slice(), w)
+			},
+		}
+		switch {
+		case c.commands != nil:
+			name := strings.TrimSpace(parent + " " + c.name)
+			cliCommand.Subcommands = cliCommands(c.commands, name, w)
+			cliCommand.Action = func(ctx *cli.Context) error {
+				alternatives := strings.Join(commandNames(c.commands), ", ")
+				if !ctx.Args().Present() {
+					return withExitCode(exitConfig, fmt.Errorf("missing subcommand of '%s' (%s)", name, alternatives))
+				}
+				return withExitCode(exitConfig, fmt.Errorf("unknown subcommand '%s %s' (%s)", name, ctx.Args().First(), alternatives))
+			}
+		case c.name == "help" && parent == "":
+			cliCommand.Action = func(*cli.Context) error {
+				writeHelp(w, commands)
+				return nil
+			}
+		}
+		appCommands = append(appCommands, cliCommand)
+	}
+	return appCommands
+}
+
+// commandNames returns the names of the commands
+func commandNames(commands []command) []string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return names
+}
+
+// writeHelp writes the subcommands with their summaries
+func writeHelp(w io.Writer, commands []command) {
+	fmt.Fprintln(w, "Usage: <executable> [subcommand] [flags]")
+	fmt.Fprintln(w, "Subcommands:")
+	var write func(prefix string, commands []command)
+	write = func(prefix string, commands []command) {
+		for _, c := range commands {
+			name := strings.TrimSpace(prefix + " " + c.name)
+			if c.commands != nil {
+				write(name, c.commands)
+				continue
+			}
+			if len(c.aliases) > 0 {
+				name += " (" + strings.Join(c.aliases, ", ") + ")"
+			}
+			fmt.Fprintf(w, "  %-20s %s\n", name, c.summary)
+		}
+	}
+	write("", commands)
+	fmt.Fprintln(w, "Run '<executable> <subcommand> -h' for the flags of a subcommand.")
+}
+
+// runConfigValidate loads and validates the configuration, including the policy
+// version, without running the checks: config validate [-c config.yaml]
+func runConfigValidate(arguments []string, w io.Writer) error {
+	var args Args
+	if err := configValidateFlagSet(&args).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	configurationParameters, err := getConfigFrom(args.ConfigPath, args.Config)
+	if err != nil {
+		return err
+	}
+	contents := fmt.Sprintf("%d scripts", len(configurationParameters.Scripts))
+	if repositories := configurationParameters.Repositories; len(repositories) > 0 {
+		scripts := 0
+		for _, repo := range repositories {
+			scripts += len(repo.Parameters.Scripts)
+		}
+		contents = fmt.Sprintf("%d repositories, %d scripts", len(repositories), scripts)
+	}
+	fmt.Fprintf(w, "configuration '%s' is valid (%s)\n", args.ConfigPath, contents)
+	return nil
+}
+
+// configValidateFlagSet defines the flags of config validate into a
+func configValidateFlagSet(a *Args) *flag.FlagSet {
+	f := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	configFlags(f, &a.ConfigPath, &a.Config)
+	return f
+}
+
+// runConfigSchema prints the JSON Schema of the configuration file, generated from
+// the parameters the configuration is decoded into: config schema
+func runConfigSchema(arguments []string, w io.Writer) error {
+	if err := configSchemaFlagSet().Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	schema, err := analyzer.ConfigSchema()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, string(schema)); err != nil {
+		return withExitCode(exitIO, err)
+	}
+	return nil
+}
+
+// configSchemaFlagSet defines the flags of config schema, which has none
+func configSchemaFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("config schema", flag.ContinueOnError)
+}
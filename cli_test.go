@@ -0,0 +1,391 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// writeValidationFixture writes the scripts into a temporary source code root with a
+// configuration validating them, and returns the configuration path
+func writeValidationFixture(t *testing.T, scripts map[string]string, scriptsYAML string) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	root := filepath.Join(tempDir, "repo")
+	if err := os.MkdirAll(filepath.Join(root, "100-Config"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "100-Config", "a.xml"), []byte("<a/>"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to create script: %v", err)
+		}
+	}
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configYAML := "scripts:\n" + scriptsYAML + `path_parameters:
+  - xml_file
+source_code_root: '` + root + `'
+ignore_patterns:
+  global:
+    - 'deploy.*'
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	return configPath
+}
+
+func TestDispatch_Subcommands(t *testing.T) {
+	// What: Subcommands, aliases and nested subcommands are dispatched by name
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `scripts:
+  - filename: deploy.sh
+    target_os: linux
+path_parameters:
+  - input
+source_code_root: '/repo'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := dispatch(toolCommands(), []string{"config", "validate", "-c", configPath}, &out); err != nil {
+		t.Fatalf("config validate failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "is valid (1 scripts)") {
+		t.Errorf("Unexpected config validate output %q", out.String())
+	}
+
+	out.Reset()
+	if err := dispatch(toolCommands(), []string{"help"}, &out); err != nil {
+		t.Fatalf("help failed: %v", err)
+	}
+	for _, want := range []string{"check", "plan (trace)", "baseline", "diff", "fix", "export-manifest", "explain", "init", "serve", "config validate", "config schema", "completion"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("help is missing %q", want)
+		}
+	}
+}
+
+func TestDispatch_Errors(t *testing.T) {
+	// What: Unknown and incomplete subcommands are errors naming the alternatives
+	tests := []struct {
+		arguments []string
+		want      string
+	}{
+		{[]string{"bogus"}, "unknown subcommand 'bogus'"},
+		{[]string{"config"}, "missing subcommand of 'config' (validate, schema)"},
+		{[]string{"config", "show"}, "unknown subcommand 'config show' (validate, schema)"},
+	}
+	for _, tt := range tests {
+		err := dispatch(toolCommands(), tt.arguments, io.Discard)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("dispatch(%v) error = %v, want %q", tt.arguments, err, tt.want)
+		}
+	}
+}
+
+func TestRunConfigSchema(t *testing.T) {
+	// What: config schema prints a JSON Schema with the sections of the configuration
+	var out bytes.Buffer
+	if err := dispatch(toolCommands(), []string{"config", "schema"}, &out); err != nil {
+		t.Fatalf("config schema failed: %v", err)
+	}
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("config schema is not JSON: %v", err)
+	}
+	for _, key := range []string{"scripts", "path_parameters", "source_code_root", "network", "repositories"} {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("schema is missing %q", key)
+		}
+	}
+	if schema.Schema == "" {
+		t.Errorf("schema is missing $schema")
+	}
+}
+
+func TestDispatch_BareInvocationIsCheck(t *testing.T) {
+	// What: Flags without a subcommand run check, as before subcommands existed
+	err := dispatch(toolCommands(), []string{"-c", "config.yaml", "-format", "xml"}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected the invalid format error of check, got: %v", err)
+	}
+	err = dispatch(toolCommands(), []string{"check", "-c", "config.yaml", "-format", "xml"}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected the invalid format error of check, got: %v", err)
+	}
+}
+
+func TestRunCheck_Compact(t *testing.T) {
+	// What: check writes the compact report of the findings to its writer, error findings exit with 1
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	err := runCheck([]string{"-c", configPath, "-format", "compact"}, &out)
+	if exitCode(err) != exitFindings {
+		t.Errorf("Expected exit code %d for error findings, got %d (%v)", exitFindings, exitCode(err), err)
+	}
+	if !strings.Contains(out.String(), "deploy.sh:1:26: error: TCX010") {
+		t.Errorf("Expected the missing file finding, got %q", out.String())
+	}
+}
+
+func TestRunCheck_Filter(t *testing.T) {
+	// What: The report lists only the findings of the included rules under the path filter, the verdict counts all
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"200-Stylesheets/missing.xml\"\nplmxml_import -xml_file=100-Config/a.xml\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-include-rule", "missing-file", "-path-filter", "200-Stylesheets"}, &out)
+	if exitCode(err) != exitFindings {
+		t.Errorf("Expected exit code %d for error findings, got %d (%v)", exitFindings, exitCode(err), err)
+	}
+	if !strings.Contains(out.String(), "deploy.sh:2:26: error: TCX010") || strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Expected only the missing stylesheet, got %q", out.String())
+	}
+
+	err = runCheck([]string{"-c", configPath, "-include-rule", "TCX999"}, &out)
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "unknown rule 'TCX999'") {
+		t.Errorf("Expected an unknown rule error, got %v", err)
+	}
+}
+
+func TestRunCheck_Explain(t *testing.T) {
+	// What: -explain ends the report with the explanation of each reported rule, once per rule
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/other.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-explain"}, &out)
+	if exitCode(err) != exitFindings {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitFindings, exitCode(err), err)
+	}
+	report := out.String()
+	if !strings.Contains(report, "# explanations\nTCX010 missing-file (error)") || strings.Count(report, "Suppress:") != 1 {
+		t.Errorf("Expected one explanation of TCX010 after the findings, got %q", report)
+	}
+}
+
+func TestRunCheck_Ruleset(t *testing.T) {
+	// What: -ruleset lenient leaves out the unreferenced files, strict fails on them too, an unknown ruleset is refused
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-ruleset", "lenient"}, &out)
+	if exitCode(err) != exitFindings || strings.Contains(out.String(), "TCX020") || !strings.Contains(out.String(), "TCX010") {
+		t.Errorf("Expected only the missing file with lenient, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	runCheck([]string{"-c", configPath, "-format", "compact", "-ruleset", "strict"}, &out)
+	if !strings.Contains(out.String(), "error: TCX020") {
+		t.Errorf("Expected the unreferenced file with strict, got %q", out.String())
+	}
+
+	err = runCheck([]string{"-c", configPath, "-ruleset", "paranoid"}, &out)
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "invalid -ruleset 'paranoid'") {
+		t.Errorf("Expected an invalid ruleset error, got %v", err)
+	}
+}
+
+func TestRunCheck_PerfBudget(t *testing.T) {
+	// What: -perf-budget fails a clean run whose phases exceed the budget, an invalid budget is refused
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	if err := runCheck([]string{"-c", configPath, "-format", "compact", "-perf-budget", "total=1h"}, &out); err != nil {
+		t.Errorf("Expected the run within budget to pass, got %v: %q", err, out.String())
+	}
+
+	out.Reset()
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-perf-budget", "syntax=1h,total=1ns"}, &out)
+	if exitCode(err) != exitFindings || !strings.Contains(out.String(), "error: TCX041 'total' took") {
+		t.Errorf("Expected the exceeded total budget, got %q (%v)", out.String(), err)
+	}
+
+	err = runCheck([]string{"-c", configPath, "-perf-budget", "total"}, &out)
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "invalid -perf-budget") {
+		t.Errorf("Expected an invalid budget error, got %v", err)
+	}
+}
+
+func TestRunCheck_QuietRestoresConsole(t *testing.T) {
+	// What: A run writing a report instead of the log gives the console back to the next run
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	var buf bytes.Buffer
+	logger.SetConsoleOutput(&buf)
+	defer func() {
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+	}()
+
+	runCheck([]string{"-c", configPath, "-format", "compact"}, &bytes.Buffer{})
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no log of the compact run, got %q", buf.String())
+	}
+	runCheck([]string{"-c", configPath}, &bytes.Buffer{})
+	if !strings.Contains(buf.String(), "100-Config/missing.xml") {
+		t.Errorf("Expected the log of the text run on the console, got %q", buf.String())
+	}
+}
+
+func TestRunCheck_JSONLog(t *testing.T) {
+	// What: -log-format json writes findings as JSON lines with their rule, script, line and path
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	var buf bytes.Buffer
+	logger.SetConsoleOutput(&buf)
+	defer func() {
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+	}()
+
+	runCheck([]string{"-c", configPath, "-log-format", "json"}, &bytes.Buffer{})
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON log line %q: %v", line, err)
+		}
+		if entry["rule"] == "TCX010" {
+			found = entry["script"] == "deploy.sh" && entry["line"] == float64(1) && entry["path"] == "100-Config/missing.xml"
+		}
+	}
+	if !found {
+		t.Errorf("Expected the missing file with its fields, got %q", buf.String())
+	}
+
+	err := runCheck([]string{"-c", configPath, "-log-format", "xml"}, &buf)
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "invalid log format 'xml'") {
+		t.Errorf("Expected an invalid log format error, got %v", err)
+	}
+}
+
+func TestRunCheck_FailFast(t *testing.T) {
+	// What: -fail-fast stops at the first error, the other scripts are not validated
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/other.xml\"\n",
+		"second.sh": "plmxml_import -xml_file=\"100-Config/third.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n  - filename: second.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	err := runCheck([]string{"-c", configPath, "-format", "compact", "-fail-fast"}, &out)
+	if exitCode(err) != exitFindings {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitFindings, exitCode(err), err)
+	}
+	if strings.Count(out.String(), "\n") != 1 || !strings.Contains(out.String(), "missing.xml") {
+		t.Errorf("Expected only the first missing file, got %q", out.String())
+	}
+}
+
+func TestRunCheck_Dedupe(t *testing.T) {
+	// What: A missing file of several scripts is reported once with the scripts, -no-dedupe reports it per script
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh":        "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+		"deploy.second.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n  - filename: deploy.second.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	runCheck([]string{"-c", configPath, "-format", "compact"}, &out)
+	if strings.Count(out.String(), "\n") != 1 || !strings.Contains(out.String(), "(2 scripts: deploy.sh, deploy.second.sh)") {
+		t.Errorf("Expected one deduplicated finding, got %q", out.String())
+	}
+
+	out.Reset()
+	runCheck([]string{"-c", configPath, "-format", "compact", "-no-dedupe"}, &out)
+	if strings.Count(out.String(), "\n") != 2 {
+		t.Errorf("Expected a finding per script, got %q", out.String())
+	}
+}
+
+func TestRunCheck_Snapshot(t *testing.T) {
+	// What: -snapshot cross-checks the environment export with the deployed items
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "$TC_ROOT/install/tem.sh -update -templates=nw4template -path=\"100-Config\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	snapshot := filepath.Join(t.TempDir(), "prod.csv")
+	if err := os.WriteFile(snapshot, []byte("type,name\ntemplate,nw4legacy\n"), 0644); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	var out bytes.Buffer
+	runCheck([]string{"-c", configPath, "-format", "compact", "-snapshot", snapshot}, &out)
+	for _, want := range []string{
+		snapshot + ":2:1: info: TCX060 template 'nw4legacy' is installed in the environment but not deployed by any script",
+		"deploy.sh:1:1: warning: TCX061 template 'nw4template' is deployed but not installed in the environment",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in %q", want, out.String())
+		}
+	}
+}
+
+func TestRunCheck_TemplatedScript(t *testing.T) {
+	// What: Template expressions match repository files and template statements are skipped
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "{% if env == 'prod' %}\nplmxml_import -xml_file=\"100-Config/{{ name }}.xml\"\n{% endif %}\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\ntemplating:\n  mode: wildcard\n")
+
+	var out bytes.Buffer
+	if err := runCheck([]string{"-c", configPath, "-format", "compact"}, &out); err != nil {
+		t.Errorf("Expected the templated script to pass, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no findings, got %q", out.String())
+	}
+}
+
+func TestRunCheck_ArchivedScript(t *testing.T) {
+	// What: A script inside a zip is validated against the archive contents plus the source code root
+	configPath := writeValidationFixture(t, nil, "  - filename: 'deploy.zip!release/install.sh'\n    target_os: linux\n")
+	file, err := os.Create(filepath.Join(filepath.Dir(configPath), "repo", "deploy.zip"))
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	w := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"release/install.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\nplmxml_import -xml_file=\"payload/b.xml\"\nplmxml_import -xml_file=\"payload/c.xml\"\n",
+		"payload/b.xml":      "<b/>",
+	} {
+		header := &zip.FileHeader{Name: name}
+		header.SetMode(0755)
+		entry, _ := w.CreateHeader(header)
+		entry.Write([]byte(content))
+	}
+	w.Close()
+	file.Close()
+
+	var out bytes.Buffer
+	runCheck([]string{"-c", configPath, "-format", "compact"}, &out)
+	if want := "deploy.zip!release/install.sh:3:26: error: TCX010"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected %q in %q", want, out.String())
+	}
+	if strings.Count(out.String(), "\n") != 1 {
+		t.Errorf("Expected only the reference missing from the archive, got %q", out.String())
+	}
+}
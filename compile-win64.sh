@@ -1,2 +1,7 @@
 #!/bin/sh
-GOOS=windows GOARCH=amd64 go build  -ldflags "-w -s" -o bin/scripts-check.exe main.go
\ No newline at end of file
+# VERSION is embedded as the tool version checked against min_tool_version (default: the one in version.go)
+LDFLAGS="-w -s"
+if [ -n "$VERSION" ]; then
+	LDFLAGS="$LDFLAGS -X main.version=$VERSION"
+fi
+GOOS=windows GOARCH=amd64 go build -ldflags "$LDFLAGS" -o bin/scripts-check.exe .
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Shells a completion script can be generated for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// Argument of the completion subcommand listing the configured script names, called
+// back by the completion scripts
+const completionScriptsArg = "scripts"
+
+// Values completed for flags with a fixed set of values
+var flagValues = map[string][]string{
+	"format":     {"text", "compact", "owners"},
+	"l":          {"info", "error", "debug"},
+	"log-format": {"text", "json"},
+	"ruleset":    {"lenient", "standard", "strict"},
+}
+
+// Flags completed with file names and with the configured script names
+var (
+	fileFlags   = map[string]bool{"c": true, "o": true, "baseline": true, "snapshot": true, "sign-key": true, "audit-log": true}
+	scriptFlags = map[string]bool{"s": true}
+)
+
+// completionCommand is a subcommand with its positional arguments and flags, the
+// validation itself has no name
+type completionCommand struct {
+	name  string
+	args  []string
+	flags []*flag.Flag
+}
+
+// completionFlagSet defines the flags of 'completion scripts' into configPath
+func completionFlagSet(configPath *string) *flag.FlagSet {
+	f := flag.NewFlagSet("completion", flag.ContinueOnError)
+	f.StringVar(configPath, "c", "config.yaml", "path of the configuration file")
+	return f
+}
+
+// completionCommands returns the subcommands with their flags, read from the command
+// table so the completion follows the subcommands and flags the tool accepts. An alias
+// is completed like its command, the flags of nested subcommands like their parent's.
+func completionCommands() []completionCommand {
+	commands := toolCommands()
+	root := completionCommand{}
+	for _, c := range commands {
+		if c.name == "check" {
+			root.flags = flagList(c.flags())
+		}
+	}
+	completions := []completionCommand{root}
+	for _, c := range commands {
+		completion := completionCommand{args: c.args}
+		if c.flags != nil {
+			completion.flags = flagList(c.flags())
+		}
+		for _, sub := range c.commands {
+			completion.args = append(completion.args, sub.name)
+			if sub.flags != nil {
+				completion.flags = append(completion.flags, flagList(sub.flags())...)
+			}
+		}
+		for _, name := range append([]string{c.name}, c.aliases...) {
+			completion.name = name
+			completions[0].args = append(completions[0].args, name)
+			completions = append(completions, completion)
+		}
+	}
+	return completions
+}
+
+// flagList returns the flags of a flag set, sorted by name
+func flagList(f *flag.FlagSet) []*flag.Flag {
+	var flags []*flag.Flag
+	f.VisitAll(func(fl *flag.Flag) { flags = append(flags, fl) })
+	return flags
+}
+
+// isBoolFlag reports whether a flag takes no value
+func isBoolFlag(fl *flag.Flag) bool {
+	b, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// completionProgram returns the name the executable is invoked with, without .exe
+func completionProgram() string {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+}
+
+// runCompletion prints the completion script for a shell, or the configured script
+// names: completion <bash|zsh|fish|powershell> | completion scripts [-c config.yaml]
+func runCompletion(arguments []string, w io.Writer) error {
+	if len(arguments) == 0 {
+		return withExitCode(exitConfig, fmt.Errorf("missing shell (must be one of %s)", strings.Join(completionShells, ", ")))
+	}
+	if arguments[0] == completionScriptsArg {
+		var configPath string
+		if err := completionFlagSet(&configPath).Parse(arguments[1:]); err != nil {
+			return withExitCode(exitConfig, err)
+		}
+		return writeScriptNames(w, configPath)
+	}
+	return writeCompletion(w, arguments[0], completionProgram())
+}
+
+// writeScriptNames writes the unique script filenames of the configuration, of all
+// repositories of a batch configuration, one per line
+func writeScriptNames(w io.Writer, configPath string) error {
+	configurationParameters, err := getConfig(configPath)
+	if err != nil {
+		return err
+	}
+	scripts := configurationParameters.Scripts
+	for _, repo := range configurationParameters.Repositories {
+		scripts = append(scripts, repo.Parameters.Scripts...)
+	}
+	seen := make(map[string]bool, len(scripts))
+	for _, script := range scripts {
+		if seen[script.Filename] {
+			continue
+		}
+		seen[script.Filename] = true
+		fmt.Fprintln(w, script.Filename)
+	}
+	return nil
+}
+
+// writeCompletion writes the completion script of shell for the executable prog
+func writeCompletion(w io.Writer, shell, prog string) error {
+	commands := completionCommands()
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, prog, commands)
+	case "zsh":
+		// zsh runs the bash completion through its bash compatibility layer
+		fmt.Fprintf(w, "# zsh completion for %s, load with: source <(%s completion zsh)\n", prog, prog)
+		fmt.Fprintln(w, "autoload -U +X compinit && compinit")
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(w, prog, commands)
+	case "fish":
+		writeFishCompletion(w, prog, commands)
+	case "powershell":
+		writePowerShellCompletion(w, prog, commands)
+	default:
+		return withExitCode(exitConfig, fmt.Errorf("unsupported shell '%s' (must be one of %s)", shell, strings.Join(completionShells, ", ")))
+	}
+	return nil
+}
+
+// completionWords returns the positional arguments and the flags of a command
+func completionWords(c completionCommand) []string {
+	words := append([]string{}, c.args...)
+	for _, fl := range c.flags {
+		words = append(words, "-"+fl.Name)
+	}
+	return words
+}
+
+// valueFlags returns the flags taking a value of all commands, by name, in the order
+// they are defined
+func valueFlags(commands []completionCommand) []*flag.Flag {
+	var flags []*flag.Flag
+	seen := make(map[string]bool)
+	for _, c := range commands {
+		for _, fl := range c.flags {
+			if !isBoolFlag(fl) && !seen[fl.Name] {
+				seen[fl.Name] = true
+				flags = append(flags, fl)
+			}
+		}
+	}
+	return flags
+}
+
+// completionFunc returns prog as a shell function name
+func completionFunc(prog string) string {
+	return "__" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, prog) + "_complete"
+}
+
+func writeBashCompletion(w io.Writer, prog string, commands []completionCommand) {
+	fn := completionFunc(prog)
+	var subcommands []string
+	for _, c := range commands {
+		if c.name != "" {
+			subcommands = append(subcommands, c.name)
+		}
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s, load with: source <(%s completion bash)\n", prog, prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w, `    local command="" config="" i`)
+	fmt.Fprintln(w, `    for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(w, `        case "${COMP_WORDS[i]}" in`)
+	fmt.Fprintf(w, "            %s) [[ -z \"$command\" ]] && command=\"${COMP_WORDS[i]}\" ;;\n", strings.Join(subcommands, "|"))
+	fmt.Fprintln(w, `            -c|--c) config="${COMP_WORDS[i+1]}" ;;`)
+	fmt.Fprintln(w, `        esac`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w, `    case "$prev" in`)
+	for _, fl := range valueFlags(commands) {
+		fmt.Fprintf(w, "        -%s|--%s) ", fl.Name, fl.Name)
+		switch {
+		case fileFlags[fl.Name]:
+			fmt.Fprint(w, `COMPREPLY=($(compgen -f -- "$cur"))`)
+		case scriptFlags[fl.Name]:
+			fmt.Fprintf(w, `COMPREPLY=($(compgen -W "$(%s %s %s ${config:+-c "$config"} 2>/dev/null)" -- "$cur"))`, prog, "completion", completionScriptsArg)
+		case flagValues[fl.Name] != nil:
+			fmt.Fprintf(w, `COMPREPLY=($(compgen -W "%s" -- "$cur"))`, strings.Join(flagValues[fl.Name], " "))
+		default:
+			fmt.Fprint(w, "COMPREPLY=()")
+		}
+		fmt.Fprintln(w, "; return ;;")
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w, `    case "$command" in`)
+	// The validation itself comes last, matching any other command
+	for i := len(commands) - 1; i >= 0; i-- {
+		name := commands[i].name
+		if name == "" {
+			name = "*"
+		}
+		fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", name, strings.Join(completionWords(commands[i]), " "))
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -o default -F %s %s %s.exe\n", fn, prog, prog)
+}
+
+// fishQuote quotes s as a single-quoted fish string
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func writeFishCompletion(w io.Writer, prog string, commands []completionCommand) {
+	fn := completionFunc(prog)
+	fmt.Fprintf(w, "# fish completion for %s, load with: %s completion fish | source\n", prog, prog)
+	fmt.Fprintf(w, "function %s_scripts\n", fn)
+	fmt.Fprintln(w, "    set -l tokens (commandline -opc)")
+	fmt.Fprintln(w, "    set -l config")
+	fmt.Fprintln(w, "    for i in (seq (count $tokens))")
+	fmt.Fprintln(w, "        if contains -- $tokens[$i] -c --c; and test $i -lt (count $tokens)")
+	fmt.Fprintln(w, "            set config -c $tokens[(math $i + 1)]")
+	fmt.Fprintln(w, "        end")
+	fmt.Fprintln(w, "    end")
+	fmt.Fprintf(w, "    %s completion %s $config 2>/dev/null\n", prog, completionScriptsArg)
+	fmt.Fprintln(w, "end")
+	fmt.Fprintf(w, "complete -c %s -f\n", prog)
+
+	for _, c := range commands {
+		condition := "__fish_use_subcommand"
+		if c.name != "" {
+			condition = "__fish_seen_subcommand_from " + c.name
+		}
+		condition = fishQuote(condition)
+		if len(c.args) > 0 {
+			fmt.Fprintf(w, "complete -c %s -n %s -a %s\n", prog, condition, fishQuote(strings.Join(c.args, " ")))
+		}
+		for _, fl := range c.flags {
+			fmt.Fprintf(w, "complete -c %s -n %s -o %s", prog, condition, fl.Name)
+			switch {
+			case isBoolFlag(fl):
+			case fileFlags[fl.Name]:
+				fmt.Fprint(w, " -r -F")
+			case scriptFlags[fl.Name]:
+				fmt.Fprintf(w, " -x -a %s", fishQuote("("+fn+"_scripts)"))
+			case flagValues[fl.Name] != nil:
+				fmt.Fprintf(w, " -x -a %s", fishQuote(strings.Join(flagValues[fl.Name], " ")))
+			default:
+				fmt.Fprint(w, " -x")
+			}
+			fmt.Fprintf(w, " -d %s\n", fishQuote(fl.Usage))
+		}
+	}
+}
+
+// powerShellList returns words as a PowerShell list of single-quoted strings
+func powerShellList(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = "'" + strings.ReplaceAll(word, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func writePowerShellCompletion(w io.Writer, prog string, commands []completionCommand) {
+	var subcommands []string
+	for _, c := range commands {
+		if c.name != "" {
+			subcommands = append(subcommands, c.name)
+		}
+	}
+
+	fmt.Fprintf(w, "# PowerShell completion for %s, load with: %s completion powershell | Out-String | Invoke-Expression\n", prog, prog)
+	fmt.Fprintf(w, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", powerShellList([]string{prog, prog + ".exe"}))
+	fmt.Fprintln(w, "    param($wordToComplete, $commandAst, $cursorPosition)")
+	fmt.Fprintln(w, "    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })")
+	fmt.Fprintln(w, "    if ($wordToComplete) { $words = @($words | Select-Object -SkipLast 1) }")
+	fmt.Fprintln(w, "    $prev = $words[-1]")
+	fmt.Fprintf(w, "    $command = @($words | Select-Object -Skip 1 | Where-Object { $_ -in @(%s) })[0]\n", powerShellList(subcommands))
+	fmt.Fprintln(w, "    $config = @()")
+	fmt.Fprintln(w, "    $i = [array]::LastIndexOf($words, '-c')")
+	fmt.Fprintln(w, "    if ($i -ge 0 -and $i + 1 -lt $words.Count) { $config = @('-c', $words[$i + 1]) }")
+	fmt.Fprintln(w, "    $candidates = switch ($prev) {")
+	for _, fl := range valueFlags(commands) {
+		fmt.Fprintf(w, "        { $_ -in '-%s', '--%s' } { ", fl.Name, fl.Name)
+		switch {
+		case fileFlags[fl.Name]:
+			// Nothing returned falls back to the file name completion
+			fmt.Fprint(w, "return")
+		case scriptFlags[fl.Name]:
+			fmt.Fprintf(w, "& ($commandAst.CommandElements[0].ToString()) completion %s @config 2>$null; break", completionScriptsArg)
+		case flagValues[fl.Name] != nil:
+			fmt.Fprintf(w, "%s; break", powerShellList(flagValues[fl.Name]))
+		default:
+			fmt.Fprint(w, "return")
+		}
+		fmt.Fprintln(w, " }")
+	}
+	fmt.Fprintln(w, "        default {")
+	fmt.Fprintln(w, "            switch ($command) {")
+	for _, c := range commands {
+		name := "default"
+		if c.name != "" {
+			name = "'" + c.name + "'"
+		}
+		fmt.Fprintf(w, "                %s { %s }\n", name, powerShellList(completionWords(c)))
+	}
+	fmt.Fprintln(w, "            }")
+	fmt.Fprintln(w, "        }")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {")
+	fmt.Fprintln(w, "        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompletion_Shells(t *testing.T) {
+	// What: Every supported shell gets a script registering the program, its subcommands and flags
+	for _, shell := range completionShells {
+		var out bytes.Buffer
+		if err := writeCompletion(&out, shell, "scripts-check"); err != nil {
+			t.Fatalf("writeCompletion(%s) failed: %v", shell, err)
+		}
+		script := out.String()
+		for _, want := range []string{"scripts-check", "trace", "completion", "format", "print-version", "completion scripts"} {
+			if !strings.Contains(script, want) {
+				t.Errorf("%s completion is missing %q", shell, want)
+			}
+		}
+	}
+}
+
+func TestWriteCompletion_FlagValues(t *testing.T) {
+	// What: Flags with a fixed set of values complete the values, -s completes the configured scripts
+	var out bytes.Buffer
+	if err := writeCompletion(&out, "bash", "scripts-check"); err != nil {
+		t.Fatalf("writeCompletion() failed: %v", err)
+	}
+	script := out.String()
+	for _, want := range []string{
+		`-format|--format) COMPREPLY=($(compgen -W "text compact owners" -- "$cur"))`,
+		`-l|--l) COMPREPLY=($(compgen -W "info error debug" -- "$cur"))`,
+		`-s|--s) COMPREPLY=($(compgen -W "$(scripts-check completion scripts ${config:+-c "$config"} 2>/dev/null)" -- "$cur"))`,
+		`trace) COMPREPLY=($(compgen -W "-c -config-sha256 -config-token-env -env -s" -- "$cur"))`,
+		"complete -o default -F __scripts_check_complete scripts-check scripts-check.exe",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("bash completion is missing %q", want)
+		}
+	}
+}
+
+func TestWriteCompletion_FollowsFlagSets(t *testing.T) {
+	// What: Every flag of the validation and of trace is offered in the completion
+	var out bytes.Buffer
+	if err := writeCompletion(&out, "fish", "scripts-check"); err != nil {
+		t.Fatalf("writeCompletion() failed: %v", err)
+	}
+	script := out.String()
+	for _, fl := range flagList(defaultFlagSet(&Args{})) {
+		if !strings.Contains(script, "'__fish_use_subcommand' -o "+fl.Name+" ") {
+			t.Errorf("fish completion is missing flag -%s", fl.Name)
+		}
+	}
+	for _, fl := range flagList(traceFlagSet(&traceOptions{})) {
+		if !strings.Contains(script, "'__fish_seen_subcommand_from trace' -o "+fl.Name+" ") {
+			t.Errorf("fish completion is missing trace flag -%s", fl.Name)
+		}
+	}
+}
+
+func TestWriteCompletion_UnsupportedShell(t *testing.T) {
+	// What: An unknown shell is an error
+	var out bytes.Buffer
+	if err := writeCompletion(&out, "tcsh", "scripts-check"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+	if err := runCompletion(nil, &out); err == nil {
+		t.Error("Expected error for missing shell")
+	}
+}
+
+func TestRunCompletion_Scripts(t *testing.T) {
+	// What: 'completion scripts' lists the unique script names of all repositories
+	configPath := filepath.Join(t.TempDir(), "batch.yaml")
+	content := `path_parameters:
+  - input
+scripts:
+  - filename: deploy.bat
+    target_os: windows
+repositories:
+  - name: first
+    source_code_root: '/repos/first'
+  - name: second
+    source_code_root: '/repos/second'
+    scripts:
+      - filename: deploy.bat
+        target_os: windows
+      - filename: deploy.sh
+        target_os: linux
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runCompletion([]string{"scripts", "-c", configPath}, &out); err != nil {
+		t.Fatalf("runCompletion() failed: %v", err)
+	}
+	if out.String() != "deploy.bat\ndeploy.sh\n" {
+		t.Errorf("Expected the unique script names, got %q", out.String())
+	}
+}
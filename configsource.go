@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// configSource describes how the configuration is read: -c is a local file or an
+// http(s) URL of a centrally managed policy
+type configSource struct {
+	TokenEnv string // environment variable with the bearer token sent to the config server
+	SHA256   string // expected SHA-256 checksum of the configuration, hex encoded
+	Env      string // profile of the configuration applied over its base parameters
+}
+
+// Largest configuration accepted
+const maxConfigSize = 4 << 20
+
+// Client fetching configuration URLs
+var configHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// isConfigURL reports whether the configuration location is an http(s) URL
+func isConfigURL(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// readConfig returns the configuration document at location, verified against the
+// pinned checksum when one is given
+func readConfig(location string, source configSource) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if isConfigURL(location) {
+		data, err = fetchConfig(location, source)
+	} else {
+		// Check if file exists first for better error message
+		if _, statErr := os.Stat(location); os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("configuration file '%s' not found", location)
+		}
+		data, err = os.ReadFile(location)
+		if err != nil {
+			err = fmt.Errorf("error reading configuration file '%s': %w", location, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if source.SHA256 != "" {
+		if err := verifyChecksum(data, source.SHA256); err != nil {
+			return nil, fmt.Errorf("configuration '%s': %w", location, err)
+		}
+	}
+	return data, nil
+}
+
+// fetchConfig downloads the configuration. The bearer token is only sent over https.
+func fetchConfig(url string, source configSource) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration URL '%s': %w", url, err)
+	}
+	if source.TokenEnv != "" {
+		token, ok := os.LookupEnv(source.TokenEnv)
+		if !ok {
+			return nil, fmt.Errorf("environment variable '%s' with the configuration server token is not set", source.TokenEnv)
+		}
+		if !strings.HasPrefix(strings.ToLower(url), "https://") {
+			return nil, fmt.Errorf("refusing to send the configuration server token over plain http to '%s'", url)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := configHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configuration '%s': %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching configuration '%s': unexpected response '%s'", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configuration '%s': %w", url, err)
+	}
+	if len(data) > maxConfigSize {
+		return nil, fmt.Errorf("configuration '%s' exceeds %d bytes", url, maxConfigSize)
+	}
+	return data, nil
+}
+
+// verifyChecksum compares the SHA-256 checksum of data with the expected hex value
+func verifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const remoteConfig = `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+`
+
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// configServer serves the configuration over https, requiring the bearer token when set
+func configServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/tcx/validate.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(remoteConfig))
+	}))
+	originalClient := configHTTPClient
+	configHTTPClient = server.Client()
+	t.Cleanup(func() {
+		configHTTPClient = originalClient
+		server.Close()
+	})
+	return server
+}
+
+func TestGetConfigFrom_URL(t *testing.T) {
+	// What: The configuration is fetched from a URL with the bearer token and the pinned checksum
+	server := configServer(t, "secret")
+	t.Setenv("TCX_CONFIG_TOKEN", "secret")
+
+	config, err := getConfigFrom(server.URL+"/tcx/validate.yaml", configSource{TokenEnv: "TCX_CONFIG_TOKEN", SHA256: checksum(remoteConfig)})
+	if err != nil {
+		t.Fatalf("getConfigFrom() failed: %v", err)
+	}
+	if config.SourceCodeRoot != "/test/path" {
+		t.Errorf("Expected source_code_root '/test/path', got '%s'", config.SourceCodeRoot)
+	}
+}
+
+func TestGetConfigFrom_URLErrors(t *testing.T) {
+	// What: Missing tokens, rejected requests, unknown paths and checksum mismatches are errors
+	server := configServer(t, "secret")
+	t.Setenv("TCX_CONFIG_TOKEN", "wrong")
+	t.Setenv("TCX_GOOD_TOKEN", "secret")
+
+	tests := []struct {
+		name   string
+		url    string
+		source configSource
+		want   string
+	}{
+		{"token variable not set", server.URL + "/tcx/validate.yaml", configSource{TokenEnv: "TCX_UNSET_TOKEN"}, "TCX_UNSET_TOKEN"},
+		{"unauthorized", server.URL + "/tcx/validate.yaml", configSource{TokenEnv: "TCX_CONFIG_TOKEN"}, "401"},
+		{"not found", server.URL + "/missing.yaml", configSource{TokenEnv: "TCX_GOOD_TOKEN"}, "404"},
+		{"token over http", "http://config.example.com/validate.yaml", configSource{TokenEnv: "TCX_CONFIG_TOKEN"}, "plain http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getConfigFrom(tt.url, tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetConfigFrom_ChecksumMismatch(t *testing.T) {
+	// What: A local configuration not matching the pinned checksum is rejected
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(remoteConfig), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := getConfigFrom(configPath, configSource{SHA256: strings.ToUpper(checksum(remoteConfig))}); err != nil {
+		t.Errorf("Expected matching checksum to be accepted, got %v", err)
+	}
+	_, err := getConfigFrom(configPath, configSource{SHA256: checksum("other")})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected checksum mismatch error, got %v", err)
+	}
+}
+
+func TestIsConfigURL(t *testing.T) {
+	// What: http(s) URLs are fetched, anything else is a file path
+	for location, want := range map[string]bool{
+		"https://config-server/tcx/validate.yaml": true,
+		"HTTP://config-server/validate.yaml":      true,
+		"config.yaml":                             false,
+		`C:\configs\validate.yaml`:                false,
+	} {
+		if got := isConfigURL(location); got != want {
+			t.Errorf("isConfigURL(%q) = %v, want %v", location, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// Files of an e2e fixture directory, besides the repository tree its configuration
+// points to with a 'source_code_root' relative to the fixture
+const (
+	e2eConfigFile   = "config.yaml"
+	e2eExpectedFile = "expected-report.json"
+)
+
+// e2eOptions are the command-line parameters of the e2e subcommand
+type e2eOptions struct {
+	Update bool
+}
+
+// e2eFlagSet defines the flags of the e2e subcommand into o
+func e2eFlagSet(o *e2eOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("e2e", flag.ContinueOnError)
+	f.BoolVar(&o.Update, "update", false, "write the report of each fixture as its expected report instead of comparing")
+	return f
+}
+
+// e2eReport is the report of a fixture, compared with its expected-report.json
+type e2eReport struct {
+	Passed   bool                     `json:"passed"`
+	Findings []report.BaselineFinding `json:"findings"`
+}
+
+// runE2E validates each fixture and compares its report with the expected one, or
+// records it with -update: e2e [-update] FIXTURE_DIR...
+// A directory without config.yaml holds fixtures in its subdirectories.
+func runE2E(arguments []string, w io.Writer) error {
+	var o e2eOptions
+	f := e2eFlagSet(&o)
+	if err := f.Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	if f.NArg() == 0 {
+		return withExitCode(exitConfig, fmt.Errorf("missing fixture directory (e2e [-update] FIXTURE_DIR...)"))
+	}
+	fixtures, err := e2eFixtures(f.Args())
+	if err != nil {
+		return withExitCode(exitIO, err)
+	}
+
+	failed := 0
+	for _, dir := range fixtures {
+		actual, err := runFixture(dir)
+		if err != nil {
+			return fmt.Errorf("fixture '%s': %w", dir, err)
+		}
+		if o.Update {
+			if err := writeE2EReport(filepath.Join(dir, e2eExpectedFile), actual); err != nil {
+				return withExitCode(exitIO, err)
+			}
+			fmt.Fprintf(w, "updated %s (%d finding(s))\n", dir, len(actual.Findings))
+			continue
+		}
+		expected, err := readE2EReport(filepath.Join(dir, e2eExpectedFile))
+		if err != nil {
+			return withExitCode(exitIO, err)
+		}
+		differences := diffE2EReports(expected, actual)
+		if len(differences) == 0 {
+			fmt.Fprintf(w, "ok   %s\n", dir)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL %s\n", dir)
+		for _, line := range differences {
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+	if failed > 0 {
+		return withExitCode(exitFindings, fmt.Errorf("%d of %d fixture(s) differ from their expected report", failed, len(fixtures)))
+	}
+	return nil
+}
+
+// e2eFixtures returns the fixture directories of the arguments, sorted
+func e2eFixtures(dirs []string) ([]string, error) {
+	var fixtures []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, e2eConfigFile)); err == nil {
+			fixtures = append(fixtures, dir)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*", e2eConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no %s in '%s' or its subdirectories", e2eConfigFile, dir)
+		}
+		for _, match := range matches {
+			fixtures = append(fixtures, filepath.Dir(match))
+		}
+	}
+	sort.Strings(fixtures)
+	return fixtures, nil
+}
+
+// runFixture runs the full validation of a fixture without console log. The source
+// code roots are relative to the fixture directory, and the directory is left out of
+// the messages, so the report does not depend on where the fixture is checked out.
+func runFixture(dir string) (e2eReport, error) {
+	configurationParameters, err := getConfig(filepath.Join(dir, e2eConfigFile))
+	if err != nil {
+		return e2eReport{}, err
+	}
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return e2eReport{}, withExitCode(exitIO, err)
+	}
+	configurationParameters.SourceCodeRoot = fixturePath(absolute, configurationParameters.SourceCodeRoot)
+	for i := range configurationParameters.Repositories {
+		repo := &configurationParameters.Repositories[i].Parameters
+		repo.SourceCodeRoot = fixturePath(absolute, repo.SourceCodeRoot)
+	}
+
+	results, err := validate(Args{LogLevel: "error"}, configurationParameters, true)
+	if failure := validationFailure(results); results == nil || failure != nil {
+		if failure != nil {
+			err = failure
+		}
+		return e2eReport{}, err
+	}
+	actual := e2eReport{Passed: err == nil, Findings: report.NewBaseline(results).Findings}
+	for _, r := range results {
+		actual.Passed = actual.Passed && r.Result.Summary.Passed
+	}
+	for i := range actual.Findings {
+		f := &actual.Findings[i].Finding
+		f.Message = stripFixtureDir(f.Message, absolute)
+		f.Suggestion = stripFixtureDir(f.Suggestion, absolute)
+	}
+	return actual, nil
+}
+
+// fixturePath resolves a path of the fixture configuration relative to its directory
+func fixturePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// stripFixtureDir replaces the fixture directory in a message with '.'
+func stripFixtureDir(text, dir string) string {
+	return strings.ReplaceAll(text, dir, ".")
+}
+
+// writeE2EReport writes the report as indented JSON
+func writeE2EReport(path string, r e2eReport) error {
+	if r.Findings == nil {
+		r.Findings = []report.BaselineFinding{}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write expected report: %w", err)
+	}
+	return nil
+}
+
+// readE2EReport reads a report written by writeE2EReport
+func readE2EReport(path string) (e2eReport, error) {
+	var r e2eReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, fmt.Errorf("failed to read expected report (record it with -update): %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("invalid expected report '%s': %w", path, err)
+	}
+	return r, nil
+}
+
+// diffE2EReports returns the differences of the reports: the outcome, and the
+// findings expected but not reported (-) and reported but not expected (+), in the
+// compact format. Findings are compared on all their fields, lines included.
+func diffE2EReports(expected, actual e2eReport) []string {
+	var differences []string
+	if expected.Passed != actual.Passed {
+		differences = append(differences, fmt.Sprintf("passed: expected %v, got %v", expected.Passed, actual.Passed))
+	}
+	reported := make(map[string]int, len(actual.Findings))
+	for _, f := range actual.Findings {
+		reported[e2eKey(f)]++
+	}
+	for _, f := range expected.Findings {
+		key := e2eKey(f)
+		if reported[key] > 0 {
+			reported[key]--
+			continue
+		}
+		differences = append(differences, "- "+report.BaselineLine(f))
+	}
+	for _, f := range actual.Findings {
+		key := e2eKey(f)
+		if reported[key] == 0 {
+			continue
+		}
+		reported[key]--
+		differences = append(differences, "+ "+report.BaselineLine(f))
+	}
+	return differences
+}
+
+// e2eKey identifies a finding by all its fields
+func e2eKey(f report.BaselineFinding) string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
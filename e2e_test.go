@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+func TestRunE2E_Fixtures(t *testing.T) {
+	// What: The fixtures of testdata/e2e report what they expect
+	var out bytes.Buffer
+	if err := runE2E([]string{filepath.Join("testdata", "e2e")}, &out); err != nil {
+		t.Fatalf("runE2E failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "ok   "+filepath.Join("testdata", "e2e", "missing-file")) {
+		t.Errorf("Expected the fixture to pass, got %q", out.String())
+	}
+}
+
+func TestRunE2E_UpdateAndDiffer(t *testing.T) {
+	// What: -update records the report of a fixture, a changed script then fails with its findings
+	dir := t.TempDir()
+	root := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(filepath.Join(root, "100-Config"), 0755); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	writeFile := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+	writeFile(filepath.Join(root, "100-Config", "a.xml"), "<a/>")
+	writeFile(filepath.Join(root, "deploy.sh"), "plmxml_import -xml_file=\"100-Config/a.xml\"\n")
+	writeFile(filepath.Join(dir, e2eConfigFile), "scripts:\n  - filename: deploy.sh\n    target_os: linux\npath_parameters:\n  - xml_file\n"+
+		"source_code_root: 'repo'\nignore_patterns:\n  global:\n    - 'deploy.*'\n")
+
+	var out bytes.Buffer
+	if err := runE2E([]string{"-update", dir}, &out); err != nil {
+		t.Fatalf("runE2E -update failed: %v", err)
+	}
+	if err := runE2E([]string{dir}, &out); err != nil {
+		t.Fatalf("Expected the recorded report to match, got %v\n%s", err, out.String())
+	}
+
+	writeFile(filepath.Join(root, "deploy.sh"), "plmxml_import -xml_file=\"100-Config/b.xml\"\n")
+	out.Reset()
+	err := runE2E([]string{dir}, &out)
+	if got := exitCode(err); got != exitFindings {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitFindings, got, err)
+	}
+	for _, want := range []string{"FAIL " + dir, "passed: expected true, got false", "+ deploy.sh:1:26: error: TCX010"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunE2E_MissingExpectedReport(t *testing.T) {
+	// What: A fixture without expected report, or a directory without fixtures, is an I/O error
+	dir := t.TempDir()
+	if got := exitCode(runE2E([]string{dir}, &bytes.Buffer{})); got != exitIO {
+		t.Errorf("Expected exit code %d without fixtures, got %d", exitIO, got)
+	}
+	if got := exitCode(runE2E(nil, &bytes.Buffer{})); got != exitConfig {
+		t.Errorf("Expected exit code %d without arguments, got %d", exitConfig, got)
+	}
+}
+
+func TestDiffE2EReports(t *testing.T) {
+	// What: Findings are compared on all fields, each expected finding matches one reported
+	finding := func(line int) report.BaselineFinding {
+		return report.BaselineFinding{Finding: analyzer.Finding{Rule: "TCX010", Severity: "error", Script: "deploy.sh", Line: line, Message: "missing"}}
+	}
+	expected := e2eReport{Findings: []report.BaselineFinding{finding(3), finding(3)}}
+	actual := e2eReport{Findings: []report.BaselineFinding{finding(3), finding(4)}}
+	got := diffE2EReports(expected, actual)
+	want := []string{"- deploy.sh:3:1: error: TCX010 missing", "+ deploy.sh:4:1: error: TCX010 missing"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
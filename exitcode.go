@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Exit codes of the tool, so wrapper scripts can tell broken scripts from a tool that
+// could not run
+const (
+	exitClean    = 0 // the validation passed
+	exitFindings = 1 // error findings, exceeded thresholds or findings new since the baseline
+	exitConfig   = 2 // invalid command line or configuration
+	exitIO       = 3 // files, the repository, git, a remote or the log could not be read or written
+	exitInternal = 4 // unexpected failure
+)
+
+// exitError is an error ending the run with an exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode returns err ending the run with code, nil when err is nil. The code of
+// an error that already has one is kept.
+func withExitCode(code int, err error) error {
+	var e *exitError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code of an error returned by run; errors without an exit
+// code are internal
+func exitCode(err error) int {
+	var e *exitError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitClean
+	case errors.As(err, &e):
+		return e.code
+	default:
+		return exitInternal
+	}
+}
+
+// kindExitCodes are the exit codes of the errors stopping a run
+var kindExitCodes = map[analyzer.ErrorKind]int{
+	analyzer.KindInternal:  exitInternal,
+	analyzer.KindConfig:    exitConfig,
+	analyzer.KindIO:        exitIO,
+	analyzer.KindThreshold: exitFindings,
+}
+
+// validationError returns the error ending a validation: the most severe of the
+// repositories, by exit code. Files that could not be read are an I/O error, error
+// findings fail the validation; the message counts the error findings reported, the
+// findings of a repository as returned by reported.
+func validationError(results []analyzer.RepositoryResult, reported func([]analyzer.Finding) []analyzer.Finding, err error) error {
+	code := exitClean
+	raise := func(c int) {
+		if c > code {
+			code = c
+		}
+	}
+	errorFindings := 0
+	for _, r := range results {
+		if r.Err != nil {
+			raise(kindExitCodes[analyzer.Kind(r.Err)])
+		}
+		if analyzer.HasIOFindings(r.Result.Findings) {
+			raise(exitIO)
+		}
+		if !r.Result.Summary.Passed {
+			raise(exitFindings)
+		}
+		for _, f := range reported(r.Result.Findings) {
+			if f.Severity == analyzer.SeverityError {
+				errorFindings++
+			}
+		}
+	}
+	if code == exitClean {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("validation failed with %d error finding(s)", errorFindings)
+	}
+	return withExitCode(code, err)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+func TestExitCode(t *testing.T) {
+	// What: Errors map to their exit code, unclassified errors are internal and -h is clean
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitClean},
+		{withExitCode(exitConfig, flag.ErrHelp), exitClean},
+		{withExitCode(exitIO, errors.New("disk")), exitIO},
+		{fmt.Errorf("wrapped: %w", withExitCode(exitConfig, errors.New("bad"))), exitConfig},
+		{withExitCode(exitIO, withExitCode(exitConfig, errors.New("bad"))), exitConfig},
+		{errors.New("unexpected"), exitInternal},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+	if withExitCode(exitIO, nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	// What: The most severe outcome of the repositories decides the exit code
+	passed := analyzer.RepositoryResult{Result: analyzer.Result{Summary: analyzer.Summary{Passed: true}}}
+	failed := analyzer.RepositoryResult{Result: analyzer.Result{Summary: analyzer.Summary{Errors: 2}}}
+	unreadable := analyzer.RepositoryResult{Result: analyzer.Result{
+		Findings: []analyzer.Finding{{Rule: analyzer.RuleTraversalError, Severity: analyzer.SeverityError}},
+		Summary:  analyzer.Summary{Errors: 1},
+	}}
+	panicked := analyzer.RepositoryResult{Err: &analyzer.PanicError{Phase: analyzer.PhaseSyntax, Value: "boom"}}
+	tests := []struct {
+		name    string
+		results []analyzer.RepositoryResult
+		want    int
+	}{
+		{"clean", []analyzer.RepositoryResult{passed}, exitClean},
+		{"findings", []analyzer.RepositoryResult{passed, failed}, exitFindings},
+		{"traversal", []analyzer.RepositoryResult{failed, unreadable}, exitIO},
+		{"panic", []analyzer.RepositoryResult{unreadable, panicked}, exitInternal},
+	}
+	for _, tt := range tests {
+		if got := exitCode(validationError(tt.results, report.Deduplicate, nil)); got != tt.want {
+			t.Errorf("%s: exit code %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidationError_CountsReportedFindings(t *testing.T) {
+	// What: The message counts the error findings reported, duplicates and filtered findings left out
+	missing := analyzer.Finding{Rule: analyzer.RuleMissingFile, Severity: analyzer.SeverityError, Script: "deploy.sh", Line: 1, Path: "100-Config/a.xml"}
+	duplicate := missing
+	duplicate.Script = "deploy.bat"
+	other := analyzer.Finding{Rule: analyzer.RuleMissingFile, Severity: analyzer.SeverityError, Script: "deploy.sh", Line: 2, Path: "200-Stylesheets/b.xml"}
+	warning := analyzer.Finding{Rule: analyzer.RuleUnreferencedFile, Severity: analyzer.SeverityWarning, Path: "100-Config/c.xml"}
+	results := []analyzer.RepositoryResult{{Result: analyzer.Result{
+		Findings: []analyzer.Finding{missing, duplicate, other, warning},
+		Summary:  analyzer.Summary{Errors: 3, Warnings: 1},
+	}}}
+
+	err := validationError(results, report.Deduplicate, nil)
+	if err == nil || err.Error() != "validation failed with 2 error finding(s)" {
+		t.Errorf("Expected the deduplicated findings counted, got %v", err)
+	}
+	filter := report.Filter{Paths: []string{"200-Stylesheets"}}
+	err = validationError(results, func(f []analyzer.Finding) []analyzer.Finding { return filter.Apply(report.Deduplicate(f)) }, nil)
+	if err == nil || err.Error() != "validation failed with 1 error finding(s)" {
+		t.Errorf("Expected the filtered findings counted, got %v", err)
+	}
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	// What: A clean validation exits with 0, a missing configuration or script with 2
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	if err := runCheck([]string{"-c", configPath, "-format", "compact"}, &bytes.Buffer{}); exitCode(err) != exitClean {
+		t.Errorf("Expected a clean run, got %d (%v)", exitCode(err), err)
+	}
+
+	err := runCheck([]string{"-c", "definitely_does_not_exist.yaml"}, &bytes.Buffer{})
+	if exitCode(err) != exitConfig {
+		t.Errorf("Expected exit code %d for a missing configuration, got %d (%v)", exitConfig, exitCode(err), err)
+	}
+
+	configPath = writeValidationFixture(t, nil, "  - filename: deploy.sh\n    target_os: linux\n")
+	err = runCheck([]string{"-c", configPath, "-format", "compact"}, &bytes.Buffer{})
+	if exitCode(err) != exitConfig {
+		t.Errorf("Expected exit code %d for a missing script, got %d (%v)", exitConfig, exitCode(err), err)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// explainFlagSet defines the flags of the explain subcommand, which has none
+func explainFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("explain", flag.ContinueOnError)
+}
+
+// ruleIDs returns the IDs of the rule catalog, completed as arguments of explain
+func ruleIDs() []string {
+	var ids []string
+	for _, rule := range analyzer.Rules() {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+// runExplain prints what the rules report, why it matters and how to fix or suppress
+// their findings, or lists the rules without arguments: explain [RULE...]
+func runExplain(arguments []string, w io.Writer) error {
+	f := explainFlagSet()
+	if err := f.Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	if f.NArg() == 0 {
+		for _, rule := range analyzer.Rules() {
+			fmt.Fprintf(w, "%s  %-22s %-8s %s\n", rule.ID, rule.Name, rule.Severity, rule.Summary)
+		}
+		return nil
+	}
+
+	// All rules are looked up first, an unknown one prints nothing
+	var explained []analyzer.Rule
+	for _, name := range f.Args() {
+		rule, ok := analyzer.LookupRule(name)
+		if !ok {
+			return withExitCode(exitConfig, fmt.Errorf("unknown rule '%s' (run 'explain' to list the rules)", name))
+		}
+		explained = append(explained, rule)
+	}
+	for i, rule := range explained {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := report.Explain(w, rule); err != nil {
+			return withExitCode(exitIO, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunExplain(t *testing.T) {
+	// What: A rule is explained by ID or name with its rationale, fix and suppression
+	for _, name := range []string{"TCX010", "missing-file"} {
+		var out bytes.Buffer
+		if err := runExplain([]string{name}, &out); err != nil {
+			t.Fatalf("runExplain(%s) failed: %v", name, err)
+		}
+		for _, want := range []string{"TCX010 missing-file (error)", "What:", "Why:", "Fix:", "Suppress:"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Expected %q in the explanation of %s, got %q", want, name, out.String())
+			}
+		}
+	}
+}
+
+func TestRunExplain_List(t *testing.T) {
+	// What: Without arguments all rules are listed, one per line in ID order
+	var out bytes.Buffer
+	if err := runExplain(nil, &out); err != nil {
+		t.Fatalf("runExplain() failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(ruleIDs()) || !strings.HasPrefix(lines[0], "TCX001  flag-not-quoted") {
+		t.Errorf("Expected the rule list, got %q", out.String())
+	}
+}
+
+func TestRunExplain_UnknownRule(t *testing.T) {
+	// What: An unknown rule is a configuration error and nothing is printed
+	var out bytes.Buffer
+	err := runExplain([]string{"TCX010", "TCX999"}, &out)
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "unknown rule 'TCX999'") {
+		t.Errorf("Expected an unknown rule error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no output, got %q", out.String())
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// fixOptions are the command-line parameters of the fix subcommand
+type fixOptions struct {
+	Args
+	DryRun bool
+}
+
+// fixFlagSet defines the flags of the fix subcommand into o
+func fixFlagSet(o *fixOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("fix", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.BoolVar(&o.DryRun, "dry-run", false, "print the fixes without rewriting the scripts")
+	return f
+}
+
+// Fixes of the rules with a mechanical fix, by rule: the fixed script line, false
+// when the line cannot be fixed safely
+var fixers = map[string]func(line string, f analyzer.Finding, targetOS string) (string, bool){
+	analyzer.RuleWrongSeparator: fixSeparators,
+}
+
+// fixSeparators replaces the separators of the path in line by those of the target OS.
+// The path must occur once in the line; a Linux path with an escaped space is left alone.
+func fixSeparators(line string, f analyzer.Finding, targetOS string) (string, bool) {
+	if f.Path == "" || strings.Count(line, f.Path) != 1 {
+		return line, false
+	}
+	wrong, right := `\`, "/"
+	if targetOS == "windows" {
+		wrong, right = "/", `\`
+	} else if strings.Contains(f.Path, `\ `) {
+		return line, false
+	}
+	return strings.Replace(line, f.Path, strings.ReplaceAll(f.Path, wrong, right), 1), true
+}
+
+// scriptFix is a fix of a line of a script
+type scriptFix struct {
+	finding analyzer.Finding
+	before  string
+	after   string
+}
+
+// runFix validates the scripts and rewrites the lines of findings with a mechanical
+// fix: fix [-c config.yaml] [-dry-run]
+func runFix(arguments []string, w io.Writer) error {
+	var o fixOptions
+	if err := fixFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	configurationParameters, err := getConfigFrom(o.ConfigPath, o.Config)
+	if err != nil {
+		return err
+	}
+	results, err := validate(o.Args, configurationParameters, true)
+	if results == nil {
+		return err
+	}
+	if err := validationFailure(results); err != nil {
+		return err
+	}
+
+	repositories := configurationParameters.Repositories
+	if len(repositories) == 0 {
+		repositories = []analyzer.Repository{{Parameters: configurationParameters}}
+	}
+	verb := "fixed"
+	if o.DryRun {
+		verb = "would fix"
+	}
+	fixable, fixed := 0, 0
+	for i, repo := range repositories {
+		prefix := ""
+		if repo.Name != "" {
+			prefix = repo.Name + ": "
+		}
+		targetOS := make(map[string]string, len(repo.Parameters.Scripts))
+		for _, script := range repo.Parameters.Scripts {
+			targetOS[script.Filename] = script.TargetOS
+		}
+		fixes := planFixes(targetOS, results[i].Result.Findings)
+		files := make([]string, 0, len(fixes))
+		for file := range fixes {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			applied, err := fixScript(filepath.Join(repo.Parameters.SourceCodeRoot, file), targetOS[file], fixes[file], o.DryRun)
+			if err != nil {
+				return err
+			}
+			fixable += len(fixes[file])
+			fixed += len(applied)
+			for _, fix := range applied {
+				fmt.Fprintf(w, "%s%s %s:%d: '%s' -> '%s'\n", prefix, verb, file, fix.finding.Line,
+					strings.TrimSpace(fix.before), strings.TrimSpace(fix.after))
+			}
+		}
+	}
+	if o.DryRun {
+		fmt.Fprintf(w, "%d of %d fixable finding(s) would be fixed\n", fixed, fixable)
+	} else {
+		fmt.Fprintf(w, "%d of %d fixable finding(s) fixed\n", fixed, fixable)
+	}
+	return nil
+}
+
+// planFixes returns the findings with a mechanical fix by configured script, given
+// with its target OS, leaving out the scripts that were transcoded, as writing them
+// back would change their encoding, and the scripts shipped in archives
+func planFixes(targetOS map[string]string, findings []analyzer.Finding) map[string][]analyzer.Finding {
+	transcoded := make(map[string]bool)
+	for _, f := range findings {
+		if f.Rule == analyzer.RuleScriptEncoding {
+			transcoded[f.Script] = true
+		}
+	}
+	fixes := make(map[string][]analyzer.Finding)
+	for _, f := range findings {
+		if _, ok := fixers[f.Rule]; ok && f.Line > 0 && targetOS[f.Script] != "" && !transcoded[f.Script] && !analyzer.IsArchivedScript(f.Script) {
+			fixes[f.Script] = append(fixes[f.Script], f)
+		}
+	}
+	return fixes
+}
+
+// fixScript applies the fixes to the script at path, which is rewritten unless
+// dryRun, and returns the fixes applied
+func fixScript(path, targetOS string, findings []analyzer.Finding, dryRun bool) ([]scriptFix, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, withExitCode(exitIO, fmt.Errorf("failed to fix '%s': %w", path, err))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, withExitCode(exitIO, fmt.Errorf("failed to fix '%s': %w", path, err))
+	}
+	lines := strings.Split(string(data), "\n")
+	var applied []scriptFix
+	for _, f := range findings {
+		if f.Line > len(lines) {
+			continue
+		}
+		before := lines[f.Line-1]
+		after, ok := fixers[f.Rule](before, f, targetOS)
+		if !ok || after == before {
+			continue
+		}
+		lines[f.Line-1] = after
+		applied = append(applied, scriptFix{finding: f, before: before, after: after})
+	}
+	if len(applied) == 0 || dryRun {
+		return applied, nil
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+		return nil, withExitCode(exitIO, fmt.Errorf("failed to fix '%s': %w", path, err))
+	}
+	return applied, nil
+}
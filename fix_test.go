@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestFixSeparators(t *testing.T) {
+	// What: Separators are replaced by those of the target OS only when the path is unambiguous
+	tests := []struct {
+		line, path, targetOS string
+		want                 string
+		ok                   bool
+	}{
+		{`import -xml_file="100-Config/a.xml"`, "100-Config/a.xml", "windows", `import -xml_file="100-Config\a.xml"`, true},
+		{`import -xml_file="100-Config\a.xml"`, `100-Config\a.xml`, "linux", `import -xml_file="100-Config/a.xml"`, true},
+		{`import -xml_file="a/b" -input="a/b"`, "a/b", "windows", "", false},
+		{`import -xml_file="my\ dir\a.xml"`, `my\ dir\a.xml`, "linux", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := fixSeparators(tt.line, analyzer.Finding{Path: tt.path}, tt.targetOS)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("fixSeparators(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRunFix(t *testing.T) {
+	// What: fix rewrites the wrong separators and keeps the Windows line endings, -dry-run leaves the script alone
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.bat": "plmxml_import -xml_file=\"100-Config/a.xml\"\r\nplmxml_import -xml_file=\"100-Config\\a.xml\"\r\n",
+	}, "  - filename: deploy.bat\n    target_os: windows\n")
+	script := filepath.Join(filepath.Dir(configPath), "repo", "deploy.bat")
+
+	var out bytes.Buffer
+	if err := runFix([]string{"-c", configPath, "-dry-run"}, &out); err != nil {
+		t.Fatalf("runFix(-dry-run) failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "would fix deploy.bat:1:") {
+		t.Errorf("Unexpected dry-run output %q", out.String())
+	}
+	if data, _ := os.ReadFile(script); !strings.Contains(string(data), "100-Config/a.xml") {
+		t.Error("Expected -dry-run to leave the script unchanged")
+	}
+
+	out.Reset()
+	if err := runFix([]string{"-c", configPath}, &out); err != nil {
+		t.Fatalf("runFix() failed: %v", err)
+	}
+	data, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatalf("Failed to read script: %v", err)
+	}
+	expected := "plmxml_import -xml_file=\"100-Config\\a.xml\"\r\nplmxml_import -xml_file=\"100-Config\\a.xml\"\r\n"
+	if string(data) != expected {
+		t.Errorf("Fixed script %q, want %q", data, expected)
+	}
+	if !strings.Contains(out.String(), "1 of 1 fixable finding(s) fixed") {
+		t.Errorf("Unexpected fix output %q", out.String())
+	}
+}
+
+func TestPlanFixes_SkipsTranscodedScripts(t *testing.T) {
+	// What: Scripts that were transcoded are not rewritten, neither are files that are not configured
+	findings := []analyzer.Finding{
+		{Rule: analyzer.RuleWrongSeparator, Script: "a.bat", Line: 1, Path: "x/y"},
+		{Rule: analyzer.RuleWrongSeparator, Script: "b.bat", Line: 1, Path: "x/y"},
+		{Rule: analyzer.RuleScriptEncoding, Script: "b.bat"},
+		{Rule: analyzer.RuleWrongSeparator, Script: "nested.bat", Line: 1, Path: "x/y"},
+		{Rule: analyzer.RuleMissingFile, Script: "a.bat", Line: 2, Path: "z"},
+	}
+	fixes := planFixes(map[string]string{"a.bat": "windows", "b.bat": "windows"}, findings)
+	if len(fixes) != 1 || len(fixes["a.bat"]) != 1 {
+		t.Errorf("Expected one fix of a.bat, got %+v", fixes)
+	}
+}
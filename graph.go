@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// graphOptions are the command-line parameters of the graph subcommand
+type graphOptions struct {
+	Args
+	Output      string
+	GraphFormat string
+}
+
+// graphFlagSet defines the flags of the graph subcommand into o
+func graphFlagSet(o *graphOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("graph", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.StringVar(&o.Output, "o", "", "file to write the graph to (default: standard output)")
+	f.StringVar(&o.GraphFormat, "format", "", "graph format: dot or json (default: json for a .json file, dot otherwise)")
+	return f
+}
+
+// runGraph validates the scripts and writes the graph of the scripts, the utilities
+// they call, the files these reference and the files listed by those, with the
+// unreferenced files of the repositories as orphans:
+// graph [-c config.yaml] [-format dot|json] [-o graph.dot]. The findings of the
+// validation do not fail the command, the graph shows the missing files.
+func runGraph(arguments []string, w io.Writer) error {
+	var o graphOptions
+	if err := graphFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	format := strings.ToLower(o.GraphFormat)
+	if format == "" {
+		format = "dot"
+		if strings.EqualFold(filepath.Ext(o.Output), ".json") {
+			format = "json"
+		}
+	}
+	if format != "dot" && format != "json" {
+		return withExitCode(exitConfig, fmt.Errorf("invalid graph format '%s' (must be 'dot' or 'json')", o.GraphFormat))
+	}
+	configurationParameters, err := getConfigFrom(o.ConfigPath, o.Config)
+	if err != nil {
+		return err
+	}
+	results, err := validate(o.Args, configurationParameters, true)
+	if results == nil {
+		return err
+	}
+	graph := analyzer.BuildDependencyGraph(results)
+
+	out := w
+	if o.Output != "" {
+		file, err := os.Create(o.Output)
+		if err != nil {
+			return withExitCode(exitIO, fmt.Errorf("failed to create graph: %w", err))
+		}
+		defer file.Close()
+		out = file
+	}
+	if format == "json" {
+		err = report.GraphJSON(out, graph)
+	} else {
+		err = report.GraphDOT(out, graph)
+	}
+	if err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to write graph: %w", err))
+	}
+	if file, ok := out.(*os.File); ok && o.Output != "" {
+		if err := file.Close(); err != nil {
+			return withExitCode(exitIO, fmt.Errorf("failed to write graph: %w", err))
+		}
+		fmt.Fprintf(w, "%d node(s) and %d edge(s) written to '%s'\n", len(graph.Nodes), len(graph.Edges), o.Output)
+	}
+	return nil
+}
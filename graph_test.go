@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestRunGraph(t *testing.T) {
+	// What: graph writes DOT to the output by default, with the file of the script even when missing
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\nplmxml_import -xml_file=\"100-Config/b.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	var out bytes.Buffer
+	if err := runGraph([]string{"-c", configPath}, &out); err != nil {
+		t.Fatalf("runGraph() failed: %v", err)
+	}
+	for _, want := range []string{
+		`"script:deploy.sh" -> "utility:deploy.sh:plmxml_import";`,
+		`"utility:deploy.sh:plmxml_import" -> "file:100-Config/a.xml";`,
+		`"file:100-Config/b.xml" [label="100-Config/b.xml", shape=note, color=red`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunGraph_JSONFile(t *testing.T) {
+	// What: A .json output file is written as JSON and the counts are printed
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	file := filepath.Join(t.TempDir(), "graph.json")
+
+	var out bytes.Buffer
+	if err := runGraph([]string{"-c", configPath, "-o", file}, &out); err != nil {
+		t.Fatalf("runGraph() failed: %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Graph not written: %v", err)
+	}
+	var graph analyzer.DependencyGraph
+	if err := json.Unmarshal(content, &graph); err != nil {
+		t.Fatalf("Invalid graph %q: %v", content, err)
+	}
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 || !strings.Contains(out.String(), "3 node(s) and 2 edge(s) written to") {
+		t.Errorf("Unexpected graph %+v, output %q", graph, out.String())
+	}
+}
+
+func TestRunGraph_InvalidFormat(t *testing.T) {
+	// What: A format other than dot or json is a configuration error
+	err := runGraph([]string{"-format", "svg"}, &bytes.Buffer{})
+	if exitCode(err) != exitConfig || !strings.Contains(err.Error(), "invalid graph format 'svg'") {
+		t.Errorf("Expected a configuration error, got %v", err)
+	}
+}
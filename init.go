@@ -0,0 +1,45 @@
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Starter configuration written by init, the documented example configuration
+//
+//go:embed config.example.yaml
+var exampleConfig []byte
+
+// initOptions are the command-line parameters of the init subcommand
+type initOptions struct {
+	Output string
+	Force  bool
+}
+
+// initFlagSet defines the flags of the init subcommand into o
+func initFlagSet(o *initOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("init", flag.ContinueOnError)
+	f.StringVar(&o.Output, "o", "config.yaml", "configuration file to write")
+	f.BoolVar(&o.Force, "force", false, "overwrite an existing configuration file")
+	return f
+}
+
+// runInit writes the example configuration as starting point, an existing file is
+// only overwritten with -force: init [-o config.yaml] [-force]
+func runInit(arguments []string, w io.Writer) error {
+	var o initOptions
+	if err := initFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	if _, err := os.Stat(o.Output); err == nil && !o.Force {
+		return withExitCode(exitConfig, fmt.Errorf("configuration file '%s' already exists (use -force to overwrite)", o.Output))
+	}
+	if err := os.WriteFile(o.Output, exampleConfig, 0644); err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to write configuration file: %w", err))
+	}
+	fmt.Fprintf(w, "configuration written to '%s', adjust the scripts and source_code_root\n", o.Output)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInit(t *testing.T) {
+	// What: init writes the example configuration and does not overwrite it without -force
+	output := filepath.Join(t.TempDir(), "config.yaml")
+
+	var out bytes.Buffer
+	if err := runInit([]string{"-o", output}, &out); err != nil {
+		t.Fatalf("runInit() failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.Equal(data, exampleConfig) {
+		t.Fatalf("Expected the example configuration, got %d bytes (%v)", len(data), err)
+	}
+
+	err = runInit([]string{"-o", output}, &out)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected error for existing file, got: %v", err)
+	}
+	if err := runInit([]string{"-o", output, "-force"}, &out); err != nil {
+		t.Errorf("runInit(-force) failed: %v", err)
+	}
+}
+
+func TestExampleConfig_Loads(t *testing.T) {
+	// What: The configuration written by init loads and validates
+	output := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(output, exampleConfig, 0644); err != nil {
+		t.Fatalf("Failed to write configuration: %v", err)
+	}
+	if _, err := getConfig(output); err != nil {
+		t.Errorf("Example configuration does not load: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// With 'git.unreferenced_age' the unreferenced files are reported with their last commit,
+// so a cleanup can start with the files untouched for years and leave the recently
+// added ones, which are likely waiting for a script update.
+
+// FileCommit is the last commit changing a file
+type FileCommit struct {
+	Date   time.Time
+	Author string
+}
+
+// loadLastCommits reads the history of root once and returns the last commit of each
+// file below it; files deleted since are listed too but never looked up. Merge commits
+// are skipped, their changes are the ones of the merged commits.
+func (r *run) loadLastCommits(root string) (map[string]FileCommit, error) {
+	repo, err := openGitRepository(root)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD failed: %w", err)
+	}
+	history, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("reading the git history failed: %w", err)
+	}
+	defer history.Close()
+
+	commits := make(map[string]FileCommit)
+	err = history.ForEach(func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			return nil
+		}
+		changes, err := commitChanges(c)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			if rel, ok := repo.relative(name); ok {
+				if _, seen := commits[rel]; !seen {
+					commits[rel] = FileCommit{Date: c.Author.When, Author: c.Author.Name}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading the git history failed: %w", err)
+	}
+	r.log.Info("last commits of {n} file(s) read from the git history", "n", len(commits))
+	return commits, nil
+}
+
+// commitChanges returns the files a commit changed from its parent, without rename
+// detection; all files of the tree for the first commit
+func commitChanges(c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() == 1 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	return object.DiffTree(parentTree, tree)
+}
+
+// lastCommit returns the last commit of a file relative to the source code root, in
+// the notation of the runtime OS; the files found by a sub-folder comparison are joined
+// to the folder by the caller (rootPrefix)
+func (r *run) lastCommit(item string) (FileCommit, bool) {
+	commit, ok := r.lastCommits[filepath.ToSlash(item)]
+	return commit, ok
+}
+
+// unreferencedAge returns the part of the unreferenced file message naming its last
+// commit, empty without 'git.unreferenced_age'
+func (r *run) unreferencedAge(item string) string {
+	if r.lastCommits == nil {
+		return ""
+	}
+	if commit, ok := r.lastCommit(item); ok {
+		return logger.Format(" (last commit {d} by {a})", "d", commit.Date.Format("2006-01-02"), "a", commit.Author)
+	}
+	return " (not committed)"
+}
+
+// logUnreferencedByAge lists the unreferenced files with their last commit, the oldest
+// first and the files never committed last
+func (r *run) logUnreferencedByAge(unreferenced []string) {
+	if r.lastCommits == nil || len(unreferenced) == 0 {
+		return
+	}
+	items := append([]string{}, unreferenced...)
+	sort.SliceStable(items, func(i, j int) bool {
+		a, aok := r.lastCommit(items[i])
+		b, bok := r.lastCommit(items[j])
+		if aok != bok {
+			return aok
+		}
+		return aok && a.Date.Before(b.Date)
+	})
+
+	r.log.Separate("UNREFERENCED FILES BY AGE")
+	for _, item := range items {
+		commit, ok := r.lastCommit(item)
+		if !ok {
+			r.log.Separate("  not committed  '{item}'", "item", item)
+			continue
+		}
+		r.log.Separate("  {d}  '{item}' by {a}", "d", commit.Date.Format("2006-01-02"), "item", item, "a", commit.Author)
+	}
+}
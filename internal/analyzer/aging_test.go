@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests for the last commits of the unreferenced files
+
+// What: The newest commit of each file below the root is read, with its author and date
+func TestLoadLastCommits(t *testing.T) {
+	root := gitTestRepo(t, "a.xml", "b c.xml")
+	if err := os.WriteFile(filepath.Join(root, "a.xml"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommit(t, root, "Jane Doe", time.Date(2019, 3, 2, 10, 0, 0, 0, time.UTC))
+
+	commits, err := testRun.loadLastCommits(root)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if a := commits["a.xml"]; a.Author != "Jane Doe" || !a.Date.Equal(time.Date(2019, 3, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the last commit of a.xml by Jane Doe on 2019-03-02, got %+v", a)
+	}
+	if b, ok := commits["b c.xml"]; !ok || b.Author != "test" {
+		t.Errorf("Expected the base commit of 'b c.xml', got %+v", b)
+	}
+}
+
+// What: The history of a root below the top level is read relative to the root
+func TestLoadLastCommits_Subdirectory(t *testing.T) {
+	root := gitTestRepo(t, "top.xml")
+	if err := os.MkdirAll(filepath.Join(root, "config", "100-Preferences"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "config", "100-Preferences", "p.xml"), []byte("p"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommit(t, root, "Bob", time.Date(2024, 1, 15, 8, 30, 0, 0, time.FixedZone("", 3600)))
+
+	commits, err := testRun.loadLastCommits(filepath.Join(root, "config"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(commits) != 1 || commits["100-Preferences/p.xml"].Author != "Bob" {
+		t.Errorf("Expected only 100-Preferences/p.xml by Bob, got %+v", commits)
+	}
+}
+
+// What: The unreferenced files are reported with their last commit, files never committed as such
+func TestReportUnreferencedFiles_Age(t *testing.T) {
+	originalResult, originalScript, originalCommits := testRun.analysisResult, testRun.currentScript, testRun.lastCommits
+	t.Cleanup(func() {
+		testRun.analysisResult, testRun.currentScript, testRun.lastCommits = originalResult, originalScript, originalCommits
+	})
+	testRun.currentScript = "deploy.sh"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.lastCommits = map[string]FileCommit{"100-Config/old.xml": {Date: time.Date(2017, 5, 1, 0, 0, 0, 0, time.UTC), Author: "Jane Doe"}}
+
+	old := filepath.Join("100-Config", "old.xml")
+	testRun.reportUnreferencedFiles("deploy.sh", "/repo", []string{old, "new.xml"}, nil, nil)
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 || !strings.HasSuffix(findings[0].Message, "(last commit 2017-05-01 by Jane Doe)") || !strings.HasSuffix(findings[1].Message, "(not committed)") {
+		t.Errorf("Expected the last commits in the messages, got %+v", findings)
+	}
+	if commits := testRun.analysisResult.File["deploy.sh"].LastCommits; len(commits) != 1 || commits[old].Author != "Jane Doe" {
+		t.Errorf("Expected the last commit of %s in the result, got %+v", old, commits)
+	}
+}
+
+// What: The files of a sub-folder comparison are looked up relative to the source code root
+func TestReportUnreferencedFiles_AgeSubfolder(t *testing.T) {
+	originalResult, originalScript, originalRoot, originalCommits := testRun.analysisResult, testRun.currentScript, testRun.sourceCodeRoot, testRun.lastCommits
+	t.Cleanup(func() {
+		testRun.analysisResult, testRun.currentScript, testRun.sourceCodeRoot, testRun.lastCommits = originalResult, originalScript, originalRoot, originalCommits
+	})
+	root := t.TempDir()
+	testRun.currentScript, testRun.sourceCodeRoot = "deploy.sh", root
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.lastCommits = map[string]FileCommit{"130-Workflows/old.xml": {Date: time.Date(2017, 5, 1, 0, 0, 0, 0, time.UTC), Author: "Jane Doe"}}
+
+	testRun.reportUnreferencedFiles("deploy.sh", filepath.Join(root, "130-Workflows"), []string{"old.xml"}, nil, nil)
+
+	old := filepath.Join("130-Workflows", "old.xml")
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 1 || findings[0].Path != old || !strings.HasSuffix(findings[0].Message, "(last commit 2017-05-01 by Jane Doe)") {
+		t.Errorf("Expected the last commit of %s in the message, got %+v", old, findings)
+	}
+	if commits := testRun.analysisResult.File["deploy.sh"].LastCommits; len(commits) != 1 || commits[old].Author != "Jane Doe" {
+		t.Errorf("Expected the last commit of %s in the result, got %+v", old, commits)
+	}
+}
+
+// What: Without unreferenced_age the messages are unchanged
+func TestUnreferencedAge_NotConfigured(t *testing.T) {
+	originalCommits := testRun.lastCommits
+	defer func() { testRun.lastCommits = originalCommits }()
+	testRun.lastCommits = nil
+
+	if age := testRun.unreferencedAge("a.xml"); age != "" {
+		t.Errorf("Expected no age, got %q", age)
+	}
+}
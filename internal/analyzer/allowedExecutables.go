@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// allowed_executables:
+//   - plmxml_import
+//   - install_xml_stylesheet_datasets
+//   - 'tc_*'
+
+// ValidateAllowedExecutables checks that the allowed executables are valid patterns
+func ValidateAllowedExecutables(patterns []string) error {
+	for i, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("allowed executable at index %d is empty", i)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("allowed executable '%s' is an invalid pattern: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// normalizeExecutable returns the name of an executable as extractExecutableName
+// does: lowercase, without the .exe, .bat or .sh extension
+func normalizeExecutable(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, extension := range []string{".exe", ".bat", ".sh"} {
+		name = strings.TrimSuffix(name, extension)
+	}
+	return name
+}
+
+// compileAllowedExecutables normalizes the patterns of the allowed executables
+func compileAllowedExecutables(patterns []string) []string {
+	allowed := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		allowed = append(allowed, normalizeExecutable(pattern))
+	}
+	return allowed
+}
+
+// executableAllowed reports whether the executable matches an allowed pattern
+func (r *run) executableAllowed(executable string) bool {
+	if len(r.allowedExecutables) == 0 {
+		return true
+	}
+	for _, pattern := range r.allowedExecutables {
+		if matched, _ := path.Match(pattern, executable); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAllowedExecutable reports the executable called by the line when it is not in
+// 'allowed_executables', e.g. a local helper binary missing on the deployment host.
+// Shell builtins, control flow, labels and lines continuing a command are not calls.
+func (r *run) checkAllowedExecutable(scriptFile, line string, lineNumber int, continued bool) {
+	if len(r.allowedExecutables) == 0 || continued || commandSkipReason(line, false) == SkipShellCommand {
+		return
+	}
+	executable := extractExecutableName(line)
+	if executable == "" || r.executableAllowed(executable) {
+		return
+	}
+	r.reportFinding(Finding{Rule: RuleExecutableNotAllowed, Script: scriptFile, Line: lineNumber, Path: executable},
+		"'{s}' line '{ln}': executable '{e}' is not in 'allowed_executables'", "s", scriptFile, "ln", lineNumber, "e", executable)
+}
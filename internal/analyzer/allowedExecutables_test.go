@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests for the allowlist of the executables called by the scripts
+
+// What: Allowed executables are non-empty, valid file name patterns
+func TestValidateAllowedExecutables(t *testing.T) {
+	if err := ValidateAllowedExecutables([]string{"plmxml_import", "tc_*"}); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+	for _, patterns := range [][]string{{" "}, {"[tc"}} {
+		if err := ValidateAllowedExecutables(patterns); err == nil {
+			t.Errorf("Expected an error for %q", patterns)
+		}
+	}
+}
+
+// What: Executables are matched lowercase and without their extension, any executable is allowed without patterns
+func TestExecutableAllowed(t *testing.T) {
+	testRun.allowedExecutables = nil
+	t.Cleanup(func() { testRun.allowedExecutables = nil })
+	if !testRun.executableAllowed("helper") {
+		t.Error("Expected any executable to be allowed without patterns")
+	}
+
+	testRun.allowedExecutables = compileAllowedExecutables([]string{"PLMXML_Import.exe", "tc_*"})
+	for executable, want := range map[string]bool{"plmxml_import": true, "tc_set_env": true, "tcxml_import": false} {
+		if got := testRun.executableAllowed(executable); got != want {
+			t.Errorf("executableAllowed(%q) = %v, want %v", executable, got, want)
+		}
+	}
+}
+
+// What: Calls of executables not allowed are TCX038 on their line; builtins, control flow and continuations are not calls
+func TestCheckFileSyntax_AllowedExecutables(t *testing.T) {
+	testRun.allowedExecutables = compileAllowedExecutables([]string{"plmxml_import"})
+	t.Cleanup(func() { testRun.allowedExecutables = nil })
+	setupHeredocTest(t, "if [ -d out ]; then\n  echo ok\nfi\nplmxml_import -i=\"a.xml\" \\\n  -replace\n./bin/my_helper.sh -i=\"b.xml\"\ndone\n")
+
+	var lines []int
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RuleExecutableNotAllowed {
+			lines = append(lines, f.Line)
+			if f.Path != "my_helper" || !strings.Contains(f.Message, "allowed_executables") {
+				t.Errorf("Unexpected finding %+v", f)
+			}
+		}
+	}
+	if !reflect.DeepEqual(lines, []int{6}) {
+		t.Errorf("Expected the helper of line 6 only, got %v (%+v)", lines, testRun.analysisResult.Findings)
+	}
+}
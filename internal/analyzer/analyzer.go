@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Analyzer validates deployment scripts and logs the validations with its logger.
+// Each validation runs with a state of its own, so an Analyzer is safe for concurrent
+// use once configured: the requests of the serve subcommand validate in parallel, each
+// with an Analyzer logging the log of the request.
+type Analyzer struct {
+	log              *logger.Logger
+	findingLogFilter func(f Finding) bool
+	dedupe           bool
+}
+
+// New returns an analyzer logging with log
+func New(log *logger.Logger) *Analyzer {
+	return &Analyzer{log: log, dedupe: true}
+}
+
+// SetFindingLogFilter sets the filter of the findings written to the log; the findings
+// left out are still recorded and counted. nil logs all findings. Must be called before
+// the validations.
+func (a *Analyzer) SetFindingLogFilter(filter func(f Finding) bool) {
+	a.findingLogFilter = filter
+}
+
+// SetFindingDeduplication sets whether findings of a rule and path repeated across
+// scripts are logged once (the default). Must be called before the validations.
+func (a *Analyzer) SetFindingDeduplication(enabled bool) {
+	a.dedupe = enabled
+}
+
+// newRun returns the state of a validation with the logger and the finding settings
+// of the analyzer
+func (a *Analyzer) newRun() *run {
+	r := newRun(a.log)
+	r.findingLogFilter, r.deduplicateFindings = a.findingLogFilter, a.dedupe
+	return r
+}
+
+// Run analyzes all configured scripts and returns the analysis result.
+// Returns an error if the configured thresholds are exceeded. A panic is returned as
+// an internal PanicError with the partial result, after writing a diagnostic bundle.
+func (a *Analyzer) Run(params Parameters) (Result, error) {
+	return a.newRun().analyze(params)
+}
+
+// Run analyzes all configured scripts with the default logger, see (*Analyzer).Run
+func Run(params Parameters) (Result, error) {
+	return New(logger.Default()).Run(params)
+}
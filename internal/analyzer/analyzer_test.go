@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// testRun is the run of the tests calling the checks directly; they set and read its state
+var testRun = newRun(logger.Default())
+
+// Tests for the validations of several goroutines, run with 'go test -race'
+
+func TestAnalyzerRun_Concurrent(t *testing.T) {
+	// What: Analyzers running in parallel get their own results and log to their own logger
+	const runs = 8
+	roots := make([]string, runs)
+	for i := range roots {
+		roots[i] = t.TempDir()
+		script := fmt.Sprintf("plmxml_import -xml_file=\"100-Config/missing-%d-x.xml\"\n", i)
+		if err := os.WriteFile(filepath.Join(roots[i], "deploy.sh"), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := range roots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var out bytes.Buffer
+			log, err := logger.New(&out, "", "info")
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer log.Close()
+			result, err := New(log).Run(Parameters{
+				SourceCodeRoot: roots[i],
+				Scripts:        []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+				PathParameters: []PathParameter{{Name: "xml_file"}},
+				IgnorePatterns: ignorePatterns{Global: []string{"deploy.sh"}},
+			})
+			missing := fmt.Sprintf("100-Config/missing-%d-x.xml", i)
+			switch {
+			case err != nil:
+				errs <- err
+			case len(result.Findings) != 1 || result.Findings[0].Rule != RuleMissingFile || result.Findings[0].Path != missing:
+				errs <- fmt.Errorf("run %d: expected the missing %s, got %+v", i, missing, result.Findings)
+			case !strings.Contains(out.String(), missing) || strings.Count(out.String(), "missing-") != strings.Count(out.String(), fmt.Sprintf("missing-%d-", i)):
+				errs <- fmt.Errorf("run %d: expected only its own file in its log, got %q", i, out.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestParseScript_Concurrent(t *testing.T) {
+	// What: Scripts parsed by several goroutines get their own lines and findings
+	log, err := logger.New(&bytes.Buffer{}, "", "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New(log)
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name, targetOS := fmt.Sprintf("deploy%d.sh", i), "linux"
+			content := fmt.Sprintf("plmxml_import -xml_file=\"100-Config/%d.xml\"\n", i)
+			if i%2 == 1 {
+				name, targetOS = fmt.Sprintf("deploy%d.bat", i), "windows"
+				content = fmt.Sprintf("plmxml_import -xml_file=\"100-Config/%d.xml\"\r\n", i)
+			}
+			lines, findings, err := a.ParseScript(name, content, targetOS, parseParameters)
+			switch {
+			case err != nil:
+				errs <- err
+			case len(lines) != 1 || len(lines[0].Flags) != 1 || lines[0].Flags[0].Path != fmt.Sprintf("100-Config/%d.xml", i):
+				errs <- fmt.Errorf("%s: unexpected lines %+v", name, lines)
+			case targetOS == "windows" && (len(findings) != 1 || findings[0].Rule != RuleWrongSeparator || findings[0].Script != name):
+				errs <- fmt.Errorf("%s: expected the wrong separator, got %+v", name, findings)
+			case targetOS == "linux" && len(findings) != 0:
+				errs <- fmt.Errorf("%s: expected no findings, got %+v", name, findings)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestAnalyzer_FindingSettings(t *testing.T) {
+	// What: The finding settings of an analyzer apply to its runs only
+	filtered := New(logger.Default())
+	filtered.SetFindingLogFilter(func(f Finding) bool { return false })
+	filtered.SetFindingDeduplication(false)
+
+	r := filtered.newRun()
+	if r.findingLogFilter == nil || r.deduplicateFindings {
+		t.Errorf("Expected the run to take the filter and no deduplication")
+	}
+	if r := New(logger.Default()).newRun(); r.findingLogFilter != nil || !r.deduplicateFindings {
+		t.Errorf("Expected another analyzer to keep the defaults")
+	}
+}
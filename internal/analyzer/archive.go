@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isArchive reports whether the path refers to a supported archive format
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// listArchiveEntries returns the names of all entries in a zip or tar(.gz) archive.
+// Returns an error if the archive is corrupt or of an unsupported format.
+func listArchiveEntries(archivePath string) ([]string, error) {
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".zip") {
+		archive, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening %q: %w", archivePath, err)
+		}
+		defer archive.Close()
+
+		entries := make([]string, 0, len(archive.File))
+		for _, entry := range archive.File {
+			entries = append(entries, entry.Name)
+		}
+		return entries, nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", archivePath, err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing %q: %w", archivePath, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	var entries []string
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entries, fmt.Errorf("error reading %q: %w", archivePath, err)
+		}
+		entries = append(entries, header.Name)
+	}
+	return entries, nil
+}
+
+// isUnsafeArchiveEntry reports whether an entry name is absolute or escapes the
+// extraction directory with '..' segments
+func isUnsafeArchiveEntry(name string) bool {
+	normalized := toSlash(name)
+	if strings.HasPrefix(normalized, "/") || filepath.VolumeName(name) != "" ||
+		(len(normalized) > 1 && normalized[1] == ':') {
+		return true
+	}
+	for _, segment := range strings.Split(normalized, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// validateArchive checks a single archive: it must open, contain at least one entry,
+// must not contain absolute or '..' entries, and must contain every expected entry.
+// Returns one message per problem found.
+func validateArchive(archivePath string, expected []string) []string {
+	entries, err := listArchiveEntries(archivePath)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if len(entries) == 0 {
+		problems = append(problems, "archive is empty")
+	}
+
+	present := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if isUnsafeArchiveEntry(entry) {
+			problems = append(problems, fmt.Sprintf("entry '%s' is an absolute path or escapes the archive root", entry))
+		}
+		present[strings.TrimPrefix(toSlash(entry), "./")] = struct{}{}
+	}
+
+	for _, want := range expected {
+		want = strings.TrimPrefix(toSlash(want), "./")
+		if _, ok := present[want]; !ok {
+			problems = append(problems, fmt.Sprintf("expected entry '%s' not found", want))
+		}
+	}
+	return problems
+}
+
+// checkArchives validates the archives referenced by the script when 'archives.validate'
+// is enabled or expected contents are configured for them. Archives missing on the file
+// system are skipped, as they are reported by the file system references check.
+func (r *run) checkArchives(scriptFile string, lines []PathFlag) {
+	if !r.archiveSettings.Validate && len(r.archiveSettings.ExpectedContents) == 0 {
+		return
+	}
+	r.log.Debug("checking archives referenced in '{s}'", "s", scriptFile)
+
+	// Expected contents are keyed by the archive path with forward slashes, the
+	// configuration takes both separators
+	expectations := make(map[string][]string, len(r.archiveSettings.ExpectedContents))
+	for archive, contents := range r.archiveSettings.ExpectedContents {
+		expectations[slashPath(archive, "windows")] = contents
+	}
+
+	for _, f := range lines {
+		i := f.Line
+		if !isArchive(f.Path) {
+			continue
+		}
+		expected, hasExpectations := expectations[slashPath(f.Path, r.currentScriptTargetOS)]
+		if !r.archiveSettings.Validate && !hasExpectations {
+			continue
+		}
+		if !r.fileExists(f.Path) || r.referencesDirectory(f.Path) {
+			continue
+		}
+
+		archivePath := r.referenceFilePath(r.localPath(f.Path))
+		problems := validateArchive(archivePath, expected)
+		for _, problem := range problems {
+			r.reportFinding(Finding{Rule: RuleArchiveContents, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path},
+				"'{s}' line '{ln}': archive '{a}': {p}", "s", scriptFile, "ln", i, "a", f.Path, "p", problem)
+		}
+		if len(problems) == 0 {
+			r.log.Info("'{s}' line '{ln}': archive '{a}' is valid", "s", scriptFile, "ln", i, "a", f.Path)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests for archive contents validation
+
+func TestIsArchive(t *testing.T) {
+	cases := map[string]bool{
+		"packages/hotfix.zip":    true,
+		"packages/HOTFIX.ZIP":    true,
+		"packages/data.tar.gz":   true,
+		"packages/data.tgz":      true,
+		"packages/data.tar":      true,
+		"packages/readme.txt":    false,
+		"packages/zip_notes.xml": false,
+	}
+	for path, expected := range cases {
+		if result := isArchive(path); result != expected {
+			t.Errorf("isArchive(%q) = %v, want %v", path, result, expected)
+		}
+	}
+}
+
+func TestIsUnsafeArchiveEntry(t *testing.T) {
+	cases := map[string]bool{
+		"hotfix/install.xml": false,
+		"/etc/passwd":        true,
+		"C:\\temp\\file.xml": true,
+		"../outside.xml":     true,
+		"dir/../../file.xml": true,
+		"dir/file..name.xml": false,
+	}
+	for entry, expected := range cases {
+		if result := isUnsafeArchiveEntry(entry); result != expected {
+			t.Errorf("isUnsafeArchiveEntry(%q) = %v, want %v", entry, result, expected)
+		}
+	}
+}
+
+func TestValidateArchive_Zip(t *testing.T) {
+	// What: Missing expected entries and unsafe entries are reported
+	zipPath := filepath.Join(t.TempDir(), "hotfix.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"hotfix/install.xml": "<x/>",
+		"../escape.xml":      "<x/>",
+	})
+
+	problems := validateArchive(zipPath, []string{"hotfix/install.xml", "hotfix/missing.xml"})
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %d: %v", len(problems), problems)
+	}
+	joined := strings.Join(problems, "\n")
+	if !strings.Contains(joined, "../escape.xml") || !strings.Contains(joined, "hotfix/missing.xml") {
+		t.Errorf("Unexpected problems: %v", problems)
+	}
+}
+
+func TestValidateArchive_EmptyAndCorrupt(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	emptyZip := filepath.Join(tmpDir, "empty.zip")
+	writeTestZip(t, emptyZip, map[string]string{})
+	if problems := validateArchive(emptyZip, nil); len(problems) != 1 || problems[0] != "archive is empty" {
+		t.Errorf("Expected 'archive is empty', got %v", problems)
+	}
+
+	corruptZip := filepath.Join(tmpDir, "corrupt.zip")
+	if err := os.WriteFile(corruptZip, []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if problems := validateArchive(corruptZip, nil); len(problems) != 1 {
+		t.Errorf("Expected 1 problem for corrupt zip, got %v", problems)
+	}
+}
+
+func TestListArchiveEntries_TarGz(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "data.tar.gz")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	content := []byte("<x/>")
+	tw.WriteHeader(&tar.Header{Name: "data/file.xml", Mode: 0644, Size: int64(len(content))})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	entries, err := listArchiveEntries(archivePath)
+	assertNoError(t, err)
+	if len(entries) != 1 || entries[0] != "data/file.xml" {
+		t.Errorf("Unexpected entries: %v", entries)
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// isArtifactReference reports whether a script path is resolved in the artifact
+// repository instead of the file system
+func (r *run) isArtifactReference(p string) bool {
+	return r.artifactSettings.URL != "" && r.artifactSettings.PathPrefix != "" &&
+		strings.HasPrefix(toSlash(p), toSlash(r.artifactSettings.PathPrefix))
+}
+
+// artifactURL returns the repository URL of a script path below the configured prefix
+func (r *run) artifactURL(p string) string {
+	rel := strings.TrimPrefix(toSlash(p), toSlash(r.artifactSettings.PathPrefix))
+	return strings.TrimSuffix(r.artifactSettings.URL, "/") + "/" + strings.TrimPrefix(rel, "/")
+}
+
+// authorizeArtifactRequest adds the credentials read from the configured environment
+// variables: a bearer token (Artifactory access token) or basic authentication
+func (r *run) authorizeArtifactRequest(req *http.Request) error {
+	if r.artifactSettings.TokenEnv != "" {
+		token, ok := os.LookupEnv(r.artifactSettings.TokenEnv)
+		if !ok {
+			return fmt.Errorf("environment variable '%s' with the artifact repository token is not set", r.artifactSettings.TokenEnv)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if r.artifactSettings.UsernameEnv != "" {
+		username, ok := os.LookupEnv(r.artifactSettings.UsernameEnv)
+		if !ok {
+			return fmt.Errorf("environment variable '%s' with the artifact repository user is not set", r.artifactSettings.UsernameEnv)
+		}
+		req.SetBasicAuth(username, os.Getenv(r.artifactSettings.PasswordEnv))
+	}
+	return nil
+}
+
+// artifactExists checks an artifact with a HEAD request. Only 404 means missing,
+// other unexpected responses are returned as errors.
+func (r *run) artifactExists(client *http.Client, url string) (bool, error) {
+	resp, err := r.doRequest(client, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodHead, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, r.authorizeArtifactRequest(req)
+	})
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected response '%s'", resp.Status)
+	}
+}
+
+// checkArtifactReferences confirms that the artifacts referenced below the configured
+// path prefix exist in the artifact repository
+func (r *run) checkArtifactReferences(scriptFile string, lines []PathFlag) {
+	if r.artifactSettings.URL == "" {
+		return
+	}
+	r.log.Debug("checking artifact references in '{s}'", "s", scriptFile)
+
+	client := r.newHTTPClient()
+	for _, f := range lines {
+		i := f.Line
+		if !r.isArtifactReference(f.Path) {
+			continue
+		}
+		url := r.artifactURL(f.Path)
+		exists, err := r.artifactExists(client, url)
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleArtifactRepository, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path},
+				"'{s}' line '{ln}': artifact '{a}' cannot be checked: {e}", "s", scriptFile, "ln", i, "a", f.Path, "e", err.Error())
+			continue
+		}
+		if !exists {
+			r.reportFinding(Finding{Rule: RuleMissingArtifact, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path},
+				"'{s}' line '{ln}' is invalid: artifact '{a}' not found in the artifact repository ('{u}')", "s", scriptFile, "ln", i, "a", f.Path, "u", url)
+			continue
+		}
+		r.log.Info("'{s}' line '{ln}' is valid: artifact '{a}' exists in the artifact repository", "s", scriptFile, "ln", i, "a", f.Path)
+	}
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newArtifactServer serves the given artifact paths, answering 404 for all others.
+// Requests without the expected bearer token are rejected.
+func newArtifactServer(t *testing.T, token string, artifacts ...string) *httptest.Server {
+	t.Helper()
+	existing := make(map[string]bool)
+	for _, a := range artifacts {
+		existing[a] = true
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodHead || !existing[r.URL.Path] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setupArtifactTest(t *testing.T, url string) {
+	t.Helper()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.artifactSettings = artifactRepository{URL: url + "/repo/", PathPrefix: "packages/", TokenEnv: "TCX_TEST_ARTIFACT_TOKEN"}
+	t.Setenv("TCX_TEST_ARTIFACT_TOKEN", "secret")
+	t.Cleanup(func() { testRun.artifactSettings = artifactRepository{} })
+}
+
+// What: Existing artifacts pass, missing versions are reported with their line
+func TestCheckArtifactReferences(t *testing.T) {
+	server := newArtifactServer(t, "secret", "/repo/nw4-1.2.0.zip")
+	setupArtifactTest(t, server.URL)
+
+	testRun.checkArtifactReferences("deploy.sh", pathFlags(map[int]string{
+		3: "packages/nw4-1.2.0.zip",
+		5: "packages/nw4-1.3.0.zip",
+		7: "100-Config/a.xml",
+	}))
+
+	if len(testRun.analysisResult.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d: %v", len(testRun.analysisResult.Findings), testRun.analysisResult.Findings)
+	}
+	f := testRun.analysisResult.Findings[0]
+	if f.Rule != RuleMissingArtifact || f.Line != 5 || f.Path != "packages/nw4-1.3.0.zip" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+}
+
+// What: Rejected credentials are reported as repository errors, not as missing artifacts
+func TestCheckArtifactReferences_Unauthorized(t *testing.T) {
+	server := newArtifactServer(t, "other-token", "/repo/nw4-1.2.0.zip")
+	setupArtifactTest(t, server.URL)
+
+	testRun.checkArtifactReferences("deploy.sh", pathFlags(map[int]string{3: "packages/nw4-1.2.0.zip"}))
+
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleArtifactRepository {
+		t.Errorf("Expected one %s finding, got %v", RuleArtifactRepository, testRun.analysisResult.Findings)
+	}
+}
+
+// What: Missing token variable is reported instead of sending an anonymous request
+func TestCheckArtifactReferences_TokenNotSet(t *testing.T) {
+	server := newArtifactServer(t, "secret", "/repo/nw4-1.2.0.zip")
+	setupArtifactTest(t, server.URL)
+	testRun.artifactSettings.TokenEnv = "TCX_TEST_UNSET_ARTIFACT_TOKEN"
+
+	testRun.checkArtifactReferences("deploy.sh", pathFlags(map[int]string{3: "packages/nw4-1.2.0.zip"}))
+
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleArtifactRepository {
+		t.Errorf("Expected one %s finding, got %v", RuleArtifactRepository, testRun.analysisResult.Findings)
+	}
+}
+
+// What: Artifact references match the prefix with either separator and map to repository URLs
+func TestArtifactURL(t *testing.T) {
+	testRun.artifactSettings = artifactRepository{URL: "https://repo.example.com/tc/", PathPrefix: "packages/"}
+	defer func() { testRun.artifactSettings = artifactRepository{} }()
+
+	if !testRun.isArtifactReference(`packages\nw4-1.2.0.zip`) {
+		t.Error("Expected Windows path below the prefix to be an artifact reference")
+	}
+	if testRun.isArtifactReference("100-Config/a.xml") {
+		t.Error("Expected path outside the prefix not to be an artifact reference")
+	}
+	if url := testRun.artifactURL(`packages\nw4-1.2.0.zip`); url != "https://repo.example.com/tc/nw4-1.2.0.zip" {
+		t.Errorf("artifactURL() = %q", url)
+	}
+}
+
+// What: Artifact references are not checked on the file system
+func TestCheckFilePathsInScript_SkipsArtifactReferences(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.currentScript = "deploy.sh"
+	testRun.sourceCodeRoot = t.TempDir()
+	testRun.artifactSettings = artifactRepository{URL: "https://repo.example.com/tc", PathPrefix: "packages/"}
+	defer func() { testRun.artifactSettings = artifactRepository{} }()
+
+	testRun.checkFilePathsInScript("deploy.sh", pathFlags(map[int]string{1: "packages/nw4-1.2.0.zip"}))
+
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", testRun.analysisResult.Findings)
+	}
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Benchmarks of the validation pipeline over synthetic repositories, run with
+// go test -run '^$' -bench . ./internal/analyzer; the tree sizes are the file counts
+
+var benchmarkTreeSizes = []int{10000, 100000}
+
+// benchmarkIgnorePatterns exercise globs, `**` and a negation on every path
+var benchmarkIgnorePatterns = []string{"*.log", "**/tmp/**", "!**/tmp/keep.xml", "400-Data/**/*.log"}
+
+// benchmarkFilesPerDir is the number of files of each directory of a synthetic tree
+const benchmarkFilesPerDir = 100
+
+// benchmarkPath returns the relative path of the i-th file of a synthetic tree: files
+// spread over four top-level directories, every tenth one a log file
+func benchmarkPath(i int) string {
+	top := []string{"100-Config", "200-Stylesheets", "300-Workflows", "400-Data"}[i%4]
+	name := fmt.Sprintf("f%05d.xml", i)
+	if i%10 == 0 {
+		name = fmt.Sprintf("f%05d.log", i)
+	}
+	return top + "/" + fmt.Sprintf("d%04d", i/benchmarkFilesPerDir) + "/" + name
+}
+
+// benchmarkTree returns the synthetic repository of size files below dir, written on
+// first use so a sub-benchmark left out by -bench does not write its tree, and by the
+// first round only of one that runs
+func benchmarkTree(b *testing.B, dir string, size int) string {
+	b.Helper()
+	root := filepath.Join(dir, fmt.Sprintf("files-%d", size))
+	if _, err := os.Stat(root); err == nil {
+		return root
+	}
+	b.StopTimer()
+	defer b.StartTimer()
+	writeBenchmarkTree(b, root, size)
+	writeBenchmarkScript(b, filepath.Join(root, "deploy.sh"), size)
+	return root
+}
+
+// writeBenchmarkTree writes a synthetic repository of size files below root
+func writeBenchmarkTree(b *testing.B, root string, size int) {
+	b.Helper()
+	for i := 0; i < size; i++ {
+		path := filepath.Join(root, filepath.FromSlash(benchmarkPath(i)))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test content"), 0644); err != nil {
+			b.Fatalf("Failed to create file: %v", err)
+		}
+	}
+}
+
+// writeBenchmarkScript writes a Linux script importing the XML files of a synthetic
+// tree of size files, so a run reports no findings
+func writeBenchmarkScript(b *testing.B, path string, size int) {
+	b.Helper()
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	for i := 0; i < size; i++ {
+		if i%10 == 0 {
+			continue
+		}
+		fmt.Fprintf(&script, "plmxml_import -u=infodba -xml_file=\"%s\"\n", benchmarkPath(i))
+	}
+	if err := os.WriteFile(path, []byte(script.String()), 0755); err != nil {
+		b.Fatalf("Failed to create script: %v", err)
+	}
+}
+
+func BenchmarkTraversal(b *testing.B) {
+	dir := b.TempDir()
+	for _, size := range benchmarkTreeSizes {
+		size := size
+		b.Run(fmt.Sprintf("files=%d", size), func(b *testing.B) {
+			root := benchmarkTree(b, dir, size)
+			for i := 0; i < b.N; i++ {
+				if _, err := testRun.traverseAndCollect(root, benchmarkIgnorePatterns); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIgnorePatternMatching(b *testing.B) {
+	for _, size := range benchmarkTreeSizes {
+		size := size
+		paths := make([]string, size)
+		for i := range paths {
+			paths[i] = benchmarkPath(i)
+		}
+		b.Run(fmt.Sprintf("files=%d", size), func(b *testing.B) {
+			testRun.ignorePatternHits = make(map[string]int)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, path := range paths {
+					testRun.shouldIgnore(path, benchmarkIgnorePatterns)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	// The log of the runs would be timed with them
+	logger.SetConsoleOutput(io.Discard)
+	logger.InitLogger("", "error")
+	defer func() {
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+	}()
+
+	dir := b.TempDir()
+	for _, size := range benchmarkTreeSizes {
+		size := size
+		b.Run(fmt.Sprintf("files=%d", size), func(b *testing.B) {
+			params := Parameters{
+				SourceCodeRoot: benchmarkTree(b, dir, size),
+				Scripts:        []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+				PathParameters: []PathParameter{{Name: "xml_file"}},
+				IgnorePatterns: ignorePatterns{Global: append([]string{"deploy.sh"}, benchmarkIgnorePatterns...)},
+			}
+			for i := 0; i < b.N; i++ {
+				if _, err := testRun.analyze(params); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// tc_bin:
+//   linux: '$TC_BIN'
+//   windows: '%TC_BIN%'
+//   utilities: [site_install_stylesheets]
+//
+// $TC_BIN/plmxml_import -xml_file="..."     called through the bin prefix
+// plmxml_import -xml_file="..."             bare, resolved through the PATH of the server
+
+// Bin prefixes of the Teamcenter utilities when 'tc_bin' does not set them
+const (
+	defaultLinuxBinPrefix   = "$TC_BIN"
+	defaultWindowsBinPrefix = "%TC_BIN%"
+)
+
+// tcUtilities are the Teamcenter utilities checked for the bin prefix, with the
+// utilities of 'tc_bin'
+var tcUtilities = []string{
+	"plmxml_import", "plmxml_export", "tcxml_import", "tcxml_export",
+	"install_xml_stylesheet_datasets", "preferences_manager", "clsutility",
+	"make_user", "import_file", "business_model_updater", "tem",
+}
+
+// binPrefixRule is the compiled 'tc_bin' configuration, nil when not configured
+type binPrefixRule struct {
+	prefixes  map[string]string // target OS -> prefix
+	utilities map[string]bool   // normalized utility names
+}
+
+// compileBinPrefix returns the rule of the configuration, nil when 'tc_bin' is not set
+func compileBinPrefix(config TCBin) *binPrefixRule {
+	if config.Linux == "" && config.Windows == "" && len(config.Utilities) == 0 {
+		return nil
+	}
+	rule := &binPrefixRule{
+		prefixes:  map[string]string{"linux": defaultLinuxBinPrefix, "windows": defaultWindowsBinPrefix},
+		utilities: make(map[string]bool),
+	}
+	if prefix := strings.TrimSpace(config.Linux); prefix != "" {
+		rule.prefixes["linux"] = normalizeBinPrefix(prefix)
+	}
+	if prefix := strings.TrimSpace(config.Windows); prefix != "" {
+		rule.prefixes["windows"] = normalizeBinPrefix(prefix)
+	}
+	for _, utility := range append(append([]string{}, tcUtilities...), config.Utilities...) {
+		rule.utilities[normalizeExecutable(utility)] = true
+	}
+	return rule
+}
+
+// normalizeBinPrefix writes ${VAR} as $VAR and drops a trailing separator
+func normalizeBinPrefix(prefix string) string {
+	if strings.HasPrefix(prefix, "${") {
+		if end := strings.Index(prefix, "}"); end > 0 {
+			prefix = "$" + prefix[2:end] + prefix[end+1:]
+		}
+	}
+	return strings.TrimRight(prefix, `/\`)
+}
+
+// checkBinPrefix reports a call of a Teamcenter utility that is not made through the
+// bin prefix of the script target OS, e.g. a bare 'plmxml_import' resolved through
+// the PATH, which differs between the servers
+func (r *run) checkBinPrefix(scriptFile, line string, lineNumber int, continued bool) {
+	if r.binPrefix == nil || continued {
+		return
+	}
+	executable := extractExecutableName(line)
+	if !r.binPrefix.utilities[executable] {
+		return
+	}
+	offset := len(line) - len(strings.TrimLeft(line, " \t"))
+	command := strings.Trim(strings.TrimPrefix(strings.Fields(line)[0], "@"), `"'`)
+	prefix := r.binPrefix.prefixes[r.currentScriptTargetOS]
+	if called := normalizeBinPrefix(command); r.hasBinPrefix(called, prefix) {
+		return
+	}
+	r.reportFinding(Finding{Rule: RuleBareUtility, Script: scriptFile, Line: lineNumber, Column: characterColumn(line, offset), Path: executable},
+		"'{s}' line '{ln}': '{e}' is not called through '{p}'", "s", scriptFile, "ln", lineNumber, "e", executable, "p", prefix)
+}
+
+// hasBinPrefix reports whether the command starts with the prefix and a separator;
+// Windows variables are compared case-insensitively
+func (r *run) hasBinPrefix(command, prefix string) bool {
+	if len(command) <= len(prefix) || !strings.ContainsRune(`/\`, rune(command[len(prefix)])) {
+		return false
+	}
+	if r.currentScriptTargetOS == "windows" {
+		return strings.EqualFold(command[:len(prefix)], prefix)
+	}
+	return command[:len(prefix)] == prefix
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests for the bin prefix of the Teamcenter utilities
+
+// What: The check is off without 'tc_bin', prefixes default per OS and are normalized
+func TestCompileBinPrefix(t *testing.T) {
+	if rule := compileBinPrefix(TCBin{}); rule != nil {
+		t.Errorf("Expected no rule without 'tc_bin', got %+v", rule)
+	}
+	rule := compileBinPrefix(TCBin{Linux: "${TC_ROOT}/bin/", Utilities: []string{"Site_Install.sh"}})
+	if want := map[string]string{"linux": "$TC_ROOT/bin", "windows": "%TC_BIN%"}; !reflect.DeepEqual(rule.prefixes, want) {
+		t.Errorf("Expected %v, got %v", want, rule.prefixes)
+	}
+	if !rule.utilities["site_install"] || !rule.utilities["plmxml_import"] {
+		t.Errorf("Expected the configured and the built-in utilities, got %v", rule.utilities)
+	}
+}
+
+// What: Utilities called bare or through another path are TCX039 at the command column, calls through the prefix are not
+func TestCheckBinPrefix(t *testing.T) {
+	testRun.binPrefix = compileBinPrefix(TCBin{Windows: "%TC_BIN%"})
+	t.Cleanup(func() { testRun.binPrefix = nil })
+	tests := []struct {
+		targetOS  string
+		line      string
+		continued bool
+		want      bool
+	}{
+		{"linux", `$TC_BIN/plmxml_import -xml_file="a.xml"`, false, false},
+		{"linux", `${TC_BIN}/plmxml_import -xml_file="a.xml"`, false, false},
+		{"linux", `  plmxml_import -xml_file="a.xml"`, false, true},
+		{"linux", `/opt/tc/bin/plmxml_import -xml_file="a.xml"`, false, true},
+		{"linux", `$TC_BINX/plmxml_import -xml_file="a.xml"`, false, true},
+		{"linux", `./site_helper -xml_file="a.xml"`, false, false},
+		{"linux", `  tem -update`, true, false},
+		{"windows", `%tc_bin%\plmxml_import.exe -xml_file="a.xml"`, false, false},
+		{"windows", `@plmxml_import -xml_file="a.xml"`, false, true},
+	}
+	for _, tt := range tests {
+		testRun.analysisResult = Result{File: map[string]Lines{"deploy": newLines()}}
+		testRun.currentScriptTargetOS = tt.targetOS
+		testRun.checkBinPrefix("deploy", tt.line, 4, tt.continued)
+		if got := len(testRun.analysisResult.Findings) == 1; got != tt.want {
+			t.Errorf("%s %q: expected a finding %v, got %+v", tt.targetOS, tt.line, tt.want, testRun.analysisResult.Findings)
+		}
+	}
+
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy": newLines()}}
+	testRun.currentScriptTargetOS = "linux"
+	testRun.checkBinPrefix("deploy", "  plmxml_import -xml_file=\"a.xml\"", 4, false)
+	if f := testRun.analysisResult.Findings[0]; f.Rule != RuleBareUtility || f.Line != 4 || f.Column != 3 || f.Path != "plmxml_import" {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// stripComment removes the comment part of a script line according to the target OS
+// conventions and reports whether a comment was found:
+//   - linux: '#' starting a word outside quotes ('$#' and '${#VAR}' are not comments)
+//   - windows: lines starting with 'REM' or '::', and inline '& REM ...' / '& :: ...'
+func stripComment(line string, targetOS string) (string, bool) {
+	if targetOS == "windows" {
+		return stripBatchComment(line)
+	}
+	return stripShellComment(line)
+}
+
+func stripShellComment(line string) (string, bool) {
+	var quote rune
+	var previous rune = ' '
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (previous == ' ' || previous == '\t' || previous == ';'):
+			return strings.TrimRight(line[:i], " \t"), true
+		}
+		previous = r
+	}
+	return line, false
+}
+
+// isBatchCommentStart reports whether the text starts with a REM or '::' comment
+func isBatchCommentStart(text string) bool {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "@")
+	if strings.HasPrefix(text, "::") {
+		return true
+	}
+	lower := strings.ToLower(text)
+	return lower == "rem" || strings.HasPrefix(lower, "rem ") || strings.HasPrefix(lower, "rem\t")
+}
+
+func stripBatchComment(line string) (string, bool) {
+	if isBatchCommentStart(line) {
+		return "", true
+	}
+	inQuotes := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '&' && !inQuotes && isBatchCommentStart(line[i+1:]):
+			return strings.TrimRight(line[:i], " \t"), true
+		}
+	}
+	return line, false
+}
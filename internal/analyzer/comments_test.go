@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		targetOS   string
+		expected   string
+		hasComment bool
+	}{
+		{"shell full line", `# plmxml_import -i="old.xml"`, "linux", "", true},
+		{"shell indented", `   #plmxml_import -i="old.xml"`, "linux", "", true},
+		{"shell inline", `plmxml_import -i="new.xml" # was old.xml`, "linux", `plmxml_import -i="new.xml"`, true},
+		{"shell after semicolon", `cd /tmp;# comment`, "linux", `cd /tmp;`, true},
+		{"shell hash in quotes", `echo "a # b" -i="x.xml"`, "linux", `echo "a # b" -i="x.xml"`, false},
+		{"shell hash in word", `echo ${#ARR} $# a#b`, "linux", `echo ${#ARR} $# a#b`, false},
+		{"batch rem", `REM plmxml_import -i="old.xml"`, "windows", "", true},
+		{"batch rem lowercase with @", `@rem old`, "windows", "", true},
+		{"batch double colon", `:: plmxml_import -i="old.xml"`, "windows", "", true},
+		{"batch inline rem", `plmxml_import -i="new.xml" & REM was old.xml`, "windows", `plmxml_import -i="new.xml"`, true},
+		{"batch inline double colon", `plmxml_import -i="new.xml" &:: old`, "windows", `plmxml_import -i="new.xml"`, true},
+		{"batch remove is not rem", `remove_item -i="a.xml"`, "windows", `remove_item -i="a.xml"`, false},
+		{"batch label", `:main`, "windows", `:main`, false},
+		{"batch ampersand in quotes", `echo "a & rem b"`, "windows", `echo "a & rem b"`, false},
+		{"batch hash is no comment", `echo #1`, "windows", `echo #1`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, hasComment := stripComment(tt.line, tt.targetOS)
+			if result != tt.expected || hasComment != tt.hasComment {
+				t.Errorf("stripComment(%q) = (%q, %v), want (%q, %v)", tt.line, result, hasComment, tt.expected, tt.hasComment)
+			}
+		})
+	}
+}
+
+// What: Commented out flags are skipped, inline comments do not hide the command
+func TestCheckFileSyntax_Comments(t *testing.T) {
+	setupSyntaxTest()
+	root := t.TempDir()
+	content := "REM plmxml_import -i=\"old.xml\"\r\n:: plmxml_import -i=old.xml\r\nplmxml_import -i=\"new.xml\" & REM -i=\"other.xml\"\r\n"
+	if err := os.WriteFile(filepath.Join(root, "deploy.bat"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+
+	testRun.checkFileSyntax("deploy.bat", root, "windows")
+
+	lines := testRun.analysisResult.File["deploy.bat"]
+	if !reflect.DeepEqual(validPaths(lines), map[int]string{3: "new.xml"}) {
+		t.Errorf("Valid = %v, want only line 3", validPaths(lines))
+	}
+	if len(lines.InvalidLines()) != 0 {
+		t.Errorf("Expected no invalid lines, got %v", lines.InvalidLines())
+	}
+	if _, ok := lines.Skipped[1]; !ok {
+		t.Error("Expected comment line 1 to be skipped")
+	}
+	if _, ok := lines.Skipped[2]; !ok {
+		t.Error("Expected comment line 2 to be skipped")
+	}
+}
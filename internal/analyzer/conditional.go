@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+var (
+	shellBlockOpenRegex  = regexp.MustCompile(`^(if|case)\s`)
+	shellBlockCloseRegex = regexp.MustCompile(`(^|;\s*)(fi|esac)$`)
+)
+
+// blockTracker follows the if/else/fi and case/esac blocks of shell scripts and the
+// parenthesized IF/ELSE blocks of batch scripts while a script is read line by line
+type blockTracker struct {
+	targetOS    string
+	shellDepth  int
+	lineDepth   int    // shell blocks open on the last line, a block opened and closed on it included
+	batchBlocks []bool // open '(' blocks, true for IF/ELSE blocks
+	inlineIf    bool   // the last line is a single line 'IF condition command'
+}
+
+// update processes the next line and reports whether the line itself runs
+// only under a condition
+func (b *blockTracker) update(line string) bool {
+	line = strings.TrimSpace(line)
+	if b.targetOS == "windows" {
+		return b.updateBatch(strings.TrimPrefix(line, "@"))
+	}
+	return b.updateShell(line)
+}
+
+// depth returns the nesting of the conditional blocks on the last line
+func (b *blockTracker) depth() int {
+	if b.targetOS != "windows" {
+		return b.lineDepth
+	}
+	depth := 0
+	if b.inlineIf {
+		depth++
+	}
+	for _, isIf := range b.batchBlocks {
+		if isIf {
+			depth++
+		}
+	}
+	return depth
+}
+
+func (b *blockTracker) updateShell(line string) bool {
+	b.lineDepth = b.shellDepth
+	if strings.HasPrefix(line, "#") {
+		return b.shellDepth > 0
+	}
+	conditional := b.shellDepth > 0
+	if shellBlockOpenRegex.MatchString(line) {
+		b.shellDepth++
+		b.lineDepth = b.shellDepth
+	}
+	if shellBlockCloseRegex.MatchString(line) && b.shellDepth > 0 {
+		b.shellDepth--
+	}
+	return conditional
+}
+
+func (b *blockTracker) updateBatch(line string) bool {
+	lower := strings.ToLower(line)
+	conditional := b.inBatchCondition()
+	b.inlineIf = false
+
+	if strings.HasPrefix(line, ")") {
+		rest := strings.TrimSpace(lower[1:])
+		if !(strings.HasPrefix(rest, "else") && strings.HasSuffix(rest, "(")) && len(b.batchBlocks) > 0 {
+			b.batchBlocks = b.batchBlocks[:len(b.batchBlocks)-1]
+		}
+		return conditional
+	}
+	isIf := strings.HasPrefix(lower, "if ")
+	if strings.HasSuffix(line, "(") {
+		b.batchBlocks = append(b.batchBlocks, isIf)
+		return conditional
+	}
+	b.inlineIf = isIf
+	return conditional || isIf // single line 'IF condition command'
+}
+
+func (b *blockTracker) inBatchCondition() bool {
+	for _, isIf := range b.batchBlocks {
+		if isIf {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalOnly returns the paths referenced only by lines inside conditional blocks
+func conditionalOnly(references map[int][]string, conditionalLines map[int]bool) map[string]struct{} {
+	unconditional := make(map[string]struct{})
+	for ln, paths := range references {
+		if !conditionalLines[ln] {
+			for _, p := range paths {
+				unconditional[p] = struct{}{}
+			}
+		}
+	}
+	result := make(map[string]struct{})
+	for ln, paths := range references {
+		for _, p := range paths {
+			if _, ok := unconditional[p]; !ok && conditionalLines[ln] {
+				result[p] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// conditionalCovered reports whether files referenced only conditionally count as referenced
+func (r *run) conditionalCovered() bool {
+	return r.conditionalPolicy != "not_covered"
+}
+
+// reportConditionalReference reports a repository file referenced only inside
+// conditional blocks. Returns whether the reference satisfies the coverage check.
+func (r *run) reportConditionalReference(script, item string) bool {
+	if !r.conditionalCovered() {
+		r.reportFinding(Finding{Rule: RuleConditionalReference, Severity: SeverityError, Script: script, Path: item, hostPath: true},
+			"Filepath '{item}' is referenced only conditionally in the script file '{script}'", "item", item, "script", script)
+		return false
+	}
+	f := Finding{Rule: RuleConditionalReference, Script: script, Path: item, hostPath: true,
+		Message: logger.Format("Filepath '{item}' is conditionally deployed by the script file '{script}'", "item", item, "script", script)}
+	if r.recordFinding(f) && r.logsFinding(f) {
+		r.findingEntry(r.analysisResult.Findings[len(r.analysisResult.Findings)-1]).Info("'{item}' is conditionally deployed by the script file '{script}'", "item", item, "script", script)
+	}
+	return true
+}
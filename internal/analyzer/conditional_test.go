@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func conditionalLinesOf(targetOS string, lines []string) []int {
+	b := blockTracker{targetOS: targetOS}
+	var result []int
+	for i, l := range lines {
+		if b.update(l) {
+			result = append(result, i+1)
+		}
+	}
+	return result
+}
+
+// What: Lines inside shell if/else/fi and case/esac blocks are conditional
+func TestBlockTracker_Shell(t *testing.T) {
+	lines := []string{
+		"plmxml_import -xml_file=\"a.xml\"",
+		"if [ -f b.xml ]; then",
+		"  plmxml_import -xml_file=\"b.xml\"",
+		"else",
+		"  if [ \"$X\" = 1 ]; then echo; fi",
+		"fi",
+		"case \"$MODE\" in",
+		"  full) plmxml_import -xml_file=\"c.xml\" ;;",
+		"esac",
+		"plmxml_import -xml_file=\"d.xml\"",
+	}
+
+	expected := []int{3, 4, 5, 6, 8, 9}
+	if result := conditionalLinesOf("linux", lines); !reflect.DeepEqual(result, expected) {
+		t.Errorf("conditional lines = %v, want %v", result, expected)
+	}
+}
+
+// What: Lines inside batch IF/ELSE blocks and single-line IFs are conditional, FOR blocks are not
+func TestBlockTracker_Batch(t *testing.T) {
+	lines := []string{
+		"plmxml_import -xml_file=\"a.xml\"",
+		"IF EXIST b.xml (",
+		"  plmxml_import -xml_file=\"b.xml\"",
+		") ELSE (",
+		"  echo missing",
+		")",
+		"if \"%MODE%\"==\"full\" plmxml_import -xml_file=\"c.xml\"",
+		"for %%f in (*.xml) do (",
+		"  plmxml_import -xml_file=\"%%f\"",
+		")",
+	}
+
+	expected := []int{3, 4, 5, 6, 7}
+	if result := conditionalLinesOf("windows", lines); !reflect.DeepEqual(result, expected) {
+		t.Errorf("conditional lines = %v, want %v", result, expected)
+	}
+}
+
+// What: Paths referenced also unconditionally are not conditional-only
+func TestConditionalOnly(t *testing.T) {
+	references := map[int][]string{1: {"a.xml"}, 3: {"a.xml"}, 4: {"b.xml"}}
+	result := conditionalOnly(references, map[int]bool{3: true, 4: true})
+
+	expected := map[string]struct{}{"b.xml": {}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("conditionalOnly() = %v, want %v", result, expected)
+	}
+}
+
+func setupConditionalTest(t *testing.T, policy string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range []string{"a.xml", "b.xml"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte("<xml/>"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", f, err)
+		}
+	}
+	lines := newLines()
+	lines.Conditional[2] = true
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": lines}}
+	testRun.currentScript = "deploy.sh"
+	testRun.sourceCodeRoot = root
+	testRun.conditionalPolicy = policy
+	t.Cleanup(func() { testRun.conditionalPolicy = "" })
+	return root
+}
+
+// What: Conditional-only references are reported separately and count as coverage by default
+func TestCompareFilesWithScripts_ConditionalCovered(t *testing.T) {
+	root := setupConditionalTest(t, "")
+
+	if err := testRun.compareFilesWithScripts("deploy.sh", pathFlags(map[int]string{1: "a.xml", 2: "b.xml"}), root, nil); err != nil {
+		t.Fatalf("compareFilesWithScripts() failed: %v", err)
+	}
+
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleConditionalReference ||
+		testRun.analysisResult.Findings[0].Severity != SeverityInfo || testRun.analysisResult.Findings[0].Path != "b.xml" {
+		t.Errorf("Expected one info %s finding for b.xml, got %v", RuleConditionalReference, testRun.analysisResult.Findings)
+	}
+	if coverage := testRun.analysisResult.File["deploy.sh"].Coverage["."]; coverage.Referenced != 2 {
+		t.Errorf("Expected 2 referenced files, got %+v", coverage)
+	}
+}
+
+// What: With not_covered, conditional-only references fail the coverage check
+func TestCompareFilesWithScripts_ConditionalNotCovered(t *testing.T) {
+	root := setupConditionalTest(t, "not_covered")
+
+	if err := testRun.compareFilesWithScripts("deploy.sh", pathFlags(map[int]string{1: "a.xml", 2: "b.xml"}), root, nil); err != nil {
+		t.Fatalf("compareFilesWithScripts() failed: %v", err)
+	}
+
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Severity != SeverityError {
+		t.Errorf("Expected one error finding, got %v", testRun.analysisResult.Findings)
+	}
+	result := testRun.analysisResult.File["deploy.sh"]
+	if result.Coverage["."].Referenced != 1 || len(result.Unreferenced) != 1 {
+		t.Errorf("Expected 1 referenced and 1 unreferenced file, got %+v, unreferenced %v", result.Coverage["."], result.Unreferenced)
+	}
+}
+
+// What: The depth is the nesting of the blocks on a line, single line conditions included
+func TestBlockTracker_Depth(t *testing.T) {
+	tests := []struct {
+		targetOS string
+		lines    []string
+		expected []int
+	}{
+		{"linux", []string{
+			"if [ -f a.xml ]; then",
+			"  if [ \"$X\" = 1 ]; then echo; fi",
+			"  case \"$MODE\" in",
+			"    full) echo ;;",
+			"  esac",
+			"fi",
+			"echo",
+		}, []int{1, 2, 2, 2, 2, 1, 0}},
+		{"windows", []string{
+			"IF EXIST a.xml (",
+			"  IF \"%X%\"==\"1\" echo",
+			"  FOR %%f IN (*.xml) DO (",
+			"    echo %%f",
+			"  )",
+			")",
+			"echo",
+		}, []int{1, 2, 1, 1, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		b := blockTracker{targetOS: tt.targetOS}
+		var depths []int
+		for _, l := range tt.lines {
+			b.update(l)
+			depths = append(depths, b.depth())
+		}
+		if !reflect.DeepEqual(depths, tt.expected) {
+			t.Errorf("%s depths = %v, want %v", tt.targetOS, depths, tt.expected)
+		}
+	}
+}
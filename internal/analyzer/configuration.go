@@ -1,20 +1,396 @@
 package analyzer
 
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 type scriptDefinition struct {
-	Filename string `yaml:"filename"`
-	TargetOS string `yaml:"target_os"`
+	Filename   string `yaml:"filename"`
+	TargetOS   string `yaml:"target_os"`
+	WorkingDir string `yaml:"working_dir"` // directory the script runs in, relative to source_code_root
+
+	// How the directory content check matches repository files with the references:
+	// exact (default), suffix or basename
+	MatchStrategy string `yaml:"match_strategy"`
 }
 
 type ignorePatterns struct {
 	Global            []string `yaml:"global"`
 	StyleSheetsFolder []string `yaml:"stylesheets_folder"`
+	WorkflowsFolder   []string `yaml:"workflows_folder"`
+
+	// Global entries scoped to checks with the mapping form; the ones applying to
+	// the unreferenced check are in Global as well
+	Scoped []ScopedIgnorePattern `yaml:"-"`
+}
+
+// ScopedIgnorePattern is an ignore pattern applying to the listed checks only:
+// unreferenced, missing, parity or permissions
+type ScopedIgnorePattern struct {
+	Pattern string   `yaml:"pattern"`
+	Checks  []string `yaml:"checks"`
+}
+
+// UnmarshalYAML accepts plain patterns as well as the scoped mapping form in global
+func (p *ignorePatterns) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		Global            []yaml.Node `yaml:"global"`
+		StyleSheetsFolder []string    `yaml:"stylesheets_folder"`
+		WorkflowsFolder   []string    `yaml:"workflows_folder"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	// replaces the patterns decoded before, e.g. the top-level ones of a repository
+	*p = ignorePatterns{StyleSheetsFolder: raw.StyleSheetsFolder, WorkflowsFolder: raw.WorkflowsFolder}
+
+	for _, node := range raw.Global {
+		if node.Kind == yaml.ScalarNode {
+			p.Global = append(p.Global, node.Value)
+			continue
+		}
+		var scoped ScopedIgnorePattern
+		if err := node.Decode(&scoped); err != nil {
+			return err
+		}
+		if scoped.Pattern == "" || len(scoped.Checks) == 0 {
+			return fmt.Errorf("line %d: scoped ignore pattern needs 'pattern' and 'checks'", node.Line)
+		}
+		for _, check := range scoped.Checks {
+			if !isIgnoreCheck(check) {
+				return fmt.Errorf("line %d: invalid ignore check '%s' (must be one of %v)", node.Line, check, ignoreChecks)
+			}
+			if check == CheckUnreferenced {
+				p.Global = append(p.Global, scoped.Pattern)
+			}
+		}
+		p.Scoped = append(p.Scoped, scoped)
+	}
+	return nil
+}
+
+// templatePackage is the expected name and version of a BMIDE template package
+type templatePackage struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// archiveRules controls validation of archives referenced by the scripts
+type archiveRules struct {
+	Validate         bool                `yaml:"validate"`
+	ExpectedContents map[string][]string `yaml:"expected_contents"` // archive path -> inner paths
+}
+
+// windowsPathRules restricts UNC servers and drive letters used in Windows-target scripts.
+// Empty lists allow any server or drive.
+type windowsPathRules struct {
+	AllowedServers []string `yaml:"allowed_servers"`
+	AllowedDrives  []string `yaml:"allowed_drives"`
+}
+
+// thresholds defines the pass/fail policy evaluated at the end of the run.
+// Unset limits are not enforced.
+type thresholds struct {
+	MaxMissingFiles      *int               `yaml:"max_missing_files"`
+	MaxUnreferencedFiles *int               `yaml:"max_unreferenced_files"`
+	MinCoveragePercent   map[string]float64 `yaml:"min_coverage_percent"` // top-level directory -> percent
+
+	// Limits of the metrics of each script, flagging scripts grown beyond a maintainable size
+	MaxScriptLines        *int `yaml:"max_script_lines"`
+	MaxUtilityInvocations *int `yaml:"max_utility_invocations"`
+	MaxDistinctFiles      *int `yaml:"max_distinct_files"`
+	MaxConditionalDepth   *int `yaml:"max_conditional_depth"`
+}
+
+// remoteTarget is the deployment staging server validated over SFTP instead of
+// the local checkout. Credentials are an identity file and/or a password read from
+// an environment variable; host keys are verified against known_hosts.
+type remoteTarget struct {
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"` // default 22
+	User         string `yaml:"user"`
+	IdentityFile string `yaml:"identity_file"`
+	PasswordEnv  string `yaml:"password_env"`
+	KnownHosts   string `yaml:"known_hosts"` // default ~/.ssh/known_hosts
+	Root         string `yaml:"root"`
+}
+
+// artifactRepository is an Artifactory/Nexus repository in which script references
+// below PathPrefix are resolved. Credentials are read from environment variables:
+// a token (bearer) or a username and password (basic).
+type artifactRepository struct {
+	URL         string `yaml:"url"`
+	PathPrefix  string `yaml:"path_prefix"`
+	TokenEnv    string `yaml:"token_env"`
+	UsernameEnv string `yaml:"username_env"`
+	PasswordEnv string `yaml:"password_env"`
+}
+
+// networkPolicy configures the requests and connections of the network-backed checks;
+// the proxy is read from HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+type networkPolicy struct {
+	Timeout time.Duration `yaml:"timeout"` // per request or connection, default 30s
+	Retries *int          `yaml:"retries"` // tries after a failure, default 2
+	Backoff time.Duration `yaml:"backoff"` // delay before the first retry, doubled for each next, default 1s
+}
+
+// urlReferences configures the URLs referenced by path flags, e.g.
+// -file="https://repo/dataset.zip", which are reported instead of checked on the file system
+type urlReferences struct {
+	Check bool `yaml:"check"` // confirm that the http(s) URLs answer a HEAD request
+}
+
+// gitBranch compares the referenced paths with the base branch of the git repository
+// checked out in source_code_root, to report references to files deleted or renamed
+// in the current branch
+type gitBranch struct {
+	BaseRef string `yaml:"base_ref"` // e.g. origin/main
+
+	// Report the unreferenced files with the date and author of their last commit
+	UnreferencedAge bool `yaml:"unreferenced_age"`
+}
+
+// ownerMapping assigns the findings on paths matching a CODEOWNERS-style pattern to
+// an owner (team or email)
+type ownerMapping struct {
+	Pattern string `yaml:"pattern"`
+	Owner   string `yaml:"owner"`
+}
+
+// templating controls the validation of scripts generated from templates with
+// {{ }} markers
+type templating struct {
+	Mode   string            `yaml:"mode"`   // render: substitute the values, wildcard: expressions match any part of a file name
+	Values map[string]string `yaml:"values"` // value name -> value, substituted in render mode
+}
+
+// FlagRule requires or forbids flags on the invocations of a utility, e.g. -replace
+// for install_xml_stylesheet_datasets. Flags are written with or without the dash.
+type FlagRule struct {
+	Utility   string   `yaml:"utility"`
+	Required  []string `yaml:"required"`
+	Forbidden []string `yaml:"forbidden"`
+	Scripts   []string `yaml:"scripts"` // script filename patterns the rule applies to, all scripts when empty
+}
+
+// TCBin is the variable the scripts call the Teamcenter utilities through, per target
+// OS (default $TC_BIN and %TC_BIN%), with utilities checked besides the built-in ones
+type TCBin struct {
+	Linux     string   `yaml:"linux"`
+	Windows   string   `yaml:"windows"`
+	Utilities []string `yaml:"utilities"`
+}
+
+// CredentialFlag is a credential flag of the utilities, e.g. -u, -p or -g, with the
+// variables its value may reference and the literal values it may have
+type CredentialFlag struct {
+	Flag      string   `yaml:"flag"`
+	Variables []string `yaml:"variables"` // written NAME, $NAME, ${NAME} or %NAME%
+	Values    []string `yaml:"values"`    // e.g. dba for -g
+}
+
+// ContentRule is a site convention checked with a regular expression over the lines of
+// the scripts and of the referenced text files, e.g. no -replace in the prod scripts
+type ContentRule struct {
+	Pattern  string `yaml:"pattern"`  // regular expression (RE2 syntax) matched against each line
+	Files    string `yaml:"files"`    // pattern of the paths or file names checked, e.g. '*_prod.sh'; all when empty
+	Message  string `yaml:"message"`  // reported for each matching line
+	Severity string `yaml:"severity"` // info, warning or error; the severity of the ruleset when empty
+}
+
+// PreferenceMode is a -mode of preferences_manager with the -scope and -action values
+// legal with it; no -action is legal when no action is listed
+type PreferenceMode struct {
+	Mode    string   `yaml:"mode"`
+	Scopes  []string `yaml:"scopes"`  // e.g. SITE, GROUP, ROLE, USER
+	Actions []string `yaml:"actions"` // e.g. OVERRIDE, SKIP, MERGE
+}
+
+// StylesheetImporter is a utility importing stylesheet datasets like
+// install_xml_stylesheet_datasets, e.g. a site-specific wrapper script, with the names
+// of its input file and XML folder flags (default input and filepath)
+type StylesheetImporter struct {
+	Utility      string `yaml:"utility"`
+	InputFlag    string `yaml:"input_flag"`
+	FilepathFlag string `yaml:"filepath_flag"`
+}
+
+// StylesheetRendering is the structure a stylesheet XML needs to render: the root
+// element (default rendering), the attributes required on it and the elements required
+// in the document
+type StylesheetRendering struct {
+	Root       string             `yaml:"root"`
+	Attributes []string           `yaml:"attributes"`
+	Elements   []RenderingElement `yaml:"elements"`
+}
+
+// RenderingElement is an element required in a stylesheet XML, e.g. an objectSet, with
+// the attributes each occurrence needs, e.g. its source
+type RenderingElement struct {
+	Name       string   `yaml:"name"`
+	Attributes []string `yaml:"attributes"`
+}
+
+// ListImport declares a utility taking a list file whose rows reference other files,
+// e.g. preference lists, dataset import lists or ICS mapping files
+type ListImport struct {
+	Utility        string   `yaml:"utility"`
+	ListFlag       string   `yaml:"list_flag"`       // flag of the list file, default input
+	BaseFlag       string   `yaml:"base_flag"`       // flag of the folder the references are relative to
+	BasePath       string   `yaml:"base_path"`       // folder of the references without base flag, default the folder of the list
+	Column         int      `yaml:"column"`          // 1-based column of the reference, default 1
+	Delimiter      string   `yaml:"delimiter"`       // column delimiter, default ','; 'tab' for tabs
+	Comment        string   `yaml:"comment"`         // prefix of comment rows, e.g. '#'
+	HeaderRows     int      `yaml:"header_rows"`     // rows skipped at the start of the list
+	Complete       bool     `yaml:"complete"`        // files of the folder not listed are TCX020 findings
+	IgnorePatterns []string `yaml:"ignore_patterns"` // files of the folder not compared, relative to it
+	Nested         string   `yaml:"nested"`          // file name pattern of referenced list files read recursively, e.g. '*.lst'
+}
+
+// PathParameter is a flag whose value is a file path. In the configuration it is
+// either the flag name or a mapping with a style or a custom capture regex.
+type PathParameter struct {
+	Name  string `yaml:"name"`
+	Style string `yaml:"style"` // equals_quoted (default): -name="path", space_quoted: -name "path", bare: -name=path or -name path
+	Regex string `yaml:"regex"` // custom pattern, its first capture group is the path
+}
+
+// UnmarshalYAML accepts a plain flag name as well as the mapping form
+func (p *PathParameter) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Name = value.Value
+		return nil
+	}
+	type plain PathParameter
+	if err := value.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return fmt.Errorf("line %d: path parameter is missing 'name'", value.Line)
+	}
+	return nil
 }
 
 // Application configuration structure
 type Parameters struct {
+	// Compatibility of the policy, verified at startup
+	MinToolVersion string `yaml:"min_tool_version"` // lowest tool version able to run the policy
+	PolicyVersion  string `yaml:"policy_version"`   // version of the configuration format
+
 	Scripts        []scriptDefinition `yaml:"scripts"`
-	PathParameters []string           `yaml:"path_parameters"`
+	PathParameters []PathParameter    `yaml:"path_parameters"`
+	GNULongOptions bool               `yaml:"gnu_long_options"` // path flags may also be written as --name
 	SourceCodeRoot string             `yaml:"source_code_root"`
 	IgnorePatterns ignorePatterns     `yaml:"ignore_patterns"`
 	Logfile        string             `yaml:"logfile"`
+	LogFormat      string             `yaml:"log_format"` // text (default) or json, one object per line
+	// Timestamps of headings and JSON lines, UTC RFC3339 by default, e.g. 'datetime' and 'Europe/Berlin'
+	LogTimestampFormat string `yaml:"log_timestamp_format"`
+	LogTimezone        string `yaml:"log_timezone"`
+	// Runs of identical log lines are collapsed into "last message repeated N times",
+	// debug lines beyond the rate limit (per second, 0 is unlimited) are dropped
+	LogSuppressRepeats bool `yaml:"log_suppress_repeats"`
+	LogDebugRateLimit  int  `yaml:"log_debug_rate_limit"`
+	// Log levels of analysis phases overriding -l, e.g. {stylesheet: debug}
+	LogLevels map[string]string `yaml:"log_levels"`
+
+	ScriptsWithinRoot bool `yaml:"scripts_within_root"` // scripts may not resolve outside source_code_root
+
+	TemplatePackages []templatePackage `yaml:"template_packages"`
+	WorkflowsFolder  string            `yaml:"workflows_folder"`
+	Archives         archiveRules      `yaml:"archives"`
+	Symlinks         string            `yaml:"symlinks"` // follow (default), skip or error
+
+	// Compare files with the script references while walking the repository,
+	// retaining only the unreferenced paths instead of the full file list
+	StreamingComparison bool `yaml:"streaming_comparison"`
+
+	// Whether files referenced only inside if/else blocks count as referenced:
+	// covered (default) or not_covered
+	ConditionalReferences string `yaml:"conditional_references"`
+	ScanHeredocs          bool   `yaml:"scan_heredocs"` // report path flags in heredoc bodies as info
+
+	// Report referenced directories without files to deploy, ignored files left out
+	RequireNonEmptyDirectories bool `yaml:"require_non_empty_directories"`
+
+	// Scan the referenced text files for UTF-8 byte order marks, smart quotes and
+	// non-breaking spaces, which break the Teamcenter import parsers
+	CheckTextCharacters bool `yaml:"check_text_characters"`
+	// Extensions of the files scanned, .txt, .csv and .xml when empty
+	TextFileExtensions []string `yaml:"text_file_extensions"`
+
+	// Severity of the finding for scripts not encoded in UTF-8, which are transcoded:
+	// info, warning (default), error or ignore
+	ScriptEncoding string `yaml:"script_encoding"`
+
+	AllowedExternalPaths []string         `yaml:"allowed_external_paths"`
+	WindowsPaths         windowsPathRules `yaml:"windows_paths"`
+	Thresholds           thresholds       `yaml:"thresholds"`
+	Remote               remoteTarget     `yaml:"remote"`
+
+	// Longest durations of the analysis phases, or of the whole run for 'total',
+	// e.g. {traversal: 2s, total: 30s}; the run fails when one is exceeded
+	PerfBudget map[string]time.Duration `yaml:"perf_budget"`
+
+	// Built-in ruleset selecting the rules reported and their severities:
+	// lenient, standard (default) or strict
+	Ruleset string `yaml:"ruleset"`
+
+	// Stop the run after this many findings (0 is unlimited), or at the first error
+	MaxFindings int  `yaml:"max_findings"`
+	FailFast    bool `yaml:"fail_fast"`
+
+	ArtifactRepository artifactRepository `yaml:"artifact_repository"`
+	URLReferences      urlReferences      `yaml:"url_references"`
+	Network            networkPolicy      `yaml:"network"`
+	Git                gitBranch          `yaml:"git"`
+
+	Owners []ownerMapping `yaml:"owners"` // the last matching pattern owns a finding
+
+	FlagRules  []FlagRule `yaml:"flag_rules"` // required and forbidden flags per utility
+	Templating templating `yaml:"templating"`
+
+	// Executables the scripts may call, e.g. the approved Teamcenter utilities; file name
+	// patterns, any executable when empty
+	AllowedExecutables []string `yaml:"allowed_executables"`
+	// Bin prefix the Teamcenter utilities are called through, checked when set
+	TCBin TCBin `yaml:"tc_bin"`
+	// Variables and values approved for the credential flags, e.g. -u, -p and -g
+	CredentialFlags []CredentialFlag `yaml:"credential_flags"`
+	// Scopes and actions legal with the preferences_manager modes, replacing the
+	// built-in entries of the modes listed
+	PreferenceModes []PreferenceMode `yaml:"preference_modes"`
+	// Regular expressions the lines of the scripts and referenced text files must not match
+	ContentRules []ContentRule `yaml:"content_rules"`
+
+	// Utilities importing stylesheet datasets, install_xml_stylesheet_datasets when empty
+	StylesheetImporters []StylesheetImporter `yaml:"stylesheet_importers"`
+	// Structure required for the stylesheet XMLs to render, a <rendering> root when empty
+	StylesheetRendering StylesheetRendering `yaml:"stylesheet_rendering"`
+	// Utilities taking a list file whose rows reference other files
+	ListImports []ListImport `yaml:"list_imports"`
+
+	// Export of the stylesheets, preferences and templates installed in a Teamcenter
+	// environment (CSV or JSON), cross-checked with the items the scripts deploy
+	EnvironmentSnapshot string `yaml:"environment_snapshot"`
+
+	// JSONL file each validation run appends an audit record to: who ran it, on which
+	// host, the git commit, the configuration checksum and the verdict
+	AuditLog string `yaml:"audit_log"`
+	// File the executable x script parity matrix is written to, JSON for a .json file
+	// and CSV otherwise
+	ParityMatrix string `yaml:"parity_matrix"`
+	// HTML file the side-by-side diff of the Windows and Linux script pairs is written to
+	ParityDiff string `yaml:"parity_diff"`
+	// SHA-256 checksum of the configuration document, set when it is loaded
+	ConfigSHA256 string `yaml:"-"`
+
+	// Repositories validated in one run, each overriding the top-level parameters
+	Repositories []Repository `yaml:"repositories"`
+	// Parameters of the target environments overriding the top-level ones, the profile
+	// selected with -env, e.g. {prod: {scripts: [...], thresholds: {...}}}
+	Profiles map[string]Profile `yaml:"profiles"`
 }
@@ -4,35 +4,84 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
-	gitignore "github.com/sabhiram/go-gitignore"
 )
 
 // matchPattern checks if a path matches a given ignore pattern using gitignore-style matching.
 // It returns true if the path should be ignored according to the pattern.
-func matchPattern(pattern, path string) bool {
-	// Create a new GitIgnore object using the pattern
-	ignore := gitignore.CompileIgnoreLines(pattern)
-
-	// Check if the path should be ignored
-	matched := ignore.MatchesPath(path)
-
-	// Return the result
+func (r *run) matchPattern(pattern, path string) bool {
+	matched, _ := r.compiledIgnoreSet([]string{pattern}).match(path)
 	return matched
 }
 
 // shouldIgnore checks if a given path should be ignored based on a list of ignore patterns.
-// It returns true if the path matches any of the provided patterns.
-// Patterns follow gitignore-style syntax.
-func shouldIgnore(path string, ignorePatterns []string) bool {
-	for _, pattern := range ignorePatterns {
-		if matchPattern(pattern, path) {
-			logger.Debug("Excluding path '{path}' as it matches ignore pattern '{p}'", "path", path, "p", pattern)
-			return true
+// Patterns follow the gitignore syntax and are evaluated as an ordered set: the last
+// pattern matching the path decides, so negations re-include paths (see ignoreSet).
+func (r *run) shouldIgnore(path string, ignorePatterns []string) bool {
+	if len(ignorePatterns) == 0 {
+		return false
+	}
+	ignored, decidedBy := r.compiledIgnoreSet(ignorePatterns).match(path)
+	if decidedBy != "" && r.ignorePatternHits != nil {
+		r.ignorePatternHits[decidedBy]++
+	}
+	if ignored {
+		r.log.Debug("Excluding path '{path}' as it matches ignore pattern '{p}'", "path", path, "p", decidedBy)
+	} else if decidedBy != "" {
+		r.log.Debug("Including path '{path}' as it matches negated ignore pattern '{p}'", "path", path, "p", decidedBy)
+	}
+	return ignored
+}
+
+// symlinkPointsOutside reports whether the resolved symlink target lies outside boundary
+func symlinkPointsOutside(target, boundary string) bool {
+	if realBoundary, err := filepath.EvalSymlinks(boundary); err == nil {
+		boundary = realBoundary
+	}
+	rel, err := filepath.Rel(boundary, target)
+	if err != nil {
+		return true
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// patternWasUsed reports whether a configured pattern excluded any path
+func (r *run) patternWasUsed(pattern string) bool {
+	return r.ignorePatternHits[pattern] > 0
+}
+
+// checkUnusedIgnorePatterns reports ignore_patterns entries that never matched a path,
+// as stale exclusions may hide newly added files from the coverage check
+func (r *run) checkUnusedIgnorePatterns(patterns ignorePatterns) {
+	r.log.Heading(" ")
+	r.log.Separate("UNUSED IGNORE PATTERNS")
+	r.log.Separate("=====================================")
+
+	groups := []struct {
+		name     string
+		patterns []string
+	}{
+		{"global", append(append([]string{}, patterns.Global...), scopedOnly(patterns)...)},
+		{"stylesheets_folder", patterns.StyleSheetsFolder},
+		{"workflows_folder", patterns.WorkflowsFolder},
+	}
+
+	unused := 0
+	for _, group := range groups {
+		for _, pattern := range group.patterns {
+			if !r.patternWasUsed(pattern) {
+				r.reportFinding(Finding{Rule: RuleUnusedIgnorePattern, Path: pattern, Suggestion: "remove the pattern from ignore_patterns." + group.name},
+					"'{p}' (ignore_patterns.{g}) did not match any path", "p", pattern, "g", group.name)
+				unused++
+			}
 		}
 	}
-	return false
+	if unused == 0 {
+		r.log.Separate("none")
+	}
 }
 
 // traverseAndCollect recursively walks a directory tree and collects file paths,
@@ -49,63 +98,181 @@ func shouldIgnore(path string, ignorePatterns []string) bool {
 //
 // The function logs errors immediately when encountered but continues traversing to collect
 // as many valid paths as possible. Directories matching ignore patterns are skipped entirely.
-func traverseAndCollect(root string, ignorePatterns []string) ([]string, error) {
+//
+// Symbolic links are handled according to the 'symlinks' configuration option:
+//   - follow (default): symlinked files are collected, symlinked directories are descended
+//     into; directories already visited are not entered again, which protects against cycles
+//   - skip: symlinks are not collected
+//   - error: every symlink is reported as an error and not collected
+//
+// Symlinks resolving outside the source code root are always reported as errors, as
+// they break deployments to Windows hosts.
+//
+// When a remote target is configured the files listed on the remote are returned instead.
+func (r *run) traverseAndCollect(root string, ignorePatterns []string) ([]string, error) {
 	var files []string
+	err := r.walkRepository(root, ignorePatterns, func(relPath string) {
+		files = append(files, relPath)
+	})
+	return files, err
+}
+
+// walkRepository walks the directory tree as described for traverseAndCollect and
+// calls visit with the relative path of each file found, without collecting them.
+func (r *run) walkRepository(root string, ignorePatterns []string, visit func(relPath string)) error {
+	if r.remoteTree != nil {
+		for _, file := range r.remoteFiles(ignorePatterns) {
+			visit(file)
+		}
+		return nil
+	}
+
 	var errors []error
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		// Handle access errors first - before trying to use path/info
-		if err != nil {
-			logger.Error("Error accessing path '{p}': {e}", "p", path, "e", err.Error())
-			errors = append(errors, fmt.Errorf("path %s: %w", path, err))
+	boundary := r.sourceCodeRoot
+	if boundary == "" {
+		boundary = root
+	}
+	visited := make(map[string]bool) // resolved directory paths already walked
+	nested := make(nestedIgnores)    // ignore files found in the walked directories
 
-			// If it's a directory we can't access, skip it entirely
-			// Note: info might be nil if the path doesn't exist at all
-			if info != nil && info.IsDir() {
-				return filepath.SkipDir
+	// Symlinked directories are walked after the regular tree, so real paths
+	// take precedence over links pointing into the tree
+	type pendingDir struct{ target, relPath string }
+	var pending []pendingDir
+	follow := func(target, relPath string) {
+		pending = append(pending, pendingDir{target, relPath})
+	}
+
+	walkDir := func(dir string, relPrefix string) {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			// Handle access errors first - before trying to use path/info
+			if err != nil {
+				r.reportFinding(Finding{Rule: RuleTraversalError, Path: path, hostPath: true},
+					"Error accessing path '{p}': {e}", "p", path, "e", err.Error())
+				errors = append(errors, fmt.Errorf("path %s: %w", path, err))
+
+				// If it's a directory we can't access, skip it entirely
+				// Note: info might be nil if the path doesn't exist at all
+				if info != nil && info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil // Skip this file, continue with siblings
 			}
-			return nil // Skip this file, continue with siblings
-		}
 
-		// Now we know the path is accessible - calculate relative path
-		relPath, err := filepath.Rel(root, path)
-		if err != nil {
-			logger.Error("Error calculating relative path for '{p}': {e}", "p", path, "e", err.Error())
-			errors = append(errors, fmt.Errorf("relative path %s: %w", path, err))
-			return nil // Skip this file, continue walking
-		}
+			// Now we know the path is accessible - calculate relative path
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				r.reportFinding(Finding{Rule: RuleTraversalError, Path: path, hostPath: true},
+					"Error calculating relative path for '{p}': {e}", "p", path, "e", err.Error())
+				errors = append(errors, fmt.Errorf("relative path %s: %w", path, err))
+				return nil // Skip this file, continue walking
+			}
+			if relPrefix != "" {
+				relPath = filepath.Join(relPrefix, relPath)
+			}
+
+			// Check if path matches ignore patterns, configured or from the ignore files
+			if r.shouldIgnore(relPath, ignorePatterns) || r.nestedIgnored(nested, relPath) {
+				if info.IsDir() && r.compiledIgnoreSet(ignorePatterns).mayReinclude(relPath) {
+					r.log.Debug("Walking ignored directory '{relPath}' as a negated pattern may re-include paths below it", "relPath", relPath)
+					return nil
+				}
+				if info.IsDir() {
+					r.log.Debug("Skipping directory '{relPath}' (matches ignore pattern)", "relPath", relPath)
+					return filepath.SkipDir // Don't descend into this directory
+				}
+				// File is ignored, skip it
+				return nil
+			}
+
+			if info.Mode()&os.ModeSymlink != 0 {
+				return r.handleSymlink(path, relPath, boundary, visit, follow)
+			}
 
-		// Check if path matches ignore patterns
-		if shouldIgnore(relPath, ignorePatterns) {
-			if info.IsDir() {
-				logger.Debug("Skipping directory '{relPath}' (matches ignore pattern)", "relPath", relPath)
-				return filepath.SkipDir // Don't descend into this directory
+			// Path is accessible and not ignored - process it
+			if !info.IsDir() && info.Name() == ignoreFileName {
+				r.log.Debug("Excluding path '{relPath}' as it is an ignore file", "relPath", relPath)
+			} else if !info.IsDir() {
+				r.log.Debug("Path '{relPath}' should be checked if existing in the script file.", "relPath", relPath)
+				visit(relPath)
+			} else {
+				if realPath, err := filepath.EvalSymlinks(path); err == nil {
+					if visited[realPath] {
+						r.log.Debug("Skipping directory '{relPath}' as it was already visited", "relPath", relPath)
+						return filepath.SkipDir
+					}
+					visited[realPath] = true
+				}
+				r.loadIgnoreFile(nested, path, relPath)
+				r.log.Debug("Excluding path '{relPath}' as it is a directory", "relPath", relPath)
 			}
-			// File is ignored, skip it
 			return nil
-		}
+		})
 
-		// Path is accessible and not ignored - process it
-		if !info.IsDir() {
-			logger.Debug("Path '{relPath}' should be checked if existing in the script file.", "relPath", relPath)
-			files = append(files, relPath)
-		} else {
-			logger.Debug("Excluding path '{relPath}' as it is a directory", "relPath", relPath)
+		// Critical error from filepath.Walk itself
+		if err != nil {
+			errors = append(errors, fmt.Errorf("error walking directory tree: %w", err))
 		}
-		return nil
-	})
+	}
+	walkDir(root, "")
+	for len(pending) > 0 {
+		next := pending[0]
+		pending = pending[1:]
+		if visited[next.target] {
+			r.log.Debug("Not following symlink '{relPath}': '{t}' was already visited", "relPath", next.relPath, "t", next.target)
+			continue
+		}
+		r.log.Debug("Following symlinked directory '{relPath}' to '{t}'", "relPath", next.relPath, "t", next.target)
+		walkDir(next.target, next.relPath)
+	}
+
+	// Error summary, the files visited before are valid partial results
+	if len(errors) > 0 {
+		return fmt.Errorf("encountered %d errors during traversal (see logs for details)", len(errors))
+	}
+
+	return nil
+}
 
-	// Critical error from filepath.Walk itself
+// handleSymlink applies the configured symlink policy to a single symlink met during
+// traversal. Symlinked files are passed to visit, symlinked directories are handed
+// to follow when following symlinks.
+func (r *run) handleSymlink(path, relPath, boundary string, visit func(relPath string), follow func(target, relPath string)) error {
+	target, err := filepath.EvalSymlinks(path)
 	if err != nil {
-		errors = append(errors, fmt.Errorf("error walking directory tree: %w", err))
+		r.reportFinding(Finding{Rule: RuleSymlinkOutsideRoot, Path: relPath, hostPath: true},
+			"Symlink '{relPath}' is broken: {e}", "relPath", relPath, "e", err.Error())
+		return nil
+	}
+	if symlinkPointsOutside(target, boundary) {
+		r.reportFinding(Finding{Rule: RuleSymlinkOutsideRoot, Path: relPath, hostPath: true, Suggestion: "replace the symlink with a copy of '" + target + "'"},
+			"Symlink '{relPath}' points outside the source code root to '{t}'", "relPath", relPath, "t", target)
 	}
 
-	// Return partial results + error summary
-	if len(errors) > 0 {
-		return files, fmt.Errorf("encountered %d errors during traversal (see logs for details)", len(errors))
+	switch r.symlinkPolicy {
+	case "skip":
+		r.log.Debug("Skipping symlink '{relPath}'", "relPath", relPath)
+		return nil
+	case "error":
+		r.reportFinding(Finding{Rule: RuleSymlinkNotAllowed, Path: relPath, hostPath: true},
+			"Symlink '{relPath}' found, symlinks are not allowed", "relPath", relPath)
+		return nil
 	}
 
-	return files, nil
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		r.reportFinding(Finding{Rule: RuleTraversalError, Path: target, hostPath: true},
+			"Error accessing symlink target '{t}': {e}", "t", target, "e", err.Error())
+		return nil
+	}
+	if !targetInfo.IsDir() {
+		r.log.Debug("Path '{relPath}' should be checked if existing in the script file.", "relPath", relPath)
+		visit(relPath)
+		return nil
+	}
+	follow(target, relPath)
+	return nil
 }
 
 // compareFilesWithScripts compares files found in the repository with paths referenced
@@ -113,7 +280,7 @@ func traverseAndCollect(root string, ignorePatterns []string) ([]string, error)
 //
 // Parameters:
 //   - script: name of the script file being validated
-//   - validLines: map of line numbers to file paths extracted from the script
+//   - validLines: valid path flags of the script, with the paths localized
 //   - root: root directory of the repository to scan
 //   - ignorePatterns: list of gitignore-style patterns to exclude from validation
 //
@@ -123,53 +290,247 @@ func traverseAndCollect(root string, ignorePatterns []string) ([]string, error)
 // The function logs detailed information about files found, files referenced in the script,
 // and any discrepancies. It continues validation even if some paths are inaccessible,
 // logging errors but returning partial results.
-func compareFilesWithScripts(script string, validLines map[int]string, root string, ignorePatterns []string) error {
-	logger.Info("Comparison if all repositry files are referenced in the script started for '{script}'", "script", script)
-	logger.Info("Repository root is '{r}'", "r", root)
-	logger.Info("ignorePatterns are '{ignorePatterns}'", "ignorePatterns", ignorePatterns)
-
-	filesFound, err := traverseAndCollect(root, ignorePatterns)
+func (r *run) compareFilesWithScripts(script string, validLines []PathFlag, root string, ignorePatterns []string) error {
+	r.log.Info("Comparison if all repositry files are referenced in the script started for '{script}'", "script", script)
+	r.log.Info("Repository root is '{r}'", "r", root)
+	r.log.Info("ignorePatterns are '{ignorePatterns}'", "ignorePatterns", ignorePatterns)
 
-	// Log the results even if there were errors
-	logger.Info("'{files}' files found in the repository after skipping the ignore lines", "files", len(filesFound))
-	for i := 0; i < len(filesFound); i++ {
-		logger.Debug("\t'{f}'", "f", filesFound[i])
+	// References of the script, including the files matched by for-loop references
+	valueSet := make(map[string]struct{})
+	references := make(map[int][]string, len(validLines))
+	for _, reference := range validLines {
+		valueSet[reference.Path] = struct{}{}
+		references[reference.Line] = append(references[reference.Line], reference.Path)
+	}
+	var conditionalLines map[int]bool
+	if result, ok := r.analysisResult.File[script]; ok {
+		conditionalLines = result.Conditional
+		for ln, ref := range result.LoopReference {
+			for _, match := range ref.Matches {
+				valueSet[match] = struct{}{}
+				references[ln] = append(references[ln], match)
+			}
+		}
 	}
+	conditional := conditionalOnly(references, conditionalLines)
+	directories := r.referencedDirectories(validLines)
+	// the list files of list imports are compared exactly, their references are resolved
+	strategy := MatchExact
+	if script == r.currentScript {
+		strategy = r.matchStrategy
+	}
+	index := newReferenceIndex(strategy, valueSet)
+	countCoverage := r.coverageCounter(root)
 
-	// If there were errors during traversal, log summary but continue with comparison
-	if err != nil {
-		logger.Error("Errors occurred during directory traversal: {e}", "e", err.Error())
-		// Don't return here - still do the comparison with partial results
+	// compare checks a single repository file against the script references.
+	// Unreferenced and conditionally referenced files are reported after the walk,
+	// sorted, so the report does not depend on the walk order.
+	filesCompared := 0
+	var unreferenced, conditionallyReferenced []string
+	compare := func(item string) {
+		filesCompared++
+		referenced := false
+		// Files matched by the strategy of the script, or below a referenced directory,
+		// are referenced by that reference
+		reference := item
+		if _, ok := valueSet[item]; !ok {
+			if matched := index.match(item, valueSet); matched != "" {
+				reference = matched
+			} else if dir := creditingDirectory(item, directories); dir != "" {
+				reference = dir
+				directories[dir].files++
+			}
+		}
+		// Check if the item exists in valueSet
+		if _, ok := valueSet[reference]; !ok {
+			unreferenced = append(unreferenced, item)
+		} else if _, ok := conditional[reference]; ok {
+			conditionallyReferenced = append(conditionallyReferenced, item)
+			referenced = r.conditionalCovered()
+		} else if _, ok := directories[reference]; ok && reference != item {
+			r.log.Info("'{item}' is found in the script file '{script}' below directory '{d}'", "item", item, "script", script, "d", reference)
+			referenced = true
+		} else if reference != item {
+			r.log.Info("'{item}' is found in the script file '{script}' as '{r}' ({m} match)", "item", item, "script", script, "r", reference, "m", strategy)
+			referenced = true
+		} else {
+			r.log.Info("'{item}' is found in the script file '{script}'", "item", item, "script", script)
+			referenced = true
+		}
+		countCoverage(item, referenced)
 	}
 
-	valueSet := make(map[string]struct{})
-	logger.Info("'{valid}' valid lines found in script '{s}'", "valid", len(validLines), "s", script)
-	for _, v := range validLines {
-		logger.Debug("\t'{v}'", "v", v)
+	var err error
+	if r.streamingComparison {
+		// Only the unreferenced files are retained, in the findings
+		r.log.Info("'{valid}' valid lines found in script '{s}'", "valid", len(validLines), "s", script)
+		r.log.Debug("Comparing repository files with the script '{s}' while walking the repository...", "s", script)
+		err = r.walkRepository(root, ignorePatterns, compare)
+		r.log.Info("'{files}' files found in the repository after skipping the ignore lines", "files", filesCompared)
+	} else {
+		var filesFound []string
+		filesFound, err = r.traverseAndCollect(root, ignorePatterns)
+
+		// Log the results even if there were errors
+		r.log.Info("'{files}' files found in the repository after skipping the ignore lines", "files", len(filesFound))
+		for i := 0; i < len(filesFound); i++ {
+			r.log.Debug("\t'{f}'", "f", filesFound[i])
+		}
+		r.log.Info("'{valid}' valid lines found in script '{s}'", "valid", len(validLines), "s", script)
+		for _, v := range validLines {
+			r.log.Debug("\t'{v}'", "v", v)
+		}
+
+		r.log.Debug("Searching for files in the repository that are not present as valid lines in the script '{s}'...", "s", script)
+		for _, item := range filesFound {
+			compare(item)
+		}
 	}
 
-	for _, value := range validLines {
-		valueSet[value] = struct{}{}
+	// If there were errors during traversal, log summary - the comparison used partial results
+	if err != nil {
+		r.log.Error("Errors occurred during directory traversal: {e}", "e", err.Error())
 	}
-	logger.Debug("Searching for files in the repository that are not present as valid lines in the script '{s}'...", "s", script)
-	// Iterate through the slice and check each item
-	hasErrors := false
-	for _, item := range filesFound {
-		// Check if the item exists in valueSet
-		if _, ok := valueSet[item]; !ok {
-			logger.Error("Filepath '{item}' does not exist in the script file '{script}'", "item", item, "script", script)
-			hasErrors = true
-		} else {
-			logger.Info("'{item}' is found in the script file '{script}'", "item", item, "script", script)
+
+	r.checkEmptyDirectories(script, directories)
+
+	sort.Strings(conditionallyReferenced)
+	var notCovered []string
+	for _, item := range conditionallyReferenced {
+		if !r.reportConditionalReference(script, item) {
+			notCovered = append(notCovered, item)
 		}
 	}
+	sort.Strings(unreferenced)
+	r.reportUnreferencedFiles(script, root, unreferenced, notCovered, r.staleRenames(root, unreferenced, validLines))
 
-	if !hasErrors && len(filesFound) > 0 {
-		logger.Info("All repository files are referenced in the script")
-	} else if len(filesFound) == 0 {
-		logger.Info("No files found in repository to check")
+	if len(unreferenced)+len(notCovered) == 0 && filesCompared > 0 {
+		r.log.Info("All repository files are referenced in the script")
+	} else if filesCompared == 0 {
+		r.log.Info("No files found in repository to check")
 	}
 
 	// Return the error at the end so caller knows issues occurred
 	return err
 }
+
+// reportUnreferencedFiles reports the sorted files under root not referenced by the
+// script and adds them, with the files counted as not covered by conditional references,
+// to the result of the script being processed. The files are reported and recorded
+// relative to the source code root, once: a file of a sub-folder comparison (the
+// workflows folder, a list import folder) is also found by the traversal of the source
+// code root. Files renamed in the current branch whose old name is still referenced are
+// reported as stale references.
+func (r *run) reportUnreferencedFiles(script, root string, unreferenced, notCovered []string, stale map[string]staleReference) {
+	prefix := r.rootPrefix(root)
+	result, recording := r.analysisResult.File[r.currentScript]
+	recorded := make(map[string]bool, len(result.Unreferenced))
+	for _, item := range result.Unreferenced {
+		recorded[item] = true
+	}
+
+	r.log.Separate("UNREFERENCED FILES in '{root}'", "root", root)
+	var reported []string
+	for _, item := range unreferenced {
+		relPath := filepath.Join(prefix, item)
+		if recorded[relPath] {
+			r.log.Debug("'{item}' is already reported as unreferenced", "item", relPath)
+			continue
+		}
+		reported = append(reported, relPath)
+		if ref, ok := stale[item]; ok {
+			f := Finding{Rule: RuleStaleRename, Script: script, Line: ref.Line, Column: ref.Column, Path: relPath, hostPath: true}
+			f.Suggestion = logger.Format("reference '{item}' instead of '{old}'", "item", item, "old", ref.OldPath)
+			r.reportFinding(f, "Filepath '{item}' is referenced by its name before the rename '{old}' in the script file '{script}'", "item", relPath, "old", ref.OldPath, "script", script)
+			continue
+		}
+		r.reportFinding(Finding{Rule: RuleUnreferencedFile, Script: script, Path: relPath, hostPath: true},
+			"Filepath '{item}' does not exist in the script file '{script}'{age}", "item", relPath, "script", script, "age", r.unreferencedAge(relPath))
+	}
+	if len(reported) == 0 {
+		r.log.Separate("none")
+	}
+	r.logUnreferencedByAge(reported)
+
+	if !recording {
+		return
+	}
+	all := append([]string{}, reported...)
+	for _, item := range notCovered {
+		if relPath := filepath.Join(prefix, item); !recorded[relPath] {
+			recorded[relPath] = true
+			all = append(all, relPath)
+		}
+	}
+	sort.Strings(all)
+	result.Unreferenced = append(result.Unreferenced, all...)
+	for _, item := range all {
+		if commit, ok := r.lastCommit(item); ok {
+			if result.LastCommits == nil {
+				result.LastCommits = make(map[string]FileCommit)
+			}
+			result.LastCommits[item] = commit
+		}
+	}
+	r.analysisResult.File[r.currentScript] = result
+}
+
+// topLevelDirectory returns the first segment of a path relative to the source code
+// root, or "." for files directly in the root
+func topLevelDirectory(relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	if idx := strings.Index(relPath, "/"); idx != -1 {
+		return relPath[:idx]
+	}
+	return "."
+}
+
+// rootPrefix returns the path of root relative to the source code root, "" for the
+// source code root itself. Paths found are relative to root, which may be a folder
+// below the source code root.
+func (r *run) rootPrefix(root string) string {
+	if rel, err := filepath.Rel(r.sourceCodeRoot, root); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return ""
+}
+
+// coverageCounter returns a function adding a single file found under root to the
+// per top-level directory coverage of the script being processed. Only the traversal
+// of the source code root counts: the files of the sub-folder comparisons (the workflows
+// folder, a list import folder) are found by it too.
+func (r *run) coverageCounter(root string) func(file string, referenced bool) {
+	lines, ok := r.analysisResult.File[r.currentScript]
+	if !ok || lines.Coverage == nil || root != r.sourceCodeRoot {
+		return func(string, bool) {}
+	}
+
+	return func(file string, referenced bool) {
+		dir := topLevelDirectory(file)
+		coverage := lines.Coverage[dir]
+		coverage.Present++
+		if referenced {
+			coverage.Referenced++
+		}
+		lines.Coverage[dir] = coverage
+	}
+}
+
+// logCoverage prints the per top-level directory coverage, sorted by directory
+func (r *run) logCoverage(coverage map[string]DirectoryCoverage) {
+	if len(coverage) == 0 {
+		return
+	}
+	r.log.Separate("COVERAGE PER DIRECTORY")
+
+	dirs := make([]string, 0, len(coverage))
+	for dir := range coverage {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		c := coverage[dir]
+		r.log.Separate("  {d}: {r}/{p} files referenced ({pct}%)", "d", dir, "r", c.Referenced, "p", c.Present, "pct", fmt.Sprintf("%.0f", c.Percent()))
+	}
+}
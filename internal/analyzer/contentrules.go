@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// content_rules:
+//   - pattern: '-replace\b'
+//     files: '*_prod.sh'
+//     message: 'prod scripts must not overwrite the stylesheets'
+//     severity: error
+//   - pattern: 'http://'
+//     files: '*.csv'
+//     message: 'use https'
+
+// compiledContentRule is a content rule ready to be matched against lines
+type compiledContentRule struct {
+	ContentRule
+	pattern *regexp.Regexp
+}
+
+// ValidateContentRules checks that each content rule has a valid pattern, file glob
+// and severity
+func ValidateContentRules(rules []ContentRule) error {
+	for i, r := range rules {
+		if r.Pattern == "" {
+			return fmt.Errorf("content rule at index %d is missing 'pattern'", i)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("content rule '%s' has an invalid pattern: %w", r.Pattern, err)
+		}
+		if _, err := path.Match(r.Files, ""); err != nil {
+			return fmt.Errorf("content rule '%s' has an invalid 'files' glob '%s': %w", r.Pattern, r.Files, err)
+		}
+		switch r.Severity {
+		case "", SeverityInfo, SeverityWarning, SeverityError:
+		default:
+			return fmt.Errorf("content rule '%s' has an invalid 'severity': '%s' (must be 'info', 'warning' or 'error')", r.Pattern, r.Severity)
+		}
+	}
+	return nil
+}
+
+// compileContentRules compiles the patterns of the content rules
+func compileContentRules(rules []ContentRule) []compiledContentRule {
+	compiled := make([]compiledContentRule, 0, len(rules))
+	for _, r := range rules {
+		compiled = append(compiled, compiledContentRule{ContentRule: r, pattern: regexp.MustCompile(r.Pattern)})
+	}
+	return compiled
+}
+
+// appliesTo reports whether the rule applies to the file, by its path or file name
+func (r compiledContentRule) appliesTo(file string) bool {
+	if r.Files == "" {
+		return true
+	}
+	return matchesFilePattern(r.Files, file)
+}
+
+// matchesFilePattern reports whether a file matches a pattern by its path with forward
+// slashes or by its file name
+func matchesFilePattern(pattern, file string) bool {
+	slashed := toSlash(file)
+	if matched, _ := path.Match(pattern, slashed); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, path.Base(slashed))
+	return matched
+}
+
+// reportContentRule reports a line of file matching a content rule at the column of
+// the match, with the message of the rule or the pattern when it has none
+func (r *run) reportContentRule(rule compiledContentRule, file string, lineNumber int, line string, location []int, filePath string) {
+	message := rule.Message
+	if message == "" {
+		message = "matches the content rule '" + rule.Pattern + "'"
+	}
+	r.reportFinding(Finding{Rule: RuleContentRule, Severity: rule.Severity, Script: file, Line: lineNumber, Column: characterColumn(line, location[0]), Path: filePath},
+		"'{f}' line '{ln}': {m} ('{match}')", "f", file, "ln", lineNumber, "m", message, "match", line[location[0]:location[1]])
+}
+
+// checkContentRules reports a script line, its comment stripped, matching a content
+// rule applying to the script; each rule is reported once per line
+func (r *run) checkContentRules(scriptFile, line string, lineNumber int) {
+	for _, rule := range r.contentRules {
+		if !rule.appliesTo(scriptFile) {
+			continue
+		}
+		if location := rule.pattern.FindStringIndex(line); location != nil {
+			r.reportContentRule(rule, scriptFile, lineNumber, line, location, "")
+		}
+	}
+}
+
+// checkReferencedContentRules reports the lines of the text files referenced by the
+// script matching a content rule applying to the file. The files are the ones scanned
+// for their characters (see referencedTextFiles) and are checked once per run.
+func (r *run) checkReferencedContentRules(scriptFile string, lines Lines) {
+	if len(r.contentRules) == 0 {
+		return
+	}
+	r.log.Debug("checking the content rules of the text files referenced by '{s}'", "s", scriptFile)
+	files, _ := r.referencedTextFiles(lines)
+	for _, file := range files {
+		if r.contentRuleFiles[file] {
+			continue
+		}
+		r.contentRuleFiles[file] = true
+		var applying []compiledContentRule
+		for _, rule := range r.contentRules {
+			if rule.appliesTo(file) {
+				applying = append(applying, rule)
+			}
+		}
+		if len(applying) == 0 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(r.sourceCodeRoot, filepath.FromSlash(file)))
+		if err != nil {
+			// missing and unreadable files are findings of the existence checks
+			r.log.Debug("Not checking the content rules of '{f}': {e}", "f", file, "e", err.Error())
+			continue
+		}
+		content, _ := decodeScript(data)
+		for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+			for _, rule := range applying {
+				if location := rule.pattern.FindStringIndex(line); location != nil {
+					r.reportContentRule(rule, file, i+1, line, location, file)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// setupContentRulesTest writes the files of the repository and compiles the rules
+func setupContentRulesTest(t *testing.T, files map[string]string, rules []ContentRule) {
+	t.Helper()
+	writeStylesheetFixture(t, "linux", files)
+	originalRules, originalFiles, originalExtensions := testRun.contentRules, testRun.contentRuleFiles, testRun.textFileExtensions
+	t.Cleanup(func() {
+		testRun.contentRules, testRun.contentRuleFiles, testRun.textFileExtensions = originalRules, originalFiles, originalExtensions
+	})
+	testRun.contentRules, testRun.contentRuleFiles, testRun.textFileExtensions = compileContentRules(rules), make(map[string]bool), normalizeTextFileExtensions(nil)
+}
+
+func TestCheckContentRules(t *testing.T) {
+	// What: Script lines matching a rule applying to the script are reported at the match with the severity of the rule
+	setupContentRulesTest(t, nil, []ContentRule{
+		{Pattern: `-replace\b`, Files: "*_prod.sh", Message: "prod scripts must not overwrite the stylesheets", Severity: SeverityError},
+		{Pattern: `infodba`},
+	})
+
+	testRun.checkContentRules("deploy_prod.sh", `install_xml_stylesheet_datasets -input="a.txt" -replace`, 1)
+	testRun.checkContentRules("deploy_test.sh", `install_xml_stylesheet_datasets -input="a.txt" -replace`, 2)
+	testRun.checkContentRules("deploy_test.sh", `plmxml_import -u=infodba -xml_file="a.xml"`, 3)
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if f := findings[0]; f.Rule != RuleContentRule || f.Script != "deploy_prod.sh" || f.Line != 1 || f.Column != 48 || f.Severity != SeverityError ||
+		f.Message != "'deploy_prod.sh' line '1': prod scripts must not overwrite the stylesheets ('-replace')" {
+		t.Errorf("Unexpected finding of the prod script %+v", f)
+	}
+	if f := findings[1]; f.Line != 3 || f.Severity != SeverityWarning || !strings.Contains(f.Message, "matches the content rule 'infodba'") {
+		t.Errorf("Expected the rule without message and severity on line 3, got %+v", f)
+	}
+}
+
+func TestCheckReferencedContentRules(t *testing.T) {
+	// What: Referenced text files matching the glob of a rule are checked line by line, once per run
+	setupContentRulesTest(t, map[string]string{
+		"500-Lists/modules.csv": "id,url\r\na,https://repo/a.zip\r\nb,http://repo/b.zip\r\n",
+		"100-Config/a.xml":      "<a href=\"http://repo\"/>",
+	}, []ContentRule{{Pattern: `http://`, Files: "*.csv", Message: "use https"}})
+	lines := newLines()
+	lines.Flags = flagsOf(map[int]string{1: "500-Lists/modules.csv", 2: "100-Config/a.xml", 3: "100-Config/missing.csv"})
+
+	testRun.checkReferencedContentRules("deploy.sh", lines)
+	testRun.checkReferencedContentRules("deploy.bat", lines)
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", findings)
+	}
+	if f := findings[0]; f.Script != "500-Lists/modules.csv" || f.Path != "500-Lists/modules.csv" || f.Line != 3 || f.Column != 3 ||
+		f.Message != "'500-Lists/modules.csv' line '3': use https ('http://')" {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}
+
+func TestValidateContentRules(t *testing.T) {
+	// What: Rules need a valid pattern, files glob and severity
+	tests := []struct {
+		rule ContentRule
+		want string
+	}{
+		{ContentRule{Files: "*.sh"}, "index 0 is missing 'pattern'"},
+		{ContentRule{Pattern: "(-replace"}, "'(-replace' has an invalid pattern"},
+		{ContentRule{Pattern: "-replace", Files: "[prod"}, "invalid 'files' glob '[prod'"},
+		{ContentRule{Pattern: "-replace", Severity: "fatal"}, "invalid 'severity': 'fatal'"},
+	}
+	for _, tt := range tests {
+		if err := ValidateContentRules([]ContentRule{tt.rule}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q, got %v", tt.want, err)
+		}
+	}
+	if err := ValidateContentRules([]ContentRule{{Pattern: `-replace\b`, Files: "*_prod.sh", Severity: SeverityError}}); err != nil {
+		t.Errorf("Expected a valid rule, got %v", err)
+	}
+}
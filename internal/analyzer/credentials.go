@@ -0,0 +1,166 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// credential_flags:
+//   - flag: u
+//     variables: [INSTALL_USER]
+//   - flag: p
+//     variables: [TC_USER_PASSWD]
+//   - flag: g
+//     values: [dba]
+//
+// $TC_BIN/plmxml_import -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba ...
+
+// credentialFlag is a credential flag with the pattern extracting its value: group 1
+type credentialFlag struct {
+	CredentialFlag
+	pattern   *regexp.Regexp
+	variables []string // names of the approved variables
+}
+
+var (
+	// variableReferenceRegex matches a value that is a single variable: $NAME, ${NAME} or %NAME%
+	variableReferenceRegex = regexp.MustCompile(`^(?:\$(\w+)|\$\{(\w+)\}|%(\w+)%)$`)
+	variableNameRegex      = regexp.MustCompile(`^\w+$`)
+)
+
+// CredentialUse is the first value of a credential flag in a script: the variable, or
+// empty for a literal value, which is not kept
+type CredentialUse struct {
+	Line     int
+	Variable string
+}
+
+// ValidateCredentialFlags checks that each credential flag is named and approves a
+// variable or a value
+func ValidateCredentialFlags(flags []CredentialFlag) error {
+	for i, flag := range flags {
+		if flagRuleName(flag.Flag) == "" {
+			return fmt.Errorf("credential flag at index %d is missing 'flag'", i)
+		}
+		if len(flag.Variables) == 0 && len(flag.Values) == 0 {
+			return fmt.Errorf("credential flag '%s' needs approved 'variables' or 'values'", flag.Flag)
+		}
+		for _, variable := range flag.Variables {
+			if variableName(variable) == "" {
+				return fmt.Errorf("credential flag '%s' has an invalid variable '%s'", flag.Flag, variable)
+			}
+		}
+	}
+	return nil
+}
+
+// referencedVariable returns the name of the variable a value references as a whole,
+// empty for a literal value
+func referencedVariable(value string) string {
+	if m := variableReferenceRegex.FindStringSubmatch(value); m != nil {
+		return m[1] + m[2] + m[3]
+	}
+	return ""
+}
+
+// variableName returns the name of a variable written NAME, $NAME, ${NAME} or %NAME%
+func variableName(variable string) string {
+	variable = strings.TrimSpace(variable)
+	if name := referencedVariable(variable); name != "" {
+		return name
+	}
+	if variableNameRegex.MatchString(variable) {
+		return variable
+	}
+	return ""
+}
+
+// compileCredentialFlags compiles the patterns of the credential flags; flags are
+// written with or without the dash, like in the flag rules
+func (r *run) compileCredentialFlags(flags []CredentialFlag) []credentialFlag {
+	compiled := make([]credentialFlag, 0, len(flags))
+	for _, flag := range flags {
+		flag.Flag = flagRuleName(flag.Flag)
+		c := credentialFlag{
+			CredentialFlag: flag,
+			pattern:        regexp.MustCompile(r.flagPrefix(flag.Flag) + `=("[^"]*"|'[^']*'|[^\s"']*)`),
+		}
+		for _, variable := range flag.Variables {
+			c.variables = append(c.variables, variableName(variable))
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// checkCredentialFlags checks the credential flags of a line: the value must be an
+// approved variable or an approved literal value, and the same variable as the first
+// use of the flag in the script. Literal values are not repeated in the findings.
+func (r *run) checkCredentialFlags(scriptFile, line string, lineNumber int) {
+	for _, flag := range r.credentialFlags {
+		for _, location := range flag.pattern.FindAllStringSubmatchIndex(line, -1) {
+			value := strings.Trim(line[location[2]:location[3]], `"'`)
+			column := flagColumn(line, location)
+			variable := referencedVariable(value)
+
+			switch {
+			case variable == "" && containsValue(flag.Values, value):
+				continue
+			case variable == "":
+				r.reportFinding(Finding{Rule: RuleCredentialFlag, Script: scriptFile, Line: lineNumber, Column: column, Path: "-" + flag.Flag},
+					"'{s}' line '{ln}': '-{f}' has a literal value instead of an approved variable", "s", scriptFile, "ln", lineNumber, "f", flag.Flag)
+				continue
+			case !flag.variableApproved(variable, r.currentScriptTargetOS):
+				r.reportFinding(Finding{Rule: RuleCredentialFlag, Script: scriptFile, Line: lineNumber, Column: column, Path: "-" + flag.Flag},
+					"'{s}' line '{ln}': '-{f}' references '{v}', which is not an approved variable", "s", scriptFile, "ln", lineNumber, "f", flag.Flag, "v", variable)
+				continue
+			}
+
+			lines := r.analysisResult.File[scriptFile]
+			if lines.Credentials == nil {
+				lines.Credentials = make(map[string]CredentialUse)
+				r.analysisResult.File[scriptFile] = lines
+			}
+			first, used := lines.Credentials[flag.Flag]
+			if !used {
+				lines.Credentials[flag.Flag] = CredentialUse{Line: lineNumber, Variable: variable}
+				continue
+			}
+			if !sameVariable(first.Variable, variable, r.currentScriptTargetOS) {
+				r.reportFinding(Finding{Rule: RuleCredentialFlag, Script: scriptFile, Line: lineNumber, Column: column, Path: "-" + flag.Flag},
+					"'{s}' line '{ln}': '-{f}' references '{v}', line '{first}' references '{fv}'", "s", scriptFile, "ln", lineNumber,
+					"f", flag.Flag, "v", variable, "first", first.Line, "fv", first.Variable)
+			}
+		}
+	}
+}
+
+// variableApproved reports whether the variable is approved for the flag; Windows
+// variables are case-insensitive
+func (flag credentialFlag) variableApproved(variable, targetOS string) bool {
+	for _, approved := range flag.variables {
+		if sameVariable(approved, variable, targetOS) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameVariable compares variable names, case-insensitively in Windows scripts
+func sameVariable(a, b, targetOS string) bool {
+	if targetOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// containsValue reports whether the value is one of the approved literal values
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
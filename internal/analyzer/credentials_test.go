@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests for the credential flags
+
+// What: Credential flags need a name and an approved variable or value, variables must be names
+func TestValidateCredentialFlags(t *testing.T) {
+	tests := []struct {
+		flags []CredentialFlag
+		want  string
+	}{
+		{[]CredentialFlag{{Flag: "-u", Variables: []string{"$INSTALL_USER"}}, {Flag: "g", Values: []string{"dba"}}}, ""},
+		{[]CredentialFlag{{Variables: []string{"INSTALL_USER"}}}, "credential flag at index 0 is missing 'flag'"},
+		{[]CredentialFlag{{Flag: "p"}}, "credential flag 'p' needs approved 'variables' or 'values'"},
+		{[]CredentialFlag{{Flag: "p", Variables: []string{"TC USER"}}}, "credential flag 'p' has an invalid variable 'TC USER'"},
+	}
+	for _, tt := range tests {
+		err := ValidateCredentialFlags(tt.flags)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%+v: expected %q, got %v", tt.flags, tt.want, err)
+		}
+	}
+}
+
+// What: Approved variables and values pass; literal, unapproved and mixed values are TCX009 at the flag column
+func TestCheckCredentialFlags(t *testing.T) {
+	testRun.credentialFlags = testRun.compileCredentialFlags([]CredentialFlag{
+		{Flag: "u", Variables: []string{"INSTALL_USER", "${ADMIN_USER}"}},
+		{Flag: "-p", Variables: []string{"$TC_USER_PASSWD"}},
+		{Flag: "g", Values: []string{"dba"}},
+	})
+	t.Cleanup(func() { testRun.credentialFlags = nil })
+	setupHeredocTest(t, "plmxml_import -u=$INSTALL_USER -p=\"${TC_USER_PASSWD}\" -g=dba -xml_file=\"a.xml\"\n"+
+		"plmxml_import -u=infodba -p=$TC_USER_PASSWD -g=dba -xml_file=\"a.xml\"\n"+
+		"plmxml_import -u=$INSTALL_USER -p=$OTHER_PASSWD -g=dba -xml_file=\"a.xml\"\n"+
+		"plmxml_import -u=$ADMIN_USER -p=$TC_USER_PASSWD -g=Engineering -xml_file=\"a.xml\"\n")
+
+	want := []struct {
+		line   int
+		column int
+		path   string
+		text   string
+	}{
+		{2, 15, "-u", "literal value"},
+		{3, 32, "-p", "'OTHER_PASSWD', which is not an approved variable"},
+		{4, 15, "-u", "'ADMIN_USER', line '1' references 'INSTALL_USER'"},
+		{4, 49, "-g", "literal value"},
+	}
+	var findings []Finding
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RuleCredentialFlag {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Line != w.line || f.Column != w.column || f.Path != w.path || !strings.Contains(f.Message, w.text) {
+			t.Errorf("Expected %+v, got %+v", w, f)
+		}
+	}
+	if strings.Contains(findings[0].Message, "infodba") {
+		t.Errorf("Expected the literal value left out, got %q", findings[0].Message)
+	}
+}
+
+// What: Variables of Windows scripts are compared case-insensitively
+func TestCheckCredentialFlags_Windows(t *testing.T) {
+	testRun.credentialFlags = testRun.compileCredentialFlags([]CredentialFlag{{Flag: "u", Variables: []string{"INSTALL_USER"}}})
+	t.Cleanup(func() { testRun.credentialFlags = nil })
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+	testRun.currentScriptTargetOS = "windows"
+	t.Cleanup(func() { testRun.currentScriptTargetOS = "" })
+
+	testRun.checkCredentialFlags("deploy.bat", `plmxml_import -u=%install_user% -xml_file="a.xml"`, 1)
+	testRun.checkCredentialFlags("deploy.bat", `plmxml_import -u=%INSTALL_USER% -xml_file="a.xml"`, 2)
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", testRun.analysisResult.Findings)
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Directory references are path flag values naming a directory instead of a file, e.g.
+// -filepath="200-Stylesheets/". Written with a trailing separator they must be a
+// directory; either way the files below them count as referenced by the line.
+
+// isDirectoryReference reports whether a path referenced by the script being processed
+// is written as a directory, ending with a separator of its target OS
+func (r *run) isDirectoryReference(p string) bool {
+	separators := "/"
+	if r.currentScriptTargetOS == "windows" {
+		separators = `\/`
+	}
+	return p != "" && strings.ContainsRune(separators, rune(p[len(p)-1]))
+}
+
+// referencesDirectory reports whether a path referenced by the script being processed
+// is a directory reference: written as one or an existing directory. The checks reading
+// the referenced files, e.g. of XML imports or archives, leave them out.
+func (r *run) referencesDirectory(p string) bool {
+	return r.isDirectoryReference(p) || r.directoryExists(r.localPath(p))
+}
+
+// directoryExists reports whether a path relative to the root, rendered for the host OS,
+// is a directory, on the remote when one is configured
+func (r *run) directoryExists(local string) bool {
+	if r.remoteTree != nil {
+		return r.remoteTree[path.Clean(toSlash(local))]
+	}
+	info, err := os.Stat(filepath.Join(r.sourceCodeRoot, local))
+	return err == nil && info.IsDir()
+}
+
+// directoryReference is a directory referenced by the script, with the files below it
+// credited as referenced during the directory content check
+type directoryReference struct {
+	lines []PathFlag // references of the directory, in line order
+	files int        // files below the directory found by the traversal
+}
+
+// referencedDirectories returns the localized references of the script that are
+// existing directories, keyed by their path
+func (r *run) referencedDirectories(validLines []PathFlag) map[string]*directoryReference {
+	directories := make(map[string]*directoryReference)
+	for _, reference := range validLines {
+		value := reference.Path
+		if value == "" || isAbsoluteReference(value) || !r.directoryExists(value) {
+			continue
+		}
+		if directories[value] == nil {
+			directories[value] = &directoryReference{}
+		}
+		directories[value].lines = append(directories[value].lines, reference)
+	}
+	for _, dir := range directories {
+		sort.SliceStable(dir.lines, func(i, j int) bool { return dir.lines[i].Line < dir.lines[j].Line })
+	}
+	return directories
+}
+
+// creditingDirectory returns the referenced directory a repository file is below, the
+// deepest one when they are nested; "" when there is none
+func creditingDirectory(item string, directories map[string]*directoryReference) string {
+	if len(directories) == 0 {
+		return ""
+	}
+	for dir := filepath.Dir(item); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if _, ok := directories[dir]; ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+// checkEmptyDirectories reports the referenced directories below which the traversal
+// found no file, when 'require_non_empty_directories' is set
+func (r *run) checkEmptyDirectories(script string, directories map[string]*directoryReference) {
+	if !r.requireNonEmptyDirectories {
+		return
+	}
+	dirs := make([]string, 0, len(directories))
+	for dir := range directories {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if directories[dir].files > 0 {
+			continue
+		}
+		reference := directories[dir].lines[0]
+		ln := reference.Line
+		r.reportFinding(Finding{Rule: RuleEmptyDirectory, Script: script, Line: ln, Column: reference.Column, Path: dir,
+			Suggestion: "add the files to deploy to the directory, or remove the reference"},
+			"'{s}' line '{ln}' is invalid: directory '{d}' has no files to deploy (ignored files are not counted)", "s", script, "ln", ln, "d", dir)
+	}
+	r.log.Debug("'{n}' referenced directories checked for files in '{s}'", "n", len(dirs), "s", script)
+}
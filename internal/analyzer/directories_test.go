@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests for the directory references of the scripts
+
+func setupDirectoriesTest(t *testing.T, files []string) string {
+	t.Helper()
+	tmpDir := setupTestDir(t, files)
+	t.Cleanup(func() { cleanup(t, tmpDir) })
+
+	originalRoot, originalScript, originalOS, originalResult, originalRequire := testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult, testRun.requireNonEmptyDirectories
+	t.Cleanup(func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult, testRun.requireNonEmptyDirectories = originalRoot, originalScript, originalOS, originalResult, originalRequire
+	})
+	testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS = tmpDir, "deploy.sh", "linux"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	return tmpDir
+}
+
+func TestIsDirectoryReference(t *testing.T) {
+	// What: A path ending with a separator of the target OS is a directory reference
+	originalOS := testRun.currentScriptTargetOS
+	defer func() { testRun.currentScriptTargetOS = originalOS }()
+
+	tests := []struct {
+		targetOS string
+		path     string
+		want     bool
+	}{
+		{"linux", "200-Stylesheets/", true},
+		{"linux", "200-Stylesheets", false},
+		{"linux", `200-Stylesheets\`, false},
+		{"windows", `200-Stylesheets\`, true},
+		{"windows", "200-Stylesheets/", true},
+		{"windows", "a.xml", false},
+		{"linux", "", false},
+	}
+	for _, tt := range tests {
+		testRun.currentScriptTargetOS = tt.targetOS
+		if got := testRun.isDirectoryReference(tt.path); got != tt.want {
+			t.Errorf("isDirectoryReference(%q) on %s = %v, want %v", tt.path, tt.targetOS, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFilePathsInScript_DirectoryReferences(t *testing.T) {
+	// What: A path written as a directory must be a directory, a directory written without separator exists
+	setupDirectoriesTest(t, []string{"200-Stylesheets/a.xml"})
+
+	testRun.checkFilePathsInScript("deploy.sh", pathFlags(map[int]string{1: "200-Stylesheets/", 2: "200-Stylesheets", 3: "200-Stylesheets/a.xml/", 4: "300-Workflows/"}))
+
+	missing := testRun.analysisResult.File["deploy.sh"].Missing
+	if expected := []string{"200-Stylesheets/a.xml/", "300-Workflows/"}; !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Expected missing %v, got %v", expected, missing)
+	}
+	if findings := testRun.analysisResult.Findings; len(findings) != 2 || findings[0].Line != 3 || findings[0].Message != "'deploy.sh' line '3' is invalid: '200-Stylesheets/a.xml/' is not a directory" {
+		t.Errorf("Expected the file referenced as directory on line 3, got %+v", findings)
+	}
+}
+
+func TestCompareFilesWithScripts_DirectoryReference(t *testing.T) {
+	// What: The files below a referenced directory are referenced, the ignored ones are not walked
+	tmpDir := setupDirectoriesTest(t, []string{"200-Stylesheets/a.xml", "200-Stylesheets/sub/b.xml", "200-Stylesheets/c.log", "300-Workflows/w.xml"})
+
+	validLines := testRun.localizeFlags(pathFlags(map[int]string{1: "200-Stylesheets/"}))
+	assertNoError(t, testRun.compareFilesWithScripts("deploy.sh", validLines, tmpDir, []string{"*.log"}))
+
+	if got, expected := testRun.analysisResult.File["deploy.sh"].Unreferenced, []string{filepath.Join("300-Workflows", "w.xml")}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected unreferenced %v, got %v", expected, got)
+	}
+	if c := testRun.analysisResult.File["deploy.sh"].Coverage["200-Stylesheets"]; c.Present != 2 || c.Referenced != 2 {
+		t.Errorf("Expected 200-Stylesheets 2/2, got %+v", c)
+	}
+}
+
+func TestCompareFilesWithScripts_EmptyDirectoryReference(t *testing.T) {
+	// What: With require_non_empty_directories, a referenced directory with only ignored files is reported
+	tmpDir := setupDirectoriesTest(t, []string{"200-Stylesheets/c.log", "300-Workflows/w.xml"})
+	if err := os.MkdirAll(filepath.Join(tmpDir, "400-Empty"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	validLines := testRun.localizeFlags(pathFlags(map[int]string{1: "300-Workflows/", 4: "200-Stylesheets/", 7: "400-Empty"}))
+	assertNoError(t, testRun.compareFilesWithScripts("deploy.sh", validLines, tmpDir, []string{"*.log"}))
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Fatalf("Expected no findings unless required, got %+v", testRun.analysisResult.Findings)
+	}
+
+	testRun.requireNonEmptyDirectories = true
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	assertNoError(t, testRun.compareFilesWithScripts("deploy.sh", validLines, tmpDir, []string{"*.log"}))
+
+	var lines []int
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule != RuleEmptyDirectory {
+			t.Errorf("Expected only %s findings, got %+v", RuleEmptyDirectory, f)
+		}
+		lines = append(lines, f.Line)
+	}
+	if expected := []int{4, 7}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected empty directories on lines %v, got %v", expected, lines)
+	}
+}
+
+func TestDirectoryReferences_SkippedByFileChecks(t *testing.T) {
+	// What: Directories passed to XML imports, preferences and archive checks are not read as files
+	setupDirectoriesTest(t, []string{"200-Stylesheets/a.xml", "300-Packages/pkg.zip/readme.txt"})
+	originalArchives := testRun.archiveSettings
+	t.Cleanup(func() { testRun.archiveSettings = originalArchives })
+	testRun.archiveSettings = archiveRules{Validate: true}
+
+	testRun.checkXMLImportReferences("deploy.sh", map[int]XMLImport{1: {Utility: "plmxml_import", Path: "200-Stylesheets/"}, 2: {Utility: "plmxml_import", Path: "200-Stylesheets"}})
+	lines := newLines()
+	lines.PreferenceImport[3] = "200-Stylesheets/"
+	testRun.collectDeployedItems("deploy.sh", lines)
+	testRun.checkArchives("deploy.sh", pathFlags(map[int]string{4: "300-Packages/pkg.zip"}))
+
+	if findings := testRun.analysisResult.Findings; len(findings) != 0 {
+		t.Errorf("Expected no findings for the directories, got %+v", findings)
+	}
+}
+
+func TestCreditingDirectory(t *testing.T) {
+	// What: The deepest referenced directory above a file credits it
+	directories := map[string]*directoryReference{
+		"a":                     {},
+		filepath.Join("a", "b"): {},
+	}
+	tests := map[string]string{
+		filepath.Join("a", "b", "c.xml"):      filepath.Join("a", "b"),
+		filepath.Join("a", "x", "c.xml"):      "a",
+		filepath.Join("ab", "c.xml"):          "",
+		"c.xml":                               "",
+		filepath.Join("a", "b", "d", "e.xml"): filepath.Join("a", "b"),
+	}
+	for item, want := range tests {
+		if got := creditingDirectory(item, directories); got != want {
+			t.Errorf("creditingDirectory(%q) = %q, want %q", item, got, want)
+		}
+	}
+}
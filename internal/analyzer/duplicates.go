@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hashFile returns the hex encoded SHA-256 of a file's content
+func hashFile(fullPath string) (string, error) {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error reading %q: %w", fullPath, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// findDuplicateContent groups the given paths (relative to root) by identical content.
+// Only files sharing a size are hashed. Returns groups with at least two distinct
+// paths; each group and the list of groups are sorted.
+func (r *run) findDuplicateContent(root string, paths []string) [][]string {
+	bySize := make(map[int64][]string)
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		info, err := os.Stat(filepath.Join(root, p))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], p)
+	}
+
+	byHash := make(map[string][]string)
+	for _, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		for _, p := range candidates {
+			sum, err := hashFile(filepath.Join(root, p))
+			if err != nil {
+				r.log.Debug("Error hashing '{p}': {e}", "p", p, "e", err.Error())
+				continue
+			}
+			byHash[sum] = append(byHash[sum], p)
+		}
+	}
+
+	var groups [][]string
+	for _, group := range byHash {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// checkDuplicateContent reports referenced files with byte-identical content under
+// different paths, typically copies that should have been moves. Informational only.
+func (r *run) checkDuplicateContent(scriptFile string, lines []PathFlag) {
+	r.log.Debug("checking for duplicate content in files referenced by '{s}'", "s", scriptFile)
+
+	paths := make([]string, 0, len(lines))
+	for _, f := range lines {
+		paths = append(paths, r.localPath(f.Path))
+	}
+
+	groups := r.findDuplicateContent(r.sourceCodeRoot, paths)
+	for _, group := range groups {
+		r.reportFinding(Finding{Rule: RuleDuplicateContent, Script: scriptFile, Path: group[0]},
+			"'{s}' references files with identical content: {paths}", "s", scriptFile, "paths", strings.Join(group, ", "))
+	}
+	if len(groups) == 0 {
+		r.log.Info("No duplicate content found in referenced files")
+	}
+}
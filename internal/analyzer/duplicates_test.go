@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests for duplicate content detection
+
+func TestFindDuplicateContent(t *testing.T) {
+	// What: Identical files are grouped, same-size different files are not
+	tmpDir := t.TempDir()
+	contents := map[string]string{
+		"200-Stylesheets/a.xml":     "<rendering>one</rendering>",
+		"200-Stylesheets/old/a.xml": "<rendering>one</rendering>",
+		"200-Stylesheets/b.xml":     "<rendering>two</rendering>",
+		"100-Config/unique.xml":     "<config/>",
+	}
+	for name, content := range contents {
+		full := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	paths := []string{
+		filepath.Join("200-Stylesheets", "a.xml"),
+		filepath.Join("200-Stylesheets", "old", "a.xml"),
+		filepath.Join("200-Stylesheets", "b.xml"),
+		filepath.Join("100-Config", "unique.xml"),
+		filepath.Join("200-Stylesheets", "a.xml"), // referenced twice, not a duplicate
+		"missing.xml",
+	}
+	groups := testRun.findDuplicateContent(tmpDir, paths)
+
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %d: %v", len(groups), groups)
+	}
+	if len(groups[0]) != 2 {
+		t.Errorf("Expected 2 paths in group, got %v", groups[0])
+	}
+}
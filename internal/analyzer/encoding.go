@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Script encodings detected by decodeScript
+const (
+	EncodingUTF8        = "UTF-8"
+	EncodingUTF16LE     = "UTF-16LE"
+	EncodingUTF16BE     = "UTF-16BE"
+	EncodingWindows1252 = "Windows-1252"
+)
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to their characters;
+// the other bytes above 0x7F match Latin-1 and map to the same code point.
+// Bytes undefined in Windows-1252 map to the Unicode replacement character.
+var windows1252 = [32]rune{
+	'€', '\uFFFD', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\uFFFD', 'Ž', '\uFFFD',
+	'\uFFFD', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\uFFFD', 'ž', 'Ÿ',
+}
+
+// decodeScript detects the encoding of a script and returns its content as UTF-8.
+// UTF-16 is detected by its byte order mark, or without one by the zero bytes of
+// ASCII text; content that is not valid UTF-8 is read as Windows-1252.
+// A UTF-8 byte order mark is removed.
+func decodeScript(data []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false), EncodingUTF16LE
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true), EncodingUTF16BE
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		data = data[3:]
+	}
+
+	if bigEndian, ok := looksLikeUTF16(data); ok {
+		if bigEndian {
+			return decodeUTF16(data, true), EncodingUTF16BE
+		}
+		return decodeUTF16(data, false), EncodingUTF16LE
+	}
+	if utf8.Valid(data) {
+		return string(data), EncodingUTF8
+	}
+	return decodeWindows1252(data), EncodingWindows1252
+}
+
+// looksLikeUTF16 reports whether data without byte order mark is UTF-16 encoded
+// text, and its byte order, from the share of zero bytes at even and odd offsets
+func looksLikeUTF16(data []byte) (bigEndian bool, ok bool) {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return false, false
+	}
+	even, odd := 0, 0
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 {
+			even++
+		}
+		if data[i+1] == 0 {
+			odd++
+		}
+	}
+	units := len(data) / 2
+	switch {
+	case odd*10 >= units*9 && even == 0:
+		return false, true
+	case even*10 >= units*9 && odd == 0:
+		return true, true
+	}
+	return false, false
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return strings.TrimPrefix(string(utf16.Decode(units)), "\uFEFF")
+}
+
+func decodeWindows1252(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		switch {
+		case c < 0x80:
+			b.WriteByte(c)
+		case c < 0xA0:
+			b.WriteRune(windows1252[c-0x80])
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// reportScriptEncoding reports a script transcoded from another encoding than
+// UTF-8 with the severity of the encoding policy
+func (r *run) reportScriptEncoding(script, encoding string) {
+	if encoding == EncodingUTF8 || r.encodingPolicy == "ignore" {
+		return
+	}
+	severity := r.encodingPolicy
+	if severity == "" {
+		severity = r.ruleSeverity(RuleScriptEncoding)
+	}
+	r.reportFinding(Finding{Rule: RuleScriptEncoding, Severity: severity, Script: script, Path: script, Suggestion: "save the script as UTF-8"},
+		"Script '{s}' is encoded in {e}, it was transcoded to UTF-8 for the analysis", "s", script, "e", encoding)
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, bigEndian, bom bool) []byte {
+	var data []byte
+	if bom {
+		if bigEndian {
+			data = append(data, 0xFE, 0xFF)
+		} else {
+			data = append(data, 0xFF, 0xFE)
+		}
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			data = append(data, byte(u>>8), byte(u))
+		} else {
+			data = append(data, byte(u), byte(u>>8))
+		}
+	}
+	return data
+}
+
+// What: UTF-16 with and without byte order mark, Windows-1252 and UTF-8 are detected and decoded
+func TestDecodeScript(t *testing.T) {
+	script := "set DIR=Config\r\nplmxml_import -xml_file=\"a.xml\"\r\n"
+	tests := []struct {
+		name     string
+		data     []byte
+		content  string
+		encoding string
+	}{
+		{"utf-8", []byte(script), script, EncodingUTF8},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, script...), script, EncodingUTF8},
+		{"utf-16le bom", encodeUTF16(script, false, true), script, EncodingUTF16LE},
+		{"utf-16be bom", encodeUTF16(script, true, true), script, EncodingUTF16BE},
+		{"utf-16le", encodeUTF16(script, false, false), script, EncodingUTF16LE},
+		{"utf-16be", encodeUTF16(script, true, false), script, EncodingUTF16BE},
+		{"windows-1252", []byte("rem \x93M\xfcller\x94 \x80\r\n"), "rem “Müller” €\r\n", EncodingWindows1252},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, encoding := decodeScript(tt.data)
+			if content != tt.content || encoding != tt.encoding {
+				t.Errorf("decodeScript() = %q, %s; want %q, %s", content, encoding, tt.content, tt.encoding)
+			}
+		})
+	}
+}
+
+// What: The encoding finding follows the policy; UTF-8 scripts are not reported
+func TestReportScriptEncoding(t *testing.T) {
+	original := testRun.encodingPolicy
+	defer func() { testRun.encodingPolicy = original }()
+
+	tests := []struct {
+		policy   string
+		encoding string
+		severity string // "" for no finding
+	}{
+		{"", EncodingUTF16LE, SeverityWarning},
+		{"error", EncodingWindows1252, SeverityError},
+		{"info", EncodingUTF16BE, SeverityInfo},
+		{"ignore", EncodingUTF16LE, ""},
+		{"error", EncodingUTF8, ""},
+	}
+	for _, tt := range tests {
+		testRun.analysisResult = Result{File: map[string]Lines{}}
+		testRun.encodingPolicy = tt.policy
+
+		testRun.reportScriptEncoding("deploy.bat", tt.encoding)
+
+		if tt.severity == "" {
+			if len(testRun.analysisResult.Findings) != 0 {
+				t.Errorf("policy %q, %s: expected no finding, got %v", tt.policy, tt.encoding, testRun.analysisResult.Findings)
+			}
+			continue
+		}
+		if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleScriptEncoding || testRun.analysisResult.Findings[0].Severity != tt.severity {
+			t.Errorf("policy %q, %s: expected one %s finding, got %v", tt.policy, tt.encoding, tt.severity, testRun.analysisResult.Findings)
+		}
+	}
+}
+
+// What: Paths of a UTF-16 encoded batch script are parsed from the transcoded content
+func TestCheckFileSyntax_UTF16Script(t *testing.T) {
+	setupSyntaxTest()
+	tmpDir := t.TempDir()
+	script := "plmxml_import -R=\"config\\a.xml\"\r\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "deploy.bat"), encodeUTF16(script, false, true), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+
+	testRun.checkFileSyntax("deploy.bat", tmpDir, "windows")
+
+	if got := validPaths(testRun.analysisResult.File["deploy.bat"])[1]; got != `config\a.xml` {
+		t.Errorf("Expected path 'config\\a.xml', got %q", got)
+	}
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleScriptEncoding {
+		t.Errorf("Expected one encoding finding, got %v", testRun.analysisResult.Findings)
+	}
+}
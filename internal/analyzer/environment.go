@@ -0,0 +1,311 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// $TC_BIN/preferences_manager -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba \
+// -mode=import -scope=SITE -file="090-Preferences/site_preferences.xml" -action=OVERRIDE
+//
+// <preference name="TC_show_checkedout_icon" type="Logical" array="false" disabled="false">
+//
+// The environment snapshot is an export of the items installed in a Teamcenter
+// environment, as CSV lines 'type,name':
+//
+//	type,name
+//	stylesheet,Nw4Part.Summary
+//	preference,TC_show_checkedout_icon
+//	template,nw4template
+//
+// or as JSON: {"stylesheets": [...], "preferences": [...], "templates": [...]}
+
+// Kinds of items deployed by the scripts and listed in the environment snapshot
+const (
+	ItemStylesheet = "stylesheet"
+	ItemPreference = "preference"
+	ItemTemplate   = "template"
+)
+
+// environmentItemKinds lists the item kinds in report order
+var environmentItemKinds = []string{ItemStylesheet, ItemPreference, ItemTemplate}
+
+// itemLocation is the file and 1-based line an item is listed or deployed in
+type itemLocation struct {
+	File string
+	Line int
+}
+
+// environmentSnapshot holds the items installed in the environment: kind -> name -> location in the snapshot
+type environmentSnapshot map[string]map[string]itemLocation
+
+// add records an item of the snapshot, the first listing of a name is kept
+func (s environmentSnapshot) add(kind, name string, at itemLocation) {
+	if s[kind] == nil {
+		s[kind] = make(map[string]itemLocation)
+	}
+	if _, ok := s[kind][name]; !ok {
+		s[kind][name] = at
+	}
+}
+
+// environmentItemKind returns the item kind of a snapshot type, accepting plurals and any case
+func environmentItemKind(value string) (string, bool) {
+	kind := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "s")
+	for _, k := range environmentItemKinds {
+		if k == kind {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// parseSnapshotCSV reads 'type,name' lines; empty lines, '#' comments and a
+// 'type,name' header are skipped
+func parseSnapshotCSV(r io.Reader, file string) (environmentSnapshot, error) {
+	snapshot := make(environmentSnapshot)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		columns := strings.SplitN(line, ",", 2)
+		if len(columns) < 2 || strings.TrimSpace(columns[1]) == "" {
+			return nil, fmt.Errorf("line %d: '%s' is not of the format 'type,name'", lineNumber, line)
+		}
+		if lineNumber == 1 && strings.EqualFold(strings.TrimSpace(columns[0]), "type") {
+			continue
+		}
+		kind, ok := environmentItemKind(columns[0])
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid type '%s' (must be one of %v)", lineNumber, strings.TrimSpace(columns[0]), environmentItemKinds)
+		}
+		snapshot.add(kind, strings.Trim(strings.TrimSpace(columns[1]), `"`), itemLocation{File: file, Line: lineNumber})
+	}
+	return snapshot, scanner.Err()
+}
+
+// parseSnapshotJSON reads an object listing the item names per kind
+func parseSnapshotJSON(r io.Reader, file string) (environmentSnapshot, error) {
+	var lists map[string][]string
+	if err := json.NewDecoder(r).Decode(&lists); err != nil {
+		return nil, err
+	}
+	snapshot := make(environmentSnapshot)
+	for key, names := range lists {
+		kind, ok := environmentItemKind(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid item list '%s' (must be one of %v)", key, environmentItemKinds)
+		}
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" {
+				snapshot.add(kind, name, itemLocation{File: file})
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// loadEnvironmentSnapshot reads the snapshot file, JSON for a .json file and CSV
+// otherwise. A file that cannot be read is an I/O error, one of an invalid format a
+// configuration error.
+func (r *run) loadEnvironmentSnapshot(file string) (environmentSnapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, withKind(KindIO, fmt.Errorf("error opening environment snapshot: %w", err))
+	}
+	defer f.Close()
+
+	var snapshot environmentSnapshot
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		snapshot, err = parseSnapshotJSON(f, file)
+	} else {
+		snapshot, err = parseSnapshotCSV(f, file)
+	}
+	if err != nil {
+		return nil, withKind(KindConfig, fmt.Errorf("invalid environment snapshot '%s': %w", file, err))
+	}
+	for _, kind := range environmentItemKinds {
+		r.log.Info("environment snapshot '{f}' lists '{n}' {k}(s)", "f", file, "n", len(snapshot[kind]), "k", kind)
+	}
+	return snapshot, nil
+}
+
+// recordDeployedItem records an item deployed by the scripts, the first deployment of a name is kept
+func (r *run) recordDeployedItem(kind, name string, at itemLocation) {
+	if r.deployedItems == nil {
+		r.deployedItems = make(map[string]map[string]itemLocation)
+	}
+	if r.deployedItems[kind] == nil {
+		r.deployedItems[kind] = make(map[string]itemLocation)
+	}
+	if _, ok := r.deployedItems[kind][name]; !ok {
+		r.deployedItems[kind][name] = at
+	}
+}
+
+// recordDeployedDatasets records the stylesheet datasets of an import definition,
+// located at their line in the input file
+func (r *run) recordDeployedDatasets(importDefinition StyleSheetImport) {
+	si := make([]int, 0, len(importDefinition.Datasets))
+	for i := range importDefinition.Datasets {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+	for _, i := range si {
+		r.recordDeployedItem(ItemStylesheet, importDefinition.Datasets[i].Name, itemLocation{File: importDefinition.InputFile, Line: i})
+	}
+}
+
+// checkDatasetCollisions reports the datasets of a stylesheet import line that
+// already exist in the environment while the line does not pass -replace: the
+// utility refuses to import them at deploy time
+func (r *run) checkDatasetCollisions(scriptFile string, lineNumber int, importDefinition StyleSheetImport) {
+	if r.environmentItems == nil || importDefinition.Replace {
+		return
+	}
+	datasets := importDefinition.Datasets
+	si := make([]int, 0, len(datasets))
+	for i := range datasets {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+	for _, i := range si {
+		if _, exists := r.environmentItems[ItemStylesheet][datasets[i].Name]; !exists {
+			continue
+		}
+		r.reportFinding(Finding{Rule: RuleDatasetExists, Script: scriptFile, Line: lineNumber, Path: importDefinition.InputFile,
+			Suggestion: "pass -replace to install_xml_stylesheet_datasets"},
+			"'{s}' line '{ln}': dataset '{d}' ('{f}' line '{fl}') already exists in the environment and is imported without '-replace'",
+			"s", scriptFile, "ln", lineNumber, "d", datasets[i].Name, "f", importDefinition.InputFile, "fl", i)
+	}
+}
+
+// isPreferenceImportLine reports whether the line invokes the preferences manager in import mode
+func isPreferenceImportLine(line string) bool {
+	return extractExecutableName(line) == "preferences_manager" && strings.Contains(strings.ToLower(line), "-mode=import")
+}
+
+// extractPreferenceNames returns the names of the preferences defined in a
+// preferences_manager export, in document order
+func extractPreferenceNames(r io.Reader) ([]string, error) {
+	var names []string
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return names, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "preference" {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "name" && strings.TrimSpace(attr.Value) != "" {
+				names = append(names, strings.TrimSpace(attr.Value))
+			}
+		}
+	}
+}
+
+// collectDeployedItems records the templates installed and the preferences imported
+// by the script; stylesheet datasets are recorded while reading the import definitions.
+// Preference files that do not exist are already reported by the file system references check.
+func (r *run) collectDeployedItems(scriptFile string, lines Lines) {
+	// sort by line number, the first deployment of an item is kept
+	si := make([]int, 0, len(lines.TemplateInstall))
+	for i := range lines.TemplateInstall {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+	for _, lineNumber := range si {
+		for _, name := range lines.TemplateInstall[lineNumber].Templates {
+			r.recordDeployedItem(ItemTemplate, name, itemLocation{File: scriptFile, Line: lineNumber})
+		}
+	}
+	si = si[:0]
+	for i := range lines.PreferenceImport {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+	for _, lineNumber := range si {
+		preferenceFile := lines.PreferenceImport[lineNumber]
+		if r.referencesDirectory(preferenceFile) {
+			r.log.Debug("'{s}' line '{ln}': skipping preferences of '{f}', it is a directory", "s", scriptFile, "ln", lineNumber, "f", preferenceFile)
+			continue
+		}
+		file, err := os.Open(r.referenceFilePath(r.localPath(preferenceFile)))
+		if err != nil {
+			r.log.Debug("'{s}' line '{ln}': skipping preferences of '{f}': {e}", "s", scriptFile, "ln", lineNumber, "f", preferenceFile, "e", err.Error())
+			continue
+		}
+		names, err := extractPreferenceNames(file)
+		file.Close()
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleXMLUnreadable, Script: scriptFile, Line: lineNumber, Path: preferenceFile},
+				"'{s}' line '{ln}': error parsing preferences file '{f}': {e}", "s", scriptFile, "ln", lineNumber, "f", preferenceFile, "e", err.Error())
+			continue
+		}
+		for _, name := range names {
+			r.recordDeployedItem(ItemPreference, name, itemLocation{File: scriptFile, Line: lineNumber})
+		}
+	}
+}
+
+// sortedItemNames returns the names of the items of a kind, sorted
+func sortedItemNames(items map[string]itemLocation) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkEnvironmentSnapshot cross-checks the environment snapshot with the items the
+// scripts deploy: items installed in the environment but not managed by any script,
+// and items deployed by the scripts but not installed in the environment.
+func (r *run) checkEnvironmentSnapshot() {
+	if r.environmentItems == nil {
+		return
+	}
+	r.log.Separate("ENVIRONMENT SNAPSHOT CHECK")
+
+	unmanaged, notInstalled := 0, 0
+	for _, kind := range environmentItemKinds {
+		for _, name := range sortedItemNames(r.environmentItems[kind]) {
+			if _, ok := r.deployedItems[kind][name]; ok {
+				continue
+			}
+			at := r.environmentItems[kind][name]
+			r.reportFinding(Finding{Rule: RuleEnvironmentUnmanaged, Script: at.File, Line: at.Line},
+				"{k} '{n}' is installed in the environment but not deployed by any script", "k", kind, "n", name)
+			unmanaged++
+		}
+		for _, name := range sortedItemNames(r.deployedItems[kind]) {
+			if _, ok := r.environmentItems[kind][name]; ok {
+				continue
+			}
+			at := r.deployedItems[kind][name]
+			r.reportFinding(Finding{Rule: RuleNotInEnvironment, Script: at.File, Line: at.Line},
+				"{k} '{n}' is deployed but not installed in the environment", "k", kind, "n", name)
+			notInstalled++
+		}
+	}
+	if unmanaged == 0 && notInstalled == 0 {
+		r.log.Info("All items of the environment snapshot are deployed by the scripts and vice versa")
+	}
+}
@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests for the environment snapshot cross-check
+
+// What: CSV snapshots skip the header, comments and empty lines and keep the line of each item
+func TestParseSnapshotCSV(t *testing.T) {
+	content := "type,name\n# exported from prod\n\nstylesheet,Nw4Part.Summary\nPreferences, TC_show_checkedout_icon\ntemplate,\"nw4template\"\n"
+	snapshot, err := parseSnapshotCSV(strings.NewReader(content), "prod.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := environmentSnapshot{
+		ItemStylesheet: {"Nw4Part.Summary": {File: "prod.csv", Line: 4}},
+		ItemPreference: {"TC_show_checkedout_icon": {File: "prod.csv", Line: 5}},
+		ItemTemplate:   {"nw4template": {File: "prod.csv", Line: 6}},
+	}
+	if !reflect.DeepEqual(snapshot, want) {
+		t.Errorf("Expected %v, got %v", want, snapshot)
+	}
+}
+
+// What: CSV lines without a name or with an unknown type are errors naming the line
+func TestParseSnapshotCSV_Invalid(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"stylesheet\n", "line 1: 'stylesheet' is not of the format 'type,name'"},
+		{"stylesheet,a\nworkflow,b\n", "line 2: invalid type 'workflow'"},
+	}
+	for _, tt := range tests {
+		_, err := parseSnapshotCSV(strings.NewReader(tt.content), "prod.csv")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseSnapshotCSV(%q) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+// What: JSON snapshots list the item names per kind
+func TestParseSnapshotJSON(t *testing.T) {
+	content := `{"stylesheets": ["Nw4Part.Summary"], "preferences": ["TC_a", " "], "templates": []}`
+	snapshot, err := parseSnapshotJSON(strings.NewReader(content), "prod.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(snapshot[ItemStylesheet]) != 1 || len(snapshot[ItemPreference]) != 1 || len(snapshot[ItemTemplate]) != 0 {
+		t.Errorf("Unexpected snapshot %v", snapshot)
+	}
+
+	if _, err := parseSnapshotJSON(strings.NewReader(`{"workflows": []}`), "prod.json"); err == nil {
+		t.Error("Expected an error for an unknown item list")
+	}
+}
+
+// What: A missing snapshot is an I/O error, an invalid one a configuration error
+func TestLoadEnvironmentSnapshot_Kinds(t *testing.T) {
+	_, err := testRun.loadEnvironmentSnapshot(filepath.Join(t.TempDir(), "missing.csv"))
+	if Kind(err) != KindIO {
+		t.Errorf("Expected an I/O error, got %v", err)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "prod.json")
+	if err := os.WriteFile(invalid, []byte("[1]"), 0644); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	_, err = testRun.loadEnvironmentSnapshot(invalid)
+	if Kind(err) != KindConfig {
+		t.Errorf("Expected a configuration error, got %v", err)
+	}
+}
+
+// What: Preference names are read from the preference elements of an export
+func TestExtractPreferenceNames(t *testing.T) {
+	content := `<preferences version="10.0">
+  <category name="General">
+    <preference name="TC_a" type="String"><context name="Teamcenter"><value>x</value></context></preference>
+    <preference name="TC_b" type="Logical"/>
+  </category>
+</preferences>`
+	names, err := extractPreferenceNames(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"TC_a", "TC_b"}) {
+		t.Errorf("Expected [TC_a TC_b], got %v", names)
+	}
+}
+
+// What: Only preferences_manager invocations in import mode import preferences
+func TestIsPreferenceImportLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`$TC_BIN/preferences_manager -u=$U -mode=import -scope=SITE -file="090-Preferences/site.xml"`, true},
+		{`%TC_BIN%\preferences_manager.exe -mode=IMPORT -file="090-Preferences\site.xml"`, true},
+		{`$TC_BIN/preferences_manager -mode=export -file="090-Preferences/site.xml"`, false},
+		{`$TC_BIN/plmxml_import -mode=import -xml_file="100-Config/a.xml"`, false},
+	}
+	for _, tt := range tests {
+		if got := isPreferenceImportLine(tt.line); got != tt.want {
+			t.Errorf("isPreferenceImportLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// What: Installed items not deployed and deployed items not installed are reported at their location
+func TestCheckEnvironmentSnapshot(t *testing.T) {
+	originalItems, originalDeployed, originalResult := testRun.environmentItems, testRun.deployedItems, testRun.analysisResult
+	defer func() {
+		testRun.environmentItems, testRun.deployedItems, testRun.analysisResult = originalItems, originalDeployed, originalResult
+	}()
+	testRun.analysisResult = Result{}
+	testRun.environmentItems = environmentSnapshot{
+		ItemStylesheet: {"Nw4Part.Summary": {File: "prod.csv", Line: 2}, "Nw4Old.Summary": {File: "prod.csv", Line: 3}},
+		ItemTemplate:   {"nw4template": {File: "prod.csv", Line: 4}},
+	}
+	testRun.deployedItems = nil
+	testRun.recordDeployedItem(ItemStylesheet, "Nw4Part.Summary", itemLocation{File: "200-Stylesheets/import.txt", Line: 1})
+	testRun.recordDeployedItem(ItemTemplate, "nw4template", itemLocation{File: "deploy.sh", Line: 5})
+	testRun.recordDeployedItem(ItemPreference, "TC_new", itemLocation{File: "deploy.sh", Line: 7})
+	testRun.recordDeployedItem(ItemPreference, "TC_new", itemLocation{File: "deploy.sh", Line: 9})
+
+	testRun.checkEnvironmentSnapshot()
+
+	want := []Finding{
+		{Rule: RuleEnvironmentUnmanaged, Severity: SeverityInfo, Script: "prod.csv", Line: 3,
+			Message: "stylesheet 'Nw4Old.Summary' is installed in the environment but not deployed by any script"},
+		{Rule: RuleNotInEnvironment, Severity: SeverityWarning, Script: "deploy.sh", Line: 7,
+			Message: "preference 'TC_new' is deployed but not installed in the environment"},
+	}
+	// Fingerprints are covered by the findings tests
+	for i := range testRun.analysisResult.Findings {
+		testRun.analysisResult.Findings[i].Fingerprint = ""
+	}
+	if !reflect.DeepEqual(testRun.analysisResult.Findings, want) {
+		t.Errorf("Expected findings %v, got %v", want, testRun.analysisResult.Findings)
+	}
+}
+
+// What: Without a snapshot the cross-check reports nothing
+func TestCheckEnvironmentSnapshot_NotConfigured(t *testing.T) {
+	originalItems, originalResult := testRun.environmentItems, testRun.analysisResult
+	defer func() { testRun.environmentItems, testRun.analysisResult = originalItems, originalResult }()
+	testRun.environmentItems, testRun.analysisResult = nil, Result{}
+
+	testRun.checkEnvironmentSnapshot()
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", testRun.analysisResult.Findings)
+	}
+}
+
+// What: Datasets existing in the environment are reported on import lines without -replace only
+func TestCheckDatasetCollisions(t *testing.T) {
+	originalItems, originalResult := testRun.environmentItems, testRun.analysisResult
+	defer func() { testRun.environmentItems, testRun.analysisResult = originalItems, originalResult }()
+	testRun.analysisResult = Result{}
+	testRun.environmentItems = environmentSnapshot{ItemStylesheet: {"Nw4Part.Summary": {File: "prod.csv", Line: 2}}}
+	datasets := map[int]StylesheetDataset{1: {Name: "Nw4Part.Summary"}, 2: {Name: "Nw4New.Summary"}}
+
+	testRun.checkDatasetCollisions("deploy.sh", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", Replace: true, Datasets: datasets})
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Fatalf("Expected no findings with -replace, got %v", testRun.analysisResult.Findings)
+	}
+
+	testRun.checkDatasetCollisions("deploy.sh", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", Datasets: datasets})
+	if len(testRun.analysisResult.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %v", testRun.analysisResult.Findings)
+	}
+	f := testRun.analysisResult.Findings[0]
+	if f.Rule != RuleDatasetExists || f.Script != "deploy.sh" || f.Line != 3 || !strings.Contains(f.Message, "dataset 'Nw4Part.Summary' ('200-Stylesheets/import.txt' line '1')") {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}
+
+// What: -replace is detected as a whole flag of the stylesheet import line
+func TestStylesheetReplaceRegex(t *testing.T) {
+	testRun.initializeRegexPatterns([]string{"input"})
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`install_xml_stylesheet_datasets -input="a.txt" -filepath="200-Stylesheets/" -replace`, true},
+		{`install_xml_stylesheet_datasets -replace -input="a.txt"`, true},
+		{`install_xml_stylesheet_datasets -input="a.txt" -replace_all`, false},
+		{`install_xml_stylesheet_datasets -input="a.txt"`, false},
+	}
+	for _, tt := range tests {
+		if got := testRun.stylesheetReplaceRegex.MatchString(tt.line); got != tt.want {
+			t.Errorf("MatchString(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package analyzer
+
+import "errors"
+
+// ErrorKind classifies the errors stopping a run, so callers can tell broken scripts
+// from a run that could not complete
+type ErrorKind int
+
+const (
+	KindInternal  ErrorKind = iota // unexpected failure
+	KindConfig                     // the configuration or a configured script is invalid
+	KindIO                         // files, the repository, git or a remote cannot be read
+	KindThreshold                  // configured thresholds are exceeded
+)
+
+// kindError is an error of a kind, with the message of the error it wraps
+type kindError struct {
+	kind ErrorKind
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() error { return e.err }
+
+// withKind returns err classified as kind, nil when err is nil
+func withKind(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// Kind returns the kind of an error returned by Run or Trace; errors that were not
+// classified are internal
+func Kind(err error) ErrorKind {
+	var k *kindError
+	if errors.As(err, &k) {
+		return k.kind
+	}
+	return KindInternal
+}
+
+// Rules reporting a file that could not be read rather than a broken script
+var ioRules = map[string]bool{
+	RuleScriptUnreadable: true,
+	RuleTraversalError:   true,
+}
+
+// HasIOFindings reports whether some findings are files that could not be read
+func HasIOFindings(findings []Finding) bool {
+	for _, f := range findings {
+		if ioRules[f.Rule] {
+			return true
+		}
+	}
+	return false
+}
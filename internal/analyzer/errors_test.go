@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	// What: The kind survives wrapping, unclassified errors are internal
+	if Kind(fmt.Errorf("repository 'a': %w", withKind(KindIO, errors.New("remote")))) != KindIO {
+		t.Error("Expected the kind of the wrapped error")
+	}
+	if Kind(errors.New("unexpected")) != KindInternal {
+		t.Error("Expected an unclassified error to be internal")
+	}
+	if withKind(KindConfig, nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+	if err := withKind(KindConfig, errors.New("bad")); err.Error() != "bad" {
+		t.Errorf("Expected the message of the wrapped error, got %q", err.Error())
+	}
+}
+
+func TestHasIOFindings(t *testing.T) {
+	// What: Unreadable scripts and traversal errors are I/O findings, missing files are not
+	if HasIOFindings([]Finding{{Rule: RuleMissingFile}}) {
+		t.Error("Expected a missing file not to be an I/O finding")
+	}
+	if !HasIOFindings([]Finding{{Rule: RuleMissingFile}, {Rule: RuleScriptUnreadable}}) {
+		t.Error("Expected an unreadable script to be an I/O finding")
+	}
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	_ "embed"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleExplanation is the detailed description of a rule printed by explain
+type RuleExplanation struct {
+	Description string `yaml:"description"` // what the rule reports
+	Rationale   string `yaml:"rationale"`   // why it matters for a Teamcenter deployment
+	Fix         string `yaml:"fix"`
+	Suppress    string `yaml:"suppress"` // how to accept or silence an intended finding
+}
+
+//go:embed rules.yaml
+var ruleCatalog []byte
+
+// ruleExplanations are the explanations of the rule catalog, keyed by rule ID
+var ruleExplanations = mustParseRuleCatalog(ruleCatalog)
+
+// mustParseRuleCatalog parses the embedded rule catalog; it is part of the binary, so
+// a malformed catalog is a build defect
+func mustParseRuleCatalog(content []byte) map[string]RuleExplanation {
+	var explanations map[string]RuleExplanation
+	if err := yaml.Unmarshal(content, &explanations); err != nil {
+		panic("invalid rule catalog: " + err.Error())
+	}
+	return explanations
+}
+
+// ExplainRule returns the explanation of the rule with the ID
+func ExplainRule(id string) RuleExplanation {
+	return ruleExplanations[id]
+}
+
+// Rules returns the rule catalog sorted by rule ID
+func Rules() []Rule {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	catalog := make([]Rule, len(ids))
+	for i, id := range ids {
+		catalog[i] = rules[id]
+	}
+	return catalog
+}
@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestRuleCatalog_ExplainsEveryRule(t *testing.T) {
+	// What: The embedded catalog explains every rule completely and only known rules
+	for id := range rules {
+		e := ExplainRule(id)
+		if e.Description == "" || e.Rationale == "" || e.Fix == "" || e.Suppress == "" {
+			t.Errorf("Rule %s is not fully explained: %+v", id, e)
+		}
+	}
+	for id := range ruleExplanations {
+		if _, ok := rules[id]; !ok {
+			t.Errorf("Explanation of unknown rule %s", id)
+		}
+	}
+}
+
+func TestRules_SortedByID(t *testing.T) {
+	// What: The catalog lists all rules in ID order
+	catalog := Rules()
+	if len(catalog) != len(rules) {
+		t.Fatalf("Expected %d rules, got %d", len(rules), len(catalog))
+	}
+	for i := 1; i < len(catalog); i++ {
+		if catalog[i-1].ID >= catalog[i].ID {
+			t.Errorf("Rules not sorted: %s before %s", catalog[i-1].ID, catalog[i].ID)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package analyzer
+
+import (
+	"fmt"
+)
+
+// checkFindingLimits stops the run after the finding f was recorded as the last one
+// allowed: the first error with fail_fast, or the max_findings-th finding
+func (r *run) checkFindingLimits(f Finding) {
+	switch {
+	case r.failFast && f.Severity == SeverityError:
+		r.stopReason = fmt.Sprintf("fail_fast: stopped at the first error (%s)", f.Rule)
+	case r.maxFindings > 0 && len(r.analysisResult.Findings) >= r.maxFindings:
+		r.stopReason = fmt.Sprintf("max_findings: stopped after %d findings", r.maxFindings)
+	default:
+		return
+	}
+	r.log.Warning("Stopping the run, {r}; the remaining checks are skipped", "r", r.stopReason)
+}
+
+// runStopped reports whether the run stopped early: the remaining phases are skipped
+// and further findings are not recorded
+func (r *run) runStopped() bool {
+	return r.stopReason != ""
+}
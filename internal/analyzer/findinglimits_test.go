@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests for stopping a run early
+
+// What: The max_findings-th finding is the last recorded, later ones are dropped
+func TestRecordFinding_MaxFindings(t *testing.T) {
+	originalResult := testRun.analysisResult
+	defer func() {
+		testRun.analysisResult, testRun.maxFindings, testRun.failFast, testRun.stopReason = originalResult, 0, false, ""
+	}()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.maxFindings, testRun.failFast, testRun.stopReason = 2, false, ""
+
+	for i := 0; i < 3; i++ {
+		testRun.reportFinding(Finding{Rule: RuleDuplicateContent}, "duplicate content")
+	}
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Errorf("Expected 2 findings, got %d", len(testRun.analysisResult.Findings))
+	}
+	if !testRun.runStopped() || !strings.Contains(testRun.stopReason, "after 2 findings") {
+		t.Errorf("Expected the run to stop, got %q", testRun.stopReason)
+	}
+}
+
+// What: With fail_fast the first error stops the run, warnings do not
+func TestRecordFinding_FailFast(t *testing.T) {
+	originalResult := testRun.analysisResult
+	defer func() {
+		testRun.analysisResult, testRun.maxFindings, testRun.failFast, testRun.stopReason = originalResult, 0, false, ""
+	}()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.maxFindings, testRun.failFast, testRun.stopReason = 0, true, ""
+
+	testRun.reportFinding(Finding{Rule: RuleUnusedIgnorePattern}, "unused")
+	if testRun.runStopped() {
+		t.Fatalf("Expected a warning not to stop the run, got %q", testRun.stopReason)
+	}
+	testRun.reportFinding(Finding{Rule: RuleMissingFile}, "missing")
+	if testRun.recordFinding(Finding{Rule: RuleMissingFile}) {
+		t.Error("Expected no finding recorded after the stop")
+	}
+	if len(testRun.analysisResult.Findings) != 2 || !strings.Contains(testRun.stopReason, "first error (TCX010)") {
+		t.Errorf("Expected the run to stop at the missing file, got %d findings, %q", len(testRun.analysisResult.Findings), testRun.stopReason)
+	}
+}
+
+// What: Phases after the stop are skipped and a stopped run does not pass
+func TestRunStopped_SkipsPhasesAndFails(t *testing.T) {
+	originalResult := testRun.analysisResult
+	defer func() { testRun.analysisResult, testRun.stopReason = originalResult, "" }()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.stopReason = "max_findings: stopped after 1 findings"
+
+	ran := false
+	testRun.timeScriptPhase(PhaseSyntax, func() { ran = true })
+	testRun.timeRunPhase(PhaseParity, func() { ran = true })
+	if ran {
+		t.Error("Expected the phases to be skipped")
+	}
+	summary := testRun.summarize(nil, thresholds{}, nil)
+	if summary.Passed || summary.Stopped != testRun.stopReason {
+		t.Errorf("Expected a failed summary with the stop reason, got %+v", summary)
+	}
+}
@@ -0,0 +1,332 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a single issue reported by one of the checks.
+// Script and Line locate the finding in the file it was found in: a deployment
+// script, or a list file such as a stylesheet import definition. Line and Column
+// are 1-based, 0 when not applicable. Path is written as in the script, NormalizedPath
+// with forward slashes, the same whether the analysis runs on Windows or Linux. Owner
+// is the team or person owning the path (or the file) by the configured ownership patterns.
+type Finding struct {
+	Rule           string `json:"rule"`
+	Severity       string `json:"severity"`
+	Script         string `json:"script,omitempty"`
+	Line           int    `json:"line,omitempty"`
+	Column         int    `json:"column,omitempty"`
+	Path           string `json:"path,omitempty"`
+	NormalizedPath string `json:"normalized_path,omitempty"`
+	Message        string `json:"message"`
+	Suggestion     string `json:"suggestion,omitempty"`
+	Owner          string `json:"owner,omitempty"`
+	// Fingerprint identifies the finding across runs, whatever its line number
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Scripts reporting the same rule and path, when deduplicated into this finding
+	Scripts []string `json:"scripts,omitempty"`
+	// hostPath marks a Path in the notation of the host OS, e.g. a repository file found
+	// by the traversal, instead of the notation of the script
+	hostPath bool
+}
+
+// Rule describes a check reported in findings
+type Rule struct {
+	ID       string
+	Name     string
+	Severity string
+	Summary  string
+}
+
+// Rule identifiers
+const (
+	RuleFlagNotQuoted        = "TCX001"
+	RuleWrongSeparator       = "TCX002"
+	RuleWindowsPathRoot      = "TCX003"
+	RuleScriptUnreadable     = "TCX004"
+	RuleScriptEncoding       = "TCX005"
+	RuleRequiredFlag         = "TCX006"
+	RuleForbiddenFlag        = "TCX007"
+	RuleTemplateValue        = "TCX008"
+	RuleCredentialFlag       = "TCX009"
+	RuleMissingFile          = "TCX010"
+	RulePathEscapesRoot      = "TCX011"
+	RuleMissingAttachment    = "TCX012"
+	RuleXMLUnreadable        = "TCX013"
+	RuleTemplatePackage      = "TCX014"
+	RuleTemplateVersion      = "TCX015"
+	RuleArchiveContents      = "TCX016"
+	RuleNotExecutable        = "TCX017"
+	RuleWorldWritable        = "TCX018"
+	RuleDuplicateContent     = "TCX019"
+	RuleUnreferencedFile     = "TCX020"
+	RuleTraversalError       = "TCX021"
+	RuleSymlinkOutsideRoot   = "TCX022"
+	RuleSymlinkNotAllowed    = "TCX023"
+	RuleUnusedIgnorePattern  = "TCX024"
+	RuleStylesheetInputLine  = "TCX025"
+	RuleStylesheetInput      = "TCX026"
+	RuleConditionalReference = "TCX027"
+	RuleLoopNotExpanded      = "TCX028"
+	RuleHeredocPath          = "TCX029"
+	RuleExecutableParity     = "TCX030"
+	RulePathParity           = "TCX031"
+	RuleDeletedInBranch      = "TCX032"
+	RuleStaleRename          = "TCX033"
+	RuleListFileRow          = "TCX034"
+	RuleListFile             = "TCX035"
+	RuleListFileCycle        = "TCX036"
+	RuleStylesheetRendering  = "TCX037"
+	RuleExecutableNotAllowed = "TCX038"
+	RuleBareUtility          = "TCX039"
+	RuleThresholdExceeded    = "TCX040"
+	RulePerfBudgetExceeded   = "TCX041"
+	RuleIgnoredReference     = "TCX042"
+	RuleEmptyDirectory       = "TCX043"
+	RulePathNotNormalized    = "TCX044"
+	RuleTextCharacters       = "TCX045"
+	RulePreferenceFlags      = "TCX046"
+	RuleContentRule          = "TCX047"
+	RuleMissingArtifact      = "TCX050"
+	RuleArtifactRepository   = "TCX051"
+	RuleRemoteReference      = "TCX052"
+	RuleRemoteUnreachable    = "TCX053"
+	RuleEnvironmentUnmanaged = "TCX060"
+	RuleNotInEnvironment     = "TCX061"
+	RuleDatasetExists        = "TCX062"
+)
+
+// rules is the catalog of all rules, keyed by rule ID
+var rules = map[string]Rule{
+	RuleFlagNotQuoted:        {RuleFlagNotQuoted, "flag-not-quoted", SeverityError, "Path flag is present but its value is not quoted"},
+	RuleWrongSeparator:       {RuleWrongSeparator, "wrong-separator", SeverityError, "Path separator does not match the script target OS"},
+	RuleWindowsPathRoot:      {RuleWindowsPathRoot, "windows-path-root", SeverityError, "UNC path or drive letter not allowed for the script"},
+	RuleScriptUnreadable:     {RuleScriptUnreadable, "script-unreadable", SeverityError, "Deployment script cannot be read"},
+	RuleScriptEncoding:       {RuleScriptEncoding, "script-encoding", SeverityWarning, "Script is not encoded in UTF-8 and was transcoded"},
+	RuleRequiredFlag:         {RuleRequiredFlag, "required-flag", SeverityError, "Utility is called without a flag required by the flag rules"},
+	RuleForbiddenFlag:        {RuleForbiddenFlag, "forbidden-flag", SeverityError, "Utility is called with a flag forbidden by the flag rules"},
+	RuleTemplateValue:        {RuleTemplateValue, "template-value", SeverityWarning, "Template expression has no value and is validated as wildcard"},
+	RuleCredentialFlag:       {RuleCredentialFlag, "credential-flag", SeverityError, "Credential flag with a literal, unapproved or mixed value"},
+	RuleMissingFile:          {RuleMissingFile, "missing-file", SeverityError, "Referenced path not found on the file system"},
+	RulePathEscapesRoot:      {RulePathEscapesRoot, "path-escapes-root", SeverityError, "Referenced path resolves outside the source code root"},
+	RuleMissingAttachment:    {RuleMissingAttachment, "missing-attachment", SeverityError, "File attached in a PLMXML/TCXML not found"},
+	RuleXMLUnreadable:        {RuleXMLUnreadable, "xml-unreadable", SeverityError, "Imported XML cannot be read or parsed"},
+	RuleTemplatePackage:      {RuleTemplatePackage, "template-package", SeverityError, "BMIDE template package missing or invocation incomplete"},
+	RuleTemplateVersion:      {RuleTemplateVersion, "template-version", SeverityError, "BMIDE template package version does not match"},
+	RuleArchiveContents:      {RuleArchiveContents, "archive-contents", SeverityError, "Referenced archive is corrupt, empty or has unexpected contents"},
+	RuleNotExecutable:        {RuleNotExecutable, "not-executable", SeverityError, "Linux script or helper does not have the executable bit set"},
+	RuleWorldWritable:        {RuleWorldWritable, "world-writable", SeverityWarning, "Referenced file is world-writable"},
+	RuleDuplicateContent:     {RuleDuplicateContent, "duplicate-content", SeverityInfo, "Referenced files have identical content"},
+	RuleUnreferencedFile:     {RuleUnreferencedFile, "unreferenced-file", SeverityError, "Repository file not referenced by the script"},
+	RuleTraversalError:       {RuleTraversalError, "traversal-error", SeverityError, "Path cannot be accessed during traversal"},
+	RuleSymlinkOutsideRoot:   {RuleSymlinkOutsideRoot, "symlink-outside-root", SeverityError, "Symlink is broken or points outside the source code root"},
+	RuleSymlinkNotAllowed:    {RuleSymlinkNotAllowed, "symlink-not-allowed", SeverityError, "Symlink found while symlinks are not allowed"},
+	RuleUnusedIgnorePattern:  {RuleUnusedIgnorePattern, "unused-ignore-pattern", SeverityWarning, "Ignore pattern did not match any path"},
+	RuleStylesheetInputLine:  {RuleStylesheetInputLine, "stylesheet-input-line", SeverityError, "Stylesheet import definition line has an invalid format"},
+	RuleStylesheetInput:      {RuleStylesheetInput, "stylesheet-input", SeverityError, "Stylesheet import definition cannot be processed"},
+	RuleConditionalReference: {RuleConditionalReference, "conditional-reference", SeverityInfo, "Repository file referenced only inside a conditional block"},
+	RuleLoopNotExpanded:      {RuleLoopNotExpanded, "loop-not-expanded", SeverityWarning, "For-loop item cannot be expanded against the repository"},
+	RuleHeredocPath:          {RuleHeredocPath, "heredoc-path", SeverityInfo, "Path flag found in a heredoc body, not validated"},
+	RuleExecutableParity:     {RuleExecutableParity, "executable-parity", SeverityError, "Executable called only by Windows or only by Linux scripts"},
+	RulePathParity:           {RulePathParity, "path-parity", SeverityError, "Path referenced only by Windows or only by Linux scripts"},
+	RuleDeletedInBranch:      {RuleDeletedInBranch, "deleted-in-branch", SeverityError, "Referenced path was deleted or renamed since the base branch"},
+	RuleStaleRename:          {RuleStaleRename, "stale-rename", SeverityError, "Repository file renamed in the branch is referenced by its old name"},
+	RuleListFileRow:          {RuleListFileRow, "list-file-row", SeverityError, "List file row has no reference in the declared column"},
+	RuleListFile:             {RuleListFile, "list-file", SeverityError, "List file of a list import cannot be processed"},
+	RuleListFileCycle:        {RuleListFileCycle, "list-file-cycle", SeverityError, "Nested list file references a list it is read from"},
+	RuleStylesheetRendering:  {RuleStylesheetRendering, "stylesheet-rendering", SeverityError, "Stylesheet XML lacks the structure needed to render"},
+	RuleExecutableNotAllowed: {RuleExecutableNotAllowed, "executable-not-allowed", SeverityError, "Script calls an executable not in allowed_executables"},
+	RuleBareUtility:          {RuleBareUtility, "bare-utility", SeverityWarning, "Teamcenter utility not called through the bin prefix"},
+	RuleThresholdExceeded:    {RuleThresholdExceeded, "threshold-exceeded", SeverityError, "Configured threshold exceeded"},
+	RulePerfBudgetExceeded:   {RulePerfBudgetExceeded, "perf-budget-exceeded", SeverityError, "Analysis phase took longer than its perf_budget"},
+	RuleIgnoredReference:     {RuleIgnoredReference, "ignored-reference", SeverityWarning, "Referenced file is excluded by an ignore pattern"},
+	RuleEmptyDirectory:       {RuleEmptyDirectory, "empty-directory", SeverityError, "Referenced directory has no files to deploy"},
+	RulePathNotNormalized:    {RulePathNotNormalized, "path-not-normalized", SeverityWarning, "Referenced path matches only once normalized"},
+	RuleTextCharacters:       {RuleTextCharacters, "text-characters", SeverityWarning, "Referenced text file has a byte order mark, smart quotes or non-breaking spaces"},
+	RulePreferenceFlags:      {RulePreferenceFlags, "preference-flags", SeverityError, "preferences_manager called with an illegal -mode, -scope and -action combination"},
+	RuleContentRule:          {RuleContentRule, "content-rule", SeverityWarning, "Script or referenced file line matches a configured content rule"},
+	RuleMissingArtifact:      {RuleMissingArtifact, "missing-artifact", SeverityError, "Referenced artifact version not found in the artifact repository"},
+	RuleArtifactRepository:   {RuleArtifactRepository, "artifact-repository", SeverityError, "Artifact repository cannot be queried"},
+	RuleRemoteReference:      {RuleRemoteReference, "remote-reference", SeverityInfo, "Path flag references a URL instead of a repository file"},
+	RuleRemoteUnreachable:    {RuleRemoteUnreachable, "remote-unreachable", SeverityError, "Referenced URL is not reachable"},
+	RuleEnvironmentUnmanaged: {RuleEnvironmentUnmanaged, "environment-unmanaged", SeverityInfo, "Item installed in the environment is not deployed by any script"},
+	RuleNotInEnvironment:     {RuleNotInEnvironment, "not-in-environment", SeverityWarning, "Item deployed by the scripts is not installed in the environment"},
+	RuleDatasetExists:        {RuleDatasetExists, "dataset-exists", SeverityError, "Stylesheet dataset exists in the environment and is imported without -replace"},
+}
+
+// LookupRule returns the rule with the ID or name, e.g. TCX010 or missing-file
+func LookupRule(idOrName string) (Rule, bool) {
+	if rule, ok := rules[strings.ToUpper(idOrName)]; ok {
+		return rule, true
+	}
+	for _, rule := range rules {
+		if rule.Name == idOrName {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// logsFinding reports whether the finding is written to the log
+func (r *run) logsFinding(f Finding) bool {
+	return r.findingLogFilter == nil || r.findingLogFilter(f)
+}
+
+// findingKey is the rule and path of a finding, the identity of deduplicated findings
+func findingKey(f Finding) string {
+	return f.Rule + "\x00" + f.Path
+}
+
+// isRepeatedFinding records the script of a finding with a path and reports whether
+// the rule and path was already logged for another script
+func (r *run) isRepeatedFinding(f Finding) bool {
+	if !r.deduplicateFindings || f.Path == "" || f.Script == "" {
+		return false
+	}
+	if r.loggedFindingScripts == nil {
+		r.loggedFindingScripts = make(map[string][]string)
+	}
+	key := findingKey(f)
+	scripts := r.loggedFindingScripts[key]
+	for _, script := range scripts {
+		if script == f.Script {
+			return false
+		}
+	}
+	r.loggedFindingScripts[key] = append(scripts, f.Script)
+	return len(scripts) > 0
+}
+
+// logRepeatedFindings lists the rules and paths reported for several scripts, sorted
+func (r *run) logRepeatedFindings() {
+	keys := make([]string, 0, len(r.loggedFindingScripts))
+	for key, scripts := range r.loggedFindingScripts {
+		if len(scripts) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	r.log.Separate("FINDINGS REPEATED ACROSS SCRIPTS")
+	for _, key := range keys {
+		rule, path, _ := strings.Cut(key, "\x00")
+		scripts := r.loggedFindingScripts[key]
+		r.log.Separate("{r} '{p}' reported for {n} scripts: {s}", "r", rule, "p", path, "n", len(scripts), "s", strings.Join(scripts, ", "))
+	}
+}
+
+// recordFinding adds a finding to the analysis result without logging it and reports
+// whether it was recorded: a run stopped early records no further findings, nor does a
+// ruleset disabling the rule. The severity defaults to the one of the rule in the
+// ruleset, the owner is set from the ownership patterns.
+func (r *run) recordFinding(f Finding) bool {
+	if r.runStopped() || !r.ruleEnabled(f.Rule) {
+		return false
+	}
+	if f.Severity == "" {
+		f.Severity = r.ruleSeverity(f.Rule)
+	}
+	if f.Path != "" {
+		notation := r.currentScriptTargetOS
+		if f.hostPath {
+			notation = hostOS
+		}
+		f.NormalizedPath = slashPath(f.Path, notation)
+	}
+	f.Message = normalizeNewlines(f.Message)
+	f.Suggestion = normalizeNewlines(f.Suggestion)
+	f.Fingerprint = r.findingFingerprint(f)
+	f.Owner = r.findingOwner(f)
+	r.analysisResult.Findings = append(r.analysisResult.Findings, f)
+	r.checkFindingLimits(f)
+	return true
+}
+
+// normalizeNewlines renders the line breaks of script excerpts in messages as \n,
+// whatever the line endings of the script
+func normalizeNewlines(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+}
+
+// recordLineText keeps the text of a script line for the fingerprints of its findings
+func (r *run) recordLineText(scriptFile string, lineNumber int, line string) {
+	lines, ok := r.analysisResult.File[scriptFile]
+	if !ok {
+		return
+	}
+	if lines.Text == nil {
+		lines.Text = make(map[int]string)
+		r.analysisResult.File[scriptFile] = lines
+	}
+	lines.Text[lineNumber] = line
+}
+
+// findingFingerprint returns the fingerprint of a finding: a hash of its rule, file,
+// normalized path and the text of its line with the whitespace collapsed, or its
+// message when neither the path nor the line is known. Line numbers are left out, so
+// the fingerprint survives lines inserted above the finding.
+func (r *run) findingFingerprint(f Finding) string {
+	content := strings.Join(strings.Fields(r.analysisResult.File[f.Script].Text[f.Line]), " ")
+	if content == "" && f.Path == "" {
+		content = toSlash(f.Message)
+	}
+	h := sha256.New()
+	for _, part := range []string{f.Rule, toSlash(f.Script), f.PortablePath(), content} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// PortablePath returns the path of the finding in the notation shared by Windows and
+// Linux agents: NormalizedPath, or Path with forward slashes for findings recorded
+// without it, e.g. in baselines of earlier versions
+func (f Finding) PortablePath() string {
+	if f.NormalizedPath != "" || f.Path == "" {
+		return f.NormalizedPath
+	}
+	return toSlash(f.Path)
+}
+
+// findingEntry returns the log entry of a finding, with its location and rule as
+// fields of the JSON log format
+func (r *run) findingEntry(f Finding) logger.Entry {
+	return r.log.With("rule", f.Rule, "severity", f.Severity, "script", f.Script, "line", f.Line, "column", f.Column, "path", f.Path, "owner", f.Owner)
+}
+
+// reportFinding formats the finding message from format and args (see logger),
+// records the finding and logs it with the level matching its severity.
+func (r *run) reportFinding(f Finding, format string, args ...interface{}) {
+	f.Message = logger.Format(format, args...)
+	if !r.recordFinding(f) {
+		return
+	}
+
+	recorded := r.analysisResult.Findings[len(r.analysisResult.Findings)-1]
+	if !r.logsFinding(recorded) || r.isRepeatedFinding(recorded) {
+		return
+	}
+	entry := r.findingEntry(recorded)
+	switch recorded.Severity {
+	case SeverityError:
+		entry.Error(f.Message)
+	case SeverityWarning:
+		entry.Warning(f.Message)
+	default:
+		entry.Info(f.Message)
+	}
+}
@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Tests for findings recording
+
+func TestRules_CatalogIsConsistent(t *testing.T) {
+	// What: Every rule is registered under its own ID with a known severity
+	for id, rule := range rules {
+		if rule.ID != id {
+			t.Errorf("Rule %s registered with ID %s", id, rule.ID)
+		}
+		switch rule.Severity {
+		case SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			t.Errorf("Rule %s has unknown severity %q", id, rule.Severity)
+		}
+	}
+}
+
+func TestReportFinding_DefaultsSeverityAndFormatsMessage(t *testing.T) {
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+
+	testRun.reportFinding(Finding{Rule: RuleDuplicateContent, Script: "deploy.sh"}, "'{s}' has duplicates", "s", "deploy.sh")
+	testRun.reportFinding(Finding{Rule: RuleMissingFile, Severity: SeverityWarning}, "overridden")
+
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(testRun.analysisResult.Findings))
+	}
+	f := testRun.analysisResult.Findings[0]
+	if f.Severity != SeverityInfo || f.Message != "'deploy.sh' has duplicates" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if testRun.analysisResult.Findings[1].Severity != SeverityWarning {
+		t.Errorf("Expected explicit severity to be kept, got %q", testRun.analysisResult.Findings[1].Severity)
+	}
+}
+
+func TestRecordFinding_NormalizesPathAndNewlines(t *testing.T) {
+	// What: Paths get a forward slash form in the notation of the script OS, excerpts line breaks as \n
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	t.Cleanup(func() { testRun.currentScriptTargetOS = "" })
+
+	testRun.currentScriptTargetOS = "windows"
+	testRun.recordFinding(Finding{Rule: RuleMissingFile, Script: "deploy.bat", Path: `100-Config\a.xml`, Message: "line\r\nnext\r"})
+	testRun.currentScriptTargetOS = "linux"
+	testRun.recordFinding(Finding{Rule: RuleMissingFile, Script: "deploy.sh", Path: `100-Config/a\ b.xml`})
+	testRun.recordFinding(Finding{Rule: RuleDuplicateContent, Script: "deploy.sh"})
+
+	if f := testRun.analysisResult.Findings[0]; f.Path != `100-Config\a.xml` || f.NormalizedPath != "100-Config/a.xml" || f.Message != "line\nnext\n" {
+		t.Errorf("Unexpected Windows finding: %+v", f)
+	}
+	if f := testRun.analysisResult.Findings[1]; f.NormalizedPath != `100-Config/a\ b.xml` {
+		t.Errorf("Expected the escape of the Linux path kept, got %+v", f)
+	}
+	if f := testRun.analysisResult.Findings[2]; f.NormalizedPath != "" || f.PortablePath() != "" {
+		t.Errorf("Expected no normalized path without a path, got %+v", f)
+	}
+	if got := (Finding{Path: `100-Config\a.xml`}).PortablePath(); got != "100-Config/a.xml" {
+		t.Errorf("PortablePath() without a normalized path = %q", got)
+	}
+}
+
+func TestRecordFinding_NormalizesHostPaths(t *testing.T) {
+	// What: Repository files found on a Windows agent normalize like on a Linux agent, whatever the script OS
+	t.Cleanup(func() { testRun.currentScriptTargetOS, hostOS = "", runtime.GOOS })
+	normalized := func(host, item string) Finding {
+		testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+		testRun.currentScriptTargetOS, hostOS = "linux", host
+		testRun.reportUnreferencedFiles("deploy.sh", "/repo", []string{item}, nil, nil)
+		return testRun.analysisResult.Findings[0]
+	}
+
+	windows, linux := normalized("windows", `100-Config\a.xml`), normalized("linux", "100-Config/a.xml")
+	if windows.NormalizedPath != "100-Config/a.xml" || windows.Path != `100-Config\a.xml` {
+		t.Errorf("Expected the Windows host path with forward slashes, got %+v", windows)
+	}
+	if windows.Fingerprint != linux.Fingerprint {
+		t.Errorf("Expected the same fingerprint on both agents, got %s and %s", windows.Fingerprint, linux.Fingerprint)
+	}
+
+	// Paths of the script keep the notation of its OS
+	hostOS = "windows"
+	testRun.recordFinding(Finding{Rule: RuleMissingFile, Script: "deploy.sh", Path: `100-Config/a\ b.xml`})
+	if f := testRun.analysisResult.Findings[1]; f.NormalizedPath != `100-Config/a\ b.xml` {
+		t.Errorf("Expected the escape of the Linux script path kept, got %+v", f)
+	}
+}
+
+func TestRecordFinding_Fingerprint(t *testing.T) {
+	// What: Fingerprints leave out the line number and whitespace, and change with the line text
+	fingerprint := func(lineNumber int, text string) string {
+		testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+		testRun.recordLineText("deploy.sh", lineNumber, text)
+		testRun.recordFinding(Finding{Rule: RuleFlagNotQuoted, Script: "deploy.sh", Line: lineNumber, Path: "a.xml"})
+		return testRun.analysisResult.Findings[0].Fingerprint
+	}
+	first := fingerprint(3, "plmxml_import -xml_file=a.xml")
+	if moved := fingerprint(9, "  plmxml_import   -xml_file=a.xml"); moved != first {
+		t.Errorf("Expected the fingerprint to survive the line moving, got %s and %s", first, moved)
+	}
+	if changed := fingerprint(3, "plmxml_import -xml_file=a.xml -import_mode=overwrite"); changed == first {
+		t.Errorf("Expected another fingerprint for another line, got %s", changed)
+	}
+	if len(first) != 16 {
+		t.Errorf("Expected a fingerprint of 16 characters, got %q", first)
+	}
+}
+
+func TestLookupRule(t *testing.T) {
+	// What: Rules are found by ID, in any case, or by name
+	for _, name := range []string{"TCX010", "tcx010", "missing-file"} {
+		if rule, ok := LookupRule(name); !ok || rule.ID != RuleMissingFile {
+			t.Errorf("LookupRule(%q) = %+v, %v", name, rule, ok)
+		}
+	}
+	if _, ok := LookupRule("no-such-rule"); ok {
+		t.Error("Expected an unknown rule not to be found")
+	}
+}
+
+func TestReportFinding_LogFilter(t *testing.T) {
+	// What: Findings left out by the log filter are recorded but not logged
+	var out bytes.Buffer
+	logger.SetConsoleOutput(&out)
+	logger.InitLogger("", "info")
+	defer func() {
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+		testRun.findingLogFilter = nil
+	}()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.findingLogFilter = func(f Finding) bool { return f.Rule == RuleMissingFile }
+
+	testRun.reportFinding(Finding{Rule: RuleDuplicateContent}, "duplicate content")
+	testRun.reportFinding(Finding{Rule: RuleMissingFile}, "missing file")
+
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(testRun.analysisResult.Findings))
+	}
+	if strings.Contains(out.String(), "duplicate content") || !strings.Contains(out.String(), "missing file") {
+		t.Errorf("Expected only the missing file in the log, got %q", out.String())
+	}
+}
+
+func TestReportFinding_RepeatedAcrossScripts(t *testing.T) {
+	// What: A rule and path repeated by another script is logged once and listed with its scripts
+	var out bytes.Buffer
+	logger.SetConsoleOutput(&out)
+	logger.InitLogger("", "error")
+	defer func() {
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+		testRun.loggedFindingScripts = nil
+	}()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.loggedFindingScripts = nil
+
+	for _, script := range []string{"a.sh", "b.sh", "a.sh"} {
+		testRun.reportFinding(Finding{Rule: RuleUnreferencedFile, Script: script, Path: "100-Config/x.xml"}, "'{s}' does not reference x.xml", "s", script)
+	}
+	testRun.logRepeatedFindings()
+
+	if len(testRun.analysisResult.Findings) != 3 {
+		t.Fatalf("Expected all 3 findings recorded, got %d", len(testRun.analysisResult.Findings))
+	}
+	if strings.Contains(out.String(), "'b.sh' does not reference") || strings.Count(out.String(), "'a.sh' does not reference") != 2 {
+		t.Errorf("Expected the finding of b.sh not logged, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "TCX020 '100-Config/x.xml' reported for 2 scripts: a.sh, b.sh") {
+		t.Errorf("Expected the repeated finding listed, got %q", out.String())
+	}
+}
+
+func TestParseLineAsCommand_RecordsFindings(t *testing.T) {
+	// What: Unquoted flags and wrong separators produce findings with rule and location
+	setupSyntaxTest()
+	testRun.analysisResult.Findings = nil
+	filename := "deploy_linux.sh"
+	initTestFile(filename, "linux")
+
+	testRun.parseLineAsCommand(filename, `plmxml_import -i=data/file.xml`, 3)
+	testRun.parseLineAsCommand(filename, `plmxml_import -i="data\file.xml"`, 4)
+
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d: %+v", len(testRun.analysisResult.Findings), testRun.analysisResult.Findings)
+	}
+	if f := testRun.analysisResult.Findings[0]; f.Rule != RuleFlagNotQuoted || f.Line != 3 || f.Script != filename {
+		t.Errorf("Unexpected first finding: %+v", f)
+	}
+	if f := testRun.analysisResult.Findings[1]; f.Rule != RuleWrongSeparator || f.Line != 4 || f.Path != `data\file.xml` {
+		t.Errorf("Unexpected second finding: %+v", f)
+	}
+}
+
+func TestCheckFilePathsInScript_RecordsMissingFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "findings-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	originalRoot := testRun.sourceCodeRoot
+	testRun.sourceCodeRoot = tmpDir
+	defer func() { testRun.sourceCodeRoot = originalRoot }()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+
+	testRun.checkFilePathsInScript("deploy.sh", pathFlags(map[int]string{7: "missing.xml"}))
+
+	if len(testRun.analysisResult.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(testRun.analysisResult.Findings))
+	}
+	if f := testRun.analysisResult.Findings[0]; f.Rule != RuleMissingFile || f.Line != 7 || f.Path != "missing.xml" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// flag_rules:
+//   - utility: install_xml_stylesheet_datasets
+//     required: ['-replace']
+//   - utility: plmxml_import
+//     forbidden: ['-overwrite']
+//     scripts: ['*_prod.sh']
+
+// compiledFlag is a flag of a flag rule with the pattern matching it as a whole word
+type compiledFlag struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// compiledFlagRule is a flag rule ready to be matched against script lines
+type compiledFlagRule struct {
+	Utility   string
+	Required  []compiledFlag
+	Forbidden []compiledFlag
+	Scripts   []string
+}
+
+// flagRuleName returns the flag name without its leading dashes
+func flagRuleName(flag string) string {
+	return strings.TrimLeft(strings.TrimSpace(flag), "-")
+}
+
+// ValidateFlagRules checks that each flag rule names a utility and at least one
+// flag, and that its script patterns are valid
+func ValidateFlagRules(rules []FlagRule) error {
+	for i, r := range rules {
+		if strings.TrimSpace(r.Utility) == "" {
+			return fmt.Errorf("flag rule at index %d is missing 'utility'", i)
+		}
+		if len(r.Required) == 0 && len(r.Forbidden) == 0 {
+			return fmt.Errorf("flag rule for '%s' needs 'required' or 'forbidden' flags", r.Utility)
+		}
+		for _, flag := range append(append([]string{}, r.Required...), r.Forbidden...) {
+			if flagRuleName(flag) == "" {
+				return fmt.Errorf("flag rule for '%s' has an empty flag", r.Utility)
+			}
+		}
+		for _, pattern := range r.Scripts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("flag rule for '%s' has an invalid script pattern '%s': %w", r.Utility, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compileFlagRules compiles the flag patterns of the rules. Utilities are compared
+// by executable name, as tracked for the parity check (lowercase, without extension).
+func (r *run) compileFlagRules(rules []FlagRule) []compiledFlagRule {
+	compile := func(flags []string) []compiledFlag {
+		compiled := make([]compiledFlag, 0, len(flags))
+		for _, flag := range flags {
+			name := flagRuleName(flag)
+			compiled = append(compiled, compiledFlag{Name: name, Pattern: regexp.MustCompile(r.flagPrefix(name) + `(?:[=\s"']|$)`)})
+		}
+		return compiled
+	}
+	compiled := make([]compiledFlagRule, 0, len(rules))
+	for _, rule := range rules {
+		compiled = append(compiled, compiledFlagRule{
+			Utility:   extractExecutableName(rule.Utility),
+			Required:  compile(rule.Required),
+			Forbidden: compile(rule.Forbidden),
+			Scripts:   rule.Scripts,
+		})
+	}
+	return compiled
+}
+
+// appliesTo reports whether the rule applies to the script, by its path or file name
+func (r compiledFlagRule) appliesTo(scriptFile string) bool {
+	if len(r.Scripts) == 0 {
+		return true
+	}
+	for _, pattern := range r.Scripts {
+		if matchesFilePattern(pattern, scriptFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFlagRules reports the required flags missing from and the forbidden flags
+// present on a line invoking a utility with flag rules
+func (r *run) checkFlagRules(scriptFile string, line string, lineNumber int) {
+	if len(r.flagRules) == 0 {
+		return
+	}
+	executable := extractExecutableName(line)
+	if executable == "" {
+		return
+	}
+	for _, rule := range r.flagRules {
+		if rule.Utility != executable || !rule.appliesTo(scriptFile) {
+			continue
+		}
+		for _, flag := range rule.Required {
+			if flag.Pattern.MatchString(line) {
+				continue
+			}
+			r.reportFinding(Finding{Rule: RuleRequiredFlag, Script: scriptFile, Line: lineNumber,
+				Suggestion: logger.Format("add -{fl}", "fl", flag.Name)},
+				"'{f}' line '{ln}': '{u}' is called without the required flag '-{fl}'", "f", scriptFile, "ln", lineNumber, "u", executable, "fl", flag.Name)
+		}
+		for _, flag := range rule.Forbidden {
+			location := flag.Pattern.FindStringIndex(line)
+			if location == nil {
+				continue
+			}
+			r.reportFinding(Finding{Rule: RuleForbiddenFlag, Script: scriptFile, Line: lineNumber, Column: flagColumn(line, location),
+				Suggestion: logger.Format("remove -{fl}", "fl", flag.Name)},
+				"'{f}' line '{ln}': '{u}' is called with the forbidden flag '-{fl}'", "f", scriptFile, "ln", lineNumber, "u", executable, "fl", flag.Name)
+		}
+	}
+}
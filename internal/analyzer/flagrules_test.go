@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests for the per-utility flag rules
+
+// What: Rules need a utility, at least one flag and valid script patterns
+func TestValidateFlagRules(t *testing.T) {
+	tests := []struct {
+		rules []FlagRule
+		want  string
+	}{
+		{[]FlagRule{{Utility: "plmxml_import", Forbidden: []string{"-overwrite"}, Scripts: []string{"*_prod.sh"}}}, ""},
+		{[]FlagRule{{Required: []string{"-replace"}}}, "flag rule at index 0 is missing 'utility'"},
+		{[]FlagRule{{Utility: "plmxml_import"}}, "needs 'required' or 'forbidden' flags"},
+		{[]FlagRule{{Utility: "plmxml_import", Required: []string{"--"}}}, "has an empty flag"},
+		{[]FlagRule{{Utility: "plmxml_import", Required: []string{"x"}, Scripts: []string{"[prod"}}}, "invalid script pattern '[prod'"},
+	}
+	for _, tt := range tests {
+		err := ValidateFlagRules(tt.rules)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("ValidateFlagRules(%v) unexpected error: %v", tt.rules, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateFlagRules(%v) error = %v, want %q", tt.rules, err, tt.want)
+		}
+	}
+}
+
+// What: Rules apply to all scripts, or to the scripts matching a pattern by path or file name
+func TestCompiledFlagRule_AppliesTo(t *testing.T) {
+	all := compiledFlagRule{}
+	prod := compiledFlagRule{Scripts: []string{"*_prod.sh", "release/*.bat"}}
+	tests := []struct {
+		rule   compiledFlagRule
+		script string
+		want   bool
+	}{
+		{all, "deploy.sh", true},
+		{prod, "scripts/deploy_prod.sh", true},
+		{prod, `release\deploy.bat`, true},
+		{prod, "deploy_test.sh", false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.appliesTo(tt.script); got != tt.want {
+			t.Errorf("appliesTo(%q) with %v = %v, want %v", tt.script, tt.rule.Scripts, got, tt.want)
+		}
+	}
+}
+
+// What: Missing required and present forbidden flags are reported on the invocations of the utility
+func TestCheckFlagRules(t *testing.T) {
+	originalRules, originalResult, originalLong := testRun.flagRules, testRun.analysisResult, testRun.gnuLongOptions
+	defer func() {
+		testRun.flagRules, testRun.analysisResult, testRun.gnuLongOptions = originalRules, originalResult, originalLong
+	}()
+	testRun.gnuLongOptions = false
+	testRun.analysisResult = Result{}
+	testRun.flagRules = testRun.compileFlagRules([]FlagRule{
+		{Utility: "install_xml_stylesheet_datasets", Required: []string{"-replace"}},
+		{Utility: "plmxml_import", Forbidden: []string{"overwrite"}, Scripts: []string{"*_prod.sh"}},
+	})
+
+	testRun.checkFlagRules("deploy_prod.sh", `$TC_BIN/install_xml_stylesheet_datasets -input="a.txt" -replace`, 1)
+	testRun.checkFlagRules("deploy_prod.sh", `$TC_BIN/install_xml_stylesheet_datasets -input="a.txt" -replaced`, 2)
+	testRun.checkFlagRules("deploy_prod.sh", `$TC_BIN/plmxml_import -xml_file="a.xml" -overwrite`, 3)
+	testRun.checkFlagRules("deploy_test.sh", `$TC_BIN/plmxml_import -xml_file="a.xml" -overwrite`, 4)
+	testRun.checkFlagRules("deploy_prod.sh", `echo install_xml_stylesheet_datasets`, 5)
+
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %v", testRun.analysisResult.Findings)
+	}
+	required, forbidden := testRun.analysisResult.Findings[0], testRun.analysisResult.Findings[1]
+	if required.Rule != RuleRequiredFlag || required.Line != 2 || required.Suggestion != "add -replace" {
+		t.Errorf("Unexpected required flag finding %+v", required)
+	}
+	if forbidden.Rule != RuleForbiddenFlag || forbidden.Line != 3 || forbidden.Column != 41 {
+		t.Errorf("Unexpected forbidden flag finding %+v", forbidden)
+	}
+}
@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The repositories are read with go-git, so the build agents need no git executable.
+
+// gitRepository is the git repository of a directory, with the top level of its work
+// tree and the directory relative to it, with forward slashes ("" for the top level)
+type gitRepository struct {
+	*git.Repository
+	top    string
+	prefix string
+}
+
+// openGitRepository opens the git repository of dir, found in dir or a parent
+func openGitRepository(dir string) (gitRepository, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return gitRepository{}, fmt.Errorf("'%s' is not in a git work tree: %w", dir, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return gitRepository{}, fmt.Errorf("'%s' is not in a git work tree: %w", dir, err)
+	}
+	top, err := realDirectory(worktree.Filesystem.Root())
+	if err != nil {
+		return gitRepository{}, err
+	}
+	real, err := realDirectory(dir)
+	if err != nil {
+		return gitRepository{}, err
+	}
+	rel, err := filepath.Rel(top, real)
+	if err != nil {
+		return gitRepository{}, err
+	}
+	prefix := filepath.ToSlash(rel)
+	if prefix == "." {
+		prefix = ""
+	}
+	return gitRepository{Repository: repo, top: top, prefix: prefix}, nil
+}
+
+// relative returns a path of the repository relative to the directory it was opened
+// for; ok is false for the paths outside it
+func (r gitRepository) relative(p string) (string, bool) {
+	if r.prefix == "" {
+		return p, true
+	}
+	if !strings.HasPrefix(p, r.prefix+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(p, r.prefix+"/"), true
+}
+
+// headCommit returns the commit checked out
+func (r gitRepository) headCommit() (*object.Commit, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD failed: %w", err)
+	}
+	return r.CommitObject(head.Hash())
+}
+
+// HeadCommit returns the commit checked out in dir, empty when dir is not in a git
+// work tree
+func HeadCommit(dir string) string {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	return head.Hash().String()
+}
+
+// loadBranchChanges lists the paths under root deleted or renamed since the current
+// branch forked from baseRef, including changes not committed yet
+func (r *run) loadBranchChanges(root, baseRef string) (map[string]string, error) {
+	repo, err := openGitRepository(root)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	baseHash, err := repo.ResolveRevision(plumbing.Revision(baseRef))
+	if err != nil {
+		return nil, fmt.Errorf("base branch '%s' not found: %w", baseRef, err)
+	}
+	base, err := repo.CommitObject(*baseHash)
+	if err != nil {
+		return nil, fmt.Errorf("base branch '%s' not found: %w", baseRef, err)
+	}
+	forks, err := head.MergeBase(base)
+	if err != nil {
+		return nil, fmt.Errorf("merge base with '%s' failed: %w", baseRef, err)
+	}
+	if len(forks) == 0 {
+		return nil, fmt.Errorf("the current branch has no commit in common with '%s'", baseRef)
+	}
+	forkTree, err := forks[0].Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	committed, err := object.DiffTreeWithOptions(context.Background(), forkTree, headTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("diff with '%s' failed: %w", baseRef, err)
+	}
+	uncommitted, err := repo.uncommittedChanges(headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	// The changes of the work tree apply over the committed ones, by the path at the fork
+	changes := make(map[string]string)
+	record := func(old, target string) {
+		rel, ok := repo.relative(old)
+		if !ok {
+			return
+		}
+		if target != "" {
+			if target, ok = repo.relative(target); !ok {
+				target = ""
+			}
+		}
+		changes[rel] = target
+	}
+	for _, change := range committed {
+		if change.From.Name == "" || change.From.Name == change.To.Name {
+			continue // added or modified
+		}
+		target := change.To.Name
+		if next, ok := uncommitted[target]; ok && target != "" {
+			target = next // renamed again or deleted in the work tree
+		}
+		record(change.From.Name, target)
+		delete(uncommitted, change.To.Name)
+	}
+	for current, target := range uncommitted {
+		if _, err := forkTree.File(current); err != nil {
+			continue // added in the branch
+		}
+		record(current, target)
+	}
+	r.log.Info("{n} path(s) deleted or renamed since '{b}'", "n", len(changes), "b", baseRef)
+	return changes, nil
+}
+
+// uncommittedChanges returns the files of the HEAD tree deleted or renamed in the work
+// tree or the index, to their rename target ("" when deleted). Renames are the deleted
+// files whose content is the one of a file added or not tracked yet.
+func (r gitRepository) uncommittedChanges(head *object.Tree) (map[string]string, error) {
+	worktree, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("reading the work tree status failed: %w", err)
+	}
+	var deleted, added []string
+	for p, s := range status {
+		switch {
+		case s.Worktree == git.Deleted || s.Staging == git.Deleted:
+			deleted = append(deleted, p)
+		case s.Worktree == git.Untracked || s.Staging == git.Added:
+			added = append(added, p)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+	sort.Strings(deleted)
+	sort.Strings(added)
+
+	byContent := make(map[plumbing.Hash]string, len(added))
+	for _, p := range added {
+		data, err := os.ReadFile(filepath.Join(r.top, filepath.FromSlash(p)))
+		if err != nil {
+			continue
+		}
+		if hash := plumbing.ComputeHash(plumbing.BlobObject, data); byContent[hash] == "" {
+			byContent[hash] = p
+		}
+	}
+	changes := make(map[string]string, len(deleted))
+	for _, p := range deleted {
+		file, err := head.File(p)
+		if err != nil {
+			continue // added to the index and deleted since, not in HEAD
+		}
+		changes[p] = byContent[file.Hash]
+		delete(byContent, file.Hash)
+	}
+	return changes, nil
+}
+
+// branchChange returns the change in the current branch of a path referenced by the
+// script being processed: the rename target, or "" when deleted
+func (r *run) branchChange(p string) (string, bool) {
+	target, ok := r.branchChanges[path.Clean(slashPath(p, r.currentScriptTargetOS))]
+	return target, ok
+}
+
+// reportBranchChange reports a missing path deleted or renamed in the current branch,
+// suggesting the rename target
+func (r *run) reportBranchChange(scriptFile string, reference PathFlag, target string) {
+	lineNumber, p := reference.Line, reference.Path
+	f := Finding{Rule: RuleDeletedInBranch, Script: scriptFile, Line: lineNumber, Column: reference.Column, Path: p}
+	if target == "" {
+		f.Suggestion = "restore the file or remove the reference"
+		r.reportFinding(f, "'{s}' line '{ln}' is invalid: '{fp}' was deleted since '{b}'",
+			"s", scriptFile, "ln", lineNumber, "fp", p, "b", r.gitBaseRef)
+		return
+	}
+	renamed := parsePath(target, "linux").render(r.currentScriptTargetOS)
+	f.Suggestion = logger.Format("reference '{t}'", "t", renamed)
+	r.reportFinding(f, "'{s}' line '{ln}' is invalid: '{fp}' was renamed to '{t}' since '{b}'",
+		"s", scriptFile, "ln", lineNumber, "fp", p, "t", renamed, "b", r.gitBaseRef)
+}
+
+// staleReference is a script line referencing a file by its name before the rename
+type staleReference struct {
+	OldPath string
+	Line    int
+	Column  int
+}
+
+// staleRenames returns the unreferenced files under root renamed in the current branch
+// whose old name is referenced by validLines, by file
+func (r *run) staleRenames(root string, unreferenced []string, validLines []PathFlag) map[string]staleReference {
+	if len(r.branchChanges) == 0 || len(unreferenced) == 0 {
+		return nil
+	}
+	renamedFrom := make(map[string]string, len(r.branchChanges))
+	for old, target := range r.branchChanges {
+		if target != "" {
+			renamedFrom[target] = old
+		}
+	}
+
+	// Paths are compared relative to root, in the notation of the runtime OS
+	prefix := filepath.ToSlash(r.rootPrefix(root))
+	references := make(map[string]PathFlag, len(validLines))
+	for _, reference := range validLines {
+		if current, ok := references[reference.Path]; !ok || reference.Line < current.Line {
+			references[reference.Path] = reference
+		}
+	}
+
+	stale := make(map[string]staleReference)
+	for _, item := range unreferenced {
+		old, ok := renamedFrom[path.Join(prefix, filepath.ToSlash(item))]
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			if !strings.HasPrefix(old, prefix+"/") {
+				continue
+			}
+			old = strings.TrimPrefix(old, prefix+"/")
+		}
+		oldPath := filepath.FromSlash(old)
+		if reference, referenced := references[oldPath]; referenced {
+			r.log.Debug("'{item}' was renamed from '{old}', which is referenced on line '{ln}'", "item", item, "old", oldPath, "ln", reference.Line)
+			stale[item] = staleReference{OldPath: oldPath, Line: reference.Line, Column: reference.Column}
+		}
+	}
+	return stale
+}
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// gitTestRepo creates a git repository with the files committed on branch main and
+// checks out branch feature
+func gitTestRepo(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitCommit(t, root, "test", time.Now())
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true, Keep: true}); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// gitCommit commits all changes of the work tree of dir with an author and date
+func gitCommit(t *testing.T, dir, author string, when time.Time) {
+	t.Helper()
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: author, Email: "test@example.com", When: when}
+	if _, err := worktree.Commit("change", &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// What: Files deleted and renamed in the branch, committed or not, are listed against the base branch
+func TestLoadBranchChanges(t *testing.T) {
+	root := gitTestRepo(t, "a.xml", "b.xml", "c.xml", "d.xml")
+	if err := os.Rename(filepath.Join(root, "a.xml"), filepath.Join(root, "a-renamed.xml")); err != nil {
+		t.Fatal(err)
+	}
+	gitCommit(t, root, "test", time.Now())
+	if err := os.Remove(filepath.Join(root, "b.xml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(root, "d.xml"), filepath.Join(root, "d-moved.xml")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := testRun.loadBranchChanges(root, "main")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	want := map[string]string{"a.xml": "a-renamed.xml", "b.xml": "", "d.xml": "d-moved.xml"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected %v, got %v", want, changes)
+	}
+}
+
+// What: An unknown base branch is an error
+func TestLoadBranchChanges_UnknownRef(t *testing.T) {
+	root := gitTestRepo(t, "a.xml")
+	if _, err := testRun.loadBranchChanges(root, "does-not-exist"); err == nil {
+		t.Error("Expected error for unknown base branch, got nil")
+	}
+}
+
+// What: The checked out commit is returned, nothing outside a work tree
+func TestHeadCommit(t *testing.T) {
+	root := gitTestRepo(t, "a.xml")
+	if commit := HeadCommit(root); len(commit) != 40 {
+		t.Errorf("Expected a commit hash, got %q", commit)
+	}
+	if commit := HeadCommit(t.TempDir()); commit != "" {
+		t.Errorf("Expected no commit outside a work tree, got %q", commit)
+	}
+}
+
+// What: Missing paths deleted or renamed in the branch are reported as such, with the rename target as suggestion
+func TestCheckFilePathsInScript_DeletedInBranch(t *testing.T) {
+	originalRoot, originalScript, originalResult, originalOS := testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult, testRun.currentScriptTargetOS
+	defer func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult, testRun.currentScriptTargetOS = originalRoot, originalScript, originalResult, originalOS
+		testRun.branchChanges, testRun.gitBaseRef = nil, ""
+	}()
+	testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS = t.TempDir(), "deploy.bat", "windows"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+	testRun.branchChanges = map[string]string{"100-Config/old.xml": "", "100-Config/a.xml": "100-Config/renamed.xml"}
+	testRun.gitBaseRef = "main"
+
+	testRun.checkFilePathsInScript("deploy.bat", pathFlags(map[int]string{1: `100-Config\old.xml`, 2: `100-Config\a.xml`, 3: `100-Config\other.xml`}))
+
+	rulesByLine := make(map[int]Finding)
+	for _, f := range testRun.analysisResult.Findings {
+		rulesByLine[f.Line] = f
+	}
+	if rulesByLine[1].Rule != RuleDeletedInBranch || rulesByLine[2].Rule != RuleDeletedInBranch || rulesByLine[3].Rule != RuleMissingFile {
+		t.Errorf("Unexpected findings: %+v", testRun.analysisResult.Findings)
+	}
+	if rulesByLine[2].Suggestion != `reference '100-Config\renamed.xml'` {
+		t.Errorf("Expected rename target as suggestion, got %q", rulesByLine[2].Suggestion)
+	}
+	if missing := testRun.analysisResult.File["deploy.bat"].Missing; len(missing) != 3 {
+		t.Errorf("Expected all 3 paths recorded as missing, got %v", missing)
+	}
+}
+
+// What: Renamed files are paired with their old name when it is referenced, relative to the compared root
+func TestStaleRenames(t *testing.T) {
+	originalRoot := testRun.sourceCodeRoot
+	defer func() { testRun.sourceCodeRoot, testRun.branchChanges = originalRoot, nil }()
+	testRun.sourceCodeRoot = filepath.FromSlash("/repo")
+	testRun.branchChanges = map[string]string{
+		"200-Stylesheets/old.xml": "200-Stylesheets/new.xml",
+		"100-Config/a.xml":        "100-Config/b.xml",
+		"100-Config/gone.xml":     "",
+	}
+
+	stale := testRun.staleRenames(filepath.FromSlash("/repo/200-Stylesheets"), []string{"new.xml", "unrelated.xml"}, pathFlags(map[int]string{4: "old.xml"}))
+	want := map[string]staleReference{"new.xml": {OldPath: "old.xml", Line: 4}}
+	if !reflect.DeepEqual(stale, want) {
+		t.Errorf("Expected %v, got %v", want, stale)
+	}
+
+	if stale := testRun.staleRenames(testRun.sourceCodeRoot, []string{filepath.Join("100-Config", "b.xml")}, pathFlags(map[int]string{1: "other.xml"})); len(stale) != 0 {
+		t.Errorf("Expected no stale reference when the old name is not referenced, got %v", stale)
+	}
+}
@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Ignore, ownership and .tcxvalidateignore patterns are kept as written, in the
+// gitignore syntax: / separates directories and \ escapes the next character, so
+// \*, \?, \[, \!, \#, "\ " and \\ match the character itself. The paths matched
+// against them are written with forward slashes instead, by host path
+// (filepath.ToSlash) or by script notation (slashPath).
+
+// compileGitignorePattern returns the regexp of a gitignore pattern without its "!"
+// negation, matching a path relative to the pattern base when the path or one of
+// its parent directories matches. nil for a blank or comment pattern, or one ending
+// with a lone \, which matches nothing like in git.
+func compileGitignorePattern(pattern string) *regexp.Regexp {
+	pattern = trimUnescapedSpaces(pattern)
+	if pattern == "" || pattern[0] == '#' {
+		return nil
+	}
+	dirOnly := strings.HasSuffix(pattern, "/") && !escapedAt(pattern, len(pattern)-1)
+	if dirOnly {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	// a separator at the beginning or in the middle anchors the pattern to its base
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '*':
+			stars := i
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			segmentStart := stars == 0 || pattern[stars-1] == '/'
+			segmentEnd := i+1 == len(pattern) || pattern[i+1] == '/'
+			switch {
+			case i == stars || !segmentStart || !segmentEnd:
+				// other consecutive asterisks are a regular *
+				b.WriteString("[^/]*")
+			case i+1 == len(pattern):
+				// trailing /** matches everything inside, a lone ** everything
+				b.WriteString(".+")
+			default:
+				// leading **/ and /**/ match zero or more directories
+				b.WriteString("(?:.*/)?")
+				i++
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, end := bracketClass(pattern, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	compiled, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil
+	}
+	return compiled
+}
+
+// bracketClass returns the regexp class of the [...] bracket expression opening at
+// start of pattern and the index of its closing ], -1 when it is not closed. A ] right
+// after [ or [! is part of the set; [!...] and [^...] negate it, never matching /.
+func bracketClass(pattern string, start int) (string, int) {
+	var b strings.Builder
+	b.WriteString("[")
+	i := start + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		b.WriteString("^/")
+		i++
+	}
+	for first := i; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == ']' && i > first {
+			b.WriteString("]")
+			return b.String(), i
+		}
+		if c == '\\' && i+1 < len(pattern) {
+			i++
+			c = pattern[i]
+		}
+		if strings.IndexByte(`\[]^`, c) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return "", -1
+}
+
+// trimUnescapedSpaces removes the trailing spaces of a pattern not escaped with \
+func trimUnescapedSpaces(pattern string) string {
+	for strings.HasSuffix(pattern, " ") && !escapedAt(pattern, len(pattern)-1) {
+		pattern = pattern[:len(pattern)-1]
+	}
+	return pattern
+}
+
+// escapedAt reports whether the character at index i of a pattern is escaped, i.e.
+// preceded by an odd number of \
+func escapedAt(pattern string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && pattern[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// ValidateGitignorePattern checks that a pattern is written in the gitignore syntax. A
+// \ before a letter, digit, '.', '-' or '_', or ending the pattern, is taken for a
+// Windows directory separator: in gitignore it is an escape, so the pattern would
+// silently match other paths than intended.
+func ValidateGitignorePattern(pattern string) error {
+	body := trimUnescapedSpaces(pattern)
+	separator := false
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			continue
+		}
+		if i+1 == len(body) || isPathNameChar(body[i+1]) {
+			separator = true
+		}
+		i++
+	}
+	if separator {
+		return fmt.Errorf("ignore pattern '%s' uses '\\' as directory separator; patterns use the gitignore syntax, separate directories with '/' (e.g. '%s')", pattern, slashSeparators(body))
+	}
+	for i := 0; i < len(body); i++ {
+		if body[i] == '\\' {
+			i++
+			continue
+		}
+		if body[i] == '[' {
+			if _, end := bracketClass(body, i); end < 0 {
+				return fmt.Errorf("ignore pattern '%s' has an unterminated '[', escape a literal one as '\\['", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateIgnorePatterns checks the gitignore syntax of all configured ignore patterns
+func ValidateIgnorePatterns(patterns ignorePatterns) error {
+	all := append(append(append([]string{}, patterns.Global...), patterns.StyleSheetsFolder...), patterns.WorkflowsFolder...)
+	for _, scoped := range patterns.Scoped {
+		all = append(all, scoped.Pattern)
+	}
+	for _, pattern := range all {
+		if err := ValidateGitignorePattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPathNameChar reports whether c is common in file names but has no meaning in the
+// gitignore syntax, so escaping it is pointless
+func isPathNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
+
+// slashSeparators returns a pattern with the \ taken for separators by
+// ValidateGitignorePattern written as /, for the suggestion of its error
+func slashSeparators(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '\\' {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		if i+1 == len(pattern) || isPathNameChar(pattern[i+1]) {
+			b.WriteByte('/')
+			continue
+		}
+		b.WriteString(pattern[i : i+2])
+		i++
+	}
+	return b.String()
+}
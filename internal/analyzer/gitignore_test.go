@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// What: Patterns keep the gitignore syntax: ** spans directories, \ escapes the next character
+func TestCompileGitignorePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"basename at any depth", "*.log", "a/b/debug.log", true},
+		{"star stays in its directory", "docs/*.xml", "docs/sub/a.xml", false},
+		{"question mark is one character", "what?.txt", "whatx.txt", true},
+		{"question mark not a separator", "a?b", "a/b", false},
+		{"directory and below", "logs/", "a/logs/run.txt", true},
+		{"directory only", "logs/", "logs", false},
+		{"anchored by a middle separator", "docs/a.xml", "x/docs/a.xml", false},
+		{"leading double star", "**/tmp", "tmp/x.xml", true},
+		{"leading double star at depth", "**/tmp/*.xml", "a/b/tmp/x.xml", true},
+		{"middle double star", "a/**/x.xml", "a/b/c/x.xml", true},
+		{"middle double star zero dirs", "a/**/x.xml", "a/x.xml", true},
+		{"trailing double star", "build/**", "build/out/x.bin", true},
+		{"trailing double star not the directory", "build/**", "build", false},
+		{"double star within a name is a star", "a**b.xml", "a-x-b.xml", true},
+		{"double star within a name stays in its directory", "a**b.xml", "a/x/b.xml", false},
+		{"escaped star", `\*star.txt`, "*star.txt", true},
+		{"escaped star is literal", `\*star.txt`, "xstar.txt", false},
+		{"escaped question mark", `what\?.txt`, "what?.txt", true},
+		{"escaped question mark is literal", `what\?.txt`, "whatx.txt", false},
+		{"escaped bracket", `\[abc].txt`, "[abc].txt", true},
+		{"escaped bracket is literal", `\[abc].txt`, "a.txt", false},
+		{"escaped exclamation", `\!bang.txt`, "!bang.txt", true},
+		{"escaped hash", `\#notes.txt`, "#notes.txt", true},
+		{"escaped trailing space", `trailing\ `, "trailing ", true},
+		{"unescaped trailing space", "trailing ", "trailing", true},
+		{"escaped backslash", `dir\\name`, `dir\name`, true},
+		{"backslash escapes a letter", `logs\keep.xml`, "logs/keep.xml", false},
+		{"regexp characters are literal", "a+(b)$.txt", "a+(b)$.txt", true},
+		{"character class", "[abc].txt", "b.txt", true},
+		{"negated character class", "[!abc].txt", "d.txt", true},
+		{"negated character class excludes", "[!abc].txt", "a.txt", false},
+		{"character class range", "v[0-9].xml", "v7.xml", true},
+		{"closing bracket first in class", "[]x].txt", "].txt", true},
+		{"unterminated bracket is literal", "a[b", "a[b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled := compileGitignorePattern(tt.pattern)
+			if compiled == nil {
+				t.Fatalf("Expected %q to compile", tt.pattern)
+			}
+			if got := compiled.MatchString(tt.path); got != tt.want {
+				t.Errorf("%q matches %q = %v, want %v (%s)", tt.pattern, tt.path, got, tt.want, compiled)
+			}
+		})
+	}
+}
+
+// What: Blank and comment patterns, and patterns ending with a lone \, match nothing
+func TestCompileGitignorePattern_MatchesNothing(t *testing.T) {
+	for _, pattern := range []string{"", "   ", "# comment", `logs\`} {
+		if compiled := compileGitignorePattern(pattern); compiled != nil {
+			t.Errorf("Expected %q to match nothing, got %s", pattern, compiled)
+		}
+	}
+}
+
+// What: Negations are decided by the ignore set, escaped ones are literal
+func TestShouldIgnore_NegationAndEscapes(t *testing.T) {
+	patterns := []string{"**/tmp/**", `!**/tmp/\!keep.xml`, `\!literal.txt`}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"a/tmp/x.xml", true},
+		{"a/tmp/!keep.xml", false},
+		{"!literal.txt", true},
+		{"literal.txt", false},
+	}
+	for _, tt := range tests {
+		if got := testRun.shouldIgnore(tt.path, patterns); got != tt.want {
+			t.Errorf("shouldIgnore(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// What: Backslash separators are refused with the pattern written with slashes, escapes are accepted
+func TestValidateGitignorePattern(t *testing.T) {
+	for _, pattern := range []string{"*.log", "!logs/keep.xml", "**/tmp/**", `\!bang.txt`, `\#notes.txt`, `file\*name`, `what\?.txt`, `\[abc].txt`, `trailing\ `, `dir\\name`, "[!a-z].xml"} {
+		if err := ValidateGitignorePattern(pattern); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", pattern, err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		message string
+	}{
+		{`logs\`, "(e.g. 'logs/')"},
+		{`100-Config\sub\a.xml`, "(e.g. '100-Config/sub/a.xml')"},
+		{`!logs\keep.xml`, "(e.g. '!logs/keep.xml')"},
+		{`dir\.hidden`, "(e.g. 'dir/.hidden')"},
+		{"a[b", "unterminated '['"},
+	}
+	for _, tt := range tests {
+		err := ValidateGitignorePattern(tt.pattern)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected %q error for %q, got %v", tt.message, tt.pattern, err)
+		}
+	}
+}
+
+// What: All configured ignore patterns are validated, scoped ones included
+func TestValidateIgnorePatterns(t *testing.T) {
+	if err := ValidateIgnorePatterns(ignorePatterns{Global: []string{"*.log"}, WorkflowsFolder: []string{"drafts/"}}); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+	for _, patterns := range []ignorePatterns{
+		{Global: []string{`dist\`}},
+		{StyleSheetsFolder: []string{`temp\`}},
+		{WorkflowsFolder: []string{`drafts\old`}},
+		{Scoped: []ScopedIgnorePattern{{Pattern: `external\`, Checks: []string{CheckMissing}}}},
+	} {
+		if err := ValidateIgnorePatterns(patterns); err == nil {
+			t.Errorf("Expected a separator error for %+v", patterns)
+		}
+	}
+}
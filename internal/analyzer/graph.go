@@ -0,0 +1,222 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// The dependency graph links the scripts to the utilities they call, the utilities to
+// the files of their path flags and the list files (list imports and stylesheet import
+// definitions) to the files of their rows, recursively. Files left unreferenced by the
+// scripts have no edge, so orphan clusters of the repository stand out.
+
+// Kinds of the nodes of the dependency graph
+const (
+	NodeScript  = "script"
+	NodeUtility = "utility"
+	NodeFile    = "file"
+	NodeList    = "list" // a file whose rows reference further files
+)
+
+// DependencyGraph is the graph of the scripts and the files they deploy
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a script, a utility called by a script, or a file. Files are shared by
+// the scripts of a repository, relative to its source code root with forward slashes.
+type GraphNode struct {
+	ID           string `json:"id"`
+	Kind         string `json:"kind"`
+	Label        string `json:"label"`
+	Repository   string `json:"repository,omitempty"`   // set when several repositories are validated
+	Missing      bool   `json:"missing,omitempty"`      // referenced but not found
+	Unreferenced bool   `json:"unreferenced,omitempty"` // in the repository but referenced by no script
+}
+
+// GraphEdge links a node to a node it depends on
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// graphBuilder collects the nodes and edges once each
+type graphBuilder struct {
+	graph DependencyGraph
+	nodes map[string]int // node ID -> index in the graph
+	edges map[GraphEdge]bool
+}
+
+// node adds a node unless present and returns its ID; a file node is turned into a
+// list node when its rows are read
+func (b *graphBuilder) node(repository, kind, id, label string) string {
+	if repository != "" {
+		id = repository + ":" + id
+	}
+	if i, ok := b.nodes[id]; ok {
+		if kind == NodeList {
+			b.graph.Nodes[i].Kind = NodeList
+		}
+		return id
+	}
+	b.nodes[id] = len(b.graph.Nodes)
+	b.graph.Nodes = append(b.graph.Nodes, GraphNode{ID: id, Kind: kind, Label: label, Repository: repository})
+	return id
+}
+
+// file adds the node of a file relative to the source code root
+func (b *graphBuilder) file(repository, kind, file string) string {
+	return b.node(repository, kind, "file:"+file, file)
+}
+
+func (b *graphBuilder) edge(from, to string) {
+	edge := GraphEdge{From: from, To: to}
+	if from == to || b.edges[edge] {
+		return
+	}
+	b.edges[edge] = true
+	b.graph.Edges = append(b.graph.Edges, edge)
+}
+
+// BuildDependencyGraph returns the dependency graph of the scripts of the results, the
+// nodes in the order they are reached from the scripts and the edges in line order
+func BuildDependencyGraph(results []RepositoryResult) DependencyGraph {
+	b := graphBuilder{graph: DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}, nodes: make(map[string]int), edges: make(map[GraphEdge]bool)}
+	for _, r := range results {
+		repository := ""
+		if len(results) > 1 {
+			repository = r.Name
+		}
+		var unreferenced []string
+		for _, script := range r.Result.Parity.Scripts {
+			lines, ok := r.Result.File[script.Filename]
+			if !ok {
+				continue
+			}
+			addScriptGraph(&b, repository, script, lines)
+			unreferenced = append(unreferenced, lines.Unreferenced...)
+		}
+
+		// a file unreferenced by one script may be referenced by another
+		sort.Strings(unreferenced)
+		for _, item := range unreferenced {
+			file := filepath.ToSlash(item)
+			id := "file:" + file
+			if repository != "" {
+				id = repository + ":" + id
+			}
+			if _, ok := b.nodes[id]; ok {
+				continue
+			}
+			b.file(repository, NodeFile, file)
+			b.graph.Nodes[b.nodes[id]].Unreferenced = true
+		}
+	}
+	return b.graph
+}
+
+// addScriptGraph adds the nodes and edges of a script
+func addScriptGraph(b *graphBuilder, repository string, script ParityScript, lines Lines) {
+	scriptID := b.node(repository, NodeScript, "script:"+script.Filename, script.Filename)
+	missing := make(map[string]bool, len(lines.Missing))
+	for _, path := range lines.Missing {
+		missing[slashPath(path, script.TargetOS)] = true
+	}
+
+	// the node a line depends on: the utility it calls, the script itself otherwise
+	caller := func(lineNumber int) string {
+		utility, ok := lines.Utility[lineNumber]
+		if !ok {
+			return scriptID
+		}
+		utilityID := b.node(repository, NodeUtility, "utility:"+script.Filename+":"+utility, utility)
+		b.edge(scriptID, utilityID)
+		return utilityID
+	}
+	reference := func(from, kind, file string) string {
+		id := b.file(repository, kind, file)
+		if missing[file] {
+			b.graph.Nodes[b.nodes[id]].Missing = true
+		}
+		b.edge(from, id)
+		return id
+	}
+
+	lineNumbers := make([]int, 0, len(lines.Text))
+	for lineNumber := range lines.Text {
+		lineNumbers = append(lineNumbers, lineNumber)
+	}
+	sort.Ints(lineNumbers)
+	for _, lineNumber := range lineNumbers {
+		if _, ok := lines.Utility[lineNumber]; ok {
+			caller(lineNumber)
+		}
+		for _, flag := range lines.Flags[lineNumber] {
+			if flag.IsValid() {
+				reference(caller(lineNumber), NodeFile, slashPath(flag.Path, script.TargetOS))
+			}
+		}
+		if ref, ok := lines.LoopReference[lineNumber]; ok {
+			from := caller(lineNumber)
+			for _, match := range ref.Matches {
+				reference(from, NodeFile, match)
+			}
+		}
+		if call, ok := lines.ListImport[lineNumber]; ok {
+			list := slashPath(call.ListFile, script.TargetOS)
+			reference(caller(lineNumber), NodeList, list)
+			addListGraph(b, repository, list, lines.ListReferences, make(map[string]bool))
+		}
+		if definition, ok := lines.StyleSheetImport[lineNumber]; ok {
+			input := reference(caller(lineNumber), NodeList, slashPath(definition.InputFile, script.TargetOS))
+			datasets := make([]int, 0, len(definition.Datasets))
+			for row := range definition.Datasets {
+				datasets = append(datasets, row)
+			}
+			sort.Ints(datasets)
+			for _, row := range datasets {
+				reference(input, NodeFile, filepath.ToSlash(definition.Datasets[row].XML))
+			}
+		}
+	}
+}
+
+// addListGraph adds the references of a list file and of the nested lists it
+// references; visited stops at cycles, which are findings of the list import check
+func addListGraph(b *graphBuilder, repository, list string, references map[string][]string, visited map[string]bool) {
+	if visited[list] {
+		return
+	}
+	visited[list] = true
+	listID := b.file(repository, NodeList, list)
+	for _, file := range references[list] {
+		kind := NodeFile
+		if _, nested := references[file]; nested {
+			kind = NodeList
+		}
+		b.edge(listID, b.file(repository, kind, file))
+		if kind == NodeList {
+			addListGraph(b, repository, file, references, visited)
+		}
+	}
+}
+
+// recordListReferences keeps the files referenced by the rows of a list file read for
+// the script being processed, for the dependency graph
+func (r *run) recordListReferences(listFile string, references []string) {
+	lines, ok := r.analysisResult.File[r.currentScript]
+	if !ok {
+		return
+	}
+	if lines.ListReferences == nil {
+		lines.ListReferences = make(map[string][]string)
+		r.analysisResult.File[r.currentScript] = lines
+	}
+	list, _ := r.relativeToRoot(listFile, r.currentScriptTargetOS)
+	files := make([]string, 0, len(references))
+	for _, reference := range references {
+		files = append(files, filepath.ToSlash(reference))
+	}
+	lines.ListReferences[list.slash()] = files
+}
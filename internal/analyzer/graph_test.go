@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// graphIndex returns the nodes of the graph by ID and its edges as "from -> to"
+func graphIndex(graph DependencyGraph) (map[string]GraphNode, map[string]bool) {
+	nodes := make(map[string]GraphNode)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	edges := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		edges[edge.From+" -> "+edge.To] = true
+	}
+	return nodes, edges
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	// What: Scripts link to their utilities, utilities to files and lists to their rows; unreferenced files are orphans
+	windows, linux := newLines(), newLines()
+	windows.Text[1] = `plmxml_import -xml_file="100-Config\a.xml"`
+	windows.Utility[1] = "plmxml_import"
+	windows.Flags[1] = []PathFlag{{Line: 1, Path: `100-Config\a.xml`}}
+	linux.Text[1] = `plmxml_import -xml_file="100-Config/a.xml"`
+	linux.Utility[1] = "plmxml_import"
+	linux.Flags[1] = []PathFlag{{Line: 1, Path: "100-Config/a.xml"}}
+	linux.Text[2] = `module_import -input="500-Modules/master.lst"`
+	linux.Utility[2] = "module_import"
+	linux.Flags[2] = []PathFlag{{Line: 2, Path: "500-Modules/master.lst"}}
+	linux.ListImport[2] = ListImportCall{ListFile: "500-Modules/master.lst"}
+	linux.ListReferences["500-Modules/master.lst"] = []string{"500-Modules/a/module.lst"}
+	linux.ListReferences["500-Modules/a/module.lst"] = []string{"500-Modules/a/part.xml", "500-Modules/master.lst"}
+	linux.Missing = []string{"100-Config/a.xml"}
+	linux.Unreferenced = []string{"300-Old/b.xml", "100-Config/a.xml"}
+	results := []RepositoryResult{{Result: Result{
+		File: map[string]Lines{"deploy.bat": windows, "deploy.sh": linux},
+		Parity: ParityMatrix{Scripts: []ParityScript{
+			{Filename: "deploy.bat", TargetOS: "windows"}, {Filename: "deploy.sh", TargetOS: "linux"},
+		}},
+	}}}
+
+	nodes, edges := graphIndex(BuildDependencyGraph(results))
+	for _, edge := range []string{
+		"script:deploy.bat -> utility:deploy.bat:plmxml_import",
+		"utility:deploy.bat:plmxml_import -> file:100-Config/a.xml",
+		"utility:deploy.sh:plmxml_import -> file:100-Config/a.xml",
+		"utility:deploy.sh:module_import -> file:500-Modules/master.lst",
+		"file:500-Modules/master.lst -> file:500-Modules/a/module.lst",
+		"file:500-Modules/a/module.lst -> file:500-Modules/a/part.xml",
+		"file:500-Modules/a/module.lst -> file:500-Modules/master.lst",
+	} {
+		if !edges[edge] {
+			t.Errorf("Expected edge %s, got %v", edge, edges)
+		}
+	}
+	if len(edges) != 9 {
+		t.Errorf("Expected 9 edges, got %v", edges)
+	}
+	if n := nodes["file:500-Modules/a/module.lst"]; n.Kind != NodeList || n.Label != "500-Modules/a/module.lst" {
+		t.Errorf("Expected the nested list as a list node, got %+v", n)
+	}
+	if n := nodes["file:100-Config/a.xml"]; !n.Missing || n.Unreferenced {
+		t.Errorf("Expected the shared file missing and referenced, got %+v", n)
+	}
+	if n := nodes["file:300-Old/b.xml"]; !n.Unreferenced || n.Kind != NodeFile {
+		t.Errorf("Expected the orphan file, got %+v", n)
+	}
+}
+
+func TestBuildDependencyGraph_Repositories(t *testing.T) {
+	// What: The nodes of several repositories are prefixed with the repository, so their files stay apart
+	lines := newLines()
+	lines.Text[1] = `plmxml_import -xml_file="100-Config/a.xml"`
+	lines.Utility[1] = "plmxml_import"
+	lines.Flags[1] = []PathFlag{{Line: 1, Path: "100-Config/a.xml"}}
+	result := Result{File: map[string]Lines{"deploy.sh": lines}, Parity: ParityMatrix{Scripts: []ParityScript{{Filename: "deploy.sh", TargetOS: "linux"}}}}
+
+	graph := BuildDependencyGraph([]RepositoryResult{{Name: "core", Result: result}, {Name: "plant", Result: result}})
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	want := []string{
+		"core:script:deploy.sh", "core:utility:deploy.sh:plmxml_import", "core:file:100-Config/a.xml",
+		"plant:script:deploy.sh", "plant:utility:deploy.sh:plmxml_import", "plant:file:100-Config/a.xml",
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+	if graph.Nodes[3].Repository != "plant" {
+		t.Errorf("Expected the repository of the node, got %+v", graph.Nodes[3])
+	}
+}
+
+func TestCheckListImports_RecordsListReferences(t *testing.T) {
+	// What: The rows of each list read, nested ones included, are kept for the graph
+	writeStylesheetFixture(t, "linux", map[string]string{
+		"500-Modules/master.lst":   "a/module.lst\n",
+		"500-Modules/a/module.lst": "part.xml\n",
+		"500-Modules/a/part.xml":   "<a/>",
+	})
+	decl := ListImport{Utility: "module_import", Column: 1, Nested: "*.lst"}
+
+	testRun.checkListImports("deploy.bat", map[int]ListImportCall{3: {Declaration: decl, ListFile: "500-Modules/master.lst"}})
+
+	want := map[string][]string{
+		"500-Modules/master.lst":   {"500-Modules/a/module.lst"},
+		"500-Modules/a/module.lst": {"500-Modules/a/part.xml"},
+	}
+	if got := testRun.analysisResult.File["deploy.bat"].ListReferences; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
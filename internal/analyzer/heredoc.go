@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Heredoc start: <<EOF, <<-EOF, <<'EOF', <<"EOF", <<\EOF (not the <<< herestring)
+var heredocStartRegex = regexp.MustCompile(`(?:^|[^<])<<(-?)\s*(?:'([^']+)'|"([^"]+)"|\\?([A-Za-z_][A-Za-z0-9_]*))`)
+
+// heredocTracker detects the bodies of shell heredocs while a script is read line by line
+type heredocTracker struct {
+	delimiter string
+	stripTabs bool // <<- allows the terminator to be indented with tabs
+}
+
+// update processes the next line and reports whether it belongs to a heredoc body,
+// the terminating delimiter line included
+func (h *heredocTracker) update(line string) bool {
+	if h.delimiter != "" {
+		terminator := line
+		if h.stripTabs {
+			terminator = strings.TrimLeft(terminator, "\t")
+		}
+		if strings.TrimRight(terminator, " \r") == h.delimiter {
+			h.delimiter = ""
+		}
+		return true
+	}
+	if m := heredocStartRegex.FindStringSubmatch(line); m != nil && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+		h.delimiter = m[2] + m[3] + m[4]
+		h.stripTabs = m[1] == "-"
+	}
+	return false
+}
+
+// scanHeredocLine reports the path flags found in a heredoc body line as informational findings
+func (r *run) scanHeredocLine(file string, line string, lineNumber int) {
+	for _, flagName := range r.pathParameters {
+		matches := r.parameterValuePatterns[flagName].FindStringSubmatch(line)
+		if len(matches) < 2 {
+			continue
+		}
+		r.reportFinding(Finding{Rule: RuleHeredocPath, Script: file, Line: lineNumber, Path: matches[1]},
+			"'{f}' line '{ln}': heredoc body contains path '{p}' in '-{s}', it is not validated", "f", file, "ln", lineNumber, "p", matches[1], "s", flagName)
+	}
+}
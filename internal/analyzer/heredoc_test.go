@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func heredocLinesOf(lines []string) []int {
+	var h heredocTracker
+	var result []int
+	for i, l := range lines {
+		if h.update(l) {
+			result = append(result, i+1)
+		}
+	}
+	return result
+}
+
+// What: Heredoc bodies and terminators are detected for all delimiter forms
+func TestHeredocTracker(t *testing.T) {
+	lines := []string{
+		"cat > input.txt <<EOF",
+		"plmxml_import -i=\"old.xml\"",
+		"EOF",
+		"sqlplus -s user <<-'SQL'",
+		"\tselect 1 from dual;",
+		"\tSQL",
+		"grep x <<< \"$VALUE\"",
+		"# cat <<NOTE",
+		"tee out <<\"END\"",
+		"END",
+		"plmxml_import -i=\"new.xml\"",
+	}
+
+	expected := []int{2, 3, 5, 6, 10}
+	if result := heredocLinesOf(lines); !reflect.DeepEqual(result, expected) {
+		t.Errorf("heredoc lines = %v, want %v", result, expected)
+	}
+}
+
+func setupHeredocTest(t *testing.T, content string) {
+	t.Helper()
+	setupSyntaxTest()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.checkFileSyntax("deploy.sh", root, "linux")
+}
+
+// What: Heredoc body lines are skipped instead of parsed as commands
+func TestCheckFileSyntax_HeredocSkipped(t *testing.T) {
+	setupHeredocTest(t, "cat > in.txt <<EOF\nplmxml_import -i=\"old.xml\"\nplmxml_import -i=old.xml\nEOF\nplmxml_import -i=\"new.xml\"\n")
+
+	lines := testRun.analysisResult.File["deploy.sh"]
+	if !reflect.DeepEqual(validPaths(lines), map[int]string{5: "new.xml"}) {
+		t.Errorf("Valid = %v, want only line 5", validPaths(lines))
+	}
+	if len(lines.InvalidLines()) != 0 {
+		t.Errorf("Expected no invalid lines, got %v", lines.InvalidLines())
+	}
+	for _, ln := range []int{2, 3, 4} {
+		if _, ok := lines.Skipped[ln]; !ok {
+			t.Errorf("Expected line %d to be skipped", ln)
+		}
+	}
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", testRun.analysisResult.Findings)
+	}
+}
+
+// What: With scan_heredocs, paths in heredoc bodies are reported as info findings
+func TestCheckFileSyntax_HeredocScanned(t *testing.T) {
+	testRun.scanHeredocs = true
+	defer func() { testRun.scanHeredocs = false }()
+	setupHeredocTest(t, "cat > in.txt <<EOF\nplmxml_import -i=\"old.xml\"\nEOF\n")
+
+	if len(testRun.analysisResult.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %v", testRun.analysisResult.Findings)
+	}
+	f := testRun.analysisResult.Findings[0]
+	if f.Rule != RuleHeredocPath || f.Severity != SeverityInfo || f.Line != 2 || f.Path != "old.xml" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"path/filepath"
+)
+
+// checkIgnoredReferences reports the paths referenced by a script that exist but are
+// excluded from the directory content check, by the global ignore patterns or by a
+// .tcxvalidateignore file. The coverage check never sees such a file, so either the
+// pattern is wrong or the file should not be deployed.
+func (r *run) checkIgnoredReferences(scriptFile string, lines []PathFlag, patterns []string) {
+	r.log.Debug("checking for referenced paths excluded by ignore patterns in '{s}'", "s", scriptFile)
+
+	// ignore files of the directories of the referenced paths, loaded on first use
+	nested := make(nestedIgnores)
+	loaded := make(map[string]bool)
+
+	for _, f := range lines {
+		i := f.Line
+		if r.isArtifactReference(f.Path) || isAbsoluteReference(f.Path) || r.pathEscapesRoot(f.Path) || !r.fileExists(f.Path) {
+			continue
+		}
+		relPath := slashPath(f.Path, r.currentScriptTargetOS)
+		// matched without counting, the pattern hits are those of the traversal
+		ignored, excludedBy := r.compiledIgnoreSet(patterns).match(relPath)
+		if !ignored && r.remoteTree == nil {
+			local := filepath.FromSlash(relPath)
+			for dir := filepath.Dir(local); !loaded[dir]; dir = filepath.Dir(dir) {
+				loaded[dir] = true
+				r.loadIgnoreFile(nested, filepath.Join(r.sourceCodeRoot, dir), dir)
+			}
+			excludedBy = nested.excludedBy(local)
+			ignored = excludedBy != ""
+		}
+		if !ignored {
+			continue
+		}
+		r.reportFinding(Finding{Rule: RuleIgnoredReference, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path,
+			Suggestion: "narrow '" + excludedBy + "', or stop referencing the file if it should not be deployed"},
+			"'{s}' line '{ln}': '{fp}' exists but is excluded by '{p}', the directory content check does not see it",
+			"s", scriptFile, "ln", i, "fp", f.Path, "p", excludedBy)
+	}
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests for checkIgnoredReferences()
+
+func setupIgnoredReferencesTest(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	for _, file := range []string{"100-Config/a.xml", "100-Config/old.log", "300-Workflows/drafts/wf.xml", "300-Workflows/wf.xml"} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	writeIgnoreFile(t, filepath.Join(tmpDir, "300-Workflows"), "drafts/\n")
+
+	originalRoot, originalScript, originalOS, originalResult := testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult
+	t.Cleanup(func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult = originalRoot, originalScript, originalOS, originalResult
+	})
+	testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS = tmpDir, "deploy.bat", "windows"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+}
+
+func TestCheckIgnoredReferences(t *testing.T) {
+	// What: Existing references excluded by a global pattern or an ignore file are reported in line order
+	setupIgnoredReferencesTest(t)
+
+	testRun.checkIgnoredReferences("deploy.bat", pathFlags(map[int]string{
+		3: `100-Config\a.xml`,
+		5: `300-Workflows\drafts\wf.xml`,
+		7: `100-Config\old.log`,
+		9: `100-Config\missing.log`,
+	}), []string{"*.log"})
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Rule != RuleIgnoredReference || findings[0].Line != 5 || findings[0].Severity != SeverityWarning {
+		t.Errorf("Expected a warning for the path excluded by the ignore file, got %+v", findings[0])
+	}
+	if want := "narrow '" + filepath.Join("300-Workflows", ignoreFileName) + "', or stop referencing the file if it should not be deployed"; findings[0].Suggestion != want {
+		t.Errorf("Expected suggestion %q, got %q", want, findings[0].Suggestion)
+	}
+	if findings[1].Line != 7 || findings[1].Suggestion != "narrow '*.log', or stop referencing the file if it should not be deployed" {
+		t.Errorf("Expected the path excluded by '*.log', got %+v", findings[1])
+	}
+}
+
+func TestCheckIgnoredReferences_NegationAndHits(t *testing.T) {
+	// What: A path re-included by a negation is not reported, and the check does not count pattern hits
+	setupIgnoredReferencesTest(t)
+	testRun.ignorePatternHits = make(map[string]int)
+
+	testRun.checkIgnoredReferences("deploy.bat", pathFlags(map[int]string{1: `100-Config\old.log`}), []string{"*.log", "!100-Config/old.log"})
+
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", testRun.analysisResult.Findings)
+	}
+	if len(testRun.ignorePatternHits) != 0 {
+		t.Errorf("Expected no pattern hits, got %v", testRun.ignorePatternHits)
+	}
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the name of the per-directory ignore files. Their patterns use the
+// gitignore syntax and are relative to the directory of the file.
+const ignoreFileName = ".tcxvalidateignore"
+
+// nestedIgnores holds the ignore files found during a traversal, keyed by the path of
+// their directory relative to the traversal root ("." for the root itself)
+type nestedIgnores map[string]*ignoreSet
+
+// loadIgnoreFile reads the ignore file of a directory into n, if there is one
+func (r *run) loadIgnoreFile(n nestedIgnores, dir, relDir string) {
+	data, err := os.ReadFile(filepath.Join(dir, ignoreFileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.reportFinding(Finding{Rule: RuleTraversalError, Path: filepath.Join(relDir, ignoreFileName), hostPath: true},
+				"Error reading ignore file '{p}': {e}", "p", filepath.Join(relDir, ignoreFileName), "e", err.Error())
+		}
+		return
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		// kept as written, trailing spaces escaped with \ are part of the pattern
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	r.log.Debug("Loaded '{n}' ignore patterns from '{p}': '{patterns}'", "n", len(patterns), "p", filepath.Join(relDir, ignoreFileName), "patterns", patterns)
+	n[relDir] = r.compiledIgnoreSet(patterns)
+}
+
+// nestedIgnored reports whether a path relative to the traversal root is excluded by
+// the ignore file of one of its parent directories
+func (r *run) nestedIgnored(n nestedIgnores, relPath string) bool {
+	if file := n.excludedBy(relPath); file != "" {
+		r.log.Debug("Excluding path '{path}' as it matches '{f}'", "path", relPath, "f", file)
+		return true
+	}
+	return false
+}
+
+// excludedBy returns the ignore file excluding a path relative to the traversal root,
+// "" when none of the ignore files of its parent directories does
+func (n nestedIgnores) excludedBy(relPath string) string {
+	if len(n) == 0 {
+		return ""
+	}
+	for dir := filepath.Dir(relPath); ; dir = filepath.Dir(dir) {
+		if ignore, ok := n[dir]; ok {
+			rel := relPath
+			if dir != "." {
+				rel, _ = filepath.Rel(dir, relPath)
+			}
+			if ignored, _ := ignore.match(rel); ignored {
+				return filepath.Join(dir, ignoreFileName)
+			}
+		}
+		if dir == "." || dir == string(filepath.Separator) {
+			return ""
+		}
+	}
+}
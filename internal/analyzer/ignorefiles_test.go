@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeIgnoreFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ignoreFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+}
+
+// What: Ignore files apply to their directory and below, relative to it, and are not collected themselves
+func TestTraverseAndCollect_NestedIgnoreFiles(t *testing.T) {
+	files := []string{
+		"100-Config/a.xml",
+		"100-Config/scratch/tmp.xml",
+		"100-Config/notes.txt",
+		"200-Stylesheets/notes.txt",
+		"200-Stylesheets/s.xml",
+		"scratch/keep.xml",
+	}
+	tmpDir := setupTestDir(t, files)
+	defer cleanup(t, tmpDir)
+	writeIgnoreFile(t, filepath.Join(tmpDir, "100-Config"), "# owner scratch\nscratch/\n*.txt\n")
+
+	collected, err := testRun.traverseAndCollect(tmpDir, []string{})
+	assertNoError(t, err)
+	sort.Strings(collected)
+
+	expected := []string{
+		filepath.Join("100-Config", "a.xml"),
+		filepath.Join("200-Stylesheets", "notes.txt"),
+		filepath.Join("200-Stylesheets", "s.xml"),
+		filepath.Join("scratch", "keep.xml"),
+	}
+	if !reflect.DeepEqual(collected, expected) {
+		t.Errorf("Expected %v, got %v", expected, collected)
+	}
+}
+
+// What: An ignore file in the root merges with the configured patterns, negations apply within the file
+func TestTraverseAndCollect_RootIgnoreFile(t *testing.T) {
+	files := []string{"a.log", "important.log", "b.tmp", "c.xml"}
+	tmpDir := setupTestDir(t, files)
+	defer cleanup(t, tmpDir)
+	writeIgnoreFile(t, tmpDir, "*.log\n!important.log\n")
+
+	collected, err := testRun.traverseAndCollect(tmpDir, []string{"*.tmp"})
+	assertNoError(t, err)
+	sort.Strings(collected)
+
+	expected := []string{"c.xml", "important.log"}
+	if !reflect.DeepEqual(collected, expected) {
+		t.Errorf("Expected %v, got %v", expected, collected)
+	}
+}
+
+// What: Paths are matched against the ignore files of all parent directories
+func TestNestedIgnores_Matches(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeIgnoreFile(t, tmpDir, "*.bak\n")
+	sub := filepath.Join(tmpDir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeIgnoreFile(t, sub, "draft.xml\n")
+
+	n := make(nestedIgnores)
+	testRun.loadIgnoreFile(n, tmpDir, ".")
+	testRun.loadIgnoreFile(n, filepath.Join(tmpDir, "a"), "a")
+	testRun.loadIgnoreFile(n, sub, filepath.Join("a", "b"))
+
+	tests := map[string]bool{
+		filepath.Join("a", "b", "draft.xml"):      true,
+		filepath.Join("a", "b", "c", "draft.xml"): true,
+		filepath.Join("a", "draft.xml"):           false,
+		filepath.Join("a", "b", "x.bak"):          true,
+		"x.xml":                                   false,
+	}
+	for path, want := range tests {
+		if got := testRun.nestedIgnored(n, path); got != want {
+			t.Errorf("matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// What: Lines of ignore files are kept as written, escaped trailing spaces and characters included
+func TestNestedIgnores_Escapes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeIgnoreFile(t, tmpDir, "notes\\ \r\n\\#draft.xml\n  # indented is a pattern\n\\*.xml\n")
+
+	n := make(nestedIgnores)
+	testRun.loadIgnoreFile(n, tmpDir, ".")
+
+	tests := map[string]bool{
+		"notes ":                    true,
+		"notes":                     false,
+		"#draft.xml":                true,
+		"  # indented is a pattern": true,
+		"*.xml":                     true,
+		"a.xml":                     false,
+	}
+	for path, want := range tests {
+		if got := testRun.nestedIgnored(n, path); got != want {
+			t.Errorf("matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+// Checks ignore patterns can be scoped to. Plain patterns apply to the
+// unreferenced check, i.e. they exclude files from the directory content check.
+const (
+	CheckUnreferenced = "unreferenced" // repository files not referenced by a script
+	CheckMissing      = "missing"      // referenced paths not found on the file system
+	CheckParity       = "parity"       // paths referenced only by Windows or only by Linux scripts
+	CheckPermissions  = "permissions"  // executable bit and world-writable files
+	CheckRendering    = "rendering"    // structure of the stylesheet XMLs
+)
+
+var ignoreChecks = []string{CheckUnreferenced, CheckMissing, CheckParity, CheckPermissions, CheckRendering}
+
+func isIgnoreCheck(check string) bool {
+	for _, c := range ignoreChecks {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedPatterns returns the configured ignore patterns scoped to a check
+func (r *run) scopedPatterns(check string) []string {
+	var patterns []string
+	for _, scoped := range r.ignores.Scoped {
+		for _, c := range scoped.Checks {
+			if c == check {
+				patterns = append(patterns, scoped.Pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// ignoredFor reports whether a path referenced by the script being processed is
+// excluded from a check by a scoped ignore pattern. Paths are compared with forward
+// slashes, the patterns in gitignore form.
+func (r *run) ignoredFor(check, path string) bool {
+	patterns := r.scopedPatterns(check)
+	if len(patterns) == 0 {
+		return false
+	}
+	if r.shouldIgnore(slashPath(path, r.currentScriptTargetOS), patterns) {
+		r.log.Debug("'{p}' is excluded from the {c} check", "p", path, "c", check)
+		return true
+	}
+	return false
+}
+
+// scopedOnly returns the scoped patterns not applying to the unreferenced check,
+// which are not part of the global patterns
+func scopedOnly(patterns ignorePatterns) []string {
+	var result []string
+	for _, scoped := range patterns.Scoped {
+		unreferenced := false
+		for _, c := range scoped.Checks {
+			unreferenced = unreferenced || c == CheckUnreferenced
+		}
+		if !unreferenced {
+			result = append(result, scoped.Pattern)
+		}
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// What: Global ignore entries may be plain patterns or mappings scoped to checks
+func TestIgnorePatterns_UnmarshalYAML(t *testing.T) {
+	content := `global:
+  - 'logs/'
+  - pattern: '*.sample.xml'
+    checks: [unreferenced]
+  - pattern: 'external/**'
+    checks: [missing, parity]
+stylesheets_folder:
+  - '*.txt'
+`
+	var p ignorePatterns
+	if err := yaml.Unmarshal([]byte(content), &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if expected := []string{"logs/", "*.sample.xml"}; !reflect.DeepEqual(p.Global, expected) {
+		t.Errorf("Global = %v, want %v", p.Global, expected)
+	}
+	if expected := []string{"*.txt"}; !reflect.DeepEqual(p.StyleSheetsFolder, expected) {
+		t.Errorf("StyleSheetsFolder = %v, want %v", p.StyleSheetsFolder, expected)
+	}
+	expected := []ScopedIgnorePattern{
+		{Pattern: "*.sample.xml", Checks: []string{CheckUnreferenced}},
+		{Pattern: "external/**", Checks: []string{CheckMissing, CheckParity}},
+	}
+	if !reflect.DeepEqual(p.Scoped, expected) {
+		t.Errorf("Scoped = %v, want %v", p.Scoped, expected)
+	}
+	if only := scopedOnly(p); !reflect.DeepEqual(only, []string{"external/**"}) {
+		t.Errorf("scopedOnly() = %v, want [external/**]", only)
+	}
+}
+
+// What: Scoped entries without pattern or checks, or with unknown checks, are rejected
+func TestIgnorePatterns_UnmarshalYAML_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown check":   "global:\n  - pattern: 'a'\n    checks: [coverage]\n",
+		"missing checks":  "global:\n  - pattern: 'a'\n",
+		"missing pattern": "global:\n  - checks: [missing]\n",
+	}
+	for name, content := range tests {
+		var p ignorePatterns
+		err := yaml.Unmarshal([]byte(content), &p)
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: expected error on line 2, got %v", name, err)
+		}
+	}
+}
+
+// What: Only patterns scoped to the check exclude a path, regardless of the separator
+func TestIgnoredFor(t *testing.T) {
+	original, originalOS := testRun.ignores, testRun.currentScriptTargetOS
+	defer func() { testRun.ignores, testRun.currentScriptTargetOS = original, originalOS }()
+	testRun.currentScriptTargetOS = "windows"
+	testRun.ignores = ignorePatterns{Scoped: []ScopedIgnorePattern{
+		{Pattern: "external/", Checks: []string{CheckMissing}},
+		{Pattern: "*.sh", Checks: []string{CheckPermissions, CheckParity}},
+	}}
+
+	tests := []struct {
+		check, path string
+		want        bool
+	}{
+		{CheckMissing, `external\tool.xml`, true},
+		{CheckMissing, "external/tool.xml", true},
+		{CheckParity, "external/tool.xml", false},
+		{CheckPermissions, "bin/run.sh", true},
+		{CheckParity, "bin/run.sh", true},
+		{CheckMissing, "bin/run.sh", false},
+	}
+	for _, tt := range tests {
+		if got := testRun.ignoredFor(tt.check, tt.path); got != tt.want {
+			t.Errorf("ignoredFor(%s, %q) = %v, want %v", tt.check, tt.path, got, tt.want)
+		}
+	}
+}
+
+// What: Paths excluded from the missing check are not looked up on the file system
+func TestCheckFilePathsInScript_ScopedIgnore(t *testing.T) {
+	originalIgnores, originalRoot, originalScript, originalResult := testRun.ignores, testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult
+	defer func() {
+		testRun.ignores, testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult = originalIgnores, originalRoot, originalScript, originalResult
+	}()
+	testRun.sourceCodeRoot, testRun.currentScript = t.TempDir(), "deploy.sh"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.ignores = ignorePatterns{Scoped: []ScopedIgnorePattern{{Pattern: "generated/", Checks: []string{CheckMissing}}}}
+
+	testRun.checkFilePathsInScript("deploy.sh", pathFlags(map[int]string{1: "generated/out.xml", 2: "missing.xml"}))
+
+	if missing := testRun.analysisResult.File["deploy.sh"].Missing; !reflect.DeepEqual(missing, []string{"missing.xml"}) {
+		t.Errorf("Expected only missing.xml to be missing, got %v", missing)
+	}
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single compiled ignore pattern
+type ignoreRule struct {
+	pattern string         // as configured
+	negate  bool           // "!pattern" re-includes paths excluded by earlier patterns
+	body    string         // pattern without the negation, in gitignore form
+	ignore  *regexp.Regexp // compileGitignorePattern
+}
+
+// ignoreSet is an ordered list of ignore patterns with full gitignore semantics:
+// the last pattern matching a path decides, so a negation re-includes paths
+// excluded by the patterns before it. `**` matches any number of directories.
+//
+// Unlike git, files below an excluded directory can be re-included by a later
+// negation, e.g. `logs/` followed by `!logs/keep.xml`.
+type ignoreSet struct {
+	rules []ignoreRule
+}
+
+// compiledIgnoreSet returns the compiled set of an ordered pattern list
+func (r *run) compiledIgnoreSet(patterns []string) *ignoreSet {
+	key := strings.Join(patterns, "\x00")
+	if set, ok := r.ignoreSets[key]; ok {
+		return set
+	}
+	set := &ignoreSet{}
+	for _, p := range patterns {
+		rule := ignoreRule{pattern: p, body: p}
+		if strings.HasPrefix(p, "!") {
+			rule.negate, rule.body = true, p[1:]
+		}
+		if rule.ignore = compileGitignorePattern(rule.body); rule.ignore == nil {
+			continue
+		}
+		set.rules = append(set.rules, rule)
+	}
+	r.ignoreSets[key] = set
+	return set
+}
+
+// match reports whether path is ignored and the pattern that decided it, ""
+// when no pattern matches the path
+func (s *ignoreSet) match(path string) (bool, string) {
+	path = filepath.ToSlash(path)
+	ignored, decidedBy := false, ""
+	for _, rule := range s.rules {
+		if rule.ignore.MatchString(path) {
+			ignored, decidedBy = !rule.negate, rule.pattern
+		}
+	}
+	return ignored, decidedBy
+}
+
+// mayReinclude reports whether a negation could re-include paths below an ignored
+// directory, in which case the directory must still be walked
+func (s *ignoreSet) mayReinclude(dir string) bool {
+	dir = strings.Trim(filepath.ToSlash(dir), "/") + "/"
+	for _, rule := range s.rules {
+		if !rule.negate {
+			continue
+		}
+		body := strings.TrimPrefix(rule.body, "/")
+		// patterns without a directory part, or starting with **, apply at any depth
+		if !strings.Contains(strings.TrimSuffix(body, "/"), "/") || strings.HasPrefix(body, "**") {
+			return true
+		}
+		if strings.HasPrefix(body, dir) || strings.HasPrefix(dir, body) {
+			return true
+		}
+	}
+	return false
+}
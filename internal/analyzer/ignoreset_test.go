@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// What: Ordered gitignore semantics - last match wins, negations, ** globs, anchors and directories
+func TestShouldIgnore_GitignoreSemantics(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"extension", []string{"*.log"}, "a/b/debug.log", true},
+		{"negation re-includes", []string{"*.log", "!important.log"}, "important.log", false},
+		{"negation at depth", []string{"*.log", "!important.log"}, "a/important.log", false},
+		{"negation before pattern has no effect", []string{"!important.log", "*.log"}, "important.log", true},
+		{"re-excluded after negation", []string{"*.log", "!important.log", "important.log"}, "important.log", true},
+		{"lone negation ignores nothing", []string{"!important.log"}, "important.log", false},
+		{"directory", []string{"logs/"}, "logs/run.txt", true},
+		{"file below directory re-included", []string{"logs/", "!logs/keep.xml"}, "logs/keep.xml", false},
+		{"other file below directory", []string{"logs/", "!logs/keep.xml"}, "logs/run.txt", true},
+		{"double star prefix", []string{"**/tmp"}, "a/b/tmp/x.xml", true},
+		{"double star middle", []string{"a/**/x.xml"}, "a/b/c/x.xml", true},
+		{"double star middle zero dirs", []string{"a/**/x.xml"}, "a/x.xml", true},
+		{"double star suffix", []string{"build/**"}, "build/out/x.bin", true},
+		{"double star negation", []string{"build/**", "!build/**/*.xml"}, "build/out/x.xml", false},
+		{"anchored", []string{"/root.txt"}, "sub/root.txt", false},
+		{"anchored root", []string{"/root.txt"}, "root.txt", true},
+		{"escaped exclamation", []string{`\!bang.txt`}, "!bang.txt", true},
+		{"windows separators are escapes", []string{`logs\keep.xml`}, "logs/keep.xml", false},
+		{"no match", []string{"*.log"}, "main.go", false},
+		{"empty list", nil, "main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testRun.shouldIgnore(tt.path, tt.patterns); got != tt.want {
+				t.Errorf("shouldIgnore(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+// What: The pattern deciding a match is counted, negations included, so they are not reported as unused
+func TestShouldIgnore_CountsDecidingPattern(t *testing.T) {
+	original := testRun.ignorePatternHits
+	defer func() { testRun.ignorePatternHits = original }()
+	testRun.ignorePatternHits = map[string]int{}
+
+	patterns := []string{"*.log", "!important.log", "*.tmp"}
+	testRun.shouldIgnore("debug.log", patterns)
+	testRun.shouldIgnore("important.log", patterns)
+	testRun.shouldIgnore("main.go", patterns)
+
+	expected := map[string]int{"*.log": 1, "!important.log": 1}
+	if !reflect.DeepEqual(testRun.ignorePatternHits, expected) {
+		t.Errorf("Expected hits %v, got %v", expected, testRun.ignorePatternHits)
+	}
+}
+
+// What: Ignored directories are walked only when a negation may re-include paths below them
+func TestIgnoreSet_MayReinclude(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		dir      string
+		want     bool
+	}{
+		{[]string{"logs/"}, "logs", false},
+		{[]string{"logs/", "!logs/keep.xml"}, "logs", true},
+		{[]string{"logs/", "!other/keep.xml"}, "logs", false},
+		{[]string{"logs/", "!keep.xml"}, "logs", true},
+		{[]string{"a/", "!a/b/keep.xml"}, filepath.Join("a", "b"), true},
+		{[]string{"build/**", "!**/*.xml"}, "build", true},
+	}
+	for _, tt := range tests {
+		if got := testRun.compiledIgnoreSet(tt.patterns).mayReinclude(tt.dir); got != tt.want {
+			t.Errorf("mayReinclude(%v, %q) = %v, want %v", tt.patterns, tt.dir, got, tt.want)
+		}
+	}
+}
+
+// What: Files below an ignored directory are collected when re-included by a negation
+func TestTraverseAndCollect_ReincludeBelowIgnoredDirectory(t *testing.T) {
+	files := []string{"logs/run.txt", "logs/keep.xml", "logs/deep/keep.xml", "logs/deep/x.txt", "main.xml"}
+	tmpDir := setupTestDir(t, files)
+	defer cleanup(t, tmpDir)
+
+	collected, err := testRun.traverseAndCollect(tmpDir, []string{"logs/", "!keep.xml"})
+	assertNoError(t, err)
+	sort.Strings(collected)
+
+	expected := []string{filepath.Join("logs", "deep", "keep.xml"), filepath.Join("logs", "keep.xml"), "main.xml"}
+	if !reflect.DeepEqual(collected, expected) {
+		t.Errorf("Expected %v, got %v", expected, collected)
+	}
+}
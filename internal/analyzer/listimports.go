@@ -0,0 +1,413 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// list_imports:
+//   - utility: preferences_manager
+//     list_flag: file
+//     column: 1
+//     comment: '#'
+//   - utility: ics_import
+//     list_flag: mapping
+//     base_flag: dir
+//     column: 3
+//     delimiter: ';'
+//     header_rows: 1
+//     complete: true
+
+// listImport is a list import declaration with the patterns matching the invocations
+// of its utility and extracting its list file and base folder flags
+type listImport struct {
+	ListImport
+	utilityRegex *regexp.Regexp
+	listRegex    *regexp.Regexp
+	baseRegex    *regexp.Regexp // nil without base flag
+}
+
+// listFormat is the layout of the rows of a list file
+type listFormat struct {
+	delimiter  string
+	column     int // 1-based column of the reference
+	comment    string
+	headerRows int
+	rowRule    string // rule of the rows without the column
+}
+
+// listRow is a row of a list file with the file it references
+type listRow struct {
+	Columns   []string
+	Reference FilePathInfo // relative to the folder for the host, and below it in the script notation
+	Local     string       // the reference relative to the source code root, for the host
+}
+
+// ValidateListImports checks that each list import names a utility, has a valid column
+// and header rows, and declares at most one of the base flag and path
+func ValidateListImports(imports []ListImport) error {
+	for i, imp := range imports {
+		if strings.TrimSpace(imp.Utility) == "" {
+			return fmt.Errorf("list import at index %d is missing 'utility'", i)
+		}
+		if imp.Column < 0 {
+			return fmt.Errorf("list import for '%s' has an invalid column %d (must be 1 or more)", imp.Utility, imp.Column)
+		}
+		if imp.HeaderRows < 0 {
+			return fmt.Errorf("list import for '%s' has negative 'header_rows'", imp.Utility)
+		}
+		if imp.BaseFlag != "" && imp.BasePath != "" {
+			return fmt.Errorf("list import for '%s' sets both 'base_flag' and 'base_path'", imp.Utility)
+		}
+		for _, pattern := range imp.IgnorePatterns {
+			if err := ValidateGitignorePattern(pattern); err != nil {
+				return fmt.Errorf("list import for '%s': %w", imp.Utility, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compileListImports compiles the patterns of the list imports. Flags are written with
+// or without the dash, like in the flag rules; the list flag defaults to input.
+func (r *run) compileListImports(imports []ListImport) []listImport {
+	valueRegex := func(flag string) *regexp.Regexp {
+		return regexp.MustCompile(r.flagPrefix(flag) + `=(?:"([^"]+)"|(\S+))`)
+	}
+	compiled := make([]listImport, 0, len(imports))
+	for _, imp := range imports {
+		imp.Utility = strings.TrimSpace(imp.Utility)
+		if imp.ListFlag = flagRuleName(imp.ListFlag); imp.ListFlag == "" {
+			imp.ListFlag = "input"
+		}
+		imp.BaseFlag = flagRuleName(imp.BaseFlag)
+		c := listImport{
+			ListImport:   imp,
+			utilityRegex: regexp.MustCompile(`(?i)(?:^|[\s/\\])` + regexp.QuoteMeta(imp.Utility) + `(?:\.\w+)?(?:\s|$)`),
+			listRegex:    valueRegex(imp.ListFlag),
+		}
+		if imp.BaseFlag != "" {
+			c.baseRegex = valueRegex(imp.BaseFlag)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// format returns the layout of the list files of the import
+func (imp listImport) format() listFormat {
+	f := listFormat{delimiter: imp.Delimiter, column: imp.Column, comment: imp.Comment, headerRows: imp.HeaderRows, rowRule: RuleListFileRow}
+	switch f.delimiter {
+	case "":
+		f.delimiter = ","
+	case "tab":
+		f.delimiter = "\t"
+	}
+	if f.column == 0 {
+		f.column = 1
+	}
+	return f
+}
+
+// flagValue returns the value of the flag matched by re in the line
+func flagValue(re *regexp.Regexp, line string) string {
+	match := re.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	if match[1] != "" {
+		return match[1]
+	}
+	return match[2]
+}
+
+// listImportLine returns the call of a list import utility made by the line
+func (r *run) listImportLine(line string) (ListImportCall, bool) {
+	for _, imp := range r.listImports {
+		if !imp.utilityRegex.MatchString(line) {
+			continue
+		}
+		listFile := flagValue(imp.listRegex, line)
+		if listFile == "" {
+			r.log.Debug("'{l}' calls '{u}' without -{f}", "l", line, "u", imp.Utility, "f", imp.ListFlag)
+			return ListImportCall{}, false
+		}
+		call := ListImportCall{Declaration: imp.ListImport, ListFile: r.resolveWorkingDir(listFile), Folder: imp.BasePath}
+		if imp.baseRegex != nil {
+			call.Folder = r.resolveWorkingDir(flagValue(imp.baseRegex, line))
+		}
+		return call, true
+	}
+	return ListImportCall{}, false
+}
+
+// checkListImports validates the files referenced by the list files of the list
+// import calls of the script
+func (r *run) checkListImports(scriptFile string, calls map[int]ListImportCall) {
+	lineNumbers := make([]int, 0, len(calls))
+	for ln := range calls {
+		lineNumbers = append(lineNumbers, ln)
+	}
+	sort.Ints(lineNumbers)
+
+	for _, lineNumber := range lineNumbers {
+		call := calls[lineNumber]
+		r.log.Debug("list file '{f}' of '{u}' on line '{ln}'", "f", call.ListFile, "u", call.Declaration.Utility, "ln", lineNumber)
+
+		// References are relative to the folder of the list file unless declared
+		folderPath := call.Folder
+		if folderPath == "" && call.Declaration.BaseFlag == "" {
+			folderPath = r.parentPath(call.ListFile)
+		}
+		folder, external, ok := r.resolveListFolder(scriptFile, lineNumber, folderPath, "list import folder")
+		if !ok {
+			continue
+		}
+		imp := listImport{ListImport: call.Declaration}
+		var err error
+		if external {
+			_, err = r.readListFile(call.ListFile, folder, imp.format())
+		} else {
+			var references []string
+			if _, references, err = r.checkListFile(call.ListFile, folder, imp, nil); err == nil && imp.Complete {
+				err = r.compareListFolder(call.ListFile, references, folder, imp.IgnorePatterns, false)
+			}
+		}
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleListFile, Script: scriptFile, Line: lineNumber, Path: call.ListFile},
+				"Error processing list file '{f}' of '{u}': {err}", "f", r.localPath(call.ListFile), "u", call.Declaration.Utility, "err", err)
+		}
+	}
+}
+
+// parentPath returns the folder of a path referenced by the script, in its notation
+func (r *run) parentPath(p string) string {
+	parent := parsePath(p, r.currentScriptTargetOS)
+	if len(parent.segments) > 0 {
+		parent.segments = parent.segments[:len(parent.segments)-1]
+	}
+	return parent.render(r.currentScriptTargetOS)
+}
+
+// listCoverage counts the references of a list file, with those of its nested lists
+type listCoverage struct {
+	References int
+	Missing    int
+}
+
+// checkListFile reads a list file, checks its references exist and reads the
+// referenced list files matching the 'nested' pattern of the import recursively. chain
+// holds the list files being read, a list referencing one of them is a TCX036 cycle.
+// Nested lists resolve their references relative to their own folder, unless the import
+// declares the folder. Returns the coverage of the list with its nested lists, and all
+// references relative to the source code root for the host.
+func (r *run) checkListFile(listFile string, folder scriptPath, imp listImport, chain []string) (listCoverage, []string, error) {
+	rows, err := r.readListFile(listFile, folder, imp.format())
+	if err != nil {
+		return listCoverage{}, nil, err
+	}
+	coverage := listCoverage{References: len(rows), Missing: r.checkListRows(listFile, rows)}
+	total := coverage
+	references := make([]string, 0, len(rows))
+	for _, row := range rows {
+		references = append(references, row.Local)
+	}
+	r.recordListReferences(listFile, references)
+	if imp.Nested == "" {
+		return total, references, nil
+	}
+
+	key := r.listKey(listFile)
+	chain = append(chain, key)
+	lineNumbers := make([]int, 0, len(rows))
+	for ln := range rows {
+		lineNumbers = append(lineNumbers, ln)
+	}
+	sort.Ints(lineNumbers)
+	for _, ln := range lineNumbers {
+		nested := rows[ln].Reference.AbsolutePath
+		if matched, _ := path.Match(imp.Nested, path.Base(slashPath(nested, r.currentScriptTargetOS))); !matched || !r.fileExists(nested) {
+			continue
+		}
+		if cycle := listCycle(chain, r.listKey(nested)); cycle != "" {
+			r.reportFinding(Finding{Rule: RuleListFileCycle, Script: listFile, Line: ln, Path: nested},
+				"Line '{ln}' is invalid: list '{fp}' is already being read ({c})", "ln", ln, "fp", nested, "c", cycle)
+			continue
+		}
+		nestedFolder := folder
+		if imp.BaseFlag == "" && imp.BasePath == "" {
+			nestedFolder, _ = r.relativeToRoot(r.parentPath(nested), r.currentScriptTargetOS)
+		}
+		sub, subReferences, err := r.checkListFile(nested, nestedFolder, imp, chain)
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleListFile, Script: listFile, Line: ln, Path: nested},
+				"Error processing list file '{f}': {err}", "f", r.localPath(nested), "err", err)
+			continue
+		}
+		total.References += sub.References
+		total.Missing += sub.Missing
+		references = append(references, subReferences...)
+	}
+	r.log.Info("List '{f}' (level {l}): {n} references, {m} missing; with its nested lists {tn} references, {tm} missing",
+		"f", r.localPath(listFile), "l", len(chain), "n", coverage.References, "m", coverage.Missing, "tn", total.References, "tm", total.Missing)
+	return total, references, nil
+}
+
+// listKey identifies a list file by its path relative to the source code root
+func (r *run) listKey(listFile string) string {
+	p, _ := r.relativeToRoot(listFile, r.currentScriptTargetOS)
+	if r.currentScriptTargetOS == "windows" {
+		return strings.ToLower(p.slash())
+	}
+	return p.slash()
+}
+
+// listCycle returns the chain of list files from key back to key, empty when key is
+// not being read
+func listCycle(chain []string, key string) string {
+	for i, k := range chain {
+		if k == key {
+			return strings.Join(append(append([]string{}, chain[i:]...), key), " -> ")
+		}
+	}
+	return ""
+}
+
+// resolveListFolder resolves the folder the references of a list file are relative to,
+// in the notation of the script target OS: relative folders are below the source code
+// root, an absolute folder must lie within it, or in 'allowed_external_paths' (external,
+// its files are not checked). A folder outside the root is a TCX011 finding, ok is false.
+func (r *run) resolveListFolder(scriptFile string, lineNumber int, folderPath, kind string) (folder scriptPath, external bool, ok bool) {
+	folder, resolved := r.relativeToRoot(folderPath, r.currentScriptTargetOS)
+	if resolved {
+		return folder, false, true
+	}
+	if !r.isAllowedExternalPath(folderPath) {
+		r.reportFinding(Finding{Rule: RulePathEscapesRoot, Script: scriptFile, Line: lineNumber, Path: folderPath, Suggestion: "reference the folder relative to source_code_root or add it to allowed_external_paths"},
+			"'{s}' line '{ln}' is invalid: {k} '{fp}' resolves outside the source code root", "s", scriptFile, "ln", lineNumber, "k", kind, "fp", folderPath)
+		return scriptPath{}, false, false
+	}
+	r.log.Info("'{s}' line '{ln}': {k} '{fp}' is outside the source code root but allowed, its files are not checked", "s", scriptFile, "ln", lineNumber, "k", kind, "fp", folderPath)
+	return parsePath(folderPath, r.currentScriptTargetOS), true, true
+}
+
+// readListFile reads the rows of a list file and resolves the reference in the column
+// of each row below the folder, in the notation of the script target OS. Rows without
+// the column are reported with the row rule of the format, references that are absolute
+// or resolve outside the source code root as TCX011 findings.
+func (r *run) readListFile(listFile string, folder scriptPath, format listFormat) (map[int]listRow, error) {
+	osLocalizedListFile := r.localPath(listFile)
+	listFileFullPath := r.referenceFilePath(osLocalizedListFile)
+
+	file, err := os.Open(listFileFullPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", listFileFullPath, err)
+	}
+	defer file.Close() // Properly closes when function returns
+
+	rows := make(map[int]listRow)
+	readLinesCount := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		readLinesCount++
+		if readLinesCount <= format.headerRows || (format.comment != "" && strings.HasPrefix(strings.TrimSpace(line), format.comment)) {
+			continue
+		}
+
+		columns := strings.Split(line, format.delimiter)
+		if len(columns) < format.column {
+			r.reportFinding(Finding{Rule: format.rowRule, Script: listFile, Line: readLinesCount},
+				"Line '{l}' is of invalid format", "l", line)
+			continue
+		}
+
+		fileName := strings.TrimSpace(columns[format.column-1])
+		name := parsePath(fileName, r.currentScriptTargetOS)
+		reference, ok := folder.join(name).clean()
+		if name.absolute() || !ok {
+			r.reportFinding(Finding{Rule: RulePathEscapesRoot, Script: listFile, Line: readLinesCount, Path: fileName, Suggestion: "reference the file relative to the folder of the list"},
+				"Line '{ln}' is invalid: '{fp}' in folder '{d}' resolves outside the source code root", "ln", readLinesCount, "fp", fileName, "d", folder.render(r.currentScriptTargetOS))
+			continue
+		}
+		if cleaned, ok := name.clean(); ok {
+			name = cleaned
+		}
+		r.log.Debug("list file reference: '{p}'", "p", reference.render(r.currentScriptTargetOS))
+
+		rows[readLinesCount] = listRow{
+			Columns:   columns,
+			Reference: FilePathInfo{RelativePath: name.render(hostOS), AbsolutePath: reference.render(r.currentScriptTargetOS)},
+			Local:     reference.render(hostOS),
+		}
+	}
+
+	r.log.Info("Read '{n}' lines from '{f}'", "n", readLinesCount, "f", osLocalizedListFile)
+
+	// Check for any errors encountered during scanning
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %q: %w", listFileFullPath, err)
+	}
+	return rows, nil
+}
+
+// checkListRows validates that the files referenced by the rows exist and returns the
+// number of missing files
+func (r *run) checkListRows(listFile string, rows map[int]listRow) int {
+	references := FilePathMap{}
+	for ln, row := range rows {
+		references[ln] = row.Reference
+	}
+	absolutePaths, _ := references.Paths("absolute")
+	rowNumbers := make([]int, 0, len(absolutePaths))
+	for ln := range absolutePaths {
+		rowNumbers = append(rowNumbers, ln)
+	}
+	sort.Ints(rowNumbers)
+	paths := make([]PathFlag, 0, len(rowNumbers))
+	for _, ln := range rowNumbers {
+		paths = append(paths, PathFlag{Line: ln, Path: absolutePaths[ln]})
+	}
+
+	result := r.analysisResult.File[r.currentScript]
+	missing := len(result.Missing)
+	r.log.Debug("Checking if all '{n}' files referenced in '{f}' exist...", "n", len(rows), "f", r.localPath(listFile))
+	r.checkFilePathsInScript(listFile, paths)
+	return len(r.analysisResult.File[r.currentScript].Missing) - missing
+}
+
+// referencesInFolder returns the references relative to the source code root that lie
+// in the folder, relative to it, with their row as line
+func referencesInFolder(references []string, folder scriptPath) []PathFlag {
+	base := folder.render(hostOS)
+	relative := make([]PathFlag, 0, len(references))
+	for i, reference := range references {
+		if rel, err := filepath.Rel(base, reference); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			relative = append(relative, PathFlag{Line: i + 1, Path: rel})
+		}
+	}
+	return relative
+}
+
+// compareListFolder compares the files of the folder with the references of a complete
+// list, relative to the source code root for the host. Unless listed, the list file
+// counts as referenced in its folder: the script references it.
+func (r *run) compareListFolder(listFile string, references []string, folder scriptPath, ignorePatterns []string, listed bool) error {
+	if !listed {
+		if list, ok := r.relativeToRoot(listFile, r.currentScriptTargetOS); ok {
+			references = append(references, list.render(hostOS))
+		}
+	}
+	location := filepath.Join(r.sourceCodeRoot, folder.render(hostOS))
+	r.log.Debug("Comparison if all repository files in '{d}' are referenced in '{f}'", "d", location, "f", r.localPath(listFile))
+	if err := r.compareFilesWithScripts(r.localPath(listFile), referencesInFolder(references, folder), location, ignorePatterns); err != nil {
+		return fmt.Errorf("comparison errors: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// What: Calls of declared utilities are recorded with the list file and the base folder
+func TestListImportLine(t *testing.T) {
+	defer func() {
+		testRun.listImportSettings = nil
+		testRun.initializeRegexPatterns(nil)
+	}()
+	testRun.listImportSettings = []ListImport{
+		{Utility: "ics_import", ListFlag: "-mapping", BaseFlag: "dir"},
+		{Utility: "import_dataset_list", BasePath: "300-Datasets"},
+	}
+	testRun.initializeRegexPatterns(nil)
+
+	tests := []struct {
+		line, listFile, folder string
+		ok                     bool
+	}{
+		{`$TC_BIN/ics_import -mapping="400-ICS/map.csv" -dir="400-ICS/files"`, "400-ICS/map.csv", "400-ICS/files", true},
+		{`ics_import.sh -mapping=400-ICS/map.csv`, "400-ICS/map.csv", "", true},
+		{`import_dataset_list -input="300-Datasets/list.txt"`, "300-Datasets/list.txt", "300-Datasets", true},
+		{`ics_import -dir="400-ICS/files"`, "", "", false},
+		{`my_ics_import_wrapper -mapping="a.csv"`, "", "", false},
+	}
+	for _, tt := range tests {
+		call, ok := testRun.listImportLine(tt.line)
+		if ok != tt.ok || call.ListFile != tt.listFile || call.Folder != tt.folder {
+			t.Errorf("listImportLine(%q) = %+v, %v", tt.line, call, ok)
+		}
+	}
+}
+
+// What: References in the declared column are resolved below the folder and checked,
+// invalid rows, missing and unlisted files of a complete list are reported
+func TestCheckListImports(t *testing.T) {
+	writeStylesheetFixture(t, "linux", map[string]string{
+		"400-ICS/map.csv":          "class;name;file\n# generated\nA1;Part;parts/part.xml\nA2;Missing;parts/missing.xml\nA3\n",
+		"400-ICS/parts/part.xml":   "<a/>",
+		"400-ICS/parts/unused.xml": "<b/>",
+	})
+	decl := ListImport{Utility: "ics_import", Column: 3, Delimiter: ";", Comment: "#", HeaderRows: 1, Complete: true}
+
+	testRun.checkListImports("deploy.sh", map[int]ListImportCall{
+		4: {Declaration: decl, ListFile: "400-ICS/map.csv"},
+		7: {Declaration: decl, ListFile: "400-ICS/absent.csv"},
+	})
+
+	found := make(map[string]Finding)
+	for _, f := range testRun.analysisResult.Findings {
+		found[f.Rule] = f
+	}
+	if f, ok := found[RuleMissingFile]; !ok || f.Script != "400-ICS/map.csv" || f.Line != 4 || f.Path != "400-ICS/parts/missing.xml" {
+		t.Errorf("Expected the missing file of line 4, got %+v", testRun.analysisResult.Findings)
+	}
+	if f, ok := found[RuleListFileRow]; !ok || f.Line != 5 {
+		t.Errorf("Expected the row without the column, got %+v", testRun.analysisResult.Findings)
+	}
+	if f, ok := found[RuleUnreferencedFile]; !ok || !strings.HasSuffix(f.Path, "unused.xml") {
+		t.Errorf("Expected the unlisted file of the folder, got %+v", testRun.analysisResult.Findings)
+	}
+	if f, ok := found[RuleListFile]; !ok || f.Line != 7 {
+		t.Errorf("Expected the unreadable list file, got %+v", testRun.analysisResult.Findings)
+	}
+	if len(testRun.analysisResult.Findings) != 4 {
+		t.Errorf("Expected 4 findings, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+// What: Declarations without a utility or with an invalid layout are rejected
+func TestCheckListImports_Nested(t *testing.T) {
+	writeStylesheetFixture(t, "linux", map[string]string{
+		"500-Modules/master.lst":   "a/module.lst\nb/module.lst\n",
+		"500-Modules/a/module.lst": "part.xml\nmissing.xml\n",
+		"500-Modules/a/part.xml":   "<a/>",
+		"500-Modules/b/module.lst": "data.xml\n../master.lst\n",
+		"500-Modules/b/data.xml":   "<b/>",
+		"500-Modules/b/unused.xml": "<c/>",
+	})
+	decl := ListImport{Utility: "module_import", Column: 1, Complete: true, Nested: "*.lst"}
+
+	testRun.checkListImports("deploy.sh", map[int]ListImportCall{
+		3: {Declaration: decl, ListFile: "500-Modules/master.lst"},
+	})
+
+	found := make(map[string]Finding)
+	for _, f := range testRun.analysisResult.Findings {
+		found[f.Rule] = f
+	}
+	if f, ok := found[RuleMissingFile]; !ok || f.Script != "500-Modules/a/module.lst" || f.Path != "500-Modules/a/missing.xml" {
+		t.Errorf("Expected the missing file of the nested list, got %+v", testRun.analysisResult.Findings)
+	}
+	if f, ok := found[RuleListFileCycle]; !ok || f.Script != "500-Modules/b/module.lst" || f.Line != 2 {
+		t.Errorf("Expected the cycle back to the master list, got %+v", testRun.analysisResult.Findings)
+	}
+	if f, ok := found[RuleUnreferencedFile]; !ok || !strings.HasSuffix(f.Path, "unused.xml") {
+		t.Errorf("Expected the file referenced by no level, got %+v", testRun.analysisResult.Findings)
+	}
+	if len(testRun.analysisResult.Findings) != 3 {
+		t.Errorf("Expected 3 findings, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+func TestListCycle(t *testing.T) {
+	chain := []string{"master.lst", "a/module.lst", "a/sub.lst"}
+	if got := listCycle(chain, "a/module.lst"); got != "a/module.lst -> a/sub.lst -> a/module.lst" {
+		t.Errorf("Unexpected cycle '%s'", got)
+	}
+	if got := listCycle(chain, "b/module.lst"); got != "" {
+		t.Errorf("Expected no cycle, got '%s'", got)
+	}
+}
+
+func TestValidateListImports(t *testing.T) {
+	tests := []struct {
+		imp     ListImport
+		message string
+	}{
+		{ListImport{Utility: "ics_import", Column: 3}, ""},
+		{ListImport{ListFlag: "mapping"}, "missing 'utility'"},
+		{ListImport{Utility: "ics_import", Column: -1}, "invalid column -1"},
+		{ListImport{Utility: "ics_import", BaseFlag: "dir", BasePath: "400-ICS"}, "both 'base_flag' and 'base_path'"},
+	}
+	for _, tt := range tests {
+		err := ValidateListImports([]ListImport{tt.imp})
+		if tt.message == "" && err != nil || tt.message != "" && (err == nil || !strings.Contains(err.Error(), tt.message)) {
+			t.Errorf("ValidateListImports(%+v) = %v, want %q", tt.imp, err, tt.message)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// forLoop is a for loop enclosing the line being parsed
+type forLoop struct {
+	Variable  string
+	Items     []string
+	Reference func(filePath string) bool // whether a path uses the loop variable
+	Replace   func(filePath, item string) string
+}
+
+var (
+	shellForRegex       = regexp.MustCompile(`^for\s+([A-Za-z_][A-Za-z0-9_]*)\s+in\s+(.*?)\s*(?:;\s*do\b\s*(.*?))?$`)
+	shellLoopStartRegex = regexp.MustCompile(`^(while|until)\s`)
+	shellLoopEndRegex   = regexp.MustCompile(`(^|;\s*)done$`)
+	batchForRegex       = regexp.MustCompile(`(?i)^for\s+(?:/[a-z]\s+)?%%([A-Za-z])\s+in\s+\(([^)]*)\)\s+do\s*(.*)$`)
+)
+
+// loopTracker follows shell for/while/until ... done loops and batch FOR loops,
+// with a single command or a ( ... ) block as body
+type loopTracker struct {
+	targetOS string
+	shell    []*forLoop // open loops, nil for loops other than for
+	batch    []*forLoop // open '(' blocks, nil for blocks other than FOR bodies
+	open     []forLoop  // the open for loops, kept with the stacks so lines do not collect them
+}
+
+func newShellLoop(variable, items string) *forLoop {
+	re := regexp.MustCompile(`\$\{` + variable + `\}|\$` + variable + `\b`)
+	return &forLoop{
+		Variable:  variable,
+		Items:     strings.Fields(items),
+		Reference: re.MatchString,
+		Replace:   func(filePath, item string) string { return re.ReplaceAllLiteralString(filePath, unquote(item)) },
+	}
+}
+
+func newBatchLoop(variable, items string) *forLoop {
+	re := regexp.MustCompile(`%%` + variable)
+	return &forLoop{
+		Variable:  variable,
+		Items:     strings.Fields(strings.ReplaceAll(items, ",", " ")),
+		Reference: re.MatchString,
+		Replace:   func(filePath, item string) string { return re.ReplaceAllLiteralString(filePath, unquote(item)) },
+	}
+}
+
+// update processes the next line and returns the for loops the line runs in
+func (l *loopTracker) update(line string) []forLoop {
+	line = strings.TrimSpace(line)
+	if l.targetOS == "windows" {
+		return l.updateBatch(strings.TrimPrefix(line, "@"))
+	}
+	return l.updateShell(line)
+}
+
+func (l *loopTracker) updateShell(line string) []forLoop {
+	if m := shellForRegex.FindStringSubmatch(line); m != nil {
+		loop := newShellLoop(m[1], m[2])
+		if shellLoopEndRegex.MatchString(m[3]) {
+			return append(l.loops(), *loop) // for ...; do command; done
+		}
+		l.shell = l.push(l.shell, loop)
+		return l.loops()
+	}
+	if shellLoopStartRegex.MatchString(line) {
+		l.shell = l.push(l.shell, nil)
+	}
+	loops := l.loops()
+	if shellLoopEndRegex.MatchString(line) && len(l.shell) > 0 {
+		l.shell = l.pop(l.shell)
+	}
+	return loops
+}
+
+func (l *loopTracker) updateBatch(line string) []forLoop {
+	if m := batchForRegex.FindStringSubmatch(line); m != nil {
+		loop := newBatchLoop(m[1], m[2])
+		if strings.TrimSpace(m[3]) == "(" {
+			l.batch = l.push(l.batch, loop)
+			return l.loops()
+		}
+		return append(l.loops(), *loop) // FOR ... DO command
+	}
+	loops := l.loops()
+	if strings.HasPrefix(line, ")") {
+		rest := strings.ToLower(strings.TrimSpace(line[1:]))
+		if !(strings.HasPrefix(rest, "else") && strings.HasSuffix(rest, "(")) && len(l.batch) > 0 {
+			l.batch = l.pop(l.batch)
+		}
+	} else if strings.HasSuffix(line, "(") {
+		l.batch = l.push(l.batch, nil)
+	}
+	return loops
+}
+
+// push opens a loop or block on the stack, nil for those other than for loops
+func (l *loopTracker) push(stack []*forLoop, loop *forLoop) []*forLoop {
+	if loop != nil {
+		l.open = append(l.open, *loop)
+	}
+	return append(stack, loop)
+}
+
+// pop closes the innermost loop or block of the stack
+func (l *loopTracker) pop(stack []*forLoop) []*forLoop {
+	if stack[len(stack)-1] != nil {
+		l.open = l.open[:len(l.open)-1]
+	}
+	return stack[:len(stack)-1]
+}
+
+// loops returns the open for loops, outermost first; appending to them copies them
+func (l *loopTracker) loops() []forLoop {
+	if len(l.open) == 0 {
+		return nil
+	}
+	return l.open[:len(l.open):len(l.open)]
+}
+
+// loopReference returns the patterns of a path built from the variable of an
+// enclosing for loop, one per loop item
+func (r *run) loopReference(filePath string) (LoopReference, bool) {
+	for i := len(r.activeLoops) - 1; i >= 0; i-- {
+		loop := r.activeLoops[i]
+		if !loop.Reference(filePath) {
+			continue
+		}
+		ref := LoopReference{Variable: loop.Variable}
+		for _, item := range loop.Items {
+			ref.Patterns = append(ref.Patterns, loop.Replace(filePath, item))
+		}
+		return ref, true
+	}
+	return LoopReference{}, false
+}
+
+// expandLoopPattern returns the repository files matching a loop pattern, relative to
+// the source code root. Returns false for patterns that cannot be expanded.
+func (r *run) expandLoopPattern(pattern string) ([]string, bool) {
+	if strings.ContainsAny(pattern, "$%`") || isAbsoluteReference(pattern) {
+		return nil, false
+	}
+	pattern = r.localPath(pattern)
+
+	var matches []string
+	if r.remoteTree != nil {
+		for p, isDir := range r.remoteTree {
+			if ok, _ := path.Match(toSlash(pattern), p); ok && !isDir {
+				matches = append(matches, filepath.FromSlash(p))
+			}
+		}
+		sort.Strings(matches)
+		return matches, true
+	}
+
+	fullMatches, err := filepath.Glob(filepath.Join(r.sourceCodeRoot, pattern))
+	if err != nil {
+		return nil, false
+	}
+	for _, m := range fullMatches {
+		if info, err := os.Stat(m); err == nil && info.IsDir() {
+			continue
+		}
+		if rel, err := filepath.Rel(r.sourceCodeRoot, m); err == nil {
+			matches = append(matches, rel)
+		}
+	}
+	return matches, true
+}
+
+// checkLoopReferences expands the for-loop references of the script against the
+// repository. Every matched file counts as referenced; loops matching nothing are missing.
+func (r *run) checkLoopReferences(scriptFile string, refs map[int]LoopReference) {
+	if len(refs) == 0 {
+		return
+	}
+	r.log.Debug("expanding for-loop references in '{s}'", "s", scriptFile)
+
+	si := make([]int, 0, len(refs))
+	for i := range refs {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+
+	for _, i := range si {
+		ref := refs[i]
+		ref.Matches = nil
+		for _, pattern := range ref.Patterns {
+			matches, ok := r.expandLoopPattern(pattern)
+			if !ok {
+				r.reportFinding(Finding{Rule: RuleLoopNotExpanded, Script: scriptFile, Line: i, Column: ref.Column, Path: pattern},
+					"'{s}' line '{ln}': loop item '{p}' cannot be expanded against the repository", "s", scriptFile, "ln", i, "p", pattern)
+				continue
+			}
+			if len(matches) == 0 {
+				r.reportFinding(Finding{Rule: RuleMissingFile, Script: scriptFile, Line: i, Column: ref.Column, Path: pattern},
+					"'{s}' line '{ln}' is invalid: loop over '{p}' matches no files", "s", scriptFile, "ln", i, "p", pattern)
+				r.recordMissing(pattern)
+				continue
+			}
+			r.log.Info("'{s}' line '{ln}': loop over '{p}' matches '{n}' files", "s", scriptFile, "ln", i, "p", pattern, "n", len(matches))
+			ref.Matches = append(ref.Matches, matches...)
+		}
+		refs[i] = ref
+	}
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupLoopTest creates a repository with the given files and a script, and runs the syntax check on it
+func setupLoopTest(t *testing.T, script, targetOS, content string, files ...string) string {
+	t.Helper()
+	setupSyntaxTest()
+	root := t.TempDir()
+	for _, f := range append(files, script) {
+		fullPath := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		data := "<xml/>"
+		if f == script {
+			data = content
+		}
+		if err := os.WriteFile(fullPath, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", f, err)
+		}
+	}
+	testRun.sourceCodeRoot = root
+	testRun.currentScript = script
+	testRun.analysisResult = Result{File: map[string]Lines{script: newLines()}}
+	testRun.currentScriptTargetOS = targetOS
+	testRun.checkFileSyntax(script, root, targetOS)
+	return root
+}
+
+// What: Shell one-line and multi-line for loops expand their globs against the repository
+func TestCheckLoopReferences_Shell(t *testing.T) {
+	setupLoopTest(t, "deploy.sh", "linux",
+		"for f in 100-Config/*.xml; do plmxml_import -i=\"$f\"; done\n"+
+			"for name in a b; do\n  plmxml_import -i=\"200-Data/${name}.xml\"\ndone\n"+
+			"plmxml_import -i=\"$f\"\n",
+		"100-Config/one.xml", "100-Config/two.xml", "200-Data/a.xml", "200-Data/b.xml")
+
+	lines := testRun.analysisResult.File["deploy.sh"]
+	testRun.checkLoopReferences("deploy.sh", lines.LoopReference)
+
+	if got := lines.LoopReference[1].Matches; !reflect.DeepEqual(got, []string{filepath.FromSlash("100-Config/one.xml"), filepath.FromSlash("100-Config/two.xml")}) {
+		t.Errorf("line 1 matches = %v", got)
+	}
+	if got := lines.LoopReference[3].Matches; !reflect.DeepEqual(got, []string{filepath.FromSlash("200-Data/a.xml"), filepath.FromSlash("200-Data/b.xml")}) {
+		t.Errorf("line 3 matches = %v", got)
+	}
+	if _, ok := validPaths(lines)[1]; ok {
+		t.Error("Loop reference should not be recorded as a valid path")
+	}
+	if validPaths(lines)[5] != "$f" {
+		t.Errorf("Variable outside a loop should stay a valid path, got %q", validPaths(lines)[5])
+	}
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %v", testRun.analysisResult.Findings)
+	}
+}
+
+// What: Batch FOR loops with a command or a block body are expanded
+func TestCheckLoopReferences_Batch(t *testing.T) {
+	setupLoopTest(t, "deploy.bat", "windows",
+		"for %%f in (100-Config\\*.xml) do plmxml_import -i=\"%%f\"\r\n"+
+			"FOR %%x IN (200-Data\\*.xml) DO (\r\n  plmxml_import -i=\"%%x\"\r\n)\r\n",
+		"100-Config/one.xml", "200-Data/a.xml")
+
+	lines := testRun.analysisResult.File["deploy.bat"]
+	testRun.checkLoopReferences("deploy.bat", lines.LoopReference)
+
+	if got := lines.LoopReference[1].Matches; !reflect.DeepEqual(got, []string{filepath.FromSlash("100-Config/one.xml")}) {
+		t.Errorf("line 1 matches = %v", got)
+	}
+	if got := lines.LoopReference[3].Matches; !reflect.DeepEqual(got, []string{filepath.FromSlash("200-Data/a.xml")}) {
+		t.Errorf("line 3 matches = %v", got)
+	}
+}
+
+// What: Nested loops are open until their done, loops other than for are left out
+func TestLoopTracker_Nested(t *testing.T) {
+	l := loopTracker{targetOS: "linux"}
+	want := [][]string{{"a"}, {"a"}, {"a", "b"}, {"a", "b", "c"}, {"a", "b"}, {"a", "b"}, {"a"}, {"a"}, nil}
+	for i, line := range []string{"for a in x; do", "while true; do", "for b in y; do", "for c in z; do echo; done",
+		"echo $b", "done", "done", "done", "echo"} {
+		var got []string
+		for _, loop := range l.update(line) {
+			got = append(got, loop.Variable)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Line %d %q: expected loops %v, got %v", i+1, line, want[i], got)
+		}
+	}
+}
+
+// What: Globs matching nothing are missing, unresolved items cannot be expanded
+func TestCheckLoopReferences_NoMatches(t *testing.T) {
+	setupLoopTest(t, "deploy.sh", "linux",
+		"for f in 100-Config/*.xml $EXTRA/*.xml; do plmxml_import -i=\"$f\"; done\n")
+
+	testRun.checkLoopReferences("deploy.sh", testRun.analysisResult.File["deploy.sh"].LoopReference)
+
+	if len(testRun.analysisResult.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %v", testRun.analysisResult.Findings)
+	}
+	if testRun.analysisResult.Findings[0].Rule != RuleMissingFile || testRun.analysisResult.Findings[1].Rule != RuleLoopNotExpanded {
+		t.Errorf("Unexpected findings: %v", testRun.analysisResult.Findings)
+	}
+	if missing := testRun.analysisResult.File["deploy.sh"].Missing; len(missing) != 1 {
+		t.Errorf("Expected 1 missing path, got %v", missing)
+	}
+}
+
+// What: Files matched by a loop are credited as referenced in the directory content check
+func TestCompareFilesWithScripts_LoopMatches(t *testing.T) {
+	root := setupLoopTest(t, "deploy.sh", "linux",
+		"for f in 100-Config/*.xml; do plmxml_import -i=\"$f\"; done\n",
+		"100-Config/one.xml", "100-Config/two.xml")
+	testRun.checkLoopReferences("deploy.sh", testRun.analysisResult.File["deploy.sh"].LoopReference)
+
+	if err := testRun.compareFilesWithScripts("deploy.sh", pathFlags(map[int]string{}), root, []string{"deploy.sh"}); err != nil {
+		t.Fatalf("compareFilesWithScripts() failed: %v", err)
+	}
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected all files referenced, got %v", testRun.analysisResult.Findings)
+	}
+}
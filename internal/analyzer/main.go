@@ -1,39 +1,288 @@
 package analyzer
 
 import (
-	"runtime"
+	"regexp"
+	"time"
 
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
 )
 
 type Lines struct {
-	Valid            map[int]string
 	StyleSheetImport map[int]StyleSheetImport
-	Invalid          map[int]string
+	XMLImport        map[int]XMLImport
+	TemplateInstall  map[int]TemplateInstall
+	PreferenceImport map[int]string // preference files imported by preferences_manager
+	ListImport       map[int]ListImportCall
+	ListReferences   map[string][]string // list file -> files of its rows, relative to the root with forward slashes
+	Utility          map[int]string      // executable called by the line
+	LoopReference    map[int]LoopReference
+	Flags            map[int][]PathFlag // every path flag of the line, in line order: the references of the script
 	Skipped          map[int]string
-	Missing          []string
+	SkipReasons      map[int]string               // category (SkipComment, ...) of the skipped and the blank lines
+	Missing          []string                     // referenced paths not found on the file system, in line order
+	Conditional      map[int]bool                 // lines inside conditional blocks
+	Coverage         map[string]DirectoryCoverage // top-level directory -> coverage
+	Executables      map[string][]Invocation      // executable -> invocations in line order, for the parity check
+	Credentials      map[string]CredentialUse     // credential flag -> its first approved variable
+	Text             map[int]string               // text of the lines, the content of the finding fingerprints
+
+	Unreferenced []string              // repository files not referenced by the script, sorted
+	LastCommits  map[string]FileCommit // last commit of the unreferenced files, with 'git.unreferenced_age'
+
+	ConditionalDepth int // deepest nesting of the conditional blocks
+
+	Timings []PhaseTiming // duration of the analysis phases of the script
+}
+
+// DirectoryCoverage counts repository files present in a directory and how many
+// of them are referenced by the script
+type DirectoryCoverage struct {
+	Present    int
+	Referenced int
+}
+
+// Percent returns the share of present files that are referenced, 100 for empty directories
+func (c DirectoryCoverage) Percent() float64 {
+	if c.Present == 0 {
+		return 100
+	}
+	return float64(c.Referenced) * 100 / float64(c.Present)
 }
 
 type Result struct {
-	File map[string]Lines
+	File     map[string]Lines
+	Findings []Finding
+	Summary  Summary
+	Timings  []PhaseTiming // phases covering all scripts
+	Parity   ParityMatrix  // invocations of each executable per script
 }
 
 type StyleSheetImport struct {
 	Line         string
 	InputFile    string
 	XMLsFilepath string
+	Replace      bool // -replace is passed, existing datasets are overwritten
+
+	Datasets map[int]StylesheetDataset // datasets of the input file by line, set by the stylesheet check
 }
 
-var pathParameters []string
-var sourceCodeRoot string
-var ignores ignorePatterns
-var (
-	convertFrom string
-	convertTo   string
-)
+// StylesheetDataset is a line of a stylesheet import definition: the dataset name and
+// its XML, localized and relative to the source code root
+type StylesheetDataset struct {
+	Name string
+	XML  string
+}
+
+// Invocation is a call of an executable by a script: its line and the command as written
+type Invocation struct {
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+}
+
+// PathFlag is a path flag found on a script line, with the rule of its finding when
+// the flag is invalid. The checks of the referenced files run on every valid flag.
+type PathFlag struct {
+	Line    int
+	Flag    string
+	Path    string // value of the flag, relative to the working directory of the line when valid
+	Column  int    // 1-based column of the path, or of the flag when not quoted
+	Rule    string // RuleFlagNotQuoted, RuleWrongSeparator or RuleWindowsPathRoot; empty when valid
+	Problem string // problem of an invalid flag listed with the invalid line, empty when not quoted
+	Remote  bool   // the path is a URL, not checked on the file system
+	Loop    bool   // the path is built from a loop variable or template expressions
+}
+
+// IsValid reports whether the flag references a path checked on the file system
+func (f PathFlag) IsValid() bool {
+	return f.Rule == "" && !f.Remote && !f.Loop
+}
+
+// XMLImport is an XML passed to the plmxml_import or tcxml_import utility
+type XMLImport struct {
+	Utility string
+	Path    string
+	Column  int // column of the path on the line
+}
 
-var analysisResult Result = Result{
-	File: make(map[string]Lines),
+// ListImportCall is a call of a utility declared in 'list_imports': its list file and
+// the folder the references of the list are relative to, empty for the folder of the list
+type ListImportCall struct {
+	Declaration ListImport
+	ListFile    string
+	Folder      string
+}
+
+// TemplateInstall is a call to the BMIDE template installer (tem)
+type TemplateInstall struct {
+	Line        string
+	Templates   []string
+	PackagePath string
+}
+
+// LoopReference is a path built from a for-loop variable or from template
+// expressions, expanded against the repository
+type LoopReference struct {
+	Variable string   // loop variable, or the template expressions
+	Patterns []string // path with the variable replaced by each loop item
+	Matches  []string // repository files matched, relative to the source code root
+	Column   int      // column of the path on the line
+}
+
+// run is the state of one validation: the configuration it was started with, the
+// patterns compiled from it, the script being processed and the result being built.
+// The entry points of the Analyzer start a run of their own, so validations started
+// by several goroutines do not share state.
+type run struct {
+	log *logger.Logger
+
+	pathParameters       []string
+	sourceCodeRoot       string
+	templateExpectations map[string]string // template name -> expected version
+	workflowsFolder      string
+	archiveSettings      archiveRules
+	symlinkPolicy        string
+	streamingComparison  bool
+	allowedExternalPaths []string
+	windowsPathSettings  windowsPathRules
+	ignores              ignorePatterns
+	activeRuleset        ruleset          // ruleset of the run
+	preferenceModes      []PreferenceMode // compatibility table of the run
+	artifactSettings     artifactRepository
+	urlSettings          urlReferences
+	templateSettings     templating
+	// Network settings shared by the network-backed checks: the remote listing over
+	// SFTP, the artifact repository and the remote references
+	networkSettings networkPolicy
+	// Whether paths referenced only inside conditional blocks count as coverage:
+	// covered (default) or not_covered
+	conditionalPolicy string
+	// Whether heredoc bodies are scanned for path flags, reported as informational findings
+	scanHeredocs bool
+	// requireNonEmptyDirectories reports referenced directories without files to deploy
+	requireNonEmptyDirectories bool
+	// checkTextCharacters scans the referenced text files, set by 'check_text_characters'
+	checkTextCharacters bool
+	// textFileExtensions are the extensions of the files scanned, lower case
+	textFileExtensions []string
+	// encodingPolicy is the severity of the finding reported for scripts not encoded
+	// in UTF-8: info, warning (default), error, or ignore for no finding
+	encodingPolicy string
+	// stylesheetRendering is the configured structure of the stylesheet XMLs
+	stylesheetRendering StylesheetRendering
+	// Whether path flags may also be written as GNU-style long options (--name)
+	gnuLongOptions bool
+
+	// Patterns compiled from the configuration of the run
+	parameterFlagPatterns  map[string]*regexp.Regexp // flagName -> regex for `-flagname`
+	parameterValuePatterns map[string]*regexp.Regexp // flagName -> regex for `-flagname="value"`
+	stylesheetImporters    []stylesheetImporter
+	stylesheetReplaceRegex *regexp.Regexp
+	xmlImportUtilityRegex  *regexp.Regexp
+	templateFlagsRegex     *regexp.Regexp
+	// Patterns extracting the values of the -mode, -scope and -action flags: group 1
+	preferenceFlagPatterns map[string]*regexp.Regexp
+	// ignoreSets caches the compiled sets by pattern list, as the same lists are
+	// matched against every path of a traversal
+	ignoreSets map[string]*ignoreSet
+	// stylesheetImporterSettings are the configured importers, compiled with the regex patterns
+	stylesheetImporterSettings []StylesheetImporter
+	// listImportSettings are the configured list imports, compiled with the regex patterns
+	listImportSettings []ListImport
+	listImports        []listImport
+	flagRules          []compiledFlagRule
+	contentRules       []compiledContentRule
+	credentialFlags    []credentialFlag
+	// allowedExecutables are the normalized patterns of the executables the scripts may
+	// call, any executable when empty
+	allowedExecutables []string
+	binPrefix          *binPrefixRule
+	// Ownership patterns in configuration order, the last matching one owns a path
+	ownerRules []ownerRule
+
+	// Limits stopping a run early, so enormous legacy runs keep the log and the runtime bounded
+	maxFindings int // findings recorded before the run stops, 0 is unlimited
+	failFast    bool
+	stopReason  string // why the run stopped early, empty while it runs
+
+	// findingLogFilter selects the findings written to the log, nil logs all
+	findingLogFilter func(f Finding) bool
+	// Findings of a rule and path reported for several scripts are logged once, for the
+	// first script, and listed with all scripts at the end of the run
+	deduplicateFindings bool
+	// loggedFindingScripts lists the scripts reporting each deduplicated rule and path, by findingKey
+	loggedFindingScripts map[string][]string
+
+	// Paths found under the remote root, relative and with forward slashes, to whether
+	// they are directories. nil when validating against the local source code root.
+	remoteTree map[string]bool
+	// Paths under the source code root deleted or renamed since the current branch forked
+	// from the base branch, relative and with forward slashes, to their rename target ("" when
+	// deleted). nil when no base branch is configured.
+	branchChanges map[string]string
+	// Base branch the changes are listed against
+	gitBaseRef string
+	// Last commit of each file under the source code root, relative and with forward
+	// slashes. nil when 'git.unreferenced_age' is not set.
+	lastCommits map[string]FileCommit
+	// Extracted archives of the scripts being validated: archive path -> temporary directory
+	extractedArchives map[string]string
+	environmentItems  environmentSnapshot // nil when no snapshot is configured
+	// kind -> name -> first deployment by the scripts
+	deployedItems map[string]map[string]itemLocation
+
+	analysisResult Result
+	currentScript  string // script being processed, results are recorded for it
+	// Store current script's target OS for validation
+	currentScriptTargetOS string
+	// Working directory of the script being processed (its 'working_dir' setting)
+	scriptWorkingDir string
+	// Match strategy of the script being processed
+	matchStrategy string
+	// Directory the line being parsed runs in, relative to the source code root and in
+	// segments, maintained by checkFileSyntax. Relative paths referenced by the line are
+	// resolved against it. nil is the source code root.
+	activeWorkingDir []string
+	// For loops enclosing the line being parsed, maintained by checkFileSyntax
+	activeLoops []forLoop
+	// Number of paths excluded by each ignore pattern during the current run
+	ignorePatternHits map[string]int
+	// Referenced files already checked by the content rules during the current run, as
+	// several scripts may reference them
+	contentRuleFiles map[string]bool
+}
+
+// newRun returns the state of a validation logging with log
+func newRun(log *logger.Logger) *run {
+	return &run{
+		log:                    log,
+		analysisResult:         Result{File: make(map[string]Lines)},
+		preferenceFlagPatterns: map[string]*regexp.Regexp{},
+		ignoreSets:             map[string]*ignoreSet{},
+		deduplicateFindings:    true,
+	}
+}
+
+// newLines returns an empty Lines record with all maps allocated.
+func newLines() Lines {
+	return Lines{
+		StyleSheetImport: make(map[int]StyleSheetImport),
+		XMLImport:        make(map[int]XMLImport),
+		TemplateInstall:  make(map[int]TemplateInstall),
+		PreferenceImport: make(map[int]string),
+		ListImport:       make(map[int]ListImportCall),
+		ListReferences:   make(map[string][]string),
+		Utility:          make(map[int]string),
+		LoopReference:    make(map[int]LoopReference),
+		Flags:            make(map[int][]PathFlag),
+		Skipped:          make(map[int]string),
+		SkipReasons:      make(map[int]string),
+		Missing:          []string{},
+		Conditional:      make(map[int]bool),
+		Coverage:         make(map[string]DirectoryCoverage),
+		Executables:      make(map[string][]Invocation),
+		Credentials:      make(map[string]CredentialUse),
+		Text:             make(map[int]string),
+	}
 }
 
 // processScript processes a single deployment script, performing syntax checks,
@@ -45,85 +294,234 @@ var analysisResult Result = Result{
 //
 // Returns:
 //   - error: Any error encountered during processing
-func processScript(script scriptDefinition, params Parameters) error {
+func (r *run) processScript(script scriptDefinition, params Parameters) error {
 	// create a results set for each of our filepaths
-	analysisResult.File[script.Filename] = Lines{
-		Valid:            make(map[int]string),
-		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
-		Skipped:          make(map[int]string),
-		Missing:          []string{},
-	}
+	r.analysisResult.File[script.Filename] = newLines()
+	r.currentScript = script.Filename
+	r.scriptWorkingDir = script.WorkingDir
+	r.matchStrategy = script.MatchStrategy
 
-	logger.Heading(" ")
-	logger.Separate("file '{filePath}'", "filePath", script.Filename)
-	logger.Separate("=====================================")
-	logger.Separate("SCRIPT SYNTAX CHECK")
-	checkFileSyntax(script.Filename, params.SourceCodeRoot, script.TargetOS)
+	r.log.Heading(" ")
+	r.log.Separate("file '{filePath}'", "filePath", script.Filename)
+	r.log.Separate("=====================================")
+	r.log.Separate("SCRIPT SYNTAX CHECK")
+	r.timeScriptPhase(PhaseSyntax, func() {
+		r.checkFileSyntax(script.Filename, params.SourceCodeRoot, script.TargetOS)
+	})
 
-	logger.Separate("FILE SYSTEM REFERENCES CHECK")
-	logger.Separate("Only path definitions with valid syntax are checked.")
-	logger.Separate("The erroring lines found in the script syntax check are ignored.")
+	r.log.Separate("FILE SYSTEM REFERENCES CHECK")
+	r.log.Separate("Only path definitions with valid syntax are checked.")
+	r.log.Separate("The erroring lines found in the script syntax check are ignored.")
 
-	runtimeOS := runtime.GOOS
-	logger.Debug("Check is executed on '{os}' filesystem", "os", runtimeOS)
+	runtimeOS := hostOS
+	r.log.Debug("Check is executed on '{os}' filesystem", "os", runtimeOS)
 
-	// Determine path conversion requirements
-	var err error
-	convertFrom, convertTo, err = determinePathConversion(script.TargetOS, script.Filename)
-	if err != nil {
-		logger.Error(err.Error())
+	// Paths are parsed in the notation of the target OS and rendered for the runtime OS
+	if err := checkTargetOS(script.TargetOS, script.Filename); err != nil {
+		r.log.Error(err.Error())
 		return err
 	}
+	r.currentScriptTargetOS = script.TargetOS
 
-	if convertFrom != "" {
-		logger.Debug("Target OS for '{f}' is '{os}', but runtime OS is '{ros}'", "f", script.Filename, "os", script.TargetOS, "ros", runtimeOS)
-		logger.Debug("Path separators will be converted from '{from}' to '{to}'", "from", convertFrom, "to", convertTo)
+	if script.TargetOS != runtimeOS {
+		r.log.Debug("Target OS for '{f}' is '{os}', but runtime OS is '{ros}'", "f", script.Filename, "os", script.TargetOS, "ros", runtimeOS)
+		r.log.Debug("Paths will be rendered from '{os}' to '{ros}' notation", "os", script.TargetOS, "ros", runtimeOS)
 	} else {
-		logger.Debug("Target OS for '{f}' is matching with the runtime OS", "f", script.Filename)
+		r.log.Debug("Target OS for '{f}' is matching with the runtime OS", "f", script.Filename)
 	}
 
-	// process ignore patterns defined in the configuration to reflect the OS and script
-	ignores = replaceInIgnorePatterns(params.IgnorePatterns, convertFrom, convertTo)
+	// ignore patterns defined in the configuration are kept in gitignore form, the paths
+	// matched against them are written with forward slashes
+	r.ignores = params.IgnorePatterns
 
-	checkFilePathsInScript(script.Filename, analysisResult.File[script.Filename].Valid)
-	checkStylesheetPaths(script.Filename, analysisResult.File[script.Filename].StyleSheetImport)
+	// The file checks run on every valid path flag of the script, a line can have several
+	references := r.analysisResult.File[script.Filename].ValidFlags()
+	r.timeScriptPhase(PhasePathCheck, func() {
+		r.checkLoopReferences(script.Filename, r.analysisResult.File[script.Filename].LoopReference)
+		r.checkPathNormalization(script.Filename, references)
+		r.checkPathEscapes(script.Filename, references)
+		r.checkFilePathsInScript(script.Filename, references)
+		r.checkIgnoredReferences(script.Filename, references, r.ignores.Global)
+	})
+	r.timeScriptPhase(PhaseContentChecks, func() {
+		r.checkFilePermissions(script, references)
+		r.checkDuplicateContent(script.Filename, references)
+	})
+	r.timeScriptPhase(PhaseStylesheet, func() {
+		r.checkStylesheetPaths(script.Filename, r.analysisResult.File[script.Filename].StyleSheetImport)
+	})
+	r.timeScriptPhase(PhaseContentChecks, func() {
+		r.checkXMLImportReferences(script.Filename, r.analysisResult.File[script.Filename].XMLImport)
+		r.checkTemplatePackages(script.Filename, r.analysisResult.File[script.Filename].TemplateInstall)
+		r.checkListImports(script.Filename, r.analysisResult.File[script.Filename].ListImport)
+		r.checkWorkflowTemplates(script.Filename, r.analysisResult.File[script.Filename].XMLImport)
+		r.checkReferencedTextCharacters(script.Filename, r.analysisResult.File[script.Filename])
+		r.checkReferencedContentRules(script.Filename, r.analysisResult.File[script.Filename])
+		r.checkArchives(script.Filename, references)
+		r.checkArtifactReferences(script.Filename, references)
+		r.checkRemoteReferences(script.Filename, r.analysisResult.File[script.Filename].RemoteFlags())
+		if r.environmentItems != nil {
+			r.collectDeployedItems(script.Filename, r.analysisResult.File[script.Filename])
+		}
+	})
 
-	logger.Separate("DIRECTORY CONTENT CHECK")
-	logger.Separate("File & directory patterns defined as 'ignore_patterns' in the configuration are ignored")
+	r.log.Separate("DIRECTORY CONTENT CHECK")
+	r.log.Separate("File & directory patterns defined as 'ignore_patterns' in the configuration are ignored")
 
-	if runtimeOS == "linux" {
-		logger.Debug("We are running on '{ros}', replacing all '\\' in ignore_patterns with '/'", "ros", runtimeOS)
-	} else if runtimeOS == "windows" {
-		logger.Debug("We are running on '{ros}', replacing all '/' in ignore_patterns with '\\'", "ros", runtimeOS)
-	}
-	validLines := replaceInMap(analysisResult.File[script.Filename].Valid, convertFrom, convertTo)
+	validLines := r.localizeFlags(references)
 
-	if err := compareFilesWithScripts(script.Filename, validLines, params.SourceCodeRoot, ignores.Global); err != nil {
-		logger.Error("Errors occurred during file comparison for '{script}': {e}", "script", script.Filename, "e", err.Error())
-		// Continue processing despite errors
-	}
-	logger.Separate(" ")
+	r.timeScriptPhase(PhaseTraversal, func() {
+		if err := r.compareFilesWithScripts(script.Filename, validLines, params.SourceCodeRoot, r.ignores.Global); err != nil {
+			r.log.Error("Errors occurred during file comparison for '{script}': {e}", "script", script.Filename, "e", err.Error())
+			// Continue processing despite errors
+		}
+	})
+
+	r.logCoverage(r.analysisResult.File[script.Filename].Coverage)
+	r.log.Separate(" ")
 
 	return nil
 }
 
-func Run(params Parameters) {
+// analyze analyzes all configured scripts and returns the analysis result, see (*Analyzer).Run
+func (r *run) analyze(params Parameters) (result Result, err error) {
+	defer r.recoverRun(params, &result, &err)
+	start := time.Now()
+
+	// initialize the state of the run
+	params = r.applyRuleset(params)
+	r.analysisResult = Result{File: make(map[string]Lines)}
+	r.pathParameters = pathParameterNames(params.PathParameters)
+	r.sourceCodeRoot = params.SourceCodeRoot
+	r.workflowsFolder = params.WorkflowsFolder
+	r.archiveSettings = params.Archives
+	r.symlinkPolicy = params.Symlinks
+	r.streamingComparison = params.StreamingComparison
+	r.conditionalPolicy = params.ConditionalReferences
+	r.scanHeredocs = params.ScanHeredocs
+	r.requireNonEmptyDirectories = params.RequireNonEmptyDirectories
+	r.checkTextCharacters = params.CheckTextCharacters
+	r.textFileExtensions = normalizeTextFileExtensions(params.TextFileExtensions)
+	r.encodingPolicy = params.ScriptEncoding
+	r.allowedExternalPaths = params.AllowedExternalPaths
+	r.windowsPathSettings = params.WindowsPaths
+	r.artifactSettings = params.ArtifactRepository
+	r.urlSettings = params.URLReferences
+	r.networkSettings = params.Network
+	r.templateSettings = params.Templating
+	r.templateExpectations = make(map[string]string)
+	for _, tp := range params.TemplatePackages {
+		r.templateExpectations[tp.Name] = tp.Version
+	}
+
+	r.maxFindings, r.failFast, r.stopReason = params.MaxFindings, params.FailFast, ""
+	r.loggedFindingScripts = make(map[string][]string)
+	r.ignorePatternHits = make(map[string]int)
+	r.ownerRules = compileOwners(params.Owners)
+
+	// Scripts shipped in archives are read from their extracted copy
+	removeArchives, err := r.extractScriptArchives(params.Scripts, params.SourceCodeRoot)
+	defer removeArchives()
+	if err != nil {
+		r.log.Error(err.Error())
+		return r.analysisResult, err
+	}
+
+	// A missing or empty script is a configuration error, not a finding
+	if err := r.checkScripts(params.Scripts, params.SourceCodeRoot, params.ScriptsWithinRoot); err != nil {
+		r.log.Error(err.Error())
+		return r.analysisResult, err
+	}
+
+	// File existence and repository content are validated on the remote when configured
+	r.remoteTree = nil
+	if params.Remote.Host != "" {
+		var err error
+		r.timeRunPhase(PhaseRemoteListing, func() { err = r.loadRemoteTree(params.Remote) })
+		if err != nil {
+			r.log.Error("Remote validation failed: {e}", "e", err.Error())
+			return r.analysisResult, withKind(KindIO, err)
+		}
+	}
+
+	// References to files deleted or renamed in the current branch are reported as such
+	r.branchChanges, r.gitBaseRef = nil, params.Git.BaseRef
+	if r.gitBaseRef != "" {
+		var err error
+		r.branchChanges, err = r.loadBranchChanges(params.SourceCodeRoot, r.gitBaseRef)
+		if err != nil {
+			r.log.Error("Listing changes since '{b}' failed: {e}", "b", r.gitBaseRef, "e", err.Error())
+			return r.analysisResult, withKind(KindIO, err)
+		}
+	}
 
-	// initialize the package level variables
-	pathParameters = params.PathParameters
-	sourceCodeRoot = params.SourceCodeRoot
+	// Unreferenced files are reported with their last commit when configured
+	r.lastCommits = nil
+	if params.Git.UnreferencedAge {
+		var err error
+		r.lastCommits, err = r.loadLastCommits(params.SourceCodeRoot)
+		if err != nil {
+			r.log.Error("Reading the last commits failed: {e}", "e", err.Error())
+			return r.analysisResult, withKind(KindIO, err)
+		}
+	}
+
+	// Items installed in the environment are cross-checked with the deployed ones when configured
+	r.environmentItems, r.deployedItems = nil, make(map[string]map[string]itemLocation)
+	if params.EnvironmentSnapshot != "" {
+		var err error
+		r.environmentItems, err = r.loadEnvironmentSnapshot(params.EnvironmentSnapshot)
+		if err != nil {
+			r.log.Error(err.Error())
+			return r.analysisResult, err
+		}
+	}
 
 	// Initialize regex patterns once for performance
-	initializeRegexPatterns(params.PathParameters)
+	r.gnuLongOptions = params.GNULongOptions
+	r.stylesheetImporterSettings = params.StylesheetImporters
+	r.stylesheetRendering = params.StylesheetRendering
+	r.listImportSettings = params.ListImports
+	r.initializeRegexPatterns(r.pathParameters)
+	r.flagRules = r.compileFlagRules(params.FlagRules)
+	r.allowedExecutables = compileAllowedExecutables(params.AllowedExecutables)
+	r.binPrefix = compileBinPrefix(params.TCBin)
+	r.credentialFlags = r.compileCredentialFlags(params.CredentialFlags)
+	r.preferenceModes = r.compilePreferenceModes(params.PreferenceModes)
+	r.contentRules, r.contentRuleFiles = compileContentRules(params.ContentRules), make(map[string]bool)
+	if err := r.applyParameterStyles(params.PathParameters); err != nil {
+		r.log.Error(err.Error())
+		return r.analysisResult, withKind(KindConfig, err)
+	}
 
 	for _, script := range params.Scripts {
-		if err := processScript(script, params); err != nil {
+		if r.runStopped() {
+			break
+		}
+		if err := r.processScript(script, params); err != nil {
 			// Error already logged in processScript, continue with other scripts
 			continue
 		}
 	}
 
 	// Check script parity (same executables in Windows and Linux scripts)
-	checkScriptParity(params.Scripts)
+	if r.ruleEnabled(RuleExecutableParity) || r.ruleEnabled(RulePathParity) {
+		r.timeRunPhase(PhaseParity, func() { r.checkScriptParity(params.Scripts) })
+	}
+	r.analysisResult.Parity = r.buildParityMatrix(params.Scripts)
+	r.checkEnvironmentSnapshot()
+
+	r.checkUnusedIgnorePatterns(params.IgnorePatterns)
+	r.logRepeatedFindings()
+
+	err = withKind(KindThreshold, r.checkThresholds(params.Scripts, params.Thresholds))
+	if budgetErr := r.checkPerfBudget(params.Scripts, params.PerfBudget, start); err == nil {
+		err = withKind(KindThreshold, budgetErr)
+	}
+
+	r.logTimings(params.Scripts)
+	r.logOwners(r.analysisResult.Findings)
+	r.analysisResult.Summary = r.summarize(params.Scripts, params.Thresholds, err)
+	r.logSummary(r.analysisResult.Summary)
+	return r.analysisResult, err
 }
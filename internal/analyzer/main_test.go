@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// runSecondFlagTest runs the analysis of a Linux script with a configuration folder
+// holding the given files
+func runSecondFlagTest(t *testing.T, script string, files ...string) Result {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "100-Config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(root, "100-Config", file), []byte("<x/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := testRun.analyze(Parameters{
+		SourceCodeRoot: root,
+		Scripts:        []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+		PathParameters: []PathParameter{{Name: "xml_file"}, {Name: "file"}},
+		IgnorePatterns: ignorePatterns{Global: []string{"deploy.sh"}},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return result
+}
+
+// What: A missing file behind the second path flag of a line is reported at the column of that flag
+func TestRun_SecondPathFlagMissing(t *testing.T) {
+	result := runSecondFlagTest(t, "plmxml_import -xml_file=\"100-Config/a.xml\" -file=\"100-Config/nothere.xml\"\n", "a.xml")
+
+	var missing []Finding
+	for _, f := range result.Findings {
+		if f.Rule == RuleMissingFile {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) != 1 || missing[0].Path != "100-Config/nothere.xml" || missing[0].Line != 1 || missing[0].Column != 51 {
+		t.Errorf("Expected one %s finding for 100-Config/nothere.xml at line 1 column 51, got %+v", RuleMissingFile, missing)
+	}
+}
+
+// What: A file only referenced by the second path flag of a line is not unreferenced
+func TestRun_SecondPathFlagReference(t *testing.T) {
+	result := runSecondFlagTest(t, "plmxml_import -xml_file=\"100-Config/a.xml\" -file=\"100-Config/b.xml\"\n", "a.xml", "b.xml")
+
+	for _, f := range result.Findings {
+		if f.Rule == RuleUnreferencedFile || f.Rule == RuleMissingFile {
+			t.Errorf("Expected no %s or %s finding, got %+v", RuleUnreferencedFile, RuleMissingFile, f)
+		}
+	}
+	if lines := result.File["deploy.sh"]; len(lines.ValidFlags()) != 2 || len(lines.Unreferenced) != 0 {
+		t.Errorf("Expected 2 valid flags and no unreferenced files, got %+v and %v", lines.ValidFlags(), lines.Unreferenced)
+	}
+}
+
+// What: A path flag given twice on a line is classified for each occurrence, an unquoted first one included
+func TestRun_RepeatedPathFlagNotQuoted(t *testing.T) {
+	result := runSecondFlagTest(t, "plmxml_import -xml_file=100-Config/a.xml -xml_file=\"100-Config/b.xml\"\n", "a.xml", "b.xml")
+
+	var notQuoted []Finding
+	for _, f := range result.Findings {
+		if f.Rule == RuleFlagNotQuoted {
+			notQuoted = append(notQuoted, f)
+		}
+	}
+	if len(notQuoted) != 1 || notQuoted[0].Column != 15 {
+		t.Errorf("Expected one %s finding at column 15, got %+v", RuleFlagNotQuoted, notQuoted)
+	}
+	valid := result.File["deploy.sh"].ValidFlags()
+	if len(valid) != 1 || valid[0].Path != "100-Config/b.xml" || valid[0].Column != 53 {
+		t.Errorf("Expected 100-Config/b.xml as the only valid flag at column 53, got %+v", valid)
+	}
+}
+
+// What: A missing file behind the second occurrence of a path flag is reported
+func TestRun_RepeatedPathFlagMissing(t *testing.T) {
+	result := runSecondFlagTest(t, "plmxml_import -xml_file=\"100-Config/a.xml\" -xml_file=\"100-Config/nothere.xml\"\n", "a.xml")
+
+	var missing []Finding
+	for _, f := range result.Findings {
+		if f.Rule == RuleMissingFile {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) != 1 || missing[0].Path != "100-Config/nothere.xml" || missing[0].Column != 55 {
+		t.Errorf("Expected one %s finding for 100-Config/nothere.xml at column 55, got %+v", RuleMissingFile, missing)
+	}
+}
@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// ManifestEntry is a file deployed by a script line
+type ManifestEntry struct {
+	Path    string `json:"path" yaml:"path"` // relative to the source code root, with forward slashes
+	Size    int64  `json:"size" yaml:"size"`
+	SHA256  string `json:"sha256" yaml:"sha256"`
+	Utility string `json:"utility" yaml:"utility"` // executable deploying the file
+	Line    int    `json:"line" yaml:"line"`
+	Source  string `json:"source,omitempty" yaml:"source,omitempty"` // definition listing the file, e.g. a stylesheet import file
+}
+
+// ScriptManifest lists the files a script deploys, in line order
+type ScriptManifest struct {
+	Repository string          `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Script     string          `json:"script" yaml:"script"`
+	Files      []ManifestEntry `json:"files" yaml:"files"`
+}
+
+// BuildManifest lists the files deployed by the scripts of a validation result: the
+// paths referenced by the script lines, the files matched by loop and template
+// references, the stylesheet XMLs of the import definitions and the template packages.
+// Directories are not listed, nor files that cannot be found, which are findings of the
+// validation. The files are hashed locally, a remote source code root is a configuration error.
+func (a *Analyzer) BuildManifest(params Parameters, result Result) ([]ScriptManifest, error) {
+	return a.newRun().buildManifest(params, result)
+}
+
+// BuildManifest lists the deployed files with the default logger, see (*Analyzer).BuildManifest
+func BuildManifest(params Parameters, result Result) ([]ScriptManifest, error) {
+	return New(logger.Default()).BuildManifest(params, result)
+}
+
+// buildManifest lists the deployed files in the run, see (*Analyzer).BuildManifest
+func (r *run) buildManifest(params Parameters, result Result) ([]ScriptManifest, error) {
+	if params.Remote.Host != "" {
+		return nil, withKind(KindConfig, fmt.Errorf("the manifest requires a local source_code_root, the files of remote '%s' cannot be hashed", params.Remote.Host))
+	}
+	r.sourceCodeRoot = params.SourceCodeRoot
+	removeArchives, err := r.extractScriptArchives(params.Scripts, params.SourceCodeRoot)
+	defer removeArchives()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]ScriptManifest, 0, len(params.Scripts))
+	for _, script := range params.Scripts {
+		lines, ok := result.File[script.Filename]
+		if !ok {
+			continue
+		}
+		r.currentScript, r.currentScriptTargetOS = script.Filename, script.TargetOS
+		files, err := r.scriptManifestEntries(lines)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, ScriptManifest{Script: script.Filename, Files: files})
+	}
+	return manifests, nil
+}
+
+// scriptManifestEntries returns the files deployed by the lines of the script being
+// processed, sorted by line and path
+func (r *run) scriptManifestEntries(lines Lines) ([]ManifestEntry, error) {
+	files := []ManifestEntry{}
+	listed := make(map[string]bool)
+	add := func(lineNumber int, path, source string) error {
+		fullPath := path
+		if !filepath.IsAbs(path) {
+			fullPath = r.referenceFilePath(path)
+		}
+		key := fmt.Sprintf("%d:%s", lineNumber, fullPath)
+		if listed[key] {
+			return nil
+		}
+		entry, ok, err := hashManifestFile(fullPath)
+		if err != nil || !ok {
+			return err
+		}
+		listed[key] = true
+		entry.Path, entry.Line, entry.Source = filepath.ToSlash(path), lineNumber, source
+		entry.Utility = lines.Utility[lineNumber]
+		files = append(files, entry)
+		return nil
+	}
+
+	for _, flag := range lines.ValidFlags() {
+		if err := add(flag.Line, r.localPath(flag.Path), ""); err != nil {
+			return nil, err
+		}
+	}
+	for lineNumber, ref := range lines.LoopReference {
+		for _, match := range ref.Matches {
+			if err := add(lineNumber, filepath.FromSlash(match), ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for lineNumber, importDefinition := range lines.StyleSheetImport {
+		for _, dataset := range importDefinition.Datasets {
+			if err := add(lineNumber, dataset.XML, importDefinition.InputFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for lineNumber, install := range lines.TemplateInstall {
+		packageDir := r.localPath(install.PackagePath)
+		for _, name := range install.Templates {
+			if err := add(lineNumber, filepath.Join(packageDir, templatePackageFile(name)), ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Line != files[j].Line {
+			return files[i].Line < files[j].Line
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// hashManifestFile returns the size and SHA-256 checksum of a file; directories and
+// files that do not exist are not listed. A file that cannot be read is an I/O error.
+func hashManifestFile(fullPath string) (ManifestEntry, bool, error) {
+	info, err := os.Stat(fullPath)
+	if err != nil || info.IsDir() {
+		return ManifestEntry{}, false, nil
+	}
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return ManifestEntry{}, false, withKind(KindIO, fmt.Errorf("error opening '%s': %w", fullPath, err))
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return ManifestEntry{}, false, withKind(KindIO, fmt.Errorf("error reading '%s': %w", fullPath, err))
+	}
+	return ManifestEntry{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, true, nil
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests for the manifest of the deployed files
+
+// What: Referenced, matched, stylesheet and template package files are listed in line order with their checksum
+func TestBuildManifest(t *testing.T) {
+	originalRoot, originalScript, originalOS := testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS
+	defer func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS = originalRoot, originalScript, originalOS
+	}()
+
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"100-Config/a.xml":                      "<a/>",
+		"100-Config/b.xml":                      "<b/>",
+		"200-Stylesheets/import.txt":            "Nw4Part.Summary,Nw4Part.xml\n",
+		"200-Stylesheets/Nw4Part.xml":           "<rendering/>",
+		"070-BMIDE/packages/nw4_template.zip":   "zip",
+		"070-BMIDE/packages/unused_template.js": "",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	lines := newLines()
+	lines.Flags[1] = []PathFlag{{Line: 1, Path: "100-Config/a.xml"}}
+	lines.Flags[2] = []PathFlag{{Line: 2, Path: "200-Stylesheets/import.txt"}}
+	lines.Flags[3] = []PathFlag{{Line: 3, Path: "070-BMIDE/packages"}}
+	lines.Flags[5] = []PathFlag{{Line: 5, Path: "100-Config/missing.xml"}}
+	lines.LoopReference[4] = LoopReference{Variable: "f", Matches: []string{"100-Config/b.xml", "100-Config/a.xml"}}
+	lines.StyleSheetImport[2] = StyleSheetImport{InputFile: "200-Stylesheets/import.txt",
+		Datasets: map[int]StylesheetDataset{1: {Name: "Nw4Part.Summary", XML: filepath.Join("200-Stylesheets", "Nw4Part.xml")}}}
+	lines.TemplateInstall[3] = TemplateInstall{Templates: []string{"nw4"}, PackagePath: "070-BMIDE/packages"}
+	lines.Utility = map[int]string{1: "plmxml_import", 2: "install_xml_stylesheet_datasets", 3: "tem", 4: "plmxml_import"}
+
+	params := Parameters{SourceCodeRoot: root, Scripts: []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}, {Filename: "other.sh", TargetOS: "linux"}}}
+	manifests, err := testRun.buildManifest(params, Result{File: map[string]Lines{"deploy.sh": lines}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Script != "deploy.sh" {
+		t.Fatalf("Expected the manifest of deploy.sh only, got %+v", manifests)
+	}
+	want := []ManifestEntry{
+		{Path: "100-Config/a.xml", Size: 4, SHA256: "29114363f749a0226b6988dda3ca2492a954117ab6b5f382706c20300dabc079", Utility: "plmxml_import", Line: 1},
+		{Path: "200-Stylesheets/Nw4Part.xml", Size: 12, Utility: "install_xml_stylesheet_datasets", Line: 2, Source: "200-Stylesheets/import.txt"},
+		{Path: "200-Stylesheets/import.txt", Size: 28, Utility: "install_xml_stylesheet_datasets", Line: 2},
+		{Path: "070-BMIDE/packages/nw4_template.zip", Size: 3, Utility: "tem", Line: 3},
+		{Path: "100-Config/a.xml", Size: 4, Utility: "plmxml_import", Line: 4},
+		{Path: "100-Config/b.xml", Size: 4, Utility: "plmxml_import", Line: 4},
+	}
+	got := manifests[0].Files
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d files, got %+v", len(want), got)
+	}
+	for i := range want {
+		if want[i].SHA256 == "" {
+			want[i].SHA256 = got[i].SHA256
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+// What: The files of a remote source code root cannot be hashed
+func TestBuildManifest_Remote(t *testing.T) {
+	params := Parameters{Remote: remoteTarget{Host: "deploy.example.com"}}
+	if _, err := testRun.buildManifest(params, Result{}); Kind(err) != KindConfig {
+		t.Errorf("Expected a configuration error, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Strategies matching the repository files with the references of a script in the
+// directory content check, selected per script with 'match_strategy'
+const (
+	MatchExact    = "exact"    // the reference is the path relative to source_code_root (default)
+	MatchSuffix   = "suffix"   // the reference and the path end with the same segments
+	MatchBasename = "basename" // the reference has the file name of the path
+)
+
+var matchStrategies = []string{MatchExact, MatchSuffix, MatchBasename}
+
+// IsMatchStrategy reports whether strategy is a 'match_strategy'; empty selects exact
+func IsMatchStrategy(strategy string) bool {
+	for _, s := range matchStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return strategy == ""
+}
+
+// referenceIndex finds the reference matching a repository file by a strategy other
+// than exact, whose paths do not match the file verbatim
+type referenceIndex struct {
+	strategy string
+	byKey    map[string]string // suffix or file name -> reference
+}
+
+// newReferenceIndex indexes the localized references of a script for a strategy; when
+// several share a key, the first in sorted order is kept so the matches do not depend
+// on map order
+func newReferenceIndex(strategy string, valueSet map[string]struct{}) referenceIndex {
+	index := referenceIndex{strategy: strategy, byKey: make(map[string]string)}
+	if strategy == "" || strategy == MatchExact {
+		return index
+	}
+	values := make([]string, 0, len(valueSet))
+	for value := range valueSet {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	add := func(key, value string) {
+		if _, ok := index.byKey[key]; !ok {
+			index.byKey[key] = value
+		}
+	}
+	for _, value := range values {
+		if strategy == MatchBasename {
+			add(filepath.Base(value), value)
+			continue
+		}
+		for _, suffix := range segmentSuffixes(value) {
+			add(suffix, value)
+		}
+	}
+	return index
+}
+
+// match returns the reference matching a repository file, "" when none does. With the
+// suffix strategy a reference deeper than the file ("release/100-Config/a.xml") or
+// shallower ("a.xml" for "100-Config/a.xml") matches when its segments end the other.
+func (x referenceIndex) match(item string, valueSet map[string]struct{}) string {
+	switch x.strategy {
+	case MatchBasename:
+		return x.byKey[filepath.Base(item)]
+	case MatchSuffix:
+		if reference, ok := x.byKey[item]; ok {
+			return reference
+		}
+		for _, suffix := range segmentSuffixes(item) {
+			if _, ok := valueSet[suffix]; ok {
+				return suffix
+			}
+		}
+	}
+	return ""
+}
+
+// segmentSuffixes returns the paths made of the last segments of a host path, the
+// longest first, the path itself included
+func segmentSuffixes(p string) []string {
+	suffixes := []string{p}
+	for i := strings.IndexRune(p, filepath.Separator); i >= 0 && i+1 < len(p); i = strings.IndexRune(p, filepath.Separator) {
+		p = p[i+1:]
+		suffixes = append(suffixes, p)
+	}
+	return suffixes
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests for the match strategies of the directory content check
+
+func TestIsMatchStrategy(t *testing.T) {
+	for _, strategy := range []string{"", MatchExact, MatchSuffix, MatchBasename} {
+		if !IsMatchStrategy(strategy) {
+			t.Errorf("Expected %q to be a match strategy", strategy)
+		}
+	}
+	if IsMatchStrategy("fuzzy") {
+		t.Error("Expected 'fuzzy' not to be a match strategy")
+	}
+}
+
+func TestReferenceIndex_Match(t *testing.T) {
+	valueSet := map[string]struct{}{
+		filepath.Join("release", "100-Config", "a.xml"): {},
+		filepath.Join("b.xml"):                          {},
+		filepath.Join("other", "c.xml"):                 {},
+	}
+	tests := []struct {
+		strategy string
+		item     string
+		want     string
+	}{
+		{MatchExact, filepath.Join("100-Config", "a.xml"), ""},
+		{MatchSuffix, filepath.Join("100-Config", "a.xml"), filepath.Join("release", "100-Config", "a.xml")},
+		{MatchSuffix, "a.xml", filepath.Join("release", "100-Config", "a.xml")},
+		{MatchSuffix, filepath.Join("200-Data", "b.xml"), "b.xml"},
+		{MatchSuffix, filepath.Join("100-Config", "xa.xml"), ""},
+		{MatchSuffix, filepath.Join("200-Data", "c.xml"), ""},
+		{MatchBasename, filepath.Join("200-Data", "c.xml"), filepath.Join("other", "c.xml")},
+		{MatchBasename, filepath.Join("200-Data", "d.xml"), ""},
+	}
+	for _, tt := range tests {
+		if got := newReferenceIndex(tt.strategy, valueSet).match(tt.item, valueSet); got != tt.want {
+			t.Errorf("%s match of %q = %q, want %q", tt.strategy, tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentSuffixes(t *testing.T) {
+	expected := []string{filepath.Join("a", "b", "c.xml"), filepath.Join("b", "c.xml"), "c.xml"}
+	if got := segmentSuffixes(filepath.Join("a", "b", "c.xml")); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCompareFilesWithScripts_MatchStrategy(t *testing.T) {
+	// What: The match strategy of the script being processed applies to its comparison, list files compare exactly
+	tmpDir := setupTestDir(t, []string{"100-Config/a.xml", "100-Config/b.xml"})
+	defer cleanup(t, tmpDir)
+
+	originalRoot, originalScript, originalResult, originalStrategy := testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult, testRun.matchStrategy
+	defer func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult, testRun.matchStrategy = originalRoot, originalScript, originalResult, originalStrategy
+	}()
+	testRun.sourceCodeRoot, testRun.currentScript, testRun.matchStrategy = tmpDir, "deploy.sh", MatchSuffix
+
+	validLines := map[int]string{1: filepath.Join("release", "100-Config", "a.xml")}
+	// the unreferenced files are recorded in the result of the script being processed
+	unreferenced := func(script string) []string {
+		testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+		assertNoError(t, testRun.compareFilesWithScripts(script, pathFlags(validLines), tmpDir, []string{}))
+		return testRun.analysisResult.File["deploy.sh"].Unreferenced
+	}
+	if got, expected := unreferenced("deploy.sh"), []string{filepath.Join("100-Config", "b.xml")}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected unreferenced %v with the suffix strategy, got %v", expected, got)
+	}
+	if got := unreferenced("imports.lst"); len(got) != 2 {
+		t.Errorf("Expected both files unreferenced by the list file, got %v", got)
+	}
+}
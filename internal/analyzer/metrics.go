@@ -0,0 +1,55 @@
+package analyzer
+
+import "fmt"
+
+// ScriptMetrics measures the size and complexity of a script, so scripts grown beyond
+// a maintainable size can be flagged with the thresholds
+type ScriptMetrics struct {
+	Lines              int // lines of the script, blank and comment lines included
+	UtilityInvocations int // lines calling an executable
+	DistinctFiles      int // distinct paths referenced by valid path flags, URLs left out
+	ConditionalDepth   int // deepest nesting of if/case blocks
+}
+
+// scriptMetrics computes the metrics of a script parsed for targetOS
+func scriptMetrics(lines Lines, targetOS string) ScriptMetrics {
+	files := make(map[string]struct{})
+	for _, flags := range lines.Flags {
+		for _, flag := range flags {
+			if flag.Rule != "" || flag.Path == "" || isRemoteReference(flag.Path) {
+				continue
+			}
+			files[slashPath(flag.Path, targetOS)] = struct{}{}
+		}
+	}
+	return ScriptMetrics{
+		Lines:              len(lines.Text),
+		UtilityInvocations: len(lines.Utility),
+		DistinctFiles:      len(files),
+		ConditionalDepth:   lines.ConditionalDepth,
+	}
+}
+
+// recordConditionalDepth keeps the deepest nesting of conditional blocks found on the
+// lines of a script
+func (r *run) recordConditionalDepth(scriptFile string, depth int) {
+	if lines, ok := r.analysisResult.File[scriptFile]; ok && depth > lines.ConditionalDepth {
+		lines.ConditionalDepth = depth
+		r.analysisResult.File[scriptFile] = lines
+	}
+}
+
+// metricsViolations returns one message per script metric above its threshold
+func metricsViolations(script string, metrics ScriptMetrics, limits thresholds) []string {
+	var violations []string
+	check := func(value int, limit *int, name, key string) {
+		if limit != nil && value > *limit {
+			violations = append(violations, fmt.Sprintf("'%s' has %d %s, above '%s' of %d", script, value, name, key, *limit))
+		}
+	}
+	check(metrics.Lines, limits.MaxScriptLines, "lines", "max_script_lines")
+	check(metrics.UtilityInvocations, limits.MaxUtilityInvocations, "utility invocations", "max_utility_invocations")
+	check(metrics.DistinctFiles, limits.MaxDistinctFiles, "distinct files", "max_distinct_files")
+	check(metrics.ConditionalDepth, limits.MaxConditionalDepth, "levels of conditional nesting", "max_conditional_depth")
+	return violations
+}
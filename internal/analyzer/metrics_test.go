@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Tests for the size and complexity metrics of the scripts
+
+// What: Lines, utility invocations, distinct files and conditional depth are measured on the parsed script
+func TestScriptMetrics(t *testing.T) {
+	logger.InitLogger(os.DevNull, "error")
+	content := "# deploy\n" +
+		"plmxml_import -xml_file=\"a.xml\"\n" +
+		"if [ -f b.xml ]; then\n" +
+		"  plmxml_import -xml_file=\"./b.xml\"\n" +
+		"  if [ \"$X\" = 1 ]; then plmxml_import -xml_file=\"a.xml\"; fi\n" +
+		"fi\n" +
+		"plmxml_import -xml_file=\"https://repo/c.xml\"\n" +
+		"plmxml_import -xml_file=d.xml\n"
+	if _, _, err := testRun.parseScript("deploy.sh", content, "linux", parseParameters); err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+
+	metrics := scriptMetrics(testRun.analysisResult.File["deploy.sh"], "linux")
+	expected := ScriptMetrics{Lines: 8, UtilityInvocations: 5, DistinctFiles: 2, ConditionalDepth: 2}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("scriptMetrics = %+v, want %+v", metrics, expected)
+	}
+}
+
+// What: Each metric above its threshold is a violation naming the script and the limit
+func TestEvaluateThresholds_Metrics(t *testing.T) {
+	linux := newLines()
+	linux.Text = map[int]string{1: "a", 2: "b", 3: "c"}
+	linux.Utility = map[int]string{1: "plmxml_import", 2: "plmxml_import"}
+	linux.ConditionalDepth = 3
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": linux}}
+	scripts := []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}}
+
+	limits := thresholds{MaxScriptLines: intPtr(3), MaxUtilityInvocations: intPtr(1), MaxConditionalDepth: intPtr(2)}
+	if !thresholdsConfigured(limits) {
+		t.Fatal("Expected the metric thresholds to count as configured")
+	}
+	violations := testRun.evaluateThresholds(scripts, limits)
+	expected := []string{
+		"'deploy.sh' has 2 utility invocations, above 'max_utility_invocations' of 1",
+		"'deploy.sh' has 3 levels of conditional nesting, above 'max_conditional_depth' of 2",
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("violations = %v, want %v", violations, expected)
+	}
+}
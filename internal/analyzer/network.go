@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Defaults of the network settings not configured
+const (
+	defaultNetworkTimeout = 30 * time.Second
+	defaultNetworkRetries = 2
+	defaultNetworkBackoff = time.Second
+)
+
+// proxyFunc selects the proxy of a request from HTTPS_PROXY, HTTP_PROXY and NO_PROXY;
+// replaced by the tests, as the standard library reads the environment once
+var proxyFunc = http.ProxyFromEnvironment
+
+// timeout returns the timeout of a request or connection
+func (n networkPolicy) timeout() time.Duration {
+	if n.Timeout > 0 {
+		return n.Timeout
+	}
+	return defaultNetworkTimeout
+}
+
+// retries returns how often a failed request or connection is tried again
+func (n networkPolicy) retries() int {
+	if n.Retries != nil {
+		return *n.Retries
+	}
+	return defaultNetworkRetries
+}
+
+// backoff returns the delay before a retry, doubled for each retry after the first
+func (n networkPolicy) backoff(retry int) time.Duration {
+	delay := defaultNetworkBackoff
+	if n.Backoff > 0 {
+		delay = n.Backoff
+	}
+	return delay << (retry - 1)
+}
+
+// newHTTPClient returns the client of the network-backed checks, with the configured
+// timeout and the proxy of the environment
+func (r *run) newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) { return proxyFunc(req) }
+	return &http.Client{Timeout: r.networkSettings.timeout(), Transport: transport}
+}
+
+// retryable reports whether a response is worth trying again: the server is
+// overloaded or temporarily unavailable
+func retryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// doRequest sends a request built by newRequest, retrying with backoff on connection
+// errors and retryable responses. The last response is returned once the retries are
+// exhausted, the caller closes its body.
+func (r *run) doRequest(client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			time.Sleep(r.networkSettings.backoff(retry))
+		}
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		last := retry >= r.networkSettings.retries()
+		switch {
+		case err != nil && !last:
+			r.log.Debug("request to '{u}' failed, retrying: {e}", "u", req.URL.Redacted(), "e", err.Error())
+		case err == nil && retryable(resp) && !last:
+			r.log.Debug("request to '{u}' answered '{s}', retrying", "u", req.URL.Redacted(), "s", resp.Status)
+			resp.Body.Close()
+		default:
+			return resp, err
+		}
+	}
+}
+
+// dialNetwork opens a TCP connection to address, through the proxy of the environment
+// when one applies to it, retrying with backoff on failure
+func (r *run) dialNetwork(address string) (net.Conn, error) {
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			time.Sleep(r.networkSettings.backoff(retry))
+		}
+		conn, err := r.dialOnce(address)
+		if err == nil || retry >= r.networkSettings.retries() {
+			return conn, err
+		}
+		r.log.Debug("connection to '{a}' failed, retrying: {e}", "a", address, "e", err.Error())
+	}
+}
+
+// dialOnce opens a TCP connection to address, tunneled through an HTTP proxy with
+// CONNECT when HTTPS_PROXY selects one for it
+func (r *run) dialOnce(address string) (net.Conn, error) {
+	timeout := r.networkSettings.timeout()
+	proxy, err := proxyFunc(&http.Request{URL: &url.URL{Scheme: "https", Host: address}})
+	if err != nil {
+		return nil, err
+	}
+	if proxy == nil {
+		return net.DialTimeout("tcp", address, timeout)
+	}
+
+	conn, err := net.DialTimeout("tcp", proxy.Host, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy '%s': %w", proxy.Redacted(), err)
+	}
+	connect := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: address}, Host: address, Header: make(http.Header)}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		connect.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err := connect.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy '%s': %w", proxy.Redacted(), err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, connect)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy '%s': %w", proxy.Redacted(), err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy '%s' refused the tunnel to '%s': '%s'", proxy.Redacted(), address, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return tunnelConn{conn, reader}, nil
+}
+
+// tunnelConn is a connection tunneled through a proxy, whose first bytes of the
+// tunneled protocol may have been buffered with the response to CONNECT
+type tunnelConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c tunnelConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
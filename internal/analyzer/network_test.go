@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests for the shared network client of the network-backed checks
+
+func setupNetworkTest(t *testing.T, retries int, proxy *url.URL) {
+	t.Helper()
+	originalSettings, originalProxy := testRun.networkSettings, proxyFunc
+	t.Cleanup(func() { testRun.networkSettings, proxyFunc = originalSettings, originalProxy })
+	testRun.networkSettings = networkPolicy{Timeout: 5 * time.Second, Retries: &retries, Backoff: time.Millisecond}
+	proxyFunc = func(*http.Request) (*url.URL, error) { return proxy, nil }
+}
+
+func TestNetworkPolicy_Defaults(t *testing.T) {
+	// What: Unset settings use the defaults, retries can be disabled with 0
+	var n networkPolicy
+	if n.timeout() != defaultNetworkTimeout || n.retries() != defaultNetworkRetries || n.backoff(1) != defaultNetworkBackoff {
+		t.Errorf("Expected the defaults, got %s, %d, %s", n.timeout(), n.retries(), n.backoff(1))
+	}
+	noRetries := 0
+	n = networkPolicy{Retries: &noRetries, Backoff: 100 * time.Millisecond}
+	if n.retries() != 0 {
+		t.Errorf("Expected no retries, got %d", n.retries())
+	}
+	if n.backoff(1) != 100*time.Millisecond || n.backoff(3) != 400*time.Millisecond {
+		t.Errorf("Expected the backoff doubled per retry, got %s and %s", n.backoff(1), n.backoff(3))
+	}
+}
+
+func TestDoRequest_RetriesUnavailable(t *testing.T) {
+	// What: Unavailable responses are retried until one succeeds
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	setupNetworkTest(t, 2, nil)
+
+	resp, err := testRun.doRequest(testRun.newHTTPClient(), func() (*http.Request, error) { return http.NewRequest(http.MethodHead, server.URL, nil) })
+	if err != nil {
+		t.Fatalf("Expected the request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("Expected 200 after 3 requests, got %d after %d", resp.StatusCode, requests)
+	}
+}
+
+func TestDoRequest_RetriesExhausted(t *testing.T) {
+	// What: The last response is returned once the retries are exhausted, other failures are not retried
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	setupNetworkTest(t, 1, nil)
+
+	for path, expected := range map[string]int32{"/busy": 2, "/missing": 1} {
+		requests = 0
+		resp, err := testRun.doRequest(testRun.newHTTPClient(), func() (*http.Request, error) { return http.NewRequest(http.MethodHead, server.URL+path, nil) })
+		if err != nil {
+			t.Fatalf("Expected a response for %s, got %v", path, err)
+		}
+		resp.Body.Close()
+		if requests != expected {
+			t.Errorf("Expected %d requests for %s, got %d", expected, path, requests)
+		}
+	}
+}
+
+func TestDoRequest_Proxy(t *testing.T) {
+	// What: Requests are sent through the proxy of the environment
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		if r.URL.Host != "repo.invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	setupNetworkTest(t, 0, proxyURL)
+
+	resp, err := testRun.doRequest(testRun.newHTTPClient(), func() (*http.Request, error) {
+		return http.NewRequest(http.MethodHead, "http://repo.invalid/a.zip", nil)
+	})
+	if err != nil {
+		t.Fatalf("Expected the proxy to answer, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || proxied != 1 {
+		t.Errorf("Expected the request proxied, got %d after %d proxied", resp.StatusCode, proxied)
+	}
+}
+
+// newTunnelProxy accepts one CONNECT with the expected credentials and answers the
+// tunneled connection itself with greeting, in the same write as the CONNECT response
+func newTunnelProxy(t *testing.T, greeting string) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Host != "sftp.invalid:22" || req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpzZWNyZXQ=" {
+			io.WriteString(conn, "HTTP/1.1 403 Forbidden\r\n\r\n")
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"+greeting)
+		io.Copy(io.Discard, conn)
+	}()
+	return listener
+}
+
+func TestDialNetwork_Tunnel(t *testing.T) {
+	// What: Connections are tunneled through the proxy with CONNECT, the bytes sent along with its response are kept
+	listener := newTunnelProxy(t, "SSH-2.0-test\r\n")
+	setupNetworkTest(t, 0, &url.URL{Scheme: "http", Host: listener.Addr().String(), User: url.UserPassword("user", "secret")})
+
+	conn, err := testRun.dialNetwork("sftp.invalid:22")
+	if err != nil {
+		t.Fatalf("Expected the tunnel, got %v", err)
+	}
+	defer conn.Close()
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || banner != "SSH-2.0-test\r\n" {
+		t.Errorf("Expected the banner through the tunnel, got %q (%v)", banner, err)
+	}
+}
+
+func TestDialNetwork_TunnelRefused(t *testing.T) {
+	// What: A proxy refusing the tunnel is a connection error
+	listener := newTunnelProxy(t, "")
+	setupNetworkTest(t, 0, &url.URL{Scheme: "http", Host: listener.Addr().String()})
+
+	if conn, err := testRun.dialNetwork("sftp.invalid:22"); err == nil {
+		conn.Close()
+		t.Errorf("Expected the refused tunnel to fail")
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Owner of findings without a matching ownership pattern
+const unownedFindings = "(unowned)"
+
+// ownerRule is a compiled ownership pattern
+type ownerRule struct {
+	matcher *regexp.Regexp // compileGitignorePattern, nil matches nothing
+	owner   string
+}
+
+// compileOwners compiles the configured ownership patterns
+func compileOwners(owners []ownerMapping) []ownerRule {
+	compiled := make([]ownerRule, 0, len(owners))
+	for _, o := range owners {
+		compiled = append(compiled, ownerRule{matcher: compileGitignorePattern(o.Pattern), owner: o.Owner})
+	}
+	return compiled
+}
+
+// ownerOf returns the owner of a path relative to the source code root, as in
+// CODEOWNERS the last matching pattern wins. "" when no pattern matches.
+func (r *run) ownerOf(p string) string {
+	p = toSlash(p)
+	for i := len(r.ownerRules) - 1; i >= 0; i-- {
+		if matcher := r.ownerRules[i].matcher; matcher != nil && matcher.MatchString(p) {
+			return r.ownerRules[i].owner
+		}
+	}
+	return ""
+}
+
+// findingOwner returns the owner of a finding: of its path, or of the file it was
+// found in when the path has no owner
+func (r *run) findingOwner(f Finding) string {
+	if f.Path != "" {
+		if owner := r.ownerOf(f.Path); owner != "" {
+			return owner
+		}
+	}
+	if f.Script != "" {
+		return r.ownerOf(f.Script)
+	}
+	return ""
+}
+
+// logOwners writes the number of findings per owner, sorted by owner, when ownership
+// patterns are configured
+func (r *run) logOwners(findings []Finding) {
+	if len(r.ownerRules) == 0 {
+		return
+	}
+	counts := make(map[string]int)
+	for _, f := range findings {
+		owner := f.Owner
+		if owner == "" {
+			owner = unownedFindings
+		}
+		counts[owner]++
+	}
+
+	r.log.Heading(" ")
+	r.log.Separate("FINDINGS BY OWNER")
+	r.log.Separate("=====================================")
+	if len(counts) == 0 {
+		r.log.Separate("none")
+		return
+	}
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		r.log.Separate("  {o}: {n} finding(s)", "o", owner, "n", counts[owner])
+	}
+}
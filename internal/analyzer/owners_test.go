@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// What: The last matching ownership pattern owns a path, as in CODEOWNERS
+func TestOwnerOf(t *testing.T) {
+	original := testRun.ownerRules
+	defer func() { testRun.ownerRules = original }()
+	testRun.ownerRules = compileOwners([]ownerMapping{
+		{Pattern: "*", Owner: "@platform"},
+		{Pattern: "200-Stylesheets/", Owner: "@ui-team"},
+		{Pattern: "100-Config/Preferences/", Owner: "@bmide-team"},
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"200-Stylesheets/form.xml", "@ui-team"},
+		{`200-Stylesheets\sub\form.xml`, "@ui-team"},
+		{`100-Config\Preferences\prefs.xml`, "@bmide-team"},
+		{"deploy.sh", "@platform"},
+	}
+	for _, tt := range tests {
+		if got := testRun.ownerOf(tt.path); got != tt.want {
+			t.Errorf("ownerOf(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// What: Findings are owned by their path, or by the file they were found in
+func TestRecordFinding_Owner(t *testing.T) {
+	originalRules, originalResult := testRun.ownerRules, testRun.analysisResult
+	defer func() { testRun.ownerRules, testRun.analysisResult = originalRules, originalResult }()
+	testRun.ownerRules = compileOwners([]ownerMapping{
+		{Pattern: "200-Stylesheets/", Owner: "@ui-team"},
+		{Pattern: "*.sh", Owner: "@ops"},
+	})
+	testRun.analysisResult = Result{}
+
+	testRun.recordFinding(Finding{Rule: RuleMissingFile, Script: "deploy.sh", Path: "200-Stylesheets/a.xml"})
+	testRun.recordFinding(Finding{Rule: RuleNotExecutable, Script: "deploy.sh", Path: "bin/tool"})
+	testRun.recordFinding(Finding{Rule: RuleExecutableParity, Path: "tool"})
+
+	owners := []string{testRun.analysisResult.Findings[0].Owner, testRun.analysisResult.Findings[1].Owner, testRun.analysisResult.Findings[2].Owner}
+	if owners[0] != "@ui-team" || owners[1] != "@ops" || owners[2] != "" {
+		t.Errorf("Expected owners [@ui-team @ops ''], got %q", owners)
+	}
+}
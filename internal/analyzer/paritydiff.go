@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The parity diff puts the commands of a Windows script next to the ones of its Linux
+// counterpart, aligned by executable and referenced file, so a reviewer reads the
+// differences of the pair instead of both scripts.
+
+// Status of an aligned row of a parity diff
+const (
+	DiffSame        = "same"    // same executable and file on both sides
+	DiffFile        = "file"    // same executable, another file
+	DiffWindowsOnly = "windows" // command of the Windows script only
+	DiffLinuxOnly   = "linux"   // command of the Linux script only
+)
+
+// ParityDiff is the alignment of the commands of a Windows and a Linux script
+type ParityDiff struct {
+	Repository string          `json:"repository,omitempty"` // set when several repositories are validated
+	Windows    string          `json:"windows"`
+	Linux      string          `json:"linux"`
+	Rows       []ParityDiffRow `json:"rows"`
+}
+
+// ParityDiffRow is a command of one script with its counterpart in the other, nil when
+// the command has none
+type ParityDiffRow struct {
+	Status  string       `json:"status"`
+	Windows *DiffCommand `json:"windows,omitempty"`
+	Linux   *DiffCommand `json:"linux,omitempty"`
+}
+
+// DiffCommand is a script line calling an executable
+type DiffCommand struct {
+	Line       int    `json:"line"`
+	Text       string `json:"text"`
+	Executable string `json:"executable"`
+	Path       string `json:"path,omitempty"` // referenced file as written, a URL for remote references
+	File       string `json:"file,omitempty"` // referenced file with forward slashes, compared between the scripts
+}
+
+// BuildParityDiffs returns the parity diffs of the Windows and Linux script pairs of
+// the results. Scripts are paired by their name without extension (deploy.bat and
+// deploy.sh), the remaining ones in the order of the configuration; scripts without
+// a counterpart are left out.
+func BuildParityDiffs(results []RepositoryResult) []ParityDiff {
+	var diffs []ParityDiff
+	for _, r := range results {
+		for _, pair := range pairScripts(r.Result.Parity.Scripts) {
+			diff := ParityDiff{
+				Windows: pair[0].Filename,
+				Linux:   pair[1].Filename,
+				Rows: alignCommands(
+					diffCommands(r.Result.File[pair[0].Filename], "windows"),
+					diffCommands(r.Result.File[pair[1].Filename], "linux")),
+			}
+			if len(results) > 1 {
+				diff.Repository = r.Name
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// pairScripts returns the Windows and Linux scripts paired, Windows first
+func pairScripts(scripts []ParityScript) [][2]ParityScript {
+	var windows, linux []ParityScript
+	for _, script := range scripts {
+		switch script.TargetOS {
+		case "windows":
+			windows = append(windows, script)
+		case "linux":
+			linux = append(linux, script)
+		}
+	}
+
+	stem := func(script ParityScript) string {
+		name := filepath.Base(filepath.FromSlash(script.Filename))
+		return strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	var pairs [][2]ParityScript
+	paired := make(map[int]bool)
+	var unpaired []ParityScript
+	for _, w := range windows {
+		match := -1
+		for i, l := range linux {
+			if !paired[i] && stem(l) == stem(w) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			unpaired = append(unpaired, w)
+			continue
+		}
+		paired[match] = true
+		pairs = append(pairs, [2]ParityScript{w, linux[match]})
+	}
+	for i, l := range linux {
+		if paired[i] {
+			continue
+		}
+		if len(unpaired) == 0 {
+			break
+		}
+		pairs = append(pairs, [2]ParityScript{unpaired[0], l})
+		unpaired = unpaired[1:]
+	}
+	return pairs
+}
+
+// diffCommands returns the lines of a script calling an executable, in line order
+func diffCommands(lines Lines, targetOS string) []DiffCommand {
+	numbers := make([]int, 0, len(lines.Utility))
+	for number := range lines.Utility {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	commands := make([]DiffCommand, 0, len(numbers))
+	for _, number := range numbers {
+		command := DiffCommand{Line: number, Text: strings.TrimSpace(lines.Text[number]), Executable: lines.Utility[number]}
+		// a command is aligned by its first valid path, or its first URL
+		for _, flag := range lines.Flags[number] {
+			if flag.IsValid() {
+				command.Path, command.File = flag.Path, slashPath(flag.Path, targetOS)
+				break
+			}
+			if flag.Remote && command.Path == "" {
+				command.Path, command.File = flag.Path, flag.Path
+			}
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// diffKey is what aligns two commands as the same: the executable and its file
+func diffKey(command DiffCommand) string {
+	return command.Executable + "\x00" + command.File
+}
+
+// alignCommands aligns the commands of both scripts on their longest common sequence
+// of executable and file. Between two aligned commands, the ones calling the same
+// executable with another file are put next to each other, the others are one-sided.
+func alignCommands(windows, linux []DiffCommand) []ParityDiffRow {
+	// common[i][j] is the length of the common sequence of windows[i:] and linux[j:]
+	common := make([][]int, len(windows)+1)
+	for i := range common {
+		common[i] = make([]int, len(linux)+1)
+	}
+	for i := len(windows) - 1; i >= 0; i-- {
+		for j := len(linux) - 1; j >= 0; j-- {
+			switch {
+			case diffKey(windows[i]) == diffKey(linux[j]):
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var rows []ParityDiffRow
+	var gapWindows, gapLinux []DiffCommand
+	i, j := 0, 0
+	for i < len(windows) || j < len(linux) {
+		switch {
+		case i < len(windows) && j < len(linux) && diffKey(windows[i]) == diffKey(linux[j]):
+			rows = append(rows, alignGap(gapWindows, gapLinux)...)
+			gapWindows, gapLinux = nil, nil
+			rows = append(rows, ParityDiffRow{Status: DiffSame, Windows: &windows[i], Linux: &linux[j]})
+			i, j = i+1, j+1
+		case j == len(linux) || (i < len(windows) && common[i+1][j] >= common[i][j+1]):
+			gapWindows = append(gapWindows, windows[i])
+			i++
+		default:
+			gapLinux = append(gapLinux, linux[j])
+			j++
+		}
+	}
+	return append(rows, alignGap(gapWindows, gapLinux)...)
+}
+
+// alignGap aligns the commands between two aligned ones: a Windows command is put next
+// to the next Linux command calling the same executable, the Linux commands skipped
+// and the Windows commands without such one are one-sided
+func alignGap(windows, linux []DiffCommand) []ParityDiffRow {
+	var rows []ParityDiffRow
+	j := 0
+	for i := range windows {
+		match := -1
+		for k := j; k < len(linux); k++ {
+			if linux[k].Executable == windows[i].Executable {
+				match = k
+				break
+			}
+		}
+		if match < 0 {
+			rows = append(rows, ParityDiffRow{Status: DiffWindowsOnly, Windows: &windows[i]})
+			continue
+		}
+		for ; j < match; j++ {
+			rows = append(rows, ParityDiffRow{Status: DiffLinuxOnly, Linux: &linux[j]})
+		}
+		rows = append(rows, ParityDiffRow{Status: DiffFile, Windows: &windows[i], Linux: &linux[match]})
+		j = match + 1
+	}
+	for ; j < len(linux); j++ {
+		rows = append(rows, ParityDiffRow{Status: DiffLinuxOnly, Linux: &linux[j]})
+	}
+	return rows
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// diffStatuses returns the status of each row with its Windows and Linux lines, 0 for
+// the missing side
+func diffStatuses(rows []ParityDiffRow) [][3]interface{} {
+	var statuses [][3]interface{}
+	for _, row := range rows {
+		w, l := 0, 0
+		if row.Windows != nil {
+			w = row.Windows.Line
+		}
+		if row.Linux != nil {
+			l = row.Linux.Line
+		}
+		statuses = append(statuses, [3]interface{}{row.Status, w, l})
+	}
+	return statuses
+}
+
+func TestPairScripts(t *testing.T) {
+	// What: Scripts are paired by name without extension first, the others in configuration order
+	scripts := []ParityScript{
+		{Filename: "install.bat", TargetOS: "windows"},
+		{Filename: "Deploy.bat", TargetOS: "windows"},
+		{Filename: "deploy.sh", TargetOS: "linux"},
+		{Filename: "setup.sh", TargetOS: "linux"},
+		{Filename: "extra.sh", TargetOS: "linux"},
+	}
+	pairs := pairScripts(scripts)
+	var got [][2]string
+	for _, pair := range pairs {
+		got = append(got, [2]string{pair[0].Filename, pair[1].Filename})
+	}
+	want := [][2]string{{"Deploy.bat", "deploy.sh"}, {"install.bat", "setup.sh"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestAlignCommands(t *testing.T) {
+	// What: Commands are aligned by executable and file, another file of the same executable is a file difference
+	windows := []DiffCommand{
+		{Line: 1, Executable: "plmxml_import", File: "100-Config/a.xml"},
+		{Line: 2, Executable: "make_user"},
+		{Line: 3, Executable: "preferences_manager", File: "200-Prefs/old.xml"},
+		{Line: 4, Executable: "plmxml_import", File: "100-Config/b.xml"},
+	}
+	linux := []DiffCommand{
+		{Line: 1, Executable: "plmxml_import", File: "100-Config/a.xml"},
+		{Line: 2, Executable: "clsutility"},
+		{Line: 3, Executable: "preferences_manager", File: "200-Prefs/new.xml"},
+		{Line: 4, Executable: "plmxml_import", File: "100-Config/b.xml"},
+	}
+	want := [][3]interface{}{
+		{DiffSame, 1, 1},
+		{DiffWindowsOnly, 2, 0},
+		{DiffLinuxOnly, 0, 2},
+		{DiffFile, 3, 3},
+		{DiffSame, 4, 4},
+	}
+	if got := diffStatuses(alignCommands(windows, linux)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestAlignCommands_OneSided(t *testing.T) {
+	// What: Commands after the last aligned one and of an empty script are one-sided
+	linux := []DiffCommand{{Line: 1, Executable: "plmxml_import"}, {Line: 2, Executable: "make_user"}}
+	want := [][3]interface{}{{DiffLinuxOnly, 0, 1}, {DiffLinuxOnly, 0, 2}}
+	if got := diffStatuses(alignCommands(nil, linux)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestBuildParityDiffs(t *testing.T) {
+	// What: The commands of a pair are read from the results with their paths compared with forward slashes
+	windows, linux := newLines(), newLines()
+	windows.Utility[3] = "plmxml_import"
+	windows.Text[3] = `  plmxml_import.exe -xml_file="100-Config\a.xml"`
+	windows.Flags[3] = []PathFlag{{Line: 3, Path: `100-Config\a.xml`}}
+	linux.Utility[5] = "plmxml_import"
+	linux.Text[5] = `plmxml_import -xml_file="100-Config/a.xml"`
+	linux.Flags[5] = []PathFlag{{Line: 5, Path: "100-Config/a.xml"}}
+	results := []RepositoryResult{{Name: "core", Result: Result{
+		File: map[string]Lines{"deploy.bat": windows, "deploy.sh": linux},
+		Parity: ParityMatrix{Scripts: []ParityScript{
+			{Filename: "deploy.sh", TargetOS: "linux"}, {Filename: "deploy.bat", TargetOS: "windows"},
+		}},
+	}}}
+
+	diffs := BuildParityDiffs(results)
+	if len(diffs) != 1 || diffs[0].Windows != "deploy.bat" || diffs[0].Linux != "deploy.sh" || diffs[0].Repository != "" {
+		t.Fatalf("Expected one unlabeled pair, got %+v", diffs)
+	}
+	if len(diffs[0].Rows) != 1 || diffs[0].Rows[0].Status != DiffSame {
+		t.Fatalf("Expected the commands aligned, got %+v", diffs[0].Rows)
+	}
+	want := DiffCommand{Line: 3, Text: `plmxml_import.exe -xml_file="100-Config\a.xml"`, Executable: "plmxml_import", Path: `100-Config\a.xml`, File: "100-Config/a.xml"}
+	if got := *diffs[0].Rows[0].Windows; got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
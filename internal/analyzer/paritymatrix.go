@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"sort"
+)
+
+// ParityMatrix counts the invocations of each executable per script, so reviewers see
+// executables called more often for one operating system than for the other
+type ParityMatrix struct {
+	Scripts     []ParityScript `json:"scripts"`
+	Executables []ParityRow    `json:"executables"`
+}
+
+// ParityScript is a column of the parity matrix
+type ParityScript struct {
+	Repository string `json:"repository,omitempty"` // set when several repositories are validated
+	Filename   string `json:"filename"`
+	TargetOS   string `json:"target_os"`
+}
+
+// ParityRow is an executable with its invocations per script, in the order of the
+// scripts of the matrix, and in all Windows and all Linux scripts
+type ParityRow struct {
+	Executable  string             `json:"executable"`
+	Calls       []int              `json:"calls"`
+	Windows     int                `json:"windows"`
+	Linux       int                `json:"linux"`
+	Invocations []ParityInvocation `json:"invocations,omitempty"` // where the executable is called
+}
+
+// ParityInvocation is a call of an executable in a script of the matrix
+type ParityInvocation struct {
+	Repository string `json:"repository,omitempty"`
+	Script     string `json:"script"`
+	Invocation
+}
+
+// buildParityMatrix returns the invocations of the executables of the scripts, the
+// executables sorted by name and the scripts in the order of the configuration
+func (r *run) buildParityMatrix(scripts []scriptDefinition) ParityMatrix {
+	matrix := ParityMatrix{Scripts: make([]ParityScript, 0, len(scripts))}
+	rows := make(map[string]*ParityRow)
+	for i, script := range scripts {
+		matrix.Scripts = append(matrix.Scripts, ParityScript{Filename: script.Filename, TargetOS: script.TargetOS})
+		for executable, invocations := range r.analysisResult.File[script.Filename].Executables {
+			row, ok := rows[executable]
+			if !ok {
+				row = &ParityRow{Executable: executable, Calls: make([]int, len(scripts))}
+				rows[executable] = row
+			}
+			calls := len(invocations)
+			row.Calls[i] += calls
+			for _, invocation := range invocations {
+				row.Invocations = append(row.Invocations, ParityInvocation{Script: script.Filename, Invocation: invocation})
+			}
+			switch script.TargetOS {
+			case "windows":
+				row.Windows += calls
+			case "linux":
+				row.Linux += calls
+			}
+		}
+	}
+
+	names := make([]string, 0, len(rows))
+	for executable := range rows {
+		names = append(names, executable)
+	}
+	sort.Strings(names)
+	matrix.Executables = make([]ParityRow, 0, len(names))
+	for _, executable := range names {
+		matrix.Executables = append(matrix.Executables, *rows[executable])
+	}
+	return matrix
+}
+
+// logCallCountDifferences logs the executables called by the scripts of both operating
+// systems a different number of times, e.g. an importer called twice on Linux and once
+// on Windows; executables missing for one of them are parity findings
+func (r *run) logCallCountDifferences(matrix ParityMatrix) {
+	for _, row := range matrix.Executables {
+		if row.Windows > 0 && row.Linux > 0 && row.Windows != row.Linux {
+			r.log.Info("Executable '{e}' is called {w} time(s) in Windows script(s) and {l} time(s) in Linux script(s)",
+				"e", row.Executable, "w", row.Windows, "l", row.Linux)
+		}
+	}
+}
+
+// MergeParityMatrices returns the parity matrix of the scripts of all results, each
+// script labeled with the name of its repository when there are several
+func MergeParityMatrices(results []RepositoryResult) ParityMatrix {
+	if len(results) == 1 {
+		return results[0].Result.Parity
+	}
+	var merged ParityMatrix
+	rows := make(map[string]*ParityRow)
+	for _, r := range results {
+		offset := len(merged.Scripts)
+		for _, script := range r.Result.Parity.Scripts {
+			script.Repository = r.Name
+			merged.Scripts = append(merged.Scripts, script)
+		}
+		for _, row := range r.Result.Parity.Executables {
+			if _, ok := rows[row.Executable]; !ok {
+				rows[row.Executable] = &ParityRow{Executable: row.Executable}
+			}
+			target := rows[row.Executable]
+			for len(target.Calls) < offset {
+				target.Calls = append(target.Calls, 0)
+			}
+			target.Calls = append(target.Calls, row.Calls...)
+			target.Windows += row.Windows
+			target.Linux += row.Linux
+			for _, invocation := range row.Invocations {
+				invocation.Repository = r.Name
+				target.Invocations = append(target.Invocations, invocation)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(rows))
+	for executable, row := range rows {
+		for len(row.Calls) < len(merged.Scripts) {
+			row.Calls = append(row.Calls, 0)
+		}
+		names = append(names, executable)
+	}
+	sort.Strings(names)
+	merged.Executables = make([]ParityRow, 0, len(names))
+	for _, executable := range names {
+		merged.Executables = append(merged.Executables, *rows[executable])
+	}
+	return merged
+}
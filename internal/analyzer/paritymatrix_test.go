@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildParityMatrix(t *testing.T) {
+	// What: Invocations are counted per script and summed per operating system, with their lines and commands
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	for i, line := range []string{"install_xml_stylesheet_datasets -input=\"a.txt\"", "install_xml_stylesheet_datasets -input=\"b.txt\"", "plmxml_import -xml_file=\"a.xml\""} {
+		testRun.trackExecutable("deploy.sh", line, i+1)
+	}
+	testRun.trackExecutable("deploy.bat", "install_xml_stylesheet_datasets.exe -input=\"a.txt\"", 4)
+
+	matrix := testRun.buildParityMatrix([]scriptDefinition{{Filename: "deploy.bat", TargetOS: "windows"}, {Filename: "deploy.sh", TargetOS: "linux"}})
+	want := []ParityRow{
+		{Executable: "install_xml_stylesheet_datasets", Calls: []int{1, 2}, Windows: 1, Linux: 2, Invocations: []ParityInvocation{
+			{Script: "deploy.bat", Invocation: Invocation{Line: 4, Command: `install_xml_stylesheet_datasets.exe -input="a.txt"`}},
+			{Script: "deploy.sh", Invocation: Invocation{Line: 1, Command: `install_xml_stylesheet_datasets -input="a.txt"`}},
+			{Script: "deploy.sh", Invocation: Invocation{Line: 2, Command: `install_xml_stylesheet_datasets -input="b.txt"`}},
+		}},
+		{Executable: "plmxml_import", Calls: []int{0, 1}, Linux: 1, Invocations: []ParityInvocation{
+			{Script: "deploy.sh", Invocation: Invocation{Line: 3, Command: `plmxml_import -xml_file="a.xml"`}},
+		}},
+	}
+	if !reflect.DeepEqual(matrix.Executables, want) {
+		t.Errorf("Expected %+v, got %+v", want, matrix.Executables)
+	}
+	if len(matrix.Scripts) != 2 || matrix.Scripts[0].Filename != "deploy.bat" || matrix.Scripts[1].TargetOS != "linux" {
+		t.Errorf("Unexpected scripts %+v", matrix.Scripts)
+	}
+}
+
+func TestMergeParityMatrices(t *testing.T) {
+	// What: The scripts of several repositories are labeled and executables of one only get zero calls elsewhere
+	results := []RepositoryResult{
+		{Name: "core", Result: Result{Parity: ParityMatrix{
+			Scripts:     []ParityScript{{Filename: "deploy.sh", TargetOS: "linux"}},
+			Executables: []ParityRow{{Executable: "plmxml_import", Calls: []int{2}, Linux: 2}},
+		}}},
+		{Name: "plant", Result: Result{Parity: ParityMatrix{
+			Scripts:     []ParityScript{{Filename: "deploy.bat", TargetOS: "windows"}},
+			Executables: []ParityRow{{Executable: "tem", Calls: []int{1}, Windows: 1, Invocations: []ParityInvocation{{Script: "deploy.bat", Invocation: Invocation{Line: 4, Command: "tem -update"}}}}},
+		}}},
+	}
+
+	merged := MergeParityMatrices(results)
+	want := []ParityRow{
+		{Executable: "plmxml_import", Calls: []int{2, 0}, Linux: 2},
+		{Executable: "tem", Calls: []int{0, 1}, Windows: 1, Invocations: []ParityInvocation{{Repository: "plant", Script: "deploy.bat", Invocation: Invocation{Line: 4, Command: "tem -update"}}}},
+	}
+	if !reflect.DeepEqual(merged.Executables, want) {
+		t.Errorf("Expected %+v, got %+v", want, merged.Executables)
+	}
+	if merged.Scripts[0].Repository != "core" || merged.Scripts[1].Repository != "plant" {
+		t.Errorf("Expected the scripts labeled with their repository, got %+v", merged.Scripts)
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// ParsedLine is a script line as classified by the parser
+type ParsedLine struct {
+	Number     int
+	Text       string     // the line with the templates rendered
+	Valid      []string   // paths of the path flags with a valid syntax
+	Invalid    string     // line with the problems of its path flags with an invalid syntax
+	Flags      []PathFlag // every path flag of the line, valid and invalid, in line order
+	Remote     []string   // URLs of the path flags
+	SkipReason string     // category of a line without path (SkipComment, ...), empty otherwise
+	Executable string     // executable called by the line
+}
+
+// ParseScript parses the content of a script for targetOS with the path parameters
+// and returns its lines, in line order, and the findings of the syntax checks. Only
+// the parser runs, nothing is read from the file system, so the parser can be fuzzed
+// and inspected without a repository.
+func (a *Analyzer) ParseScript(name, content, targetOS string, parameters []PathParameter) ([]ParsedLine, []Finding, error) {
+	return a.newRun().parseScript(name, content, targetOS, parameters)
+}
+
+// ParseScript parses a script with the default logger, see (*Analyzer).ParseScript
+func ParseScript(name, content, targetOS string, parameters []PathParameter) ([]ParsedLine, []Finding, error) {
+	return New(logger.Default()).ParseScript(name, content, targetOS, parameters)
+}
+
+// parseScript parses a script in the run, see (*Analyzer).ParseScript
+func (r *run) parseScript(name, content, targetOS string, parameters []PathParameter) ([]ParsedLine, []Finding, error) {
+	if err := ValidatePathParameters(parameters); err != nil {
+		return nil, nil, err
+	}
+	r.applyRuleset(Parameters{})
+	r.analysisResult = Result{File: map[string]Lines{name: newLines()}}
+	r.loggedFindingScripts = make(map[string][]string)
+	r.ignorePatternHits = make(map[string]int)
+	r.ownerRules = nil
+	r.maxFindings, r.failFast, r.stopReason = 0, false, ""
+	r.templateSettings = templating{}
+	r.flagRules, r.allowedExecutables, r.binPrefix, r.credentialFlags = nil, nil, nil, nil
+	r.listImportSettings, r.stylesheetImporterSettings = nil, nil
+	r.scriptWorkingDir, r.gnuLongOptions = "", false
+	r.pathParameters = pathParameterNames(parameters)
+	r.initializeRegexPatterns(r.pathParameters)
+	if err := r.applyParameterStyles(parameters); err != nil {
+		return nil, nil, withKind(KindConfig, err)
+	}
+
+	r.currentScript, r.currentScriptTargetOS = name, targetOS
+	r.parseScriptContent(name, content, targetOS)
+
+	lines := r.analysisResult.File[name]
+	invalid := lines.InvalidLines()
+	var parsed []ParsedLine
+	for number, text := range lines.Text {
+		line := ParsedLine{
+			Number:     number,
+			Text:       text,
+			Invalid:    invalid[number],
+			Flags:      lines.Flags[number],
+			SkipReason: lines.SkipReasons[number],
+			Executable: lines.Utility[number],
+		}
+		for _, flag := range line.Flags {
+			if flag.IsValid() {
+				line.Valid = append(line.Valid, flag.Path)
+			} else if flag.Remote {
+				line.Remote = append(line.Remote, flag.Path)
+			}
+		}
+		parsed = append(parsed, line)
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Number < parsed[j].Number })
+	return parsed, r.analysisResult.Findings, nil
+}
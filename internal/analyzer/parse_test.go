@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Tests and fuzz targets of the parser entry point
+
+var parseParameters = []PathParameter{{Name: "xml_file"}, {Name: "input", Style: "space_quoted"}}
+
+// What: Lines are returned in order with their classification, without reading the file system
+func TestParseScript(t *testing.T) {
+	logger.InitLogger(os.DevNull, "error")
+	content := "# import\nplmxml_import -xml_file=\"a.xml\"\nplmxml_import -xml_file=b.xml\n\ntem -update\n"
+	lines, findings, err := testRun.parseScript("deploy.sh", content, "linux", parseParameters)
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %+v", lines)
+	}
+	if lines[0].SkipReason != SkipComment || !reflect.DeepEqual(lines[1].Valid, []string{"a.xml"}) || lines[1].Executable != "plmxml_import" || lines[2].Invalid == "" || lines[3].SkipReason != SkipBlank {
+		t.Errorf("Unexpected lines %+v", lines)
+	}
+	if len(findings) != 1 || findings[0].Rule != RuleFlagNotQuoted || findings[0].Line != 3 {
+		t.Errorf("Expected the unquoted flag, got %+v", findings)
+	}
+}
+
+// What: Each path flag of a line is classified, a line can be valid and invalid with a finding per invalid flag
+func TestParseScript_FlagsOfALine(t *testing.T) {
+	logger.InitLogger(os.DevNull, "error")
+	content := "plmxml_import -input bare.txt -xml_file=\"a.xml\"\n" +
+		"plmxml_import -xml_file=\"b\\c.xml\" -input bare.txt\n" +
+		"plmxml_import -xml_file=\"d.xml\" -input \"e.txt\"\n"
+	lines, findings, err := testRun.parseScript("deploy.sh", content, "linux", parseParameters)
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+
+	first := lines[0]
+	if !reflect.DeepEqual(first.Valid, []string{"a.xml"}) || first.Invalid == "" {
+		t.Errorf("Expected line 1 valid and invalid, got %+v", first)
+	}
+	expected := []PathFlag{{Line: 1, Flag: "input", Column: 15, Rule: RuleFlagNotQuoted}, {Line: 1, Flag: "xml_file", Path: "a.xml", Column: 42}}
+	if !reflect.DeepEqual(first.Flags, expected) {
+		t.Errorf("Expected the flags of line 1 in line order %+v, got %+v", expected, first.Flags)
+	}
+
+	second := lines[1]
+	if second.Valid != nil || strings.Count(second.Invalid, "[") != 1 || len(second.Flags) != 2 {
+		t.Errorf("Expected line 2 invalid by both flags, got %+v", second)
+	}
+	if lines[2].Invalid != "" || !reflect.DeepEqual(lines[2].Valid, []string{"d.xml", "e.txt"}) {
+		t.Errorf("Expected line 3 valid by both flags, got %+v", lines[2])
+	}
+
+	var rules []string
+	for _, f := range findings {
+		rules = append(rules, fmt.Sprintf("%s:%d:%d", f.Rule, f.Line, f.Column))
+	}
+	if want := []string{"TCX001:1:15", "TCX002:2:26", "TCX001:2:35"}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("Expected a finding per invalid flag %v, got %v", want, rules)
+	}
+}
+
+// What: Invalid path parameters are refused before parsing
+func TestParseScript_InvalidParameters(t *testing.T) {
+	if _, _, err := testRun.parseScript("deploy.sh", "", "linux", []PathParameter{{Name: "x", Regex: "("}}); err == nil {
+		t.Error("Expected an invalid regex to be refused")
+	}
+}
+
+// What: A line longer than the scanner limit ends the parsing with TCX004 instead of silently
+func TestParseScript_LongLine(t *testing.T) {
+	logger.InitLogger(os.DevNull, "error")
+	content := "tem -update\n" + strings.Repeat("a", maxScriptLineLength+1) + "\ntem -update\n"
+	lines, findings, _ := testRun.parseScript("deploy.sh", content, "linux", parseParameters)
+	if len(lines) != 1 {
+		t.Errorf("Expected the lines before the long line, got %d", len(lines))
+	}
+	if len(findings) != 1 || findings[0].Rule != RuleScriptUnreadable || findings[0].Line != 2 {
+		t.Errorf("Expected TCX004 on line 2, got %+v", findings)
+	}
+}
+
+// FuzzParseScript checks the parser neither panics nor hangs on arbitrary content,
+// and that it accounts for every line: lines with path flags are not skipped, and
+// lines are valid or invalid by their flags only
+func FuzzParseScript(f *testing.F) {
+	logger.InitLogger(os.DevNull, "error")
+	for _, seed := range []string{
+		"plmxml_import -xml_file=\"a.xml\"\n",
+		"plmxml_import -xml_file=\"unterminated\n",
+		"plmxml_import -input \"a b.xml\" -xml_file='c.xml' \\\n  -xml_file=\"d.xml\"\n",
+		"cat <<EOF\n-xml_file=\"in.xml\"\nEOF\n",
+		"for f in *.xml; do plmxml_import -xml_file=\"$f\"; done\n",
+		"\x00\xff\xfe-xml_file=\"\x01\"\r\n",
+		"if [ -f a ]; then\n  plmxml_import -xml_file=\"{{ site }}/a.xml\"\nfi\n",
+	} {
+		f.Add(seed, false)
+		f.Add(strings.ReplaceAll(seed, "\n", "\r\n"), true)
+	}
+	f.Fuzz(func(t *testing.T, content string, windows bool) {
+		targetOS := "linux"
+		if windows {
+			targetOS = "windows"
+		}
+		lines, _, err := testRun.parseScript("script", content, targetOS, parseParameters)
+		if err != nil {
+			t.Fatalf("ParseScript failed: %v", err)
+		}
+		for i, line := range lines {
+			if line.Number != i+1 {
+				t.Fatalf("Line %d returned as line %d", i+1, line.Number)
+			}
+			if line.Valid != nil && line.SkipReason != "" {
+				t.Errorf("Line %d is valid and skipped: %+v", line.Number, line)
+			}
+			if (line.Valid != nil || line.Invalid != "") && len(line.Flags) == 0 {
+				t.Errorf("Line %d is classified without path flags: %+v", line.Number, line)
+			}
+		}
+		if utf8.ValidString(content) && len(lines) > strings.Count(content, "\n")+1 {
+			t.Errorf("Got %d lines of %d", len(lines), strings.Count(content, "\n")+1)
+		}
+	})
+}
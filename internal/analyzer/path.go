@@ -2,51 +2,134 @@ package analyzer
 
 import (
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
+	"regexp"
 	"strings"
-
-	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
 )
 
-func checkFilePathsInScript(scriptFile string, lines map[int]string) {
-
-	logger.Debug("checking file paths for '{s}'", "s", scriptFile)
+func (r *run) checkFilePathsInScript(scriptFile string, lines []PathFlag) {
 
-	// sort by line number and check
-	si := make([]int, 0, len(lines))
-	for i := range lines {
-		si = append(si, i)
-	}
-	sort.Ints(si)
+	r.log.Debug("checking file paths for '{s}'", "s", scriptFile)
 
 	hasErrors := false
-	for _, i := range si {
-		if fileExists(lines[i]) {
-			logger.Info("'{s}' line '{ln}' is valid: file path '{fp}' exists", "s", scriptFile, "ln", i, "fp", lines[i])
+	for _, f := range lines {
+		i := f.Line
+		if r.isArtifactReference(f.Path) {
+			r.log.Debug("'{s}' line '{ln}': '{fp}' is resolved in the artifact repository", "s", scriptFile, "ln", i, "fp", f.Path)
+			continue
+		}
+		if r.ignoredFor(CheckMissing, f.Path) {
+			continue
+		}
+		if r.fileExists(f.Path) && r.isDirectoryReference(f.Path) && !r.directoryExists(r.localPath(f.Path)) {
+			r.reportFinding(Finding{Rule: RuleMissingFile, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path},
+				"'{s}' line '{ln}' is invalid: '{fp}' is not a directory", "s", scriptFile, "ln", i, "fp", f.Path)
+			hasErrors = true
+			r.recordMissing(f.Path)
+		} else if r.fileExists(f.Path) {
+			r.log.Info("'{s}' line '{ln}' is valid: file path '{fp}' exists", "s", scriptFile, "ln", i, "fp", f.Path)
 		} else {
-			logger.Error("'{s}' line '{ln}' is invalid: '{fp}' not found on file system", "s", scriptFile, "ln", i, "fp", lines[i])
+			if target, deleted := r.branchChange(f.Path); deleted {
+				r.reportBranchChange(scriptFile, f, target)
+			} else {
+				r.reportFinding(Finding{Rule: RuleMissingFile, Script: scriptFile, Line: i, Column: f.Column, Path: f.Path},
+					"'{s}' line '{ln}' is invalid: '{fp}' not found on file system", "s", scriptFile, "ln", i, "fp", f.Path)
+			}
 			hasErrors = true
+			r.recordMissing(f.Path)
 		}
 	}
 
 	if !hasErrors && len(lines) > 0 {
-		logger.Info("All file paths exist on the file system")
+		r.log.Info("All file paths exist on the file system")
 	} else if len(lines) == 0 {
-		logger.Info("No file paths to check")
+		r.log.Info("No file paths to check")
+	}
+}
+
+// recordMissing adds a referenced path not found on the file system to the result
+// of the script being processed
+func (r *run) recordMissing(path string) {
+	if result, ok := r.analysisResult.File[r.currentScript]; ok {
+		result.Missing = append(result.Missing, path)
+		r.analysisResult.File[r.currentScript] = result
 	}
 }
 
-func fileExists(path string) bool {
-	path = strings.ReplaceAll(path, convertFrom, convertTo)
-	fullPath := filepath.Join(sourceCodeRoot, path)
-	logger.Debug("fullPath: '{f}'", "f", fullPath)
+func (r *run) fileExists(path string) bool {
+	path = r.localPath(path)
+	if r.remoteTree != nil {
+		return r.remoteExists(path) || r.archiveExists(path)
+	}
+	fullPath := filepath.Join(r.sourceCodeRoot, path)
+	r.log.Debug("fullPath: '{f}'", "f", fullPath)
 	_, err := os.Stat(fullPath)
 	if err == nil {
 		return true
 	}
-	if os.IsNotExist(err) {
-		return false
+	// Archived scripts also reference the files shipped with them
+	return r.archiveExists(path)
+}
+
+var driveLetterRegex = regexp.MustCompile(`^([A-Za-z]):`)
+
+// toSlash converts both separator styles to forward slashes
+func toSlash(p string) string {
+	return strings.ReplaceAll(p, `\`, `/`)
+}
+
+// isAbsoluteReference reports whether a script path is absolute for either target OS
+// (/opt/..., C:\..., \\server\share\...)
+func isAbsoluteReference(p string) bool {
+	normalized := toSlash(p)
+	return strings.HasPrefix(normalized, "/") || driveLetterRegex.MatchString(normalized)
+}
+
+// isAllowedExternalPath reports whether the path starts with one of the configured
+// 'allowed_external_paths' entries. Comparison is separator and case insensitive.
+func (r *run) isAllowedExternalPath(p string) bool {
+	normalized := strings.ToLower(toSlash(p))
+	for _, allowed := range r.allowedExternalPaths {
+		prefix := strings.ToLower(strings.TrimRight(toSlash(allowed), "/"))
+		if prefix != "" && (normalized == prefix || strings.HasPrefix(normalized, prefix+"/")) {
+			return true
+		}
 	}
 	return false
 }
+
+// pathEscapesRoot reports whether a script path resolves outside the source code root,
+// either through '..' segments or by being an absolute path to another location.
+func (r *run) pathEscapesRoot(p string) bool {
+	normalized := toSlash(p)
+	if isAbsoluteReference(normalized) {
+		root := toSlash(r.sourceCodeRoot)
+		if root == "" || !isAbsoluteReference(root) {
+			return true
+		}
+		rel := path.Clean(normalized)
+		root = path.Clean(root)
+		return !(strings.EqualFold(rel, root) || strings.HasPrefix(strings.ToLower(rel), strings.ToLower(root)+"/"))
+	}
+	cleaned := path.Clean(normalized)
+	return cleaned == ".." || strings.HasPrefix(cleaned, "../")
+}
+
+// checkPathEscapes reports referenced paths resolving outside the source code root,
+// unless they are allowed in 'allowed_external_paths'.
+func (r *run) checkPathEscapes(scriptFile string, lines []PathFlag) {
+	r.log.Debug("checking for paths escaping the source code root in '{s}'", "s", scriptFile)
+
+	for _, f := range lines {
+		if !r.pathEscapesRoot(f.Path) {
+			continue
+		}
+		if r.isAllowedExternalPath(f.Path) {
+			r.log.Info("'{s}' line '{ln}': '{fp}' is outside the source code root but allowed", "s", scriptFile, "ln", f.Line, "fp", f.Path)
+			continue
+		}
+		r.reportFinding(Finding{Rule: RulePathEscapesRoot, Script: scriptFile, Line: f.Line, Column: f.Column, Path: f.Path, Suggestion: "reference the file relative to source_code_root or add it to allowed_external_paths"},
+			"'{s}' line '{ln}' is invalid: '{fp}' resolves outside the source code root", "s", scriptFile, "ln", f.Line, "fp", f.Path)
+	}
+}
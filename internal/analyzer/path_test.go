@@ -31,12 +31,12 @@ func TestFileExists_True(t *testing.T) {
 	}
 
 	// Set sourceCodeRoot to temp dir for test
-	originalRoot := sourceCodeRoot
-	sourceCodeRoot = tmpDir
-	defer func() { sourceCodeRoot = originalRoot }()
+	originalRoot := testRun.sourceCodeRoot
+	testRun.sourceCodeRoot = tmpDir
+	defer func() { testRun.sourceCodeRoot = originalRoot }()
 
 	// Test
-	result := fileExists("testfile.txt")
+	result := testRun.fileExists("testfile.txt")
 	if !result {
 		t.Errorf("Expected fileExists to return true for existing file")
 	}
@@ -51,13 +51,78 @@ func TestFileExists_False(t *testing.T) {
 	defer os.RemoveAll(tmpDir)
 
 	// Set sourceCodeRoot to temp dir for test
-	originalRoot := sourceCodeRoot
-	sourceCodeRoot = tmpDir
-	defer func() { sourceCodeRoot = originalRoot }()
+	originalRoot := testRun.sourceCodeRoot
+	testRun.sourceCodeRoot = tmpDir
+	defer func() { testRun.sourceCodeRoot = originalRoot }()
 
 	// Test with non-existent file
-	result := fileExists("nonexistent.txt")
+	result := testRun.fileExists("nonexistent.txt")
 	if result {
 		t.Errorf("Expected fileExists to return false for missing file")
 	}
 }
+
+func TestCheckFilePathsInScript_RecordsMissing(t *testing.T) {
+	// What: Paths not found on the file system are recorded in line order
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "found.xml"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	originalRoot, originalScript, originalResult := testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult
+	defer func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.analysisResult = originalRoot, originalScript, originalResult
+	}()
+	testRun.sourceCodeRoot, testRun.currentScript = tmpDir, "deploy.sh"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+
+	testRun.checkFilePathsInScript("deploy.sh", pathFlags(map[int]string{9: "z.xml", 2: "found.xml", 4: "a.xml"}))
+
+	missing := testRun.analysisResult.File["deploy.sh"].Missing
+	if len(missing) != 2 || missing[0] != "a.xml" || missing[1] != "z.xml" {
+		t.Errorf("Expected missing [a.xml z.xml], got %v", missing)
+	}
+}
+
+// Tests for pathEscapesRoot() and isAllowedExternalPath()
+
+func TestPathEscapesRoot(t *testing.T) {
+	originalRoot := testRun.sourceCodeRoot
+	testRun.sourceCodeRoot = "/repo/config"
+	defer func() { testRun.sourceCodeRoot = originalRoot }()
+
+	cases := map[string]bool{
+		"100-Preferences/prefs.xml":          false,
+		"100-Preferences/../200-Other/x.xml": false,
+		"../outside.xml":                     true,
+		`100-Preferences\..\..\outside.xml`:  true,
+		"/opt/tc/data/file.xml":              true,
+		`C:\Siemens\data\file.xml`:           true,
+		`\\server\share\file.xml`:            true,
+		"/repo/config/100-Preferences/a.xml": false,
+		"/repo/configuration/a.xml":          true,
+	}
+	for p, expected := range cases {
+		if result := testRun.pathEscapesRoot(p); result != expected {
+			t.Errorf("pathEscapesRoot(%q) = %v, want %v", p, result, expected)
+		}
+	}
+}
+
+func TestIsAllowedExternalPath(t *testing.T) {
+	original := testRun.allowedExternalPaths
+	testRun.allowedExternalPaths = []string{`C:\Siemens\TC_DATA`, "/opt/tc/"}
+	defer func() { testRun.allowedExternalPaths = original }()
+
+	cases := map[string]bool{
+		`c:\siemens\tc_data\file.xml`: true,
+		"/opt/tc/file.xml":            true,
+		"/opt/tcother/file.xml":       false,
+		`D:\data\file.xml`:            false,
+	}
+	for p, expected := range cases {
+		if result := testRun.isAllowedExternalPath(p); result != expected {
+			t.Errorf("isAllowedExternalPath(%q) = %v, want %v", p, result, expected)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"runtime"
+	"strings"
+)
+
+// hostOS is the OS the analysis runs on, paths are rendered for it to access files
+var hostOS = runtime.GOOS
+
+// scriptPath is a path parsed from its notation on an OS into segments, so it can be
+// rendered for another OS without touching characters that are not separators:
+// Windows paths are separated by \ (and /), Linux paths by / only, a \ in a Linux path
+// is part of the name or an escape.
+type scriptPath struct {
+	volume   string // drive ("C:") or UNC share ("\\server\share") of Windows paths
+	rooted   bool   // starts at the root of the volume or file system
+	segments []string
+}
+
+// parsePath parses a path written for targetOS ("windows" or "linux")
+func parsePath(path, targetOS string) scriptPath {
+	var p scriptPath
+	separators := "/"
+	if targetOS == "windows" {
+		separators = `\/`
+		if match := uncPathRegex.FindString(path); match != "" {
+			// \\server\share
+			rest := path[len(match):]
+			share := rest
+			if i := strings.IndexAny(rest, separators); i >= 0 {
+				share, rest = rest[:i], rest[i:]
+			} else {
+				rest = ""
+			}
+			p.volume, path = match+share, rest
+		} else if len(path) >= 2 && path[1] == ':' && isLetter(path[0]) {
+			p.volume, path = path[:2], path[2:]
+		}
+	}
+	p.rooted = path != "" && strings.ContainsRune(separators, rune(path[0]))
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		p.segments = append(p.segments, segment)
+	}
+	return p
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// render returns the path in the notation of targetOS
+func (p scriptPath) render(targetOS string) string {
+	separator := "/"
+	if targetOS == "windows" {
+		separator = `\`
+	}
+	var b strings.Builder
+	b.WriteString(p.volume)
+	if p.rooted {
+		b.WriteString(separator)
+	}
+	b.WriteString(strings.Join(p.segments, separator))
+	return b.String()
+}
+
+// slash returns the path with forward slashes, the notation paths are compared in
+// across scripts and matched against ignore patterns
+func (p scriptPath) slash() string {
+	return p.render("linux")
+}
+
+// join returns the path with the segments of the relative path appended
+func (p scriptPath) join(rel scriptPath) scriptPath {
+	p.segments = append(append([]string{}, p.segments...), rel.segments...)
+	return p
+}
+
+// clean resolves the '.' and '..' segments; ok is false when a '..' leaves the start
+// of the path
+func (p scriptPath) clean() (scriptPath, bool) {
+	var segments []string
+	for _, segment := range p.segments {
+		switch segment {
+		case ".":
+		case "..":
+			if len(segments) == 0 {
+				return p, false
+			}
+			segments = segments[:len(segments)-1]
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	p.segments = segments
+	return p, true
+}
+
+// normalize resolves the '.' and '..' segments, so that cosmetic differences such as
+// "./a/../b.xml" do not keep a path from matching "b.xml"; a path whose '..' leave its
+// start is kept as written, the root escape check reports it
+func (p scriptPath) normalize() scriptPath {
+	if cleaned, ok := p.clean(); ok {
+		return cleaned
+	}
+	return p
+}
+
+// absolute reports whether the path starts at a volume or the root of the file system
+func (p scriptPath) absolute() bool {
+	return p.volume != "" || p.rooted
+}
+
+// relativeToRoot resolves a path referenced by a script for targetOS relative to the
+// source code root: relative paths are below the root, absolute paths are made relative
+// when they lie within it (compared case insensitively, like pathEscapesRoot). ok is
+// false when the path resolves outside the root.
+func (r *run) relativeToRoot(path, targetOS string) (scriptPath, bool) {
+	p, ok := parsePath(path, targetOS).clean()
+	if !ok || !p.absolute() {
+		return scriptPath{segments: p.segments}, ok
+	}
+	root, ok := parsePath(r.sourceCodeRoot, hostOS).clean()
+	if !ok || !root.absolute() || !strings.EqualFold(p.volume, root.volume) || len(p.segments) < len(root.segments) {
+		return scriptPath{}, false
+	}
+	for i, segment := range root.segments {
+		if !strings.EqualFold(segment, p.segments[i]) {
+			return scriptPath{}, false
+		}
+	}
+	return scriptPath{segments: p.segments[len(root.segments):]}, true
+}
+
+// localPath renders a path referenced by the script being processed for the host OS
+func (r *run) localPath(path string) string {
+	return parsePath(path, r.currentScriptTargetOS).normalize().render(hostOS)
+}
+
+// localizeFlags renders the paths of path flags for the host OS
+func (r *run) localizeFlags(flags []PathFlag) []PathFlag {
+	localized := make([]PathFlag, len(flags))
+	for i, flag := range flags {
+		flag.Path = r.localPath(flag.Path)
+		localized[i] = flag
+	}
+	return localized
+}
+
+// slashPath returns a path referenced by a script for targetOS with forward slashes
+func slashPath(path, targetOS string) string {
+	return parsePath(path, targetOS).normalize().slash()
+}
+
+// normalizedReference returns a relative path referenced by a script for targetOS as it
+// is compared with the repository files, written for targetOS: without "./" segments,
+// duplicate separators and resolved '..'. changed is false when the path is written so
+// already, a trailing separator apart, which marks a directory reference.
+func normalizedReference(path, targetOS string) (string, bool) {
+	p := parsePath(path, targetOS)
+	if p.absolute() {
+		return path, false
+	}
+	normalized := p.normalize().render(targetOS)
+	if normalized == "" {
+		normalized = "."
+	}
+	separators := "/"
+	if targetOS == "windows" {
+		separators = `\/`
+	}
+	written := path
+	for len(written) > 1 && strings.ContainsRune(separators, rune(written[len(written)-1])) {
+		written = written[:len(written)-1]
+	}
+	return normalized, written != normalized
+}
+
+// checkPathNormalization reports the paths of a script that only match the repository
+// files once normalized, so authors can write them as they are compared
+func (r *run) checkPathNormalization(scriptFile string, lines []PathFlag) {
+	for _, f := range lines {
+		normalized, changed := normalizedReference(f.Path, r.currentScriptTargetOS)
+		if !changed {
+			continue
+		}
+		r.reportFinding(Finding{Rule: RulePathNotNormalized, Script: scriptFile, Line: f.Line, Column: f.Column, Path: f.Path, Suggestion: "write the path as '" + normalized + "'"},
+			"'{s}' line '{ln}': '{fp}' is compared as '{n}'", "s", scriptFile, "ln", f.Line, "fp", f.Path, "n", normalized)
+	}
+}
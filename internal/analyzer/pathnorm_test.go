@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"runtime"
+	"testing"
+)
+
+// What: Paths are parsed in the notation of the script target OS and rendered for another OS
+func TestParsePath_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		from, to string
+		want     string
+	}{
+		{"windows to linux", `100-Config\sub\a.xml`, "windows", "linux", "100-Config/sub/a.xml"},
+		{"windows mixed separators", `100-Config/sub\a.xml`, "windows", "linux", "100-Config/sub/a.xml"},
+		{"linux to windows", "100-Config/sub/a.xml", "linux", "windows", `100-Config\sub\a.xml`},
+		{"linux backslash is part of the name", `100-Config/a\ b.xml`, "linux", "windows", `100-Config\a\ b.xml`},
+		{"linux to linux keeps backslash", `100-Config/a\b.xml`, "linux", "linux", `100-Config/a\b.xml`},
+		{"repeated separators", `100-Config\\sub\a.xml`, "windows", "linux", "100-Config/sub/a.xml"},
+		{"drive letter", `C:\Temp\a.xml`, "windows", "windows", `C:\Temp\a.xml`},
+		{"UNC path", `\\server\share\dir\a.xml`, "windows", "windows", `\\server\share\dir\a.xml`},
+		{"rooted linux path", "/opt/tc/a.xml", "linux", "windows", `\opt\tc\a.xml`},
+		{"trailing separator dropped", `130-Workflows\`, "windows", "linux", "130-Workflows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePath(tt.path, tt.from).render(tt.to); got != tt.want {
+				t.Errorf("parsePath(%q, %q).render(%q) = %q, want %q", tt.path, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// What: Drive letters and UNC shares are parsed as the volume of Windows paths only
+func TestParsePath_Volume(t *testing.T) {
+	if p := parsePath(`C:\Temp`, "windows"); p.volume != "C:" || !p.rooted {
+		t.Errorf("Expected volume 'C:' and rooted, got %+v", p)
+	}
+	if p := parsePath(`\\server\share\a.xml`, "windows"); p.volume != `\\server\share` || len(p.segments) != 1 {
+		t.Errorf("Expected volume '\\\\server\\share' and one segment, got %+v", p)
+	}
+	if p := parsePath("C:/Temp", "linux"); p.volume != "" || p.segments[0] != "C:" {
+		t.Errorf("Expected no volume for a Linux path, got %+v", p)
+	}
+}
+
+// What: slashPath writes paths of either OS with forward slashes
+func TestSlashPath(t *testing.T) {
+	if got := slashPath(`100-Config\a.xml`, "windows"); got != "100-Config/a.xml" {
+		t.Errorf("Expected '100-Config/a.xml', got %q", got)
+	}
+	if got := slashPath(`100-Config/a\b.xml`, "linux"); got != `100-Config/a\b.xml` {
+		t.Errorf("Expected backslash kept in Linux path, got %q", got)
+	}
+}
+
+// What: localPath and localizeLines render paths of the current script for the host OS
+func TestLocalPath(t *testing.T) {
+	originalOS := testRun.currentScriptTargetOS
+	defer func() { testRun.currentScriptTargetOS, hostOS = originalOS, runtime.GOOS }()
+
+	testRun.currentScriptTargetOS, hostOS = "windows", "linux"
+	if got := testRun.localPath(`100-Config\a.xml`); got != "100-Config/a.xml" {
+		t.Errorf("Expected '100-Config/a.xml', got %q", got)
+	}
+
+	testRun.currentScriptTargetOS, hostOS = "linux", "windows"
+	lines := testRun.localizeFlags(pathFlags(map[int]string{1: "100-Config/a.xml", 2: "no-separator"}))
+	if lines[0].Path != `100-Config\a.xml` || lines[1].Path != "no-separator" || lines[1].Line != 2 {
+		t.Errorf("Unexpected localized lines: %v", lines)
+	}
+}
+
+// What: Paths are resolved relative to the source code root, absolute ones only within it
+func TestRelativeToRoot(t *testing.T) {
+	originalRoot := testRun.sourceCodeRoot
+	defer func() { testRun.sourceCodeRoot, hostOS = originalRoot, runtime.GOOS }()
+	testRun.sourceCodeRoot, hostOS = "/deploy/repo", "linux"
+
+	tests := []struct {
+		path, targetOS, want string
+		ok                   bool
+	}{
+		{`200-Stylesheets\`, "windows", "200-Stylesheets", true},
+		{"./200-Stylesheets/../200-Stylesheets", "linux", "200-Stylesheets", true},
+		{"/deploy/REPO/200-Stylesheets", "linux", "200-Stylesheets", true},
+		{"", "linux", "", true},
+		{"/opt/stylesheets", "linux", "", false},
+		{`C:\deploy\repo\200-Stylesheets`, "windows", "", false},
+		{"../200-Stylesheets", "linux", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := testRun.relativeToRoot(tt.path, tt.targetOS)
+		if ok != tt.ok || (ok && got.slash() != tt.want) {
+			t.Errorf("relativeToRoot(%q, %s) = %q, %v, want %q, %v", tt.path, tt.targetOS, got.slash(), ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// What: Cosmetic differences are resolved before matching, paths leaving their start are kept
+func TestSlashPath_Normalizes(t *testing.T) {
+	tests := []struct {
+		path, targetOS, want string
+	}{
+		{"./100-Config/a.xml", "linux", "100-Config/a.xml"},
+		{"100-Config//sub/../a.xml", "linux", "100-Config/a.xml"},
+		{`.\100-Config\\a.xml`, "windows", "100-Config/a.xml"},
+		{"100-Config/", "linux", "100-Config"},
+		{"../outside.xml", "linux", "../outside.xml"},
+		{"/opt/./conf/a.xml", "linux", "/opt/conf/a.xml"},
+	}
+	for _, tt := range tests {
+		if got := slashPath(tt.path, tt.targetOS); got != tt.want {
+			t.Errorf("slashPath(%q, %s) = %q, want %q", tt.path, tt.targetOS, got, tt.want)
+		}
+	}
+}
+
+// What: Paths changed by the normalization are reported with the path as compared, trailing separators are not
+func TestCheckPathNormalization(t *testing.T) {
+	originalScript, originalOS, originalResult := testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult
+	defer func() {
+		testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult = originalScript, originalOS, originalResult
+	}()
+	testRun.currentScript, testRun.currentScriptTargetOS = "deploy.bat", "windows"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+
+	testRun.checkPathNormalization("deploy.bat", pathFlags(map[int]string{
+		1: `100-Config\a.xml`,
+		2: `.\100-Config\a.xml`,
+		3: `100-Config\\sub\..\a.xml`,
+		4: `200-Stylesheets\`,
+		5: `C:\Siemens\.\TC_DATA`,
+		6: `.\`,
+	}))
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	for i, ln := range []int{2, 3} {
+		if findings[i].Rule != RulePathNotNormalized || findings[i].Line != ln || findings[i].Suggestion != `write the path as '100-Config\a.xml'` {
+			t.Errorf("Expected the path of line %d compared as '100-Config\\a.xml', got %+v", ln, findings[i])
+		}
+	}
+}
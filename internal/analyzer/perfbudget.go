@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PerfBudgetTotal is the 'perf_budget' key of the duration of the whole run
+const PerfBudgetTotal = "total"
+
+// ValidatePerfBudget checks that the budget keys are analysis phases or 'total' and
+// the durations positive
+func ValidatePerfBudget(budget map[string]time.Duration) error {
+	for phase, limit := range budget {
+		if phase != PerfBudgetTotal && !IsPhase(phase) {
+			return fmt.Errorf("invalid 'perf_budget' phase '%s' (must be 'total' or an analysis phase: %s)", phase, strings.Join(phases, ", "))
+		}
+		if limit <= 0 {
+			return fmt.Errorf("invalid 'perf_budget' of '%s': %s (must be positive)", phase, limit)
+		}
+	}
+	return nil
+}
+
+// ParsePerfBudget parses a budget given on the command line as comma-separated
+// phase=duration pairs, e.g. "traversal=2s,total=30s"
+func ParsePerfBudget(value string) (map[string]time.Duration, error) {
+	budget := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		phase, duration, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid perf budget '%s' (must be phase=duration)", pair)
+		}
+		limit, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid perf budget duration of '%s': %w", phase, err)
+		}
+		budget[strings.TrimSpace(phase)] = limit
+	}
+	return budget, ValidatePerfBudget(budget)
+}
+
+// phaseDurations adds up the durations of each phase over the scripts and the run
+func (r *run) phaseDurations(scripts []scriptDefinition) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for _, script := range scripts {
+		for _, t := range r.analysisResult.File[script.Filename].Timings {
+			durations[t.Phase] += t.Duration
+		}
+	}
+	for _, t := range r.analysisResult.Timings {
+		durations[t.Phase] += t.Duration
+	}
+	return durations
+}
+
+// evaluatePerfBudget compares the phase durations and the duration of the run with
+// the budget. Returns one message per exceeded budget, in phase order.
+func evaluatePerfBudget(durations map[string]time.Duration, total time.Duration, budget map[string]time.Duration) []string {
+	var violations []string
+	keys := make([]string, 0, len(budget))
+	for phase := range budget {
+		keys = append(keys, phase)
+	}
+	sort.Strings(keys)
+	for _, phase := range keys {
+		elapsed := durations[phase]
+		if phase == PerfBudgetTotal {
+			elapsed = total
+		}
+		if limit := budget[phase]; elapsed > limit {
+			violations = append(violations, fmt.Sprintf("'%s' took %s, exceeding 'perf_budget' of %s", phase, elapsed.Round(time.Millisecond), limit))
+		}
+	}
+	return violations
+}
+
+// checkPerfBudget logs the budget evaluation of a run started at start and returns an
+// error if any phase exceeds its budget
+func (r *run) checkPerfBudget(scripts []scriptDefinition, budget map[string]time.Duration, start time.Time) error {
+	if len(budget) == 0 {
+		return nil
+	}
+
+	r.log.Heading(" ")
+	r.log.Separate("PERFORMANCE BUDGET CHECK")
+	r.log.Separate("=====================================")
+
+	violations := evaluatePerfBudget(r.phaseDurations(scripts), time.Since(start), budget)
+	for _, violation := range violations {
+		r.reportFinding(Finding{Rule: RulePerfBudgetExceeded}, violation)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d performance budget(s) exceeded", len(violations))
+	}
+	r.log.Separate("none")
+	return nil
+}
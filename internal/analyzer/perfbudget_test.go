@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests for the performance budget of the analysis phases
+
+func setupPerfBudgetTest() []scriptDefinition {
+	win, linux := newLines(), newLines()
+	win.Timings = []PhaseTiming{{PhaseSyntax, 10 * time.Millisecond}, {PhaseTraversal, 300 * time.Millisecond}}
+	linux.Timings = []PhaseTiming{{PhaseSyntax, 20 * time.Millisecond}, {PhaseTraversal, 400 * time.Millisecond}}
+
+	testRun.analysisResult = Result{
+		File:    map[string]Lines{"deploy.bat": win, "deploy.sh": linux},
+		Timings: []PhaseTiming{{PhaseParity, 5 * time.Millisecond}},
+	}
+	return []scriptDefinition{
+		{Filename: "deploy.bat", TargetOS: "windows"},
+		{Filename: "deploy.sh", TargetOS: "linux"},
+	}
+}
+
+func TestPhaseDurations(t *testing.T) {
+	durations := testRun.phaseDurations(setupPerfBudgetTest())
+
+	if durations[PhaseSyntax] != 30*time.Millisecond {
+		t.Errorf("Expected the syntax durations of both scripts added up to 30ms, got %v", durations[PhaseSyntax])
+	}
+	if durations[PhaseTraversal] != 700*time.Millisecond {
+		t.Errorf("Expected the traversal durations added up to 700ms, got %v", durations[PhaseTraversal])
+	}
+	if durations[PhaseParity] != 5*time.Millisecond {
+		t.Errorf("Expected the parity duration of the run, got %v", durations[PhaseParity])
+	}
+}
+
+func TestEvaluatePerfBudget(t *testing.T) {
+	durations := testRun.phaseDurations(setupPerfBudgetTest())
+
+	within := map[string]time.Duration{PhaseTraversal: time.Second, PerfBudgetTotal: 2 * time.Second}
+	if violations := evaluatePerfBudget(durations, time.Second, within); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+
+	exceeded := map[string]time.Duration{
+		PhaseTraversal:  500 * time.Millisecond,
+		PhaseSyntax:     time.Second,
+		PerfBudgetTotal: 500 * time.Millisecond,
+	}
+	violations := evaluatePerfBudget(durations, time.Second, exceeded)
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %d: %v", len(violations), violations)
+	}
+	if violations[0] != "'total' took 1s, exceeding 'perf_budget' of 500ms" {
+		t.Errorf("Unexpected total violation %q", violations[0])
+	}
+	if !strings.HasPrefix(violations[1], "'traversal' took 700ms") {
+		t.Errorf("Expected traversal violation, got %q", violations[1])
+	}
+}
+
+func TestEvaluatePerfBudget_PhaseNotRun(t *testing.T) {
+	// A phase that did not run, e.g. the remote listing without remote, uses no budget
+	budget := map[string]time.Duration{PhaseRemoteListing: time.Nanosecond}
+	if violations := evaluatePerfBudget(testRun.phaseDurations(setupPerfBudgetTest()), 0, budget); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestCheckPerfBudget(t *testing.T) {
+	scripts := setupPerfBudgetTest()
+	if err := testRun.checkPerfBudget(scripts, nil, time.Now()); err != nil {
+		t.Errorf("Expected no error without budget, got %v", err)
+	}
+
+	scripts = setupPerfBudgetTest()
+	err := testRun.checkPerfBudget(scripts, map[string]time.Duration{PhaseTraversal: time.Millisecond}, time.Now())
+	if err == nil || err.Error() != "1 performance budget(s) exceeded" {
+		t.Errorf("Expected exceeded budget error, got %v", err)
+	}
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 1 || findings[0].Rule != RulePerfBudgetExceeded || findings[0].Severity != SeverityError {
+		t.Errorf("Expected one error finding %s, got %+v", RulePerfBudgetExceeded, findings)
+	}
+}
+
+func TestValidatePerfBudget(t *testing.T) {
+	if err := ValidatePerfBudget(map[string]time.Duration{PhaseStylesheet: time.Second, PerfBudgetTotal: time.Minute}); err != nil {
+		t.Errorf("Expected valid budget, got %v", err)
+	}
+	if err := ValidatePerfBudget(map[string]time.Duration{"walking": time.Second}); err == nil || !strings.Contains(err.Error(), "invalid 'perf_budget' phase 'walking'") {
+		t.Errorf("Expected unknown phase error, got %v", err)
+	}
+	if err := ValidatePerfBudget(map[string]time.Duration{PhaseSyntax: 0}); err == nil || !strings.Contains(err.Error(), "must be positive") {
+		t.Errorf("Expected non-positive duration error, got %v", err)
+	}
+}
+
+func TestParsePerfBudget(t *testing.T) {
+	budget, err := ParsePerfBudget("traversal=2s, path check=500ms,total=1m")
+	if err != nil {
+		t.Fatalf("Expected valid budget, got %v", err)
+	}
+	expected := map[string]time.Duration{PhaseTraversal: 2 * time.Second, PhasePathCheck: 500 * time.Millisecond, PerfBudgetTotal: time.Minute}
+	for phase, limit := range expected {
+		if budget[phase] != limit {
+			t.Errorf("Expected %s budget %v, got %v", phase, limit, budget[phase])
+		}
+	}
+
+	for value, message := range map[string]string{
+		"traversal":      "must be phase=duration",
+		"traversal=fast": "invalid perf budget duration of 'traversal'",
+		"walking=1s":     "invalid 'perf_budget' phase 'walking'",
+	} {
+		if _, err := ParsePerfBudget(value); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected %q error for %q, got %v", message, value, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// gitExecutableMode is the file mode git records for executable files
+const gitExecutableMode = "100755"
+
+// gitFileModes returns the git file modes of the given paths relative to root, as
+// recorded in the index. Returns nil if root is not inside a git work tree.
+func (r *run) gitFileModes(root string, paths []string) map[string]string {
+	repo, err := openGitRepository(root)
+	if err != nil {
+		r.log.Debug("git file modes not available for '{r}': {e}", "r", root, "e", err.Error())
+		return nil
+	}
+	index, err := repo.Storer.Index()
+	if err != nil {
+		r.log.Debug("git file modes not available for '{r}': {e}", "r", root, "e", err.Error())
+		return nil
+	}
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[filepath.ToSlash(p)] = true
+	}
+	modes := make(map[string]string)
+	for _, entry := range index.Entries {
+		if rel, ok := repo.relative(entry.Name); ok && wanted[rel] {
+			modes[rel] = fmt.Sprintf("%o", uint32(entry.Mode))
+		}
+	}
+	return modes
+}
+
+// isExecutableFile reports whether a file is executable, preferring the mode recorded
+// in git (which is what gets deployed) and falling back to the file system mode.
+// The second return value is false if the mode could not be determined.
+func isExecutableFile(fullPath, relPath string, gitModes map[string]string) (bool, bool) {
+	if mode, ok := gitModes[filepath.ToSlash(relPath)]; ok {
+		return mode == gitExecutableMode, true
+	}
+	if runtime.GOOS == "windows" {
+		return false, false // the file system does not record the executable bit
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return false, false
+	}
+	return info.Mode()&0111 != 0, true
+}
+
+// isWorldWritable reports whether a file can be written by any user
+func isWorldWritable(fullPath string) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return false
+	}
+	return info.Mode().Perm()&0002 != 0
+}
+
+// checkFilePermissions verifies, for Linux-target scripts, that the script and the
+// referenced .sh helpers are executable, and reports world-writable referenced files.
+func (r *run) checkFilePermissions(script scriptDefinition, lines []PathFlag) {
+	if script.TargetOS != "linux" {
+		return
+	}
+	r.log.Debug("checking file permissions for '{s}'", "s", script.Filename)
+
+	localized := r.localizeFlags(lines)
+	paths := []string{script.Filename}
+	for _, f := range localized {
+		paths = append(paths, f.Path)
+	}
+	gitModes := r.gitFileModes(r.sourceCodeRoot, paths)
+
+	scriptPath := r.scriptFilePath(r.sourceCodeRoot, script.Filename)
+	if executable, known := isExecutableFile(scriptPath, script.Filename, gitModes); known && !executable {
+		suggestion := "git update-index --chmod=+x " + script.Filename
+		if IsArchivedScript(script.Filename) {
+			suggestion = "set the executable bit of the script when building the archive"
+		}
+		r.reportFinding(Finding{Rule: RuleNotExecutable, Script: script.Filename, Path: script.Filename, Suggestion: suggestion},
+			"Script '{s}' does not have the executable bit set", "s", script.Filename)
+	}
+
+	for n, f := range lines {
+		i, local := f.Line, localized[n].Path
+		fullPath := r.referenceFilePath(local)
+		if _, err := os.Stat(fullPath); err != nil {
+			continue // reported by the file system references check
+		}
+		if r.ignoredFor(CheckPermissions, f.Path) {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(local), ".sh") {
+			if executable, known := isExecutableFile(fullPath, local, gitModes); known && !executable {
+				r.reportFinding(Finding{Rule: RuleNotExecutable, Script: script.Filename, Line: i, Column: f.Column, Path: f.Path, Suggestion: "git update-index --chmod=+x " + filepath.ToSlash(local)},
+					"'{s}' line '{ln}': helper script '{fp}' does not have the executable bit set", "s", script.Filename, "ln", i, "fp", f.Path)
+			}
+		}
+		if isWorldWritable(fullPath) {
+			r.reportFinding(Finding{Rule: RuleWorldWritable, Script: script.Filename, Line: i, Column: f.Column, Path: f.Path},
+				"'{s}' line '{ln}': '{fp}' is world-writable", "s", script.Filename, "ln", i, "fp", f.Path)
+		}
+	}
+}
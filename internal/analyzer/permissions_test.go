@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Tests for file permission checks
+
+// What: The modes recorded in the git index are read for the paths relative to the root
+func TestGitFileModes(t *testing.T) {
+	root := gitTestRepo(t, "top.sh")
+	if err := os.MkdirAll(filepath.Join(root, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "config", "deploy.sh"), []byte("deploy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "config", "helper.sh"), []byte("helper"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommit(t, root, "test", time.Now())
+
+	modes := testRun.gitFileModes(filepath.Join(root, "config"), []string{"deploy.sh", "helper.sh", "missing.sh"})
+	want := map[string]string{"deploy.sh": "100755", "helper.sh": "100644"}
+	if runtime.GOOS == "windows" {
+		want["deploy.sh"] = "100644" // the file system does not record the executable bit
+	}
+	if !reflect.DeepEqual(modes, want) {
+		t.Errorf("Expected modes %v, got %v", want, modes)
+	}
+	if modes := testRun.gitFileModes(t.TempDir(), []string{"a.sh"}); modes != nil {
+		t.Errorf("Expected no modes outside a work tree, got %v", modes)
+	}
+}
+
+func TestIsExecutableFile_GitModeTakesPrecedence(t *testing.T) {
+	// What: The git mode decides even if the file system disagrees
+	gitModes := map[string]string{"100-Config/helper.sh": "100644"}
+	executable, known := isExecutableFile("/nonexistent", filepath.Join("100-Config", "helper.sh"), gitModes)
+	if !known || executable {
+		t.Errorf("Expected known non-executable, got executable=%v known=%v", executable, known)
+	}
+}
+
+func TestIsExecutableFile_FileSystemFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File system does not record the executable bit on Windows")
+	}
+	tmpDir := t.TempDir()
+	helper := filepath.Join(tmpDir, "helper.sh")
+	if err := os.WriteFile(helper, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if executable, known := isExecutableFile(helper, "helper.sh", nil); !known || executable {
+		t.Errorf("Expected non-executable, got executable=%v known=%v", executable, known)
+	}
+	os.Chmod(helper, 0755)
+	if executable, _ := isExecutableFile(helper, "helper.sh", nil); !executable {
+		t.Error("Expected executable after chmod 0755")
+	}
+}
+
+func TestIsWorldWritable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("World-writable mode is not checked on Windows")
+	}
+	file := filepath.Join(t.TempDir(), "data.xml")
+	if err := os.WriteFile(file, []byte("<x/>"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if isWorldWritable(file) {
+		t.Error("Expected 0644 file not to be world-writable")
+	}
+	os.Chmod(file, 0666)
+	if !isWorldWritable(file) {
+		t.Error("Expected 0666 file to be world-writable")
+	}
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// $TC_BIN/preferences_manager -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba \
+// -mode=import -scope=SITE -file="090-Preferences/site_preferences.xml" -action=OVERRIDE
+//
+// preference_modes:
+//   - mode: import
+//     scopes: [SITE, GROUP]
+//     actions: [OVERRIDE, MERGE]
+
+// preferencesManager is the executable name of the Teamcenter preferences manager
+const preferencesManager = "preferences_manager"
+
+// defaultPreferenceModes is the built-in compatibility table of the preferences manager
+// modes; the modes of 'preference_modes' replace the entries of the same mode
+var defaultPreferenceModes = []PreferenceMode{
+	{Mode: "import", Scopes: []string{"SITE", "GROUP", "ROLE", "USER"}, Actions: []string{"OVERRIDE", "SKIP", "MERGE"}},
+	{Mode: "export", Scopes: []string{"SITE", "GROUP", "ROLE", "USER"}},
+	{Mode: "remove", Scopes: []string{"SITE", "GROUP", "ROLE", "USER"}},
+}
+
+// ValidatePreferenceModes checks that each mode is named once and its scopes and
+// actions are not empty
+func ValidatePreferenceModes(modes []PreferenceMode) error {
+	seen := make(map[string]bool, len(modes))
+	for i, m := range modes {
+		mode := strings.ToLower(strings.TrimSpace(m.Mode))
+		if mode == "" {
+			return fmt.Errorf("preference mode at index %d is missing 'mode'", i)
+		}
+		if seen[mode] {
+			return fmt.Errorf("preference mode '%s' is listed more than once", m.Mode)
+		}
+		seen[mode] = true
+		for _, value := range append(append([]string{}, m.Scopes...), m.Actions...) {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("preference mode '%s' has an empty scope or action", m.Mode)
+			}
+		}
+	}
+	return nil
+}
+
+// compilePreferenceModes returns the built-in table with the configured modes replacing
+// the entries of the same mode and the others added, and compiles the flag patterns
+func (r *run) compilePreferenceModes(configured []PreferenceMode) []PreferenceMode {
+	for _, flag := range []string{"mode", "scope", "action"} {
+		r.preferenceFlagPatterns[flag] = regexp.MustCompile(r.flagPrefix(flag) + `=("[^"]*"|'[^']*'|[^\s"']*)`)
+	}
+	modes := append([]PreferenceMode{}, defaultPreferenceModes...)
+	for _, m := range configured {
+		replaced := false
+		for i := range modes {
+			if strings.EqualFold(modes[i].Mode, strings.TrimSpace(m.Mode)) {
+				modes[i], replaced = m, true
+			}
+		}
+		if !replaced {
+			modes = append(modes, m)
+		}
+	}
+	return modes
+}
+
+// preferenceFlag returns the value of a flag of the line, unquoted, with its column;
+// found is false when the line does not pass the flag
+func (r *run) preferenceFlag(line, flag string) (value string, column int, found bool) {
+	location := r.preferenceFlagPatterns[flag].FindStringSubmatchIndex(line)
+	if location == nil {
+		return "", 0, false
+	}
+	return strings.Trim(line[location[2]:location[3]], `"'`), flagColumn(line, location), true
+}
+
+// isVariableValue reports whether a flag value is set at deploy time, e.g. $SCOPE or %SCOPE%
+func isVariableValue(value string) bool {
+	return strings.ContainsAny(value, "$%")
+}
+
+// listValues returns the values as listed in the messages
+func listValues(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+// checkPreferenceFlags checks that the -mode, -scope and -action flags of a
+// preferences manager invocation are a legal combination of the compatibility table.
+// Values are compared case-insensitively; values set by variables are not checked.
+func (r *run) checkPreferenceFlags(scriptFile, line string, lineNumber int) {
+	if len(r.preferenceModes) == 0 || extractExecutableName(line) != preferencesManager {
+		return
+	}
+	mode, modeColumn, ok := r.preferenceFlag(line, "mode")
+	if !ok {
+		r.reportFinding(Finding{Rule: RulePreferenceFlags, Script: scriptFile, Line: lineNumber, Suggestion: "add -mode=import, -mode=export or -mode=remove"},
+			"'{s}' line '{ln}': '{u}' is called without '-mode'", "s", scriptFile, "ln", lineNumber, "u", preferencesManager)
+		return
+	}
+	if isVariableValue(mode) {
+		return
+	}
+	var entry *PreferenceMode
+	var known []string
+	for i := range r.preferenceModes {
+		known = append(known, r.preferenceModes[i].Mode)
+		if strings.EqualFold(r.preferenceModes[i].Mode, mode) {
+			entry = &r.preferenceModes[i]
+		}
+	}
+	if entry == nil {
+		r.reportFinding(Finding{Rule: RulePreferenceFlags, Script: scriptFile, Line: lineNumber, Column: modeColumn},
+			"'{s}' line '{ln}': '-mode={m}' is not a mode of '{u}' ({known})", "s", scriptFile, "ln", lineNumber, "m", mode, "u", preferencesManager, "known", listValues(known))
+		return
+	}
+
+	if scope, column, ok := r.preferenceFlag(line, "scope"); ok && !isVariableValue(scope) && !legalValue(entry.Scopes, scope) {
+		r.reportFinding(Finding{Rule: RulePreferenceFlags, Script: scriptFile, Line: lineNumber, Column: column},
+			"'{s}' line '{ln}': '-scope={sc}' is not legal with '-mode={m}' (scopes: {legal})", "s", scriptFile, "ln", lineNumber, "sc", scope, "m", mode, "legal", listValues(entry.Scopes))
+	}
+	if action, column, ok := r.preferenceFlag(line, "action"); ok && !isVariableValue(action) && !legalValue(entry.Actions, action) {
+		f := Finding{Rule: RulePreferenceFlags, Script: scriptFile, Line: lineNumber, Column: column}
+		if len(entry.Actions) == 0 {
+			f.Suggestion = "remove -action"
+		}
+		r.reportFinding(f, "'{s}' line '{ln}': '-action={a}' is not legal with '-mode={m}' (actions: {legal})", "s", scriptFile, "ln", lineNumber, "a", action, "m", mode, "legal", listValues(entry.Actions))
+	}
+}
+
+// legalValue reports whether values contains value, case-insensitively; an empty list
+// contains none
+func legalValue(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// setupPreferenceFlagsTest compiles the compatibility table with the configured modes
+func setupPreferenceFlagsTest(t *testing.T, configured []PreferenceMode) {
+	t.Helper()
+	originalModes, originalResult := testRun.preferenceModes, testRun.analysisResult
+	t.Cleanup(func() { testRun.preferenceModes, testRun.analysisResult = originalModes, originalResult })
+	testRun.preferenceModes = testRun.compilePreferenceModes(configured)
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+}
+
+func TestCheckPreferenceFlags(t *testing.T) {
+	// What: Scopes and actions not legal with the mode, unknown modes and calls without mode are reported at the flag
+	setupPreferenceFlagsTest(t, nil)
+	lines := []string{
+		`$TC_BIN/preferences_manager -u=$U -mode=import -scope=SITE -file="090-Preferences/site.xml" -action=OVERRIDE`,
+		`$TC_BIN/preferences_manager -mode=IMPORT -scope=group -file="090-Preferences/group.xml" -action=merge`,
+		`$TC_BIN/preferences_manager -mode=export -scope=SITE -out_file="site.xml" -action=OVERRIDE`,
+		`$TC_BIN/preferences_manager -mode=import -scope=PLANT -file="090-Preferences/site.xml"`,
+		`$TC_BIN/preferences_manager -mode=upgrade -scope=SITE`,
+		`$TC_BIN/preferences_manager -scope=SITE -file="090-Preferences/site.xml"`,
+		`$TC_BIN/preferences_manager -mode=import -scope=$SCOPE -file="090-Preferences/site.xml" -action="${ACTION}"`,
+		`$TC_BIN/plmxml_import -mode=anything -xml_file="a.xml"`,
+	}
+	for i, line := range lines {
+		testRun.checkPreferenceFlags("deploy.sh", line, i+1)
+	}
+
+	want := []struct {
+		line   int
+		column int
+		text   string
+	}{
+		{3, 75, "'-action=OVERRIDE' is not legal with '-mode=export' (actions: none)"},
+		{4, 42, "'-scope=PLANT' is not legal with '-mode=import' (scopes: SITE, GROUP, ROLE, USER)"},
+		{5, 29, "'-mode=upgrade' is not a mode of 'preferences_manager' (import, export, remove)"},
+		{6, 0, "'preferences_manager' is called without '-mode'"},
+	}
+	findings := testRun.analysisResult.Findings
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Rule != RulePreferenceFlags || f.Line != w.line || f.Column != w.column || !strings.Contains(f.Message, w.text) {
+			t.Errorf("Expected line %d column %d %q, got %+v", w.line, w.column, w.text, f)
+		}
+	}
+	if findings[0].Suggestion != "remove -action" {
+		t.Errorf("Expected the action removed for a mode without actions, got %q", findings[0].Suggestion)
+	}
+}
+
+func TestCheckPreferenceFlags_ConfiguredModes(t *testing.T) {
+	// What: A configured mode replaces the built-in entry of the mode, other modes are added
+	setupPreferenceFlagsTest(t, []PreferenceMode{
+		{Mode: "Import", Scopes: []string{"SITE"}, Actions: []string{"OVERRIDE"}},
+		{Mode: "dryrun", Scopes: []string{"SITE"}},
+	})
+	testRun.checkPreferenceFlags("deploy.sh", `preferences_manager -mode=import -scope=USER -file="a.xml" -action=MERGE`, 1)
+	testRun.checkPreferenceFlags("deploy.sh", `preferences_manager -mode=dryrun -scope=SITE -file="a.xml"`, 2)
+	testRun.checkPreferenceFlags("deploy.sh", `preferences_manager -mode=export -scope=ROLE -out_file="a.xml"`, 3)
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 || findings[0].Line != 1 || findings[1].Line != 1 ||
+		!strings.Contains(findings[0].Message, "(scopes: SITE)") || !strings.Contains(findings[1].Message, "(actions: OVERRIDE)") {
+		t.Errorf("Expected the scope and the action of line 1, got %+v", findings)
+	}
+}
+
+func TestValidatePreferenceModes(t *testing.T) {
+	// What: Modes must be named once and list no empty scope or action
+	tests := []struct {
+		modes []PreferenceMode
+		want  string
+	}{
+		{[]PreferenceMode{{Scopes: []string{"SITE"}}}, "index 0 is missing 'mode'"},
+		{[]PreferenceMode{{Mode: "import"}, {Mode: "IMPORT"}}, "'IMPORT' is listed more than once"},
+		{[]PreferenceMode{{Mode: "import", Actions: []string{" "}}}, "'import' has an empty scope or action"},
+	}
+	for _, tt := range tests {
+		if err := ValidatePreferenceModes(tt.modes); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q, got %v", tt.want, err)
+		}
+	}
+	if err := ValidatePreferenceModes([]PreferenceMode{{Mode: "import", Scopes: []string{"SITE"}}}); err != nil {
+		t.Errorf("Expected a valid mode, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is an entry of the 'profiles' mapping: the parameters of a target
+// environment, e.g. prod, overriding the base ones of the configuration (see ApplyProfile)
+type Profile struct{}
+
+// UnmarshalYAML checks the entry is a mapping, it is merged over the base parameters
+// before they are decoded
+func (p *Profile) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: a profile must be a mapping of parameters", value.Line)
+	}
+	return nil
+}
+
+// ApplyProfile returns the configuration document with the parameters of the profile
+// env merged over its base ones and the 'profiles' mapping removed: the mappings are
+// merged key by key, e.g. a profile setting one threshold keeps the others, while the
+// other values, lists such as 'scripts' included, are replaced. The document is
+// returned as is without env.
+func ApplyProfile(document []byte, env string) ([]byte, error) {
+	if env == "" {
+		return document, nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("profile '%s' is not defined (no 'profiles')", env)
+	}
+	base := root.Content[0]
+
+	var profiles *yaml.Node
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		if base.Content[i].Value == "profiles" {
+			profiles = base.Content[i+1]
+			base.Content = append(base.Content[:i:i], base.Content[i+2:]...)
+			break
+		}
+	}
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("profile '%s' is not defined (no 'profiles')", env)
+	}
+	var names []string
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		if profiles.Content[i].Value != env {
+			names = append(names, profiles.Content[i].Value)
+			continue
+		}
+		profile := profiles.Content[i+1]
+		if profile.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("profile '%s' must be a mapping of parameters", env)
+		}
+		mergeNode(base, profile)
+		return yaml.Marshal(&root)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("profile '%s' is not defined (profiles: %s)", env, strings.Join(names, ", "))
+}
+
+// mergeNode sets the keys of the override mapping in the base one, merging the
+// mappings both set
+func mergeNode(base, override *yaml.Node) {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		merged := false
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value != key.Value {
+				continue
+			}
+			if existing := base.Content[j+1]; existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeNode(existing, value)
+			} else {
+				base.Content[j+1] = value
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+}
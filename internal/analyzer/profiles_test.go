@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const profilesConfig = `
+scripts:
+  - filename: deploy.sh
+    target_os: linux
+path_parameters:
+  - xml_file
+source_code_root: '/repos/dev'
+thresholds:
+  max_missing_files: 5
+  max_script_lines: 500
+perf_budget:
+  total: 30s
+profiles:
+  test:
+    source_code_root: '/repos/test'
+  prod:
+    source_code_root: '/repos/prod'
+    scripts:
+      - filename: deploy_prod.sh
+        target_os: linux
+    thresholds:
+      max_script_lines: 300
+`
+
+func TestApplyProfile(t *testing.T) {
+	// What: The profile overrides the keys it sets, merges the mappings and replaces the lists
+	document, err := ApplyProfile([]byte(profilesConfig), "prod")
+	if err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+	var p Parameters
+	if err := yaml.Unmarshal(document, &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if p.SourceCodeRoot != "/repos/prod" {
+		t.Errorf("Expected the root of the profile, got %q", p.SourceCodeRoot)
+	}
+	if len(p.Scripts) != 1 || p.Scripts[0].Filename != "deploy_prod.sh" {
+		t.Errorf("Expected the scripts replaced by the profile, got %+v", p.Scripts)
+	}
+	if p.Thresholds.MaxScriptLines == nil || *p.Thresholds.MaxScriptLines != 300 || p.Thresholds.MaxMissingFiles == nil || *p.Thresholds.MaxMissingFiles != 5 {
+		t.Errorf("Expected the thresholds merged, got %+v", p.Thresholds)
+	}
+	if !reflect.DeepEqual(p.PathParameters, []PathParameter{{Name: "xml_file"}}) || p.PerfBudget["total"] != 30*time.Second {
+		t.Errorf("Expected the base parameters kept, got %+v %v", p.PathParameters, p.PerfBudget)
+	}
+	if p.Profiles != nil {
+		t.Errorf("Expected the profiles removed, got %v", p.Profiles)
+	}
+}
+
+func TestApplyProfile_NoEnv(t *testing.T) {
+	// What: Without an environment the base parameters are used
+	document, err := ApplyProfile([]byte(profilesConfig), "")
+	if err != nil || string(document) != profilesConfig {
+		t.Errorf("Expected the document unchanged, got %v", err)
+	}
+}
+
+func TestApplyProfile_Errors(t *testing.T) {
+	// What: An unknown profile lists the defined ones; a profile that is not a mapping is rejected
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{"unknown", profilesConfig, "profile 'staging' is not defined (profiles: prod, test)"},
+		{"no profiles", "source_code_root: '/repos'\n", "profile 'staging' is not defined (no 'profiles')"},
+		{"not a mapping", "profiles:\n  staging: '/repos'\n", "profile 'staging' must be a mapping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyProfile([]byte(tt.document), "staging")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestProfile_UnmarshalYAML(t *testing.T) {
+	// What: Profiles decoded with the base parameters must be mappings
+	var p Parameters
+	if err := yaml.Unmarshal([]byte(profilesConfig), &p); err != nil || len(p.Profiles) != 2 {
+		t.Errorf("Expected 2 profiles, got %v %v", p.Profiles, err)
+	}
+	err := yaml.Unmarshal([]byte("profiles:\n  prod: [a]\n"), &p)
+	if err == nil || !strings.Contains(err.Error(), "a profile must be a mapping") {
+		t.Errorf("Expected a mapping error, got %v", err)
+	}
+}
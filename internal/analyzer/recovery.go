@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PanicError is an unexpected failure of the analyzer, recovered with its stack
+type PanicError struct {
+	Phase  string // analysis phase that panicked, empty outside the phases
+	Script string // script analyzed by the phase, empty for phases of the run
+	Value  interface{}
+	Stack  []byte
+	Bundle string // directory of the diagnostic bundle, empty when it could not be written
+}
+
+func (e *PanicError) Error() string {
+	msg := "internal error"
+	if e.Phase != "" {
+		msg += fmt.Sprintf(" in phase '%s'", e.Phase)
+	}
+	if e.Script != "" {
+		msg += fmt.Sprintf(" of '%s'", e.Script)
+	}
+	msg += fmt.Sprintf(": %v", e.Value)
+	if e.Bundle != "" {
+		msg += fmt.Sprintf(" (diagnostic bundle in '%s')", e.Bundle)
+	}
+	return msg
+}
+
+// runPhase runs a phase in the logger scope of its name; a panic is raised again as a
+// PanicError of the phase, so the recovery of Run knows where it happened
+func (r *run) runPhase(phase, script string, fn func()) {
+	restore := r.log.EnterScope(phase)
+	defer restore()
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(*PanicError); ok {
+				panic(v)
+			}
+			panic(&PanicError{Phase: phase, Script: script, Value: v, Stack: debug.Stack()})
+		}
+	}()
+	fn()
+}
+
+// recoverRun, deferred by Run, turns a panic into an internal error returned with the
+// partial result, and writes the diagnostic bundle
+func (r *run) recoverRun(params Parameters, result *Result, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	p, ok := v.(*PanicError)
+	if !ok {
+		p = &PanicError{Script: r.currentScript, Value: v, Stack: debug.Stack()}
+	}
+	bundle, bundleErr := writeDiagnosticBundle(p, params, r.analysisResult.Findings)
+	if bundleErr != nil {
+		r.log.Error("Failed to write the diagnostic bundle: {e}", "e", bundleErr.Error())
+	}
+	p.Bundle = bundle
+	r.log.Error(p.Error())
+	*result, *err = r.analysisResult, withKind(KindInternal, p)
+}
+
+// writeDiagnosticBundle writes the panic with its stack, the configuration with the
+// secrets redacted and the findings so far to a new temporary directory:
+//
+//	panic.txt      phase, script, value, Go version and stack
+//	config.yaml    the parameters of the run
+//	findings.json  the findings reported before the panic
+func writeDiagnosticBundle(p *PanicError, params Parameters, findings []Finding) (string, error) {
+	dir, err := os.MkdirTemp("", "validate-tcx-panic-")
+	if err != nil {
+		return "", err
+	}
+
+	report := fmt.Sprintf("phase: %s\nscript: %s\npanic: %v\ngo: %s %s/%s\n\n%s",
+		p.Phase, p.Script, p.Value, runtime.Version(), runtime.GOOS, runtime.GOARCH, p.Stack)
+	if err := os.WriteFile(filepath.Join(dir, "panic.txt"), []byte(report), 0644); err != nil {
+		return dir, err
+	}
+	config, err := redactedConfig(params)
+	if err != nil {
+		return dir, fmt.Errorf("configuration snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), config, 0644); err != nil {
+		return dir, err
+	}
+	if findings == nil {
+		findings = []Finding{}
+	}
+	encoded, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return dir, err
+	}
+	return dir, os.WriteFile(filepath.Join(dir, "findings.json"), append(encoded, '\n'), 0644)
+}
+
+// secretKeys are the configuration keys whose values are redacted; the '_env' keys
+// name environment variables and are kept
+var secretKeys = regexp.MustCompile(`(?i)password|passphrase|secret|token|credential|private_key|api_key`)
+
+// redactedConfig returns the parameters as YAML with the values of secret keys and the
+// passwords of URLs replaced by 'REDACTED'
+func redactedConfig(params Parameters) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(params); err != nil {
+		return nil, err
+	}
+	redactNode(&node)
+	return yaml.Marshal(&node)
+}
+
+func redactNode(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i].Value, n.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Value != "" && secretKeys.MatchString(key) && !strings.HasSuffix(key, "_env") {
+				value.Value, value.Tag, value.Style = "REDACTED", "!!str", 0
+				continue
+			}
+			redactNode(value)
+		}
+	case yaml.ScalarNode:
+		n.Value = redactURL(n.Value)
+	default:
+		for _, c := range n.Content {
+			redactNode(c)
+		}
+	}
+}
+
+// redactURL replaces the password of a URL with credentials
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	return u.String()
+}
@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshClientConfig builds the SSH client configuration for the remote target.
+// Host keys are always verified against the known_hosts file.
+func sshClientConfig(target remoteTarget) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if target.IdentityFile != "" {
+		key, err := os.ReadFile(target.IdentityFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity file: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identity file '%s': %w", target.IdentityFile, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if target.PasswordEnv != "" {
+		password, ok := os.LookupEnv(target.PasswordEnv)
+		if !ok {
+			return nil, fmt.Errorf("environment variable '%s' with the remote password is not set", target.PasswordEnv)
+		}
+		auth = append(auth, ssh.Password(password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("no credentials configured for remote '%s' (set 'identity_file' or 'password_env')", target.Host)
+	}
+
+	knownHostsFile := target.KnownHosts
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts '%s': %w", knownHostsFile, err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            target.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// remoteAddress returns host:port of the remote target, the port defaulting to 22
+func remoteAddress(target remoteTarget) string {
+	port := target.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(target.Host, strconv.Itoa(port))
+}
+
+// collectRemoteTree walks root on the SFTP server and returns all paths found below it
+func (r *run) collectRemoteTree(client *sftp.Client, root string) (map[string]bool, error) {
+	root = path.Clean(root)
+	if info, err := client.Stat(root); err != nil {
+		return nil, fmt.Errorf("remote root '%s': %w", root, err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("remote root '%s' is not a directory", root)
+	}
+
+	tree := make(map[string]bool)
+	walker := client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			r.reportFinding(Finding{Rule: RuleTraversalError, Path: walker.Path()},
+				"Error accessing remote path '{p}': {e}", "p", walker.Path(), "e", err.Error())
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), root), "/")
+		if rel == "" {
+			continue
+		}
+		tree[rel] = walker.Stat().IsDir()
+	}
+	return tree, nil
+}
+
+// loadRemoteTree connects to the remote target and lists its content once per run
+func (r *run) loadRemoteTree(target remoteTarget) error {
+	config, err := sshClientConfig(target)
+	if err != nil {
+		return err
+	}
+	address := remoteAddress(target)
+	r.log.Info("Listing remote content of '{r}' on '{a}'", "r", target.Root, "a", address)
+
+	tcp, err := r.dialNetwork(address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to remote '%s': %w", address, err)
+	}
+	// The handshake is bounded by the network timeout as well
+	tcp.SetDeadline(time.Now().Add(r.networkSettings.timeout()))
+	c, channels, requests, err := ssh.NewClientConn(tcp, address, config)
+	if err != nil {
+		tcp.Close()
+		return fmt.Errorf("failed to connect to remote '%s': %w", address, err)
+	}
+	tcp.SetDeadline(time.Time{})
+	conn := ssh.NewClient(c, channels, requests)
+	defer conn.Close()
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		return fmt.Errorf("failed to start SFTP session on '%s': %w", address, err)
+	}
+	defer client.Close()
+
+	tree, err := r.collectRemoteTree(client, target.Root)
+	if err != nil {
+		return err
+	}
+	r.log.Info("'{n}' paths found on the remote", "n", len(tree))
+	r.remoteTree = tree
+	return nil
+}
+
+// remoteExists reports whether a path relative to the root exists on the remote
+func (r *run) remoteExists(p string) bool {
+	_, ok := r.remoteTree[path.Clean(toSlash(p))]
+	return ok
+}
+
+// remoteFiles returns the remote files not matching the ignore patterns, sorted.
+// Like the local traversal, nothing below an ignored directory is returned.
+func (r *run) remoteFiles(ignorePatterns []string) []string {
+	paths := make([]string, 0, len(r.remoteTree))
+	for p := range r.remoteTree {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var files []string
+	var ignoredDirs []string
+	for _, p := range paths {
+		if underIgnoredDirectory(p, ignoredDirs) {
+			continue
+		}
+		relPath := filepath.FromSlash(p)
+		if r.shouldIgnore(relPath, ignorePatterns) {
+			if r.remoteTree[p] && !r.compiledIgnoreSet(ignorePatterns).mayReinclude(relPath) {
+				ignoredDirs = append(ignoredDirs, p)
+			}
+			continue
+		}
+		if !r.remoteTree[p] {
+			r.log.Debug("Path '{relPath}' should be checked if existing in the script file.", "relPath", relPath)
+			files = append(files, relPath)
+		}
+	}
+	return files
+}
+
+func underIgnoredDirectory(p string, ignoredDirs []string) bool {
+	for _, dir := range ignoredDirs {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// newInMemorySFTPClient starts an in-memory SFTP server with the given files and
+// directories (directories end with '/') and returns a client connected to it
+func newInMemorySFTPClient(t *testing.T, paths []string) *sftp.Client {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	server := sftp.NewRequestServer(serverConn, sftp.InMemHandler())
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	if err != nil {
+		t.Fatalf("Failed to create SFTP client: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	for _, p := range paths {
+		if strings.HasSuffix(p, "/") {
+			if err := client.MkdirAll(strings.TrimSuffix(p, "/")); err != nil {
+				t.Fatalf("Failed to create remote directory %s: %v", p, err)
+			}
+			continue
+		}
+		f, err := client.Create(p)
+		if err != nil {
+			t.Fatalf("Failed to create remote file %s: %v", p, err)
+		}
+		f.Close()
+	}
+	return client
+}
+
+// What: Remote tree contains all paths below the root, relative to it
+func TestCollectRemoteTree(t *testing.T) {
+	client := newInMemorySFTPClient(t, []string{
+		"/deploy/", "/deploy/100-Config/", "/deploy/100-Config/a.xml", "/deploy/deploy.sh", "/other.txt",
+	})
+
+	tree, err := testRun.collectRemoteTree(client, "/deploy/")
+	if err != nil {
+		t.Fatalf("collectRemoteTree() failed: %v", err)
+	}
+
+	expected := map[string]bool{"100-Config": true, "100-Config/a.xml": false, "deploy.sh": false}
+	if !reflect.DeepEqual(tree, expected) {
+		t.Errorf("collectRemoteTree() = %v, want %v", tree, expected)
+	}
+}
+
+// What: A missing remote root is an error
+func TestCollectRemoteTree_MissingRoot(t *testing.T) {
+	client := newInMemorySFTPClient(t, nil)
+
+	if _, err := testRun.collectRemoteTree(client, "/missing"); err == nil {
+		t.Error("Expected error for missing remote root, got nil")
+	}
+}
+
+// What: fileExists checks the remote tree when a remote is configured
+func TestFileExists_Remote(t *testing.T) {
+	testRun.remoteTree = map[string]bool{"100-Config": true, "100-Config/a.xml": false}
+	testRun.sourceCodeRoot = t.TempDir()
+	testRun.currentScriptTargetOS, hostOS = "windows", "linux"
+	defer func() { testRun.remoteTree, hostOS = nil, runtime.GOOS }()
+
+	if !testRun.fileExists(`100-Config\a.xml`) {
+		t.Error("Expected '100-Config\\a.xml' to exist on the remote")
+	}
+	if testRun.fileExists("100-Config/missing.xml") {
+		t.Error("Expected '100-Config/missing.xml' to be missing on the remote")
+	}
+}
+
+// What: Remote files exclude directories and everything below ignored directories
+func TestRemoteFiles_IgnorePatterns(t *testing.T) {
+	testRun.remoteTree = map[string]bool{
+		"100-Config":       true,
+		"100-Config/a.xml": false,
+		"logs":             true,
+		"logs/run.log":     false,
+		"deploy.sh":        false,
+		"notes.tmp":        false,
+	}
+	defer func() { testRun.remoteTree = nil }()
+
+	files, err := testRun.traverseAndCollect("unused", []string{"logs/", "*.tmp"})
+	if err != nil {
+		t.Fatalf("traverseAndCollect() failed: %v", err)
+	}
+
+	expected := []string{filepath.FromSlash("100-Config/a.xml"), "deploy.sh"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("traverseAndCollect() = %v, want %v", files, expected)
+	}
+}
+
+// What: Remote without credentials is rejected before connecting
+func TestSSHClientConfig_NoCredentials(t *testing.T) {
+	_, err := sshClientConfig(remoteTarget{Host: "staging", Root: "/deploy"})
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Expected no credentials error, got: %v", err)
+	}
+}
+
+// What: Password environment variable must be set
+func TestSSHClientConfig_PasswordEnvNotSet(t *testing.T) {
+	_, err := sshClientConfig(remoteTarget{Host: "staging", PasswordEnv: "TCX_TEST_UNSET_PASSWORD"})
+	if err == nil || !strings.Contains(err.Error(), "TCX_TEST_UNSET_PASSWORD") {
+		t.Errorf("Expected unset password variable error, got: %v", err)
+	}
+}
+
+// What: Missing known_hosts file is an error, host keys are never ignored
+func TestSSHClientConfig_KnownHostsMissing(t *testing.T) {
+	t.Setenv("TCX_TEST_PASSWORD", "secret")
+	_, err := sshClientConfig(remoteTarget{
+		Host:        "staging",
+		PasswordEnv: "TCX_TEST_PASSWORD",
+		KnownHosts:  filepath.Join(t.TempDir(), "known_hosts"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "known_hosts") {
+		t.Errorf("Expected known_hosts error, got: %v", err)
+	}
+}
+
+// What: Port defaults to 22
+func TestRemoteAddress(t *testing.T) {
+	if addr := remoteAddress(remoteTarget{Host: "staging"}); addr != "staging:22" {
+		t.Errorf("remoteAddress() = %q, want 'staging:22'", addr)
+	}
+	if addr := remoteAddress(remoteTarget{Host: "staging", Port: 2222}); addr != "staging:2222" {
+		t.Errorf("remoteAddress() = %q, want 'staging:2222'", addr)
+	}
+}
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// Repository is an entry of the 'repositories' list: a name and the parameters of
+// the repository, the top-level ones with the keys set in the entry overridden
+type Repository struct {
+	Name       string
+	Parameters Parameters
+
+	overrides yaml.Node
+}
+
+// UnmarshalYAML keeps the entry to apply it over the top-level parameters
+// (see ResolveRepositories)
+func (r *Repository) UnmarshalYAML(value *yaml.Node) error {
+	var named struct {
+		Name string `yaml:"name"`
+	}
+	if err := value.Decode(&named); err != nil {
+		return err
+	}
+	r.Name, r.overrides = named.Name, *value
+	return nil
+}
+
+// ResolveRepositories sets the parameters of each repository from document, the
+// configuration the parameters were decoded from: its top-level parameters with the
+// keys of the repository entry overriding them.
+func (p *Parameters) ResolveRepositories(document []byte) error {
+	for i := range p.Repositories {
+		var resolved Parameters
+		if err := yaml.Unmarshal(document, &resolved); err != nil {
+			return err
+		}
+		resolved.Repositories = nil
+		if err := p.Repositories[i].overrides.Decode(&resolved); err != nil {
+			return fmt.Errorf("repository '%s': %w", p.Repositories[i].Name, err)
+		}
+		resolved.Repositories = nil
+		p.Repositories[i].Parameters = resolved
+	}
+	return nil
+}
+
+// RepositoryResult is the outcome of a single repository of a batch run
+type RepositoryResult struct {
+	Name   string
+	Root   string
+	Result Result
+	Err    error
+}
+
+// RunRepositories validates the configured repositories one after the other, each in
+// a run of its own, so results do not leak between them. The error lists the
+// repositories whose validation failed.
+func (a *Analyzer) RunRepositories(params Parameters) ([]RepositoryResult, error) {
+	results := make([]RepositoryResult, 0, len(params.Repositories))
+	var failed []string
+	for _, repo := range params.Repositories {
+		a.log.Heading(" ")
+		a.log.Separate("REPOSITORY '{n}' in '{r}'", "n", repo.Name, "r", repo.Parameters.SourceCodeRoot)
+		a.log.Separate("=====================================")
+
+		result, err := a.Run(repo.Parameters)
+		results = append(results, RepositoryResult{Name: repo.Name, Root: repo.Parameters.SourceCodeRoot, Result: result, Err: err})
+		if err != nil {
+			failed = append(failed, repo.Name)
+		}
+	}
+
+	a.logRepositoriesSummary(results)
+	if len(failed) > 0 {
+		return results, fmt.Errorf("validation failed for %d of %d repositories: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+	return results, nil
+}
+
+// RunRepositories validates the configured repositories with the default logger, see
+// (*Analyzer).RunRepositories
+func RunRepositories(params Parameters) ([]RepositoryResult, error) {
+	return New(logger.Default()).RunRepositories(params)
+}
+
+// logRepositoriesSummary writes the consolidated summary of a batch run
+func (a *Analyzer) logRepositoriesSummary(results []RepositoryResult) {
+	a.log.Heading(" ")
+	a.log.Separate("REPOSITORIES SUMMARY")
+	a.log.Separate("=====================================")
+	errors, warnings, passed := 0, 0, 0
+	for _, result := range results {
+		s := result.Result.Summary
+		verdict := s.Verdict()
+		if result.Err != nil && len(s.Scripts) == 0 {
+			verdict = "ERROR"
+		}
+		a.log.Separate("'{n}': {e} errors, {w} warnings, {v}", "n", result.Name, "e", s.Errors, "w", s.Warnings, "v", verdict)
+		errors += s.Errors
+		warnings += s.Warnings
+		if s.Passed {
+			passed++
+		}
+	}
+	a.log.Separate("Total: {p}/{n} repositories passed, {e} errors, {w} warnings", "p", passed, "n", len(results), "e", errors, "w", warnings)
+}
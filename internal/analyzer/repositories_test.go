@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const batchConfig = `
+scripts:
+  - filename: deploy.sh
+    target_os: linux
+path_parameters:
+  - xml_file
+source_code_root: '/repos/main'
+ignore_patterns:
+  global:
+    - 'deploy.*'
+repositories:
+  - name: main
+  - name: other
+    source_code_root: '/repos/other'
+    ignore_patterns:
+      global:
+        - 'build/'
+`
+
+// What: Repositories inherit the top-level parameters and override the keys they set
+func TestResolveRepositories(t *testing.T) {
+	var p Parameters
+	if err := yaml.Unmarshal([]byte(batchConfig), &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := p.ResolveRepositories([]byte(batchConfig)); err != nil {
+		t.Fatalf("ResolveRepositories failed: %v", err)
+	}
+	if len(p.Repositories) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(p.Repositories))
+	}
+
+	main, other := p.Repositories[0], p.Repositories[1]
+	if main.Name != "main" || main.Parameters.SourceCodeRoot != "/repos/main" {
+		t.Errorf("Expected 'main' with the top-level root, got %q %q", main.Name, main.Parameters.SourceCodeRoot)
+	}
+	if other.Parameters.SourceCodeRoot != "/repos/other" {
+		t.Errorf("Expected overridden root '/repos/other', got %q", other.Parameters.SourceCodeRoot)
+	}
+	if !reflect.DeepEqual(other.Parameters.IgnorePatterns.Global, []string{"build/"}) {
+		t.Errorf("Expected ignore patterns replaced by the override, got %v", other.Parameters.IgnorePatterns.Global)
+	}
+	if len(other.Parameters.Scripts) != 1 || other.Parameters.Scripts[0].Filename != "deploy.sh" {
+		t.Errorf("Expected scripts inherited from the top level, got %v", other.Parameters.Scripts)
+	}
+	if other.Parameters.Repositories != nil {
+		t.Errorf("Expected no nested repositories, got %v", other.Parameters.Repositories)
+	}
+}
+
+// What: Each repository is validated with its own state, findings and executables do not leak between repositories
+func TestRunRepositories_Isolated(t *testing.T) {
+	newRepo := func(content string, files ...string) string {
+		root := t.TempDir()
+		for _, f := range files {
+			if err := os.WriteFile(filepath.Join(root, f), []byte("<x/>"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	base := Parameters{
+		Scripts:        []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+		PathParameters: []PathParameter{{Name: "xml_file"}},
+		IgnorePatterns: ignorePatterns{Global: []string{"deploy.sh"}},
+	}
+	broken, clean := base, base
+	broken.SourceCodeRoot = newRepo("plmxml_import -xml_file=\"missing.xml\"\ntem_install -update\n")
+	clean.SourceCodeRoot = newRepo("plmxml_import -xml_file=\"a.xml\"\n", "a.xml")
+
+	results, err := RunRepositories(Parameters{Repositories: []Repository{
+		{Name: "broken", Parameters: broken},
+		{Name: "clean", Parameters: clean},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error without thresholds, got: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Result.Summary.Passed || len(results[0].Result.Findings) == 0 {
+		t.Errorf("Expected 'broken' to fail with findings, got %+v", results[0].Result.Summary)
+	}
+	if !results[1].Result.Summary.Passed || len(results[1].Result.Findings) != 0 {
+		t.Errorf("Expected 'clean' to pass without findings, got %+v", results[1].Result.Findings)
+	}
+	if executables := results[1].Result.File["deploy.sh"].Executables; len(executables) != 1 || len(executables["plmxml_import"]) != 1 {
+		t.Errorf("Expected only the executable of 'clean', got %v", executables)
+	}
+	if executables := results[0].Result.File["deploy.sh"].Executables; len(executables["tem_install"]) != 1 {
+		t.Errorf("Expected 'broken' to keep its executables, got %v", executables)
+	}
+}
+
+// What: A repository that cannot be validated fails the batch, naming the repository
+func TestRunRepositories_Failed(t *testing.T) {
+	params := Parameters{
+		Scripts:        []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+		PathParameters: []PathParameter{{Name: "xml_file"}},
+		SourceCodeRoot: t.TempDir(),
+	}
+	_, err := RunRepositories(Parameters{Repositories: []Repository{{Name: "empty", Parameters: params}}})
+	if err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("Expected error naming the repository, got: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+// Built-in rulesets, selected with 'ruleset' so repositories can adopt the validation
+// leniently and tighten it over time
+const (
+	RulesetLenient  = "lenient"  // script syntax and existence of the referenced files only
+	RulesetStandard = "standard" // all rules with the severities of the catalog (default)
+	RulesetStrict   = "strict"   // warnings are errors, conditional references and heredocs are checked
+)
+
+// ruleset pre-selects the rules reported and their severities
+type ruleset struct {
+	disabled   map[string]bool   // rules not reported
+	severities map[string]string // severities replacing those of the catalog
+	defaults   func(p *Parameters)
+}
+
+// rulesets are the built-in rulesets by name; standard is the zero ruleset
+var rulesets = map[string]ruleset{
+	RulesetLenient: {
+		disabled: map[string]bool{
+			RuleNotExecutable: true, RuleWorldWritable: true, RuleDuplicateContent: true,
+			RuleUnreferencedFile: true, RuleUnusedIgnorePattern: true, RuleConditionalReference: true,
+			RuleLoopNotExpanded: true, RuleHeredocPath: true, RuleExecutableParity: true, RulePathParity: true, RuleBareUtility: true,
+			RuleIgnoredReference: true, RulePathNotNormalized: true, RuleTextCharacters: true,
+			RuleContentRule: true,
+		},
+	},
+	RulesetStandard: {},
+	RulesetStrict: {
+		severities: map[string]string{
+			RuleScriptEncoding: SeverityError, RuleTemplateValue: SeverityError, RuleWorldWritable: SeverityError,
+			RuleUnusedIgnorePattern: SeverityError, RuleLoopNotExpanded: SeverityError, RuleNotInEnvironment: SeverityError,
+			RuleBareUtility: SeverityError, RuleIgnoredReference: SeverityError, RulePathNotNormalized: SeverityError,
+			RuleTextCharacters: SeverityError, RuleContentRule: SeverityError,
+		},
+		defaults: strictDefaults,
+	},
+}
+
+// strictDefaults tightens the parameters of the strict ruleset: files referenced only
+// conditionally do not count as referenced, unless configured, and heredoc bodies are scanned
+func strictDefaults(p *Parameters) {
+	if p.ConditionalReferences == "" {
+		p.ConditionalReferences = "not_covered"
+	}
+	p.ScanHeredocs = true
+}
+
+// IsRuleset reports whether name is a built-in ruleset; empty selects standard
+func IsRuleset(name string) bool {
+	_, ok := rulesets[name]
+	return ok || name == ""
+}
+
+// applyRuleset activates the ruleset of the parameters and returns them with the
+// defaults of the ruleset set
+func (r *run) applyRuleset(params Parameters) Parameters {
+	r.activeRuleset = rulesets[params.Ruleset]
+	if r.activeRuleset.defaults != nil {
+		r.activeRuleset.defaults(&params)
+	}
+	if params.Ruleset != "" {
+		r.log.Info("Ruleset '{r}' selected", "r", params.Ruleset)
+	}
+	return params
+}
+
+// ruleEnabled reports whether the findings of the rule are reported by the ruleset
+func (r *run) ruleEnabled(rule string) bool {
+	return !r.activeRuleset.disabled[rule]
+}
+
+// ruleSeverity returns the severity of the rule in the ruleset
+func (r *run) ruleSeverity(rule string) string {
+	if severity, ok := r.activeRuleset.severities[rule]; ok {
+		return severity
+	}
+	return rules[rule].Severity
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// Tests for the built-in rulesets
+
+// What: The lenient ruleset drops the findings of the rules beyond syntax and existence
+func TestRecordFinding_LenientRuleset(t *testing.T) {
+	originalResult := testRun.analysisResult
+	defer func() { testRun.analysisResult, testRun.activeRuleset = originalResult, ruleset{} }()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.applyRuleset(Parameters{Ruleset: RulesetLenient})
+
+	if testRun.recordFinding(Finding{Rule: RuleUnreferencedFile, Path: "a.xml"}) {
+		t.Error("Expected the unreferenced file not to be recorded")
+	}
+	if !testRun.recordFinding(Finding{Rule: RuleMissingFile, Path: "b.xml"}) {
+		t.Error("Expected the missing file to be recorded")
+	}
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleMissingFile {
+		t.Errorf("Expected only the missing file, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+// What: The strict ruleset raises warnings to errors, explicit severities are kept
+func TestRecordFinding_StrictRuleset(t *testing.T) {
+	originalResult := testRun.analysisResult
+	defer func() { testRun.analysisResult, testRun.activeRuleset = originalResult, ruleset{} }()
+	testRun.analysisResult = Result{File: make(map[string]Lines)}
+	testRun.applyRuleset(Parameters{Ruleset: RulesetStrict})
+
+	testRun.recordFinding(Finding{Rule: RuleUnusedIgnorePattern})
+	testRun.recordFinding(Finding{Rule: RuleDuplicateContent})
+	testRun.recordFinding(Finding{Rule: RuleUnusedIgnorePattern, Severity: SeverityInfo})
+	severities := []string{testRun.analysisResult.Findings[0].Severity, testRun.analysisResult.Findings[1].Severity, testRun.analysisResult.Findings[2].Severity}
+	if severities[0] != SeverityError || severities[1] != SeverityInfo || severities[2] != SeverityInfo {
+		t.Errorf("Unexpected severities %v", severities)
+	}
+}
+
+// What: The strict ruleset scans heredocs and does not count conditional references unless configured
+func TestApplyRuleset_StrictDefaults(t *testing.T) {
+	defer func() { testRun.activeRuleset = ruleset{} }()
+	params := testRun.applyRuleset(Parameters{Ruleset: RulesetStrict})
+	if params.ConditionalReferences != "not_covered" || !params.ScanHeredocs {
+		t.Errorf("Expected the strict defaults, got %q, %v", params.ConditionalReferences, params.ScanHeredocs)
+	}
+	params = testRun.applyRuleset(Parameters{Ruleset: RulesetStrict, ConditionalReferences: "covered"})
+	if params.ConditionalReferences != "covered" {
+		t.Errorf("Expected the configured policy to be kept, got %q", params.ConditionalReferences)
+	}
+	if params = testRun.applyRuleset(Parameters{}); params.ScanHeredocs || !testRun.ruleEnabled(RuleUnreferencedFile) {
+		t.Error("Expected the standard ruleset to change nothing")
+	}
+}
+
+// What: Only the built-in ruleset names are accepted, empty is standard
+func TestIsRuleset(t *testing.T) {
+	for _, name := range []string{"", RulesetLenient, RulesetStandard, RulesetStrict} {
+		if !IsRuleset(name) {
+			t.Errorf("Expected %q to be a ruleset", name)
+		}
+	}
+	if IsRuleset("paranoid") {
+		t.Error("Expected an unknown ruleset to be refused")
+	}
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The configuration schema is a JSON Schema generated from the Parameters struct, so
+// it covers every section the loader decodes. Editors use it for the completion of
+// the YAML configuration (e.g. with a '# yaml-language-server: $schema=' comment).
+
+// schemaID is the draft of JSON Schema the configuration schema is written in
+const schemaID = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums are the values of the string fields accepting a fixed set, keyed by the
+// Go type and field name; empty selects the default and is left out
+var schemaEnums = map[string][]string{
+	"scriptDefinition.TargetOS":        {"windows", "linux"},
+	"scriptDefinition.MatchStrategy":   matchStrategies,
+	"Parameters.LogFormat":             {"text", "json"},
+	"Parameters.Symlinks":              {"follow", "skip", "error"},
+	"Parameters.ConditionalReferences": {"covered", "not_covered"},
+	"Parameters.ScriptEncoding":        {"info", "warning", "error", "ignore"},
+	"Parameters.Ruleset":               {RulesetLenient, RulesetStandard, RulesetStrict},
+	"ScopedIgnorePattern.Checks":       ignoreChecks,
+	"PathParameter.Style":              {"equals_quoted", "space_quoted", "bare"},
+	"templating.Mode":                  {TemplatingRender, TemplatingWildcard},
+}
+
+// schemaObject is a JSON Schema, its keys written in sorted order by encoding/json
+type schemaObject map[string]interface{}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigSchema returns the JSON Schema of the configuration file
+func ConfigSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(Parameters{}))
+	schema["$schema"] = schemaID
+	schema["title"] = "validate-tcx-deploy-script configuration"
+
+	// a repository entry has the parameters overriding the top-level ones and its name
+	repository := typeSchema(reflect.TypeOf(Parameters{}))
+	properties := repository["properties"].(schemaObject)
+	delete(properties, "repositories")
+	delete(properties, "profiles")
+	properties["name"] = schemaObject{"type": "string"}
+	repository["required"] = []string{"name"}
+
+	// a profile has the parameters overriding the base ones, repositories included
+	profile := typeSchema(reflect.TypeOf(Parameters{}))
+	delete(profile["properties"].(schemaObject), "profiles")
+	schema["$defs"] = schemaObject{"repository": repository, "profile": profile}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema returns the schema of the values decoded into a Go type
+func typeSchema(t reflect.Type) schemaObject {
+	switch t {
+	case durationType:
+		return schemaObject{"type": "string", "pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`}
+	case reflect.TypeOf(PathParameter{}):
+		// a flag name or the mapping form, see PathParameter.UnmarshalYAML
+		return schemaObject{"oneOf": []schemaObject{{"type": "string"}, structSchema(t)}}
+	case reflect.TypeOf(Repository{}):
+		// defined once, as it holds the parameters
+		return schemaObject{"$ref": "#/$defs/repository"}
+	case reflect.TypeOf(Profile{}):
+		return schemaObject{"$ref": "#/$defs/profile"}
+	case reflect.TypeOf(ignorePatterns{}):
+		// global entries are plain patterns or scoped to checks, see ignorePatterns.UnmarshalYAML
+		schema := structSchema(t)
+		scoped := structSchema(reflect.TypeOf(ScopedIgnorePattern{}))
+		scoped["required"] = []string{"pattern", "checks"}
+		schema["properties"].(schemaObject)["global"] = schemaObject{"type": "array",
+			"items": schemaObject{"oneOf": []schemaObject{{"type": "string"}, scoped}}}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return schemaObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schemaObject{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schemaObject{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return schemaObject{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return schemaObject{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return schemaObject{"type": "string"}
+}
+
+// structSchema returns the schema of a struct decoded by its yaml tags. Unknown keys
+// are not allowed, so typos in key names are reported by the editor.
+func structSchema(t reflect.Type) schemaObject {
+	properties := schemaObject{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if !field.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		property := typeSchema(field.Type)
+		if values, ok := schemaEnums[t.Name()+"."+field.Name]; ok {
+			enum := schemaObject{"type": "string", "enum": values}
+			if property["type"] == "array" {
+				property["items"] = enum
+			} else {
+				property = enum
+			}
+		}
+		properties[name] = property
+	}
+	return schemaObject{"type": "object", "properties": properties, "additionalProperties": false}
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tests for the JSON Schema of the configuration
+
+// checkSchema validates a YAML node against the parts of JSON Schema the configuration
+// schema uses, returning the first violation
+func checkSchema(node *yaml.Node, schema, defs map[string]interface{}, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return checkSchema(node, defs[filepath.Base(ref)].(map[string]interface{}), defs, at)
+	}
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		for _, alternative := range alternatives {
+			if checkSchema(node, alternative.(map[string]interface{}), defs, at) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: no alternative matches", at)
+	}
+	switch schema["type"] {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: expected a mapping", at)
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			property, ok := properties[key].(map[string]interface{})
+			if !ok {
+				property, ok = schema["additionalProperties"].(map[string]interface{})
+			}
+			if !ok {
+				return fmt.Errorf("%s: unknown key '%s'", at, key)
+			}
+			if err := checkSchema(node.Content[i+1], property, defs, at+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("%s: expected a sequence", at)
+		}
+		for i, item := range node.Content {
+			if err := checkSchema(item, schema["items"].(map[string]interface{}), defs, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%s: expected a %v", at, schema["type"])
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(node.Value) {
+			return fmt.Errorf("%s: '%s' does not match %s", at, node.Value, pattern)
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			for _, value := range enum {
+				if value == node.Value {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: '%s' is not one of %v", at, node.Value, enum)
+		}
+	}
+	return nil
+}
+
+func loadConfigSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	content, err := ConfigSchema()
+	if err != nil {
+		t.Fatalf("ConfigSchema failed: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("ConfigSchema is not JSON: %v", err)
+	}
+	return schema
+}
+
+func TestConfigSchema_ExampleConfiguration(t *testing.T) {
+	// What: The example configuration, which shows every section, is valid against the schema
+	schema := loadConfigSchema(t)
+	content, err := os.ReadFile(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read the example configuration: %v", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		t.Fatalf("Failed to parse the example configuration: %v", err)
+	}
+	if err := checkSchema(document.Content[0], schema, schema["$defs"].(map[string]interface{}), "config"); err != nil {
+		t.Errorf("Example configuration does not match the schema: %v", err)
+	}
+}
+
+func TestConfigSchema_Invalid(t *testing.T) {
+	// What: Unknown keys, values outside an enum and malformed durations are rejected
+	schema := loadConfigSchema(t)
+	defs := schema["$defs"].(map[string]interface{})
+	tests := map[string]string{
+		"unknown key":      "scripts:\n  - filename: deploy.sh\n    target: linux\n",
+		"enum":             "ruleset: paranoid\n",
+		"duration":         "network:\n  timeout: 30 seconds\n",
+		"repository":       "repositories:\n  - name: a\n    repositories: []\n",
+		"profile":          "profiles:\n  prod:\n    profiles: {}\n",
+		"profile key":      "profiles:\n  prod:\n    thresholds:\n      max_lines: 10\n",
+		"scoped ignore":    "ignore_patterns:\n  global:\n    - pattern: '*.log'\n      checks: [everything]\n",
+		"path parameter":   "path_parameters:\n  - name: input\n    style: glued\n",
+		"list of mappings": "owners: '@team'\n",
+	}
+	for name, content := range tests {
+		var document yaml.Node
+		if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+			t.Fatalf("%s: failed to parse: %v", name, err)
+		}
+		if err := checkSchema(document.Content[0], schema, defs, "config"); err == nil {
+			t.Errorf("%s: expected the configuration to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scripts:
+//   - filename: 'release-2024.10.zip!deploy/install_linux.sh'
+//     target_os: linux
+//
+// A script shipped in a release zip is validated as shipped: the archive, relative to
+// the source code root, is extracted to a temporary directory, the script is read from
+// there and its references are resolved against the archive contents plus the source root.
+
+// archiveSeparator separates the archive from the path of the script inside it
+const archiveSeparator = "!"
+
+// splitArchivePath splits a script filename 'archive.zip!inner/path' into the archive
+// and the slash-separated path inside it
+func splitArchivePath(filename string) (archive, inner string, ok bool) {
+	i := strings.Index(filename, archiveSeparator)
+	if i < 0 || !strings.HasSuffix(strings.ToLower(filename[:i]), ".zip") {
+		return "", "", false
+	}
+	return filename[:i], strings.TrimLeft(toSlash(filename[i+1:]), "/"), true
+}
+
+// IsArchivedScript reports whether the script filename points into an archive
+func IsArchivedScript(filename string) bool {
+	_, _, ok := splitArchivePath(filename)
+	return ok
+}
+
+// extractArchive extracts a zip into a new temporary directory and returns it.
+// Entries escaping the directory are refused.
+func extractArchive(archivePath string) (string, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("error opening %q: %w", archivePath, err)
+	}
+	defer archive.Close()
+
+	dir, err := os.MkdirTemp("", "tcx-archive-")
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range archive.File {
+		if isUnsafeArchiveEntry(entry.Name) {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("entry %q of %q escapes the archive", entry.Name, archivePath)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(toSlash(entry.Name)))
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				os.RemoveAll(dir)
+				return "", err
+			}
+			continue
+		}
+		if err := extractArchiveEntry(entry, target); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error extracting %q of %q: %w", entry.Name, archivePath, err)
+		}
+	}
+	return dir, nil
+}
+
+// extractArchiveEntry writes a zip entry to target, keeping its permission bits; entries
+// created without Unix modes stay readable
+func extractArchiveEntry(entry *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, entry.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// extractScriptArchives extracts the archives of the archived scripts once each and
+// returns the function removing the extracted files. A missing archive is a
+// configuration error, one that cannot be extracted an I/O error.
+func (r *run) extractScriptArchives(scripts []scriptDefinition, root string) (func(), error) {
+	r.extractedArchives = make(map[string]string)
+	cleanup := func() {
+		for _, dir := range r.extractedArchives {
+			os.RemoveAll(dir)
+		}
+	}
+	for _, script := range scripts {
+		archive, _, ok := splitArchivePath(script.Filename)
+		if !ok {
+			continue
+		}
+		if _, done := r.extractedArchives[archive]; done {
+			continue
+		}
+		archivePath := filepath.Join(root, r.localPath(archive))
+		if _, err := os.Stat(archivePath); os.IsNotExist(err) {
+			cleanup()
+			return func() {}, withKind(KindConfig, fmt.Errorf("archive '%s' of script '%s' not found in source_code_root '%s'", archive, script.Filename, root))
+		}
+		dir, err := extractArchive(archivePath)
+		if err != nil {
+			cleanup()
+			return func() {}, withKind(KindIO, fmt.Errorf("archive of script '%s' cannot be extracted: %w", script.Filename, err))
+		}
+		r.log.Debug("extracted '{a}' to '{d}'", "a", archive, "d", dir)
+		r.extractedArchives[archive] = dir
+	}
+	return cleanup, nil
+}
+
+// scriptFilePath returns the path a script is read from: the extracted copy of an
+// archived script, otherwise the script in the source code root
+func (r *run) scriptFilePath(root, filename string) string {
+	if archive, inner, ok := splitArchivePath(filename); ok {
+		if dir, ok := r.extractedArchives[archive]; ok {
+			return filepath.Join(dir, filepath.FromSlash(inner))
+		}
+	}
+	return filepath.Join(root, filename)
+}
+
+// referenceFilePath returns the file a localized path referenced by the script being
+// processed is read from: the source code root, or the archive the script is
+// shipped in when only the archive contains it
+func (r *run) referenceFilePath(path string) string {
+	fullPath := filepath.Join(r.sourceCodeRoot, path)
+	if _, err := os.Stat(fullPath); err == nil || !r.archiveExists(path) {
+		return fullPath
+	}
+	archive, _, _ := splitArchivePath(r.currentScript)
+	return filepath.Join(r.extractedArchives[archive], path)
+}
+
+// archiveExists reports whether a path referenced by the script being processed is
+// shipped in the archive the script is part of
+func (r *run) archiveExists(path string) bool {
+	archive, _, ok := splitArchivePath(r.currentScript)
+	if !ok || r.extractedArchives[archive] == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(r.extractedArchives[archive], path))
+	return err == nil
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests for scripts shipped in archives
+
+// What: Only filenames with a zip before the '!' point into an archive
+func TestSplitArchivePath(t *testing.T) {
+	tests := []struct {
+		filename       string
+		archive, inner string
+		ok             bool
+	}{
+		{"release-2024.10.zip!deploy/install_linux.sh", "release-2024.10.zip", "deploy/install_linux.sh", true},
+		{`releases\r.ZIP!\deploy\install.bat`, `releases\r.ZIP`, "deploy/install.bat", true},
+		{"deploy!.sh", "", "", false},
+		{"deploy.sh", "", "", false},
+	}
+	for _, tt := range tests {
+		archive, inner, ok := splitArchivePath(tt.filename)
+		if archive != tt.archive || inner != tt.inner || ok != tt.ok {
+			t.Errorf("splitArchivePath(%q) = %q, %q, %v, want %q, %q, %v", tt.filename, archive, inner, ok, tt.archive, tt.inner, tt.ok)
+		}
+	}
+}
+
+// What: Archived scripts are read from the extracted copy and the removal deletes it
+func TestExtractScriptArchives(t *testing.T) {
+	root := t.TempDir()
+	writeTestZip(t, filepath.Join(root, "release.zip"), map[string]string{"deploy/install.sh": "echo install\n"})
+	scripts := []scriptDefinition{{Filename: "release.zip!deploy/install.sh", TargetOS: "linux"}, {Filename: "deploy.sh", TargetOS: "linux"}}
+
+	remove, err := testRun.extractScriptArchives(scripts, root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := testRun.scriptFilePath(root, scripts[0].Filename)
+	if data, err := os.ReadFile(path); err != nil || string(data) != "echo install\n" {
+		t.Errorf("Expected the extracted script at %q, got %q (%v)", path, data, err)
+	}
+	if got := testRun.scriptFilePath(root, "deploy.sh"); got != filepath.Join(root, "deploy.sh") {
+		t.Errorf("Expected plain scripts in the source code root, got %q", got)
+	}
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the extracted files to be removed, got %v", err)
+	}
+}
+
+// What: A missing archive is a configuration error, one escaping the extraction directory an I/O error
+func TestExtractScriptArchives_Errors(t *testing.T) {
+	root := t.TempDir()
+	_, err := testRun.extractScriptArchives([]scriptDefinition{{Filename: "missing.zip!install.sh"}}, root)
+	if Kind(err) != KindConfig || !strings.Contains(err.Error(), "archive 'missing.zip'") {
+		t.Errorf("Expected a configuration error for the missing archive, got %v", err)
+	}
+
+	writeTestZip(t, filepath.Join(root, "evil.zip"), map[string]string{"../install.sh": "echo\n"})
+	_, err = testRun.extractScriptArchives([]scriptDefinition{{Filename: "evil.zip!install.sh"}}, root)
+	if Kind(err) != KindIO || !strings.Contains(err.Error(), "escapes the archive") {
+		t.Errorf("Expected an I/O error for the unsafe entry, got %v", err)
+	}
+}
+
+// What: References of an archived script exist in the archive or in the source code root
+func TestFileExists_ArchivedScript(t *testing.T) {
+	originalRoot, originalScript, originalArchives := testRun.sourceCodeRoot, testRun.currentScript, testRun.extractedArchives
+	defer func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.extractedArchives = originalRoot, originalScript, originalArchives
+	}()
+	originalOS := testRun.currentScriptTargetOS
+	defer func() { testRun.currentScriptTargetOS = originalOS }()
+	testRun.currentScriptTargetOS = hostOS
+
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "100-Config"), 0755)
+	os.WriteFile(filepath.Join(root, "100-Config", "a.xml"), []byte("<a/>"), 0644)
+	writeTestZip(t, filepath.Join(root, "release.zip"), map[string]string{"install.sh": "echo\n", "payload/b.xml": "<b/>"})
+	testRun.sourceCodeRoot = root
+	remove, err := testRun.extractScriptArchives([]scriptDefinition{{Filename: "release.zip!install.sh"}}, root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer remove()
+
+	testRun.currentScript = "release.zip!install.sh"
+	if !testRun.fileExists("100-Config/a.xml") || !testRun.fileExists("payload/b.xml") || testRun.fileExists("payload/c.xml") {
+		t.Error("Expected the references to resolve against the archive plus the source code root")
+	}
+	testRun.currentScript = "deploy.sh"
+	if testRun.fileExists("payload/b.xml") {
+		t.Error("Expected archive contents to be visible to the archived scripts only")
+	}
+}
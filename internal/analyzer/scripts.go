@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkScripts verifies the configured scripts before any check runs: each script must
+// exist under the source code root and must not be empty. With withinRoot the script
+// may also not resolve outside the source code root through '..' segments. A
+// configured working directory must exist.
+// A misconfigured script fails the run instead of producing empty results; a script
+// that cannot be accessed is an I/O error.
+func (r *run) checkScripts(scripts []scriptDefinition, root string, withinRoot bool) error {
+	for _, script := range scripts {
+		if withinRoot && scriptEscapesRoot(script.Filename) {
+			return withKind(KindConfig, fmt.Errorf("script '%s' resolves outside source_code_root '%s'", script.Filename, root))
+		}
+
+		fullPath := r.scriptFilePath(root, script.Filename)
+		info, err := os.Stat(fullPath)
+		if os.IsNotExist(err) && IsArchivedScript(script.Filename) {
+			return withKind(KindConfig, fmt.Errorf("script '%s' not found in its archive", script.Filename))
+		}
+		if os.IsNotExist(err) {
+			return withKind(KindConfig, fmt.Errorf("script '%s' not found in source_code_root '%s'", script.Filename, root))
+		}
+		if err != nil {
+			return withKind(KindIO, fmt.Errorf("script '%s' cannot be accessed: %w", script.Filename, err))
+		}
+		if info.IsDir() {
+			return withKind(KindConfig, fmt.Errorf("script '%s' is a directory, not a file", script.Filename))
+		}
+		if info.Size() == 0 {
+			return withKind(KindConfig, fmt.Errorf("script '%s' is empty", script.Filename))
+		}
+
+		if script.WorkingDir != "" {
+			dir := filepath.Join(root, parsePath(script.WorkingDir, script.TargetOS).render(hostOS))
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				return withKind(KindConfig, fmt.Errorf("working_dir '%s' of script '%s' is not a directory in source_code_root '%s'", script.WorkingDir, script.Filename, root))
+			}
+		}
+	}
+	return nil
+}
+
+// scriptEscapesRoot reports whether a script filename, which is relative to the
+// source code root, points outside of it
+func scriptEscapesRoot(filename string) bool {
+	cleaned := filepath.Clean(filename)
+	return cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// What: Existing, non-empty scripts pass the check
+func TestCheckScripts_Valid(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte("echo\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	scripts := []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}}
+	if err := testRun.checkScripts(scripts, root, true); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// What: Missing, empty and directory scripts fail with a configuration error naming the script
+func TestCheckScripts_Invalid(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "empty.bat"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "folder.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"missing.bat", "not found"},
+		{"empty.bat", "is empty"},
+		{"folder.sh", "is a directory"},
+	}
+	for _, tt := range tests {
+		err := testRun.checkScripts([]scriptDefinition{{Filename: tt.filename, TargetOS: "windows"}}, root, false)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.filename) {
+			t.Errorf("checkScripts(%q): expected error containing %q, got: %v", tt.filename, tt.want, err)
+		}
+	}
+}
+
+// What: Scripts outside the source code root are only rejected with scripts_within_root
+func TestCheckScripts_WithinRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "repo")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "deploy.sh"), []byte("echo\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	scripts := []scriptDefinition{{Filename: filepath.Join("..", "deploy.sh"), TargetOS: "linux"}}
+
+	if err := testRun.checkScripts(scripts, root, false); err != nil {
+		t.Errorf("Expected no error without scripts_within_root, got: %v", err)
+	}
+	err := testRun.checkScripts(scripts, root, true)
+	if err == nil || !strings.Contains(err.Error(), "outside source_code_root") {
+		t.Errorf("Expected error for script outside the root, got: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Categories of the lines the syntax check does not analyze, so users can audit
+// whether the parser ignores lines it should analyze
+const (
+	SkipBlank        = "blank"
+	SkipComment      = "comment"
+	SkipContinuation = "continuation"       // continues the command of the previous line
+	SkipShellCommand = "shell command"      // shell builtin, control flow or variable assignment
+	SkipNoFlag       = "no flag"            // utility call without a path parameter
+	SkipHeredoc      = "heredoc"            // body of a heredoc
+	SkipTemplate     = "template statement" // {% if %}, {{ end }}, ...
+)
+
+// skipCategories lists the categories in report order
+var skipCategories = []string{SkipBlank, SkipComment, SkipContinuation, SkipShellCommand, SkipNoFlag, SkipHeredoc, SkipTemplate}
+
+// shellKeywords are the control flow words starting lines that are not commands
+var shellKeywords = map[string]bool{
+	"then": true, "else": true, "elif": true, "fi": true, "do": true, "done": true,
+	"case": true, "esac": true, "while": true, "until": true, "function": true,
+	"{": true, "}": true, "(": true, ")": true, ";;": true, "setlocal": true, "endlocal": true,
+}
+
+// recordSkipReason records the category of a line of the script that is not analyzed
+func (r *run) recordSkipReason(file string, lineNumber int, category string) {
+	lines, ok := r.analysisResult.File[file]
+	if !ok {
+		return
+	}
+	if lines.SkipReasons == nil {
+		lines.SkipReasons = make(map[int]string)
+		r.analysisResult.File[file] = lines
+	}
+	lines.SkipReasons[lineNumber] = category
+}
+
+// continuesLine reports whether the raw line continues on the next one: a trailing
+// '\' in Linux and '^' in Windows scripts
+func continuesLine(line, targetOS string) bool {
+	line = strings.TrimRight(line, " \t\r")
+	if targetOS == "windows" {
+		return strings.HasSuffix(line, "^")
+	}
+	return strings.HasSuffix(line, `\`)
+}
+
+// commandSkipReason returns the category of a command line without path parameters
+func commandSkipReason(line string, continued bool) string {
+	if continued {
+		return SkipContinuation
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return SkipBlank
+	}
+	first := strings.ToLower(strings.TrimPrefix(fields[0], "@"))
+	if shellKeywords[first] || strings.HasPrefix(first, ":") || extractExecutableName(line) == "" {
+		return SkipShellCommand
+	}
+	return SkipNoFlag
+}
+
+// countSkipReasons counts the skipped lines per category, nil without skipped lines
+func countSkipReasons(reasons map[int]string) map[string]int {
+	if len(reasons) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, category := range reasons {
+		counts[category]++
+	}
+	return counts
+}
+
+// formatSkipCounts renders the counts in category order, e.g. '3 comment, 1 no flag'
+func formatSkipCounts(counts map[string]int) string {
+	var parts []string
+	for _, category := range skipCategories {
+		if counts[category] > 0 {
+			parts = append(parts, logger.Format("{n} {c}", "n", counts[category], "c", category))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Tests for the categories of skipped lines
+
+// What: Skipped and blank lines are categorized and counted per category
+func TestCheckFileSyntax_SkipReasons(t *testing.T) {
+	setupSyntaxTest()
+	root := t.TempDir()
+	content := "#!/bin/bash\n" +
+		"\n" +
+		"export TC_BIN=/opt/tc/bin\n" +
+		"if [ -d \"$TC_BIN\" ]; then\n" +
+		"fi\n" +
+		"plmxml_import -u=infodba \\\n" +
+		"  -i=\"100-Config/a.xml\"\n" +
+		"plmxml_import -u=infodba \\\n" +
+		"  -g=dba\n" +
+		"install_util -status\n" +
+		"cat <<EOF\n" +
+		"body\n" +
+		"EOF\n"
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+
+	testRun.checkFileSyntax("deploy.sh", root, "linux")
+
+	want := map[int]string{
+		1: SkipComment, 2: SkipBlank, 3: SkipShellCommand, 4: SkipShellCommand, 5: SkipShellCommand,
+		6: SkipNoFlag, 8: SkipNoFlag, 9: SkipContinuation, 10: SkipNoFlag, 11: SkipNoFlag, 12: SkipHeredoc, 13: SkipHeredoc,
+	}
+	if got := testRun.analysisResult.File["deploy.sh"].SkipReasons; !reflect.DeepEqual(got, want) {
+		t.Errorf("SkipReasons = %v, want %v", got, want)
+	}
+}
+
+// What: Commands without path parameters are told apart from shell commands and continued lines
+func TestCommandSkipReason(t *testing.T) {
+	tests := []struct {
+		line      string
+		continued bool
+		want      string
+	}{
+		{"echo done", false, SkipShellCommand},
+		{"TC_DATA=/opt/tc/data", false, SkipShellCommand},
+		{":end", false, SkipShellCommand},
+		{"done", false, SkipShellCommand},
+		{"%TC_BIN%\\install_util.exe -status", false, SkipNoFlag},
+		{"  -g=dba", true, SkipContinuation},
+	}
+	for _, tt := range tests {
+		if got := commandSkipReason(tt.line, tt.continued); got != tt.want {
+			t.Errorf("commandSkipReason(%q, %v) = %q, want %q", tt.line, tt.continued, got, tt.want)
+		}
+	}
+}
+
+// What: Counts are rendered in category order
+func TestFormatSkipCounts(t *testing.T) {
+	counts := countSkipReasons(map[int]string{1: SkipNoFlag, 2: SkipComment, 3: SkipComment})
+	if got := formatSkipCounts(counts); got != "2 comment, 1 no flag" {
+		t.Errorf("Unexpected counts %q", got)
+	}
+	if countSkipReasons(nil) != nil {
+		t.Error("Expected no counts without skipped lines")
+	}
+}
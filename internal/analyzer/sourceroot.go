@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SourceCodeRootAuto is the 'source_code_root' value inferring the root from the
+// location of the scripts, so one configuration serves checkouts at different paths
+const SourceCodeRootAuto = "auto"
+
+// ResolveSourceCodeRoot replaces 'source_code_root: auto', of the configuration and of
+// each repository, with the git top level of the directory of the first script, or with
+// that directory outside of a git work tree. The script filenames are then relative to
+// the working directory, or absolute, and are made relative to the inferred root.
+func ResolveSourceCodeRoot(p *Parameters) error {
+	for i := range p.Repositories {
+		if err := ResolveSourceCodeRoot(&p.Repositories[i].Parameters); err != nil {
+			return fmt.Errorf("repository '%s': %w", p.Repositories[i].Name, err)
+		}
+	}
+	if p.SourceCodeRoot != SourceCodeRootAuto {
+		return nil
+	}
+	if len(p.Scripts) == 0 || p.Scripts[0].Filename == "" {
+		return fmt.Errorf("'source_code_root: auto' needs a script to infer the root from")
+	}
+
+	root, err := inferSourceCodeRoot(scriptLocation(p.Scripts[0].Filename))
+	if err != nil {
+		return err
+	}
+	for i, script := range p.Scripts {
+		filename, err := relativeScriptFilename(root, script.Filename)
+		if err != nil {
+			return err
+		}
+		p.Scripts[i].Filename = filename
+	}
+	p.SourceCodeRoot = root
+	return nil
+}
+
+// scriptLocation returns the file a script filename is read from: the archive of an
+// archived script, the script itself otherwise
+func scriptLocation(filename string) string {
+	if archive, _, ok := splitArchivePath(filename); ok {
+		return archive
+	}
+	return filename
+}
+
+// inferSourceCodeRoot returns the git top level of the directory of a script, or the
+// directory itself when it is not in a git work tree
+func inferSourceCodeRoot(script string) (string, error) {
+	dir, err := realDirectory(filepath.Dir(script))
+	if err != nil {
+		return "", fmt.Errorf("cannot infer 'source_code_root' from script '%s': %w", script, err)
+	}
+	if repo, err := openGitRepository(dir); err == nil {
+		return repo.top, nil
+	}
+	return dir, nil
+}
+
+// realDirectory returns the absolute path of an existing directory with its symlinks
+// resolved, so paths below it compare with the git top level
+func realDirectory(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("'%s' is not a directory", abs)
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// relativeScriptFilename returns a script filename relative to the inferred root,
+// keeping the entry of an archived script
+func relativeScriptFilename(root, filename string) (string, error) {
+	location, inner := scriptLocation(filename), ""
+	if location != filename {
+		inner = filename[len(location):]
+	}
+	dir, err := realDirectory(filepath.Dir(location))
+	if err != nil {
+		return "", fmt.Errorf("script '%s' cannot be located: %w", filename, err)
+	}
+	rel, err := filepath.Rel(root, filepath.Join(dir, filepath.Base(location)))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("script '%s' is outside the inferred source_code_root '%s'", filename, root)
+	}
+	return rel + inner, nil
+}
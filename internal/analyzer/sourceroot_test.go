@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests for 'source_code_root: auto'
+
+func writeScriptFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("echo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// What: Outside a git work tree the root is the directory of the first script
+func TestResolveSourceCodeRoot_ScriptDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeScriptFile(t, filepath.Join(dir, "deploy", "deploy.sh"))
+	writeScriptFile(t, filepath.Join(dir, "deploy", "win", "deploy.bat"))
+
+	p := Parameters{SourceCodeRoot: SourceCodeRootAuto, Scripts: []scriptDefinition{
+		{Filename: filepath.Join(dir, "deploy", "deploy.sh"), TargetOS: "linux"},
+		{Filename: filepath.Join(dir, "deploy", "win", "deploy.bat"), TargetOS: "windows"},
+	}}
+	if err := ResolveSourceCodeRoot(&p); err != nil {
+		t.Fatalf("Expected the root to be inferred, got %v", err)
+	}
+	expected, _ := filepath.EvalSymlinks(filepath.Join(dir, "deploy"))
+	if p.SourceCodeRoot != expected {
+		t.Errorf("Expected root %q, got %q", expected, p.SourceCodeRoot)
+	}
+	if p.Scripts[0].Filename != "deploy.sh" || p.Scripts[1].Filename != filepath.Join("win", "deploy.bat") {
+		t.Errorf("Expected the filenames relative to the root, got %+v", p.Scripts)
+	}
+}
+
+// What: In a git work tree the root is its top level, archived scripts keep their entry
+func TestResolveSourceCodeRoot_GitTopLevel(t *testing.T) {
+	root := gitTestRepo(t, "a.xml")
+	writeScriptFile(t, filepath.Join(root, "scripts", "deploy.sh"))
+	writeScriptFile(t, filepath.Join(root, "scripts", "release.zip"))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(filepath.Join(root, "scripts")); err != nil {
+		t.Fatal(err)
+	}
+
+	p := Parameters{SourceCodeRoot: SourceCodeRootAuto, Scripts: []scriptDefinition{
+		{Filename: "deploy.sh", TargetOS: "linux"},
+		{Filename: "release.zip!deploy/install.sh", TargetOS: "linux"},
+	}}
+	if err := ResolveSourceCodeRoot(&p); err != nil {
+		t.Fatalf("Expected the root to be inferred, got %v", err)
+	}
+	expected, _ := filepath.EvalSymlinks(root)
+	if p.SourceCodeRoot != expected {
+		t.Errorf("Expected the git top level %q, got %q", expected, p.SourceCodeRoot)
+	}
+	if p.Scripts[0].Filename != filepath.Join("scripts", "deploy.sh") || p.Scripts[1].Filename != filepath.Join("scripts", "release.zip")+"!deploy/install.sh" {
+		t.Errorf("Expected the filenames relative to the top level, got %+v", p.Scripts)
+	}
+}
+
+// What: Configured roots are kept, scripts outside the inferred root or missing are errors
+func TestResolveSourceCodeRoot_Errors(t *testing.T) {
+	p := Parameters{SourceCodeRoot: "/repo", Scripts: []scriptDefinition{{Filename: "missing.sh"}}}
+	if err := ResolveSourceCodeRoot(&p); err != nil || p.SourceCodeRoot != "/repo" {
+		t.Errorf("Expected the configured root kept, got %q, %v", p.SourceCodeRoot, err)
+	}
+
+	dir := t.TempDir()
+	writeScriptFile(t, filepath.Join(dir, "a", "deploy.sh"))
+	writeScriptFile(t, filepath.Join(dir, "b", "deploy.bat"))
+	tests := []struct {
+		scripts []scriptDefinition
+		message string
+	}{
+		{nil, "needs a script"},
+		{[]scriptDefinition{{Filename: filepath.Join(dir, "missing", "deploy.sh")}}, "cannot infer 'source_code_root'"},
+		{[]scriptDefinition{{Filename: filepath.Join(dir, "a", "deploy.sh")}, {Filename: filepath.Join(dir, "b", "deploy.bat")}}, "is outside the inferred source_code_root"},
+	}
+	for _, tt := range tests {
+		p := Parameters{SourceCodeRoot: SourceCodeRootAuto, Scripts: tt.scripts}
+		if err := ResolveSourceCodeRoot(&p); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected %q error, got %v", tt.message, err)
+		}
+	}
+
+	repos := Parameters{Repositories: []Repository{{Name: "broken", Parameters: Parameters{SourceCodeRoot: SourceCodeRootAuto}}}}
+	if err := ResolveSourceCodeRoot(&repos); err == nil || !strings.Contains(err.Error(), "repository 'broken'") {
+		t.Errorf("Expected the repository error, got %v", err)
+	}
+}
@@ -1,13 +1,10 @@
 package analyzer
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
-
-	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
 )
 
 // $TC_BIN/install_xml_stylesheet_datasets -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba \
@@ -15,6 +12,57 @@ import (
 // -filepath="200-Stylesheets/" \
 // -replace
 
+// stylesheet_importers:
+//   - utility: site_install_stylesheets
+//     input_flag: list
+//     filepath_flag: xmldir
+
+// defaultStylesheetImporter is the Teamcenter utility, used unless importers are configured
+var defaultStylesheetImporter = StylesheetImporter{Utility: "install_xml_stylesheet_datasets"}
+
+// stylesheetImporter is a stylesheet import utility with the patterns matching its
+// invocations and extracting its flags: group 1 is the input file, group 2 the folder
+type stylesheetImporter struct {
+	StylesheetImporter
+	utilityRegex *regexp.Regexp
+	flagsRegex   *regexp.Regexp
+}
+
+// ValidateStylesheetImporters checks that each stylesheet importer names a utility
+func ValidateStylesheetImporters(importers []StylesheetImporter) error {
+	for i, importer := range importers {
+		if strings.TrimSpace(importer.Utility) == "" {
+			return fmt.Errorf("stylesheet importer at index %d is missing 'utility'", i)
+		}
+	}
+	return nil
+}
+
+// compileStylesheetImporters compiles the patterns of the importers, or of
+// install_xml_stylesheet_datasets when none are configured. Flags are written with or
+// without the dash, like in the flag rules.
+func compileStylesheetImporters(importers []StylesheetImporter) []stylesheetImporter {
+	if len(importers) == 0 {
+		importers = []StylesheetImporter{defaultStylesheetImporter}
+	}
+	compiled := make([]stylesheetImporter, 0, len(importers))
+	for _, importer := range importers {
+		importer.Utility = strings.TrimSpace(importer.Utility)
+		if importer.InputFlag = flagRuleName(importer.InputFlag); importer.InputFlag == "" {
+			importer.InputFlag = "input"
+		}
+		if importer.FilepathFlag = flagRuleName(importer.FilepathFlag); importer.FilepathFlag == "" {
+			importer.FilepathFlag = "filepath"
+		}
+		compiled = append(compiled, stylesheetImporter{
+			StylesheetImporter: importer,
+			utilityRegex:       regexp.MustCompile(regexp.QuoteMeta(importer.Utility)),
+			flagsRegex:         regexp.MustCompile(`-` + regexp.QuoteMeta(importer.InputFlag) + `="([^"]+)"|-` + regexp.QuoteMeta(importer.FilepathFlag) + `="([^"]+)"`),
+		})
+	}
+	return compiled
+}
+
 type FilePathInfo struct {
 	RelativePath string
 	AbsolutePath string
@@ -42,97 +90,81 @@ func (fpm FilePathMap) Paths(pathType string) (map[int]string, error) {
 }
 
 // processStylesheetInputFile processes a single stylesheet import definition file.
-// It reads the input file, extracts XML file references, validates they exist,
-// and compares repository files with script references.
+// It reads the input file, extracts XML file references, validates they exist and
+// render, and compares repository files with script references.
+//
+// The XMLs are resolved in the notation of the script target OS: the -filepath folder
+// relative to the source code root (an absolute folder must lie within it, or in
+// 'allowed_external_paths'), the file names of the input file below the folder. Paths
+// resolving outside the root are reported as TCX011 findings.
 //
 // Parameters:
+//   - scriptFile, lineNumber: The script and line of the stylesheet import
 //   - importDefinition: The stylesheet import definition containing input file and XML paths
 //
 // Returns:
+//   - map[int]StylesheetDataset: The datasets defined in the input file, keyed by line number
 //   - error: Any error encountered during processing, or nil on success
-func processStylesheetInputFile(importDefinition StyleSheetImport) error {
-	osLocalizedInputFileLocation := strings.ReplaceAll(importDefinition.InputFile, convertFrom, convertTo)
-	osLocalizedXMLsFilePath := strings.ReplaceAll(importDefinition.XMLsFilepath, convertFrom, convertTo)
-
-	inputFileFullPath := filepath.Join(sourceCodeRoot, osLocalizedInputFileLocation)
+func (r *run) processStylesheetInputFile(scriptFile string, lineNumber int, importDefinition StyleSheetImport) (map[int]StylesheetDataset, error) {
+	// The folder of the XMLs is checked before the input file is read
+	folder, external, ok := r.resolveListFolder(scriptFile, lineNumber, importDefinition.XMLsFilepath, "stylesheet XML folder")
+	if !ok {
+		return nil, nil
+	}
 
-	// Open the input text file
-	file, err := os.Open(inputFileFullPath)
+	// Rows are 'dataset name,XML file name'
+	rows, err := r.readListFile(importDefinition.InputFile, folder, listFormat{delimiter: ",", column: 2, rowRule: RuleStylesheetInputLine})
 	if err != nil {
-		return fmt.Errorf("error opening %q: %w", inputFileFullPath, err)
-	}
-	defer file.Close() // Properly closes when function returns
-
-	xmlFilesReferences := FilePathMap{}
-	readLinesCount := 0
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		readLinesCount++
-
-		// Split each line by the comma
-		columns := strings.Split(line, ",")
-		if len(columns) >= 2 {
-			// Trim spaces, form the full path, and append to the slice with files to check if existing on the file system
-			filePath := strings.ReplaceAll(importDefinition.XMLsFilepath, convertFrom, convertTo)
-			fileName := strings.TrimSpace(columns[1])
-			pathToStylesheetXML := filepath.Join(filePath, fileName)
-			logger.Debug("stylesheet XML absolute path: '{p}'", "p", pathToStylesheetXML)
-			logger.Debug("stylesheet XML relative path: '{p}'", "p", fileName)
-
-			xmlFilesReferences[readLinesCount] = FilePathInfo{RelativePath: fileName, AbsolutePath: pathToStylesheetXML}
-		} else {
-			logger.Error("Line '{l}' is of invalid format", "l", line)
-		}
+		return nil, err
 	}
-
-	logger.Info("Read '{n}' lines from '{f}'", "n", readLinesCount, "f", osLocalizedInputFileLocation)
-
-	// Check for any errors encountered during scanning
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading %q: %w", inputFileFullPath, err)
+	datasets := make(map[int]StylesheetDataset, len(rows))
+	for ln, row := range rows {
+		datasets[ln] = StylesheetDataset{Name: strings.TrimSpace(row.Columns[0]), XML: row.Local}
 	}
-
-	// Get absolute paths for validation
-	absolutePaths, err := xmlFilesReferences.Paths("absolute")
-	if err != nil {
-		return fmt.Errorf("error getting absolute paths: %w", err)
+	if external {
+		return datasets, nil
 	}
 
-	logger.Debug("Checking if all '{n}' stylesheet XMLs referenced in '{f}' exist...", "n", readLinesCount, "f", osLocalizedInputFileLocation)
-	checkFilePathsInScript(importDefinition.InputFile, absolutePaths)
-
-	// Get relative paths for comparison
-	relativePaths, err := xmlFilesReferences.Paths("relative")
-	if err != nil {
-		return fmt.Errorf("error getting relative paths: %w", err)
+	r.checkListRows(importDefinition.InputFile, rows)
+	r.checkStylesheetRendering(importDefinition.InputFile, rows)
+	references := make([]string, 0, len(rows))
+	for _, row := range rows {
+		references = append(references, row.Local)
 	}
-
-	logger.Debug("Comparison if all repositry files in '200-Stylesheets' are referenced in '{input}'", "input", osLocalizedInputFileLocation)
-	xmlsLocation := filepath.Join(sourceCodeRoot, osLocalizedXMLsFilePath)
-
-	if err := compareFilesWithScripts(osLocalizedInputFileLocation, relativePaths, xmlsLocation, ignores.StyleSheetsFolder); err != nil {
-		return fmt.Errorf("stylesheet comparison errors: %w", err)
+	if err := r.compareListFolder(importDefinition.InputFile, references, folder, r.ignores.StyleSheetsFolder, true); err != nil {
+		return datasets, fmt.Errorf("stylesheet %w", err)
 	}
-
-	return nil
+	return datasets, nil
 }
 
-func checkStylesheetPaths(scriptFile string, styleSheetImport map[int]StyleSheetImport) {
-	logger.Debug("checking stylesheet import paths for '{s}'", "s", scriptFile)
-	logger.Debug("found stylesheet imports: '{s_imp_def}'", "s_imp_def", styleSheetImport)
+func (r *run) checkStylesheetPaths(scriptFile string, styleSheetImport map[int]StyleSheetImport) {
+	r.log.Debug("checking stylesheet import paths for '{s}'", "s", scriptFile)
+	r.log.Debug("found stylesheet imports: '{s_imp_def}'", "s_imp_def", styleSheetImport)
 	countImportDefs := len(styleSheetImport)
 	index := 1
 
-	for _, importDefinition := range styleSheetImport {
-		osLocalizedInputFileLocation := strings.ReplaceAll(importDefinition.InputFile, convertFrom, convertTo)
-		logger.Debug("input file '{i}' of '{aa}' is '{s}'", "i", index, "aa", countImportDefs, "s", osLocalizedInputFileLocation)
+	// sort by line number and check
+	si := make([]int, 0, len(styleSheetImport))
+	for i := range styleSheetImport {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+
+	for _, lineNumber := range si {
+		importDefinition := styleSheetImport[lineNumber]
+		osLocalizedInputFileLocation := r.localPath(importDefinition.InputFile)
+		r.log.Debug("input file '{i}' of '{aa}' is '{s}'", "i", index, "aa", countImportDefs, "s", osLocalizedInputFileLocation)
 		index++
 
 		// Process each stylesheet import file
-		if err := processStylesheetInputFile(importDefinition); err != nil {
-			logger.Error("Error processing stylesheet import file '{f}': {err}", "f", osLocalizedInputFileLocation, "err", err)
+		datasets, err := r.processStylesheetInputFile(scriptFile, lineNumber, importDefinition)
+		importDefinition.Datasets = datasets
+		styleSheetImport[lineNumber] = importDefinition
+		r.recordDeployedDatasets(importDefinition)
+		r.checkDatasetCollisions(scriptFile, lineNumber, importDefinition)
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleStylesheetInput, Script: scriptFile, Path: importDefinition.InputFile},
+				"Error processing stylesheet import file '{f}': {err}", "f", osLocalizedInputFileLocation, "err", err)
 			// Continue processing other imports despite errors
 			continue
 		}
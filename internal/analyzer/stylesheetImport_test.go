@@ -1,6 +1,9 @@
 package analyzer
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
@@ -84,3 +87,132 @@ func TestPaths_InvalidType(t *testing.T) {
 		t.Errorf("Expected meaningful error message, got: %v", err)
 	}
 }
+
+// writeStylesheetFixture writes the files of a repository below a temporary source code
+// root and prepares the analyzer state of a script for targetOS
+func writeStylesheetFixture(t *testing.T, targetOS string, files map[string]string) string {
+	t.Helper()
+	originalRoot, originalScript, originalOS, originalResult := testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult
+	t.Cleanup(func() {
+		testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS, testRun.analysisResult = originalRoot, originalScript, originalOS, originalResult
+	})
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	testRun.sourceCodeRoot, testRun.currentScript, testRun.currentScriptTargetOS = root, "deploy.bat", targetOS
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": newLines()}}
+	return root
+}
+
+func TestProcessStylesheetInputFile_ScriptNotation(t *testing.T) {
+	// What: Folder and file names in the Windows notation are resolved on any host, once
+	writeStylesheetFixture(t, "windows", map[string]string{
+		"200-Stylesheets/import.txt":        "Nw4Part.Summary,forms\\Nw4Part.xml\n",
+		"200-Stylesheets/forms/Nw4Part.xml": "<rendering/>",
+	})
+
+	datasets, err := testRun.processStylesheetInputFile("deploy.bat", 3, StyleSheetImport{InputFile: `200-Stylesheets\import.txt`, XMLsFilepath: `200-Stylesheets\`})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	// The input file itself is the only file of the folder not listed
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule != RuleUnreferencedFile || f.Path != filepath.Join("200-Stylesheets", "import.txt") {
+			t.Errorf("Unexpected finding %+v", f)
+		}
+	}
+	if xml := datasets[1].XML; xml != filepath.Join("200-Stylesheets", "forms", "Nw4Part.xml") {
+		t.Errorf("Expected the XML rendered for the host, got %q", xml)
+	}
+}
+
+func TestProcessStylesheetInputFile_AbsoluteFolder(t *testing.T) {
+	// What: An absolute folder within the source code root is resolved relative to it
+	root := writeStylesheetFixture(t, "linux", map[string]string{
+		"200-Stylesheets/import.txt":  "Nw4Part.Summary,Nw4Part.xml\nNw4Part.Missing,Missing.xml\n",
+		"200-Stylesheets/Nw4Part.xml": "<rendering/>",
+	})
+
+	testRun.processStylesheetInputFile("deploy.bat", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", XMLsFilepath: filepath.ToSlash(root) + "/200-Stylesheets"})
+	var missing []string
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RuleMissingFile {
+			missing = append(missing, f.Path)
+		}
+	}
+	if len(missing) != 1 || missing[0] != "200-Stylesheets/Missing.xml" {
+		t.Errorf("Expected only Missing.xml to be missing, got %v", missing)
+	}
+}
+
+func TestProcessStylesheetInputFile_OutsideRoot(t *testing.T) {
+	// What: A folder or an XML resolving outside the source code root is a TCX011 finding
+	writeStylesheetFixture(t, "linux", map[string]string{
+		"200-Stylesheets/import.txt": "Nw4Part.Summary,../../Nw4Part.xml\nNw4Part.Form,/opt/Nw4Form.xml\n",
+	})
+
+	datasets, err := testRun.processStylesheetInputFile("deploy.sh", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", XMLsFilepath: "/opt/stylesheets"})
+	if err != nil || datasets != nil {
+		t.Fatalf("Expected the import to be skipped, got %v, %v", datasets, err)
+	}
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RulePathEscapesRoot || testRun.analysisResult.Findings[0].Line != 3 {
+		t.Fatalf("Expected a TCX011 finding for the folder, got %+v", testRun.analysisResult.Findings)
+	}
+
+	testRun.analysisResult.Findings = nil
+	testRun.processStylesheetInputFile("deploy.sh", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", XMLsFilepath: "200-Stylesheets/"})
+	var escaping []int
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RulePathEscapesRoot && f.Script == "200-Stylesheets/import.txt" {
+			escaping = append(escaping, f.Line)
+		}
+	}
+	if len(escaping) != 2 {
+		t.Errorf("Expected TCX011 findings for both lines of the input file, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+func TestIsStylesheetImportLine_ConfiguredImporters(t *testing.T) {
+	// What: Configured importers replace the default utility, each with its own flag names
+	defer func() {
+		testRun.stylesheetImporterSettings = nil
+		testRun.initializeRegexPatterns(nil)
+	}()
+	testRun.stylesheetImporterSettings = []StylesheetImporter{
+		{Utility: "site_install_stylesheets", InputFlag: "-list", FilepathFlag: "xmldir"},
+		{Utility: "install_xml_stylesheet_datasets"},
+	}
+	testRun.initializeRegexPatterns(nil)
+
+	tests := []struct {
+		line, input, filepath string
+		want                  bool
+	}{
+		{`site_install_stylesheets.sh -list="200-Stylesheets/a.txt" -xmldir="200-Stylesheets/"`, "200-Stylesheets/a.txt", "200-Stylesheets/", true},
+		{`site_install_stylesheets.sh -input="200-Stylesheets/a.txt"`, "", "", true},
+		{`install_xml_stylesheet_datasets -input="b.txt" -filepath="200-Stylesheets/"`, "b.txt", "200-Stylesheets/", true},
+		{`plmxml_import -xml_file="a.xml"`, "", "", false},
+	}
+	for _, tt := range tests {
+		var input, filepath string
+		if got := testRun.isStylesheetImportLine(tt.line, &input, &filepath); got != tt.want || input != tt.input || filepath != tt.filepath {
+			t.Errorf("isStylesheetImportLine(%q) = %v, %q, %q", tt.line, got, input, filepath)
+		}
+	}
+}
+
+func TestValidateStylesheetImporters(t *testing.T) {
+	// What: Importers without a utility are rejected
+	if err := ValidateStylesheetImporters([]StylesheetImporter{{Utility: "site_install_stylesheets"}}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := ValidateStylesheetImporters([]StylesheetImporter{{InputFlag: "list"}}); err == nil || !strings.Contains(err.Error(), "missing 'utility'") {
+		t.Errorf("Expected missing utility error, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// <rendering xmlns="http://www.w3.org/1999/xhtml" ...>
+//   <page titleKey="tc_xrt_Overview">
+//     <objectSet source="IMAN_specification.Dataset" defaultdisplay="listDisplay">
+//
+// stylesheet_rendering:
+//   root: rendering
+//   elements:
+//     - name: objectSet
+//       attributes: [source]
+
+// defaultRenderingRoot is the root element of the Teamcenter XML rendering stylesheets
+const defaultRenderingRoot = "rendering"
+
+// ValidateStylesheetRendering checks that each required element and attribute is named
+func ValidateStylesheetRendering(rendering StylesheetRendering) error {
+	for _, attribute := range rendering.Attributes {
+		if strings.TrimSpace(attribute) == "" {
+			return fmt.Errorf("stylesheet rendering has an empty root attribute")
+		}
+	}
+	for i, element := range rendering.Elements {
+		if strings.TrimSpace(element.Name) == "" {
+			return fmt.Errorf("stylesheet rendering element at index %d is missing 'name'", i)
+		}
+		for _, attribute := range element.Attributes {
+			if strings.TrimSpace(attribute) == "" {
+				return fmt.Errorf("stylesheet rendering element '%s' has an empty attribute", element.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// renderingProblems reads a stylesheet XML and returns how it differs from the
+// structure of the rendering configuration: a root element other than the configured
+// one, root attributes missing, required elements absent or occurrences lacking their
+// attributes. Names are compared without their namespace.
+func renderingProblems(r io.Reader, rendering StylesheetRendering) ([]string, error) {
+	root := strings.TrimSpace(rendering.Root)
+	if root == "" {
+		root = defaultRenderingRoot
+	}
+	var problems []string
+	counts := make(map[string]int, len(rendering.Elements))
+	incomplete := make(map[string]int, len(rendering.Elements))
+	decoder := xml.NewDecoder(r)
+	seenRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !seenRoot {
+			seenRoot = true
+			if element.Name.Local != root {
+				problems = append(problems, fmt.Sprintf("root element '%s' instead of '%s'", element.Name.Local, root))
+			}
+			for _, attribute := range missingAttributes(element, rendering.Attributes) {
+				problems = append(problems, fmt.Sprintf("root attribute '%s' missing", attribute))
+			}
+		}
+		for _, required := range rendering.Elements {
+			if element.Name.Local != strings.TrimSpace(required.Name) {
+				continue
+			}
+			counts[required.Name]++
+			if len(missingAttributes(element, required.Attributes)) > 0 {
+				incomplete[required.Name]++
+			}
+		}
+	}
+	if !seenRoot {
+		return nil, fmt.Errorf("no root element")
+	}
+
+	for _, required := range rendering.Elements {
+		switch {
+		case counts[required.Name] == 0:
+			problems = append(problems, fmt.Sprintf("element '%s' missing", required.Name))
+		case incomplete[required.Name] > 0:
+			problems = append(problems, fmt.Sprintf("%d of %d '%s' elements without %s", incomplete[required.Name], counts[required.Name],
+				required.Name, strings.Join(required.Attributes, ", ")))
+		}
+	}
+	return problems, nil
+}
+
+// missingAttributes returns the attributes not set on the element, or set empty
+func missingAttributes(element xml.StartElement, attributes []string) []string {
+	var missing []string
+	for _, name := range attributes {
+		found := false
+		for _, attr := range element.Attr {
+			if attr.Name.Local == strings.TrimSpace(name) && strings.TrimSpace(attr.Value) != "" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, strings.TrimSpace(name))
+		}
+	}
+	return missing
+}
+
+// checkStylesheetRendering checks the structure of the existing XMLs of a stylesheet
+// import definition, so XMLs importing but not rendering are found before deployment.
+// XMLs not found are skipped, they are reported by the file system references check.
+func (r *run) checkStylesheetRendering(inputFile string, rows map[int]listRow) {
+	lineNumbers := make([]int, 0, len(rows))
+	for ln := range rows {
+		lineNumbers = append(lineNumbers, ln)
+	}
+	sort.Ints(lineNumbers)
+
+	for _, ln := range lineNumbers {
+		xmlPath := rows[ln].Reference.AbsolutePath
+		if !r.fileExists(xmlPath) || r.ignoredFor(CheckRendering, xmlPath) {
+			continue
+		}
+		problems, err := r.readRenderingProblems(xmlPath)
+		if err != nil {
+			problems = []string{fmt.Sprintf("not well-formed: %v", err)}
+		}
+		if len(problems) == 0 {
+			r.log.Debug("'{f}' has the structure of a rendering stylesheet", "f", r.localPath(xmlPath))
+			continue
+		}
+		r.reportFinding(Finding{Rule: RuleStylesheetRendering, Script: inputFile, Line: ln, Path: xmlPath},
+			"Line '{ln}': stylesheet '{f}' would not render: {p}", "ln", ln, "f", r.localPath(xmlPath), "p", strings.Join(problems, "; "))
+	}
+}
+
+// readRenderingProblems opens a stylesheet XML and returns its rendering problems
+func (r *run) readRenderingProblems(xmlPath string) ([]string, error) {
+	file, err := os.Open(r.referenceFilePath(r.localPath(xmlPath)))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return renderingProblems(file, r.stylesheetRendering)
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests for the structural check of the stylesheet XMLs
+
+func TestRenderingProblems(t *testing.T) {
+	// What: The root element, its attributes and the required elements are checked, without namespaces
+	rendering := StylesheetRendering{
+		Attributes: []string{"xmlns"},
+		Elements:   []RenderingElement{{Name: "page"}, {Name: "objectSet", Attributes: []string{"source"}}},
+	}
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"complete", `<rendering xmlns="http://www.w3.org/1999/xhtml"><page><objectSet source="IMAN_specification.Dataset"/></page></rendering>`, nil},
+		{"root", `<form xmlns="x"><page/><objectSet source="a"/></form>`, []string{"root element 'form' instead of 'rendering'"}},
+		{"root attribute", `<rendering><page/><objectSet source="a"/></rendering>`, []string{"root attribute 'xmlns' missing"}},
+		{"element", `<rendering xmlns="x"><objectSet source="a"/></rendering>`, []string{"element 'page' missing"}},
+		{"element attribute", `<rendering xmlns="x"><page/><objectSet source="a"/><objectSet source=""/></rendering>`, []string{"1 of 2 'objectSet' elements without source"}},
+	}
+	for _, tt := range tests {
+		got, err := renderingProblems(strings.NewReader(tt.doc), rendering)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRenderingProblems_Malformed(t *testing.T) {
+	// What: Malformed and empty documents are errors
+	for _, doc := range []string{`<rendering><page>`, ``} {
+		if _, err := renderingProblems(strings.NewReader(doc), StylesheetRendering{}); err == nil {
+			t.Errorf("Expected an error for %q", doc)
+		}
+	}
+}
+
+func TestCheckStylesheetRendering(t *testing.T) {
+	// What: XMLs not rendering are TCX037 on their row of the input file, missing XMLs are skipped
+	writeStylesheetFixture(t, "linux", map[string]string{
+		"200-Stylesheets/import.txt":  "Nw4Part.Summary,Nw4Part.xml\nNw4Part.Form,Nw4Form.xml\nNw4Part.Broken,Broken.xml\nNw4Part.Missing,Missing.xml\n",
+		"200-Stylesheets/Nw4Part.xml": "<rendering/>",
+		"200-Stylesheets/Nw4Form.xml": "<html/>",
+		"200-Stylesheets/Broken.xml":  "<rendering>",
+	})
+
+	testRun.processStylesheetInputFile("deploy.sh", 3, StyleSheetImport{InputFile: "200-Stylesheets/import.txt", XMLsFilepath: "200-Stylesheets"})
+	lines := make(map[int]string)
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RuleStylesheetRendering {
+			lines[f.Line] = f.Path
+		}
+	}
+	if len(lines) != 2 || lines[2] != "200-Stylesheets/Nw4Form.xml" || lines[3] != "200-Stylesheets/Broken.xml" {
+		t.Errorf("Expected the form and the broken XML, got %v", lines)
+	}
+}
+
+func TestValidateStylesheetRendering(t *testing.T) {
+	// What: Required elements and attributes need names
+	if err := ValidateStylesheetRendering(StylesheetRendering{Elements: []RenderingElement{{Name: "objectSet", Attributes: []string{"source"}}}}); err != nil {
+		t.Errorf("Expected a valid rendering, got %v", err)
+	}
+	for _, rendering := range []StylesheetRendering{
+		{Attributes: []string{" "}},
+		{Elements: []RenderingElement{{Attributes: []string{"source"}}}},
+		{Elements: []RenderingElement{{Name: "objectSet", Attributes: []string{""}}}},
+	} {
+		if err := ValidateStylesheetRendering(rendering); err == nil {
+			t.Errorf("Expected an error for %+v", rendering)
+		}
+	}
+}
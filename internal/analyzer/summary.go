@@ -0,0 +1,106 @@
+package analyzer
+
+// ScriptSummary counts the results of a single script
+type ScriptSummary struct {
+	Script       string
+	Valid        int // lines with a valid path reference
+	Invalid      int // lines with an invalid path reference
+	Missing      int // referenced paths not found
+	Unreferenced int // repository files not referenced
+	Remote       int // lines referencing a URL
+	Errors       int // error findings
+	Warnings     int // warning findings
+
+	Skipped map[string]int // lines not analyzed, by category (SkipComment, ...)
+	Metrics ScriptMetrics
+}
+
+// Summary is the outcome of the run. With thresholds configured the run passes when
+// none is exceeded; without, it passes when there are no error findings.
+type Summary struct {
+	Scripts  []ScriptSummary
+	Errors   int
+	Warnings int
+	Passed   bool
+	Stopped  string // why the run stopped early (max_findings, fail_fast), which fails it
+}
+
+// Verdict returns PASS or FAIL
+func (s Summary) Verdict() string {
+	if s.Passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// summarize counts the results of the scripts in configuration order
+func (r *run) summarize(scripts []scriptDefinition, limits thresholds, thresholdsErr error) Summary {
+	var summary Summary
+	index := make(map[string]int, len(scripts))
+	for _, script := range scripts {
+		lines := r.analysisResult.File[script.Filename]
+		index[script.Filename] = len(summary.Scripts)
+		valid, remote := lines.referenceLines()
+		summary.Scripts = append(summary.Scripts, ScriptSummary{
+			Script:       script.Filename,
+			Valid:        valid,
+			Invalid:      len(lines.InvalidLines()),
+			Missing:      len(lines.Missing),
+			Unreferenced: len(lines.Unreferenced),
+			Remote:       remote,
+			Skipped:      countSkipReasons(lines.SkipReasons),
+			Metrics:      scriptMetrics(lines, script.TargetOS),
+		})
+	}
+
+	for _, f := range r.analysisResult.Findings {
+		i, perScript := index[f.Script]
+		switch f.Severity {
+		case SeverityError:
+			summary.Errors++
+			if perScript {
+				summary.Scripts[i].Errors++
+			}
+		case SeverityWarning:
+			summary.Warnings++
+			if perScript {
+				summary.Scripts[i].Warnings++
+			}
+		}
+	}
+
+	if thresholdsConfigured(limits) {
+		summary.Passed = thresholdsErr == nil
+	} else {
+		summary.Passed = summary.Errors == 0
+	}
+	// Findings past the stop are unknown, an incomplete run does not pass
+	if r.runStopped() {
+		summary.Stopped, summary.Passed = r.stopReason, false
+	}
+	return summary
+}
+
+// logSummary writes the SUMMARY block closing the log
+func (r *run) logSummary(summary Summary) {
+	r.log.Heading(" ")
+	r.log.Separate("SUMMARY")
+	r.log.Separate("=====================================")
+	for _, s := range summary.Scripts {
+		r.log.Separate("'{s}': {v} valid, {i} invalid, {m} missing, {u} unreferenced ({e} errors, {w} warnings)",
+			"s", s.Script, "v", s.Valid, "i", s.Invalid, "m", s.Missing, "u", s.Unreferenced, "e", s.Errors, "w", s.Warnings)
+		r.log.Separate("  metrics: {l} lines, {u} utility invocations, {f} distinct files, conditional depth {d}",
+			"l", s.Metrics.Lines, "u", s.Metrics.UtilityInvocations, "f", s.Metrics.DistinctFiles, "d", s.Metrics.ConditionalDepth)
+		if s.Remote > 0 {
+			r.log.Separate("  remote references: {r}", "r", s.Remote)
+		}
+		if len(s.Skipped) > 0 {
+			r.log.Separate("  skipped lines: {c}", "c", formatSkipCounts(s.Skipped))
+		}
+	}
+	r.log.Separate("Total: {e} errors, {w} warnings", "e", summary.Errors, "w", summary.Warnings)
+	if summary.Stopped != "" {
+		r.log.Separate("Run stopped early, {r}", "r", summary.Stopped)
+	}
+	r.log.Separate("Result: {r}", "r", summary.Verdict())
+}
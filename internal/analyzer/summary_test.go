@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func setupSummaryTest() []scriptDefinition {
+	shLines := newLines()
+	shLines.Flags[1] = []PathFlag{{Line: 1, Path: "a.xml"}}
+	shLines.Flags[2] = []PathFlag{{Line: 2, Path: "b.xml"}}
+	shLines.Flags[3] = []PathFlag{{Line: 3, Rule: RuleFlagNotQuoted}}
+	shLines.Flags[4] = []PathFlag{{Line: 4, Path: "$f/a.xml", Loop: true}}
+	shLines.LoopReference[4] = LoopReference{Variable: "f"}
+	shLines.Missing = []string{"e.xml"}
+	shLines.Unreferenced = []string{"c.xml", "d.xml"}
+
+	testRun.analysisResult = Result{
+		File: map[string]Lines{"deploy.sh": shLines, "deploy.bat": newLines()},
+		Findings: []Finding{
+			{Rule: RuleMissingFile, Severity: SeverityError, Script: "deploy.sh"},
+			{Rule: RuleWorldWritable, Severity: SeverityWarning, Script: "deploy.sh"},
+			{Rule: RuleDuplicateContent, Severity: SeverityInfo, Script: "deploy.bat"},
+			{Rule: RulePathParity, Severity: SeverityError},
+		},
+	}
+	return []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}, {Filename: "deploy.bat", TargetOS: "windows"}}
+}
+
+// What: Per-script counts and totals are collected in configuration order
+func TestSummarize_Counts(t *testing.T) {
+	scripts := setupSummaryTest()
+
+	summary := testRun.summarize(scripts, thresholds{}, nil)
+
+	expected := []ScriptSummary{
+		{Script: "deploy.sh", Valid: 3, Invalid: 1, Missing: 1, Unreferenced: 2, Errors: 1, Warnings: 1, Metrics: ScriptMetrics{DistinctFiles: 3}},
+		{Script: "deploy.bat"},
+	}
+	if !reflect.DeepEqual(summary.Scripts, expected) {
+		t.Errorf("Scripts = %+v, want %+v", summary.Scripts, expected)
+	}
+	if summary.Errors != 2 || summary.Warnings != 1 {
+		t.Errorf("Expected 2 errors and 1 warning, got %d and %d", summary.Errors, summary.Warnings)
+	}
+}
+
+// What: Without thresholds any error fails the run
+func TestSummarize_VerdictWithoutThresholds(t *testing.T) {
+	scripts := setupSummaryTest()
+
+	if summary := testRun.summarize(scripts, thresholds{}, nil); summary.Passed || summary.Verdict() != "FAIL" {
+		t.Errorf("Expected FAIL with error findings, got %s", summary.Verdict())
+	}
+
+	testRun.analysisResult.Findings = []Finding{{Rule: RuleWorldWritable, Severity: SeverityWarning}}
+	if summary := testRun.summarize(scripts, thresholds{}, nil); !summary.Passed || summary.Verdict() != "PASS" {
+		t.Errorf("Expected PASS with warnings only, got %s", summary.Verdict())
+	}
+}
+
+// What: With thresholds the threshold check decides the verdict
+func TestSummarize_VerdictWithThresholds(t *testing.T) {
+	scripts := setupSummaryTest()
+	limit := 5
+	limits := thresholds{MaxUnreferencedFiles: &limit}
+
+	if summary := testRun.summarize(scripts, limits, nil); !summary.Passed {
+		t.Error("Expected PASS when no threshold is exceeded")
+	}
+	if summary := testRun.summarize(scripts, limits, errors.New("1 threshold(s) exceeded")); summary.Passed {
+		t.Error("Expected FAIL when a threshold is exceeded")
+	}
+}
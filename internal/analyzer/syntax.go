@@ -8,21 +8,11 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
 )
 
-// Package-level regex patterns (compiled once for performance)
-var (
-	parameterFlagPatterns  map[string]*regexp.Regexp // flagName -> regex for `-flagname`
-	parameterValuePatterns map[string]*regexp.Regexp // flagName -> regex for `-flagname="value"`
-	stylesheetUtilityRegex *regexp.Regexp
-	stylesheetFlagsRegex   *regexp.Regexp
-)
-
-// Track executables per script for parity checking
-var scriptExecutables map[string]map[string]bool // scriptFile -> set of unique executables
-
 // Common shell commands to ignore when tracking executables
 var shellCommands = map[string]bool{
 	"echo": true, "cd": true, "mkdir": true, "rm": true, "cp": true, "mv": true,
@@ -32,83 +22,249 @@ var shellCommands = map[string]bool{
 	"pushd": true, "popd": true, "exit": true, "return": true,
 }
 
-// Store current script's target OS for validation
-var currentScriptTargetOS string
-
 // initializeRegexPatterns compiles all regex patterns once for efficiency
-func initializeRegexPatterns(parameters []string) {
-	parameterFlagPatterns = make(map[string]*regexp.Regexp)
-	parameterValuePatterns = make(map[string]*regexp.Regexp)
+func (r *run) initializeRegexPatterns(parameters []string) {
+	r.parameterFlagPatterns = make(map[string]*regexp.Regexp)
+	r.parameterValuePatterns = make(map[string]*regexp.Regexp)
 
 	for _, flagName := range parameters {
-		// Compile pattern for checking if flag exists: -flagname
-		flagPattern := fmt.Sprintf(`-%s`, regexp.QuoteMeta(flagName))
-		parameterFlagPatterns[flagName] = regexp.MustCompile(flagPattern)
+		// Compile pattern for checking if flag exists: -flagname as a whole word
+		flagPattern := r.flagPrefix(flagName) + `(?:[=\s"']|$)`
+		r.parameterFlagPatterns[flagName] = regexp.MustCompile(flagPattern)
 
 		// Compile pattern for extracting value: -flagname="value"
-		valuePattern := fmt.Sprintf(`-%s="([^"]+)"`, regexp.QuoteMeta(flagName))
-		parameterValuePatterns[flagName] = regexp.MustCompile(valuePattern)
+		valuePattern, _ := parameterValuePattern(PathParameter{Name: flagName}, r.flagPrefix(flagName))
+		r.parameterValuePatterns[flagName] = regexp.MustCompile(valuePattern)
 	}
 
 	// Compile stylesheet-specific patterns
-	stylesheetUtilityRegex = regexp.MustCompile(`install_xml_stylesheet_datasets`)
-	stylesheetFlagsRegex = regexp.MustCompile(`-input="([^"]+)"|-filepath="([^"]+)"`)
+	r.stylesheetImporters = compileStylesheetImporters(r.stylesheetImporterSettings)
+	r.listImports = r.compileListImports(r.listImportSettings)
+	r.stylesheetReplaceRegex = regexp.MustCompile(`(?:^|\s)-replace(?:\s|$)`)
+
+	// Compile PLMXML/TCXML import utility pattern
+	r.xmlImportUtilityRegex = regexp.MustCompile(`(?i)\b(plmxml_import|tcxml_import)\b`)
+
+	// Compile BMIDE template installer patterns: -templates=a,b -path="dir"
+	r.templateFlagsRegex = regexp.MustCompile(`-(templates|path)=(?:"([^"]*)"|(\S+))`)
+}
+
+// pathParameterNames returns the flag names of the configured path parameters
+func pathParameterNames(parameters []PathParameter) []string {
+	names := make([]string, 0, len(parameters))
+	for _, p := range parameters {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// flagPrefix returns the pattern of a flag name starting a word: -name, and --name
+// with GNU-style long options. -name does not match inside -names or --name otherwise.
+func (r *run) flagPrefix(flagName string) string {
+	dashes := "-"
+	if r.gnuLongOptions {
+		dashes = "--?"
+	}
+	return `(?:^|[^\w-])` + dashes + regexp.QuoteMeta(flagName)
+}
+
+// characterColumn returns the 1-based character column of a byte offset in line
+func characterColumn(line string, offset int) int {
+	return utf8.RuneCountInString(line[:offset]) + 1
+}
+
+// flagColumn returns the column of the flag found at location in line. The flag
+// pattern may include the character before the flag; the dash marks its start.
+func flagColumn(line string, location []int) int {
+	offset := location[0]
+	if dash := strings.IndexByte(line[location[0]:location[1]], '-'); dash > 0 {
+		offset += dash
+	}
+	return characterColumn(line, offset)
+}
+
+// parameterValuePattern returns the value extraction pattern of a path parameter
+// written after flag, the pattern of its flag name
+func parameterValuePattern(p PathParameter, flag string) (string, error) {
+	if p.Regex != "" {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return "", fmt.Errorf("path parameter '%s': invalid regex: %w", p.Name, err)
+		}
+		if re.NumSubexp() < 1 {
+			return "", fmt.Errorf("path parameter '%s': regex must have a capture group for the path", p.Name)
+		}
+		return p.Regex, nil
+	}
+	switch p.Style {
+	case "", "equals_quoted":
+		return flag + `="([^"]+)"`, nil
+	case "space_quoted":
+		return flag + `\s+"([^"]+)"`, nil
+	case "bare":
+		return flag + `(?:=|\s+)"?([^"\s]+)"?`, nil
+	default:
+		return "", fmt.Errorf("path parameter '%s': invalid style '%s' (must be 'equals_quoted', 'space_quoted' or 'bare')", p.Name, p.Style)
+	}
+}
+
+// ValidatePathParameters checks the styles and custom regexes of the path parameters.
+// The flag prefix is left to the run, so the configuration is validated without
+// reading the state of a run of another goroutine.
+func ValidatePathParameters(parameters []PathParameter) error {
+	for _, p := range parameters {
+		if _, err := parameterValuePattern(p, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyParameterStyles replaces the value patterns of path parameters configured
+// with a style or a custom regex
+func (r *run) applyParameterStyles(parameters []PathParameter) error {
+	for _, p := range parameters {
+		if p.Style == "" && p.Regex == "" {
+			continue
+		}
+		pattern, err := parameterValuePattern(p, r.flagPrefix(p.Name))
+		if err != nil {
+			return err
+		}
+		r.parameterValuePatterns[p.Name] = regexp.MustCompile(pattern)
+		if p.Regex != "" {
+			// the flag shape is unknown, a custom regex is its own presence check
+			r.parameterFlagPatterns[p.Name] = r.parameterValuePatterns[p.Name]
+		}
+	}
+	return nil
 }
 
-func checkFileSyntax(filePath string, sourceCodeRoot string, targetOS string) {
+func (r *run) checkFileSyntax(filePath string, sourceCodeRoot string, targetOS string) {
 
 	// Set current script's target OS for validation
-	currentScriptTargetOS = targetOS
+	r.currentScriptTargetOS = targetOS
 
-	fullPath := filepath.Join(sourceCodeRoot, filePath)
+	fullPath := r.scriptFilePath(sourceCodeRoot, filePath)
 
-	file, err := os.Open(fullPath)
+	data, err := os.ReadFile(fullPath)
 	if err != nil {
-		logger.Error("Error opening '{f}'. {e}.", "f", filePath, "e", err.Error())
+		r.reportFinding(Finding{Rule: RuleScriptUnreadable, Script: filePath, Path: filePath},
+			"Error opening '{f}'. {e}.", "f", filePath, "e", err.Error())
 		return
 	}
-	defer file.Close()
 
-	// Read lines from the file
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
+	// Scripts edited with Windows tools may be UTF-16 or Windows-1252 encoded
+	content, encoding := decodeScript(data)
+	r.log.Debug("'{f}' is encoded in {e}", "f", filePath, "e", encoding)
+	r.reportScriptEncoding(filePath, encoding)
+
+	r.parseScriptContent(filePath, content, targetOS)
+
+	r.log.Info("valid lines")
+	r.logValidationResults("valid", filePath)
+	r.log.Info("stylesheet import")
+	r.logValidationResults("stylesheet import", filePath)
+	r.log.Separate("lines with invalid syntax of referenced filepaths")
+	hasInvalidLines := r.logValidationResults("invalid", filePath)
+	if !hasInvalidLines {
+		r.log.Separate("none")
+	}
+	r.log.Info("skipped lines")
+	r.logValidationResults("skipped", filePath)
+	if counts := countSkipReasons(r.analysisResult.File[filePath].SkipReasons); len(counts) > 0 {
+		r.log.Info("skipped lines by category: {c}", "c", formatSkipCounts(counts))
+	}
+}
+
+// Longest script line read, longer lines end the parsing of the script with TCX004
+const maxScriptLineLength = 1 << 20
 
+// parseScriptContent parses the lines of a decoded script: the classification of the
+// lines into valid, invalid and skipped ones and the checks of the commands
+func (r *run) parseScriptContent(filePath, content, targetOS string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScriptLineLength)
+	lineNumber := 0
+	blocks := blockTracker{targetOS: targetOS}
+	loops := loopTracker{targetOS: targetOS}
+	var heredocs heredocTracker
+	dirs := r.newDirTracker(targetOS, filePath, r.scriptWorkingDir)
+	defer func() { r.activeLoops, r.activeWorkingDir = nil, nil }()
+
+	// whether the previous line continues on the current one
+	continued := false
 	for scanner.Scan() {
 		lineNumber++
-		line := scanner.Text()
+		line := r.renderTemplateLine(filePath, scanner.Text(), lineNumber)
+		r.recordLineText(filePath, lineNumber, line)
+		wasContinued := continued
+		continued = continuesLine(line, targetOS)
 		if strings.TrimSpace(line) == "" {
+			r.recordSkipReason(filePath, lineNumber, SkipBlank)
+			continue
+		}
+		if r.isTemplateStatement(line) {
+			r.log.Debug("line '{ln} {l}' is a template statement", "ln", lineNumber, "l", line)
+			r.analysisResult.File[filePath].Skipped[lineNumber] = line
+			r.recordSkipReason(filePath, lineNumber, SkipTemplate)
+			continue
+		}
+		// Heredoc bodies are input of a command, not commands
+		if targetOS == "linux" && heredocs.update(line) {
+			r.log.Debug("line '{ln} {l}' is part of a heredoc", "ln", lineNumber, "l", line)
+			r.analysisResult.File[filePath].Skipped[lineNumber] = line
+			r.recordSkipReason(filePath, lineNumber, SkipHeredoc)
+			if r.scanHeredocs {
+				r.scanHeredocLine(filePath, line, lineNumber)
+			}
 			continue
 		}
-		parseLineAsCommand(filePath, line, lineNumber)
+		// Comments are not parsed; the command part of a line with an inline comment is
+		code, hasComment := stripComment(line, targetOS)
+		if hasComment && strings.TrimSpace(code) == "" {
+			r.log.Debug("line '{ln} {l}' is a comment", "ln", lineNumber, "l", line)
+			r.analysisResult.File[filePath].Skipped[lineNumber] = line
+			r.recordSkipReason(filePath, lineNumber, SkipComment)
+			continue
+		}
+		line = code
+		if blocks.update(line) {
+			r.analysisResult.File[filePath].Conditional[lineNumber] = true
+		}
+		r.recordConditionalDepth(filePath, blocks.depth())
+		r.activeLoops = loops.update(line)
+		r.activeWorkingDir = dirs.update(line)
+		r.parseLineAsCommand(filePath, line, lineNumber)
+		r.checkAllowedExecutable(filePath, line, lineNumber, wasContinued)
+		r.checkBinPrefix(filePath, line, lineNumber, wasContinued)
+		r.checkCredentialFlags(filePath, line, lineNumber)
+		r.checkPreferenceFlags(filePath, line, lineNumber)
+		r.checkContentRules(filePath, line, lineNumber)
+		if _, skipped := r.analysisResult.File[filePath].Skipped[lineNumber]; skipped {
+			r.recordSkipReason(filePath, lineNumber, commandSkipReason(line, wasContinued))
+		}
 	}
-
-	logger.Info("valid lines")
-	logValidationResults("valid", filePath)
-	logger.Info("stylesheet import")
-	logValidationResults("stylesheet import", filePath)
-	logger.Separate("lines with invalid syntax of referenced filepaths")
-	hasInvalidLines := logValidationResults("invalid", filePath)
-	if !hasInvalidLines {
-		logger.Separate("none")
+	if err := scanner.Err(); err != nil {
+		r.reportFinding(Finding{Rule: RuleScriptUnreadable, Script: filePath, Line: lineNumber + 1, Path: filePath},
+			"Error reading '{f}' after line '{ln}'. {e}.", "f", filePath, "ln", lineNumber, "e", err.Error())
 	}
-	logger.Info("skipped lines")
-	logValidationResults("skipped", filePath)
 }
 
-func logValidationResults(lineType string, filePath string) bool {
+func (r *run) logValidationResults(lineType string, filePath string) bool {
 	var lines map[int]string
 	switch lineType {
 	case "valid":
-		lines = analysisResult.File[filePath].Valid
+		return r.logValidFlags(filePath)
 	case "invalid":
-		lines = analysisResult.File[filePath].Invalid
+		lines = r.analysisResult.File[filePath].InvalidLines()
 	case "skipped":
-		lines = analysisResult.File[filePath].Skipped
+		lines = r.analysisResult.File[filePath].Skipped
 	case "stylesheet import":
-		lines = analysisResult.File[filePath].GetStyleSheetImportLines()
+		lines = r.analysisResult.File[filePath].GetStyleSheetImportLines()
 	}
 	if len(lines) == 0 {
-		logger.Info("No {lt} entries found", "lt", lineType)
+		r.log.Info("No {lt} entries found", "lt", lineType)
 		return false
 	}
 
@@ -121,120 +277,349 @@ func logValidationResults(lineType string, filePath string) bool {
 	for _, i := range si {
 
 		if lineType == "invalid" {
-			logger.Error("'{f}' line '{ln}' is invalid: '{val}'", "f", filePath, "ln", i, "val", lines[i])
+			r.log.Error("'{f}' line '{ln}' is invalid: '{val}'", "f", filePath, "ln", i, "val", lines[i])
 		} else {
-			logger.Info("'{f}' line '{ln}' is valid: '{val}'", "f", filePath, "ln", i, "val", lines[i])
+			r.log.Info("'{f}' line '{ln}' is valid: '{val}'", "f", filePath, "ln", i, "val", lines[i])
 		}
 	}
 	return true
 }
 
-func parseLineAsCommand(file string, line string, lineNumber int) {
+// logValidFlags logs the paths of the valid path flags of a script, in line order
+func (r *run) logValidFlags(filePath string) bool {
+	flags := r.analysisResult.File[filePath].ValidFlags()
+	if len(flags) == 0 {
+		r.log.Info("No valid entries found")
+		return false
+	}
+	for _, flag := range flags {
+		r.log.Info("'{f}' line '{ln}' is valid: '{val}'", "f", filePath, "ln", flag.Line, "val", flag.Path)
+	}
+	return true
+}
 
-	logger.Debug("parsing line '{ln} {l}'", "ln", lineNumber, "l", line)
+func (r *run) parseLineAsCommand(file string, line string, lineNumber int) {
+
+	r.log.Debug("parsing line '{ln} {l}'", "ln", lineNumber, "l", line)
 
 	// Track executables for parity check
-	trackExecutable(file, line)
+	r.trackExecutable(file, line, lineNumber)
+	if executable := extractExecutableName(line); executable != "" {
+		r.analysisResult.File[file].Utility[lineNumber] = executable
+	}
+	r.checkFlagRules(file, line, lineNumber)
 
-	var skipLine bool = true
+	// Record BMIDE template installations
+	var install TemplateInstall
+	if r.isTemplateInstallLine(line, &install) {
+		install.PackagePath = r.resolveWorkingDir(install.PackagePath)
+		r.analysisResult.File[file].TemplateInstall[lineNumber] = install
+	}
 
-	for _, flagName := range pathParameters {
+	// Record the calls of list import utilities, their list flag need not be a path parameter
+	call, listed := r.listImportLine(line)
+	if listed {
+		r.analysisResult.File[file].ListImport[lineNumber] = call
+	}
 
-		logger.Debug("searching for flag '{f}'", "f", flagName)
+	var skipLine bool = !listed
+
+	// Each path flag of the line is classified, in line order: a line can reference
+	// valid paths and have invalid flags, with a finding per invalid flag
+	for _, found := range r.findPathFlags(line) {
+		skipLine = false // do not capture this line as skip line
+		flag := r.classifyPathFlag(file, line, lineNumber, found)
+		r.analysisResult.File[file].Flags[lineNumber] = append(r.analysisResult.File[file].Flags[lineNumber], flag)
+	}
+
+	if skipLine {
+		r.log.Debug("line '{ln} {l}' does not contain any flag of interest", "ln", lineNumber, "l", line)
+		r.analysisResult.File[file].Skipped[lineNumber] = line
+	}
+
+}
+
+// foundFlag is a path parameter found on a line, at the location of its flag
+type foundFlag struct {
+	name     string
+	location []int
+}
+
+// findPathFlags returns the path parameters present on a line, in line order. A flag
+// given several times on the line is returned for each occurrence.
+func (r *run) findPathFlags(line string) []foundFlag {
+	var found []foundFlag
+	for _, flagName := range r.pathParameters {
+		r.log.Debug("searching for flag '{f}'", "f", flagName)
 
 		// Use pre-compiled regex (no compilation in loop!)
-		re := parameterFlagPatterns[flagName]
-		matches := re.FindStringSubmatch(line)
-		logger.Debug("'{lm}' matches found for flag '{f}'", "lm", len(matches), "f", flagName)
-
-		// Check if the line contains our flags of interest
-		if len(matches) < 1 {
-			// Continue and try with the next parameter
-			logger.Debug("no '{p}' flag found ...", "p", flagName)
-			continue
+		locations := r.parameterFlagPatterns[flagName].FindAllStringIndex(line, -1)
+		for _, location := range locations {
+			found = append(found, foundFlag{flagName, location})
+		}
+		if len(locations) == 0 {
+			r.log.Debug("no '{p}' flag found ...", "p", flagName)
 		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].location[0] < found[j].location[0] })
+	return found
+}
 
-		logger.Debug("checking if the '-{f}' flag definition is properly formatted", "f", flagName)
+// classifyPathFlag checks the formatting and the path of a path flag of a line and
+// returns it classified. Every flag is classified on its own, a line can reference
+// several valid paths and have invalid flags; the line-level records are made for its
+// first valid path.
+func (r *run) classifyPathFlag(file, line string, lineNumber int, found foundFlag) PathFlag {
+	flagName := found.name
+	lines := r.analysisResult.File[file]
+	r.log.Debug("checking if the '-{f}' flag definition is properly formatted", "f", flagName)
+
+	// Use pre-compiled regex for value extraction, the value of this flag follows it
+	start := found.location[0]
+	valueLocation := r.parameterValuePatterns[flagName].FindStringSubmatchIndex(line[start:])
+	for i := range valueLocation {
+		if valueLocation[i] >= 0 {
+			valueLocation[i] += start
+		}
+	}
 
-		// Use pre-compiled regex for value extraction
-		re = parameterValuePatterns[flagName]
-		matches = re.FindStringSubmatch(line)
+	// Check if the flag found is properly formatted
+	if len(valueLocation) < 4 || valueLocation[0] != start || valueLocation[2] < 0 {
+		r.log.Debug("line '{l}': '-{s}' is present but not quoted properly", "l", lineNumber, "s", flagName)
+		column := flagColumn(line, found.location)
+		r.recordFinding(Finding{Rule: RuleFlagNotQuoted, Script: file, Line: lineNumber, Column: column,
+			Message:    logger.Format("'{f}' line '{ln}' is invalid: '-{s}' is present but not quoted properly", "f", file, "ln", lineNumber, "s", flagName),
+			Suggestion: logger.Format("use -{s}=\"<path>\"", "s", flagName)})
+		return PathFlag{Line: lineNumber, Flag: flagName, Column: column, Rule: RuleFlagNotQuoted}
+	}
 
-		logger.Debug("'{lm}' matches found", "lm", len(matches))
+	// Extract the file path
+	r.log.Debug("Formatting correct, extracting the file path in '-{f}'...", "f", flagName)
+	filePath := line[valueLocation[2]:valueLocation[3]]
+	column := characterColumn(line, valueLocation[2])
+	r.log.Debug("filepath is: '{fp}'", "fp", filePath)
+
+	// URLs are downloaded by the script, they are neither validated as paths nor
+	// checked on the file system
+	if isRemoteReference(filePath) {
+		r.log.Debug("line '{ln}': '{fp}' is a remote reference", "ln", lineNumber, "fp", filePath)
+		return PathFlag{Line: lineNumber, Flag: flagName, Path: filePath, Column: column, Remote: true}
+	}
 
-		// Check if the flag found is properly formatted
-		if len(matches) < 2 {
-			logger.Debug("line '{l}': '-{s}' is present but not quoted properly", "l", lineNumber, "s", flagName)
-			analysisResult.File[file].Invalid[lineNumber] = line
-			skipLine = false // do not capture this line as skip line
-			break
-		} else if len(matches) == 2 {
-			// Extract the file path
-			logger.Debug("Formatting correct, extracting the file path in '-{f}'...", "f", flagName)
-			filePath := matches[1]
-			logger.Debug("filepath is: '{fp}'", "fp", filePath)
-
-			// Validate path separators match target OS
-			if err := validatePathSeparators(filePath, currentScriptTargetOS, lineNumber); err != nil {
-				logger.Error("'{f}' {e}", "f", file, "e", err.Error())
-				analysisResult.File[file].Invalid[lineNumber] = line + " [" + err.Error() + "]"
-				skipLine = false
-				break
-			}
+	// Validate path separators and Windows path roots match target OS
+	rule := RuleWrongSeparator
+	err := validatePathSeparators(filePath, r.currentScriptTargetOS, lineNumber)
+	if err == nil {
+		rule = RuleWindowsPathRoot
+		err = r.validateWindowsPathRoot(filePath, r.currentScriptTargetOS, lineNumber)
+	}
+	if err != nil {
+		r.reportFinding(Finding{Rule: rule, Script: file, Line: lineNumber, Column: column, Path: filePath},
+			"'{f}' {e}", "f", file, "e", err.Error())
+		return PathFlag{Line: lineNumber, Flag: flagName, Path: filePath, Column: column, Rule: rule, Problem: err.Error()}
+	}
 
-			analysisResult.File[file].Valid[lineNumber] = filePath
+	// Relative paths are referenced from the working directory of the line
+	filePath = r.resolveWorkingDir(filePath)
+	flag := PathFlag{Line: lineNumber, Flag: flagName, Path: filePath, Column: column}
+
+	// Paths built from template expressions or from a for-loop variable are expanded
+	// after the syntax check; a line records the first of them
+	ref, ok := r.templateReference(filePath)
+	if ok {
+		r.log.Debug("line '{ln}': '{fp}' uses template expressions '{v}'", "ln", lineNumber, "fp", filePath, "v", ref.Variable)
+	} else if ref, ok = r.loopReference(filePath); ok {
+		r.log.Debug("line '{ln}': '{fp}' uses loop variable '{v}'", "ln", lineNumber, "fp", filePath, "v", ref.Variable)
+	}
+	if ok {
+		flag.Loop = true
+		if _, recorded := lines.LoopReference[lineNumber]; !recorded {
+			ref.Column = column
+			lines.LoopReference[lineNumber] = ref
+		}
+		return flag
+	}
 
-			skipLine = false // do not capture this line as skip line
+	if hasValidFlag(lines.Flags[lineNumber]) {
+		return flag
+	}
 
-			logger.Debug("is the line defining a call to 'install_xml_stylesheet_datasets' utility?")
-			var (
-				inputFile           string
-				stylesheetsFilepath string
-			)
-			if isStylesheetImportLine(line, &inputFile, &stylesheetsFilepath) {
-				analysisResult.File[file].StyleSheetImport[lineNumber] = StyleSheetImport{
-					Line:         line,
-					XMLsFilepath: stylesheetsFilepath,
-					InputFile:    inputFile,
-				}
-			}
-			break
+	r.log.Debug("is the line defining a call to a stylesheet import utility?")
+	var (
+		inputFile           string
+		stylesheetsFilepath string
+	)
+	if r.isStylesheetImportLine(line, &inputFile, &stylesheetsFilepath) {
+		lines.StyleSheetImport[lineNumber] = StyleSheetImport{
+			Line:         line,
+			XMLsFilepath: r.resolveWorkingDir(stylesheetsFilepath),
+			InputFile:    r.resolveWorkingDir(inputFile),
+			Replace:      r.stylesheetReplaceRegex.MatchString(line),
 		}
 	}
 
-	if skipLine {
-		logger.Debug("line '{ln} {l}' does not contain any flag of interest", "ln", lineNumber, "l", line)
-		analysisResult.File[file].Skipped[lineNumber] = line
+	r.log.Debug("is the line defining a call to 'plmxml_import' or 'tcxml_import' utility?")
+	if utility := r.xmlImportUtility(line); utility != "" {
+		lines.XMLImport[lineNumber] = XMLImport{Utility: utility, Path: filePath, Column: column}
 	}
-
+	if isPreferenceImportLine(line) {
+		lines.PreferenceImport[lineNumber] = filePath
+	}
+	return flag
 }
 
-func isStylesheetImportLine(line string, input *string, filepath *string) bool {
+func (r *run) isStylesheetImportLine(line string, input *string, filepath *string) bool {
 	// Use pre-compiled regex
-	if !stylesheetUtilityRegex.MatchString(line) {
-		logger.Debug("'{l}' does not contain 'install_xml_stylesheet_datasets'", "l", line)
+	var importer *stylesheetImporter
+	for i := range r.stylesheetImporters {
+		if r.stylesheetImporters[i].utilityRegex.MatchString(line) {
+			importer = &r.stylesheetImporters[i]
+			break
+		}
+	}
+	if importer == nil {
+		r.log.Debug("'{l}' does not contain a stylesheet import utility", "l", line)
 		return false
 	}
-	logger.Debug("'{l}' is refering to 'install_xml_stylesheet_datasets'", "l", line)
-	logger.Debug("Extracting the values for input and filepath flags...")
+	r.log.Debug("'{l}' is refering to '{u}'", "l", line, "u", importer.Utility)
+	r.log.Debug("Extracting the values for input and filepath flags...")
 
 	// Use pre-compiled regex
-	matches := stylesheetFlagsRegex.FindAllStringSubmatch(line, -1)
+	matches := importer.flagsRegex.FindAllStringSubmatch(line, -1)
 
-	logger.Debug("Match is '{m}'", "m", matches)
+	r.log.Debug("Match is '{m}'", "m", matches)
 	for _, match := range matches {
 		if match[1] != "" {
-			logger.Debug("Value of -input flag is is '{v}'", "v", match[1])
+			r.log.Debug("Value of -{f} flag is is '{v}'", "f", importer.InputFlag, "v", match[1])
 			*input = match[1]
 		}
 		if match[2] != "" {
-			logger.Debug("Value of -filepath flag is is '{v}'", "v", match[2])
+			r.log.Debug("Value of -{f} flag is is '{v}'", "f", importer.FilepathFlag, "v", match[2])
 			*filepath = match[2]
 		}
 	}
 	return true
 }
 
+// xmlImportUtility returns the XML import utility ("plmxml_import" or "tcxml_import")
+// invoked by the line, or an empty string if the line does not invoke one
+func (r *run) xmlImportUtility(line string) string {
+	match := r.xmlImportUtilityRegex.FindStringSubmatch(line)
+	if match == nil {
+		r.log.Debug("'{l}' does not contain 'plmxml_import' or 'tcxml_import'", "l", line)
+		return ""
+	}
+	r.log.Debug("'{l}' is refering to '{u}'", "l", line, "u", match[1])
+	return strings.ToLower(match[1])
+}
+
+// isTemplateInstallLine reports whether the line invokes the BMIDE template installer
+// (tem.sh / tem.bat) and extracts the -templates and -path flag values into install
+func (r *run) isTemplateInstallLine(line string, install *TemplateInstall) bool {
+	if extractExecutableName(line) != "tem" {
+		return false
+	}
+	r.log.Debug("'{l}' is refering to the BMIDE template installer", "l", line)
+
+	install.Line = line
+	for _, match := range r.templateFlagsRegex.FindAllStringSubmatch(line, -1) {
+		value := match[2]
+		if value == "" {
+			value = match[3]
+		}
+		switch match[1] {
+		case "templates":
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					install.Templates = append(install.Templates, name)
+				}
+			}
+		case "path":
+			install.PackagePath = value
+		}
+	}
+	r.log.Debug("templates '{t}' in package path '{p}'", "t", install.Templates, "p", install.PackagePath)
+	return true
+}
+
+// hasValidFlag reports whether one of the flags is valid
+func hasValidFlag(flags []PathFlag) bool {
+	for _, flag := range flags {
+		if flag.IsValid() {
+			return true
+		}
+	}
+	return false
+}
+
+// pathFlags returns the path flags of the lines selected by keep, in line order
+func (l Lines) pathFlags(keep func(PathFlag) bool) []PathFlag {
+	numbers := make([]int, 0, len(l.Flags))
+	for number := range l.Flags {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	var flags []PathFlag
+	for _, number := range numbers {
+		for _, flag := range l.Flags[number] {
+			if keep(flag) {
+				flags = append(flags, flag)
+			}
+		}
+	}
+	return flags
+}
+
+// ValidFlags returns the valid path flags of the lines, in line order: the paths of the
+// script checked on the file system
+func (l Lines) ValidFlags() []PathFlag {
+	return l.pathFlags(PathFlag.IsValid)
+}
+
+// RemoteFlags returns the path flags referencing URLs, in line order
+func (l Lines) RemoteFlags() []PathFlag {
+	return l.pathFlags(func(flag PathFlag) bool { return flag.Remote })
+}
+
+// InvalidLines returns the lines with invalid path flags, with the problems of the
+// flags, by line number. A line can be valid as well when another flag is.
+func (l Lines) InvalidLines() map[int]string {
+	invalid := make(map[int]string)
+	for number, flags := range l.Flags {
+		for _, flag := range flags {
+			if flag.Rule == "" {
+				continue
+			}
+			if _, ok := invalid[number]; !ok {
+				invalid[number] = strings.TrimSpace(l.Text[number])
+			}
+			if flag.Problem != "" {
+				invalid[number] += " [" + flag.Problem + "]"
+			}
+		}
+	}
+	return invalid
+}
+
+// referenceLines counts the lines with a valid path flag, the loop references included,
+// and the lines with a remote reference
+func (l Lines) referenceLines() (valid, remote int) {
+	for _, flags := range l.Flags {
+		hasValid, hasRemote := false, false
+		for _, flag := range flags {
+			hasValid = hasValid || flag.Rule == "" && !flag.Remote
+			hasRemote = hasRemote || flag.Remote
+		}
+		if hasValid {
+			valid++
+		}
+		if hasRemote {
+			remote++
+		}
+	}
+	return valid, remote
+}
+
 // Method to get a map of line numbers to Line strings from StyleSheetImport struct
 func (l Lines) GetStyleSheetImportLines() map[int]string {
 	importLines := make(map[int]string)
@@ -264,6 +649,52 @@ func validatePathSeparators(filePath string, targetOS string, lineNumber int) er
 	return nil
 }
 
+var uncPathRegex = regexp.MustCompile(`^\\\\([^\\/]+)[\\/]`)
+
+// validateWindowsPathRoot checks UNC (\\server\share\...) and drive letter (C:\...) prefixes:
+// Windows scripts may only use the configured servers and drives, Linux scripts none at all
+func (r *run) validateWindowsPathRoot(filePath string, targetOS string, lineNumber int) error {
+	server := ""
+	if match := uncPathRegex.FindStringSubmatch(filePath); match != nil {
+		server = match[1]
+	}
+	drive := ""
+	if match := driveLetterRegex.FindStringSubmatch(filePath); match != nil {
+		drive = match[1]
+	}
+	if server == "" && drive == "" {
+		return nil
+	}
+
+	if targetOS == "linux" {
+		if server != "" {
+			return fmt.Errorf("line %d: path '%s' is a UNC path but script targets Linux", lineNumber, filePath)
+		}
+		return fmt.Errorf("line %d: path '%s' has a drive letter but script targets Linux", lineNumber, filePath)
+	}
+
+	if server != "" && !containsFold(r.windowsPathSettings.AllowedServers, server) {
+		return fmt.Errorf("line %d: path '%s' uses server '%s' which is not in 'allowed_servers'", lineNumber, filePath, server)
+	}
+	if drive != "" && !containsFold(r.windowsPathSettings.AllowedDrives, drive) {
+		return fmt.Errorf("line %d: path '%s' uses drive '%s:' which is not in 'allowed_drives'", lineNumber, filePath, drive)
+	}
+	return nil
+}
+
+// containsFold reports whether value is in list (case-insensitive); an empty list contains everything
+func containsFold(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSuffix(item, ":"), value) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractExecutableName extracts the executable name from a command line
 func extractExecutableName(line string) string {
 	line = strings.TrimSpace(line)
@@ -316,33 +747,51 @@ func extractExecutableName(line string) string {
 	return cmd
 }
 
-// trackExecutable records executable calls for parity checking
-func trackExecutable(scriptFile string, line string) {
+// trackExecutable records executable calls of the script with their line and command
+// for parity checking, in the results of the run
+func (r *run) trackExecutable(scriptFile string, line string, lineNumber int) {
 	executable := extractExecutableName(line)
 	if executable == "" {
 		return
 	}
 
-	if scriptExecutables == nil {
-		scriptExecutables = make(map[string]map[string]bool)
+	if r.analysisResult.File == nil {
+		r.analysisResult.File = make(map[string]Lines)
 	}
-	if scriptExecutables[scriptFile] == nil {
-		scriptExecutables[scriptFile] = make(map[string]bool)
+	lines := r.analysisResult.File[scriptFile]
+	if lines.Executables == nil {
+		lines.Executables = make(map[string][]Invocation)
+		r.analysisResult.File[scriptFile] = lines
 	}
+	lines.Executables[executable] = append(lines.Executables[executable], Invocation{Line: lineNumber, Command: strings.TrimSpace(line)})
+}
 
-	scriptExecutables[scriptFile][executable] = true
+// invocationSites returns the first script and line calling the executable and all
+// its calls as 'script:line', in the order of the scripts
+func (r *run) invocationSites(scripts []string, executable string) (string, int, string) {
+	firstScript, firstLine := "", 0
+	var sites []string
+	for _, script := range scripts {
+		for _, invocation := range r.analysisResult.File[script].Executables[executable] {
+			if firstScript == "" {
+				firstScript, firstLine = script, invocation.Line
+			}
+			sites = append(sites, fmt.Sprintf("%s:%d", script, invocation.Line))
+		}
+	}
+	return firstScript, firstLine, strings.Join(sites, ", ")
 }
 
 // checkScriptParity verifies that Windows and Linux scripts call the same executables
-func checkScriptParity(scripts []scriptDefinition) {
+func (r *run) checkScriptParity(scripts []scriptDefinition) {
 	if len(scripts) < 2 {
 		return // Need at least 2 scripts to compare
 	}
 
-	logger.Heading(" ")
-	logger.Separate("SCRIPT PARITY CHECK")
-	logger.Separate("=====================================")
-	logger.Separate("Checking that Windows and Linux scripts call the same executables...")
+	r.log.Heading(" ")
+	r.log.Separate("SCRIPT PARITY CHECK")
+	r.log.Separate("=====================================")
+	r.log.Separate("Checking that Windows and Linux scripts call the same executables...")
 
 	// Group scripts by target OS
 	windowsScripts := []string{}
@@ -361,31 +810,31 @@ func checkScriptParity(scripts []scriptDefinition) {
 		// Collect all executables from Windows scripts
 		windowsExecs := make(map[string]bool)
 		for _, ws := range windowsScripts {
-			logger.Debug("Collecting executables from Windows script '{ws}'", "ws", ws)
-			for exec := range scriptExecutables[ws] {
+			r.log.Debug("Collecting executables from Windows script '{ws}'", "ws", ws)
+			for exec := range r.analysisResult.File[ws].Executables {
 				windowsExecs[exec] = true
-				logger.Debug("  Windows executable: '{exec}'", "exec", exec)
+				r.log.Debug("  Windows executable: '{exec}'", "exec", exec)
 			}
 		}
-		logger.Debug("Total Windows executables: {count}", "count", len(windowsExecs))
+		r.log.Debug("Total Windows executables: {count}", "count", len(windowsExecs))
 
 		// Collect all executables from Linux scripts
 		linuxExecs := make(map[string]bool)
 		for _, ls := range linuxScripts {
-			logger.Debug("Collecting executables from Linux script '{ls}'", "ls", ls)
-			for exec := range scriptExecutables[ls] {
+			r.log.Debug("Collecting executables from Linux script '{ls}'", "ls", ls)
+			for exec := range r.analysisResult.File[ls].Executables {
 				linuxExecs[exec] = true
-				logger.Debug("  Linux executable: '{exec}'", "exec", exec)
+				r.log.Debug("  Linux executable: '{exec}'", "exec", exec)
 			}
 		}
-		logger.Debug("Total Linux executables: {count}", "count", len(linuxExecs))
+		r.log.Debug("Total Linux executables: {count}", "count", len(linuxExecs))
 
 		// Find executables in Windows but not in Linux
 		missingInLinux := []string{}
 		for exec := range windowsExecs {
 			if !linuxExecs[exec] {
 				missingInLinux = append(missingInLinux, exec)
-				logger.Debug("Executable '{exec}' found in Windows but not in Linux", "exec", exec)
+				r.log.Debug("Executable '{exec}' found in Windows but not in Linux", "exec", exec)
 			}
 		}
 
@@ -394,61 +843,94 @@ func checkScriptParity(scripts []scriptDefinition) {
 		for exec := range linuxExecs {
 			if !windowsExecs[exec] {
 				missingInWindows = append(missingInWindows, exec)
-				logger.Debug("Executable '{exec}' found in Linux but not in Windows", "exec", exec)
+				r.log.Debug("Executable '{exec}' found in Linux but not in Windows", "exec", exec)
 			}
 		}
 
 		// Report findings
 		if len(missingInLinux) > 0 {
 			sort.Strings(missingInLinux)
-			logger.Error("Executables in Windows script(s) but missing in Linux script(s): {execs}",
+			for _, exec := range missingInLinux {
+				script, line, sites := r.invocationSites(windowsScripts, exec)
+				r.recordFinding(Finding{Rule: RuleExecutableParity, Script: script, Line: line, Path: exec,
+					Message: logger.Format("Executable '{e}' is called in Windows script(s) but missing in Linux script(s) (called at {at})", "e", exec, "at", sites)})
+			}
+			r.log.Error("Executables in Windows script(s) but missing in Linux script(s): {execs}",
 				"execs", strings.Join(missingInLinux, ", "))
 		}
 
 		if len(missingInWindows) > 0 {
 			sort.Strings(missingInWindows)
-			logger.Error("Executables in Linux script(s) but missing in Windows script(s): {execs}",
+			for _, exec := range missingInWindows {
+				script, line, sites := r.invocationSites(linuxScripts, exec)
+				r.recordFinding(Finding{Rule: RuleExecutableParity, Script: script, Line: line, Path: exec,
+					Message: logger.Format("Executable '{e}' is called in Linux script(s) but missing in Windows script(s) (called at {at})", "e", exec, "at", sites)})
+			}
+			r.log.Error("Executables in Linux script(s) but missing in Windows script(s): {execs}",
 				"execs", strings.Join(missingInWindows, ", "))
 		}
 
 		if len(missingInLinux) == 0 && len(missingInWindows) == 0 {
-			logger.Separate("none")
+			r.log.Separate("none")
 		}
+		r.logCallCountDifferences(r.buildParityMatrix(scripts))
 
 		// Check file path parity
-		logger.Separate(" ")
-		logger.Separate("Checking that Windows and Linux scripts reference the same file paths...")
+		r.log.Separate(" ")
+		r.log.Separate("Checking that Windows and Linux scripts reference the same file paths...")
 
 		// Collect all file paths from Windows scripts (normalized to forward slashes)
 		windowsPaths := make(map[string]bool)
 		for _, ws := range windowsScripts {
-			logger.Debug("Collecting file paths from Windows script '{ws}'", "ws", ws)
-			for _, path := range analysisResult.File[ws].Valid {
-				normalizedPath := strings.ReplaceAll(path, `\`, `/`)
+			r.log.Debug("Collecting file paths from Windows script '{ws}'", "ws", ws)
+			for _, flag := range r.analysisResult.File[ws].ValidFlags() {
+				path := flag.Path
+				normalizedPath := slashPath(path, "windows")
+				if r.ignoredFor(CheckParity, normalizedPath) {
+					continue
+				}
 				windowsPaths[normalizedPath] = true
-				logger.Debug("  Windows path: '{path}' -> normalized: '{norm}'", "path", path, "norm", normalizedPath)
+				r.log.Debug("  Windows path: '{path}' -> normalized: '{norm}'", "path", path, "norm", normalizedPath)
+			}
+			for _, ref := range r.analysisResult.File[ws].LoopReference {
+				for _, match := range ref.Matches {
+					if match = filepath.ToSlash(match); !r.ignoredFor(CheckParity, match) {
+						windowsPaths[match] = true
+					}
+				}
 			}
 		}
-		logger.Debug("Total Windows paths: {count}", "count", len(windowsPaths))
+		r.log.Debug("Total Windows paths: {count}", "count", len(windowsPaths))
 
 		// Collect all file paths from Linux scripts
 		linuxPaths := make(map[string]bool)
 		for _, ls := range linuxScripts {
-			logger.Debug("Collecting file paths from Linux script '{ls}'", "ls", ls)
-			for _, path := range analysisResult.File[ls].Valid {
-				normalizedPath := strings.ReplaceAll(path, `\`, `/`)
+			r.log.Debug("Collecting file paths from Linux script '{ls}'", "ls", ls)
+			for _, flag := range r.analysisResult.File[ls].ValidFlags() {
+				path := flag.Path
+				normalizedPath := slashPath(path, "linux")
+				if r.ignoredFor(CheckParity, normalizedPath) {
+					continue
+				}
 				linuxPaths[normalizedPath] = true
-				logger.Debug("  Linux path: '{path}' -> normalized: '{norm}'", "path", path, "norm", normalizedPath)
+				r.log.Debug("  Linux path: '{path}' -> normalized: '{norm}'", "path", path, "norm", normalizedPath)
+			}
+			for _, ref := range r.analysisResult.File[ls].LoopReference {
+				for _, match := range ref.Matches {
+					if match = filepath.ToSlash(match); !r.ignoredFor(CheckParity, match) {
+						linuxPaths[match] = true
+					}
+				}
 			}
 		}
-		logger.Debug("Total Linux paths: {count}", "count", len(linuxPaths))
+		r.log.Debug("Total Linux paths: {count}", "count", len(linuxPaths))
 
 		// Find paths in Windows but not in Linux
 		missingPathsInLinux := []string{}
 		for path := range windowsPaths {
 			if !linuxPaths[path] {
 				missingPathsInLinux = append(missingPathsInLinux, path)
-				logger.Debug("Path '{path}' found in Windows but not in Linux", "path", path)
+				r.log.Debug("Path '{path}' found in Windows but not in Linux", "path", path)
 			}
 		}
 
@@ -457,29 +939,33 @@ func checkScriptParity(scripts []scriptDefinition) {
 		for path := range linuxPaths {
 			if !windowsPaths[path] {
 				missingPathsInWindows = append(missingPathsInWindows, path)
-				logger.Debug("Path '{path}' found in Linux but not in Windows", "path", path)
+				r.log.Debug("Path '{path}' found in Linux but not in Windows", "path", path)
 			}
 		}
 
 		// Report findings
 		if len(missingPathsInLinux) > 0 {
 			sort.Strings(missingPathsInLinux)
-			logger.Error("File paths in Windows script(s) but missing in Linux script(s):")
+			r.log.Error("File paths in Windows script(s) but missing in Linux script(s):")
 			for _, path := range missingPathsInLinux {
-				logger.Error("  {path}", "path", path)
+				r.log.Error("  {path}", "path", path)
+				r.recordFinding(Finding{Rule: RulePathParity, Path: path,
+					Message: logger.Format("File path '{p}' is referenced in Windows script(s) but missing in Linux script(s)", "p", path)})
 			}
 		}
 
 		if len(missingPathsInWindows) > 0 {
 			sort.Strings(missingPathsInWindows)
-			logger.Error("File paths in Linux script(s) but missing in Windows script(s):")
+			r.log.Error("File paths in Linux script(s) but missing in Windows script(s):")
 			for _, path := range missingPathsInWindows {
-				logger.Error("  {path}", "path", path)
+				r.log.Error("  {path}", "path", path)
+				r.recordFinding(Finding{Rule: RulePathParity, Path: path,
+					Message: logger.Format("File path '{p}' is referenced in Linux script(s) but missing in Windows script(s)", "p", path)})
 			}
 		}
 
 		if len(missingPathsInLinux) == 0 && len(missingPathsInWindows) == 0 {
-			logger.Separate("none")
+			r.log.Separate("none")
 		}
 	}
 }
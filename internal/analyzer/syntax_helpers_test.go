@@ -177,19 +177,16 @@ func TestExtractExecutableName_DotSlashPath(t *testing.T) {
 // What it tests: Empty registry -> Track "install_data" -> Should be in registry
 func TestTrackExecutable_AddNew(t *testing.T) {
 	// SETUP: Clear package state
-	scriptExecutables = nil
+	testRun.analysisResult = Result{}
 
 	// EXECUTE: Track executable
-	trackExecutable("script.sh", "$TC_BIN/install_data -input=file")
+	testRun.trackExecutable("script.sh", "$TC_BIN/install_data -input=file", 1)
 
 	// ASSERT: Verify it's in the map
-	if scriptExecutables == nil {
-		t.Fatal("scriptExecutables map not initialized")
-	}
-	if scriptExecutables["script.sh"] == nil {
+	if testRun.analysisResult.File["script.sh"].Executables == nil {
 		t.Fatal("script.sh not in map")
 	}
-	if !scriptExecutables["script.sh"]["install_data"] {
+	if len(testRun.analysisResult.File["script.sh"].Executables["install_data"]) == 0 {
 		t.Error("Expected install_data to be tracked")
 	}
 }
@@ -198,18 +195,21 @@ func TestTrackExecutable_AddNew(t *testing.T) {
 // What it tests: Call install_data 3 times in same script -> Should only have 1 entry (no duplicates)
 func TestTrackExecutable_SameExecutableMultipleTimes(t *testing.T) {
 	// SETUP: Clear state
-	scriptExecutables = nil
+	testRun.analysisResult = Result{}
 
 	// EXECUTE: Track same executable 3 times with different arguments
-	trackExecutable("script.sh", "$TC_BIN/install_data -input=file1.xml")
-	trackExecutable("script.sh", "$TC_BIN/install_data -input=file2.xml")
-	trackExecutable("script.sh", "./install_data -input=file3.xml")
+	testRun.trackExecutable("script.sh", "$TC_BIN/install_data -input=file1.xml", 1)
+	testRun.trackExecutable("script.sh", "$TC_BIN/install_data -input=file2.xml", 2)
+	testRun.trackExecutable("script.sh", "./install_data -input=file3.xml", 3)
 
-	// ASSERT: Should still be tracked once (map[string]bool means unique set)
-	if len(scriptExecutables["script.sh"]) != 1 {
-		t.Errorf("Expected 1 unique executable, got %d", len(scriptExecutables["script.sh"]))
+	// ASSERT: Should still be tracked once, with its invocations counted
+	if len(testRun.analysisResult.File["script.sh"].Executables) != 1 {
+		t.Errorf("Expected 1 unique executable, got %d", len(testRun.analysisResult.File["script.sh"].Executables))
+	}
+	if calls := len(testRun.analysisResult.File["script.sh"].Executables["install_data"]); calls != 3 {
+		t.Errorf("Expected 3 invocations, got %d", calls)
 	}
-	if !scriptExecutables["script.sh"]["install_data"] {
+	if len(testRun.analysisResult.File["script.sh"].Executables["install_data"]) == 0 {
 		t.Error("Expected install_data to be tracked")
 	}
 }
@@ -218,24 +218,24 @@ func TestTrackExecutable_SameExecutableMultipleTimes(t *testing.T) {
 // What it tests: Track install_data, configure_plmxml, import_dataset -> All 3 should be in registry
 func TestTrackExecutable_DifferentExecutablesSameScript(t *testing.T) {
 	// SETUP
-	scriptExecutables = nil
+	testRun.analysisResult = Result{}
 
 	// EXECUTE: Track 3 different executables in same script
-	trackExecutable("script.sh", "install_data -input=file")
-	trackExecutable("script.sh", "configure_plmxml -path=config")
-	trackExecutable("script.sh", "import_dataset -file=data")
+	testRun.trackExecutable("script.sh", "install_data -input=file", 1)
+	testRun.trackExecutable("script.sh", "configure_plmxml -path=config", 2)
+	testRun.trackExecutable("script.sh", "import_dataset -file=data", 3)
 
 	// ASSERT: All 3 should be tracked
-	if len(scriptExecutables["script.sh"]) != 3 {
-		t.Errorf("Expected 3 executables, got %d", len(scriptExecutables["script.sh"]))
+	if len(testRun.analysisResult.File["script.sh"].Executables) != 3 {
+		t.Errorf("Expected 3 executables, got %d", len(testRun.analysisResult.File["script.sh"].Executables))
 	}
-	if !scriptExecutables["script.sh"]["install_data"] {
+	if len(testRun.analysisResult.File["script.sh"].Executables["install_data"]) == 0 {
 		t.Error("Expected install_data")
 	}
-	if !scriptExecutables["script.sh"]["configure_plmxml"] {
+	if len(testRun.analysisResult.File["script.sh"].Executables["configure_plmxml"]) == 0 {
 		t.Error("Expected configure_plmxml")
 	}
-	if !scriptExecutables["script.sh"]["import_dataset"] {
+	if len(testRun.analysisResult.File["script.sh"].Executables["import_dataset"]) == 0 {
 		t.Error("Expected import_dataset")
 	}
 }
@@ -244,27 +244,27 @@ func TestTrackExecutable_DifferentExecutablesSameScript(t *testing.T) {
 // What it tests: win.bat tracks install_data, linux.sh tracks configure_plmxml -> Each has its own list
 func TestTrackExecutable_MultipleScriptsSeparate(t *testing.T) {
 	// SETUP
-	scriptExecutables = nil
+	testRun.analysisResult = Result{}
 
 	// EXECUTE: Track executables in 2 different scripts
-	trackExecutable("win.bat", "install_data.exe -input=file")
-	trackExecutable("linux.sh", "configure_plmxml -path=config")
+	testRun.trackExecutable("win.bat", "install_data.exe -input=file", 1)
+	testRun.trackExecutable("linux.sh", "configure_plmxml -path=config", 2)
 
 	// ASSERT: Each script has its own set
-	if len(scriptExecutables) != 2 {
-		t.Errorf("Expected 2 scripts, got %d", len(scriptExecutables))
+	if len(testRun.analysisResult.File) != 2 {
+		t.Errorf("Expected 2 scripts, got %d", len(testRun.analysisResult.File))
 	}
-	if !scriptExecutables["win.bat"]["install_data"] {
+	if len(testRun.analysisResult.File["win.bat"].Executables["install_data"]) == 0 {
 		t.Error("Expected install_data in win.bat")
 	}
-	if !scriptExecutables["linux.sh"]["configure_plmxml"] {
+	if len(testRun.analysisResult.File["linux.sh"].Executables["configure_plmxml"]) == 0 {
 		t.Error("Expected configure_plmxml in linux.sh")
 	}
 	// Verify they don't cross-contaminate
-	if scriptExecutables["win.bat"]["configure_plmxml"] {
+	if len(testRun.analysisResult.File["win.bat"].Executables["configure_plmxml"]) > 0 {
 		t.Error("configure_plmxml should not be in win.bat")
 	}
-	if scriptExecutables["linux.sh"]["install_data"] {
+	if len(testRun.analysisResult.File["linux.sh"].Executables["install_data"]) > 0 {
 		t.Error("install_data should not be in linux.sh")
 	}
 }
@@ -273,17 +273,17 @@ func TestTrackExecutable_MultipleScriptsSeparate(t *testing.T) {
 // What it tests: echo, cd, mkdir -> Registry should be empty (all ignored)
 func TestTrackExecutable_IgnoresShellCommands(t *testing.T) {
 	// SETUP
-	scriptExecutables = nil
+	testRun.analysisResult = Result{}
 
 	// EXECUTE: Try to track shell commands (should be ignored)
-	trackExecutable("script.sh", "echo Starting deployment")
-	trackExecutable("script.sh", "cd /opt/tc")
-	trackExecutable("script.sh", "mkdir -p output")
-	trackExecutable("script.sh", "export TC_ROOT=/opt/tc")
+	testRun.trackExecutable("script.sh", "echo Starting deployment", 1)
+	testRun.trackExecutable("script.sh", "cd /opt/tc", 2)
+	testRun.trackExecutable("script.sh", "mkdir -p output", 3)
+	testRun.trackExecutable("script.sh", "export TC_ROOT=/opt/tc", 4)
 
 	// ASSERT: Nothing tracked (all shell commands)
-	if scriptExecutables["script.sh"] != nil && len(scriptExecutables["script.sh"]) != 0 {
-		t.Errorf("Expected 0 executables (all shell commands), got %d", len(scriptExecutables["script.sh"]))
+	if len(testRun.analysisResult.File["script.sh"].Executables) != 0 {
+		t.Errorf("Expected 0 executables (all shell commands), got %d", len(testRun.analysisResult.File["script.sh"].Executables))
 	}
 }
 
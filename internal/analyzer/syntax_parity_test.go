@@ -11,8 +11,7 @@ import (
 // Helper function to set up test scenario
 func setupParityTest() {
 	// Reset global state
-	scriptExecutables = make(map[string]map[string]bool)
-	analysisResult = Result{
+	testRun.analysisResult = Result{
 		File: make(map[string]Lines),
 	}
 
@@ -21,27 +20,39 @@ func setupParityTest() {
 	logger.InitLogger(os.DevNull, "error")
 }
 
+// setExecutables sets the number of invocations of the executables of a script
+func setExecutables(script string, executables map[string]int) {
+	lines := testRun.analysisResult.File[script]
+	lines.Executables = make(map[string][]Invocation)
+	for executable, calls := range executables {
+		for i := 1; i <= calls; i++ {
+			lines.Executables[executable] = append(lines.Executables[executable], Invocation{Line: i, Command: executable})
+		}
+	}
+	testRun.analysisResult.File[script] = lines
+}
+
 // TestCheckScriptParity_PerfectMatch tests matching executables between Windows and Linux
 // What it tests: Both scripts have same utilities -> No parity issues reported
 func TestCheckScriptParity_PerfectMatch(t *testing.T) {
 	setupParityTest()
 
 	// Simulate identical executables
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-		"tc_utils":      true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-		"tc_utils":      true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+		"tc_utils":      1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+		"tc_utils":      1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Check that no errors were added (parity issues would add errors to analysisResult)
 	// Since we can't directly assert error count, we verify the function completes without panic
@@ -52,13 +63,13 @@ func TestCheckScriptParity_PerfectMatch(t *testing.T) {
 func TestCheckScriptParity_MissingInLinux(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-		"tc_utils":      true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"tc_utils": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+		"tc_utils":      1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"tc_utils": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
@@ -66,7 +77,7 @@ func TestCheckScriptParity_MissingInLinux(t *testing.T) {
 	}
 
 	// Function should detect mismatch and log it
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// The function logs errors but doesn't return them
 	// In a real scenario, we'd check analysisResult.Files for the error entry
@@ -77,20 +88,20 @@ func TestCheckScriptParity_MissingInLinux(t *testing.T) {
 func TestCheckScriptParity_MissingInWindows(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"tc_utils": true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"tc_utils":      true,
-		"deploy_config": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"tc_utils": 1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"tc_utils":      1,
+		"deploy_config": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Function should detect mismatch and log it
 }
@@ -100,21 +111,21 @@ func TestCheckScriptParity_MissingInWindows(t *testing.T) {
 func TestCheckScriptParity_MultipleMismatches(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"util_a": true,
-		"util_b": true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"util_b": true,
-		"util_c": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"util_a": 1,
+		"util_b": 1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"util_b": 1,
+		"util_c": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report both util_a (missing in Linux) and util_c (missing in Windows)
 }
@@ -124,18 +135,18 @@ func TestCheckScriptParity_MultipleMismatches(t *testing.T) {
 func TestCheckScriptParity_EmptyWindowsScript(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-		"tc_utils":      true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+		"tc_utils":      1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report that Windows script has no executables while Linux has 2
 }
@@ -145,18 +156,18 @@ func TestCheckScriptParity_EmptyWindowsScript(t *testing.T) {
 func TestCheckScriptParity_EmptyLinuxScript(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-		"tc_utils":      true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+		"tc_utils":      1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report that Linux script has no executables while Windows has 2
 }
@@ -166,15 +177,15 @@ func TestCheckScriptParity_EmptyLinuxScript(t *testing.T) {
 func TestCheckScriptParity_BothEmpty(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// No parity issues since both are empty (matching state)
 }
@@ -184,15 +195,15 @@ func TestCheckScriptParity_BothEmpty(t *testing.T) {
 func TestCheckScriptParity_OnlyWindowsScript(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Function should handle gracefully (no Linux counterpart to compare)
 }
@@ -202,15 +213,15 @@ func TestCheckScriptParity_OnlyWindowsScript(t *testing.T) {
 func TestCheckScriptParity_OnlyLinuxScript(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-	}
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Function should handle gracefully (no Windows counterpart to compare)
 }
@@ -220,19 +231,19 @@ func TestCheckScriptParity_OnlyLinuxScript(t *testing.T) {
 func TestCheckScriptParity_CaseSensitivity(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"PlmXML_Import": true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"PlmXML_Import": 1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report mismatch since executable names differ in case
 	// (extractExecutableName already lowercases, so this tests that behavior)
@@ -244,20 +255,20 @@ func TestCheckScriptParity_MultipleScriptPairs(t *testing.T) {
 	setupParityTest()
 
 	// First pair: deploy scripts
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+	})
 
 	// Second pair: install scripts
-	scriptExecutables["install_win.bat"] = map[string]bool{
-		"tc_utils": true,
-	}
-	scriptExecutables["install_linux.sh"] = map[string]bool{
-		"deploy_config": true, // Different utility
-	}
+	setExecutables("install_win.bat", map[string]int{
+		"tc_utils": 1,
+	})
+	setExecutables("install_linux.sh", map[string]int{
+		"deploy_config": 1, // Different utility
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
@@ -266,20 +277,20 @@ func TestCheckScriptParity_MultipleScriptPairs(t *testing.T) {
 		{Filename: "install_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// First pair should match, second pair should have parity error
 }
 
-// TestCheckScriptParity_NoScripts tests empty scriptExecutables map
+// TestCheckScriptParity_NoScripts tests scripts without executables
 // What it tests: No scripts tracked -> Function handles gracefully without errors
 func TestCheckScriptParity_NoScripts(t *testing.T) {
 	setupParityTest()
 
-	// scriptExecutables is empty
+	// No executables are tracked
 	scripts := []scriptDefinition{}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should complete without panic
 }
@@ -292,19 +303,19 @@ func TestCheckScriptParity_IgnoresNonTCUtilities(t *testing.T) {
 	// These should have been filtered out by extractExecutableName and not tracked
 	// But if they were tracked, parity check should still work correctly
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{
-		"plmxml_import": true,
-	}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{
-		"plmxml_import": true,
-	}
+	setExecutables("deploy_win.bat", map[string]int{
+		"plmxml_import": 1,
+	})
+	setExecutables("deploy_linux.sh", map[string]int{
+		"plmxml_import": 1,
+	})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should match (both have plmxml_import)
 	// Shell commands like echo/mkdir shouldn't affect parity
@@ -315,8 +326,8 @@ func TestCheckScriptParity_IgnoresNonTCUtilities(t *testing.T) {
 func TestGetLinuxCounterpart_FindsMatch(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	// Note: getLinuxCounterpart is not exported, this tests the concept
 	// The actual function finds counterparts using string manipulation
@@ -337,8 +348,8 @@ func TestGetLinuxCounterpart_FindsMatch(t *testing.T) {
 func TestGetWindowsCounterpart_FindsMatch(t *testing.T) {
 	setupParityTest()
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	linuxScript := "deploy_linux.sh"
 	expectedWindows := "deploy_win.bat"
@@ -359,37 +370,35 @@ func TestCheckScriptParity_FilePathPerfectMatch(t *testing.T) {
 	setupParityTest()
 
 	// Set up identical file paths (Windows uses backslash, Linux uses forward slash)
-	analysisResult.File["deploy_win.bat"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_win.bat"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV\Nw4RoHSExemptions.xml`,
 			2: `085-Dynamic_LOV\Nw4RoHSRegulation.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	analysisResult.File["deploy_linux.sh"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_linux.sh"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV/Nw4RoHSExemptions.xml`,
 			2: `085-Dynamic_LOV/Nw4RoHSRegulation.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report no parity issues (paths are equivalent after normalization)
 }
@@ -399,39 +408,37 @@ func TestCheckScriptParity_FilePathPerfectMatch(t *testing.T) {
 func TestCheckScriptParity_FilePathMissingInLinux(t *testing.T) {
 	setupParityTest()
 
-	analysisResult.File["deploy_win.bat"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_win.bat"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV\Nw4AutomotiveClass.xml`,
 			2: `085-Dynamic_LOV\Nw4Packaging.xml`,
 			3: `085-Dynamic_LOV\Nw4RoHSExemptions.xml`,
 			4: `085-Dynamic_LOV\Nw4RoHSRegulation.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	analysisResult.File["deploy_linux.sh"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_linux.sh"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV/Nw4RoHSExemptions.xml`,
 			2: `085-Dynamic_LOV/Nw4RoHSRegulation.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report Nw4AutomotiveClass.xml and Nw4Packaging.xml missing in Linux
 }
@@ -441,36 +448,34 @@ func TestCheckScriptParity_FilePathMissingInLinux(t *testing.T) {
 func TestCheckScriptParity_FilePathMissingInWindows(t *testing.T) {
 	setupParityTest()
 
-	analysisResult.File["deploy_win.bat"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_win.bat"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV\Nw4RoHSExemptions.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	analysisResult.File["deploy_linux.sh"] = Lines{
-		Valid: map[int]string{
+	testRun.analysisResult.File["deploy_linux.sh"] = Lines{
+		Flags: flagsOf(map[int]string{
 			1: `085-Dynamic_LOV/Nw4RoHSExemptions.xml`,
 			2: `085-Dynamic_LOV/Nw4LinuxOnly.xml`,
-		},
+		}),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Should report Nw4LinuxOnly.xml missing in Windows
 }
@@ -480,31 +485,52 @@ func TestCheckScriptParity_FilePathMissingInWindows(t *testing.T) {
 func TestCheckScriptParity_FilePathEmptyScripts(t *testing.T) {
 	setupParityTest()
 
-	analysisResult.File["deploy_win.bat"] = Lines{
-		Valid:            make(map[int]string),
+	testRun.analysisResult.File["deploy_win.bat"] = Lines{
+		Flags:            make(map[int][]PathFlag),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	analysisResult.File["deploy_linux.sh"] = Lines{
-		Valid:            make(map[int]string),
+	testRun.analysisResult.File["deploy_linux.sh"] = Lines{
+		Flags:            make(map[int][]PathFlag),
 		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
 		Skipped:          make(map[int]string),
 		Missing:          []string{},
 	}
 
-	scriptExecutables["deploy_win.bat"] = map[string]bool{}
-	scriptExecutables["deploy_linux.sh"] = map[string]bool{}
+	setExecutables("deploy_win.bat", map[string]int{})
+	setExecutables("deploy_linux.sh", map[string]int{})
 
 	scripts := []scriptDefinition{
 		{Filename: "deploy_win.bat", TargetOS: "windows"},
 		{Filename: "deploy_linux.sh", TargetOS: "linux"},
 	}
 
-	checkScriptParity(scripts)
+	testRun.checkScriptParity(scripts)
 
 	// Both empty -> no parity issues
 }
+
+// TestCheckScriptParity_FindingLocation tests that a parity finding points at the calls
+// What it tests: Windows calls tem twice, Linux never -> Finding at the first call, listing both
+func TestCheckScriptParity_FindingLocation(t *testing.T) {
+	setupParityTest()
+	testRun.analysisResult.File["deploy_win.bat"] = newLines()
+	testRun.analysisResult.File["deploy_linux.sh"] = newLines()
+	testRun.trackExecutable("deploy_win.bat", "%TC_BIN%\\tem -update=all", 7)
+	testRun.trackExecutable("deploy_win.bat", "%TC_BIN%\\tem -update=nw4", 12)
+
+	testRun.checkScriptParity([]scriptDefinition{
+		{Filename: "deploy_win.bat", TargetOS: "windows"},
+		{Filename: "deploy_linux.sh", TargetOS: "linux"},
+	})
+
+	if len(testRun.analysisResult.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", testRun.analysisResult.Findings)
+	}
+	f := testRun.analysisResult.Findings[0]
+	if f.Rule != RuleExecutableParity || f.Script != "deploy_win.bat" || f.Line != 7 || !strings.Contains(f.Message, "deploy_win.bat:7, deploy_win.bat:12") {
+		t.Errorf("Expected the finding at the first call, got %+v", f)
+	}
+}
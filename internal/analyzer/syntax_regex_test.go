@@ -8,7 +8,7 @@ import (
 // What it tests: Calling initializeRegexPatterns() -> All patterns compile without error
 func TestInitializeRegexPatterns_Success(t *testing.T) {
 	testParams := []string{"R", "i", "source", "target"}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	// If we get here without panic, compilation succeeded
 }
@@ -17,28 +17,25 @@ func TestInitializeRegexPatterns_Success(t *testing.T) {
 // What it tests: After initializeRegexPatterns() -> All regex pattern pointers are non-nil
 func TestInitializeRegexPatterns_PatternsNotNil(t *testing.T) {
 	testParams := []string{"R", "i", "source", "target"}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	// Check parameter-specific patterns are not nil
-	if parameterFlagPatterns == nil {
+	if testRun.parameterFlagPatterns == nil {
 		t.Error("parameterFlagPatterns should not be nil after initialization")
 	}
-	if parameterValuePatterns == nil {
+	if testRun.parameterValuePatterns == nil {
 		t.Error("parameterValuePatterns should not be nil after initialization")
 	}
-	if stylesheetUtilityRegex == nil {
-		t.Error("stylesheetUtilityRegex should not be nil after initialization")
-	}
-	if stylesheetFlagsRegex == nil {
-		t.Error("stylesheetFlagsRegex should not be nil after initialization")
+	if len(testRun.stylesheetImporters) == 0 || testRun.stylesheetImporters[0].utilityRegex == nil || testRun.stylesheetImporters[0].flagsRegex == nil {
+		t.Error("stylesheet importer patterns should not be nil after initialization")
 	}
 
 	// Check that specific parameter patterns were created
 	for _, param := range testParams {
-		if parameterFlagPatterns[param] == nil {
+		if testRun.parameterFlagPatterns[param] == nil {
 			t.Errorf("parameterFlagPatterns[%s] should not be nil", param)
 		}
-		if parameterValuePatterns[param] == nil {
+		if testRun.parameterValuePatterns[param] == nil {
 			t.Errorf("parameterValuePatterns[%s] should not be nil", param)
 		}
 	}
@@ -48,21 +45,18 @@ func TestInitializeRegexPatterns_PatternsNotNil(t *testing.T) {
 // What it tests: Call with empty parameter list -> Creates only stylesheet patterns
 func TestInitializeRegexPatterns_EmptyParams(t *testing.T) {
 	testParams := []string{}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	// Stylesheet patterns should still be created
-	if stylesheetUtilityRegex == nil {
-		t.Error("stylesheetUtilityRegex should not be nil even with empty params")
-	}
-	if stylesheetFlagsRegex == nil {
-		t.Error("stylesheetFlagsRegex should not be nil even with empty params")
+	if len(testRun.stylesheetImporters) == 0 || testRun.stylesheetImporters[0].utilityRegex == nil || testRun.stylesheetImporters[0].flagsRegex == nil {
+		t.Error("stylesheet importer patterns should not be nil even with empty params")
 	}
 
 	// Parameter maps should be empty but not nil
-	if parameterFlagPatterns == nil {
+	if testRun.parameterFlagPatterns == nil {
 		t.Error("parameterFlagPatterns should be initialized (empty map)")
 	}
-	if len(parameterFlagPatterns) != 0 {
+	if len(testRun.parameterFlagPatterns) != 0 {
 		t.Error("parameterFlagPatterns should be empty")
 	}
 }
@@ -71,10 +65,10 @@ func TestInitializeRegexPatterns_EmptyParams(t *testing.T) {
 // What it tests: Pattern for "-R" matches "-R" in line, but not "R" alone
 func TestParameterFlagPattern_Matching(t *testing.T) {
 	testParams := []string{"R", "i"}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	// Test -R pattern
-	rPattern := parameterFlagPatterns["R"]
+	rPattern := testRun.parameterFlagPatterns["R"]
 	if rPattern == nil {
 		t.Fatal("R pattern not initialized")
 	}
@@ -94,9 +88,9 @@ func TestParameterFlagPattern_Matching(t *testing.T) {
 // What it tests: Pattern extracts "file.xml" from "-R=\"file.xml\""
 func TestParameterValuePattern_Extraction(t *testing.T) {
 	testParams := []string{"R"}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
-	rValuePattern := parameterValuePatterns["R"]
+	rValuePattern := testRun.parameterValuePatterns["R"]
 	if rValuePattern == nil {
 		t.Fatal("R value pattern not initialized")
 	}
@@ -118,7 +112,7 @@ func TestParameterValuePattern_Extraction(t *testing.T) {
 // What it tests: Pattern matches "install_xml_stylesheet_datasets" utility calls
 func TestStylesheetUtilityRegex_Matching(t *testing.T) {
 	testParams := []string{}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	testCases := []struct {
 		input    string
@@ -132,9 +126,9 @@ func TestStylesheetUtilityRegex_Matching(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		matches := stylesheetUtilityRegex.MatchString(tc.input)
+		matches := testRun.stylesheetImporters[0].utilityRegex.MatchString(tc.input)
 		if matches != tc.expected {
-			t.Errorf("stylesheetUtilityRegex.MatchString(%q) = %v, expected %v",
+			t.Errorf("utilityRegex.MatchString(%q) = %v, expected %v",
 				tc.input, matches, tc.expected)
 		}
 	}
@@ -144,10 +138,10 @@ func TestStylesheetUtilityRegex_Matching(t *testing.T) {
 // What it tests: Pattern extracts values from -input="..." and -filepath="..."
 func TestStylesheetFlagsRegex_Extraction(t *testing.T) {
 	testParams := []string{}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	input := "install_xml_stylesheet_datasets -input=\"data.xml\" -filepath=\"styles.xsl\""
-	matches := stylesheetFlagsRegex.FindAllStringSubmatch(input, -1)
+	matches := testRun.stylesheetImporters[0].flagsRegex.FindAllStringSubmatch(input, -1)
 
 	if len(matches) != 2 {
 		t.Fatalf("Expected 2 flag matches, got %d", len(matches))
@@ -168,21 +162,21 @@ func TestStylesheetFlagsRegex_Extraction(t *testing.T) {
 // What it tests: Pass 4 parameters -> All 4 have flag and value patterns
 func TestMultipleParameters_AllCompiled(t *testing.T) {
 	testParams := []string{"R", "i", "source", "target", "custom"}
-	initializeRegexPatterns(testParams)
+	testRun.initializeRegexPatterns(testParams)
 
 	for _, param := range testParams {
-		if parameterFlagPatterns[param] == nil {
+		if testRun.parameterFlagPatterns[param] == nil {
 			t.Errorf("Flag pattern for %q not initialized", param)
 		}
-		if parameterValuePatterns[param] == nil {
+		if testRun.parameterValuePatterns[param] == nil {
 			t.Errorf("Value pattern for %q not initialized", param)
 		}
 	}
 
-	if len(parameterFlagPatterns) != len(testParams) {
-		t.Errorf("Expected %d flag patterns, got %d", len(testParams), len(parameterFlagPatterns))
+	if len(testRun.parameterFlagPatterns) != len(testParams) {
+		t.Errorf("Expected %d flag patterns, got %d", len(testParams), len(testRun.parameterFlagPatterns))
 	}
-	if len(parameterValuePatterns) != len(testParams) {
-		t.Errorf("Expected %d value patterns, got %d", len(testParams), len(parameterValuePatterns))
+	if len(testRun.parameterValuePatterns) != len(testParams) {
+		t.Errorf("Expected %d value patterns, got %d", len(testParams), len(testRun.parameterValuePatterns))
 	}
 }
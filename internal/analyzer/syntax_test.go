@@ -2,6 +2,9 @@ package analyzer
 
 import (
 	"os"
+	"reflect"
+	"sort"
+	"regexp"
 	"testing"
 	
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
@@ -9,27 +12,20 @@ import (
 
 // Helper to reset state before each test
 func setupSyntaxTest() {
-	scriptExecutables = make(map[string]map[string]bool)
-	analysisResult.File = make(map[string]Lines)
-	currentScriptTargetOS = "windows"
+	testRun.analysisResult.File = make(map[string]Lines)
+	testRun.currentScriptTargetOS = "windows"
 	
 	// Initialize logger
 	logger.InitLogger(os.DevNull, "error")
 	
 	// Initialize with common test parameters
 	testParams := []string{"R", "i", "source", "target"}
-	pathParameters = testParams  // Set package-level variable used by parseLineAsCommand
-	initializeRegexPatterns(testParams)
+	testRun.pathParameters = testParams  // Set package-level variable used by parseLineAsCommand
+	testRun.initializeRegexPatterns(testParams)
 }// Test initialization happens before each test
 func initTestFile(filename string, targetOS string) {
-	analysisResult.File[filename] = Lines{
-		Valid:            make(map[int]string),
-		StyleSheetImport: make(map[int]StyleSheetImport),
-		Invalid:          make(map[int]string),
-		Skipped:          make(map[int]string),
-		Missing:          []string{},
-	}
-	currentScriptTargetOS = targetOS
+	testRun.analysisResult.File[filename] = newLines()
+	testRun.currentScriptTargetOS = targetOS
 }
 
 // TestParseLineAsCommand_ValidRFlag tests parsing line with -R flag
@@ -42,15 +38,15 @@ func TestParseLineAsCommand_ValidRFlag(t *testing.T) {
 	line := "-R=\"config\\data.xml\""
 	lineNum := 10
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Check that path was added to valid
-	if _, exists := analysisResult.File[filename].Valid[lineNum]; !exists {
+	if _, exists := validPaths(testRun.analysisResult.File[filename])[lineNum]; !exists {
 		t.Error("Expected line to be in valid paths")
 	}
 
-	if analysisResult.File[filename].Valid[lineNum] != "config\\data.xml" {
-		t.Errorf("Expected path 'config\\data.xml', got '%s'", analysisResult.File[filename].Valid[lineNum])
+	if validPaths(testRun.analysisResult.File[filename])[lineNum] != "config\\data.xml" {
+		t.Errorf("Expected path 'config\\data.xml', got '%s'", validPaths(testRun.analysisResult.File[filename])[lineNum])
 	}
 }
 
@@ -64,15 +60,15 @@ func TestParseLineAsCommand_ValidIFlag(t *testing.T) {
 	line := "-i=\"data/import.xml\""
 	lineNum := 15
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Check that path was added to valid
-	if _, exists := analysisResult.File[filename].Valid[lineNum]; !exists {
+	if _, exists := validPaths(testRun.analysisResult.File[filename])[lineNum]; !exists {
 		t.Error("Expected line to be in valid paths")
 	}
 
-	if analysisResult.File[filename].Valid[lineNum] != "data/import.xml" {
-		t.Errorf("Expected path 'data/import.xml', got '%s'", analysisResult.File[filename].Valid[lineNum])
+	if validPaths(testRun.analysisResult.File[filename])[lineNum] != "data/import.xml" {
+		t.Errorf("Expected path 'data/import.xml', got '%s'", validPaths(testRun.analysisResult.File[filename])[lineNum])
 	}
 }
 
@@ -86,10 +82,10 @@ func TestParseLineAsCommand_WrongSeparatorWindows(t *testing.T) {
 	line := "-R=\"config/data.xml\""
 	lineNum := 25
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Check that error was added for wrong separator
-	if _, exists := analysisResult.File[filename].Invalid[lineNum]; !exists {
+	if _, exists := testRun.analysisResult.File[filename].InvalidLines()[lineNum]; !exists {
 		t.Error("Expected line to be in invalid paths due to wrong separator")
 	}
 }
@@ -104,10 +100,10 @@ func TestParseLineAsCommand_WrongSeparatorLinux(t *testing.T) {
 	line := "-R=\"config\\data.xml\""
 	lineNum := 30
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Check that error was added for wrong separator
-	if _, exists := analysisResult.File[filename].Invalid[lineNum]; !exists {
+	if _, exists := testRun.analysisResult.File[filename].InvalidLines()[lineNum]; !exists {
 		t.Error("Expected line to be in invalid paths due to wrong separator")
 	}
 }
@@ -122,10 +118,10 @@ func TestParseLineAsCommand_NoFlags(t *testing.T) {
 	line := "echo Hello World"
 	lineNum := 35
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Check that line was skipped
-	if _, exists := analysisResult.File[filename].Skipped[lineNum]; !exists {
+	if _, exists := testRun.analysisResult.File[filename].Skipped[lineNum]; !exists {
 		t.Error("Expected line to be in skipped")
 	}
 }
@@ -137,20 +133,19 @@ func TestParseLineAsCommand_TracksExecutable(t *testing.T) {
 	filename := "deploy_linux.sh"
 	initTestFile(filename, "linux")
 
-	scriptExecutables = make(map[string]map[string]bool)
-
 	line := "$TC_BIN/plmxml_import -R=\"config/file.xml\""
 	lineNum := 65
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Verify executable was tracked
-	executables, exists := scriptExecutables[filename]
+	executables := testRun.analysisResult.File[filename].Executables
+	exists := len(executables) > 0
 	if !exists {
 		t.Fatal("Expected script to be in registry")
 	}
 
-	if !executables["plmxml_import"] {
+	if len(executables["plmxml_import"]) == 0 {
 		t.Error("Expected plmxml_import to be tracked")
 	}
 }
@@ -162,17 +157,16 @@ func TestParseLineAsCommand_IgnoresShellCommands(t *testing.T) {
 	filename := "deploy_linux.sh"
 	initTestFile(filename, "linux")
 
-	scriptExecutables = make(map[string]map[string]bool)
-
 	line := "echo Starting deployment"
 	lineNum := 70
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Verify shell command was NOT tracked
-	executables, exists := scriptExecutables[filename]
+	executables := testRun.analysisResult.File[filename].Executables
+	exists := len(executables) > 0
 	if exists && len(executables) > 0 {
-		if executables["echo"] {
+		if len(executables["echo"]) > 0 {
 			t.Error("Shell command 'echo' should not be tracked")
 		}
 	}
@@ -185,31 +179,30 @@ func TestParseLineAsCommand_VariableSubstitution(t *testing.T) {
 	filename := "deploy_win.bat"
 	initTestFile(filename, "windows")
 
-	scriptExecutables = make(map[string]map[string]bool)
-
 	line := "%TC_BIN%\\plmxml_import -R=\"config\\file.xml\""
 	lineNum := 85
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
 	// Should extract path
-	if _, exists := analysisResult.File[filename].Valid[lineNum]; !exists {
+	if _, exists := validPaths(testRun.analysisResult.File[filename])[lineNum]; !exists {
 		t.Error("Expected line to be in valid paths")
 	}
 
 	// Should track executable
-	executables, exists := scriptExecutables[filename]
+	executables := testRun.analysisResult.File[filename].Executables
+	exists := len(executables) > 0
 	if !exists {
 		t.Fatal("Expected script to be in registry")
 	}
 
-	if !executables["plmxml_import"] {
+	if len(executables["plmxml_import"]) == 0 {
 		t.Error("Expected plmxml_import to be tracked")
 	}
 }
 
 // TestParseLineAsCommand_MultipleFlags tests line with multiple flags
-// What it tests: Line "-R file1.xml -i file2.xml" -> Extracts first valid flag's path
+// What it tests: Line "-R file1.xml -i file2.xml" -> Extracts the paths of both flags
 func TestParseLineAsCommand_MultipleFlags(t *testing.T) {
 	setupSyntaxTest()
 	filename := "test_script.sh"
@@ -218,10 +211,198 @@ func TestParseLineAsCommand_MultipleFlags(t *testing.T) {
 	line := "-R=\"config/file1.xml\" -i=\"data/file2.xml\""
 	lineNum := 45
 
-	parseLineAsCommand(filename, line, lineNum)
+	testRun.parseLineAsCommand(filename, line, lineNum)
 
-	// The function processes flags in order and breaks after first valid one
-	if _, exists := analysisResult.File[filename].Valid[lineNum]; !exists {
-		t.Error("Expected line to be in valid paths")
+	// Every valid flag of the line is a reference, in line order
+	flags := testRun.analysisResult.File[filename].ValidFlags()
+	if len(flags) != 2 || flags[0].Path != "config/file1.xml" || flags[1].Path != "data/file2.xml" {
+		t.Errorf("Expected both flag paths, got %+v", flags)
+	}
+}
+
+// TestParseLineAsCommand_ParameterStyles tests path parameters configured with a style or regex
+// What it tests: space_quoted, bare and custom regex parameters extract the path
+func TestParseLineAsCommand_ParameterStyles(t *testing.T) {
+	setupSyntaxTest()
+	parameters := []PathParameter{
+		{Name: "xml", Style: "space_quoted"},
+		{Name: "dir", Style: "bare"},
+		{Name: "cfg", Regex: `--?cfg:(\S+)`},
+	}
+	testRun.pathParameters = pathParameterNames(parameters)
+	testRun.initializeRegexPatterns(testRun.pathParameters)
+	if err := testRun.applyParameterStyles(parameters); err != nil {
+		t.Fatalf("applyParameterStyles() failed: %v", err)
+	}
+	filename := "test_script.sh"
+	initTestFile(filename, "linux")
+
+	testRun.parseLineAsCommand(filename, `import_util -xml "100-Config/a.xml"`, 1)
+	testRun.parseLineAsCommand(filename, `import_util -dir=100-Config/b`, 2)
+	testRun.parseLineAsCommand(filename, `import_util -dir "100-Config/c"`, 3)
+	testRun.parseLineAsCommand(filename, `import_util --cfg:100-Config/d.cfg`, 4)
+	testRun.parseLineAsCommand(filename, `import_util -xml=100-Config/e.xml`, 5)
+
+	expected := map[int]string{1: "100-Config/a.xml", 2: "100-Config/b", 3: "100-Config/c", 4: "100-Config/d.cfg"}
+	if !reflect.DeepEqual(validPaths(testRun.analysisResult.File[filename]), expected) {
+		t.Errorf("Valid = %v, want %v", validPaths(testRun.analysisResult.File[filename]), expected)
+	}
+	if _, ok := testRun.analysisResult.File[filename].InvalidLines()[5]; !ok {
+		t.Error("Expected line 5 to be invalid for a space_quoted parameter")
+	}
+}
+
+// TestValidatePathParameters tests validation of parameter styles and regexes
+// What it tests: unknown styles, invalid regexes and regexes without capture group are rejected
+func TestValidatePathParameters(t *testing.T) {
+	valid := []PathParameter{{Name: "i"}, {Name: "x", Style: "bare"}, {Name: "y", Regex: `-y:(\S+)`}}
+	if err := ValidatePathParameters(valid); err != nil {
+		t.Errorf("Expected valid parameters, got %v", err)
+	}
+
+	for _, p := range []PathParameter{{Name: "a", Style: "colon"}, {Name: "b", Regex: `-b=(`}, {Name: "c", Regex: `-c=\S+`}} {
+		if err := ValidatePathParameters([]PathParameter{p}); err == nil {
+			t.Errorf("Expected error for %+v, got nil", p)
+		}
+	}
+}
+
+// TestParseLineAsCommand_FlagWordBoundaries tests that flags only match as whole words
+// What it tests: -R does not match -RANDOM or --R; --R matches with GNU long options
+func TestParseLineAsCommand_FlagWordBoundaries(t *testing.T) {
+	setupSyntaxTest()
+	filename := "test_script.sh"
+	initTestFile(filename, "linux")
+
+	testRun.parseLineAsCommand(filename, `util -RANDOM=yes -i="a.xml"`, 1)
+	testRun.parseLineAsCommand(filename, `util --R=unquoted`, 2)
+	testRun.parseLineAsCommand(filename, `util x-R=unquoted`, 3)
+	testRun.parseLineAsCommand(filename, `util -R=unquoted`, 4)
+
+	if validPaths(testRun.analysisResult.File[filename])[1] != "a.xml" {
+		t.Errorf("Expected line 1 path 'a.xml', got %q", validPaths(testRun.analysisResult.File[filename])[1])
+	}
+	for _, ln := range []int{2, 3} {
+		if _, ok := testRun.analysisResult.File[filename].Skipped[ln]; !ok {
+			t.Errorf("Expected line %d to be skipped", ln)
+		}
+	}
+	if _, ok := testRun.analysisResult.File[filename].InvalidLines()[4]; !ok {
+		t.Error("Expected line 4 to be invalid")
+	}
+
+	testRun.gnuLongOptions = true
+	defer func() { testRun.gnuLongOptions = false }()
+	testRun.initializeRegexPatterns(testRun.pathParameters)
+	testRun.parseLineAsCommand(filename, `util --R="b.xml"`, 5)
+	testRun.parseLineAsCommand(filename, `util ---R="c.xml"`, 6)
+
+	if validPaths(testRun.analysisResult.File[filename])[5] != "b.xml" {
+		t.Errorf("Expected line 5 path 'b.xml', got %q", validPaths(testRun.analysisResult.File[filename])[5])
+	}
+	if _, ok := testRun.analysisResult.File[filename].Skipped[6]; !ok {
+		t.Error("Expected line 6 to be skipped")
+	}
+}
+
+// What: Path columns are recorded for valid lines, flag columns for lines with unquoted flags,
+// counted in characters and set on the findings
+func TestParseLineAsCommand_Columns(t *testing.T) {
+	setupSyntaxTest()
+	filename := "test_script.sh"
+	initTestFile(filename, "linux")
+	testRun.analysisResult.Findings = nil
+
+	testRun.parseLineAsCommand(filename, `util -i="a.xml"`, 1)
+	testRun.parseLineAsCommand(filename, `  util -R=unquoted`, 2)
+	testRun.parseLineAsCommand(filename, `écho -i="b.xml"`, 3)
+	testRun.parseLineAsCommand(filename, `util -i="dir\c.xml"`, 4)
+
+	expected := map[int]int{1: 10, 2: 8, 3: 10, 4: 10}
+	got := map[int]int{}
+	for number, flags := range testRun.analysisResult.File[filename].Flags {
+		got[number] = flags[0].Column
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected columns %v, got %v", expected, got)
+	}
+
+	columns := map[string]int{}
+	for _, f := range testRun.analysisResult.Findings {
+		columns[f.Rule] = f.Column
+	}
+	if columns[RuleFlagNotQuoted] != 8 || columns[RuleWrongSeparator] != 10 {
+		t.Errorf("Expected finding columns 8 (TCX001) and 10 (TCX002), got %v", columns)
+	}
+}
+
+// What: Columns are character based, the flag pattern prefix is not counted
+func TestFlagColumn(t *testing.T) {
+	line := `é -R=x`
+	loc := regexp.MustCompile(testRun.flagPrefix("R")).FindStringIndex(line)
+	if got := flagColumn(line, loc); got != 3 {
+		t.Errorf("flagColumn() = %d, want 3", got)
+	}
+	if got := characterColumn(line, 0); got != 1 {
+		t.Errorf("characterColumn() at offset 0 = %d, want 1", got)
+	}
+}
+
+// What: A line with a quoted path and a malformed flag is valid and invalid, with the column of its path
+func TestParseLineAsCommand_ValidAndInvalidFlags(t *testing.T) {
+	setupSyntaxTest()
+	testRun.analysisResult.Findings = nil
+	filename := "test_script.bat"
+	initTestFile(filename, "windows")
+
+	testRun.parseLineAsCommand(filename, `util -R=unquoted -i="dir\a.xml" -source="dir/b.xml"`, 1)
+
+	lines := testRun.analysisResult.File[filename]
+	valid := lines.ValidFlags()
+	if len(valid) != 1 || valid[0].Path != `dir\a.xml` || valid[0].Column != 22 {
+		t.Errorf("Expected the valid path at column 22, got %+v", valid)
+	}
+	if lines.InvalidLines()[1] == "" || len(lines.Flags[1]) != 3 {
+		t.Errorf("Expected the line invalid with 3 flags, got %q %+v", lines.InvalidLines()[1], lines.Flags[1])
+	}
+	if len(testRun.analysisResult.Findings) != 2 || testRun.analysisResult.Findings[0].Rule != RuleFlagNotQuoted || testRun.analysisResult.Findings[1].Rule != RuleWrongSeparator {
+		t.Errorf("Expected TCX001 and TCX002, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+// pathFlags returns the paths of script lines as their valid path flags, in line order
+func pathFlags(paths map[int]string) []PathFlag {
+	numbers := make([]int, 0, len(paths))
+	for number := range paths {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	flags := make([]PathFlag, 0, len(numbers))
+	for _, number := range numbers {
+		flags = append(flags, PathFlag{Line: number, Path: paths[number]})
+	}
+	return flags
+}
+
+// flagsOf returns the paths of script lines as the path flags of the lines
+func flagsOf(paths map[int]string) map[int][]PathFlag {
+	flags := make(map[int][]PathFlag, len(paths))
+	for _, flag := range pathFlags(paths) {
+		flags[flag.Line] = []PathFlag{flag}
+	}
+	return flags
+}
+
+// validPaths returns the paths of the valid path flags by line, the paths of a line
+// with several valid flags separated by a space
+func validPaths(l Lines) map[int]string {
+	paths := make(map[int]string)
+	for _, flag := range l.ValidFlags() {
+		if current, ok := paths[flag.Line]; ok {
+			paths[flag.Line] = current + " " + flag.Path
+			continue
+		}
+		paths[flag.Line] = flag.Path
 	}
+	return paths
 }
@@ -143,3 +143,49 @@ func TestValidatePathSeparators_LinuxAbsolutePath(t *testing.T) {
 	}
 }
 
+
+// TestValidateWindowsPathRoot_LinuxRejectsUNCAndDrive tests UNC and drive paths in Linux scripts
+// What it tests: Linux script with "\\server\share" or "C:/data" -> Error
+func TestValidateWindowsPathRoot_LinuxRejectsUNCAndDrive(t *testing.T) {
+	err := testRun.validateWindowsPathRoot(`\\fileserver\share\file.xml`, "linux", 3)
+	if err == nil || !strings.Contains(err.Error(), "UNC") {
+		t.Errorf("Expected UNC error for Linux script, got: %v", err)
+	}
+
+	err = testRun.validateWindowsPathRoot("C:/data/file.xml", "linux", 4)
+	if err == nil || !strings.Contains(err.Error(), "drive letter") {
+		t.Errorf("Expected drive letter error for Linux script, got: %v", err)
+	}
+}
+
+// TestValidateWindowsPathRoot_AllowedLists tests the configured server and drive allowlists
+// What it tests: Windows script paths are checked against allowed_servers / allowed_drives
+func TestValidateWindowsPathRoot_AllowedLists(t *testing.T) {
+	original := testRun.windowsPathSettings
+	defer func() { testRun.windowsPathSettings = original }()
+
+	testRun.windowsPathSettings = windowsPathRules{}
+	if err := testRun.validateWindowsPathRoot(`\\anyserver\share\file.xml`, "windows", 1); err != nil {
+		t.Errorf("Expected any server to be allowed with empty list, got: %v", err)
+	}
+
+	testRun.windowsPathSettings = windowsPathRules{
+		AllowedServers: []string{"FileServer01"},
+		AllowedDrives:  []string{"D:"},
+	}
+	if err := testRun.validateWindowsPathRoot(`\\fileserver01\share\file.xml`, "windows", 1); err != nil {
+		t.Errorf("Expected allowed server to pass, got: %v", err)
+	}
+	if err := testRun.validateWindowsPathRoot(`\\otherserver\share\file.xml`, "windows", 2); err == nil {
+		t.Error("Expected error for server not in allowed_servers")
+	}
+	if err := testRun.validateWindowsPathRoot(`d:\data\file.xml`, "windows", 3); err != nil {
+		t.Errorf("Expected allowed drive to pass, got: %v", err)
+	}
+	if err := testRun.validateWindowsPathRoot(`C:\data\file.xml`, "windows", 4); err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("Expected error with line number for drive not in allowed_drives, got: %v", err)
+	}
+	if err := testRun.validateWindowsPathRoot(`config\file.xml`, "windows", 5); err != nil {
+		t.Errorf("Expected relative path to pass, got: %v", err)
+	}
+}
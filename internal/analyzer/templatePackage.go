@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// $TC_ROOT/install/tem.sh -update -templates=nw4template -path="070-BMIDE/packages"
+//
+// The installer expects '<template>_template.zip' in the package path. Full
+// packages also carry 'feature_<template>.xml', whose root element holds the
+// template version.
+
+// templatePackageFile returns the package zip name for a template
+func templatePackageFile(templateName string) string {
+	return templateName + "_template.zip"
+}
+
+// readTemplateVersion opens a template package and returns the version declared
+// in its feature manifest.
+func readTemplateVersion(zipPath string, templateName string) (string, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening %q: %w", zipPath, err)
+	}
+	defer archive.Close()
+
+	manifestName := "feature_" + templateName + ".xml"
+	for _, entry := range archive.File {
+		if !strings.EqualFold(path.Base(entry.Name), manifestName) {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return "", fmt.Errorf("error reading %q in %q: %w", entry.Name, zipPath, err)
+		}
+		defer rc.Close()
+		return readRootAttribute(rc, "version")
+	}
+	return "", fmt.Errorf("manifest %q not found in %q", manifestName, zipPath)
+}
+
+// readRootAttribute returns the value of an attribute on the document's root element
+func readRootAttribute(r io.Reader, attributeName string) (string, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("no root element found: %w", err)
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			for _, attr := range element.Attr {
+				if attr.Name.Local == attributeName {
+					return attr.Value, nil
+				}
+			}
+			return "", fmt.Errorf("root element <%s> has no %q attribute", element.Name.Local, attributeName)
+		}
+	}
+}
+
+// checkTemplatePackages verifies that each template installed by the script has
+// its package zip in the -path directory and, when an expected version is
+// configured in 'template_packages', that the package declares that version.
+func (r *run) checkTemplatePackages(scriptFile string, installs map[int]TemplateInstall) {
+	r.log.Debug("checking BMIDE template packages for '{s}'", "s", scriptFile)
+
+	// sort by line number and check
+	si := make([]int, 0, len(installs))
+	for i := range installs {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+
+	hasErrors := false
+	for _, i := range si {
+		install := installs[i]
+		if len(install.Templates) == 0 {
+			r.reportFinding(Finding{Rule: RuleTemplatePackage, Script: scriptFile, Line: i},
+				"'{s}' line '{ln}' is invalid: template installer called without '-templates'", "s", scriptFile, "ln", i)
+			hasErrors = true
+			continue
+		}
+		if strings.ContainsAny(install.PackagePath, "$%") {
+			r.log.Info("'{s}' line '{ln}': package path '{p}' uses variables, skipping package check", "s", scriptFile, "ln", i, "p", install.PackagePath)
+			continue
+		}
+
+		packageDir := r.localPath(install.PackagePath)
+		if !filepath.IsAbs(packageDir) {
+			packageDir = r.referenceFilePath(packageDir)
+		}
+
+		for _, name := range install.Templates {
+			zipPath := filepath.Join(packageDir, templatePackageFile(name))
+			if _, err := os.Stat(zipPath); err != nil {
+				r.reportFinding(Finding{Rule: RuleTemplatePackage, Script: scriptFile, Line: i, Path: zipPath},
+					"'{s}' line '{ln}' is invalid: template package '{p}' not found on file system", "s", scriptFile, "ln", i, "p", zipPath)
+				hasErrors = true
+				continue
+			}
+			r.log.Info("'{s}' line '{ln}': template package '{p}' exists", "s", scriptFile, "ln", i, "p", zipPath)
+
+			expected, ok := r.templateExpectations[name]
+			if !ok || expected == "" {
+				continue
+			}
+			version, err := readTemplateVersion(zipPath, name)
+			if err != nil {
+				r.reportFinding(Finding{Rule: RuleTemplateVersion, Script: scriptFile, Line: i, Path: zipPath},
+					"'{s}' line '{ln}': {e}", "s", scriptFile, "ln", i, "e", err.Error())
+				hasErrors = true
+				continue
+			}
+			if version != expected {
+				r.reportFinding(Finding{Rule: RuleTemplateVersion, Script: scriptFile, Line: i, Path: zipPath},
+					"'{s}' line '{ln}': template '{t}' has version '{v}', expected '{ev}'", "s", scriptFile, "ln", i, "t", name, "v", version, "ev", expected)
+				hasErrors = true
+			} else {
+				r.log.Info("'{s}' line '{ln}': template '{t}' version '{v}' matches", "s", scriptFile, "ln", i, "t", name, "v", version)
+			}
+		}
+	}
+
+	if !hasErrors && len(installs) > 0 {
+		r.log.Info("All BMIDE template packages exist")
+	}
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestZip creates a zip archive with the given entries and contents.
+func writeTestZip(t *testing.T, zipPath string, entries map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(zipPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range entries {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to finalize zip: %v", err)
+	}
+}
+
+func TestIsTemplateInstallLine_ExtractsFlags(t *testing.T) {
+	// What: tem.sh line yields template names and package path
+	var install TemplateInstall
+	line := `$TC_ROOT/install/tem.sh -update -templates=nw4template,nw4other -path="070-BMIDE/packages"`
+
+	if !testRun.isTemplateInstallLine(line, &install) {
+		t.Fatal("Expected line to be detected as template install")
+	}
+	if len(install.Templates) != 2 || install.Templates[0] != "nw4template" || install.Templates[1] != "nw4other" {
+		t.Errorf("Unexpected templates: %v", install.Templates)
+	}
+	if install.PackagePath != "070-BMIDE/packages" {
+		t.Errorf("Expected package path '070-BMIDE/packages', got %q", install.PackagePath)
+	}
+}
+
+func TestIsTemplateInstallLine_OtherUtility(t *testing.T) {
+	// What: Non-tem utilities are not detected
+	var install TemplateInstall
+	if testRun.isTemplateInstallLine(`$TC_BIN/plmxml_import -templates=x`, &install) {
+		t.Error("Expected plmxml_import not to be detected as template install")
+	}
+}
+
+func TestReadTemplateVersion(t *testing.T) {
+	// What: Version is read from feature_<name>.xml root element
+	tmpDir := t.TempDir()
+	zipPath := filepath.Join(tmpDir, "nw4template_template.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"nw4template_template.xml":        "<TcBusinessData/>",
+		"install/feature_nw4template.xml": `<feature name="nw4template" version="2.1.0"/>`,
+	})
+
+	version, err := readTemplateVersion(zipPath, "nw4template")
+	assertNoError(t, err)
+	if version != "2.1.0" {
+		t.Errorf("Expected version '2.1.0', got %q", version)
+	}
+
+	_, err = readTemplateVersion(zipPath, "missing")
+	assertErrorContains(t, err, "not found")
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Scripts generated from Jinja2 or Go templates:
+//
+//	{% if env == 'prod' %}
+//	$TC_BIN/plmxml_import -xml_file="100-Config/{{ env }}/site.xml"
+//	{% endif %}
+//
+// With 'templating.mode: render' the expressions are replaced by the configured values,
+// with 'wildcard' (and for expressions without a value) they match any part of a file
+// name, like a for-loop item. Lines holding only a template statement are skipped.
+
+// Templating modes
+const (
+	TemplatingRender   = "render"
+	TemplatingWildcard = "wildcard"
+)
+
+var (
+	templateExpressionRegex = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	templateStatementRegex  = regexp.MustCompile(`^\s*(?:\{%.*%\}|\{#.*#\}|\{\{-?\s*(?:if|else|end|range|with|define|block|template|/\*)\b.*\}\})\s*$`)
+)
+
+// templateValueName returns the name of the value an expression refers to: the
+// expression without the leading dot of Go templates and without Jinja filters
+func templateValueName(expression string) string {
+	name := strings.SplitN(expression, "|", 2)[0]
+	return strings.TrimPrefix(strings.TrimSpace(name), ".")
+}
+
+// isTemplateStatement reports whether the line holds only a template statement or comment
+func (r *run) isTemplateStatement(line string) bool {
+	return r.templateSettings.Mode != "" && templateStatementRegex.MatchString(line)
+}
+
+// renderTemplateLine replaces the expressions of a line with their configured values in
+// render mode. Expressions without a value are reported and kept, to be validated as wildcards.
+func (r *run) renderTemplateLine(scriptFile string, line string, lineNumber int) string {
+	if r.templateSettings.Mode != TemplatingRender || !strings.Contains(line, "{{") {
+		return line
+	}
+	return templateExpressionRegex.ReplaceAllStringFunc(line, func(expression string) string {
+		name := templateValueName(templateExpressionRegex.FindStringSubmatch(expression)[1])
+		if value, ok := r.templateSettings.Values[name]; ok {
+			return value
+		}
+		r.reportFinding(Finding{Rule: RuleTemplateValue, Script: scriptFile, Line: lineNumber,
+			Suggestion: logger.Format("add '{n}' to templating.values", "n", name)},
+			"'{s}' line '{ln}': template expression '{e}' has no value, validated as wildcard", "s", scriptFile, "ln", lineNumber, "e", expression)
+		return expression
+	})
+}
+
+// templateReference returns the glob pattern of a path built from template
+// expressions, each expression matching any part of a file name
+func (r *run) templateReference(filePath string) (LoopReference, bool) {
+	if r.templateSettings.Mode == "" || !templateExpressionRegex.MatchString(filePath) {
+		return LoopReference{}, false
+	}
+	expressions := templateExpressionRegex.FindAllString(filePath, -1)
+	return LoopReference{
+		Variable: strings.Join(expressions, " "),
+		Patterns: []string{templateExpressionRegex.ReplaceAllLiteralString(filePath, "*")},
+	}, true
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// Tests for scripts generated from templates
+
+// What: Values are referred to with or without the Go template dot, filters are ignored
+func TestTemplateValueName(t *testing.T) {
+	tests := map[string]string{
+		"env":            "env",
+		".Env":           "Env",
+		"env | upper":    "env",
+		" release|trim ": "release",
+	}
+	for expression, want := range tests {
+		if got := templateValueName(expression); got != want {
+			t.Errorf("templateValueName(%q) = %q, want %q", expression, got, want)
+		}
+	}
+}
+
+// What: Lines holding only a Jinja or Go template statement are template statements
+func TestIsTemplateStatement(t *testing.T) {
+	original := testRun.templateSettings
+	defer func() { testRun.templateSettings = original }()
+	testRun.templateSettings = templating{Mode: TemplatingWildcard}
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"{% if env == 'prod' %}", true},
+		{"  {%- endfor -%}", true},
+		{"{# generated #}", true},
+		{"{{ if .Prod }}", true},
+		{"{{- end }}", true},
+		{`plmxml_import -xml_file="{{ env }}/a.xml"`, false},
+		{"{{ env }}", false},
+	}
+	for _, tt := range tests {
+		if got := testRun.isTemplateStatement(tt.line); got != tt.want {
+			t.Errorf("isTemplateStatement(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	testRun.templateSettings = templating{}
+	if testRun.isTemplateStatement("{% if env %}") {
+		t.Error("Expected no template statements without templating")
+	}
+}
+
+// What: Render mode substitutes the values; expressions without a value are kept and reported
+func TestRenderTemplateLine(t *testing.T) {
+	originalSettings, originalResult := testRun.templateSettings, testRun.analysisResult
+	defer func() { testRun.templateSettings, testRun.analysisResult = originalSettings, originalResult }()
+	testRun.analysisResult = Result{}
+	testRun.templateSettings = templating{Mode: TemplatingRender, Values: map[string]string{"env": "prod"}}
+
+	got := testRun.renderTemplateLine("deploy.sh", `plmxml_import -xml_file="100-Config/{{ .env }}/{{ site }}.xml"`, 3)
+	if want := `plmxml_import -xml_file="100-Config/prod/{{ site }}.xml"`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if len(testRun.analysisResult.Findings) != 1 || testRun.analysisResult.Findings[0].Rule != RuleTemplateValue || testRun.analysisResult.Findings[0].Line != 3 {
+		t.Errorf("Expected a template value finding on line 3, got %v", testRun.analysisResult.Findings)
+	}
+
+	testRun.templateSettings.Mode = TemplatingWildcard
+	line := `plmxml_import -xml_file="100-Config/{{ env }}/a.xml"`
+	if got := testRun.renderTemplateLine("deploy.sh", line, 4); got != line {
+		t.Errorf("Expected the line unchanged in wildcard mode, got %q", got)
+	}
+}
+
+// What: Template expressions in a path become wildcards of a glob pattern
+func TestTemplateReference(t *testing.T) {
+	original := testRun.templateSettings
+	defer func() { testRun.templateSettings = original }()
+	testRun.templateSettings = templating{Mode: TemplatingWildcard}
+
+	ref, ok := testRun.templateReference("100-Config/{{ env }}/site_{{ .Release }}.xml")
+	if !ok {
+		t.Fatal("Expected a template reference")
+	}
+	if len(ref.Patterns) != 1 || ref.Patterns[0] != "100-Config/*/site_*.xml" {
+		t.Errorf("Unexpected patterns %v", ref.Patterns)
+	}
+	if ref.Variable != "{{ env }} {{ .Release }}" {
+		t.Errorf("Unexpected expressions %q", ref.Variable)
+	}
+
+	if _, ok := testRun.templateReference("100-Config/a.xml"); ok {
+		t.Error("Expected no template reference for a plain path")
+	}
+}
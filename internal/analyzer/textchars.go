@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Characters editors put into text files that the Teamcenter import parsers do not
+// read as the characters they look like: a UTF-8 byte order mark becomes part of the
+// first column or the XML declaration, smart quotes do not close a quoted value and
+// non-breaking spaces are not separators.
+
+// defaultTextFileExtensions are the extensions of the referenced files scanned
+// when 'text_file_extensions' is empty
+var defaultTextFileExtensions = []string{".txt", ".csv", ".xml"}
+
+// Kinds of the characters breaking the import parsers
+const (
+	charBOM         = "UTF-8 byte order mark"
+	charSmartQuote  = "smart quote"
+	charNonBreaking = "non-breaking space"
+)
+
+// badCharacters maps the characters breaking the import parsers to their kind
+var badCharacters = map[rune]string{
+	'\u2018': charSmartQuote, '\u2019': charSmartQuote, '\u201A': charSmartQuote, // ‘ ’ ‚
+	'\u201C': charSmartQuote, '\u201D': charSmartQuote, '\u201E': charSmartQuote, // “ ” „
+	'\u00A0': charNonBreaking, '\u202F': charNonBreaking, // no-break space, narrow no-break space
+}
+
+// characterOccurrences are the occurrences of a kind of character in a file
+type characterOccurrences struct {
+	Kind   string
+	Count  int
+	Offset int // byte offset of the first occurrence, 0-based
+	Line   int // line of the first occurrence, 1-based
+	Column int // column of the first occurrence in characters, 1-based
+}
+
+// scanTextCharacters returns the byte order mark, smart quotes and non-breaking spaces
+// of the content of a file, by kind in the order of their first occurrence. Content
+// that is not valid UTF-8 is read as Windows-1252, where they are single bytes.
+func scanTextCharacters(data []byte) []characterOccurrences {
+	var found []characterOccurrences
+	index := make(map[string]int)
+	add := func(kind string, offset, line, column int) {
+		if i, ok := index[kind]; ok {
+			found[i].Count++
+			return
+		}
+		index[kind] = len(found)
+		found = append(found, characterOccurrences{Kind: kind, Count: 1, Offset: offset, Line: line, Column: column})
+	}
+
+	offset := 0
+	if bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}) {
+		add(charBOM, 0, 1, 1)
+		offset = 3
+	}
+	decode := utf8.DecodeRune
+	if !utf8.Valid(data[offset:]) {
+		decode = decodeWindows1252Rune
+	}
+	line, column := 1, 1
+	for offset < len(data) {
+		r, size := decode(data[offset:])
+		if kind, ok := badCharacters[r]; ok {
+			add(kind, offset, line, column)
+		}
+		if r == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+		offset += size
+	}
+	return found
+}
+
+// decodeWindows1252Rune returns the character of the first byte of data in Windows-1252
+func decodeWindows1252Rune(data []byte) (rune, int) {
+	b := data[0]
+	if b >= 0x80 && b <= 0x9F {
+		return windows1252[b-0x80], 1
+	}
+	return rune(b), 1
+}
+
+// isTextFile reports whether the file has one of the scanned extensions
+func (r *run) isTextFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range r.textFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTextFileExtensions checks that each extension is set and its own name
+func ValidateTextFileExtensions(extensions []string) error {
+	for i, ext := range extensions {
+		if name := strings.TrimPrefix(strings.TrimSpace(ext), "."); name == "" || strings.ContainsAny(name, `./\*?`) {
+			return fmt.Errorf("text file extension at index %d is invalid: '%s' (e.g. '.csv')", i, ext)
+		}
+	}
+	return nil
+}
+
+// normalizeTextFileExtensions returns the configured extensions lower case with a
+// leading dot, the defaults when none is configured
+func normalizeTextFileExtensions(extensions []string) []string {
+	if len(extensions) == 0 {
+		return defaultTextFileExtensions
+	}
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
+// referencedTextFiles returns the text files referenced by the script, relative to the
+// source code root with forward slashes and sorted, with the line referencing them, 0
+// for the files referenced through list files and stylesheet import definitions
+func (r *run) referencedTextFiles(lines Lines) ([]string, map[string]int) {
+	referencedBy := make(map[string]int)
+	add := func(file string, lineNumber int) {
+		if !r.isTextFile(file) {
+			return
+		}
+		if current, ok := referencedBy[file]; !ok || (lineNumber > 0 && (current == 0 || lineNumber < current)) {
+			referencedBy[file] = lineNumber
+		}
+	}
+	for _, flag := range lines.ValidFlags() {
+		add(slashPath(flag.Path, r.currentScriptTargetOS), flag.Line)
+	}
+	for lineNumber, ref := range lines.LoopReference {
+		for _, match := range ref.Matches {
+			add(filepath.ToSlash(match), lineNumber)
+		}
+	}
+	for _, references := range lines.ListReferences {
+		for _, file := range references {
+			add(file, 0)
+		}
+	}
+	for _, definition := range lines.StyleSheetImport {
+		for _, dataset := range definition.Datasets {
+			add(filepath.ToSlash(dataset.XML), 0)
+		}
+	}
+
+	files := make([]string, 0, len(referencedBy))
+	for file := range referencedBy {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, referencedBy
+}
+
+// checkReferencedTextCharacters reports the referenced text files holding a UTF-8 byte
+// order mark, smart quotes or non-breaking spaces, once per kind of character with the
+// number of occurrences and the offset of the first
+func (r *run) checkReferencedTextCharacters(scriptFile string, lines Lines) {
+	if !r.checkTextCharacters || !r.ruleEnabled(RuleTextCharacters) {
+		return
+	}
+	r.log.Debug("checking the characters of the text files referenced by '{s}'", "s", scriptFile)
+
+	files, referencedBy := r.referencedTextFiles(lines)
+	reported := 0
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(r.sourceCodeRoot, filepath.FromSlash(file)))
+		if err != nil {
+			// missing and unreadable files are findings of the existence checks
+			r.log.Debug("Not scanning '{f}': {e}", "f", file, "e", err.Error())
+			continue
+		}
+		for _, found := range scanTextCharacters(data) {
+			f := Finding{Rule: RuleTextCharacters, Script: scriptFile, Line: referencedBy[file], Path: file}
+			if found.Kind == charBOM {
+				f.Suggestion = "save the file as UTF-8 without byte order mark"
+				r.reportFinding(f, "'{f}' referenced by '{s}' starts with a {kind} (byte offset 0)", "f", file, "s", scriptFile, "kind", found.Kind)
+			} else {
+				f.Suggestion = fmt.Sprintf("replace the %s(s) with their ASCII equivalent", found.Kind)
+				r.reportFinding(f, "'{f}' referenced by '{s}' has {n} {kind}(s), the first at byte offset {o} (line {l}, column {c})",
+					"f", file, "s", scriptFile, "n", found.Count, "kind", found.Kind, "o", found.Offset, "l", found.Line, "c", found.Column)
+			}
+			reported++
+		}
+	}
+	if reported == 0 {
+		r.log.Info("No byte order marks, smart quotes or non-breaking spaces found in {n} referenced text file(s)", "n", len(files))
+	}
+}
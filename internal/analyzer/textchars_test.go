@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupTextCharactersTest writes the files of the repository and enables the check
+func setupTextCharactersTest(t *testing.T, files map[string]string) {
+	t.Helper()
+	writeStylesheetFixture(t, "linux", files)
+	originalCheck, originalExtensions := testRun.checkTextCharacters, testRun.textFileExtensions
+	t.Cleanup(func() { testRun.checkTextCharacters, testRun.textFileExtensions = originalCheck, originalExtensions })
+	testRun.checkTextCharacters, testRun.textFileExtensions = true, normalizeTextFileExtensions(nil)
+}
+
+func TestScanTextCharacters(t *testing.T) {
+	// What: The byte order mark, smart quotes and non-breaking spaces are found with the offset, line and column of the first
+	data := []byte("\xEF\xBB\xBFname,value\n\u201Cdesc\u201D,a\u00A0b\n")
+	want := []characterOccurrences{
+		{Kind: charBOM, Count: 1, Offset: 0, Line: 1, Column: 1},
+		{Kind: charSmartQuote, Count: 2, Offset: 14, Line: 2, Column: 1},
+		{Kind: charNonBreaking, Count: 1, Offset: 26, Line: 2, Column: 9},
+	}
+	if got := scanTextCharacters(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := scanTextCharacters([]byte("name,\"value\"\n")); got != nil {
+		t.Errorf("Expected nothing in ASCII content, got %+v", got)
+	}
+}
+
+func TestScanTextCharacters_Windows1252(t *testing.T) {
+	// What: Content that is not UTF-8 is read as Windows-1252, its smart quotes and non-breaking spaces are single bytes
+	got := scanTextCharacters([]byte("a,\x93b\x94\xA0c"))
+	want := []characterOccurrences{
+		{Kind: charSmartQuote, Count: 2, Offset: 2, Line: 1, Column: 3},
+		{Kind: charNonBreaking, Count: 1, Offset: 5, Line: 1, Column: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestCheckReferencedTextCharacters(t *testing.T) {
+	// What: Referenced text files and the files of their lists are scanned, other extensions and missing files are not
+	setupTextCharactersTest(t, map[string]string{
+		"100-Config/prefs.xml":   "\xEF\xBB\xBF<preferences/>",
+		"100-Config/clean.csv":   "a,b\n",
+		"100-Config/notes.md":    "\u201Cquoted\u201D",
+		"500-Lists/master.txt":   "part.csv\n",
+		"500-Lists/part.csv":     "id;\u2018name\u2019\n",
+		"100-Config/binary.png":  "\u00A0",
+		"100-Config/ignored.txt": "",
+	})
+	lines := newLines()
+	lines.Flags = flagsOf(map[int]string{2: "100-Config/prefs.xml", 3: "100-Config/clean.csv", 4: "100-Config/notes.md", 5: "100-Config/missing.txt", 6: "500-Lists/master.txt"})
+	lines.ListReferences = map[string][]string{"500-Lists/master.txt": {"500-Lists/part.csv"}}
+
+	testRun.checkReferencedTextCharacters("deploy.sh", lines)
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if f := findings[0]; f.Rule != RuleTextCharacters || f.Path != "100-Config/prefs.xml" || f.Line != 2 ||
+		f.Message != "'100-Config/prefs.xml' referenced by 'deploy.sh' starts with a UTF-8 byte order mark (byte offset 0)" {
+		t.Errorf("Unexpected byte order mark finding %+v", f)
+	}
+	if f := findings[1]; f.Path != "500-Lists/part.csv" || f.Line != 0 ||
+		f.Message != "'500-Lists/part.csv' referenced by 'deploy.sh' has 2 smart quote(s), the first at byte offset 3 (line 1, column 4)" {
+		t.Errorf("Unexpected smart quote finding %+v", f)
+	}
+}
+
+func TestCheckReferencedTextCharacters_Disabled(t *testing.T) {
+	// What: Without 'check_text_characters' the files are not scanned
+	setupTextCharactersTest(t, map[string]string{"a.csv": "\xEF\xBB\xBFa"})
+	testRun.checkTextCharacters = false
+	lines := newLines()
+	lines.Flags = flagsOf(map[int]string{1: "a.csv"})
+
+	testRun.checkReferencedTextCharacters("deploy.sh", lines)
+	if len(testRun.analysisResult.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", testRun.analysisResult.Findings)
+	}
+}
+
+func TestTextFileExtensions(t *testing.T) {
+	// What: Configured extensions are matched case-insensitively with or without leading dot, invalid ones are rejected
+	original := testRun.textFileExtensions
+	defer func() { testRun.textFileExtensions = original }()
+	testRun.textFileExtensions = normalizeTextFileExtensions([]string{"LST", ".Csv"})
+	if !testRun.isTextFile("a/b.lst") || !testRun.isTextFile("a/B.CSV") || testRun.isTextFile("a.xml") {
+		t.Errorf("Unexpected matching of %v", testRun.textFileExtensions)
+	}
+	if err := ValidateTextFileExtensions([]string{".csv", "txt"}); err != nil {
+		t.Errorf("Expected valid extensions, got %v", err)
+	}
+	for _, ext := range []string{"", ".", "*.csv", "a/b"} {
+		if err := ValidateTextFileExtensions([]string{ext}); err == nil || !strings.Contains(err.Error(), "index 0 is invalid") {
+			t.Errorf("Expected %q to be rejected, got %v", ext, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// evaluateThresholds compares the analysis results of the scripts with the configured
+// thresholds. Returns one message per violated threshold.
+func (r *run) evaluateThresholds(scripts []scriptDefinition, limits thresholds) []string {
+	var violations []string
+
+	missing, unreferenced := 0, 0
+	for _, script := range scripts {
+		missing += len(r.analysisResult.File[script.Filename].Missing)
+		unreferenced += len(r.analysisResult.File[script.Filename].Unreferenced)
+	}
+
+	if limits.MaxMissingFiles != nil && missing > *limits.MaxMissingFiles {
+		violations = append(violations, fmt.Sprintf("%d missing files exceed 'max_missing_files' of %d", missing, *limits.MaxMissingFiles))
+	}
+	if limits.MaxUnreferencedFiles != nil && unreferenced > *limits.MaxUnreferencedFiles {
+		violations = append(violations, fmt.Sprintf("%d unreferenced files exceed 'max_unreferenced_files' of %d", unreferenced, *limits.MaxUnreferencedFiles))
+	}
+
+	dirs := make([]string, 0, len(limits.MinCoveragePercent))
+	for dir := range limits.MinCoveragePercent {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, script := range scripts {
+		metrics := scriptMetrics(r.analysisResult.File[script.Filename], script.TargetOS)
+		violations = append(violations, metricsViolations(script.Filename, metrics, limits)...)
+	}
+
+	for _, script := range scripts {
+		coverage := r.analysisResult.File[script.Filename].Coverage
+		for _, dir := range dirs {
+			c, ok := coverage[dir]
+			if !ok {
+				continue
+			}
+			if minimum := limits.MinCoveragePercent[dir]; c.Percent() < minimum {
+				violations = append(violations, fmt.Sprintf("'%s' coverage of '%s' is %.0f%%, below 'min_coverage_percent' of %.0f%%", script.Filename, dir, c.Percent(), minimum))
+			}
+		}
+	}
+	return violations
+}
+
+// thresholdsConfigured reports whether any threshold is set
+func thresholdsConfigured(limits thresholds) bool {
+	return limits.MaxMissingFiles != nil || limits.MaxUnreferencedFiles != nil || len(limits.MinCoveragePercent) > 0 ||
+		limits.MaxScriptLines != nil || limits.MaxUtilityInvocations != nil || limits.MaxDistinctFiles != nil || limits.MaxConditionalDepth != nil
+}
+
+// checkThresholds logs the threshold evaluation and returns an error if any is violated
+func (r *run) checkThresholds(scripts []scriptDefinition, limits thresholds) error {
+	if !thresholdsConfigured(limits) {
+		return nil
+	}
+
+	r.log.Heading(" ")
+	r.log.Separate("THRESHOLDS CHECK")
+	r.log.Separate("=====================================")
+
+	violations := r.evaluateThresholds(scripts, limits)
+	for _, violation := range violations {
+		r.reportFinding(Finding{Rule: RuleThresholdExceeded}, violation)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d threshold(s) exceeded", len(violations))
+	}
+	r.log.Separate("none")
+	return nil
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests for the threshold-based pass/fail policy
+
+func setupThresholdsTest() []scriptDefinition {
+	win, linux := newLines(), newLines()
+	win.Missing, win.Unreferenced = []string{"x.xml"}, []string{"a.xml", "b.xml", "c.xml", "d.xml"}
+	win.Coverage["100-Preferences"] = DirectoryCoverage{Present: 10, Referenced: 10}
+	win.Coverage["300-Workflows"] = DirectoryCoverage{Present: 10, Referenced: 5}
+	linux.Missing = []string{"x.xml", "y.xml"}
+
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.bat": win, "deploy.sh": linux}}
+	return []scriptDefinition{
+		{Filename: "deploy.bat", TargetOS: "windows"},
+		{Filename: "deploy.sh", TargetOS: "linux"},
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestEvaluateThresholds_WithinLimits(t *testing.T) {
+	scripts := setupThresholdsTest()
+	limits := thresholds{
+		MaxMissingFiles:      intPtr(3),
+		MaxUnreferencedFiles: intPtr(4),
+		MinCoveragePercent:   map[string]float64{"100-Preferences": 100, "300-Workflows": 50},
+	}
+
+	if violations := testRun.evaluateThresholds(scripts, limits); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestEvaluateThresholds_Exceeded(t *testing.T) {
+	scripts := setupThresholdsTest()
+	limits := thresholds{
+		MaxMissingFiles:      intPtr(0),
+		MaxUnreferencedFiles: intPtr(3),
+		MinCoveragePercent:   map[string]float64{"300-Workflows": 60},
+	}
+
+	violations := testRun.evaluateThresholds(scripts, limits)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %d: %v", len(violations), violations)
+	}
+	if !strings.Contains(violations[2], "300-Workflows") {
+		t.Errorf("Expected coverage violation for 300-Workflows, got %q", violations[2])
+	}
+}
+
+func TestCheckThresholds_NotConfigured(t *testing.T) {
+	scripts := setupThresholdsTest()
+	if err := testRun.checkThresholds(scripts, thresholds{}); err != nil {
+		t.Errorf("Expected no error without thresholds, got %v", err)
+	}
+	if err := testRun.checkThresholds(scripts, thresholds{MaxMissingFiles: intPtr(0)}); err == nil {
+		t.Error("Expected error when max_missing_files is exceeded")
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"time"
+)
+
+// Analysis phases timed per script, and for the whole run
+const (
+	PhaseSyntax        = "syntax"
+	PhasePathCheck     = "path check"
+	PhaseContentChecks = "content checks"
+	PhaseStylesheet    = "stylesheet"
+	PhaseTraversal     = "traversal"
+	PhaseRemoteListing = "remote listing"
+	PhaseParity        = "parity"
+)
+
+// phases lists the analysis phases, which are the scopes of 'log_levels'
+var phases = []string{PhaseSyntax, PhasePathCheck, PhaseContentChecks, PhaseStylesheet, PhaseTraversal, PhaseRemoteListing, PhaseParity}
+
+// IsPhase reports whether name is an analysis phase
+func IsPhase(name string) bool {
+	for _, phase := range phases {
+		if phase == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PhaseTiming is the wall-clock duration of an analysis phase
+type PhaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// timeScriptPhase runs a phase of the current script, logged in the scope of the
+// phase (see runPhase), and records its duration. Durations of a phase run several times are added up.
+func (r *run) timeScriptPhase(phase string, fn func()) {
+	if r.runStopped() {
+		return
+	}
+	start := time.Now()
+	r.runPhase(phase, r.currentScript, fn)
+	result, ok := r.analysisResult.File[r.currentScript]
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start)
+	for i := range result.Timings {
+		if result.Timings[i].Phase == phase {
+			result.Timings[i].Duration += elapsed
+			return
+		}
+	}
+	result.Timings = append(result.Timings, PhaseTiming{phase, elapsed})
+	r.analysisResult.File[r.currentScript] = result
+}
+
+// timeRunPhase runs a phase covering all scripts, logged in the scope of the phase,
+// and records its duration
+func (r *run) timeRunPhase(phase string, fn func()) {
+	if r.runStopped() {
+		return
+	}
+	start := time.Now()
+	r.runPhase(phase, "", fn)
+	r.analysisResult.Timings = append(r.analysisResult.Timings, PhaseTiming{phase, time.Since(start)})
+}
+
+// logTimings writes the phase durations, per script and for the run
+func (r *run) logTimings(scripts []scriptDefinition) {
+	r.log.Info("PHASE TIMING")
+	for _, script := range scripts {
+		for _, t := range r.analysisResult.File[script.Filename].Timings {
+			r.log.Info("'{s}' {p}: {d}", "s", script.Filename, "p", t.Phase, "d", t.Duration.Round(time.Microsecond))
+		}
+	}
+	for _, t := range r.analysisResult.Timings {
+		r.log.Info("{p}: {d}", "p", t.Phase, "d", t.Duration.Round(time.Microsecond))
+	}
+}
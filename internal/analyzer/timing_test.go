@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// What: Script phases are recorded against the current script, in call order
+func TestTimeScriptPhase(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.currentScript = "deploy.sh"
+
+	ran := false
+	testRun.timeScriptPhase(PhaseSyntax, func() { ran = true; time.Sleep(time.Millisecond) })
+	testRun.timeScriptPhase(PhaseTraversal, func() {})
+
+	if !ran {
+		t.Fatal("Expected the phase function to run")
+	}
+	timings := testRun.analysisResult.File["deploy.sh"].Timings
+	if len(timings) != 2 || timings[0].Phase != PhaseSyntax || timings[1].Phase != PhaseTraversal {
+		t.Fatalf("Unexpected timings: %v", timings)
+	}
+	if timings[0].Duration < time.Millisecond {
+		t.Errorf("Expected syntax duration of at least 1ms, got %v", timings[0].Duration)
+	}
+}
+
+// What: Durations of a phase run several times are added up
+func TestTimeScriptPhase_Repeated(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.currentScript = "deploy.sh"
+
+	testRun.timeScriptPhase(PhaseContentChecks, func() { time.Sleep(time.Millisecond) })
+	testRun.timeScriptPhase(PhaseStylesheet, func() {})
+	testRun.timeScriptPhase(PhaseContentChecks, func() { time.Sleep(time.Millisecond) })
+
+	timings := testRun.analysisResult.File["deploy.sh"].Timings
+	if len(timings) != 2 || timings[0].Phase != PhaseContentChecks {
+		t.Fatalf("Unexpected timings: %v", timings)
+	}
+	if timings[0].Duration < 2*time.Millisecond {
+		t.Errorf("Expected accumulated duration of at least 2ms, got %v", timings[0].Duration)
+	}
+}
+
+// What: A phase of a script without results still runs but is not recorded
+func TestTimeScriptPhase_UnknownScript(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{}}
+	testRun.currentScript = "deploy.sh"
+
+	ran := false
+	testRun.timeScriptPhase(PhaseSyntax, func() { ran = true })
+
+	if !ran {
+		t.Error("Expected the phase function to run")
+	}
+	if _, ok := testRun.analysisResult.File["deploy.sh"]; ok {
+		t.Error("Expected no result entry to be created")
+	}
+}
+
+// What: Run phases are recorded on the result
+func TestTimeRunPhase(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{}}
+
+	testRun.timeRunPhase(PhaseParity, func() {})
+
+	if len(testRun.analysisResult.Timings) != 1 || testRun.analysisResult.Timings[0].Phase != PhaseParity {
+		t.Errorf("Unexpected timings: %v", testRun.analysisResult.Timings)
+	}
+}
+
+// What: A phase is logged with its level of 'log_levels', the rest of the run with -l
+func TestTimeScriptPhase_LogLevel(t *testing.T) {
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.currentScript = "deploy.sh"
+	var buf bytes.Buffer
+	logger.SetConsoleOutput(&buf)
+	logger.InitLogger("", "error")
+	logger.SetScopeLevels(map[string]string{PhaseStylesheet: "debug"})
+	defer func() {
+		logger.SetScopeLevels(nil)
+		logger.SetConsoleOutput(os.Stdout)
+		logger.InitLogger("", "error")
+	}()
+
+	testRun.timeScriptPhase(PhaseStylesheet, func() { logger.Debug("stylesheet line") })
+	testRun.timeScriptPhase(PhaseSyntax, func() { logger.Debug("syntax line") })
+
+	if output := buf.String(); !strings.Contains(output, "stylesheet line") || strings.Contains(output, "syntax line") {
+		t.Errorf("Expected only the stylesheet phase at debug level, got %q", output)
+	}
+}
+
+// What: Only the analysis phases are phases of 'log_levels'
+func TestIsPhase(t *testing.T) {
+	if !IsPhase(PhaseStylesheet) || !IsPhase("path check") || IsPhase("stylesheets") {
+		t.Error("Unexpected IsPhase result")
+	}
+}
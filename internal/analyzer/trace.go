@@ -0,0 +1,636 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// TraceStep is one line of the dry-run execution trace of a deployment script
+type TraceStep struct {
+	Script      string
+	Line        int
+	Command     string // command as it would run, variables resolved
+	Conditional bool   // runs only if a condition that cannot be evaluated holds
+	Note        string // trace remark instead of a command, e.g. an unevaluated condition
+}
+
+// String formats the step as 'file:line: command'. Conditional commands are
+// marked with '[?]', remarks with 'note:'.
+func (s TraceStep) String() string {
+	switch {
+	case s.Note != "":
+		return fmt.Sprintf("%s:%d: note: %s", s.Script, s.Line, s.Note)
+	case s.Conditional:
+		return fmt.Sprintf("%s:%d: [?] %s", s.Script, s.Line, s.Command)
+	default:
+		return fmt.Sprintf("%s:%d: %s", s.Script, s.Line, s.Command)
+	}
+}
+
+const (
+	maxTraceDepth = 10    // nesting of call/source, protects against recursive scripts
+	maxTraceSteps = 10000 // lines executed per script, protects against goto loops
+)
+
+var (
+	shellAssignmentRegex = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	shellVariableRegex   = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+	shellIfRegex         = regexp.MustCompile(`^(if|elif)\s+(.+?)(?:\s*;\s*then)?$`)
+	shellSourceRegex     = regexp.MustCompile(`^(?:source|\.)\s+(\S+)`)
+
+	batchAssignmentRegex = regexp.MustCompile(`(?i)^set\s+(/[ap]\s+)?"?([^=\s"]+)=([^"]*)"?\s*$`)
+	batchVariableRegex   = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+	batchLabelRegex      = regexp.MustCompile(`^:([^\s:]+)`)
+	batchIfRegex         = regexp.MustCompile(`(?i)^if\s+(/i\s+)?(not\s+)?(.+)$`)
+	batchTestRegex       = regexp.MustCompile(`(?i)^(exist|defined|errorlevel)\s+("[^"]*"|\S+)\s+(.+)$`)
+	batchCompareRegex    = regexp.MustCompile(`^("[^"]*"|[^\s=]+)\s*==\s*("[^"]*"|\S+)\s+(.+)$`)
+	batchGotoRegex       = regexp.MustCompile(`(?i)^goto\s+:?(\S+)`)
+	batchCallRegex       = regexp.MustCompile(`(?i)^call\s+(\S+)`)
+)
+
+// branchFrame is an open if/elif/else block
+type branchFrame struct {
+	active    bool // lines of the current branch are executed
+	done      bool // a previous branch of the block was definitely taken
+	uncertain bool // a condition of the block cannot be evaluated
+}
+
+// tracer symbolically executes deployment scripts
+type tracer struct {
+	run   *run
+	root  string
+	vars  map[string]string
+	steps []TraceStep
+	depth int
+}
+
+// Trace symbolically executes a configured deployment script line by line and returns
+// the commands that would run. Variables set by the script are expanded, call/source
+// are followed and simple if/goto are honored. Branches whose conditions cannot be
+// evaluated (unknown variables, command results) are noted and traced as conditional.
+func (a *Analyzer) Trace(params Parameters, scriptFile string) ([]TraceStep, error) {
+	return a.newRun().trace(params, scriptFile)
+}
+
+// Trace traces a script with the default logger, see (*Analyzer).Trace
+func Trace(params Parameters, scriptFile string) ([]TraceStep, error) {
+	return New(logger.Default()).Trace(params, scriptFile)
+}
+
+// trace traces a script in the run, see (*Analyzer).Trace
+func (r *run) trace(params Parameters, scriptFile string) ([]TraceStep, error) {
+	for _, script := range params.Scripts {
+		if script.Filename != scriptFile {
+			continue
+		}
+		t := &tracer{run: r, root: params.SourceCodeRoot, vars: make(map[string]string)}
+		removeArchives, err := r.extractScriptArchives([]scriptDefinition{script}, t.root)
+		defer removeArchives()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(r.scriptFilePath(t.root, scriptFile)); err != nil {
+			return nil, withKind(KindIO, fmt.Errorf("cannot trace '%s': %w", scriptFile, err))
+		}
+		if script.TargetOS == "windows" {
+			t.traceBatch(scriptFile)
+		} else {
+			t.traceShell(scriptFile)
+		}
+		return t.steps, nil
+	}
+	return nil, withKind(KindConfig, fmt.Errorf("script '%s' is not configured", scriptFile))
+}
+
+func (t *tracer) command(file string, line int, command string, conditional bool) {
+	t.steps = append(t.steps, TraceStep{Script: file, Line: line, Command: command, Conditional: conditional})
+}
+
+func (t *tracer) note(file string, line int, format string, args ...interface{}) {
+	t.steps = append(t.steps, TraceStep{Script: file, Line: line, Note: fmt.Sprintf(format, args...)})
+}
+
+// readLines returns the lines of a script relative to the source code root,
+// transcoded to UTF-8 like for the analysis
+func (t *tracer) readLines(file string) ([]string, error) {
+	path := filepath.Join(t.root, filepath.FromSlash(toSlash(file)))
+	if IsArchivedScript(file) {
+		path = t.run.scriptFilePath(t.root, file)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content, _ := decodeScript(data)
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// enter opens a called or sourced script, false when it cannot be traced
+func (t *tracer) enter(caller string, line int, file string) ([]string, bool) {
+	if t.depth >= maxTraceDepth {
+		t.note(caller, line, "'%s' not followed, nesting deeper than %d", file, maxTraceDepth)
+		return nil, false
+	}
+	lines, err := t.readLines(file)
+	if err != nil {
+		t.note(caller, line, "'%s' cannot be followed: %v", file, err)
+		return nil, false
+	}
+	return lines, true
+}
+
+// exists evaluates a file test against the source code root. Absolute paths and
+// paths with unresolved variables cannot be evaluated.
+func (t *tracer) exists(p string, unresolved func(string) bool, wantDir bool) (result bool, known bool) {
+	if unresolved(p) || isAbsoluteReference(p) {
+		return false, false
+	}
+	info, err := os.Stat(filepath.Join(t.root, filepath.FromSlash(toSlash(p))))
+	if err != nil {
+		return false, true
+	}
+	return !wantDir || info.IsDir(), true
+}
+
+// setVariable records an assignment; assignments in uncertain branches make the
+// variable unknown
+func (t *tracer) setVariable(name, value string, known bool) {
+	if known {
+		t.vars[name] = value
+	} else {
+		delete(t.vars, name)
+	}
+}
+
+func framesActive(frames []branchFrame) bool {
+	for _, f := range frames {
+		if !f.active {
+			return false
+		}
+	}
+	return true
+}
+
+func framesUncertain(frames []branchFrame) bool {
+	for _, f := range frames {
+		if f.uncertain {
+			return true
+		}
+	}
+	return false
+}
+
+// openBranch starts the branch of an if/elif with the evaluated condition
+func openBranch(frame *branchFrame, result, known bool) {
+	switch {
+	case frame.done:
+		frame.active = false
+	case !known:
+		frame.active, frame.uncertain = true, true
+	default:
+		frame.active, frame.done = result, result
+	}
+}
+
+// unquote removes one pair of surrounding quotes
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitWords splits a shell condition into words, keeping quoted words together
+func splitWords(s string) []string {
+	var words []string
+	var current strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+// Shell (Linux) scripts
+
+func (t *tracer) expandShell(s string) string {
+	return shellVariableRegex.ReplaceAllStringFunc(s, func(m string) string {
+		sub := shellVariableRegex.FindStringSubmatch(m)
+		name := sub[1] + sub[2]
+		if value, ok := t.vars[name]; ok {
+			return value
+		}
+		return m
+	})
+}
+
+func shellUnresolved(s string) bool {
+	return strings.Contains(s, "$") || strings.Contains(s, "`")
+}
+
+// evalShellCondition evaluates '[ ... ]', '[[ ... ]]' and 'test ...' conditions with
+// string comparisons and file tests
+func (t *tracer) evalShellCondition(condition string) (result bool, known bool) {
+	words := splitWords(condition)
+	negate := false
+	if len(words) > 0 && words[0] == "!" {
+		negate, words = true, words[1:]
+	}
+	switch {
+	case len(words) >= 2 && (words[0] == "[" && words[len(words)-1] == "]" || words[0] == "[[" && words[len(words)-1] == "]]"):
+		words = words[1 : len(words)-1]
+	case len(words) >= 1 && words[0] == "test":
+		words = words[1:]
+	default:
+		return false, false // exit status of a command
+	}
+	if len(words) > 0 && words[0] == "!" {
+		negate, words = !negate, words[1:]
+	}
+	for _, w := range words {
+		if shellUnresolved(w) {
+			return false, false
+		}
+	}
+
+	switch {
+	case len(words) == 2 && (words[0] == "-f" || words[0] == "-e" || words[0] == "-r" || words[0] == "-s"):
+		result, known = t.exists(words[1], shellUnresolved, false)
+	case len(words) == 2 && words[0] == "-d":
+		result, known = t.exists(words[1], shellUnresolved, true)
+	case len(words) == 2 && words[0] == "-z":
+		result, known = words[1] == "", true
+	case len(words) == 2 && words[0] == "-n":
+		result, known = words[1] != "", true
+	case len(words) == 3 && (words[1] == "=" || words[1] == "=="):
+		result, known = words[0] == words[2], true
+	case len(words) == 3 && words[1] == "!=":
+		result, known = words[0] != words[2], true
+	default:
+		return false, false
+	}
+	return result != negate, known
+}
+
+// traceShell traces a shell script, returns true when the script exits
+func (t *tracer) traceShell(file string) bool {
+	lines, err := t.readLines(file)
+	if err != nil {
+		t.note(file, 0, "'%s' cannot be read: %v", file, err)
+		return false
+	}
+	return t.traceShellLines(file, lines)
+}
+
+func (t *tracer) traceShellLines(file string, lines []string) bool {
+	var frames []branchFrame
+	for i, raw := range lines {
+		n := i + 1
+		code, _ := stripComment(raw, "linux")
+		line := strings.TrimSpace(code)
+		if line == "" || line == "then" {
+			continue
+		}
+
+		// Block structure is tracked also in branches that are not executed
+		if m := shellIfRegex.FindStringSubmatch(line); m != nil {
+			if m[1] == "if" {
+				frames = append(frames, branchFrame{})
+				if !framesActive(frames[:len(frames)-1]) {
+					frames[len(frames)-1].done = true
+					continue
+				}
+			} else if len(frames) == 0 || !framesActive(frames[:len(frames)-1]) {
+				continue
+			}
+			condition := t.expandShell(m[2])
+			result, known := t.evalShellCondition(condition)
+			if !known {
+				t.note(file, n, "condition '%s' cannot be evaluated, the branch is traced as conditional", condition)
+			}
+			openBranch(&frames[len(frames)-1], result, known)
+			continue
+		}
+		if line == "else" {
+			if len(frames) > 0 {
+				top := &frames[len(frames)-1]
+				top.active = !top.done
+			}
+			continue
+		}
+		if line == "fi" {
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+			continue
+		}
+		if !framesActive(frames) {
+			continue
+		}
+		uncertain := framesUncertain(frames)
+
+		if m := shellAssignmentRegex.FindStringSubmatch(line); m != nil {
+			value := m[2]
+			if !strings.HasPrefix(value, "'") {
+				value = t.expandShell(value)
+			}
+			value = unquote(value)
+			t.setVariable(m[1], value, !uncertain && !shellUnresolved(value))
+			continue
+		}
+
+		command := t.expandShell(line)
+		if m := shellSourceRegex.FindStringSubmatch(command); m != nil {
+			t.command(file, n, command, uncertain)
+			sourced := unquote(m[1])
+			if shellUnresolved(sourced) {
+				t.note(file, n, "'%s' cannot be followed, the path is not resolved", sourced)
+				continue
+			}
+			if sourcedLines, ok := t.enter(file, n, sourced); ok {
+				t.depth++
+				exited := t.traceShellLines(sourced, sourcedLines)
+				t.depth--
+				if exited {
+					return true
+				}
+			}
+			continue
+		}
+		if command == "exit" || strings.HasPrefix(command, "exit ") {
+			if uncertain {
+				t.note(file, n, "'%s' in a branch that cannot be evaluated, tracing continues", command)
+				continue
+			}
+			t.command(file, n, command, false)
+			return true
+		}
+		t.command(file, n, command, uncertain)
+	}
+	return false
+}
+
+// Batch (Windows) scripts
+
+func (t *tracer) expandBatch(s string) string {
+	return batchVariableRegex.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.ToUpper(batchVariableRegex.FindStringSubmatch(m)[1])
+		if value, ok := t.vars[name]; ok {
+			return value
+		}
+		return m
+	})
+}
+
+func batchUnresolved(s string) bool {
+	return strings.Contains(s, "%")
+}
+
+// evalBatchCondition evaluates the condition after 'if [/i] [not]' and returns the
+// command it guards: exist, defined and == comparisons are supported
+func (t *tracer) evalBatchCondition(caseInsensitive bool, condition string) (result bool, known bool, command string) {
+	if m := batchTestRegex.FindStringSubmatch(condition); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "exist":
+			result, known = t.exists(unquote(m[2]), batchUnresolved, false)
+			return result, known, m[3]
+		case "defined":
+			_, defined := t.vars[strings.ToUpper(m[2])]
+			return true, defined, m[3] // variables not set by the script may come from the environment
+		default: // errorlevel
+			return false, false, m[3]
+		}
+	}
+	if m := batchCompareRegex.FindStringSubmatch(condition); m != nil {
+		if batchUnresolved(m[1]) || batchUnresolved(m[2]) {
+			return false, false, m[3]
+		}
+		left, right := unquote(m[1]), unquote(m[2])
+		if caseInsensitive {
+			return strings.EqualFold(left, right), true, m[3]
+		}
+		return left == right, true, m[3]
+	}
+	return false, false, ""
+}
+
+// traceBatch traces a batch script, returns true when the trace stops (exit)
+func (t *tracer) traceBatch(file string) bool {
+	lines, err := t.readLines(file)
+	if err != nil {
+		t.note(file, 0, "'%s' cannot be read: %v", file, err)
+		return false
+	}
+	return t.traceBatchLines(file, lines)
+}
+
+func (t *tracer) traceBatchLines(file string, lines []string) bool {
+
+	labels := make(map[string]int)
+	for i, raw := range lines {
+		if m := batchLabelRegex.FindStringSubmatch(strings.TrimSpace(raw)); m != nil {
+			labels[strings.ToLower(m[1])] = i
+		}
+	}
+
+	var frames []branchFrame
+	var returns []int // line indexes to continue at after 'call :label'
+	executed := 0
+	for pc := 0; pc < len(lines); pc++ {
+		executed++
+		if executed > maxTraceSteps {
+			t.note(file, pc+1, "more than %d lines executed, possible endless loop, tracing stopped", maxTraceSteps)
+			return true
+		}
+		n := pc + 1
+		code, _ := stripComment(lines[pc], "windows")
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(code), "@"))
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		// Block structure is tracked also in branches that are not executed
+		if strings.HasPrefix(line, ")") {
+			if len(frames) > 0 {
+				rest := strings.TrimSpace(line[1:])
+				if strings.HasPrefix(strings.ToLower(rest), "else") && strings.HasSuffix(rest, "(") {
+					top := &frames[len(frames)-1]
+					top.active = !top.done && framesActive(frames[:len(frames)-1])
+				} else {
+					frames = frames[:len(frames)-1]
+				}
+			}
+			continue
+		}
+		if !framesActive(frames) {
+			if strings.HasSuffix(line, "(") {
+				frames = append(frames, branchFrame{done: true})
+			}
+			continue
+		}
+		uncertain := framesUncertain(frames)
+
+		action, target := t.batchCommand(file, n, t.expandBatch(line), uncertain, &frames)
+		switch action {
+		case batchJump:
+			if target == "eof" {
+				if len(returns) == 0 {
+					return false
+				}
+				pc, returns = returns[len(returns)-1], returns[:len(returns)-1]
+				continue
+			}
+			next, ok := labels[strings.ToLower(target)]
+			if !ok {
+				t.note(file, n, "label ':%s' not found, tracing stopped", target)
+				return false
+			}
+			pc = next
+			frames = nil
+		case batchCallLabel:
+			next, ok := labels[strings.ToLower(target)]
+			if !ok {
+				t.note(file, n, "label ':%s' not found", target)
+				continue
+			}
+			returns = append(returns, pc)
+			pc = next
+		case batchExit:
+			return true
+		}
+	}
+	return false
+}
+
+type batchAction int
+
+const (
+	batchContinue  batchAction = iota
+	batchJump                  // goto target; 'eof' returns
+	batchCallLabel             // call :target
+	batchExit                  // exit, stops the whole trace
+)
+
+// batchCommand traces a single batch command and returns the control flow it causes
+func (t *tracer) batchCommand(file string, n int, line string, uncertain bool, frames *[]branchFrame) (batchAction, string) {
+	if m := batchIfRegex.FindStringSubmatch(line); m != nil {
+		result, known, command := t.evalBatchCondition(m[1] != "", m[3])
+		if command == "" {
+			t.note(file, n, "condition '%s' is not supported, the line is not traced", line)
+			return batchContinue, ""
+		}
+		if m[2] != "" {
+			result = !result
+		}
+		if !known {
+			t.note(file, n, "condition '%s' cannot be evaluated, the branch is traced as conditional", strings.TrimSpace(strings.TrimSuffix(line[len("if"):], command)))
+		}
+		command = strings.TrimSpace(command)
+		if command == "(" {
+			*frames = append(*frames, branchFrame{})
+			openBranch(&(*frames)[len(*frames)-1], result, known)
+			return batchContinue, ""
+		}
+		if known && !result {
+			return batchContinue, ""
+		}
+		if strings.HasPrefix(command, "(") && strings.HasSuffix(command, ")") {
+			command = strings.TrimSpace(command[1 : len(command)-1])
+		}
+		return t.batchCommand(file, n, command, uncertain || !known, frames)
+	}
+
+	if strings.HasSuffix(line, "(") {
+		// for loops and other blocks: the body is traced once
+		*frames = append(*frames, branchFrame{active: true})
+		t.command(file, n, line, uncertain)
+		return batchContinue, ""
+	}
+
+	if m := batchAssignmentRegex.FindStringSubmatch(line); m != nil {
+		t.setVariable(strings.ToUpper(m[2]), m[3], !uncertain && m[1] == "" && !batchUnresolved(m[3]))
+		return batchContinue, ""
+	}
+
+	lower := strings.ToLower(line)
+	if m := batchGotoRegex.FindStringSubmatch(line); m != nil {
+		if uncertain {
+			t.note(file, n, "'%s' in a branch that cannot be evaluated, jump not followed", line)
+			return batchContinue, ""
+		}
+		return batchJump, m[1]
+	}
+	if lower == "exit" || strings.HasPrefix(lower, "exit ") {
+		if uncertain {
+			t.note(file, n, "'%s' in a branch that cannot be evaluated, tracing continues", line)
+			return batchContinue, ""
+		}
+		t.command(file, n, line, false)
+		if strings.HasPrefix(lower, "exit /b") {
+			return batchJump, "eof"
+		}
+		return batchExit, ""
+	}
+
+	t.command(file, n, line, uncertain)
+	if m := batchCallRegex.FindStringSubmatch(line); m != nil {
+		if strings.HasPrefix(m[1], ":") {
+			if uncertain {
+				t.note(file, n, "'%s' in a branch that cannot be evaluated, call not followed", line)
+				return batchContinue, ""
+			}
+			return batchCallLabel, strings.TrimPrefix(m[1], ":")
+		}
+		called := unquote(m[1])
+		if !strings.HasSuffix(strings.ToLower(called), ".bat") && !strings.HasSuffix(strings.ToLower(called), ".cmd") {
+			return batchContinue, "" // executable, not a script
+		}
+		if batchUnresolved(called) {
+			t.note(file, n, "'%s' cannot be followed, the path is not resolved", called)
+			return batchContinue, ""
+		}
+		if calledLines, ok := t.enter(file, n, called); ok {
+			t.depth++
+			exited := t.traceBatchLines(called, calledLines)
+			t.depth--
+			if exited {
+				return batchExit, ""
+			}
+		}
+	}
+	return batchContinue, ""
+}
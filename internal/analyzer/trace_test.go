@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTraceTest writes the given files below a temporary source code root
+func setupTraceTest(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func traceLines(t *testing.T, root, script, targetOS string) []string {
+	t.Helper()
+	params := Parameters{
+		Scripts:        []scriptDefinition{{Filename: script, TargetOS: targetOS}},
+		SourceCodeRoot: root,
+	}
+	steps, err := testRun.trace(params, script)
+	if err != nil {
+		t.Fatalf("Trace() failed: %v", err)
+	}
+	var lines []string
+	for _, s := range steps {
+		lines = append(lines, s.String())
+	}
+	return lines
+}
+
+func assertTrace(t *testing.T, got []string, expected []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Trace:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+// What: Shell variables are expanded, sourced scripts followed, exit stops the trace
+func TestTrace_ShellVariablesSourceExit(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.sh": "#!/bin/bash\nCFG=\"100-Config\"\nsource env.sh\nplmxml_import -xml_file=\"${CFG}/a.xml\" -u=$TC_USER\nexit 0\necho never\n",
+		"env.sh":    "export TC_USER=infodba\n",
+	})
+
+	assertTrace(t, traceLines(t, root, "deploy.sh", "linux"), []string{
+		"deploy.sh:3: source env.sh",
+		`deploy.sh:4: plmxml_import -xml_file="100-Config/a.xml" -u=infodba`,
+		"deploy.sh:5: exit 0",
+	})
+}
+
+// What: Shell file tests and string comparisons choose the branch
+func TestTrace_ShellIfElse(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.sh":        "MODE=full\nif [ -f 100-Config/a.xml ]; then\n  echo found\nelse\n  echo missing\nfi\nif [ \"$MODE\" != \"full\" ]\nthen\n  echo partial\nelif [ \"$MODE\" = \"full\" ]; then\n  echo complete\nfi\n",
+		"100-Config/a.xml": "<xml/>",
+	})
+
+	assertTrace(t, traceLines(t, root, "deploy.sh", "linux"), []string{
+		"deploy.sh:3: echo found",
+		"deploy.sh:11: echo complete",
+	})
+}
+
+// What: Conditions with unknown variables are noted, both branches traced as conditional
+func TestTrace_ShellUnevaluatedCondition(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.sh": "if [ \"$TARGET\" = \"prod\" ]; then\n  echo prod\n  exit 1\nelse\n  echo test\nfi\necho done\n",
+	})
+
+	assertTrace(t, traceLines(t, root, "deploy.sh", "linux"), []string{
+		`deploy.sh:1: note: condition '[ "$TARGET" = "prod" ]' cannot be evaluated, the branch is traced as conditional`,
+		"deploy.sh:2: [?] echo prod",
+		"deploy.sh:3: note: 'exit 1' in a branch that cannot be evaluated, tracing continues",
+		"deploy.sh:5: [?] echo test",
+		"deploy.sh:7: echo done",
+	})
+}
+
+// What: Batch set/%VAR%, if exist blocks, goto and call :label are honored
+func TestTrace_BatchControlFlow(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.bat": "@echo off\r\nrem deploy\r\nset \"CFG=100-Config\"\r\nif not exist %CFG%\\a.xml (\r\n  echo missing\r\n) else (\r\n  plmxml_import -xml_file=\"%CFG%\\a.xml\"\r\n)\r\ngoto main\r\necho skipped\r\n:main\r\ncall :sub one\r\n" +
+			"if /i \"%CFG%\"==\"100-config\" echo same\r\ngoto :eof\r\n:sub\r\necho sub\r\nexit /b 0\r\n",
+		"100-Config/a.xml": "<xml/>",
+	})
+
+	assertTrace(t, traceLines(t, root, "deploy.bat", "windows"), []string{
+		"deploy.bat:1: echo off",
+		`deploy.bat:7: plmxml_import -xml_file="100-Config\a.xml"`,
+		"deploy.bat:12: call :sub one",
+		"deploy.bat:16: echo sub",
+		"deploy.bat:17: exit /b 0",
+		"deploy.bat:13: echo same",
+	})
+}
+
+// What: Called batch scripts are followed, unevaluated conditions do not jump
+func TestTrace_BatchCallAndUnevaluatedGoto(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.bat": "if \"%MODE%\"==\"full\" goto full\r\ncall helper.bat\r\ncall missing.bat\r\n:full\r\necho full\r\n",
+		"helper.bat": "echo helper\r\nexit /b\r\necho never\r\n",
+	})
+
+	got := traceLines(t, root, "deploy.bat", "windows")
+	assertTrace(t, got[:5], []string{
+		`deploy.bat:1: note: condition '"%MODE%"=="full"' cannot be evaluated, the branch is traced as conditional`,
+		"deploy.bat:1: note: 'goto full' in a branch that cannot be evaluated, jump not followed",
+		"deploy.bat:2: call helper.bat",
+		"helper.bat:1: echo helper",
+		"helper.bat:2: exit /b",
+	})
+	if !strings.HasPrefix(got[6], "deploy.bat:3: note: 'missing.bat' cannot be followed") {
+		t.Errorf("Expected note for missing called script, got %q", got[6])
+	}
+	if got[len(got)-1] != "deploy.bat:5: echo full" {
+		t.Errorf("Expected trace to continue after the label, got %q", got[len(got)-1])
+	}
+}
+
+// What: Endless goto loops are stopped
+func TestTrace_BatchEndlessLoop(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.bat": ":again\r\ngoto again\r\n",
+	})
+
+	got := traceLines(t, root, "deploy.bat", "windows")
+	if len(got) != 1 || !strings.Contains(got[0], "possible endless loop") {
+		t.Errorf("Expected endless loop note, got %v", got)
+	}
+}
+
+// What: Recursive sourcing is limited
+func TestTrace_ShellRecursiveSource(t *testing.T) {
+	root := setupTraceTest(t, map[string]string{
+		"deploy.sh": ". deploy.sh\n",
+	})
+
+	got := traceLines(t, root, "deploy.sh", "linux")
+	if !strings.Contains(got[len(got)-1], "nesting deeper than") {
+		t.Errorf("Expected nesting note, got %v", got[len(got)-1])
+	}
+}
+
+// What: Scripts that are not configured cannot be traced
+func TestTrace_NotConfigured(t *testing.T) {
+	if _, err := testRun.trace(Parameters{}, "deploy.sh"); err == nil {
+		t.Error("Expected error for script that is not configured, got nil")
+	}
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// urlRegex matches a path flag value starting with a URL scheme, e.g. https://
+var urlRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://`)
+
+// isRemoteReference reports whether a path flag value is a URL, downloaded by the
+// script instead of read from the file system
+func isRemoteReference(value string) bool {
+	return urlRegex.MatchString(value)
+}
+
+// urlHasVariables reports whether a URL is built from shell, batch or template
+// variables, whose values are not known to the analysis
+func urlHasVariables(url string) bool {
+	return shellVariableRegex.MatchString(url) || batchVariableRegex.MatchString(url) || templateExpressionRegex.MatchString(url)
+}
+
+// urlReachable checks an http(s) URL with a HEAD request, following redirects. A
+// response below 400 is reachable; one the server does not allow HEAD for, too.
+func (r *run) urlReachable(client *http.Client, url string) error {
+	resp, err := r.doRequest(client, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodHead, url, nil)
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusMethodNotAllowed {
+		return fmt.Errorf("unexpected response '%s'", resp.Status)
+	}
+	return nil
+}
+
+// checkRemoteReferences reports the URLs referenced by the path flags of a script in
+// the REMOTE REFERENCES section and, with 'url_references.check', confirms that the
+// http(s) ones are reachable. URLs built from variables cannot be checked.
+func (r *run) checkRemoteReferences(scriptFile string, lines []PathFlag) {
+	if len(lines) == 0 {
+		return
+	}
+	r.log.Separate("REMOTE REFERENCES")
+
+	client := r.newHTTPClient()
+	for _, f := range lines {
+		i, url, column := f.Line, f.Path, f.Column
+		r.reportFinding(Finding{Rule: RuleRemoteReference, Script: scriptFile, Line: i, Column: column, Path: url},
+			"'{s}' line '{ln}': '{u}' is a remote reference, not checked on the file system", "s", scriptFile, "ln", i, "u", url)
+
+		scheme := strings.ToLower(url[:strings.Index(url, "://")])
+		if !r.urlSettings.Check || (scheme != "http" && scheme != "https") {
+			continue
+		}
+		if urlHasVariables(url) {
+			r.log.Debug("'{s}' line '{ln}': '{u}' is built from variables and cannot be checked", "s", scriptFile, "ln", i, "u", url)
+			continue
+		}
+		if err := r.urlReachable(client, url); err != nil {
+			r.reportFinding(Finding{Rule: RuleRemoteUnreachable, Script: scriptFile, Line: i, Column: column, Path: url},
+				"'{s}' line '{ln}' is invalid: '{u}' is not reachable: {e}", "s", scriptFile, "ln", i, "u", url, "e", err.Error())
+			continue
+		}
+		r.log.Info("'{s}' line '{ln}' is valid: '{u}' is reachable", "s", scriptFile, "ln", i, "u", url)
+	}
+}
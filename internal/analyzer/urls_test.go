@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+)
+
+// Tests for the URLs referenced by the path flags
+
+// newURLServer answers HEAD requests with 200 for the given paths and 404 for the
+// others; /redirect is redirected to the first path
+func newURLServer(t *testing.T, paths ...string) *httptest.Server {
+	t.Helper()
+	existing := make(map[string]bool)
+	for _, p := range paths {
+		existing[p] = true
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" && len(paths) > 0 {
+			http.Redirect(w, r, paths[0], http.StatusFound)
+			return
+		}
+		if r.Method != http.MethodHead || !existing[r.URL.Path] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setupURLTest(t *testing.T, check bool) {
+	t.Helper()
+	originalScript, originalResult, originalSettings, originalNetwork := testRun.currentScript, testRun.analysisResult, testRun.urlSettings, testRun.networkSettings
+	t.Cleanup(func() {
+		testRun.currentScript, testRun.analysisResult, testRun.urlSettings, testRun.networkSettings = originalScript, originalResult, originalSettings, originalNetwork
+	})
+	testRun.currentScript = "deploy.sh"
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+	testRun.urlSettings = urlReferences{Check: check}
+	noRetries := 0
+	testRun.networkSettings = networkPolicy{Retries: &noRetries}
+}
+
+func TestIsRemoteReference(t *testing.T) {
+	// What: Values starting with a URL scheme are remote references, paths are not
+	tests := map[string]bool{
+		"https://repo.example.com/a.zip": true,
+		"HTTP://repo/a.zip":              true,
+		"ftp://files/a.xml":              true,
+		"s3+https://bucket/a.zip":        true,
+		"100-Config/a.xml":               false,
+		`C:\deploy\a.xml`:                false,
+		"file:a.xml":                     false,
+		"$BASE_URL/a.zip":                false,
+		"":                               false,
+	}
+	for value, want := range tests {
+		if got := isRemoteReference(value); got != want {
+			t.Errorf("isRemoteReference(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestParseScript_RemoteReference(t *testing.T) {
+	// What: A URL is not validated as a Windows path and is recorded as the remote reference of its line
+	logger.InitLogger(os.DevNull, "error")
+	content := "plmxml_import -xml_file=\"https://repo.example.com/a.xml\"\r\n" +
+		"plmxml_import -input \"http://repo/b.txt\" -xml_file=\"100-Config\\c.xml\"\r\n"
+	lines, findings, err := testRun.parseScript("deploy.bat", content, "windows", parseParameters)
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("Expected no syntax findings for the URLs, got %+v", findings)
+	}
+	if !reflect.DeepEqual(lines[0].Remote, []string{"https://repo.example.com/a.xml"}) || lines[0].Valid != nil || lines[0].Invalid != "" {
+		t.Errorf("Expected line 1 remote only, got %+v", lines[0])
+	}
+	if !reflect.DeepEqual(lines[1].Remote, []string{"http://repo/b.txt"}) || !reflect.DeepEqual(lines[1].Valid, []string{`100-Config\c.xml`}) {
+		t.Errorf("Expected line 2 remote and valid, got %+v", lines[1])
+	}
+}
+
+func TestCheckRemoteReferences(t *testing.T) {
+	// What: Without the check the URLs are only reported as remote references
+	setupURLTest(t, false)
+	testRun.analysisResult.File["deploy.sh"].Flags[3] = []PathFlag{{Line: 3, Flag: "file", Path: "https://repo.invalid/a.zip", Column: 15, Remote: true}}
+
+	testRun.checkRemoteReferences("deploy.sh", testRun.analysisResult.File["deploy.sh"].RemoteFlags())
+
+	findings := testRun.analysisResult.Findings
+	if len(findings) != 1 || findings[0].Rule != RuleRemoteReference || findings[0].Line != 3 || findings[0].Column != 15 {
+		t.Errorf("Expected one %s finding on line 3 column 15, got %+v", RuleRemoteReference, findings)
+	}
+}
+
+func TestCheckRemoteReferences_Reachability(t *testing.T) {
+	// What: With the check, unreachable http(s) URLs are reported; variables and other schemes are not requested
+	server := newURLServer(t, "/a.zip")
+	setupURLTest(t, true)
+
+	testRun.checkRemoteReferences("deploy.sh", pathFlags(map[int]string{
+		1: server.URL + "/a.zip",
+		2: server.URL + "/b.zip",
+		3: server.URL + "/redirect",
+		4: server.URL + "/$VERSION/a.zip",
+		5: "ftp://files.invalid/a.xml",
+		6: "http://127.0.0.1:1/a.zip",
+	}))
+
+	var unreachable []int
+	for _, f := range testRun.analysisResult.Findings {
+		if f.Rule == RuleRemoteUnreachable {
+			unreachable = append(unreachable, f.Line)
+		}
+	}
+	if len(unreachable) != 2 || unreachable[0] != 2 || unreachable[1] != 6 {
+		t.Errorf("Expected lines 2 and 6 unreachable, got %v in %+v", unreachable, testRun.analysisResult.Findings)
+	}
+}
+
+func TestUrlHasVariables(t *testing.T) {
+	// What: Shell, batch and template variables make a URL unknown, percent-encoding does not
+	tests := map[string]bool{
+		"https://repo/${VERSION}/a.zip":   true,
+		"https://repo/$VERSION/a.zip":     true,
+		"https://repo/%VERSION%/a.zip":    true,
+		"https://repo/{{ .Version }}/a":   true,
+		"https://repo/a%20b.zip":          false,
+		"https://repo/packages/1.2/a.zip": false,
+	}
+	for url, want := range tests {
+		if got := urlHasVariables(url); got != want {
+			t.Errorf("urlHasVariables(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// cd DIR, cd /d DIR (batch), pushd DIR and popd, up to the end of the command
+	changeDirRegex = regexp.MustCompile(`(?i)^(cd|chdir|pushd|popd)\b(?:\s+/d\s)?\s*([^;&|]*)`)
+	// $(dirname "$0"), %~dp0 and similar: the directory of the script itself
+	scriptDirRegex = regexp.MustCompile(`(?i)^(?:"?\$\(dirname\s+"?\$(?:0|\{0\}|\{BASH_SOURCE\[0\]\}|BASH_SOURCE)"?\)"?|"?%~dp0"?)$`)
+)
+
+// Deepest working directory followed, in segments; deeper ones exceed the path length
+// of the file systems and are treated like directories that cannot be resolved
+const maxWorkingDirDepth = 128
+
+// dirTracker follows cd, pushd and popd commands of a script. After a change to a
+// directory that cannot be resolved (a variable or an absolute path), relative paths
+// are resolved against the source code root again.
+type dirTracker struct {
+	run       *run
+	targetOS  string
+	scriptDir []string // directory of the script, relative to the source code root
+	dir       []string
+	stack     [][]string // directories saved by pushd
+}
+
+// newDirTracker returns a tracker starting in workingDir, relative to the source
+// code root (the root itself when empty)
+func (r *run) newDirTracker(targetOS, scriptFile, workingDir string) *dirTracker {
+	scriptDir := parsePath(scriptFile, hostOS).segments
+	if len(scriptDir) > 0 {
+		scriptDir = scriptDir[:len(scriptDir)-1]
+	}
+	t := &dirTracker{run: r, targetOS: targetOS, scriptDir: scriptDir}
+	t.dir = t.resolve(workingDir)
+	return t
+}
+
+// update processes the next line and returns the directory the line runs in
+func (t *dirTracker) update(line string) []string {
+	command := strings.TrimPrefix(strings.TrimSpace(line), "@")
+	m := changeDirRegex.FindStringSubmatch(command)
+	if m == nil {
+		return t.dir
+	}
+	target := strings.TrimSpace(m[2])
+	switch strings.ToLower(m[1]) {
+	case "popd":
+		if len(t.stack) > 0 {
+			t.dir, t.stack = t.stack[len(t.stack)-1], t.stack[:len(t.stack)-1]
+		}
+	case "pushd":
+		t.stack = append(t.stack, t.dir)
+		t.dir = t.resolve(target)
+	default:
+		if target == "" || target == "-" {
+			// cd without argument prints (batch) or goes home (shell), cd - goes back
+			if t.targetOS == "linux" {
+				t.dir = nil
+			}
+			break
+		}
+		t.dir = t.resolve(target)
+	}
+	t.run.log.Debug("working directory is now '{d}'", "d", t.render(t.dir))
+	return t.dir
+}
+
+// resolve returns the directory a cd to target ends up in
+func (t *dirTracker) resolve(target string) []string {
+	if scriptDirRegex.MatchString(target) {
+		return t.scriptDir
+	}
+	target = unquote(target)
+	if target == "" {
+		return t.dir
+	}
+	p := parsePath(target, t.targetOS)
+	if p.volume != "" || p.rooted || strings.ContainsAny(target, "$%~") {
+		t.run.log.Debug("cannot resolve working directory '{d}', paths are resolved against the source code root", "d", target)
+		return nil
+	}
+	dir := joinSegments(t.dir, p.segments)
+	if len(dir) > maxWorkingDirDepth {
+		t.run.log.Debug("working directory deeper than {n} directories, paths are resolved against the source code root", "n", maxWorkingDirDepth)
+		return nil
+	}
+	return dir
+}
+
+func (t *dirTracker) render(dir []string) string {
+	return scriptPath{segments: dir}.render(t.targetOS)
+}
+
+// joinSegments appends segments to dir, resolving '.' and '..'. A '..' above the
+// source code root is kept, paths escaping it are reported by checkPathEscapes.
+func joinSegments(dir, segments []string) []string {
+	joined := append([]string(nil), dir...)
+	for _, segment := range segments {
+		switch {
+		case segment == ".":
+		case segment == ".." && len(joined) > 0 && joined[len(joined)-1] != "..":
+			joined = joined[:len(joined)-1]
+		default:
+			joined = append(joined, segment)
+		}
+	}
+	return joined
+}
+
+// resolveWorkingDir returns a path referenced by a line relative to the source code
+// root: relative paths are resolved against the working directory of the line,
+// absolute paths and paths starting with a variable are returned as is
+func (r *run) resolveWorkingDir(filePath string) string {
+	if len(r.activeWorkingDir) == 0 || filePath == "" || strings.ContainsAny(filePath[:1], "$%") {
+		return filePath
+	}
+	p := parsePath(filePath, r.currentScriptTargetOS)
+	if p.volume != "" || p.rooted {
+		return filePath
+	}
+	p.segments = joinSegments(r.activeWorkingDir, p.segments)
+	return p.render(r.currentScriptTargetOS)
+}
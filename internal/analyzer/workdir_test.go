@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// What: cd, pushd and popd change the directory relative paths are resolved against
+func TestDirTracker_Update(t *testing.T) {
+	tests := []struct {
+		name     string
+		targetOS string
+		lines    []string
+		want     []string
+	}{
+		{"cd into folder", "linux", []string{"cd 100-Config"}, []string{"100-Config"}},
+		{"cd with chained command", "linux", []string{"cd 100-Config && plmxml_import -xml_file=\"a.xml\""}, []string{"100-Config"}},
+		{"cd up", "linux", []string{"cd 100-Config/sub", "cd .."}, []string{"100-Config"}},
+		{"batch cd /d", "windows", []string{`@cd /d "100-Config\sub"`}, []string{"100-Config", "sub"}},
+		{"pushd and popd", "windows", []string{"cd 100-Config", "pushd 200-Data", "popd"}, []string{"100-Config"}},
+		{"cd to the script directory", "linux", []string{"cd 100-Config", `cd "$(dirname "$0")"`}, []string{"scripts"}},
+		{"batch cd to the script directory", "windows", []string{"cd 100-Config", "cd /d %~dp0"}, []string{"scripts"}},
+		{"unresolvable directory", "linux", []string{"cd 100-Config", "cd $TC_DATA"}, nil},
+		{"absolute directory", "linux", []string{"cd 100-Config", "cd /opt/tc"}, nil},
+		{"batch cd without argument", "windows", []string{"cd 100-Config", "cd"}, []string{"100-Config"}},
+		{"shell cd without argument", "linux", []string{"cd 100-Config", "cd"}, nil},
+		{"other command", "linux", []string{"cd 100-Config", "echo cd 200-Data"}, []string{"100-Config"}},
+		{"directory too deep", "linux", []string{"cd 100-Config", "cd " + strings.Repeat("a/", maxWorkingDirDepth)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := testRun.newDirTracker(tt.targetOS, filepath.Join("scripts", "deploy"), "")
+			var got []string
+			for _, line := range tt.lines {
+				got = tracker.update(line)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected working directory %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// What: The configured working directory is the starting directory of the script
+func TestNewDirTracker_WorkingDir(t *testing.T) {
+	tracker := testRun.newDirTracker("windows", "deploy.bat", `100-Config\sub`)
+	if got := tracker.update("echo start"); !reflect.DeepEqual(got, []string{"100-Config", "sub"}) {
+		t.Errorf("Expected working directory [100-Config sub], got %v", got)
+	}
+}
+
+// What: Relative paths are resolved against the working directory, absolute and variable paths are kept
+func TestResolveWorkingDir(t *testing.T) {
+	originalOS, originalDir := testRun.currentScriptTargetOS, testRun.activeWorkingDir
+	defer func() { testRun.currentScriptTargetOS, testRun.activeWorkingDir = originalOS, originalDir }()
+	testRun.currentScriptTargetOS, testRun.activeWorkingDir = "windows", []string{"100-Config"}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a.xml", `100-Config\a.xml`},
+		{`..\200-Data\b.xml`, `200-Data\b.xml`},
+		{`C:\Temp\a.xml`, `C:\Temp\a.xml`},
+		{`\\server\share\a.xml`, `\\server\share\a.xml`},
+		{`%TC_DATA%\a.xml`, `%TC_DATA%\a.xml`},
+	}
+	for _, tt := range tests {
+		if got := testRun.resolveWorkingDir(tt.path); got != tt.want {
+			t.Errorf("resolveWorkingDir(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	testRun.activeWorkingDir = nil
+	if got := testRun.resolveWorkingDir("a.xml"); got != "a.xml" {
+		t.Errorf("Expected path unchanged in the source code root, got %q", got)
+	}
+}
+
+// What: The syntax check records paths relative to the source code root after a cd
+func TestCheckFileSyntax_WorkingDir(t *testing.T) {
+	setupSyntaxTest()
+	root := t.TempDir()
+	content := "cd 100-Config\nplmxml_import -i=\"a.xml\"\ncd ..\nplmxml_import -i=\"200-Data/b.xml\"\n"
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	testRun.sourceCodeRoot = root
+	testRun.analysisResult = Result{File: map[string]Lines{"deploy.sh": newLines()}}
+
+	testRun.checkFileSyntax("deploy.sh", root, "linux")
+
+	valid := validPaths(testRun.analysisResult.File["deploy.sh"])
+	if valid[2] != "100-Config/a.xml" || valid[4] != "200-Data/b.xml" {
+		t.Errorf("Expected paths resolved from the working directory, got %v", valid)
+	}
+	if testRun.activeWorkingDir != nil {
+		t.Errorf("Expected working directory reset after the syntax check, got %v", testRun.activeWorkingDir)
+	}
+}
+
+// What: A working_dir that is not a directory fails the script check
+func TestCheckScripts_WorkingDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte("echo\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "100-Config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts := []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux", WorkingDir: "100-Config"}}
+	if err := testRun.checkScripts(scripts, root, false); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	scripts[0].WorkingDir = "missing"
+	if err := testRun.checkScripts(scripts, root, false); err == nil || !strings.Contains(err.Error(), "working_dir") {
+		t.Errorf("Expected working_dir error, got: %v", err)
+	}
+}
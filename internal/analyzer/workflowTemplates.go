@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// $TC_BIN/plmxml_import -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba \
+// -xml_file="130-Workflow_Templates/release_process.xml" -transfermode=workflow_template_overwrite
+
+// localizeFolder converts a folder from the configuration, written with either
+// separator, to the runtime OS notation without trailing separators, so it can be
+// used as a path prefix.
+func localizeFolder(folder string) string {
+	return parsePath(folder, "windows").render(hostOS)
+}
+
+// workflowReferences returns the plmxml_import references located under the workflows
+// folder, in script line order. Paths are localized to the runtime OS and made relative
+// to the workflows folder.
+func (r *run) workflowReferences(xmlImports map[int]XMLImport, folder string) []PathFlag {
+	prefix := folder + string(filepath.Separator)
+
+	var references []PathFlag
+	for lineNumber, xmlImport := range xmlImports {
+		if xmlImport.Utility != "plmxml_import" {
+			continue
+		}
+		localized := r.localPath(xmlImport.Path)
+		if strings.HasPrefix(localized, prefix) {
+			references = append(references, PathFlag{Line: lineNumber, Path: strings.TrimPrefix(localized, prefix), Column: xmlImport.Column})
+		}
+	}
+	sort.Slice(references, func(i, j int) bool { return references[i].Line < references[j].Line })
+	return references
+}
+
+// checkWorkflowTemplates mirrors the stylesheet folder check for workflow templates:
+// every workflow PLMXML referenced by a plmxml_import line must exist, and every
+// file in 'workflows_folder' must be referenced by a plmxml_import line.
+func (r *run) checkWorkflowTemplates(scriptFile string, xmlImports map[int]XMLImport) {
+	if r.workflowsFolder == "" {
+		r.log.Debug("no 'workflows_folder' configured, skipping workflow templates check")
+		return
+	}
+
+	folder := localizeFolder(r.workflowsFolder)
+	r.log.Debug("checking workflow templates in '{f}' for '{s}'", "f", folder, "s", scriptFile)
+
+	references := r.workflowReferences(xmlImports, folder)
+
+	// Existence check uses paths relative to the source code root
+	existencePaths := make([]PathFlag, len(references))
+	for i, reference := range references {
+		reference.Path = filepath.Join(folder, reference.Path)
+		existencePaths[i] = reference
+	}
+	r.checkFilePathsInScript(scriptFile, existencePaths)
+
+	r.log.Debug("Comparison if all repositry files in '{f}' are referenced in '{s}'", "f", folder, "s", scriptFile)
+	workflowsLocation := filepath.Join(r.sourceCodeRoot, folder)
+	if err := r.compareFilesWithScripts(scriptFile, references, workflowsLocation, r.ignores.WorkflowsFolder); err != nil {
+		r.log.Error("Error comparing workflow templates for '{s}': {e}", "s", scriptFile, "e", err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests for the workflow templates folder check
+
+func TestLocalizeFolder(t *testing.T) {
+	// What: Both separators are converted and trailing separators removed
+	sep := string(filepath.Separator)
+	result := localizeFolder(`130-Workflows\sub/`)
+	expected := "130-Workflows" + sep + "sub"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestWorkflowReferences_OnlyPLMXMLUnderFolder(t *testing.T) {
+	// What: Only plmxml_import paths inside the folder are returned, relative to it
+	originalOS := testRun.currentScriptTargetOS
+	testRun.currentScriptTargetOS = "windows"
+	defer func() { testRun.currentScriptTargetOS = originalOS }()
+
+	xmlImports := map[int]XMLImport{
+		1: {Utility: "plmxml_import", Path: `130-Workflows\release.xml`},
+		2: {Utility: "tcxml_import", Path: `130-Workflows\data.xml`},
+		3: {Utility: "plmxml_import", Path: `085-Dynamic_LOV\lov.xml`},
+	}
+
+	references := testRun.workflowReferences(xmlImports, localizeFolder("130-Workflows"))
+
+	if len(references) != 1 {
+		t.Fatalf("Expected 1 workflow reference, got %d: %v", len(references), references)
+	}
+	if references[0] != (PathFlag{Line: 1, Path: "release.xml"}) {
+		t.Errorf("Expected 'release.xml' on line 1, got %+v", references[0])
+	}
+}
+
+// runWorkflowsTest runs the analysis of a Linux script importing 130-Workflows/a.xml,
+// with the workflows folder holding a.xml and b.xml
+func runWorkflowsTest(t *testing.T) Result {
+	t.Helper()
+	root := t.TempDir()
+	script := "plmxml_import -xml_file=\"130-Workflows/a.xml\"\n"
+	if err := os.WriteFile(filepath.Join(root, "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "130-Workflows"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"a.xml", "b.xml"} {
+		if err := os.WriteFile(filepath.Join(root, "130-Workflows", file), []byte("<x/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := testRun.analyze(Parameters{
+		SourceCodeRoot:  root,
+		WorkflowsFolder: "130-Workflows",
+		Scripts:         []scriptDefinition{{Filename: "deploy.sh", TargetOS: "linux"}},
+		PathParameters:  []PathParameter{{Name: "xml_file"}},
+		IgnorePatterns:  ignorePatterns{Global: []string{"deploy.sh"}},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return result
+}
+
+// What: A workflow file left unreferenced is reported once, relative to the source code root
+func TestRun_WorkflowsFolderUnreferenced(t *testing.T) {
+	result := runWorkflowsTest(t)
+
+	var unreferenced []Finding
+	for _, f := range result.Findings {
+		if f.Rule == RuleUnreferencedFile {
+			unreferenced = append(unreferenced, f)
+		}
+	}
+	expected := filepath.Join("130-Workflows", "b.xml")
+	if len(unreferenced) != 1 || unreferenced[0].Path != expected {
+		t.Errorf("Expected one %s finding for %s, got %+v", RuleUnreferencedFile, expected, unreferenced)
+	}
+	if lines := result.File["deploy.sh"]; len(lines.Unreferenced) != 1 || lines.Unreferenced[0] != expected {
+		t.Errorf("Expected %s as the only unreferenced file, got %v", expected, lines.Unreferenced)
+	}
+}
+
+// What: The files of the workflows folder are counted once in the coverage, by the traversal of the source code root
+func TestRun_WorkflowsFolderCoverage(t *testing.T) {
+	result := runWorkflowsTest(t)
+
+	coverage := result.File["deploy.sh"].Coverage
+	if got := coverage["130-Workflows"]; got.Present != 2 || got.Referenced != 1 {
+		t.Errorf("Expected 1 of 2 files referenced in 130-Workflows, got %+v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// $TC_BIN/plmxml_import -u=$INSTALL_USER -p=$TC_USER_PASSWD -g=dba \
+// -xml_file="130-Workflow_Templates/release_process.xml"
+//
+// <ExternalFile id="id12" locationRef="attachments/release_process.pdf" format="pdf"/>
+
+// xmlReferenceAttributes lists, per XML element name, the attribute holding a
+// path to a file that has to be shipped together with the imported XML.
+var xmlReferenceAttributes = map[string]string{
+	"ExternalFile": "locationRef", // PLMXML dataset attachments
+	"ImanFile":     "file_name",   // TCXML exported volume files
+}
+
+// extractXMLFileReferences reads an XML document and returns the file references
+// declared by the elements listed in xmlReferenceAttributes, in document order.
+func extractXMLFileReferences(r io.Reader) ([]string, error) {
+	var references []string
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return references, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attributeName, ok := xmlReferenceAttributes[element.Name.Local]
+		if !ok {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if attr.Name.Local == attributeName && strings.TrimSpace(attr.Value) != "" {
+				references = append(references, strings.TrimSpace(attr.Value))
+			}
+		}
+	}
+	return references, nil
+}
+
+// processXMLImportFile opens a single XML referenced by an import line and checks
+// that every attachment it declares exists relative to the XML's own directory.
+//
+// Parameters:
+//   - xmlPath: The XML path as written in the script
+//
+// Returns:
+//   - int: The number of missing attachments
+//   - error: Any error encountered while reading the XML
+func (r *run) processXMLImportFile(xmlPath string) (int, error) {
+	osLocalizedXMLPath := r.localPath(xmlPath)
+	xmlFullPath := r.referenceFilePath(osLocalizedXMLPath)
+
+	file, err := os.Open(xmlFullPath)
+	if err != nil {
+		return 0, fmt.Errorf("error opening %q: %w", xmlFullPath, err)
+	}
+	defer file.Close()
+
+	references, err := extractXMLFileReferences(file)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %q: %w", xmlFullPath, err)
+	}
+	r.log.Info("'{n}' file references found in '{f}'", "n", len(references), "f", osLocalizedXMLPath)
+
+	missing := 0
+	xmlDir := filepath.Dir(xmlFullPath)
+	for _, reference := range references {
+		if strings.Contains(reference, "://") {
+			r.log.Debug("Skipping non-file reference '{r}' in '{f}'", "r", reference, "f", osLocalizedXMLPath)
+			continue
+		}
+		attachmentPath := parsePath(reference, "windows").render(hostOS)
+		if !filepath.IsAbs(attachmentPath) {
+			attachmentPath = filepath.Join(xmlDir, attachmentPath)
+		}
+		if _, err := os.Stat(attachmentPath); err != nil {
+			r.reportFinding(Finding{Rule: RuleMissingAttachment, Script: xmlPath, Path: reference},
+				"'{f}' references '{r}' which is not found on file system", "f", osLocalizedXMLPath, "r", reference)
+			missing++
+		} else {
+			r.log.Info("'{f}' reference '{r}' exists", "f", osLocalizedXMLPath, "r", reference)
+		}
+	}
+	return missing, nil
+}
+
+// checkXMLImportReferences validates the attachments declared in the XMLs passed
+// to plmxml_import / tcxml_import. XMLs that do not exist are skipped here, as they
+// are already reported by the file system references check.
+func (r *run) checkXMLImportReferences(scriptFile string, xmlImports map[int]XMLImport) {
+	r.log.Debug("checking PLMXML/TCXML attachments for '{s}'", "s", scriptFile)
+
+	// sort by line number and check
+	si := make([]int, 0, len(xmlImports))
+	for i := range xmlImports {
+		si = append(si, i)
+	}
+	sort.Ints(si)
+
+	hasErrors := false
+	for _, i := range si {
+		if !r.fileExists(xmlImports[i].Path) {
+			r.log.Debug("'{s}' line '{ln}': skipping attachments check, '{f}' does not exist", "s", scriptFile, "ln", i, "f", xmlImports[i].Path)
+			continue
+		}
+		if r.referencesDirectory(xmlImports[i].Path) {
+			r.log.Debug("'{s}' line '{ln}': skipping attachments check, '{f}' is a directory", "s", scriptFile, "ln", i, "f", xmlImports[i].Path)
+			continue
+		}
+		missing, err := r.processXMLImportFile(xmlImports[i].Path)
+		if err != nil {
+			r.reportFinding(Finding{Rule: RuleXMLUnreadable, Script: scriptFile, Line: i, Path: xmlImports[i].Path},
+				"'{s}' line '{ln}': error processing XML import file: {e}", "s", scriptFile, "ln", i, "e", err.Error())
+			hasErrors = true
+			continue
+		}
+		if missing > 0 {
+			hasErrors = true
+		}
+	}
+
+	if !hasErrors && len(xmlImports) > 0 {
+		r.log.Info("All PLMXML/TCXML attachments exist on the file system")
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests for PLMXML/TCXML attachment validation
+
+func TestExtractXMLFileReferences_PLMXMLAndTCXML(t *testing.T) {
+	// What: ExternalFile@locationRef and ImanFile@file_name are collected in order
+	doc := `<?xml version="1.0"?>
+<PLMXML xmlns="http://www.plmxml.org/Schemas/PLMXMLSchema">
+  <DataSet id="id1" memberRefs="#id2"/>
+  <ExternalFile id="id2" locationRef="attachments/spec.pdf" format="pdf"/>
+  <ImanFile elemId="id3" file_name="volume_file.doc"/>
+  <ExternalFile id="id4" locationRef=""/>
+</PLMXML>`
+
+	refs, err := extractXMLFileReferences(strings.NewReader(doc))
+	assertNoError(t, err)
+
+	if len(refs) != 2 {
+		t.Fatalf("Expected 2 references, got %d: %v", len(refs), refs)
+	}
+	if refs[0] != "attachments/spec.pdf" || refs[1] != "volume_file.doc" {
+		t.Errorf("Unexpected references: %v", refs)
+	}
+}
+
+func TestExtractXMLFileReferences_Malformed(t *testing.T) {
+	// What: Malformed XML returns an error
+	_, err := extractXMLFileReferences(strings.NewReader(`<PLMXML><ExternalFile locationRef="a.pdf">`))
+	if err == nil {
+		t.Error("Expected error for malformed XML, got nil")
+	}
+}
+
+func TestProcessXMLImportFile_MissingAttachment(t *testing.T) {
+	// What: Attachments are resolved relative to the XML directory
+	tmpDir := setupTestDir(t, []string{"130-Workflows/attachments/present.pdf"})
+	defer cleanup(t, tmpDir)
+
+	doc := `<PLMXML>
+  <ExternalFile id="id1" locationRef="attachments/present.pdf"/>
+  <ExternalFile id="id2" locationRef="attachments\missing.pdf"/>
+  <ExternalFile id="id3" locationRef="https://server/file.pdf"/>
+</PLMXML>`
+	if err := os.WriteFile(filepath.Join(tmpDir, "130-Workflows", "process.xml"), []byte(doc), 0644); err != nil {
+		t.Fatalf("Failed to write XML: %v", err)
+	}
+
+	originalRoot := testRun.sourceCodeRoot
+	testRun.sourceCodeRoot = tmpDir
+	defer func() { testRun.sourceCodeRoot = originalRoot }()
+
+	missing, err := testRun.processXMLImportFile("130-Workflows/process.xml")
+	assertNoError(t, err)
+	if missing != 1 {
+		t.Errorf("Expected 1 missing attachment, got %d", missing)
+	}
+}
+
+func TestParseLineAsCommand_RecordsXMLImport(t *testing.T) {
+	// What: plmxml_import lines are recorded for the attachments check
+	setupSyntaxTest()
+	filename := "deploy_linux.sh"
+	initTestFile(filename, "linux")
+
+	testRun.parseLineAsCommand(filename, `$TC_BIN/plmxml_import -i="130-Workflows/process.xml"`, 5)
+	testRun.parseLineAsCommand(filename, `$TC_BIN/other_util -i="130-Workflows/other.xml"`, 6)
+
+	xmlImports := testRun.analysisResult.File[filename].XMLImport
+	if xmlImports[5].Path != "130-Workflows/process.xml" || xmlImports[5].Utility != "plmxml_import" {
+		t.Errorf("Expected line 5 to be recorded as XML import, got %v", xmlImports)
+	}
+	if _, exists := xmlImports[6]; exists {
+		t.Error("Expected line 6 not to be recorded as XML import")
+	}
+}
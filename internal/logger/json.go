@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Names of the logger types in the JSON format; separators and headings are sections
+var levelNames = map[int]string{1: "error", 2: "info", 3: "debug", 4: "section", 5: "section", 6: "warning"}
+
+// Attributes of every JSON log line, placeholders of the same name are left out
+var reservedFields = map[string]bool{"time": true, "level": true, "msg": true}
+
+// SetJSONOutput sets whether log lines are written as JSON objects, one per line:
+//
+//	{"time":"2024-01-02T15:04:05Z","level":"error","msg":"...","line":3,"rule":"TCX010","script":"deploy.sh"}
+//
+// The {key} placeholders of the message and the fields of With are kept as attributes,
+// so log queries can filter on them. Must be called before InitLogger.
+func SetJSONOutput(enabled bool) {
+	std.SetJSONOutput(enabled)
+}
+
+// SetJSONOutput sets whether log lines are written as JSON objects, see the package
+// function
+func (l *Logger) SetJSONOutput(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.json = enabled
+}
+
+// Entry logs messages with structured fields
+type Entry struct {
+	logger *Logger
+	fields []interface{}
+}
+
+// With returns an entry logging with the fields, key, value pairs such as
+// "script", "deploy.sh", "line", 3. Fields are attributes of the JSON format only,
+// empty values are left out.
+func With(fields ...interface{}) Entry {
+	return std.With(fields...)
+}
+
+// With returns an entry logging with the fields, see the package function
+func (l *Logger) With(fields ...interface{}) Entry {
+	return Entry{l, fields}
+}
+
+func (e Entry) write(loggerType int, format string, args ...interface{}) {
+	log_msg := format_string(format, args...)
+	e.logger.mu.Lock()
+	defer e.logger.mu.Unlock()
+	e.logger.write_line(loggerType, log_msg, append(append([]interface{}{}, args...), e.fields...))
+}
+
+// Error logs an error message with the fields of the entry
+func (e Entry) Error(format string, args ...interface{}) {
+	e.write(1, format, args...)
+}
+
+// Warning logs a warning message with the fields of the entry
+func (e Entry) Warning(format string, args ...interface{}) {
+	e.write(6, format, args...)
+}
+
+// Info logs an informational message with the fields of the entry
+func (e Entry) Info(format string, args ...interface{}) {
+	e.write(2, format, args...)
+}
+
+// Debug logs a debug message with the fields of the entry
+func (e Entry) Debug(format string, args ...interface{}) {
+	e.write(3, format, args...)
+}
+
+// write_json writes the message as a JSON object with the time, the level and the
+// fields sorted by key; decoration lines of the text layout (blank or '=====') are
+// left out. The caller holds mu.
+func (l *Logger) write_json(w io.Writer, loggerType int, log_msg string, fields []interface{}) {
+	if w == nil || is_decoration(log_msg) {
+		return
+	}
+	attributes := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i < len(fields)-1; i += 2 {
+		key := fmt.Sprint(fields[i])
+		if reservedFields[key] || isEmptyField(fields[i+1]) {
+			continue
+		}
+		attributes[key] = jsonValue(fields[i+1])
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	writeJSONValue(&b, l.timestamp())
+	b.WriteString(`,"level":`)
+	writeJSONValue(&b, levelNames[loggerType])
+	b.WriteString(`,"msg":`)
+	writeJSONValue(&b, log_msg)
+	for _, key := range keys {
+		b.WriteByte(',')
+		writeJSONValue(&b, key)
+		b.WriteByte(':')
+		writeJSONValue(&b, attributes[key])
+	}
+	b.WriteString("}\n")
+	w.Write(b.Bytes())
+}
+
+// isEmptyField reports whether a field value is left out: empty strings and zeros
+func isEmptyField(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	}
+	return false
+}
+
+// jsonValue keeps numbers and booleans, other values are rendered like in messages
+func jsonValue(value interface{}) interface{} {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return value
+	}
+	return fmt.Sprint(value)
+}
+
+// writeJSONValue writes the JSON encoding of a string, number or boolean
+func writeJSONValue(b *bytes.Buffer, value interface{}) {
+	encoded, _ := json.Marshal(value)
+	b.Write(encoded)
+}
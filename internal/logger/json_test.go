@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// initJSONLogger writes the log as JSON to the returned buffer at the level
+func initJSONLogger(t *testing.T, level string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	SetJSONOutput(true)
+	if err := InitLogger("", level); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	t.Cleanup(func() {
+		SetJSONOutput(false)
+		SetConsoleOutput(os.Stdout)
+		InitLogger("", "error")
+	})
+	return &buf
+}
+
+func TestJSONOutput_Fields(t *testing.T) {
+	// What: The placeholders and the fields of With are attributes of the JSON line, empty fields are left out
+	buf := initJSONLogger(t, "error")
+
+	With("rule", "TCX010", "script", "deploy_linux.sh", "line", 3, "path", "").Error("'{fp}' not found", "fp", "a.xml")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Invalid JSON line %q: %v", buf.String(), err)
+	}
+	if line["level"] != "error" || line["msg"] != "'a.xml' not found" || line["rule"] != "TCX010" ||
+		line["script"] != "deploy_linux.sh" || line["line"] != float64(3) || line["fp"] != "a.xml" {
+		t.Errorf("Unexpected JSON line %v", line)
+	}
+	if _, ok := line["path"]; ok {
+		t.Error("Expected the empty path to be left out")
+	}
+	if _, ok := line["time"]; !ok {
+		t.Error("Expected the time")
+	}
+}
+
+func TestJSONOutput_LevelsAndDecoration(t *testing.T) {
+	// What: JSON lines keep the log level, decoration lines of the text layout are left out
+	buf := initJSONLogger(t, "info")
+
+	Separate("=====================================")
+	Heading(" ")
+	Separate("SCRIPT SYNTAX CHECK")
+	Info("info {n}", "n", 1)
+	Debug("hidden")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"level":"section","msg":"SCRIPT SYNTAX CHECK"`) ||
+		!strings.Contains(lines[1], `"level":"info","msg":"info 1","n":1`) {
+		t.Errorf("Unexpected JSON log %q", buf.String())
+	}
+}
+
+func TestWith_TextOutput(t *testing.T) {
+	// What: The text format logs the message of an entry without its fields
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "error"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	With("rule", "TCX010").Warning("message")
+	if buf.String() != "WARNING: message\n" {
+		t.Errorf("Unexpected text %q", buf.String())
+	}
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// limits keep the log of huge repositories manageable: runs of identical lines are
+// collapsed into "last message repeated N times", and debug lines beyond the rate
+// limit, e.g. one per file traversed, are dropped and counted
+type limits struct {
+	suppressRepeats bool
+	debugRateLimit  int // debug lines per second, 0 is unlimited
+
+	lastType     int
+	lastMessage  string
+	repeatCount  int
+	windowStart  time.Time
+	windowLines  int
+	droppedDebug int
+}
+
+var now = time.Now
+
+// SetRepeatSuppression sets whether runs of identical log lines are collapsed
+func SetRepeatSuppression(enabled bool) {
+	std.SetRepeatSuppression(enabled)
+}
+
+// SetRepeatSuppression sets whether runs of identical log lines are collapsed
+func (l *Logger) SetRepeatSuppression(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flush_pending()
+	l.suppressRepeats = enabled
+}
+
+// SetDebugRateLimit limits the debug lines written per second; 0 writes all
+func SetDebugRateLimit(perSecond int) {
+	std.SetDebugRateLimit(perSecond)
+}
+
+// SetDebugRateLimit limits the debug lines written per second; 0 writes all
+func (l *Logger) SetDebugRateLimit(perSecond int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flush_pending()
+	l.debugRateLimit, l.windowStart, l.windowLines = perSecond, time.Time{}, 0
+}
+
+// is_decoration reports whether the line only shapes the text layout: blank or '====='
+func is_decoration(log_msg string) bool {
+	return strings.Trim(log_msg, " =-") == ""
+}
+
+// line_visible reports whether a line of the logger type is written at the current
+// level; the caller holds mu
+func (l *Logger) line_visible(loggerType int) bool {
+	if scoped, allowed := l.scope_level(loggerType); scoped {
+		return allowed
+	}
+	w, ok := l.levelWriters[loggerType]
+	return !ok || w != io.Discard
+}
+
+// limit_line reports whether the visible line is written: repeats of the previous line
+// are counted, debug lines beyond the rate limit dropped. Writes the pending repeat
+// and drop counts before the line; the caller holds mu.
+func (l *Logger) limit_line(loggerType int, log_msg string) bool {
+	if l.suppressRepeats && !is_decoration(log_msg) && loggerType == l.lastType && log_msg == l.lastMessage {
+		l.repeatCount++
+		return false
+	}
+	if loggerType == 3 && l.debugRateLimit > 0 {
+		if t := now(); t.Sub(l.windowStart) >= time.Second {
+			l.windowStart, l.windowLines = t, 0
+		}
+		if l.windowLines >= l.debugRateLimit {
+			l.droppedDebug++
+			return false
+		}
+		l.windowLines++
+	}
+	l.flush_pending()
+	if l.suppressRepeats && !is_decoration(log_msg) {
+		l.lastType, l.lastMessage = loggerType, log_msg
+	}
+	return true
+}
+
+// flush_pending writes the repeat count of the previous line and the count of the
+// dropped debug lines, and forgets them; the caller holds mu
+func (l *Logger) flush_pending() {
+	if l.repeatCount > 0 {
+		l.emit_line(l.lastType, format_string("last message repeated {n} times", "n", l.repeatCount), nil)
+	}
+	if l.droppedDebug > 0 {
+		l.emit_line(3, format_string("{n} debug lines dropped by the rate limit of {l} per second", "n", l.droppedDebug, "l", l.debugRateLimit), nil)
+	}
+	l.lastType, l.lastMessage, l.repeatCount, l.droppedDebug = 0, "", 0, 0
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetRepeatSuppression_CollapsesRuns(t *testing.T) {
+	// What: A run of identical lines is written once followed by its repeat count
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+	SetRepeatSuppression(true)
+	defer SetRepeatSuppression(false)
+
+	for i := 0; i < 4; i++ {
+		Info("skipping '{f}'", "f", "lib.so")
+	}
+	Separate(" ")
+	Separate(" ")
+	Info("done")
+	Debug("hidden")
+	Debug("hidden")
+
+	expected := "INFO: skipping 'lib.so'\nINFO: last message repeated 3 times\n \n \nINFO: done\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSetRepeatSuppression_FlushedOnDisable(t *testing.T) {
+	// What: The pending repeat count is written when the suppression ends
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	SetRepeatSuppression(true)
+	Error("failed")
+	Error("failed")
+	SetRepeatSuppression(false)
+
+	expected := "ERROR: failed\nERROR: last message repeated 1 times\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSetDebugRateLimit_DropsBeyondLimit(t *testing.T) {
+	// What: Debug lines beyond the limit per second are dropped and counted
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "debug"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	clock := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+	SetDebugRateLimit(2)
+	defer SetDebugRateLimit(0)
+
+	for i := 1; i <= 5; i++ {
+		Debug("file {i}", "i", i)
+	}
+	Info("still written")
+	clock = clock.Add(time.Second)
+	Debug("file {i}", "i", 6)
+
+	output := buf.String()
+	for _, line := range []string{"DEBUG: file 1\n", "DEBUG: file 2\n", "DEBUG: 3 debug lines dropped by the rate limit of 2 per second\nINFO: still written\n", "DEBUG: file 6\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in %q", line, output)
+		}
+	}
+	if strings.Contains(output, "file 3") {
+		t.Errorf("Expected the lines beyond the limit to be dropped, got %q", output)
+	}
+}
@@ -6,31 +6,104 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
-var (
-	InfoLogger      *log.Logger
-	DebugLogger     *log.Logger
-	ErrorLogger     *log.Logger
-	SeparatorLogger *log.Logger
-	HeadingLogger   *log.Logger
-	logFile         *os.File // Store file handle for cleanup
-)
+// Logger writes the log of a validation to the console output and the log file, with
+// the level, the format and the limits it is configured with. The package functions
+// log with the default logger of InitLogger; a validation with a log of its own, e.g.
+// a request of the serve subcommand, logs with a Logger of New. A Logger is safe for
+// concurrent use.
+type Logger struct {
+	// mu serializes the writes of log lines and sections, so lines logged
+	// concurrently are not interleaved within a section
+	mu sync.Mutex
+
+	infoLog      *log.Logger
+	debugLog     *log.Logger
+	errorLog     *log.Logger
+	warningLog   *log.Logger
+	separatorLog *log.Logger
+	headingLog   *log.Logger
+	file         *os.File // Store file handle for cleanup
+	console      io.Writer
+
+	json         bool              // see SetJSONOutput
+	levelWriters map[int]io.Writer // writers of the logger types for the level of init
+
+	timestampLayout   string
+	timestampLocation *time.Location
+
+	scopeLevels map[string]string // see SetScopeLevels
+	scope       string
+	scopedInfo  *log.Logger
+	scopedDebug *log.Logger
+
+	limits
+}
+
+// std is the logger of the package functions
+var std = newLogger(os.Stdout)
+
+func newLogger(console io.Writer) *Logger {
+	return &Logger{console: console, timestampLayout: time.RFC3339, timestampLocation: time.UTC}
+}
+
+// New returns a logger writing to console and, when logfile is not empty, to the log
+// file, with the level of InitLogger. Close closes the log file.
+func New(console io.Writer, logfile string, logLevel string) (*Logger, error) {
+	l := newLogger(console)
+	if err := l.init(logfile, logLevel); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Default returns the logger of the package functions
+func Default() *Logger {
+	return std
+}
+
+// SetConsoleOutput sets where log messages are written besides the log file and
+// returns the previous writer, to restore it.
+// Must be called before InitLogger; io.Discard keeps the console free for reports.
+func SetConsoleOutput(w io.Writer) io.Writer {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	previous := std.console
+	std.console = w
+	return previous
+}
+
+// ConsoleOutput returns where log messages are written besides the log file, the
+// console of the loggers created with New by the callers of the package functions
+func ConsoleOutput() io.Writer {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return std.console
+}
 
 // InitLogger initializes the logging system with the specified log file and level.
 // logfile: path to the log file (empty string for stdout only)
 // logLevel: "debug", "info", or "error" to control verbosity
 func InitLogger(logfile string, logLevel string) error {
+	return std.init(logfile, logLevel)
+}
+
+func (l *Logger) init(logfile string, logLevel string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	var multi_writer io.Writer
 	if logfile == "" {
-		multi_writer = io.MultiWriter(os.Stdout)
+		multi_writer = io.MultiWriter(l.console)
 	} else {
 		file, err := os.OpenFile(logfile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0755)
 		if err != nil {
 			return fmt.Errorf("failed to open log file: %w", err)
 		}
-		logFile = file // Store for later cleanup
-		multi_writer = io.MultiWriter(os.Stdout, file)
+		l.file = file // Store for later cleanup
+		multi_writer = io.MultiWriter(l.console, file)
 	}
 
 	var debug_writer io.Writer
@@ -47,11 +120,16 @@ func InitLogger(logfile string, logLevel string) error {
 		info_writer = io.Discard
 	}
 
-	InfoLogger = log.New(info_writer, "INFO: ", 0)
-	ErrorLogger = log.New(multi_writer, "ERROR: ", 0)
-	DebugLogger = log.New(debug_writer, "DEBUG: ", 0)
-	SeparatorLogger = log.New(multi_writer, "", 0)
-	HeadingLogger = log.New(multi_writer, "", log.Ldate|log.Ltime)
+	l.levelWriters = map[int]io.Writer{1: multi_writer, 2: info_writer, 3: debug_writer, 4: multi_writer, 5: multi_writer, 6: multi_writer}
+	l.scopedInfo = log.New(multi_writer, "INFO: ", 0)
+	l.scopedDebug = log.New(multi_writer, "DEBUG: ", 0)
+
+	l.infoLog = log.New(info_writer, "INFO: ", 0)
+	l.errorLog = log.New(multi_writer, "ERROR: ", 0)
+	l.warningLog = log.New(multi_writer, "WARNING: ", 0)
+	l.debugLog = log.New(debug_writer, "DEBUG: ", 0)
+	l.separatorLog = log.New(multi_writer, "", 0)
+	l.headingLog = log.New(multi_writer, "", 0) // timestamped in the layout of SetTimestamps
 
 	return nil
 }
@@ -59,8 +137,16 @@ func InitLogger(logfile string, logLevel string) error {
 // Close closes the log file if one was opened.
 // Should be called with defer in main to ensure cleanup.
 func Close() error {
-	if logFile != nil {
-		return logFile.Close()
+	return std.Close()
+}
+
+// Close writes the pending repeat counts and closes the log file if one was opened
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	l.flush_pending()
+	l.mu.Unlock()
+	if l.file != nil {
+		return l.file.Close()
 	}
 	return nil
 }
@@ -88,46 +174,127 @@ func format_string(format string, args ...interface{}) string {
 	return result
 }
 
-func write_to_log(loggerType int, format string, args ...interface{}) {
+func (l *Logger) write_to_log(loggerType int, format string, args ...interface{}) {
 	log_msg := format_string(format, args...)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write_line(loggerType, log_msg, args)
+}
+
+// write_line writes a formatted message unless it repeats the previous one or exceeds
+// the debug rate limit (see SetRepeatSuppression, SetDebugRateLimit); the caller holds mu
+func (l *Logger) write_line(loggerType int, log_msg string, fields []interface{}) {
+	if (l.suppressRepeats || l.debugRateLimit > 0) && l.line_visible(loggerType) && !l.limit_line(loggerType, log_msg) {
+		return
+	}
+	l.emit_line(loggerType, log_msg, fields)
+}
+
+// emit_line writes a formatted message with the logger of the type, or as a JSON
+// object with the fields (key, value pairs) in the JSON format; the caller holds mu
+func (l *Logger) emit_line(loggerType int, log_msg string, fields []interface{}) {
+	scoped, allowed := l.scope_level(loggerType)
+	if l.json {
+		w := l.levelWriters[loggerType]
+		if scoped {
+			if !allowed {
+				return
+			}
+			w = l.levelWriters[1]
+		}
+		l.write_json(w, loggerType, log_msg, fields)
+		return
+	}
+	if scoped {
+		switch {
+		case !allowed:
+		case loggerType == 2:
+			l.scopedInfo.Println(log_msg)
+		default:
+			l.scopedDebug.Println(log_msg)
+		}
+		return
+	}
 	switch loggerType {
 	case 1:
-		ErrorLogger.Println(log_msg)
+		l.errorLog.Println(log_msg)
 	case 2:
-		InfoLogger.Println(log_msg)
+		l.infoLog.Println(log_msg)
 	case 3:
 		// below adds caller info to the string to be logged
 		// _, fn, line, _ := runtime.Caller(1)
 		// format = filepath.Base(fn) + ":" + strconv.Itoa(line) + ": " + format
-		DebugLogger.Println(log_msg)
+		l.debugLog.Println(log_msg)
 	case 4:
-		SeparatorLogger.Println(log_msg)
+		l.separatorLog.Println(log_msg)
 	case 5:
-		HeadingLogger.Println(log_msg)
+		l.headingLog.Println(l.timestamp() + " " + log_msg)
+	case 6:
+		l.warningLog.Println(log_msg)
 	}
 }
 
+// Format returns the message with {key} placeholders replaced, without logging it.
+func Format(format string, args ...interface{}) string {
+	return format_string(format, args...)
+}
+
 // Error logs an error message. Always visible regardless of log level.
 func Error(format string, args ...interface{}) {
-	write_to_log(1, format, args...)
+	std.write_to_log(1, format, args...)
+}
+
+// Warning logs a warning message. Always visible regardless of log level.
+func Warning(format string, args ...interface{}) {
+	std.write_to_log(6, format, args...)
 }
 
 // Info logs an informational message. Visible when log level is "info" or "debug".
 func Info(format string, args ...interface{}) {
-	write_to_log(2, format, args...)
+	std.write_to_log(2, format, args...)
 }
 
 // Debug logs a debug message. Only visible when log level is "debug".
 func Debug(format string, args ...interface{}) {
-	write_to_log(3, format, args...)
+	std.write_to_log(3, format, args...)
 }
 
 // Separate logs a separator line without prefix. Always visible.
 func Separate(format string, args ...interface{}) {
-	write_to_log(4, format, args...)
+	std.write_to_log(4, format, args...)
 }
 
 // Heading logs a heading with timestamp. Always visible.
 func Heading(format string, args ...interface{}) {
-	write_to_log(5, format, args...)
+	std.write_to_log(5, format, args...)
+}
+
+// Error logs an error message. Always visible regardless of log level.
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.write_to_log(1, format, args...)
+}
+
+// Warning logs a warning message. Always visible regardless of log level.
+func (l *Logger) Warning(format string, args ...interface{}) {
+	l.write_to_log(6, format, args...)
+}
+
+// Info logs an informational message. Visible when log level is "info" or "debug".
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.write_to_log(2, format, args...)
+}
+
+// Debug logs a debug message. Only visible when log level is "debug".
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.write_to_log(3, format, args...)
+}
+
+// Separate logs a separator line without prefix. Always visible.
+func (l *Logger) Separate(format string, args ...interface{}) {
+	l.write_to_log(4, format, args...)
+}
+
+// Heading logs a heading with timestamp. Always visible.
+func (l *Logger) Heading(format string, args ...interface{}) {
+	l.write_to_log(5, format, args...)
 }
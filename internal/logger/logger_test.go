@@ -61,10 +61,10 @@ func TestFormatString(t *testing.T) {
 
 func TestInitLogger_StdoutOnly(t *testing.T) {
 	// Reset loggers
-	InfoLogger = nil
-	DebugLogger = nil
-	ErrorLogger = nil
-	logFile = nil
+	std.infoLog = nil
+	std.debugLog = nil
+	std.errorLog = nil
+	std.file = nil
 
 	err := InitLogger("", "info")
 	if err != nil {
@@ -72,12 +72,12 @@ func TestInitLogger_StdoutOnly(t *testing.T) {
 	}
 	defer Close()
 
-	if InfoLogger == nil || ErrorLogger == nil || DebugLogger == nil {
+	if std.infoLog == nil || std.errorLog == nil || std.debugLog == nil {
 		t.Error("Loggers were not initialized")
 	}
 
-	if logFile != nil {
-		t.Error("logFile should be nil when no file specified")
+	if std.file != nil {
+		t.Error("std.file should be nil when no file specified")
 	}
 }
 
@@ -87,10 +87,10 @@ func TestInitLogger_WithFile(t *testing.T) {
 	logPath := filepath.Join(tempDir, "test.log")
 
 	// Reset loggers
-	InfoLogger = nil
-	DebugLogger = nil
-	ErrorLogger = nil
-	logFile = nil
+	std.infoLog = nil
+	std.debugLog = nil
+	std.errorLog = nil
+	std.file = nil
 
 	err := InitLogger(logPath, "debug")
 	if err != nil {
@@ -98,8 +98,8 @@ func TestInitLogger_WithFile(t *testing.T) {
 	}
 	defer Close()
 
-	if logFile == nil {
-		t.Error("logFile should not be nil when file specified")
+	if std.file == nil {
+		t.Error("std.file should not be nil when file specified")
 	}
 
 	// Verify file was created
@@ -124,7 +124,7 @@ func TestInitLogger_InvalidPath(t *testing.T) {
 }
 
 func TestClose_NoFile(t *testing.T) {
-	logFile = nil
+	std.file = nil
 	err := Close()
 	if err != nil {
 		t.Errorf("Close() with no file should not error, got: %v", err)
@@ -145,9 +145,9 @@ func TestClose_WithFile(t *testing.T) {
 		t.Errorf("Close() failed: %v", err)
 	}
 
-	// Verify file handle was closed (logFile still points to closed file)
-	if logFile == nil {
-		t.Error("logFile should still reference the file object after Close()")
+	// Verify file handle was closed (std.file still points to closed file)
+	if std.file == nil {
+		t.Error("std.file should still reference the file object after Close()")
 	}
 }
 
@@ -203,16 +203,16 @@ func TestLogLevels(t *testing.T) {
 
 			// Initialize logger with buffer as output
 			if tt.logLevel == "debug" {
-				InfoLogger = log.New(&buf, "INFO: ", 0)
-				DebugLogger = log.New(&buf, "DEBUG: ", 0)
+				std.infoLog = log.New(&buf, "INFO: ", 0)
+				std.debugLog = log.New(&buf, "DEBUG: ", 0)
 			} else if tt.logLevel == "info" {
-				InfoLogger = log.New(&buf, "INFO: ", 0)
-				DebugLogger = log.New(io.Discard, "DEBUG: ", 0)
+				std.infoLog = log.New(&buf, "INFO: ", 0)
+				std.debugLog = log.New(io.Discard, "DEBUG: ", 0)
 			} else {
-				InfoLogger = log.New(io.Discard, "INFO: ", 0)
-				DebugLogger = log.New(io.Discard, "DEBUG: ", 0)
+				std.infoLog = log.New(io.Discard, "INFO: ", 0)
+				std.debugLog = log.New(io.Discard, "DEBUG: ", 0)
 			}
-			ErrorLogger = log.New(&buf, "ERROR: ", 0)
+			std.errorLog = log.New(&buf, "ERROR: ", 0)
 
 			tt.testFunc()
 
@@ -236,7 +236,7 @@ func TestLogLevels(t *testing.T) {
 
 func TestErrorLogging(t *testing.T) {
 	var buf bytes.Buffer
-	ErrorLogger = log.New(&buf, "ERROR: ", 0)
+	std.errorLog = log.New(&buf, "ERROR: ", 0)
 
 	Error("test error {msg}", "msg", "failed")
 
@@ -249,10 +249,28 @@ func TestErrorLogging(t *testing.T) {
 	}
 }
 
+func TestWarningLogging(t *testing.T) {
+	var buf bytes.Buffer
+	std.warningLog = log.New(&buf, "WARNING: ", 0)
+
+	Warning("pattern {p} unused", "p", "*.tmp")
+
+	output := buf.String()
+	if !strings.Contains(output, "WARNING: pattern *.tmp unused") {
+		t.Errorf("Expected formatted warning, got %q", output)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if result := Format("line {ln}", "ln", 7); result != "line 7" {
+		t.Errorf("Format() = %q, want %q", result, "line 7")
+	}
+}
+
 func TestSeparateAndHeading(t *testing.T) {
 	var buf bytes.Buffer
-	SeparatorLogger = log.New(&buf, "", 0)
-	HeadingLogger = log.New(&buf, "", log.Ldate|log.Ltime)
+	std.separatorLog = log.New(&buf, "", 0)
+	std.headingLog = log.New(&buf, "", log.Ldate|log.Ltime)
 
 	Separate("=================")
 	if !strings.Contains(buf.String(), "=================") {
@@ -271,3 +289,49 @@ func TestSeparateAndHeading(t *testing.T) {
 		t.Error("Heading() should include timestamp")
 	}
 }
+
+func TestSetConsoleOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+
+	if err := InitLogger("", "error"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer Close()
+
+	Error("console message")
+	if !strings.Contains(buf.String(), "console message") {
+		t.Errorf("Expected message on console output, got %q", buf.String())
+	}
+	if previous := SetConsoleOutput(&buf); previous != &buf {
+		t.Errorf("Expected the previous console output, got %v", previous)
+	}
+}
+
+func TestNew_Independent(t *testing.T) {
+	// What: Loggers created with New write to their own console with their own settings
+	var first, second bytes.Buffer
+	a, err := New(&first, "", "info")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer a.Close()
+	b, err := New(&second, "", "error")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer b.Close()
+	b.SetJSONOutput(true)
+
+	a.Info("first message")
+	b.Info("hidden message")
+	b.Error("second message")
+
+	if !strings.Contains(first.String(), "INFO: first message") || strings.Contains(first.String(), "second") {
+		t.Errorf("Expected only the text line of the first logger, got %q", first.String())
+	}
+	if !strings.Contains(second.String(), `"msg":"second message"`) || strings.Contains(second.String(), "hidden") {
+		t.Errorf("Expected only the JSON error line of the second logger, got %q", second.String())
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+// SetScopeLevels sets the log levels ("debug", "info" or "error") of scopes, e.g. the
+// analyzer phases, overriding the level of InitLogger for the info and debug lines
+// logged within the scope: debug for the stylesheet processing only keeps the rest of
+// the log readable on a large repository. nil logs all scopes with the level of
+// InitLogger.
+func SetScopeLevels(levels map[string]string) {
+	std.SetScopeLevels(levels)
+}
+
+// SetScopeLevels sets the log levels of the scopes, see the package function
+func (l *Logger) SetScopeLevels(levels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.scopeLevels = levels
+}
+
+// EnterScope makes name the scope of the lines logged until the returned function
+// restores the previous one:
+//
+//	defer logger.EnterScope("stylesheet")()
+func EnterScope(name string) (restore func()) {
+	return std.EnterScope(name)
+}
+
+// EnterScope makes name the scope of the lines logged with l, see the package function
+func (l *Logger) EnterScope(name string) (restore func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	previous := l.scope
+	l.scope = name
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.scope = previous
+	}
+}
+
+// scope_level returns whether the current scope has a level and if so whether it
+// logs lines of the logger type, info (2) or debug (3); the caller holds mu
+func (l *Logger) scope_level(loggerType int) (scoped, allowed bool) {
+	if loggerType != 2 && loggerType != 3 {
+		return false, false
+	}
+	level, ok := l.scopeLevels[l.scope]
+	if !ok || l.scopedInfo == nil {
+		return false, false
+	}
+	return true, level == "debug" || (loggerType == 2 && level == "info")
+}
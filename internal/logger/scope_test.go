@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScopeLevels(t *testing.T) {
+	// What: A scope with a level logs its debug lines while the rest of the log stays at the level of InitLogger
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "error"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+	SetScopeLevels(map[string]string{"stylesheet": "debug", "syntax": "error"})
+	defer SetScopeLevels(nil)
+
+	Debug("before the scope")
+	restore := EnterScope("stylesheet")
+	Debug("stylesheet details")
+	Info("stylesheet info")
+	restore()
+	Info("after the scope")
+
+	output := buf.String()
+	if !strings.Contains(output, "DEBUG: stylesheet details") || !strings.Contains(output, "INFO: stylesheet info") {
+		t.Errorf("Expected the lines of the debug scope, got %q", output)
+	}
+	if strings.Contains(output, "before the scope") || strings.Contains(output, "after the scope") {
+		t.Errorf("Expected the lines outside the scope at error level, got %q", output)
+	}
+}
+
+func TestScopeLevels_QuieterScope(t *testing.T) {
+	// What: A scope at error level drops its info lines even at info level, errors are always logged
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+	SetScopeLevels(map[string]string{"traversal": "error"})
+	defer SetScopeLevels(nil)
+
+	defer EnterScope("traversal")()
+	Info("visited")
+	Error("unreadable")
+	if output := buf.String(); strings.Contains(output, "visited") || !strings.Contains(output, "ERROR: unreadable") {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"sync"
+)
+
+// Section buffers the log lines of one script, so scripts processed in parallel do not
+// interleave their lines. Flush writes the banner and the buffered lines in one piece,
+// in the layout of a sequential run:
+//
+//	2024/01/02 15:04:05
+//	file 'deploy.sh'
+//	=====================================
+//	...
+//
+// The level of each line, that of the scope it was logged in, is applied when
+// flushing. A Section is safe for concurrent use.
+type Section struct {
+	mu     sync.Mutex
+	logger *Logger
+	banner string
+	lines  []sectionLine
+}
+
+// sectionLine is a buffered log line with the type of its logger and the scope it
+// was logged in
+type sectionLine struct {
+	loggerType int
+	message    string
+	fields     []interface{}
+	scope      string
+}
+
+// NewSection starts the section of a script with the banner naming it, e.g.
+// NewSection("file '{f}'", "f", script)
+func NewSection(format string, args ...interface{}) *Section {
+	return std.NewSection(format, args...)
+}
+
+// NewSection starts a section flushed to l, see the package function
+func (l *Logger) NewSection(format string, args ...interface{}) *Section {
+	return &Section{logger: l, banner: format_string(format, args...)}
+}
+
+func (s *Section) add(loggerType int, format string, args ...interface{}) {
+	s.logger.mu.Lock()
+	scope := s.logger.scope
+	s.logger.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, sectionLine{loggerType, format_string(format, args...), args, scope})
+}
+
+// Error buffers an error message
+func (s *Section) Error(format string, args ...interface{}) {
+	s.add(1, format, args...)
+}
+
+// Warning buffers a warning message
+func (s *Section) Warning(format string, args ...interface{}) {
+	s.add(6, format, args...)
+}
+
+// Info buffers an informational message
+func (s *Section) Info(format string, args ...interface{}) {
+	s.add(2, format, args...)
+}
+
+// Debug buffers a debug message
+func (s *Section) Debug(format string, args ...interface{}) {
+	s.add(3, format, args...)
+}
+
+// Separate buffers a separator line without prefix
+func (s *Section) Separate(format string, args ...interface{}) {
+	s.add(4, format, args...)
+}
+
+// Heading buffers a heading with timestamp, taken when flushing
+func (s *Section) Heading(format string, args ...interface{}) {
+	s.add(5, format, args...)
+}
+
+// Flush writes the banner and the buffered lines without lines of other sections or
+// of the package functions in between, and empties the section; a later flush starts
+// with the banner again. Flushing an empty section writes nothing, not even the banner.
+func (s *Section) Flush() {
+	s.mu.Lock()
+	lines := s.lines
+	s.lines = nil
+	s.mu.Unlock()
+	if len(lines) == 0 {
+		return
+	}
+
+	l := s.logger
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write_line(5, " ", nil)
+	l.write_line(4, s.banner, nil)
+	l.write_line(4, "=====================================", nil)
+	// Lines are written with the level of the scope they were logged in
+	scope := l.scope
+	defer func() { l.scope = scope }()
+	for _, line := range lines {
+		l.scope = line.scope
+		l.write_line(line.loggerType, line.message, line.fields)
+	}
+}
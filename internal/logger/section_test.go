@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSection_Flush(t *testing.T) {
+	// What: A section is written with its banner on flush, with the levels of the logger
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	s := NewSection("file '{f}'", "f", "deploy.sh")
+	s.Separate("SCRIPT SYNTAX CHECK")
+	s.Error("line {ln} is invalid", "ln", 3)
+	s.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("Expected nothing written before the flush, got %q", buf.String())
+	}
+
+	s.Flush()
+	output := buf.String()
+	if !strings.Contains(output, "file 'deploy.sh'\n=====================================\nSCRIPT SYNTAX CHECK\nERROR: line 3 is invalid\n") {
+		t.Errorf("Unexpected section %q", output)
+	}
+	if strings.Contains(output, "hidden") {
+		t.Error("Expected the debug line to be discarded at info level")
+	}
+
+	buf.Reset()
+	s.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("Expected an empty section to write nothing, got %q", buf.String())
+	}
+}
+
+func TestSection_ConcurrentFlushesDoNotInterleave(t *testing.T) {
+	// What: Sections logged and flushed in parallel are written in one piece each
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := NewSection("file 'script{i}.sh'", "i", i)
+			for n := 0; n < 50; n++ {
+				s.Info("script{i} line {n}", "i", i, "n", n)
+				Separate("unbuffered")
+			}
+			s.Flush()
+		}(i)
+	}
+	wg.Wait()
+
+	output := buf.String()
+	for i := 0; i < 8; i++ {
+		start := strings.Index(output, fmt.Sprintf("file 'script%d.sh'", i))
+		if start < 0 {
+			t.Fatalf("Section %d missing", i)
+		}
+		lines := strings.Split(output[start:], "\n")[2:52]
+		for n, line := range lines {
+			if line != fmt.Sprintf("INFO: script%d line %d", i, n) {
+				t.Fatalf("Section %d interleaved at line %d: %q", i, n, line)
+			}
+		}
+	}
+}
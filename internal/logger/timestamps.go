@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// timestampLayouts are the named timestamp formats
+var timestampLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    "2006-01-02 15:04:05",
+}
+
+// ParseTimestamps resolves a timestamp format and timezone: the format is a named one
+// (rfc3339, rfc3339nano, datetime) or a Go reference layout such as '02.01.2006 15:04 MST',
+// the timezone an IANA name such as 'Europe/Berlin', 'UTC' or 'Local'. Empty values
+// select the defaults.
+func ParseTimestamps(format, timezone string) (string, *time.Location, error) {
+	layout, location := time.RFC3339, time.UTC
+	if format != "" {
+		if named, ok := timestampLayouts[format]; ok {
+			layout = named
+		} else if probe := time.Date(2001, 11, 12, 13, 14, 15, 0, time.UTC); probe.Format(format) == format {
+			return "", nil, fmt.Errorf("timestamp format '%s' is neither named nor a Go reference layout", format)
+		} else {
+			layout = format
+		}
+	}
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return "", nil, fmt.Errorf("unknown timezone '%s'", timezone)
+		}
+		location = loaded
+	}
+	return layout, location, nil
+}
+
+// SetTimestamps sets the layout and timezone of the timestamps of ParseTimestamps.
+// Timestamps of headings and JSON lines are UTC RFC3339 by default, so the logs of
+// agents in different regions are comparable.
+func SetTimestamps(layout string, location *time.Location) {
+	std.SetTimestamps(layout, location)
+}
+
+// SetTimestamps sets the layout and timezone of the timestamps of l
+func (l *Logger) SetTimestamps(layout string, location *time.Location) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timestampLayout, l.timestampLocation = layout, location
+}
+
+// timestamp returns the current time in the timestamp layout and timezone
+func (l *Logger) timestamp() string {
+	return now().In(l.timestampLocation).Format(l.timestampLayout)
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamps(t *testing.T) {
+	// What: Named formats and Go layouts are resolved, unknown values are rejected
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("No timezone database: %v", err)
+	}
+	for _, tt := range []struct {
+		format, timezone, layout string
+		location                 *time.Location
+	}{
+		{"", "", time.RFC3339, time.UTC},
+		{"datetime", "Europe/Berlin", "2006-01-02 15:04:05", berlin},
+		{"02.01.2006 15:04", "UTC", "02.01.2006 15:04", time.UTC},
+	} {
+		layout, location, err := ParseTimestamps(tt.format, tt.timezone)
+		if err != nil || layout != tt.layout || location.String() != tt.location.String() {
+			t.Errorf("ParseTimestamps(%q, %q) = %q, %v, %v", tt.format, tt.timezone, layout, location, err)
+		}
+	}
+
+	if _, _, err := ParseTimestamps("iso", ""); err == nil || !strings.Contains(err.Error(), "'iso'") {
+		t.Errorf("Expected the unknown format to be rejected, got %v", err)
+	}
+	if _, _, err := ParseTimestamps("", "Mars/Olympus"); err == nil || !strings.Contains(err.Error(), "unknown timezone 'Mars/Olympus'") {
+		t.Errorf("Expected the unknown timezone to be rejected, got %v", err)
+	}
+}
+
+func TestSetTimestamps_HeadingsAndJSON(t *testing.T) {
+	// What: Headings and JSON lines carry the time in the layout and timezone set
+	clock := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	defer SetConsoleOutput(os.Stdout)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer InitLogger("", "error")
+
+	Heading("run")
+	if expected := "2024-01-02T15:04:05Z run\n"; buf.String() != expected {
+		t.Errorf("Expected the default UTC RFC3339 heading %q, got %q", expected, buf.String())
+	}
+
+	SetTimestamps("2006-01-02 15:04:05 MST", time.FixedZone("CET", 3600))
+	defer SetTimestamps(time.RFC3339, time.UTC)
+	buf.Reset()
+	Heading("run")
+	if expected := "2024-01-02 16:04:05 CET run\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	SetJSONOutput(true)
+	defer SetJSONOutput(false)
+	if err := InitLogger("", "info"); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	buf.Reset()
+	Info("done")
+	if !strings.HasPrefix(buf.String(), `{"time":"2024-01-02 16:04:05 CET",`) {
+		t.Errorf("Unexpected JSON time in %q", buf.String())
+	}
+}
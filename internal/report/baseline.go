@@ -0,0 +1,136 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Version of the baseline file format
+const baselineVersion = 1
+
+// BaselineFinding is a finding recorded in a baseline, with the repository of a batch
+// configuration it was found in
+type BaselineFinding struct {
+	Repository string `json:"repository,omitempty"`
+	analyzer.Finding
+}
+
+// Baseline is the set of accepted findings the later runs are compared with
+type Baseline struct {
+	Version  int               `json:"version"`
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// NewBaseline returns the baseline of the findings of each repository, sorted
+func NewBaseline(results []analyzer.RepositoryResult) Baseline {
+	b := Baseline{Version: baselineVersion, Findings: []BaselineFinding{}}
+	for _, r := range results {
+		for _, f := range SortFindings(r.Result.Findings) {
+			b.Findings = append(b.Findings, BaselineFinding{Repository: r.Name, Finding: f})
+		}
+	}
+	return b
+}
+
+// WriteBaseline writes the baseline as indented JSON
+func WriteBaseline(w io.Writer, b Baseline) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// ReadBaseline reads a baseline written by WriteBaseline
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return b, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if b.Version != baselineVersion {
+		return b, fmt.Errorf("unsupported baseline version %d (expected %d)", b.Version, baselineVersion)
+	}
+	return b, nil
+}
+
+// baselineKey identifies a finding across runs: its repository, rule, file and path,
+// or its message when it has no path. Line numbers are left out so a baseline
+// survives lines moving in the scripts; paths are compared with forward slashes so
+// baselines recorded on Windows and Linux agents match.
+func baselineKey(f BaselineFinding) string {
+	key := f.Repository + "\x00" + f.Rule + "\x00" + toSlash(f.Script) + "\x00" + f.PortablePath()
+	if f.Path == "" {
+		key += "\x00" + toSlash(f.Message)
+	}
+	return key
+}
+
+// toSlash converts both separator styles to forward slashes, the paths of messages
+// are rendered for the OS the analysis ran on
+func toSlash(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// fingerprintKey identifies a finding across runs by its repository and fingerprint
+func fingerprintKey(f BaselineFinding) string {
+	return f.Repository + "\x00" + f.Fingerprint
+}
+
+// hasFingerprints reports whether all findings of the baseline have a fingerprint;
+// baselines of earlier versions are compared by baselineKey
+func hasFingerprints(b Baseline) bool {
+	for _, f := range b.Findings {
+		if f.Fingerprint == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffBaseline compares the current findings with the baseline: added are the
+// findings not in the baseline, fixed the baseline findings no longer found. Findings
+// are matched by their fingerprints, or by baselineKey when the baseline has none. A
+// key found n times in the baseline accepts n current findings.
+func DiffBaseline(baseline, current Baseline) (added, fixed []BaselineFinding) {
+	baselineKey := baselineKey
+	if hasFingerprints(baseline) && hasFingerprints(current) {
+		baselineKey = fingerprintKey
+	}
+	accepted := make(map[string]int, len(baseline.Findings))
+	for _, f := range baseline.Findings {
+		accepted[baselineKey(f)]++
+	}
+	for _, f := range current.Findings {
+		key := baselineKey(f)
+		if accepted[key] > 0 {
+			accepted[key]--
+			continue
+		}
+		added = append(added, f)
+	}
+
+	remaining := make(map[string]int, len(current.Findings))
+	for _, f := range current.Findings {
+		remaining[baselineKey(f)]++
+	}
+	for _, f := range baseline.Findings {
+		key := baselineKey(f)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		fixed = append(fixed, f)
+	}
+	return added, fixed
+}
+
+// BaselineLine renders a baseline finding in the compact format, prefixed with its
+// repository
+func BaselineLine(f BaselineFinding) string {
+	if f.Repository == "" {
+		return CompactLine(f.Finding)
+	}
+	return f.Repository + ": " + CompactLine(f.Finding)
+}
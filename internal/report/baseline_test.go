@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// What: A baseline written and read back keeps the findings and their repository
+func TestBaseline_RoundTrip(t *testing.T) {
+	results := []analyzer.RepositoryResult{
+		{Name: "first", Result: analyzer.Result{Findings: []analyzer.Finding{
+			{Rule: "TCX010", Severity: "error", Script: "deploy.sh", Line: 4, Path: "a.xml", Message: "missing"},
+		}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteBaseline(&buf, NewBaseline(results)); err != nil {
+		t.Fatalf("WriteBaseline failed: %v", err)
+	}
+	b, err := ReadBaseline(&buf)
+	if err != nil {
+		t.Fatalf("ReadBaseline failed: %v", err)
+	}
+	if len(b.Findings) != 1 || b.Findings[0].Repository != "first" || b.Findings[0].Line != 4 {
+		t.Errorf("Unexpected baseline %+v", b)
+	}
+}
+
+// What: Baselines of another format version are refused
+func TestReadBaseline_Version(t *testing.T) {
+	_, err := ReadBaseline(strings.NewReader(`{"version": 2, "findings": []}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported baseline version 2") {
+		t.Errorf("Expected version error, got: %v", err)
+	}
+}
+
+// What: Findings are matched without line numbers, each baseline finding accepts one current finding
+func TestDiffBaseline(t *testing.T) {
+	finding := func(line int, path, message string) BaselineFinding {
+		return BaselineFinding{Finding: analyzer.Finding{Rule: "TCX010", Script: "deploy.sh", Line: line, Path: path, Message: message}}
+	}
+	baseline := Baseline{Findings: []BaselineFinding{
+		finding(3, "a.xml", "line 3"),
+		finding(5, "b.xml", "line 5"),
+		finding(0, "", "no path"),
+	}}
+	current := Baseline{Findings: []BaselineFinding{
+		finding(4, "a.xml", "line 4"),
+		finding(9, "a.xml", "line 9"),
+		finding(0, "", "no path"),
+	}}
+
+	added, fixed := DiffBaseline(baseline, current)
+	if len(added) != 1 || added[0].Line != 9 {
+		t.Errorf("Expected the second a.xml finding as added, got %+v", added)
+	}
+	if len(fixed) != 1 || fixed[0].Path != "b.xml" {
+		t.Errorf("Expected b.xml as fixed, got %+v", fixed)
+	}
+}
+
+// What: Findings with fingerprints are matched by them, baselines without by rule, file and path
+func TestDiffBaseline_Fingerprints(t *testing.T) {
+	finding := func(line int, fingerprint string) BaselineFinding {
+		return BaselineFinding{Finding: analyzer.Finding{Rule: "TCX001", Script: "deploy.sh", Line: line, Path: "a.xml", Fingerprint: fingerprint}}
+	}
+	baseline := Baseline{Findings: []BaselineFinding{finding(3, "1111"), finding(5, "2222")}}
+	current := Baseline{Findings: []BaselineFinding{finding(4, "1111"), finding(6, "3333")}}
+
+	added, fixed := DiffBaseline(baseline, current)
+	if len(added) != 1 || added[0].Fingerprint != "3333" || len(fixed) != 1 || fixed[0].Fingerprint != "2222" {
+		t.Errorf("Expected 3333 added and 2222 fixed, got added %+v, fixed %+v", added, fixed)
+	}
+
+	legacy := Baseline{Findings: []BaselineFinding{finding(3, ""), finding(5, "")}}
+	if added, fixed := DiffBaseline(legacy, current); len(added) != 0 || len(fixed) != 0 {
+		t.Errorf("Expected the legacy baseline to match by path, got added %+v, fixed %+v", added, fixed)
+	}
+}
+
+// What: Findings recorded on Windows and Linux agents, or without a normalized path, match
+func TestDiffBaseline_AcrossAgents(t *testing.T) {
+	baseline := Baseline{Findings: []BaselineFinding{
+		{Finding: analyzer.Finding{Rule: "TCX010", Script: "deploy.bat", Path: `100-Config\a.xml`}},
+		{Finding: analyzer.Finding{Rule: "TCX013", Script: `scripts\deploy.bat`, Message: `'100-Config\b.xml' is not readable`}},
+	}}
+	current := Baseline{Findings: []BaselineFinding{
+		{Finding: analyzer.Finding{Rule: "TCX010", Script: "deploy.bat", Path: `100-Config\a.xml`, NormalizedPath: "100-Config/a.xml"}},
+		{Finding: analyzer.Finding{Rule: "TCX013", Script: "scripts/deploy.bat", Message: `'100-Config/b.xml' is not readable`}},
+	}}
+	if added, fixed := DiffBaseline(baseline, current); len(added) != 0 || len(fixed) != 0 {
+		t.Errorf("Expected the findings to match, got added %+v, fixed %+v", added, fixed)
+	}
+}
+
+// What: Baseline lines are compact lines prefixed with their repository
+func TestBaselineLine(t *testing.T) {
+	f := BaselineFinding{Repository: "first", Finding: analyzer.Finding{Rule: "TCX010", Severity: "error", Script: "deploy.sh", Line: 2, Column: 5, Message: "missing"}}
+	if got := BaselineLine(f); got != "first: deploy.sh:2:5: error: TCX010 missing" {
+		t.Errorf("BaselineLine() = %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// SortFindings orders findings by file, line, column, rule and path, so that
+// output does not depend on the order in which the checks ran
+func SortFindings(findings []analyzer.Finding) []analyzer.Finding {
+	sorted := make([]analyzer.Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Script != b.Script {
+			return a.Script < b.Script
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Path < b.Path
+	})
+	return sorted
+}
+
+// CompactLine renders a finding as 'file:line:col: severity: RULE message', the shape
+// recognized by standard editor problem matchers. Findings without a script are
+// located at their path; unknown lines and columns are rendered as 1. A deduplicated
+// finding lists the scripts reporting it.
+func CompactLine(f analyzer.Finding) string {
+	file := f.Script
+	if file == "" {
+		file = f.Path
+	}
+	if file == "" {
+		file = "-"
+	}
+	line, column := f.Line, f.Column
+	if line < 1 {
+		line = 1
+	}
+	if column < 1 {
+		column = 1
+	}
+	text := fmt.Sprintf("%s:%d:%d: %s: %s %s", file, line, column, f.Severity, f.Rule, f.Message)
+	if len(f.Scripts) > 1 {
+		text += fmt.Sprintf(" (%d scripts: %s)", len(f.Scripts), strings.Join(f.Scripts, ", "))
+	}
+	return text
+}
+
+// Compact writes one line per finding in the compact format
+func Compact(w io.Writer, findings []analyzer.Finding) error {
+	for _, f := range SortFindings(findings) {
+		if _, err := fmt.Fprintln(w, CompactLine(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
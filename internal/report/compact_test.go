@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestCompactLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		finding  analyzer.Finding
+		expected string
+	}{
+		{
+			name:     "full location",
+			finding:  analyzer.Finding{Rule: "TCX001", Severity: "error", Script: "deploy.sh", Line: 12, Column: 25, Message: "flag not quoted"},
+			expected: "deploy.sh:12:25: error: TCX001 flag not quoted",
+		},
+		{
+			name:     "line without column",
+			finding:  analyzer.Finding{Rule: "TCX010", Severity: "error", Script: "deploy.bat", Line: 3, Message: "not found"},
+			expected: "deploy.bat:3:1: error: TCX010 not found",
+		},
+		{
+			name:     "deduplicated",
+			finding:  analyzer.Finding{Rule: "TCX020", Severity: "error", Script: "a.sh", Path: "x.xml", Message: "unreferenced", Scripts: []string{"a.sh", "b.sh"}},
+			expected: "a.sh:1:1: error: TCX020 unreferenced (2 scripts: a.sh, b.sh)",
+		},
+		{
+			name:     "path only",
+			finding:  analyzer.Finding{Rule: "TCX020", Severity: "warning", Path: "100-Config/a.xml", Message: "unreferenced"},
+			expected: "100-Config/a.xml:1:1: warning: TCX020 unreferenced",
+		},
+		{
+			name:     "no location",
+			finding:  analyzer.Finding{Rule: "TCX040", Severity: "error", Message: "threshold exceeded"},
+			expected: "-:1:1: error: TCX040 threshold exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := CompactLine(tt.finding); result != tt.expected {
+				t.Errorf("CompactLine() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompact_SortedOutput(t *testing.T) {
+	findings := []analyzer.Finding{
+		{Rule: "TCX010", Severity: "error", Script: "b.sh", Line: 1, Message: "second file"},
+		{Rule: "TCX010", Severity: "error", Script: "a.sh", Line: 9, Message: "later line"},
+		{Rule: "TCX001", Severity: "error", Script: "a.sh", Line: 2, Message: "earlier line"},
+	}
+
+	var buf bytes.Buffer
+	if err := Compact(&buf, findings); err != nil {
+		t.Fatalf("Compact() failed: %v", err)
+	}
+
+	expected := "a.sh:2:1: error: TCX001 earlier line\n" +
+		"a.sh:9:1: error: TCX010 later line\n" +
+		"b.sh:1:1: error: TCX010 second file\n"
+	if buf.String() != expected {
+		t.Errorf("Compact() output:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
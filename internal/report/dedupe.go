@@ -0,0 +1,50 @@
+package report
+
+import (
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Deduplicate merges the findings of a rule and path reported for several scripts into
+// the first one, which lists the scripts in Scripts. Findings without a path or script,
+// and repeated findings of the same script, e.g. on other lines, are kept.
+func Deduplicate(findings []analyzer.Finding) []analyzer.Finding {
+	type key struct{ rule, path string }
+	first := make(map[key]int) // index of the merged finding in deduplicated
+	deduplicated := make([]analyzer.Finding, 0, len(findings))
+	for _, f := range findings {
+		if f.Path == "" || f.Script == "" {
+			deduplicated = append(deduplicated, f)
+			continue
+		}
+		k := key{f.Rule, f.PortablePath()}
+		i, ok := first[k]
+		if !ok {
+			first[k] = len(deduplicated)
+			deduplicated = append(deduplicated, f)
+			continue
+		}
+		merged := &deduplicated[i]
+		if containsScript(merged, f.Script) {
+			deduplicated = append(deduplicated, f)
+			continue
+		}
+		if len(merged.Scripts) == 0 {
+			merged.Scripts = []string{merged.Script}
+		}
+		merged.Scripts = append(merged.Scripts, f.Script)
+	}
+	return deduplicated
+}
+
+// containsScript reports whether the finding is reported for the script
+func containsScript(f *analyzer.Finding, script string) bool {
+	if f.Script == script {
+		return true
+	}
+	for _, s := range f.Scripts {
+		if s == script {
+			return true
+		}
+	}
+	return false
+}
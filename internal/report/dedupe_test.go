@@ -0,0 +1,45 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestDeduplicate(t *testing.T) {
+	findings := []analyzer.Finding{
+		{Rule: "TCX020", Script: "deploy.sh", Path: "100-Config/a.xml"},
+		{Rule: "TCX020", Script: "deploy.bat", Path: "100-Config/a.xml"},
+		{Rule: "TCX010", Script: "deploy.sh", Line: 3, Path: "100-Config/a.xml"},
+		{Rule: "TCX010", Script: "deploy.sh", Line: 7, Path: "100-Config/a.xml"},
+		{Rule: "TCX020", Script: "second.sh", Path: "100-Config/a.xml"},
+		{Rule: "TCX050", Path: "deploy.*"},
+		{Rule: "TCX050", Path: "deploy.*"},
+	}
+	want := []analyzer.Finding{
+		{Rule: "TCX020", Script: "deploy.sh", Path: "100-Config/a.xml", Scripts: []string{"deploy.sh", "deploy.bat", "second.sh"}},
+		{Rule: "TCX010", Script: "deploy.sh", Line: 3, Path: "100-Config/a.xml"},
+		{Rule: "TCX010", Script: "deploy.sh", Line: 7, Path: "100-Config/a.xml"},
+		{Rule: "TCX050", Path: "deploy.*"},
+		{Rule: "TCX050", Path: "deploy.*"},
+	}
+	if got := Deduplicate(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if findings[0].Scripts != nil {
+		t.Error("Expected the findings not to be modified")
+	}
+}
+
+func TestDeduplicate_NormalizedPath(t *testing.T) {
+	// What: The same path written for Windows and Linux scripts is merged
+	findings := []analyzer.Finding{
+		{Rule: "TCX020", Script: "deploy.sh", Path: "100-Config/a.xml", NormalizedPath: "100-Config/a.xml"},
+		{Rule: "TCX020", Script: "deploy.bat", Path: `100-Config\a.xml`, NormalizedPath: "100-Config/a.xml"},
+	}
+	got := Deduplicate(findings)
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Scripts, []string{"deploy.sh", "deploy.bat"}) {
+		t.Errorf("Expected one merged finding, got %+v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Explain writes the rule with its explanation from the rule catalog:
+//
+//	TCX010 missing-file (error): Referenced path not found on the file system
+//	  What:     ...
+//	  Why:      ...
+//	  Fix:      ...
+//	  Suppress: ...
+func Explain(w io.Writer, rule analyzer.Rule) error {
+	explanation := analyzer.ExplainRule(rule.ID)
+	if _, err := fmt.Fprintf(w, "%s %s (%s): %s\n", rule.ID, rule.Name, rule.Severity, rule.Summary); err != nil {
+		return err
+	}
+	for _, part := range []struct{ label, text string }{
+		{"What", explanation.Description},
+		{"Why", explanation.Rationale},
+		{"Fix", explanation.Fix},
+		{"Suppress", explanation.Suppress},
+	} {
+		if part.text == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "  %-9s %s\n", part.label+":", part.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Explanations writes the explanations of the rules of the findings, sorted by rule
+// ID, under an '# explanations' heading; nothing without findings
+func Explanations(w io.Writer, findings []analyzer.Finding) error {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			ids = append(ids, f.Rule)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+
+	if _, err := fmt.Fprintln(w, "\n# explanations"); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		rule, ok := analyzer.LookupRule(id)
+		if !ok {
+			rule = analyzer.Rule{ID: id}
+		}
+		if err := Explain(w, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestExplain(t *testing.T) {
+	// What: A rule is written with its summary and the parts of its explanation
+	rule, _ := analyzer.LookupRule("TCX002")
+	var out bytes.Buffer
+	if err := Explain(&out, rule); err != nil {
+		t.Fatalf("Explain() failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "TCX002 wrong-separator (error): Path separator does not match the script target OS" ||
+		!strings.HasPrefix(lines[1], "  What:     ") || !strings.HasPrefix(lines[4], "  Suppress: ") {
+		t.Errorf("Unexpected explanation %q", out.String())
+	}
+}
+
+func TestExplanations(t *testing.T) {
+	// What: The rules of the findings are explained once each in ID order, nothing without findings
+	findings := []analyzer.Finding{{Rule: "TCX020"}, {Rule: "TCX010"}, {Rule: "TCX020"}}
+	var out bytes.Buffer
+	if err := Explanations(&out, findings); err != nil {
+		t.Fatalf("Explanations() failed: %v", err)
+	}
+	text := out.String()
+	if !strings.HasPrefix(text, "\n# explanations\nTCX010 ") || strings.Count(text, "TCX020 unreferenced-file") != 1 ||
+		strings.Index(text, "TCX010") > strings.Index(text, "TCX020") {
+		t.Errorf("Unexpected explanations %q", text)
+	}
+
+	out.Reset()
+	Explanations(&out, nil)
+	if out.Len() != 0 {
+		t.Errorf("Expected no output without findings, got %q", out.String())
+	}
+}
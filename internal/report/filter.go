@@ -0,0 +1,80 @@
+package report
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Filter selects the findings a report lists. Rules are rule IDs; a finding is listed
+// when its rule is included (all rules when none is), not excluded, and its path or
+// file matches one of the path filters (all paths when none is given).
+type Filter struct {
+	IncludeRules []string
+	ExcludeRules []string
+	Paths        []string // directories or patterns, with either separator
+}
+
+// IsEmpty reports whether the filter lists all findings
+func (f Filter) IsEmpty() bool {
+	return len(f.IncludeRules) == 0 && len(f.ExcludeRules) == 0 && len(f.Paths) == 0
+}
+
+// Match reports whether the finding is listed
+func (f Filter) Match(finding analyzer.Finding) bool {
+	if len(f.IncludeRules) > 0 && !containsRule(f.IncludeRules, finding.Rule) {
+		return false
+	}
+	if containsRule(f.ExcludeRules, finding.Rule) {
+		return false
+	}
+	if len(f.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range f.Paths {
+		if matchesPathFilter(finding.Path, pattern) || matchesPathFilter(finding.Script, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the findings listed by the filter, in their order
+func (f Filter) Apply(findings []analyzer.Finding) []analyzer.Finding {
+	if f.IsEmpty() {
+		return findings
+	}
+	listed := make([]analyzer.Finding, 0, len(findings))
+	for _, finding := range findings {
+		if f.Match(finding) {
+			listed = append(listed, finding)
+		}
+	}
+	return listed
+}
+
+// containsRule reports whether the rule ID is in the list
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPathFilter reports whether p is the directory or file of the filter, lies
+// below it, or matches it as a pattern ('*' does not cross directories)
+func matchesPathFilter(p, pattern string) bool {
+	if p == "" {
+		return false
+	}
+	p = strings.TrimPrefix(strings.ReplaceAll(p, `\`, "/"), "./")
+	pattern = strings.TrimSuffix(strings.TrimPrefix(strings.ReplaceAll(pattern, `\`, "/"), "./"), "/")
+	if p == pattern || strings.HasPrefix(p, pattern+"/") {
+		return true
+	}
+	matched, _ := path.Match(pattern, p)
+	return matched
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestFilter_Match(t *testing.T) {
+	missing := analyzer.Finding{Rule: "TCX010", Script: "deploy.sh", Path: "200-Stylesheets/Nw4Part.xml"}
+	listFile := analyzer.Finding{Rule: "TCX010", Script: `200-Stylesheets\import.txt`}
+	unquoted := analyzer.Finding{Rule: "TCX001", Script: "deploy.sh", Path: "100-Config/a.xml"}
+	tests := []struct {
+		name    string
+		filter  Filter
+		finding analyzer.Finding
+		want    bool
+	}{
+		{"empty filter", Filter{}, unquoted, true},
+		{"included rule", Filter{IncludeRules: []string{"TCX010"}}, missing, true},
+		{"not included rule", Filter{IncludeRules: []string{"TCX010"}}, unquoted, false},
+		{"excluded rule", Filter{ExcludeRules: []string{"TCX001"}}, unquoted, false},
+		{"path below directory", Filter{Paths: []string{"200-Stylesheets/"}}, missing, true},
+		{"file below directory", Filter{Paths: []string{"200-Stylesheets"}}, listFile, true},
+		{"path pattern", Filter{Paths: []string{"*/*.xml"}}, unquoted, true},
+		{"directory prefix is not a directory", Filter{Paths: []string{"200-Style"}}, missing, false},
+		{"rule and path", Filter{IncludeRules: []string{"TCX010"}, Paths: []string{`100-Config`}}, missing, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.finding); got != tt.want {
+				t.Errorf("Match(%+v) = %v, want %v", tt.finding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	findings := []analyzer.Finding{{Rule: "TCX001"}, {Rule: "TCX010"}, {Rule: "TCX020"}}
+	got := Filter{ExcludeRules: []string{"TCX010"}}.Apply(findings)
+	if want := []analyzer.Finding{{Rule: "TCX001"}, {Rule: "TCX020"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
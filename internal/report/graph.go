@@ -0,0 +1,75 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// graphNodeStyles are the DOT attributes of the kinds of nodes
+var graphNodeStyles = map[string]string{
+	analyzer.NodeScript:  `shape=box, style="filled,bold", fillcolor="#cfe2f3"`,
+	analyzer.NodeUtility: `shape=ellipse, style=filled, fillcolor="#d9ead3"`,
+	analyzer.NodeFile:    `shape=note`,
+	analyzer.NodeList:    `shape=folder, style=filled, fillcolor="#fff2cc"`,
+}
+
+// dotQuote returns a DOT string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// GraphDOT writes the dependency graph in the DOT language of Graphviz, e.g. for
+// 'dot -Tsvg graph.dot -o graph.svg'. Missing files are drawn red, unreferenced
+// ones dashed and gray; the nodes of a repository are grouped in a cluster.
+func GraphDOT(w io.Writer, graph analyzer.DependencyGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph deployment {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+
+	var repositories []string
+	byRepository := make(map[string][]analyzer.GraphNode)
+	for _, node := range graph.Nodes {
+		if _, ok := byRepository[node.Repository]; !ok {
+			repositories = append(repositories, node.Repository)
+		}
+		byRepository[node.Repository] = append(byRepository[node.Repository], node)
+	}
+	for i, repository := range repositories {
+		indent := "  "
+		if repository != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%s;\n", i, dotQuote(repository))
+			indent = "    "
+		}
+		for _, node := range byRepository[repository] {
+			attributes := graphNodeStyles[node.Kind]
+			switch {
+			case node.Missing:
+				attributes += `, color=red, fontcolor=red`
+			case node.Unreferenced:
+				attributes += `, style=dashed, color=gray, fontcolor=gray`
+			}
+			fmt.Fprintf(&b, "%s%s [label=%s, %s];\n", indent, dotQuote(node.ID), dotQuote(node.Label), attributes)
+		}
+		if repository != "" {
+			b.WriteString("  }\n")
+		}
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// GraphJSON writes the dependency graph as an indented JSON document of its nodes and edges
+func GraphJSON(w io.Writer, graph analyzer.DependencyGraph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(graph)
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestGraphDOT(t *testing.T) {
+	// What: Nodes are styled by kind, missing and unreferenced files stand out and labels are quoted
+	graph := analyzer.DependencyGraph{
+		Nodes: []analyzer.GraphNode{
+			{ID: "script:deploy.sh", Kind: analyzer.NodeScript, Label: "deploy.sh"},
+			{ID: "utility:deploy.sh:plmxml_import", Kind: analyzer.NodeUtility, Label: "plmxml_import"},
+			{ID: `file:100-Config/a "new".xml`, Kind: analyzer.NodeFile, Label: `100-Config/a "new".xml`, Missing: true},
+			{ID: "file:300-Old/b.xml", Kind: analyzer.NodeFile, Label: "300-Old/b.xml", Unreferenced: true},
+		},
+		Edges: []analyzer.GraphEdge{
+			{From: "script:deploy.sh", To: "utility:deploy.sh:plmxml_import"},
+			{From: "utility:deploy.sh:plmxml_import", To: `file:100-Config/a "new".xml`},
+		},
+	}
+	var b bytes.Buffer
+	if err := GraphDOT(&b, graph); err != nil {
+		t.Fatalf("GraphDOT() failed: %v", err)
+	}
+	dot := b.String()
+	for _, want := range []string{
+		"digraph deployment {\n",
+		`  "script:deploy.sh" [label="deploy.sh", shape=box`,
+		`  "file:100-Config/a \"new\".xml" [label="100-Config/a \"new\".xml", shape=note, color=red, fontcolor=red];`,
+		`  "file:300-Old/b.xml" [label="300-Old/b.xml", shape=note, style=dashed, color=gray, fontcolor=gray];`,
+		`  "script:deploy.sh" -> "utility:deploy.sh:plmxml_import";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %q in:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "subgraph") {
+		t.Errorf("Expected no cluster without repositories:\n%s", dot)
+	}
+}
+
+func TestGraphDOT_Repositories(t *testing.T) {
+	// What: The nodes of each repository are grouped in a cluster labeled with its name
+	graph := analyzer.DependencyGraph{Nodes: []analyzer.GraphNode{
+		{ID: "core:script:deploy.sh", Kind: analyzer.NodeScript, Label: "deploy.sh", Repository: "core"},
+		{ID: "plant:script:deploy.sh", Kind: analyzer.NodeScript, Label: "deploy.sh", Repository: "plant"},
+	}}
+	var b bytes.Buffer
+	if err := GraphDOT(&b, graph); err != nil {
+		t.Fatalf("GraphDOT() failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "  subgraph cluster_0 {\n    label=\"core\";\n    \"core:script:deploy.sh\"") || !strings.Contains(b.String(), "subgraph cluster_1 {\n    label=\"plant\";") {
+		t.Errorf("Expected a cluster per repository:\n%s", b.String())
+	}
+}
+
+func TestGraphJSON(t *testing.T) {
+	// What: The graph is written with its nodes and edges, empty flags left out
+	graph := analyzer.DependencyGraph{
+		Nodes: []analyzer.GraphNode{{ID: "script:deploy.sh", Kind: analyzer.NodeScript, Label: "deploy.sh"}},
+		Edges: []analyzer.GraphEdge{},
+	}
+	var b bytes.Buffer
+	if err := GraphJSON(&b, graph); err != nil {
+		t.Fatalf("GraphJSON() failed: %v", err)
+	}
+	var decoded analyzer.DependencyGraph
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || len(decoded.Nodes) != 1 || decoded.Nodes[0].Kind != "script" {
+		t.Errorf("Unexpected JSON %s (%v)", b.String(), err)
+	}
+	if strings.Contains(b.String(), "missing") || !strings.Contains(b.String(), `"edges": []`) {
+		t.Errorf("Unexpected JSON %s", b.String())
+	}
+}
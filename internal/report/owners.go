@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Heading of the findings without an owner
+const unowned = "(unowned)"
+
+// GroupByOwner returns the sorted findings per owner; findings without an owner are
+// grouped under "(unowned)"
+func GroupByOwner(findings []analyzer.Finding) map[string][]analyzer.Finding {
+	groups := make(map[string][]analyzer.Finding)
+	for _, f := range SortFindings(findings) {
+		owner := f.Owner
+		if owner == "" {
+			owner = unowned
+		}
+		groups[owner] = append(groups[owner], f)
+	}
+	return groups
+}
+
+// ByOwner writes the findings in the compact format grouped by owner, under a
+// '# owner (n)' heading per owner sorted by name
+func ByOwner(w io.Writer, findings []analyzer.Finding) error {
+	groups := GroupByOwner(findings)
+	owners := make([]string, 0, len(groups))
+	for owner := range groups {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	for i, owner := range owners {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# %s (%d)\n", owner, len(groups[owner])); err != nil {
+			return err
+		}
+		if err := Compact(w, groups[owner]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// What: Findings are grouped by owner, owners sorted and unowned findings grouped together
+func TestByOwner(t *testing.T) {
+	findings := []analyzer.Finding{
+		{Rule: "TCX020", Severity: "error", Script: "deploy.sh", Path: "b.xml", Message: "b", Owner: "@ui-team"},
+		{Rule: "TCX010", Severity: "error", Script: "deploy.sh", Line: 2, Message: "missing"},
+		{Rule: "TCX017", Severity: "error", Script: "deploy.bat", Line: 1, Message: "a", Owner: "@bmide-team"},
+		{Rule: "TCX020", Severity: "error", Script: "deploy.bat", Path: "a.xml", Message: "c", Owner: "@ui-team"},
+	}
+
+	var buf bytes.Buffer
+	if err := ByOwner(&buf, findings); err != nil {
+		t.Fatalf("ByOwner failed: %v", err)
+	}
+
+	expected := "# (unowned) (1)\n" +
+		"deploy.sh:2:1: error: TCX010 missing\n" +
+		"\n" +
+		"# @bmide-team (1)\n" +
+		"deploy.bat:1:1: error: TCX017 a\n" +
+		"\n" +
+		"# @ui-team (2)\n" +
+		"deploy.bat:1:1: error: TCX020 c\n" +
+		"deploy.sh:1:1: error: TCX020 b\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+// What: No findings write nothing
+func TestByOwner_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ByOwner(&buf, nil); err != nil {
+		t.Fatalf("ByOwner failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q", buf.String())
+	}
+}
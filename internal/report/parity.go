@@ -0,0 +1,51 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// ParityCSV writes the parity matrix as CSV, a row per executable with its invocations
+// per script and in all Windows and all Linux scripts:
+//
+//	executable,deploy.bat,deploy.sh,windows,linux
+//	install_xml_stylesheet_datasets,1,2,1,2
+//
+// Scripts of several repositories are headed 'repository/script'.
+func ParityCSV(w io.Writer, matrix analyzer.ParityMatrix) error {
+	out := csv.NewWriter(w)
+	header := []string{"executable"}
+	for _, script := range matrix.Scripts {
+		label := script.Filename
+		if script.Repository != "" {
+			label = script.Repository + "/" + script.Filename
+		}
+		header = append(header, label)
+	}
+	if err := out.Write(append(header, "windows", "linux")); err != nil {
+		return err
+	}
+	for _, row := range matrix.Executables {
+		record := []string{row.Executable}
+		for _, calls := range row.Calls {
+			record = append(record, strconv.Itoa(calls))
+		}
+		if err := out.Write(append(record, strconv.Itoa(row.Windows), strconv.Itoa(row.Linux))); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ParityJSON writes the parity matrix as an indented JSON document, the calls of each
+// executable in the order of the scripts
+func ParityJSON(w io.Writer, matrix analyzer.ParityMatrix) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(matrix)
+}
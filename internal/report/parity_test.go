@@ -0,0 +1,43 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestParityCSV(t *testing.T) {
+	// What: The scripts are the columns, labeled with their repository when set
+	matrix := analyzer.ParityMatrix{
+		Scripts: []analyzer.ParityScript{{Filename: "deploy.bat", TargetOS: "windows"}, {Repository: "plant", Filename: "deploy.sh", TargetOS: "linux"}},
+		Executables: []analyzer.ParityRow{
+			{Executable: "install_xml_stylesheet_datasets", Calls: []int{1, 2}, Windows: 1, Linux: 2},
+			{Executable: "plmxml_import", Calls: []int{0, 1}, Linux: 1},
+		},
+	}
+	var b bytes.Buffer
+	if err := ParityCSV(&b, matrix); err != nil {
+		t.Fatalf("ParityCSV() failed: %v", err)
+	}
+	want := "executable,deploy.bat,plant/deploy.sh,windows,linux\ninstall_xml_stylesheet_datasets,1,2,1,2\nplmxml_import,0,1,0,1\n"
+	if b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+}
+
+func TestParityJSON(t *testing.T) {
+	// What: The matrix is written with the calls in the order of the scripts
+	matrix := analyzer.ParityMatrix{
+		Scripts:     []analyzer.ParityScript{{Filename: "deploy.sh", TargetOS: "linux"}},
+		Executables: []analyzer.ParityRow{{Executable: "plmxml_import", Calls: []int{2}, Linux: 2}},
+	}
+	var b bytes.Buffer
+	if err := ParityJSON(&b, matrix); err != nil {
+		t.Fatalf("ParityJSON() failed: %v", err)
+	}
+	if !strings.Contains(b.String(), `"filename": "deploy.sh"`) || !strings.Contains(b.String(), `"calls": [`) || strings.Contains(b.String(), "repository") {
+		t.Errorf("Unexpected JSON %s", b.String())
+	}
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// diffCell is a command of a side of the HTML parity diff, its text split around the
+// referenced path to highlight it
+type diffCell struct {
+	Line                int
+	Before, Path, After string
+	Executable          string
+}
+
+type diffRow struct {
+	Status         string
+	Windows, Linux *diffCell
+}
+
+type diffPair struct {
+	Title          string
+	Windows, Linux string
+	Rows           []diffRow
+	Differences    int
+}
+
+// newDiffCell splits the text of a command around its path, the whole text before it
+// when the path is not written as such (e.g. after a continued line)
+func newDiffCell(command *analyzer.DiffCommand) *diffCell {
+	if command == nil {
+		return nil
+	}
+	cell := &diffCell{Line: command.Line, Before: command.Text, Executable: command.Executable}
+	if command.Path == "" {
+		return cell
+	}
+	if at := strings.Index(command.Text, command.Path); at >= 0 {
+		cell.Before, cell.Path, cell.After = command.Text[:at], command.Path, command.Text[at+len(command.Path):]
+	}
+	return cell
+}
+
+var parityDiffTemplate = template.Must(template.New("parity").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Script parity diff</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; width: 100%; table-layout: fixed; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; vertical-align: top; }
+td.code { font-family: monospace; white-space: pre-wrap; word-break: break-all; }
+td.line { width: 3em; text-align: right; color: #777; }
+mark { background: none; }
+tr.file td { background: #fff8dc; }
+tr.file mark { background: #ffd54f; }
+tr.windows td, tr.linux td { background: #ffe0e0; }
+td.empty, tr.windows td.empty, tr.linux td.empty { background: #f4f4f4; }
+body.differences tr.same { display: none; }
+</style>
+</head>
+<body>
+<h1>Script parity diff</h1>
+<label><input type="checkbox" onchange="document.body.classList.toggle('differences', this.checked)"> differences only</label>
+{{- range .}}
+<h2>{{.Title}}</h2>
+<p>{{.Differences}} difference(s)</p>
+<table>
+<tr><th class="line"></th><th>{{.Windows}}</th><th class="line"></th><th>{{.Linux}}</th></tr>
+{{- range .Rows}}
+<tr class="{{.Status}}">{{template "cell" .Windows}}{{template "cell" .Linux}}</tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No pair of Windows and Linux scripts.</p>
+{{- end}}
+</body>
+</html>
+{{define "cell"}}{{if .}}<td class="line">{{.Line}}</td><td class="code" title="{{.Executable}}">{{.Before}}{{if .Path}}<mark>{{.Path}}</mark>{{end}}{{.After}}</td>{{else}}<td class="line empty"></td><td class="code empty"></td>{{end}}{{end}}
+`))
+
+// ParityHTML writes the parity diffs as a standalone HTML page, a side-by-side table
+// per script pair. Commands of one script only are highlighted on their side, the
+// files of the commands calling the same executable with another file are marked
+// inline, and a checkbox hides the commands matching on both sides.
+func ParityHTML(w io.Writer, diffs []analyzer.ParityDiff) error {
+	pairs := make([]diffPair, 0, len(diffs))
+	for _, diff := range diffs {
+		pair := diffPair{Title: diff.Windows + " / " + diff.Linux, Windows: diff.Windows, Linux: diff.Linux}
+		if diff.Repository != "" {
+			pair.Title = diff.Repository + ": " + pair.Title
+		}
+		for _, row := range diff.Rows {
+			if row.Status != analyzer.DiffSame {
+				pair.Differences++
+			}
+			pair.Rows = append(pair.Rows, diffRow{Status: row.Status, Windows: newDiffCell(row.Windows), Linux: newDiffCell(row.Linux)})
+		}
+		pairs = append(pairs, pair)
+	}
+	return parityDiffTemplate.Execute(w, pairs)
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestParityHTML(t *testing.T) {
+	// What: The pair is a side-by-side table, file differences are marked inline and the text is escaped
+	diffs := []analyzer.ParityDiff{{Repository: "plant", Windows: "deploy.bat", Linux: "deploy.sh", Rows: []analyzer.ParityDiffRow{
+		{Status: analyzer.DiffSame,
+			Windows: &analyzer.DiffCommand{Line: 1, Text: "make_user -u=admin", Executable: "make_user"},
+			Linux:   &analyzer.DiffCommand{Line: 1, Text: "make_user -u=admin", Executable: "make_user"}},
+		{Status: analyzer.DiffFile,
+			Windows: &analyzer.DiffCommand{Line: 2, Text: `plmxml_import -xml_file="old.xml"`, Executable: "plmxml_import", Path: "old.xml"},
+			Linux:   &analyzer.DiffCommand{Line: 3, Text: `plmxml_import -xml_file="new.xml"`, Executable: "plmxml_import", Path: "new.xml"}},
+		{Status: analyzer.DiffLinuxOnly,
+			Linux: &analyzer.DiffCommand{Line: 4, Text: "clsutility <script>", Executable: "clsutility"}},
+	}}}
+	var b bytes.Buffer
+	if err := ParityHTML(&b, diffs); err != nil {
+		t.Fatalf("ParityHTML() failed: %v", err)
+	}
+	page := b.String()
+	for _, want := range []string{
+		"<h2>plant: deploy.bat / deploy.sh</h2>",
+		"<p>2 difference(s)</p>",
+		`plmxml_import -xml_file=&#34;<mark>old.xml</mark>&#34;`,
+		`<tr class="linux"><td class="line empty"></td><td class="code empty"></td><td class="line">4</td>`,
+		"clsutility &lt;script&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %q in the page:\n%s", want, page)
+		}
+	}
+}
+
+func TestParityHTML_NoPairs(t *testing.T) {
+	// What: Without a pair of scripts the page says so
+	var b bytes.Buffer
+	if err := ParityHTML(&b, nil); err != nil {
+		t.Fatalf("ParityHTML() failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "No pair of Windows and Linux scripts.") {
+		t.Errorf("Unexpected page %s", b.String())
+	}
+}
@@ -2,12 +2,20 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"runtime/pprof"
+	"strings"
+	"sync"
 
 	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
 	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,57 +23,470 @@ import (
 type Args struct {
 	ConfigPath string
 	LogLevel   string
+	LogFormat  string // overrides 'log_format' of the configuration
+	Format     string
+	Profile    bool
+	// Print the version information as JSON and exit
+	PrintVersion bool
+
+	Config configSource // fetching of a configuration given as URL
+
+	// Environment snapshot overriding 'environment_snapshot' of the configuration
+	Snapshot string
+	// Audit log overriding 'audit_log' of the configuration
+	AuditLog string
+	// Parity matrix file overriding 'parity_matrix' of the configuration
+	ParityMatrix string
+	// Parity diff file overriding 'parity_diff' of the configuration
+	ParityDiff string
+	// Ruleset overriding 'ruleset' of the configuration
+	Ruleset string
+	// Phase durations overriding 'perf_budget' of the configuration, e.g. "traversal=2s,total=30s"
+	PerfBudget string
+	// Limits overriding 'max_findings' and 'fail_fast' of the configuration
+	MaxFindings int
+	FailFast    bool
+
+	// Findings of a rule and path repeated across scripts are listed for each script
+	NoDedupe bool
+	// The report ends with the explanations of the rules of its findings
+	Explain bool
+
+	// Findings listed by the report, comma-separated rule IDs or names and paths
+	IncludeRules string
+	ExcludeRules string
+	PathFilter   string
 }
 
 func main() {
 	err := run()
-	if err != nil {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	os.Exit(exitCode(err))
 }
 
 func run() error {
-	args := ProcessArgs()
+	return dispatch(toolCommands(), os.Args[1:], os.Stdout)
+}
 
-	configurationParameters, err := getConfig(args.ConfigPath)
+// runCheck validates the configured scripts, the bare invocation without a
+// subcommand: [check] [-c config.yaml] [-l level] [-format text|compact|owners] [-profile]
+func runCheck(arguments []string, w io.Writer) error {
+	args := parseArgs(arguments)
+	if args.PrintVersion {
+		return withExitCode(exitIO, printVersion(w))
+	}
+	if args.Format != "text" && args.Format != "compact" && args.Format != "owners" {
+		return withExitCode(exitConfig, fmt.Errorf("invalid format '%s' (must be 'text', 'compact' or 'owners')", args.Format))
+	}
+	filter, err := reportFilter(args)
+	if err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	configurationParameters, err := getConfigFrom(args.ConfigPath, args.Config)
 	if err != nil {
 		return err
 	}
 
-	err = logger.InitLogger(configurationParameters.Logfile, args.LogLevel)
+	// Reports are written to stdout, the log only goes to the log file
+	results, err := validate(args, configurationParameters, args.Format != "text")
+	if results == nil {
+		return err
+	}
+	reported := func(findings []analyzer.Finding) []analyzer.Finding { return reportedFindings(args, filter, findings) }
+	if len(configurationParameters.Repositories) == 0 {
+		if writeErr := writeReport(w, args, reported(results[0].Result.Findings)); writeErr != nil {
+			return withExitCode(exitIO, fmt.Errorf("failed to write report: %w", writeErr))
+		}
+		return validationError(results, reported, err)
+	}
+
+	for i, r := range results {
+		if args.Format == "text" {
+			break
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "==> %s <==\n", r.Name)
+		if writeErr := writeReport(w, args, reported(r.Result.Findings)); writeErr != nil {
+			return withExitCode(exitIO, fmt.Errorf("failed to write report: %w", writeErr))
+		}
+	}
+	// The text reports of the repositories are one log, explained once
+	if args.Format == "text" && args.Explain {
+		var findings []analyzer.Finding
+		for _, r := range results {
+			findings = append(findings, reported(r.Result.Findings)...)
+		}
+		if writeErr := report.Explanations(w, findings); writeErr != nil {
+			return withExitCode(exitIO, fmt.Errorf("failed to write report: %w", writeErr))
+		}
+	}
+	return validationError(results, reported, err)
+}
+
+// outputMu serializes the writing of the audit log and the parity files, which the
+// parallel requests of the serve subcommand share
+var outputMu sync.Mutex
+
+// validate runs the validation of the configuration with the logging of args, the
+// log is not written to the console when quiet. A configuration without repositories
+// is validated as one unnamed repository. Each validation logs with a logger of its
+// own, so validations of several goroutines run in parallel.
+func validate(args Args, configurationParameters analyzer.Parameters, quiet bool) ([]analyzer.RepositoryResult, error) {
+	filter, err := reportFilter(args)
 	if err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
+		return nil, withExitCode(exitConfig, err)
+	}
+	console := logger.ConsoleOutput()
+	if quiet {
+		console = io.Discard
 	}
-	defer logger.Close()
 
-	analyzer.Run(configurationParameters)
-	return nil
+	logFormat := configurationParameters.LogFormat
+	if args.LogFormat != "" {
+		logFormat = args.LogFormat
+	}
+	if logFormat != "" && logFormat != "text" && logFormat != "json" {
+		return nil, withExitCode(exitConfig, fmt.Errorf("invalid log format '%s' (must be 'text' or 'json')", logFormat))
+	}
+	layout, location, err := logger.ParseTimestamps(configurationParameters.LogTimestampFormat, configurationParameters.LogTimezone)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	log, err := logger.New(console, configurationParameters.Logfile, args.LogLevel)
+	if err != nil {
+		return nil, withExitCode(exitIO, fmt.Errorf("failed to initialize logger: %w", err))
+	}
+	defer log.Close()
+	log.SetJSONOutput(logFormat == "json")
+	log.SetTimestamps(layout, location)
+	log.SetScopeLevels(configurationParameters.LogLevels)
+	log.SetRepeatSuppression(configurationParameters.LogSuppressRepeats)
+	log.SetDebugRateLimit(configurationParameters.LogDebugRateLimit)
+
+	// The text report is the log, the filtered findings are left out of it
+	validator := analyzer.New(log)
+	if !filter.IsEmpty() {
+		validator.SetFindingLogFilter(filter.Match)
+	}
+	validator.SetFindingDeduplication(!args.NoDedupe)
+
+	if args.Profile {
+		stop, err := startProfiling(cpuProfileFile, heapProfileFile)
+		if err != nil {
+			return nil, withExitCode(exitIO, err)
+		}
+		defer stop()
+	}
+
+	if args.Snapshot != "" {
+		configurationParameters.EnvironmentSnapshot = args.Snapshot
+		for i := range configurationParameters.Repositories {
+			configurationParameters.Repositories[i].Parameters.EnvironmentSnapshot = args.Snapshot
+		}
+	}
+
+	var results []analyzer.RepositoryResult
+	if args.MaxFindings < 0 {
+		return nil, withExitCode(exitConfig, fmt.Errorf("invalid -max-findings %d (must be 0 for unlimited or positive)", args.MaxFindings))
+	}
+	if args.MaxFindings > 0 {
+		configurationParameters.MaxFindings = args.MaxFindings
+		for i := range configurationParameters.Repositories {
+			configurationParameters.Repositories[i].Parameters.MaxFindings = args.MaxFindings
+		}
+	}
+	if args.Ruleset != "" {
+		if !analyzer.IsRuleset(args.Ruleset) {
+			return nil, withExitCode(exitConfig, fmt.Errorf("invalid -ruleset '%s' (must be 'lenient', 'standard' or 'strict')", args.Ruleset))
+		}
+		configurationParameters.Ruleset = args.Ruleset
+		for i := range configurationParameters.Repositories {
+			configurationParameters.Repositories[i].Parameters.Ruleset = args.Ruleset
+		}
+	}
+	if args.PerfBudget != "" {
+		budget, err := analyzer.ParsePerfBudget(args.PerfBudget)
+		if err != nil {
+			return nil, withExitCode(exitConfig, fmt.Errorf("invalid -perf-budget: %w", err))
+		}
+		configurationParameters.PerfBudget = budget
+		for i := range configurationParameters.Repositories {
+			configurationParameters.Repositories[i].Parameters.PerfBudget = budget
+		}
+	}
+	if args.FailFast {
+		configurationParameters.FailFast = true
+		for i := range configurationParameters.Repositories {
+			configurationParameters.Repositories[i].Parameters.FailFast = true
+		}
+	}
+
+	if len(configurationParameters.Repositories) > 0 {
+		results, err = validator.RunRepositories(configurationParameters)
+	} else {
+		var result analyzer.Result
+		result, err = validator.Run(configurationParameters)
+		results = []analyzer.RepositoryResult{{Root: configurationParameters.SourceCodeRoot, Result: result, Err: err}}
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	// A run that cannot be audited does not count as validated
+	auditLog := configurationParameters.AuditLog
+	if args.AuditLog != "" {
+		auditLog = args.AuditLog
+	}
+	if auditLog != "" {
+		if auditErr := appendAuditRecords(auditLog, args.ConfigPath, configurationParameters.ConfigSHA256, results); auditErr != nil {
+			return nil, withExitCode(exitIO, auditErr)
+		}
+	}
+
+	parityMatrix := configurationParameters.ParityMatrix
+	if args.ParityMatrix != "" {
+		parityMatrix = args.ParityMatrix
+	}
+	if parityMatrix != "" {
+		if parityErr := writeParityMatrix(parityMatrix, results); parityErr != nil {
+			return nil, withExitCode(exitIO, parityErr)
+		}
+	}
+
+	parityDiff := configurationParameters.ParityDiff
+	if args.ParityDiff != "" {
+		parityDiff = args.ParityDiff
+	}
+	if parityDiff != "" {
+		if diffErr := writeParityDiff(parityDiff, results); diffErr != nil {
+			return nil, withExitCode(exitIO, diffErr)
+		}
+	}
+	return results, err
+}
+
+// writeReport writes the findings in the report format of args, followed by the
+// explanations of their rules with -explain; the text format is the log
+func writeReport(w io.Writer, args Args, findings []analyzer.Finding) error {
+	var err error
+	switch args.Format {
+	case "compact":
+		err = report.Compact(w, findings)
+	case "owners":
+		err = report.ByOwner(w, findings)
+	}
+	if err != nil || !args.Explain {
+		return err
+	}
+	return report.Explanations(w, findings)
+}
+
+// reportFilter returns the filter of the findings reported by check; the rules are
+// given by ID or name
+func reportFilter(args Args) (report.Filter, error) {
+	var filter report.Filter
+	for _, list := range []struct {
+		value string
+		rules *[]string
+	}{{args.IncludeRules, &filter.IncludeRules}, {args.ExcludeRules, &filter.ExcludeRules}} {
+		for _, name := range splitList(list.value) {
+			rule, ok := analyzer.LookupRule(name)
+			if !ok {
+				return filter, fmt.Errorf("unknown rule '%s' (use a rule ID such as TCX010 or its name such as missing-file)", name)
+			}
+			*list.rules = append(*list.rules, rule.ID)
+		}
+	}
+	filter.Paths = splitList(args.PathFilter)
+	return filter, nil
 }
 
+// reportedFindings returns the findings check reports: deduplicated unless -no-dedupe,
+// and selected by the filter
+func reportedFindings(args Args, filter report.Filter, findings []analyzer.Finding) []analyzer.Finding {
+	if !args.NoDedupe {
+		findings = report.Deduplicate(findings)
+	}
+	return filter.Apply(findings)
+}
+
+// splitList returns the non-empty entries of a comma-separated list
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ProcessArgs parses the command-line parameters of the bare invocation
 func ProcessArgs() Args {
+	return parseArgs(os.Args[1:])
+}
+
+// parseArgs parses the command-line parameters of the validation
+func parseArgs(arguments []string) Args {
 	var a Args
+	f := defaultFlagSet(&a)
+	f.Parse(arguments)
+	return a
+}
+
+// defaultFlagSet defines the flags of the validation into a
+func defaultFlagSet(a *Args) *flag.FlagSet {
+	f := flag.NewFlagSet("check", 1)
+	validationFlags(f, a)
+	f.StringVar(&a.Format, "format", "text", "output format: text (log output), compact (one line per finding) or owners (compact, grouped by owner)")
+
+	f.BoolVar(&a.Explain, "explain", false, "end the report with the explanation of each reported rule (see 'explain')")
+	f.BoolVar(&a.NoDedupe, "no-dedupe", false, "report findings of a rule and path repeated across scripts for each script")
+	f.StringVar(&a.IncludeRules, "include-rule", "", "report only the findings of these rules (comma-separated IDs or names)")
+	f.StringVar(&a.ExcludeRules, "exclude-rule", "", "leave the findings of these rules out of the report (comma-separated IDs or names)")
+	f.StringVar(&a.PathFilter, "path-filter", "", "report only the findings under these directories or matching these patterns (comma-separated)")
+	f.BoolVar(&a.PrintVersion, "print-version", false, "print the tool and policy versions as JSON and exit")
+	f.BoolVar(&a.Profile, "profile", false, "write CPU and heap profiles ("+cpuProfileFile+", "+heapProfileFile+")")
+	return f
+}
 
-	f := flag.NewFlagSet("Default", 1)
-	f.StringVar(&a.ConfigPath, "c", "config.yaml", "path to configuration file")
+// validationFlags defines the flags selecting and logging the validation, shared by the
+// subcommands running it
+func validationFlags(f *flag.FlagSet, a *Args) {
+	configFlags(f, &a.ConfigPath, &a.Config)
 	f.StringVar(&a.LogLevel, "l", "error", "info, error, or debug logging")
+	f.StringVar(&a.LogFormat, "log-format", "", "log format: text or json, one object per line with the message fields (overrides 'log_format')")
+	f.StringVar(&a.Snapshot, "snapshot", "", "export of the items installed in the environment (CSV or JSON) to cross-check with the deployed ones")
+	f.StringVar(&a.Ruleset, "ruleset", "", "built-in ruleset: lenient (syntax and existence only), standard or strict (overrides 'ruleset')")
+	f.IntVar(&a.MaxFindings, "max-findings", 0, "stop the run after this many findings (overrides 'max_findings', 0 keeps it)")
+	f.BoolVar(&a.FailFast, "fail-fast", false, "stop the run at the first error finding")
+	f.StringVar(&a.PerfBudget, "perf-budget", "", "fail the run when phases take longer, e.g. traversal=2s,total=30s (overrides 'perf_budget')")
+	f.StringVar(&a.AuditLog, "audit-log", "", "JSONL file to append the audit record of the run to (overrides 'audit_log')")
+	f.StringVar(&a.ParityMatrix, "parity-matrix", "", "file to write the executable x script invocation counts to, JSON for .json and CSV otherwise (overrides 'parity_matrix')")
+	f.StringVar(&a.ParityDiff, "parity-diff", "", "HTML file to write the side-by-side diff of the Windows and Linux script pairs to (overrides 'parity_diff')")
+}
 
-	f.Parse(os.Args[1:])
-	return a
+// configFlags defines the flags locating the configuration
+func configFlags(f *flag.FlagSet, configPath *string, source *configSource) {
+	f.StringVar(configPath, "c", "config.yaml", "path or http(s) URL of the configuration file")
+	f.StringVar(&source.TokenEnv, "config-token-env", "", "environment variable with the bearer token for a configuration URL")
+	f.StringVar(&source.SHA256, "config-sha256", "", "expected SHA-256 checksum of the configuration")
+	f.StringVar(&source.Env, "env", "", "profile of the configuration to apply, e.g. prod (see 'profiles')")
 }
 
-func getConfig(filename string) (analyzer.Parameters, error) {
-	var c analyzer.Parameters
+// Profile files written with -profile, in the working directory
+const (
+	cpuProfileFile  = "cpu.pprof"
+	heapProfileFile = "heap.pprof"
+)
 
-	// Check if file exists first for better error message
-	_, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return c, fmt.Errorf("configuration file '%s' not found", filename)
+// startProfiling starts the CPU profile; the returned function stops it
+// and writes the heap profile
+func startProfiling(cpuFile, heapFile string) (func(), error) {
+	cpu, err := os.Create(cpuFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
 	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		cpu.Close()
 
-	yamlFile, err := os.ReadFile(filename)
+		heap, err := os.Create(heapFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create heap profile: %v\n", err)
+			return
+		}
+		defer heap.Close()
+		if err := pprof.WriteHeapProfile(heap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write heap profile: %v\n", err)
+		}
+	}, nil
+}
+
+// traceOptions are the command-line parameters of the plan subcommand
+type traceOptions struct {
+	ConfigPath string
+	Script     string
+	Config     configSource
+}
+
+// traceFlagSet defines the flags of the plan subcommand into o
+func traceFlagSet(o *traceOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("plan", flag.ContinueOnError)
+	configFlags(f, &o.ConfigPath, &o.Config)
+	f.StringVar(&o.Script, "s", "", "script to trace (default: all configured scripts)")
+	return f
+}
+
+// runTrace prints the dry-run execution trace of the configured scripts:
+// plan [-c config.yaml] [-s script], or its alias trace
+func runTrace(arguments []string, w io.Writer) error {
+	var o traceOptions
+	if err := traceFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	configurationParameters, err := getConfigFrom(o.ConfigPath, o.Config)
+	if err != nil {
+		return err
+	}
+
+	// A batch configuration traces the scripts of every repository
+	repositories := configurationParameters.Repositories
+	if len(repositories) == 0 {
+		repositories = []analyzer.Repository{{Parameters: configurationParameters}}
+	}
+
+	traced := 0
+	for _, repo := range repositories {
+		for _, script := range repo.Parameters.Scripts {
+			if o.Script != "" && script.Filename != o.Script {
+				continue
+			}
+			traced++
+			steps, err := analyzer.Trace(repo.Parameters, script.Filename)
+			if err != nil {
+				return withExitCode(kindExitCodes[analyzer.Kind(err)], err)
+			}
+			if repo.Name != "" {
+				fmt.Fprintf(w, "# %s: %s (%s)\n", repo.Name, script.Filename, script.TargetOS)
+			} else {
+				fmt.Fprintf(w, "# %s (%s)\n", script.Filename, script.TargetOS)
+			}
+			for _, step := range steps {
+				fmt.Fprintln(w, step.String())
+			}
+		}
+	}
+	if traced == 0 {
+		return withExitCode(exitConfig, fmt.Errorf("script '%s' is not configured", o.Script))
+	}
+	return nil
+}
+
+func getConfig(filename string) (analyzer.Parameters, error) {
+	return getConfigFrom(filename, configSource{})
+}
+
+// getConfigFrom reads the configuration from a file or an http(s) URL (see configSource)
+func getConfigFrom(filename string, source configSource) (c analyzer.Parameters, err error) {
+	// A configuration that cannot be loaded is a configuration error
+	defer func() { err = withExitCode(exitConfig, err) }()
+
+	yamlFile, err := readConfig(filename, source)
 	if err != nil {
-		return c, fmt.Errorf("error reading configuration file '%s': %w", filename, err)
+		return c, err
 	}
 
 	// Check for tabs in YAML (common mistake that causes parsing errors)
@@ -73,10 +494,26 @@ func getConfig(filename string) (analyzer.Parameters, error) {
 		return c, fmt.Errorf("invalid YAML in '%s': file contains tabs. YAML requires spaces for indentation, not tabs", filename)
 	}
 
-	err = yaml.Unmarshal(yamlFile, &c)
+	// The checksum is the one of the document read, the profile is its part
+	checksum := sha256.Sum256(yamlFile)
+	document, err := analyzer.ApplyProfile(yamlFile, source.Env)
+	if err != nil {
+		return c, fmt.Errorf("invalid configuration '%s': %w", filename, err)
+	}
+	err = yaml.Unmarshal(document, &c)
 	if err != nil {
 		return c, fmt.Errorf("invalid YAML format in '%s': %w", filename, err)
 	}
+	c.ConfigSHA256 = hex.EncodeToString(checksum[:])
+	if err := checkCompatibility(&c); err != nil {
+		return c, fmt.Errorf("incompatible configuration '%s': %w", filename, err)
+	}
+	if err := c.ResolveRepositories(document); err != nil {
+		return c, fmt.Errorf("invalid YAML format in '%s': %w", filename, err)
+	}
+	if err := analyzer.ResolveSourceCodeRoot(&c); err != nil {
+		return c, fmt.Errorf("configuration validation failed in '%s': %w", filename, err)
+	}
 
 	// Validate the configuration
 	err = validateConfig(&c)
@@ -88,6 +525,29 @@ func getConfig(filename string) (analyzer.Parameters, error) {
 }
 
 func validateConfig(c *analyzer.Parameters) error {
+	if len(c.Repositories) == 0 {
+		return validateParameters(c)
+	}
+
+	// Each repository is validated with the top-level parameters it inherits
+	names := make(map[string]bool, len(c.Repositories))
+	for i := range c.Repositories {
+		repo := &c.Repositories[i]
+		if repo.Name == "" {
+			return fmt.Errorf("repository at index %d is missing 'name'", i)
+		}
+		if names[repo.Name] {
+			return fmt.Errorf("repository name '%s' is used more than once", repo.Name)
+		}
+		names[repo.Name] = true
+		if err := validateParameters(&repo.Parameters); err != nil {
+			return fmt.Errorf("repository '%s': %w", repo.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateParameters(c *analyzer.Parameters) error {
 	// Validate scripts list
 	if len(c.Scripts) == 0 {
 		return fmt.Errorf("'scripts' list cannot be empty")
@@ -105,6 +565,10 @@ func validateConfig(c *analyzer.Parameters) error {
 			return fmt.Errorf("script '%s' has invalid 'target_os': '%s' (must be 'windows' or 'linux')",
 				script.Filename, script.TargetOS)
 		}
+		if !analyzer.IsMatchStrategy(script.MatchStrategy) {
+			return fmt.Errorf("script '%s' has invalid 'match_strategy': '%s' (must be 'exact', 'suffix' or 'basename')",
+				script.Filename, script.MatchStrategy)
+		}
 	}
 
 	// Validate source_code_root
@@ -116,6 +580,127 @@ func validateConfig(c *analyzer.Parameters) error {
 	if len(c.PathParameters) == 0 {
 		return fmt.Errorf("'path_parameters' list cannot be empty")
 	}
+	if err := analyzer.ValidatePathParameters(c.PathParameters); err != nil {
+		return err
+	}
+
+	// Validate conditional references policy
+	switch c.ConditionalReferences {
+	case "", "covered", "not_covered":
+	default:
+		return fmt.Errorf("invalid 'conditional_references': '%s' (must be 'covered' or 'not_covered')", c.ConditionalReferences)
+	}
+
+	switch c.ScriptEncoding {
+	case "", "info", "warning", "error", "ignore":
+	default:
+		return fmt.Errorf("invalid 'script_encoding': '%s' (must be 'info', 'warning', 'error' or 'ignore')", c.ScriptEncoding)
+	}
+
+	switch c.Templating.Mode {
+	case "", analyzer.TemplatingRender, analyzer.TemplatingWildcard:
+	default:
+		return fmt.Errorf("invalid 'templating.mode': '%s' (must be 'render' or 'wildcard')", c.Templating.Mode)
+	}
+
+	if err := analyzer.ValidateIgnorePatterns(c.IgnorePatterns); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateFlagRules(c.FlagRules); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateAllowedExecutables(c.AllowedExecutables); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateCredentialFlags(c.CredentialFlags); err != nil {
+		return err
+	}
+	if err := analyzer.ValidatePreferenceModes(c.PreferenceModes); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateContentRules(c.ContentRules); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateStylesheetImporters(c.StylesheetImporters); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateStylesheetRendering(c.StylesheetRendering); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateListImports(c.ListImports); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateTextFileExtensions(c.TextFileExtensions); err != nil {
+		return err
+	}
+
+	switch c.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid 'log_format': '%s' (must be 'text' or 'json')", c.LogFormat)
+	}
+
+	if _, _, err := logger.ParseTimestamps(c.LogTimestampFormat, c.LogTimezone); err != nil {
+		return fmt.Errorf("invalid 'log_timestamp_format' or 'log_timezone': %w", err)
+	}
+
+	if c.LogDebugRateLimit < 0 {
+		return fmt.Errorf("invalid 'log_debug_rate_limit': %d (must be 0 for unlimited or positive)", c.LogDebugRateLimit)
+	}
+
+	// Validate the log levels of the phases
+	for phase, level := range c.LogLevels {
+		if !analyzer.IsPhase(phase) {
+			return fmt.Errorf("invalid 'log_levels' phase '%s' (must be 'syntax', 'path check', 'content checks', 'stylesheet', 'traversal', 'remote listing' or 'parity')", phase)
+		}
+		switch level {
+		case "debug", "info", "error":
+		default:
+			return fmt.Errorf("invalid 'log_levels' level '%s' of '%s' (must be 'debug', 'info' or 'error')", level, phase)
+		}
+	}
+
+	if err := analyzer.ValidatePerfBudget(c.PerfBudget); err != nil {
+		return err
+	}
+
+	if !analyzer.IsRuleset(c.Ruleset) {
+		return fmt.Errorf("invalid 'ruleset': '%s' (must be 'lenient', 'standard' or 'strict')", c.Ruleset)
+	}
+
+	if c.MaxFindings < 0 {
+		return fmt.Errorf("invalid 'max_findings': %d (must be 0 for unlimited or positive)", c.MaxFindings)
+	}
+
+	// Validate ownership patterns
+	for i, o := range c.Owners {
+		if o.Pattern == "" || o.Owner == "" {
+			return fmt.Errorf("owners entry at index %d needs both 'pattern' and 'owner'", i)
+		}
+		if err := analyzer.ValidateGitignorePattern(o.Pattern); err != nil {
+			return fmt.Errorf("owners entry at index %d: %w", i, err)
+		}
+	}
+
+	// Validate remote target
+	if c.Remote.Host != "" && c.Remote.Root == "" {
+		return fmt.Errorf("'remote.root' is required when 'remote.host' is set")
+	}
+
+	// Validate network settings
+	if c.Network.Timeout < 0 || c.Network.Backoff < 0 {
+		return fmt.Errorf("invalid 'network' timeout or backoff (must be positive)")
+	}
+	if c.Network.Retries != nil && *c.Network.Retries < 0 {
+		return fmt.Errorf("invalid 'network.retries': %d (must be 0 for no retries or positive)", *c.Network.Retries)
+	}
+
+	// Validate symlinks policy
+	switch c.Symlinks {
+	case "", "follow", "skip", "error":
+	default:
+		return fmt.Errorf("invalid 'symlinks': '%s' (must be 'follow', 'skip' or 'error')", c.Symlinks)
+	}
 
 	return nil
 }
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
 )
 
 func TestGetConfig_Success(t *testing.T) {
@@ -182,6 +187,12 @@ func TestProcessArgs_Defaults(t *testing.T) {
 	if args.LogLevel != "error" {
 		t.Errorf("Expected default log level 'error', got '%s'", args.LogLevel)
 	}
+	if args.Format != "text" {
+		t.Errorf("Expected default format 'text', got '%s'", args.Format)
+	}
+	if args.Profile {
+		t.Error("Expected profiling to be disabled by default")
+	}
 }
 
 func TestProcessArgs_CustomValues(t *testing.T) {
@@ -190,7 +201,7 @@ func TestProcessArgs_CustomValues(t *testing.T) {
 	defer func() { os.Args = oldArgs }()
 
 	// Test with custom arguments
-	os.Args = []string{"cmd", "-c", "custom.yaml", "-l", "debug"}
+	os.Args = []string{"cmd", "-c", "custom.yaml", "-l", "debug", "--profile"}
 
 	args := ProcessArgs()
 
@@ -200,6 +211,22 @@ func TestProcessArgs_CustomValues(t *testing.T) {
 	if args.LogLevel != "debug" {
 		t.Errorf("Expected log level 'debug', got '%s'", args.LogLevel)
 	}
+	if !args.Profile {
+		t.Error("Expected profiling to be enabled")
+	}
+}
+
+func TestRun_InvalidFormat(t *testing.T) {
+	// Save original os.Args
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "-c", "config.yaml", "-format", "xml"}
+
+	err := run()
+	if err == nil || !contains(err.Error(), "invalid format") {
+		t.Errorf("Expected invalid format error, got: %v", err)
+	}
 }
 
 func TestRun_ConfigNotFound(t *testing.T) {
@@ -451,3 +478,705 @@ logfile: test.log
 		t.Errorf("Error should mention path_parameters, got: %v", err)
 	}
 }
+
+func TestGetConfig_InvalidSymlinksPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "invalid_symlinks.yaml")
+
+	invalidSymlinksYAML := `scripts:
+  - filename: test.sh
+    target_os: linux
+path_parameters:
+  - input
+source_code_root: '/test/path'
+symlinks: ignore
+`
+	err := os.WriteFile(configPath, []byte(invalidSymlinksYAML), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err = getConfig(configPath)
+	if err == nil {
+		t.Error("Expected error for invalid symlinks policy, got nil")
+	}
+
+	if err != nil && !contains(err.Error(), "symlinks") {
+		t.Errorf("Error should mention symlinks, got: %v", err)
+	}
+}
+
+func TestGetConfig_RemoteWithoutRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "remote_no_root.yaml")
+
+	remoteYAML := `scripts:
+  - filename: test.sh
+    target_os: linux
+path_parameters:
+  - input
+source_code_root: '/test/path'
+remote:
+  host: tcstaging01
+  user: tcdeploy
+`
+	err := os.WriteFile(configPath, []byte(remoteYAML), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err = getConfig(configPath)
+	if err == nil || !contains(err.Error(), "remote.root") {
+		t.Errorf("Expected error mentioning remote.root, got: %v", err)
+	}
+}
+
+func TestRunTrace(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "deploy.sh"), []byte("CFG=100-Config\nplmxml_import -xml_file=$CFG/a.xml\n"), 0644); err != nil {
+		t.Fatalf("Failed to create script: %v", err)
+	}
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configYAML := `scripts:
+  - filename: deploy.sh
+    target_os: linux
+path_parameters:
+  - xml_file
+source_code_root: '` + tempDir + `'
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runTrace([]string{"-c", configPath, "-s", "deploy.sh"}, &out); err != nil {
+		t.Fatalf("runTrace() failed: %v", err)
+	}
+	expected := "# deploy.sh (linux)\ndeploy.sh:2: plmxml_import -xml_file=100-Config/a.xml\n"
+	if out.String() != expected {
+		t.Errorf("runTrace() output %q, want %q", out.String(), expected)
+	}
+
+	if err := runTrace([]string{"-c", configPath, "-s", "other.sh"}, &out); err == nil {
+		t.Error("Expected error for script that is not configured, got nil")
+	}
+}
+
+func TestGetConfig_PathParameterStyles(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "styles.yaml")
+
+	stylesYAML := `scripts:
+  - filename: test.sh
+    target_os: linux
+path_parameters:
+  - input
+  - name: xml
+    style: space_quoted
+  - name: cfg
+    regex: '--cfg:(\S+)'
+source_code_root: '/test/path'
+`
+	if err := os.WriteFile(configPath, []byte(stylesYAML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := getConfig(configPath)
+	if err != nil {
+		t.Fatalf("getConfig() failed: %v", err)
+	}
+	expected := []analyzer.PathParameter{{Name: "input"}, {Name: "xml", Style: "space_quoted"}, {Name: "cfg", Regex: `--cfg:(\S+)`}}
+	if !reflect.DeepEqual(config.PathParameters, expected) {
+		t.Errorf("PathParameters = %+v, want %+v", config.PathParameters, expected)
+	}
+
+	invalidYAML := strings.Replace(stylesYAML, "space_quoted", "colon", 1)
+	if err := os.WriteFile(configPath, []byte(invalidYAML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := getConfig(configPath); err == nil || !contains(err.Error(), "invalid style") {
+		t.Errorf("Expected invalid style error, got: %v", err)
+	}
+}
+
+// What: Profiling writes non-empty CPU and heap profiles once stopped
+func TestStartProfiling(t *testing.T) {
+	tmpDir := t.TempDir()
+	cpuFile := filepath.Join(tmpDir, "cpu.pprof")
+	heapFile := filepath.Join(tmpDir, "heap.pprof")
+
+	stop, err := startProfiling(cpuFile, heapFile)
+	if err != nil {
+		t.Fatalf("startProfiling failed: %v", err)
+	}
+	stop()
+
+	for _, file := range []string{cpuFile, heapFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Errorf("Expected profile %s: %v", file, err)
+		} else if info.Size() == 0 {
+			t.Errorf("Expected profile %s to be non-empty", file)
+		}
+	}
+}
+
+// What: Profiling fails when the CPU profile cannot be created
+func TestStartProfiling_InvalidPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	if _, err := startProfiling(filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "heap.pprof")); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+}
+
+func TestGetConfig_InvalidScriptEncoding(t *testing.T) {
+	// What: Unknown script_encoding policies are rejected
+	configPath := filepath.Join(t.TempDir(), "invalid_encoding.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+script_encoding: transcode
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "script_encoding") {
+		t.Errorf("Expected script_encoding error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidRuleset(t *testing.T) {
+	// What: Rulesets other than the built-in ones are rejected
+	configPath := filepath.Join(t.TempDir(), "invalid_ruleset.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+ruleset: paranoid
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid 'ruleset': 'paranoid'") {
+		t.Errorf("Expected ruleset error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidLogLevels(t *testing.T) {
+	// What: Log levels of unknown phases or with unknown levels are rejected
+	for _, tt := range []struct{ logLevels, message string }{
+		{"{stylesheets: debug}", "invalid 'log_levels' phase 'stylesheets'"},
+		{"{stylesheet: verbose}", "invalid 'log_levels' level 'verbose' of 'stylesheet'"},
+	} {
+		configPath := filepath.Join(t.TempDir(), "invalid_log_levels.yaml")
+		content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+log_levels: ` + tt.logLevels + "\n"
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		_, err := getConfig(configPath)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected %q, got %v", tt.message, err)
+		}
+	}
+}
+
+func TestGetConfig_NegativeDebugRateLimit(t *testing.T) {
+	// What: A negative debug rate limit is rejected
+	configPath := filepath.Join(t.TempDir(), "negative_rate_limit.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+log_debug_rate_limit: -1
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid 'log_debug_rate_limit': -1") {
+		t.Errorf("Expected log_debug_rate_limit error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidTimezone(t *testing.T) {
+	// What: An unknown log timezone is rejected
+	configPath := filepath.Join(t.TempDir(), "invalid_timezone.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+log_timezone: Mars/Olympus
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "unknown timezone 'Mars/Olympus'") {
+		t.Errorf("Expected log_timezone error, got %v", err)
+	}
+}
+
+func TestGetConfig_IncompleteOwner(t *testing.T) {
+	// What: Ownership entries without an owner are rejected
+	configPath := filepath.Join(t.TempDir(), "incomplete_owner.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+owners:
+  - pattern: '200-Stylesheets/'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "owners") {
+		t.Errorf("Expected owners error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidFlagRule(t *testing.T) {
+	// What: Flag rules without flags are rejected
+	configPath := filepath.Join(t.TempDir(), "invalid_flag_rule.yaml")
+	content := `scripts:
+  - filename: test.sh
+    target_os: linux
+path_parameters:
+  - input
+source_code_root: '/test/path'
+flag_rules:
+  - utility: plmxml_import
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "flag rule for 'plmxml_import'") {
+		t.Errorf("Expected flag rule error, got %v", err)
+	}
+}
+
+func TestGetConfig_Repositories(t *testing.T) {
+	// What: Repositories are resolved from the top-level parameters and validated each
+	configPath := filepath.Join(t.TempDir(), "batch.yaml")
+	content := `path_parameters:
+  - input
+scripts:
+  - filename: test.bat
+    target_os: windows
+repositories:
+  - name: first
+    source_code_root: '/repos/first'
+  - name: second
+    source_code_root: '/repos/second'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := getConfig(configPath)
+	if err != nil {
+		t.Fatalf("getConfig() failed: %v", err)
+	}
+	if len(config.Repositories) != 2 || config.Repositories[1].Parameters.SourceCodeRoot != "/repos/second" {
+		t.Errorf("Expected 2 resolved repositories, got %+v", config.Repositories)
+	}
+	if len(config.Repositories[0].Parameters.Scripts) != 1 {
+		t.Errorf("Expected scripts inherited by the repositories, got %+v", config.Repositories[0].Parameters.Scripts)
+	}
+}
+
+func TestGetConfig_InvalidRepositories(t *testing.T) {
+	// What: Repositories without name, with duplicate names or incomplete parameters are rejected
+	tests := []struct {
+		name         string
+		repositories string
+		want         string
+	}{
+		{"missing name", "  - source_code_root: '/repos/a'\n", "missing 'name'"},
+		{"duplicate name", "  - name: a\n    source_code_root: '/repos/a'\n  - name: a\n    source_code_root: '/repos/b'\n", "more than once"},
+		{"missing root", "  - name: a\n", "repository 'a': 'source_code_root' is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "batch.yaml")
+			content := "path_parameters:\n  - input\nscripts:\n  - filename: test.bat\n    target_os: windows\nrepositories:\n" + tt.repositories
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			_, err := getConfig(configPath)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetConfigFrom_Profile(t *testing.T) {
+	// What: The profile selected with -env overrides the base parameters, the checksum is the one of the file
+	configPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	content := `path_parameters:
+  - input
+scripts:
+  - filename: test.bat
+    target_os: windows
+source_code_root: '/repos/dev'
+profiles:
+  prod:
+    source_code_root: '/repos/prod'
+    scripts:
+      - filename: prod.sh
+        target_os: linux
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	base, err := getConfig(configPath)
+	if err != nil || base.SourceCodeRoot != "/repos/dev" {
+		t.Fatalf("Expected the base parameters without -env, got %q %v", base.SourceCodeRoot, err)
+	}
+	prod, err := getConfigFrom(configPath, configSource{Env: "prod"})
+	if err != nil {
+		t.Fatalf("getConfigFrom() failed: %v", err)
+	}
+	if prod.SourceCodeRoot != "/repos/prod" || len(prod.Scripts) != 1 || prod.Scripts[0].Filename != "prod.sh" {
+		t.Errorf("Expected the parameters of the profile, got %q %+v", prod.SourceCodeRoot, prod.Scripts)
+	}
+	if prod.ConfigSHA256 != base.ConfigSHA256 {
+		t.Errorf("Expected the checksum of the file, got %s and %s", prod.ConfigSHA256, base.ConfigSHA256)
+	}
+
+	_, err = getConfigFrom(configPath, configSource{Env: "staging"})
+	if err == nil || !strings.Contains(err.Error(), "profile 'staging' is not defined (profiles: prod)") || exitCode(err) != exitConfig {
+		t.Errorf("Expected a configuration error for an unknown profile, got %v", err)
+	}
+}
+
+func TestGetConfig_StylesheetImporterWithoutUtility(t *testing.T) {
+	// What: Stylesheet importers without a utility are rejected
+	configPath := filepath.Join(t.TempDir(), "stylesheet_importer.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+stylesheet_importers:
+  - input_flag: list
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "stylesheet importer at index 0 is missing 'utility'") {
+		t.Errorf("Expected stylesheet importer error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidListImport(t *testing.T) {
+	// What: List imports with an invalid column are rejected
+	configPath := filepath.Join(t.TempDir(), "list_import.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+list_imports:
+  - utility: ics_import
+    column: -2
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "list import for 'ics_import' has an invalid column -2") {
+		t.Errorf("Expected list import error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidStylesheetRendering(t *testing.T) {
+	// What: Stylesheet rendering elements without a name are rejected
+	configPath := filepath.Join(t.TempDir(), "stylesheet_rendering.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+stylesheet_rendering:
+  elements:
+    - attributes: [source]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "stylesheet rendering element at index 0 is missing 'name'") {
+		t.Errorf("Expected stylesheet rendering error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidAllowedExecutable(t *testing.T) {
+	// What: Allowed executables that are no valid patterns are rejected
+	configPath := filepath.Join(t.TempDir(), "allowed_executables.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+allowed_executables:
+  - '[tc'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "allowed executable '[tc' is an invalid pattern") {
+		t.Errorf("Expected allowed executable error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidPreferenceMode(t *testing.T) {
+	// What: Preference modes listed twice are rejected
+	configPath := filepath.Join(t.TempDir(), "preference_modes.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+preference_modes:
+  - mode: import
+    scopes: [SITE]
+  - mode: Import
+    scopes: [USER]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "preference mode 'Import' is listed more than once") {
+		t.Errorf("Expected preference mode error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidContentRule(t *testing.T) {
+	// What: Content rules with an invalid pattern are rejected
+	configPath := filepath.Join(t.TempDir(), "content_rules.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+content_rules:
+  - pattern: '(-replace'
+    files: '*_prod.sh'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "content rule '(-replace' has an invalid pattern") {
+		t.Errorf("Expected content rule error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidTextFileExtension(t *testing.T) {
+	// What: Text file extensions that are empty or patterns are rejected
+	configPath := filepath.Join(t.TempDir(), "text_file_extensions.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+check_text_characters: true
+text_file_extensions: ['.csv', '*.lst']
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "text file extension at index 1 is invalid: '*.lst'") {
+		t.Errorf("Expected text file extension error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidCredentialFlag(t *testing.T) {
+	// What: Credential flags without approved variables or values are rejected
+	configPath := filepath.Join(t.TempDir(), "credential_flags.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+credential_flags:
+  - flag: p
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "credential flag 'p' needs approved 'variables' or 'values'") {
+		t.Errorf("Expected credential flag error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidPerfBudget(t *testing.T) {
+	// What: Performance budgets of unknown phases are rejected
+	configPath := filepath.Join(t.TempDir(), "perf_budget.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+perf_budget:
+  traversal: 2s
+  walking: 1s
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid 'perf_budget' phase 'walking'") {
+		t.Errorf("Expected perf budget error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidIgnorePattern(t *testing.T) {
+	// What: Ignore patterns using \ as directory separator are rejected, as in gitignore \ escapes
+	configPath := filepath.Join(t.TempDir(), "ignore_patterns.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+ignore_patterns:
+  global:
+    - '*.log'
+    - '100-Config\drafts\'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), `ignore pattern '100-Config\drafts\' uses '\' as directory separator`) {
+		t.Errorf("Expected ignore pattern error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidMatchStrategy(t *testing.T) {
+	// What: Unknown match strategies of a script are rejected
+	configPath := filepath.Join(t.TempDir(), "match_strategy.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+    match_strategy: fuzzy
+path_parameters:
+  - input
+source_code_root: '/test/path'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "script 'test.bat' has invalid 'match_strategy': 'fuzzy'") {
+		t.Errorf("Expected match strategy error, got %v", err)
+	}
+}
+
+func TestGetConfig_InvalidNetworkRetries(t *testing.T) {
+	// What: Negative network retries are rejected, durations are parsed
+	configPath := filepath.Join(t.TempDir(), "network.yaml")
+	content := `scripts:
+  - filename: test.bat
+    target_os: windows
+path_parameters:
+  - input
+source_code_root: '/test/path'
+network:
+  timeout: 5s
+  retries: -1
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid 'network.retries': -1") {
+		t.Errorf("Expected network retries error, got %v", err)
+	}
+}
+
+func TestGetConfig_SourceCodeRootAuto(t *testing.T) {
+	// What: 'source_code_root: auto' is replaced with the directory of the first script before validation
+	dir := t.TempDir()
+	script := filepath.Join(dir, "deploy.sh")
+	if err := os.WriteFile(script, []byte("echo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	configPath := filepath.Join(dir, "auto_root.yaml")
+	content := `scripts:
+  - filename: '` + script + `'
+    target_os: linux
+path_parameters:
+  - input
+source_code_root: auto
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	c, err := getConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	expected, _ := filepath.EvalSymlinks(dir)
+	if c.SourceCodeRoot != expected || c.Scripts[0].Filename != "deploy.sh" {
+		t.Errorf("Expected root %q and script 'deploy.sh', got %q and %q", expected, c.SourceCodeRoot, c.Scripts[0].Filename)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest file written by export-manifest
+const defaultManifestFile = "tcx-manifest.json"
+
+// manifestOptions are the command-line parameters of the export-manifest subcommand
+type manifestOptions struct {
+	Args
+	Output  string
+	SignKey string
+}
+
+// manifestFlagSet defines the flags of the export-manifest subcommand into o
+func manifestFlagSet(o *manifestOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("export-manifest", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.StringVar(&o.Output, "o", defaultManifestFile, "manifest file to write, YAML for a .yaml or .yml file and JSON otherwise")
+	f.StringVar(&o.SignKey, "sign-key", "", "PEM private key (PKCS#8: Ed25519, ECDSA or RSA) signing the manifest into '<manifest>.sig'")
+	return f
+}
+
+// deployManifest is the document written by export-manifest
+type deployManifest struct {
+	Version string                    `json:"version" yaml:"version"` // tool version
+	Scripts []analyzer.ScriptManifest `json:"scripts" yaml:"scripts"`
+}
+
+// runExportManifest validates the scripts and, when the validation passes, writes the
+// manifest of the files they deploy for audit and change management:
+// export-manifest [-c config.yaml] [-o tcx-manifest.json] [-sign-key key.pem]
+func runExportManifest(arguments []string, w io.Writer) error {
+	var o manifestOptions
+	if err := manifestFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	configurationParameters, err := getConfigFrom(o.ConfigPath, o.Config)
+	if err != nil {
+		return err
+	}
+	// The key is read before validating, a wrong key fails early
+	var signer crypto.Signer
+	if o.SignKey != "" {
+		if signer, err = readSigningKey(o.SignKey); err != nil {
+			return withExitCode(exitConfig, err)
+		}
+	}
+
+	results, err := validate(o.Args, configurationParameters, true)
+	if results == nil {
+		return err
+	}
+	reported := func(findings []analyzer.Finding) []analyzer.Finding {
+		return reportedFindings(o.Args, report.Filter{}, findings)
+	}
+	if err := validationError(results, reported, err); err != nil {
+		return err
+	}
+
+	repositories := configurationParameters.Repositories
+	if len(repositories) == 0 {
+		repositories = []analyzer.Repository{{Parameters: configurationParameters}}
+	}
+	manifest := deployManifest{Version: version, Scripts: []analyzer.ScriptManifest{}}
+	files := 0
+	for i, repo := range repositories {
+		scripts, err := analyzer.BuildManifest(repo.Parameters, results[i].Result)
+		if err != nil {
+			return withExitCode(kindExitCodes[analyzer.Kind(err)], err)
+		}
+		for _, script := range scripts {
+			script.Repository = repo.Name
+			files += len(script.Files)
+			manifest.Scripts = append(manifest.Scripts, script)
+		}
+	}
+
+	content, err := encodeManifest(manifest, o.Output)
+	if err != nil {
+		return withExitCode(exitInternal, fmt.Errorf("failed to encode manifest: %w", err))
+	}
+	if err := os.WriteFile(o.Output, content, 0644); err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to write manifest: %w", err))
+	}
+	fmt.Fprintf(w, "%d file(s) of %d script(s) listed in '%s'\n", files, len(manifest.Scripts), o.Output)
+
+	if signer == nil {
+		return nil
+	}
+	signature, err := signManifest(signer, content)
+	if err != nil {
+		return withExitCode(exitInternal, fmt.Errorf("failed to sign manifest: %w", err))
+	}
+	if err := os.WriteFile(o.Output+".sig", signature, 0644); err != nil {
+		return withExitCode(exitIO, fmt.Errorf("failed to write signature: %w", err))
+	}
+	fmt.Fprintf(w, "signature written to '%s'\n", o.Output+".sig")
+	return nil
+}
+
+// encodeManifest returns the manifest as YAML for a .yaml or .yml file, as JSON otherwise
+func encodeManifest(manifest deployManifest, file string) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), encoder.Close()
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	return append(content, '\n'), err
+}
+
+// readSigningKey reads a PEM encoded PKCS#8 private key
+func readSigningKey(file string) (crypto.Signer, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("signing key '%s' is not PEM encoded", file)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key '%s' is not a PKCS#8 private key: %w", file, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("signing key '%s' cannot sign", file)
+	}
+	return signer, nil
+}
+
+// signManifest returns the detached signature of the manifest content: Ed25519 signs
+// the content itself, ECDSA and RSA (PKCS #1 v1.5) its SHA-256 digest. The signature is
+// verified with e.g. 'openssl dgst -sha256 -verify public.pem -signature <manifest>.sig <manifest>'.
+func signManifest(signer crypto.Signer, content []byte) ([]byte, error) {
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		return signer.Sign(rand.Reader, content, crypto.Hash(0))
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		digest := sha256.Sum256(content)
+		return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	return nil, fmt.Errorf("unsupported key type %T", signer)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeSigningKey writes the key as PEM encoded PKCS#8 and returns the path
+func writeSigningKey(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return path
+}
+
+func TestRunExportManifest(t *testing.T) {
+	// What: The manifest lists the deployed files with size, checksum, utility and line, signed with Ed25519
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	output := filepath.Join(t.TempDir(), "manifest.json")
+
+	var out bytes.Buffer
+	if err := runExportManifest([]string{"-c", configPath, "-o", output, "-sign-key", writeSigningKey(t, private)}, &out); err != nil {
+		t.Fatalf("runExportManifest() failed: %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Manifest not written: %v", err)
+	}
+	var manifest deployManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if len(manifest.Scripts) != 1 || len(manifest.Scripts[0].Files) != 1 {
+		t.Fatalf("Expected 1 script deploying 1 file, got %+v", manifest)
+	}
+	entry := manifest.Scripts[0].Files[0]
+	if entry.Path != "100-Config/a.xml" || entry.Size != 4 || entry.Utility != "plmxml_import" || entry.Line != 1 ||
+		entry.SHA256 != "29114363f749a0226b6988dda3ca2492a954117ab6b5f382706c20300dabc079" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+
+	signature, err := os.ReadFile(output + ".sig")
+	if err != nil {
+		t.Fatalf("Signature not written: %v", err)
+	}
+	if !ed25519.Verify(public, content, signature) {
+		t.Error("Signature does not verify the manifest")
+	}
+}
+
+func TestRunExportManifest_YAML(t *testing.T) {
+	// What: A .yaml output is written as YAML
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	output := filepath.Join(t.TempDir(), "manifest.yaml")
+
+	if err := runExportManifest([]string{"-c", configPath, "-o", output}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runExportManifest() failed: %v", err)
+	}
+	content, _ := os.ReadFile(output)
+	var manifest deployManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil || len(manifest.Scripts) != 1 {
+		t.Fatalf("Expected a YAML manifest, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(output + ".sig"); !os.IsNotExist(err) {
+		t.Error("Expected no signature without -sign-key")
+	}
+}
+
+func TestRunExportManifest_Failing(t *testing.T) {
+	// What: No manifest is written for scripts failing the validation
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	output := filepath.Join(t.TempDir(), "manifest.json")
+
+	err := runExportManifest([]string{"-c", configPath, "-o", output}, &bytes.Buffer{})
+	if exitCode(err) != exitFindings {
+		t.Errorf("Expected exit code %d, got %d (%v)", exitFindings, exitCode(err), err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected no manifest for a failing validation")
+	}
+}
+
+func TestSignManifest_ECDSA(t *testing.T) {
+	// What: ECDSA keys sign the SHA-256 digest of the manifest
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, err := readSigningKey(writeSigningKey(t, key))
+	if err != nil {
+		t.Fatalf("readSigningKey() failed: %v", err)
+	}
+	signature, err := signManifest(signer, []byte("manifest"))
+	if err != nil {
+		t.Fatalf("signManifest() failed: %v", err)
+	}
+	digest := sha256.Sum256([]byte("manifest"))
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], signature) {
+		t.Error("Signature does not verify the manifest")
+	}
+}
+
+func TestReadSigningKey_Invalid(t *testing.T) {
+	// What: Keys that are not PEM encoded PKCS#8 are errors
+	path := filepath.Join(t.TempDir(), "key.pem")
+	os.WriteFile(path, []byte("not a key"), 0600)
+	if _, err := readSigningKey(path); err == nil || !strings.Contains(err.Error(), "not PEM encoded") {
+		t.Errorf("Expected a PEM error, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// writeParityMatrix writes the executable x script parity matrix of the results to the
+// file, as JSON for a .json file and as CSV otherwise
+func writeParityMatrix(file string, results []analyzer.RepositoryResult) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create parity matrix: %w", err)
+	}
+	defer f.Close()
+
+	matrix := analyzer.MergeParityMatrices(results)
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		err = report.ParityJSON(f, matrix)
+	} else {
+		err = report.ParityCSV(f, matrix)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write parity matrix '%s': %w", file, err)
+	}
+	return f.Close()
+}
+
+// writeParityDiff writes the side-by-side HTML diff of the Windows and Linux script
+// pairs of the results to the file
+func writeParityDiff(file string, results []analyzer.RepositoryResult) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create parity diff: %w", err)
+	}
+	defer f.Close()
+
+	if err := report.ParityHTML(f, analyzer.BuildParityDiffs(results)); err != nil {
+		return fmt.Errorf("failed to write parity diff '%s': %w", file, err)
+	}
+	return f.Close()
+}
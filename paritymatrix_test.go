@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestRunCheck_ParityMatrix(t *testing.T) {
+	// What: -parity-matrix writes the invocations per script, as CSV or as JSON for a .json file
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh":  "plmxml_import -xml_file=\"100-Config/a.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+		"deploy.bat": "plmxml_import -xml_file=\"100-Config\\a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n  - filename: deploy.bat\n    target_os: windows\n")
+	dir := t.TempDir()
+
+	csvFile := filepath.Join(dir, "parity.csv")
+	if err := runCheck([]string{"-c", configPath, "-format", "compact", "-parity-matrix", csvFile}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runCheck() failed: %v", err)
+	}
+	content, err := os.ReadFile(csvFile)
+	if err != nil {
+		t.Fatalf("Parity matrix not written: %v", err)
+	}
+	if want := "executable,deploy.sh,deploy.bat,windows,linux\nplmxml_import,2,1,1,2\n"; string(content) != want {
+		t.Errorf("Expected %q, got %q", want, content)
+	}
+
+	jsonFile := filepath.Join(dir, "parity.json")
+	if err := runCheck([]string{"-c", configPath, "-format", "compact", "-parity-matrix", jsonFile}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runCheck() failed: %v", err)
+	}
+	content, err = os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("Parity matrix not written: %v", err)
+	}
+	var matrix analyzer.ParityMatrix
+	if err := json.Unmarshal(content, &matrix); err != nil {
+		t.Fatalf("Invalid matrix %q: %v", content, err)
+	}
+	if len(matrix.Scripts) != 2 || len(matrix.Executables) != 1 || matrix.Executables[0].Linux != 2 {
+		t.Errorf("Unexpected matrix %+v", matrix)
+	}
+}
+
+func TestRunCheck_ParityMatrixUnwritable(t *testing.T) {
+	// What: A parity matrix that cannot be written is an I/O error
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+
+	err := runCheck([]string{"-c", configPath, "-parity-matrix", filepath.Join(t.TempDir(), "missing", "parity.csv")}, &bytes.Buffer{})
+	if exitCode(err) != exitIO || !strings.Contains(err.Error(), "failed to create parity matrix") {
+		t.Errorf("Expected an I/O error, got %v", err)
+	}
+}
+
+func TestRunCheck_ParityDiff(t *testing.T) {
+	// What: -parity-diff writes the script pair side by side with the file difference marked
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh":  "plmxml_import -xml_file=\"100-Config/a.xml\"\n",
+		"deploy.bat": "plmxml_import -xml_file=\"100-Config\\b.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n  - filename: deploy.bat\n    target_os: windows\n")
+	file := filepath.Join(t.TempDir(), "parity.html")
+
+	runCheck([]string{"-c", configPath, "-format", "compact", "-parity-diff", file}, &bytes.Buffer{})
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Parity diff not written: %v", err)
+	}
+	for _, want := range []string{"deploy.bat / deploy.sh", `<tr class="file">`, `<mark>100-Config\b.xml</mark>`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in %s", want, content)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package validatortest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// Finding is a finding of the report; an alias, so modules outside of this one can
+// write the expected findings
+type Finding = analyzer.Finding
+
+// Report is the outcome of a validation run by Repo.Run
+type Report struct {
+	analyzer.Result
+	Err error // error of the run, e.g. an unreadable repository; findings are no error
+}
+
+// FindingsOf returns the findings of the rule, all findings for an empty rule
+func (r Report) FindingsOf(rule string) []Finding {
+	var findings []Finding
+	for _, f := range r.Result.Findings {
+		if rule == "" || f.Rule == rule {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// matches reports whether the finding has the set fields of want; the message of
+// want is a substring of the message
+func matches(f, want Finding) bool {
+	return (want.Rule == "" || f.Rule == want.Rule) &&
+		(want.Severity == "" || f.Severity == want.Severity) &&
+		(want.Script == "" || f.Script == want.Script) &&
+		(want.Line == 0 || f.Line == want.Line) &&
+		(want.Column == 0 || f.Column == want.Column) &&
+		(want.Path == "" || f.Path == want.Path) &&
+		strings.Contains(f.Message, want.Message)
+}
+
+// AssertFinding fails the test when no finding of the report has the set fields of want
+func AssertFinding(t testing.TB, r Report, want Finding) {
+	t.Helper()
+	for _, f := range r.Result.Findings {
+		if matches(f, want) {
+			return
+		}
+	}
+	t.Errorf("Expected a finding %+v, got %+v", want, r.Result.Findings)
+}
+
+// AssertNoFinding fails the test when a finding of the report has the set fields of
+// unwanted, e.g. its rule only
+func AssertNoFinding(t testing.TB, r Report, unwanted Finding) {
+	t.Helper()
+	for _, f := range r.Result.Findings {
+		if matches(f, unwanted) {
+			t.Errorf("Expected no finding %+v, got %+v", unwanted, f)
+		}
+	}
+}
+
+// AssertNoFindings fails the test when the report has findings
+func AssertNoFindings(t testing.TB, r Report) {
+	t.Helper()
+	if len(r.Result.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", r.Result.Findings)
+	}
+}
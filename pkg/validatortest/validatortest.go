@@ -0,0 +1,129 @@
+// Package validatortest helps testing the checks of the validation: it builds
+// temporary repositories with deployment scripts, runs the validation on them and
+// asserts the findings of the report.
+//
+//	repo := validatortest.NewRepo(t).
+//		Files("100-Config/a.xml").
+//		Script("deploy.sh", "linux", `plmxml_import -xml_file="100-Config/b.xml"`)
+//	report := repo.Run()
+//	validatortest.AssertFinding(t, report, validatortest.Finding{Rule: "TCX010", Script: "deploy.sh", Line: 1})
+package validatortest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+	"github.com/ananchev/validate-tcx-deploy-script/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// Path parameters of the configuration when the test configures none
+var defaultPathParameters = []string{"xml_file", "input"}
+
+// initLogs limits the log output of the validations to errors, once per test binary
+var initLogs sync.Once
+
+// Repo is a temporary repository with the deployment scripts of a test and the
+// configuration validating them
+type Repo struct {
+	t       testing.TB
+	root    string
+	scripts []string // entries of 'scripts', YAML flow mappings
+	config  []string // top-level keys added with Config
+}
+
+// NewRepo returns an empty repository in a temporary directory removed with the test
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	initLogs.Do(func() { logger.InitLogger("", "error") })
+	return &Repo{t: t, root: t.TempDir()}
+}
+
+// Root returns the directory of the repository, the source code root of the validation
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// File writes a file of the repository; path is relative to the root, with forward slashes
+func (r *Repo) File(path, content string) *Repo {
+	r.t.Helper()
+	r.write(path, content, 0644)
+	return r
+}
+
+// write writes a file of the repository with the permissions of mode
+func (r *Repo) write(path, content string, mode os.FileMode) {
+	r.t.Helper()
+	fullPath := filepath.Join(r.root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		r.t.Fatalf("Failed to create directory of %s: %v", path, err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), mode); err != nil {
+		r.t.Fatalf("Failed to create file %s: %v", path, err)
+	}
+}
+
+// Files writes files of the repository with placeholder content
+func (r *Repo) Files(paths ...string) *Repo {
+	r.t.Helper()
+	for _, path := range paths {
+		r.File(path, "test content")
+	}
+	return r
+}
+
+// Script writes a deployment script of the lines and adds it to the validated scripts;
+// Windows scripts are written with \r\n line endings
+func (r *Repo) Script(filename, targetOS string, lines ...string) *Repo {
+	r.t.Helper()
+	newline := "\n"
+	if targetOS == "windows" {
+		newline = "\r\n"
+	}
+	r.write(filename, strings.Join(lines, newline)+newline, 0755)
+	r.scripts = append(r.scripts, "{filename: "+quote(filename)+", target_os: "+quote(targetOS)+"}")
+	return r
+}
+
+// Config adds top-level keys to the configuration, in YAML, e.g.
+// "allowed_executables: [plmxml_import]". Without 'path_parameters' the flags
+// xml_file and input are path parameters.
+func (r *Repo) Config(document string) *Repo {
+	r.config = append(r.config, document)
+	return r
+}
+
+// Parameters returns the configuration of the repository, decoded like a
+// configuration file; the test fails on invalid YAML
+func (r *Repo) Parameters() analyzer.Parameters {
+	r.t.Helper()
+	document := "scripts: [" + strings.Join(r.scripts, ", ") + "]\n" +
+		"source_code_root: " + quote(r.root) + "\n" +
+		strings.Join(r.config, "\n")
+	var params analyzer.Parameters
+	if err := yaml.Unmarshal([]byte(document), &params); err != nil {
+		r.t.Fatalf("Invalid configuration: %v\n%s", err, document)
+	}
+	if len(params.PathParameters) == 0 {
+		for _, name := range defaultPathParameters {
+			params.PathParameters = append(params.PathParameters, analyzer.PathParameter{Name: name})
+		}
+	}
+	return params
+}
+
+// Run validates the scripts of the repository
+func (r *Repo) Run() Report {
+	r.t.Helper()
+	result, err := analyzer.Run(r.Parameters())
+	return Report{Result: result, Err: err}
+}
+
+// quote returns the value as a single-quoted YAML scalar
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,89 @@
+package validatortest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// What: Files and scripts are written below the root, Windows scripts with \r\n line endings
+func TestRepo_Files(t *testing.T) {
+	repo := NewRepo(t).
+		File("100-Config/a.xml", "<a/>").
+		Files("100-Config/b.xml").
+		Script("deploy.bat", "windows", `plmxml_import -xml_file="100-Config\a.xml"`, "exit /b 0")
+
+	data, err := os.ReadFile(filepath.Join(repo.Root(), "deploy.bat"))
+	if err != nil || string(data) != "plmxml_import -xml_file=\"100-Config\\a.xml\"\r\nexit /b 0\r\n" {
+		t.Errorf("Unexpected script %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo.Root(), "100-Config", "b.xml")); err != nil {
+		t.Errorf("Expected the placeholder file, got %v", err)
+	}
+}
+
+// What: The configuration lists the scripts, the root and the added keys, with default path parameters
+func TestRepo_Parameters(t *testing.T) {
+	repo := NewRepo(t).Script("it's.sh", "linux").Config("allowed_executables: [plmxml_import]")
+	params := repo.Parameters()
+	if len(params.Scripts) != 1 || params.Scripts[0].Filename != "it's.sh" || params.Scripts[0].TargetOS != "linux" {
+		t.Errorf("Unexpected scripts %+v", params.Scripts)
+	}
+	if params.SourceCodeRoot != repo.Root() || len(params.AllowedExecutables) != 1 || len(params.PathParameters) != 2 {
+		t.Errorf("Unexpected parameters %+v", params)
+	}
+	if params := NewRepo(t).Config("path_parameters: [input]").Parameters(); len(params.PathParameters) != 1 {
+		t.Errorf("Expected the configured path parameters, got %+v", params.PathParameters)
+	}
+}
+
+// What: Findings of a run are asserted by their set fields
+func TestRepo_Run(t *testing.T) {
+	report := NewRepo(t).
+		Files("100-Config/a.xml").
+		Script("deploy.sh", "linux", `plmxml_import -xml_file="100-Config/a.xml"`, `plmxml_import -xml_file="100-Config/b.xml"`).
+		Config("ignore_patterns:\n  global: ['deploy.sh']").
+		Run()
+
+	if report.Err != nil {
+		t.Fatalf("Run failed: %v", report.Err)
+	}
+	AssertFinding(t, report, Finding{Rule: analyzer.RuleMissingFile, Script: "deploy.sh", Line: 2, Message: "100-Config/b.xml"})
+	AssertNoFinding(t, report, Finding{Rule: analyzer.RuleMissingFile, Line: 1})
+	if findings := report.FindingsOf(analyzer.RuleMissingFile); len(findings) != 1 {
+		t.Errorf("Expected one missing file, got %+v", findings)
+	}
+}
+
+// What: A repository whose scripts reference existing files only has no findings
+func TestAssertNoFindings(t *testing.T) {
+	report := NewRepo(t).
+		Files("100-Config/a.xml").
+		Script("deploy.sh", "linux", `plmxml_import -xml_file="100-Config/a.xml"`).
+		Config("ignore_patterns:\n  global: ['deploy.sh']").
+		Run()
+	AssertNoFindings(t, report)
+}
+
+// What: Parallel tests validating their own repositories get their own findings
+func TestRepo_RunParallel(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprintf("repo%d", i), func(t *testing.T) {
+			t.Parallel()
+			missing := fmt.Sprintf("100-Config/missing%d.xml", i)
+			report := NewRepo(t).
+				Files("100-Config/a.xml").
+				Script("deploy.sh", "linux", `plmxml_import -xml_file="100-Config/a.xml"`, `plmxml_import -xml_file="`+missing+`"`).
+				Config("ignore_patterns:\n  global: ['deploy.sh']").
+				Run()
+			AssertFinding(t, report, Finding{Rule: analyzer.RuleMissingFile, Script: "deploy.sh", Line: 2, Message: missing})
+			if findings := report.FindingsOf(""); len(findings) != 1 {
+				t.Errorf("Expected one finding, got %+v", findings)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/report"
+)
+
+// serveOptions are the command-line parameters of the serve subcommand
+type serveOptions struct {
+	Args
+	Address string
+}
+
+// serveFlagSet defines the flags of the serve subcommand into o
+func serveFlagSet(o *serveOptions) *flag.FlagSet {
+	f := flag.NewFlagSet("serve", flag.ContinueOnError)
+	validationFlags(f, &o.Args)
+	f.StringVar(&o.Address, "addr", "127.0.0.1:8080", "address to listen on")
+	return f
+}
+
+// serveResponse is the answer to a validation request
+type serveResponse struct {
+	Passed   bool                     `json:"passed"`
+	Findings []report.BaselineFinding `json:"findings"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// validationServer validates the configured scripts on request. Requests are validated
+// in parallel, each with a logger and an analyzer of its own, see validate.
+type validationServer struct {
+	args Args
+}
+
+// newServeHandler returns the handler of the validation server: POST /check validates
+// and answers the findings as JSON, GET /healthz answers 'ok'
+func newServeHandler(args Args) http.Handler {
+	s := &validationServer{args: args}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/check", s.check)
+	return mux
+}
+
+// check validates the scripts. The configuration is read for every request, so a
+// changed policy applies without restarting the server.
+func (s *validationServer) check(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeServeResponse(w, http.StatusMethodNotAllowed, serveResponse{Error: "use POST"})
+		return
+	}
+	configurationParameters, err := getConfigFrom(s.args.ConfigPath, s.args.Config)
+	if err != nil {
+		writeServeResponse(w, http.StatusInternalServerError, serveResponse{Error: err.Error()})
+		return
+	}
+	results, err := validate(s.args, configurationParameters, true)
+	if failure := validationFailure(results); results == nil || failure != nil {
+		if failure != nil {
+			err = failure
+		}
+		writeServeResponse(w, http.StatusInternalServerError, serveResponse{Error: err.Error()})
+		return
+	}
+
+	response := serveResponse{Passed: err == nil, Findings: report.NewBaseline(results).Findings}
+	for _, r := range results {
+		response.Passed = response.Passed && r.Result.Summary.Passed
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	writeServeResponse(w, http.StatusOK, response)
+}
+
+// writeServeResponse writes the response as JSON with the status code
+func writeServeResponse(w http.ResponseWriter, status int, response serveResponse) {
+	if response.Findings == nil {
+		response.Findings = []report.BaselineFinding{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// runServe validates the configured scripts on HTTP requests until the server fails:
+// serve [-c config.yaml] [-addr 127.0.0.1:8080]
+func runServe(arguments []string, w io.Writer) error {
+	var o serveOptions
+	if err := serveFlagSet(&o).Parse(arguments); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	// The configuration is checked once at startup so a broken policy fails early
+	if _, err := getConfigFrom(o.ConfigPath, o.Config); err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: o.Address, Handler: newServeHandler(o.Args), ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(w, "validating '%s' on POST http://%s/check\n", o.ConfigPath, o.Address)
+	return withExitCode(exitIO, server.ListenAndServe())
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestServeHandler_Check(t *testing.T) {
+	// What: POST /check answers the findings as JSON, other methods are refused
+	configPath := writeValidationFixture(t, map[string]string{
+		"deploy.sh": "plmxml_import -xml_file=\"100-Config/missing.xml\"\nplmxml_import -xml_file=\"100-Config/a.xml\"\n",
+	}, "  - filename: deploy.sh\n    target_os: linux\n")
+	server := httptest.NewServer(newServeHandler(Args{ConfigPath: configPath, LogLevel: "error"}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/check", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /check failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var response serveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || response.Passed || len(response.Findings) != 1 || response.Findings[0].Rule != "TCX010" {
+		t.Errorf("Unexpected response %d %+v", resp.StatusCode, response)
+	}
+
+	resp, err = http.Get(server.URL + "/check")
+	if err != nil {
+		t.Fatalf("GET /check failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /check, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeHandler_ConfigError(t *testing.T) {
+	// What: A configuration that cannot be loaded answers 500 with the error
+	server := httptest.NewServer(newServeHandler(Args{ConfigPath: "definitely_does_not_exist.yaml"}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/check", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /check failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var response serveResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	if resp.StatusCode != http.StatusInternalServerError || response.Error == "" {
+		t.Errorf("Expected 500 with error, got %d %+v", resp.StatusCode, response)
+	}
+}
+
+func TestServeHandler_ParallelRequests(t *testing.T) {
+	// What: Parallel requests to servers of different configurations each answer the findings of their own
+	var servers []*httptest.Server
+	for i := 0; i < 2; i++ {
+		configPath := writeValidationFixture(t, map[string]string{
+			"deploy.sh": fmt.Sprintf("plmxml_import -xml_file=\"100-Config/a.xml\"\nplmxml_import -xml_file=\"100-Config/missing%d.xml\"\n", i),
+		}, "  - filename: deploy.sh\n    target_os: linux\n")
+		server := httptest.NewServer(newServeHandler(Args{ConfigPath: configPath, LogLevel: "error"}))
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Post(servers[i%2].URL+"/check", "application/json", nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer resp.Body.Close()
+			var response serveResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				errs <- err
+				return
+			}
+			want := fmt.Sprintf("missing%d.xml", i%2)
+			if resp.StatusCode != http.StatusOK || len(response.Findings) != 1 || !strings.Contains(response.Findings[0].Path, want) {
+				errs <- fmt.Errorf("request %d: expected the finding of %s, got %d %+v", i, want, resp.StatusCode, response)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestServeHandler_ParallelRequestLogs(t *testing.T) {
+	// What: Parallel requests log to the log file of their own configuration and share the audit log
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	var servers []*httptest.Server
+	var logfiles []string
+	for i := 0; i < 2; i++ {
+		configPath := writeValidationFixture(t, map[string]string{
+			"deploy.sh": fmt.Sprintf("plmxml_import -xml_file=\"100-Config/missing%d.xml\"\n", i),
+		}, "  - filename: deploy.sh\n    target_os: linux\n")
+		logfile := filepath.Join(filepath.Dir(configPath), "validation.log")
+		config, err := os.OpenFile(configPath, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(config, "logfile: '%s'\n", logfile)
+		config.Close()
+		server := httptest.NewServer(newServeHandler(Args{ConfigPath: configPath, LogLevel: "info", AuditLog: auditLog}))
+		defer server.Close()
+		servers = append(servers, server)
+		logfiles = append(logfiles, logfile)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Post(servers[i%2].URL+"/check", "application/json", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, logfile := range logfiles {
+		content, err := os.ReadFile(logfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		own, other := fmt.Sprintf("missing%d.xml", i), fmt.Sprintf("missing%d.xml", 1-i)
+		if strings.Count(string(content), own) < 4 || strings.Contains(string(content), other) {
+			t.Errorf("Expected the log of configuration %d to list only %s for each request, got %q", i, own, content)
+		}
+	}
+	content, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("Expected an audit record per line, got %q", line)
+		}
+	}
+	if len(lines) != 8 {
+		t.Errorf("Expected 8 audit records, got %d", len(lines))
+	}
+}
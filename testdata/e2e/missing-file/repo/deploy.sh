@@ -0,0 +1,3 @@
+#!/bin/bash
+plmxml_import -xml_file="100-Config/a.xml"
+plmxml_import -xml_file="100-Config/b.xml"
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+// version is the semantic version of the tool, set at build time with
+// -ldflags "-X main.version=1.2.3"
+var version = "1.0.0"
+
+// policyVersion is the version of the configuration format the tool reads. A policy
+// with the same major and a lower or equal minor version is compatible.
+const policyVersion = "1.0"
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version; missing parts are 0
+type semver struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseVersion parses a semantic version, with or without the leading 'v'.
+// Build metadata (+...) is ignored.
+func parseVersion(s string) (semver, error) {
+	var v semver
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(text, '+'); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.IndexByte(text, '-'); i >= 0 {
+		text, v.prerelease = text[:i], text[i+1:]
+	}
+	fields := strings.Split(text, ".")
+	if text == "" || len(fields) > 3 {
+		return v, fmt.Errorf("invalid version '%s' (expected MAJOR.MINOR.PATCH)", s)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version '%s' (expected MAJOR.MINOR.PATCH)", s)
+		}
+		v.parts[i] = n
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 when v is lower, equal or greater than o. A prerelease
+// is lower than the release of the same version.
+func (v semver) compare(o semver) int {
+	for i := range v.parts {
+		if v.parts[i] != o.parts[i] {
+			if v.parts[i] < o.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	case v.prerelease < o.prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// checkCompatibility refuses a configuration requiring a newer tool or written for
+// an unsupported policy format, so CI agents with different tool versions do not
+// silently validate differently
+func checkCompatibility(c *analyzer.Parameters) error {
+	if c.MinToolVersion != "" {
+		required, err := parseVersion(c.MinToolVersion)
+		if err != nil {
+			return fmt.Errorf("'min_tool_version': %w", err)
+		}
+		current, err := parseVersion(version)
+		if err != nil {
+			return fmt.Errorf("tool version: %w", err)
+		}
+		if current.compare(required) < 0 {
+			return fmt.Errorf("the policy requires tool version %s or later, this is %s", c.MinToolVersion, version)
+		}
+	}
+
+	if c.PolicyVersion != "" {
+		policy, err := parseVersion(c.PolicyVersion)
+		if err != nil {
+			return fmt.Errorf("'policy_version': %w", err)
+		}
+		supported, _ := parseVersion(policyVersion)
+		if policy.parts[0] != supported.parts[0] || policy.parts[1] > supported.parts[1] {
+			return fmt.Errorf("policy version %s is not supported, this tool reads policy version %s", c.PolicyVersion, policyVersion)
+		}
+	}
+	return nil
+}
+
+// versionInfo is the machine-readable output of -print-version
+type versionInfo struct {
+	Version       string `json:"version"`
+	PolicyVersion string `json:"policy_version"`
+	GoVersion     string `json:"go_version"`
+	Platform      string `json:"platform"`
+}
+
+// printVersion writes the version information as JSON
+func printVersion(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(versionInfo{
+		Version:       version,
+		PolicyVersion: policyVersion,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+	})
+}
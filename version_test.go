@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ananchev/validate-tcx-deploy-script/internal/analyzer"
+)
+
+func TestParseVersion(t *testing.T) {
+	// What: Semantic versions are parsed with optional 'v', missing parts, prerelease and build metadata
+	tests := []struct {
+		input      string
+		parts      [3]int
+		prerelease string
+	}{
+		{"1.2.3", [3]int{1, 2, 3}, ""},
+		{"v2.0.1", [3]int{2, 0, 1}, ""},
+		{"1.4", [3]int{1, 4, 0}, ""},
+		{"1.0.0-rc.1", [3]int{1, 0, 0}, "rc.1"},
+		{"1.0.0+build.7", [3]int{1, 0, 0}, ""},
+	}
+	for _, tt := range tests {
+		v, err := parseVersion(tt.input)
+		if err != nil {
+			t.Errorf("parseVersion(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if v.parts != tt.parts || v.prerelease != tt.prerelease {
+			t.Errorf("parseVersion(%q) = %+v, want %v %q", tt.input, v, tt.parts, tt.prerelease)
+		}
+	}
+
+	for _, invalid := range []string{"", "one.two", "1.2.3.4", "1.-2"} {
+		if _, err := parseVersion(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// What: Versions compare numerically, a prerelease is lower than its release
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0", "2.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+	}
+	for _, tt := range tests {
+		a, _ := parseVersion(tt.a)
+		b, _ := parseVersion(tt.b)
+		if got := a.compare(b); got != tt.want {
+			t.Errorf("compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	// What: Policies requiring a newer tool or an unsupported policy format are refused
+	originalVersion := version
+	defer func() { version = originalVersion }()
+	version = "1.4.0"
+
+	tests := []struct {
+		name    string
+		params  analyzer.Parameters
+		wantErr string
+	}{
+		{"no requirements", analyzer.Parameters{}, ""},
+		{"older tool required", analyzer.Parameters{MinToolVersion: "1.2.0"}, ""},
+		{"same tool version", analyzer.Parameters{MinToolVersion: "v1.4.0"}, ""},
+		{"newer tool required", analyzer.Parameters{MinToolVersion: "1.5.0"}, "requires tool version 1.5.0"},
+		{"invalid tool version", analyzer.Parameters{MinToolVersion: "latest"}, "min_tool_version"},
+		{"supported policy", analyzer.Parameters{PolicyVersion: policyVersion}, ""},
+		{"newer policy minor", analyzer.Parameters{PolicyVersion: "1.9"}, "not supported"},
+		{"other policy major", analyzer.Parameters{PolicyVersion: "2.0"}, "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCompatibility(&tt.params)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetConfig_IncompatiblePolicy(t *testing.T) {
+	// What: Loading a configuration requiring a newer tool fails before validation
+	configPath := filepath.Join(t.TempDir(), "policy.yaml")
+	content := "min_tool_version: '999.0.0'\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := getConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "incompatible configuration") {
+		t.Errorf("Expected incompatible configuration error, got %v", err)
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	// What: The version information is printed as JSON
+	var buf bytes.Buffer
+	if err := printVersion(&buf); err != nil {
+		t.Fatalf("printVersion failed: %v", err)
+	}
+	var info versionInfo
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if info.Version != version || info.PolicyVersion != policyVersion || info.GoVersion == "" {
+		t.Errorf("Unexpected version information: %+v", info)
+	}
+}